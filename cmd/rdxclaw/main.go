@@ -11,15 +11,20 @@ import (
 	"context"
 	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/Sterlites/RDxClaw/pkg/agent"
@@ -32,6 +37,7 @@ import (
 	"github.com/Sterlites/RDxClaw/pkg/devices"
 	"github.com/Sterlites/RDxClaw/pkg/health"
 	"github.com/Sterlites/RDxClaw/pkg/heartbeat"
+	"github.com/Sterlites/RDxClaw/pkg/knowledge"
 	"github.com/Sterlites/RDxClaw/pkg/logger"
 	"github.com/Sterlites/RDxClaw/pkg/migrate"
 	"github.com/Sterlites/RDxClaw/pkg/providers"
@@ -53,7 +59,80 @@ var (
 	goVersion string
 )
 
-const logo = "🦾"
+// brand is how the CLI presents the assistant: the defaults until
+// loadConfig replaces them with the configured branding.
+var brand = config.DefaultBranding()
+
+// formatReply prefixes a reply of the agent with the brand's mark.
+func formatReply(b config.BrandingConfig, response string) string {
+	return b.Mark() + " " + response
+}
+
+// workspaceFlag holds the global --workspace flag; see workspaceOverride.
+var workspaceFlag string
+
+// globalFlags are the flags every command accepts.
+type globalFlags struct {
+	workspace string
+	profile   string
+	dataDir   string
+}
+
+// parseGlobalFlags removes the global flags from args, wherever they
+// appear, and returns the remaining args and the flag values.
+func parseGlobalFlags(args []string) (rest []string, flags globalFlags) {
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--workspace" && i+1 < len(args):
+			flags.workspace = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], "--workspace="):
+			flags.workspace = strings.TrimPrefix(args[i], "--workspace=")
+		case args[i] == "--profile" && i+1 < len(args):
+			flags.profile = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], "--profile="):
+			flags.profile = strings.TrimPrefix(args[i], "--profile=")
+		case args[i] == "--data-dir" && i+1 < len(args):
+			flags.dataDir = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], "--data-dir="):
+			flags.dataDir = strings.TrimPrefix(args[i], "--data-dir=")
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+	return rest, flags
+}
+
+// workspaceOverride returns the workspace chosen by --workspace or, failing
+// that, RDXCLAW_WORKSPACE. Either one takes precedence over
+// agents.defaults.workspace in config.json and its env var.
+func workspaceOverride() string {
+	if workspaceFlag != "" {
+		return workspaceFlag
+	}
+	return os.Getenv("RDXCLAW_WORKSPACE")
+}
+
+// applyWorkspaceOverride points cfg at the overriding workspace, if any,
+// creating it when it does not exist yet.
+func applyWorkspaceOverride(cfg *config.Config) error {
+	workspace := workspaceOverride()
+	if workspace == "" {
+		return nil
+	}
+	cfg.Agents.Defaults.Workspace = workspace
+	path := cfg.WorkspacePath()
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+		cfg.Agents.Defaults.Workspace = abs
+	}
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return fmt.Errorf("workspace %s: %w", path, err)
+	}
+	return nil
+}
 
 // formatVersion returns the version string with optional git commit
 func formatVersion() string {
@@ -77,7 +156,8 @@ func formatBuildInfo() (build string, goVer string) {
 }
 
 func printVersion() {
-	fmt.Printf("%s rdxclaw %s\n", logo, formatVersion())
+	loadBranding()
+	fmt.Printf("%s rdxclaw %s\n", brand.Mark(), formatVersion())
 	build, goVer := formatBuildInfo()
 	if build != "" {
 		fmt.Printf("  Build: %s\n", build)
@@ -122,6 +202,27 @@ func copyDirectory(src, dst string) error {
 }
 
 func main() {
+	var flags globalFlags
+	os.Args, flags = parseGlobalFlags(os.Args)
+	workspaceFlag = flags.workspace
+	if err := config.SetProfile(flags.profile); err != nil {
+		fmt.Printf("Error: --profile: %v\n", err)
+		os.Exit(1)
+	}
+	if err := config.CheckProfile(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := config.SetDataDir(flags.dataDir); err != nil {
+		fmt.Printf("Error: --data-dir: %v\n", err)
+		os.Exit(1)
+	}
+	if config.DataDir() != "" {
+		if err := config.CheckDataDir(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
 	if len(os.Args) < 2 {
 		printHelp()
 		os.Exit(1)
@@ -148,6 +249,12 @@ func main() {
 		cronCmd()
 	case "swarm":
 		swarmCmd()
+	case "usage":
+		usageCmd()
+	case "knowledge":
+		knowledgeCmd()
+	case "profile":
+		profileCmd()
 	case "skills":
 		if len(os.Args) < 3 {
 			skillsHelp()
@@ -164,17 +271,29 @@ func main() {
 
 		workspace := cfg.WorkspacePath()
 		installer := skills.NewSkillInstaller(workspace)
+		trusted, err := skills.ParsePublisherKeys(cfg.Skills.TrustedPublishers)
+		if err != nil {
+			fmt.Printf("Error loading config: skills.trusted_publishers: %v\n", err)
+			os.Exit(1)
+		}
+		installer.SetTrustedPublishers(trusted)
+		installer.SetAllowUntrusted(cfg.Skills.AllowUntrusted)
 		// 获取全局配置目录和内置 skills 目录
 		globalDir := filepath.Dir(getConfigPath())
 		globalSkillsDir := filepath.Join(globalDir, "skills")
 		builtinSkillsDir := filepath.Join(globalDir, "rdxclaw", "skills")
 		skillsLoader := skills.NewSkillsLoader(workspace, globalSkillsDir, builtinSkillsDir)
+		installer.SetSkillsLoader(skillsLoader)
 
 		switch subcommand {
 		case "list":
-			skillsListCmd(skillsLoader)
+			skillsListCmd(skillsLoader, installer)
 		case "install":
 			skillsInstallCmd(installer)
+		case "sync":
+			skillsSyncCmd(installer, workspace)
+		case "check":
+			skillsCheckCmd(installer)
 		case "remove", "uninstall":
 			if len(os.Args) < 4 {
 				fmt.Println("Usage: rdxclaw skills remove <skill-name>")
@@ -207,8 +326,9 @@ func main() {
 }
 
 func printHelp() {
-	fmt.Printf("%s RDxClaw - High-Performance Agentic AI Framework v%s\n\n", logo, version)
-	fmt.Println("Usage: rdxclaw <command>")
+	loadBranding()
+	fmt.Printf("%s %s - High-Performance Agentic AI Framework v%s\n\n", brand.Mark(), brand.Name, version)
+	fmt.Println("Usage: rdxclaw [--data-dir <dir>] [--profile <name>] [--workspace <dir>] <command>")
 	fmt.Println()
 	fmt.Println("Commands:")
 	fmt.Println("  onboard     Initialize rdxclaw configuration and workspace")
@@ -221,7 +341,18 @@ func printHelp() {
 	fmt.Println("  migrate     Migrate from OpenClaw to rdxclaw")
 	fmt.Println("  skills      Manage skills (install, list, remove)")
 	fmt.Println("  swarm       Manage swarm agents (list, kill)")
+	fmt.Println("  usage       Show LLM token usage of the running server")
+	fmt.Println("  knowledge   Inspect knowledge collections (stats, compact)")
+	fmt.Println("  profile     Manage profiles (list, create)")
 	fmt.Println("  version     Show version information")
+	fmt.Println()
+	fmt.Println("Global options:")
+	fmt.Println("  --data-dir <dir>   Keep config, credentials, skills and the default workspace in <dir>")
+	fmt.Println("                     instead of ~/.rdxclaw (also RDXCLAW_DATA_DIR)")
+	fmt.Println("  --profile <name>   Use ~/.rdxclaw/profiles/<name> for config, credentials and skills")
+	fmt.Println("                     (also RDXCLAW_PROFILE)")
+	fmt.Println("  --workspace <dir>  Use this workspace instead of the configured one")
+	fmt.Println("                     (also RDXCLAW_WORKSPACE; the flag wins over the env var)")
 }
 
 func onboard() {
@@ -279,10 +410,15 @@ func onboard() {
 		os.Exit(1)
 	}
 
+	// An overriding workspace is set up but not written to config.json
+	if err := applyWorkspaceOverride(cfg); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
 	workspace := cfg.WorkspacePath()
 	createWorkspaceTemplates(workspace)
 
-	fmt.Printf("\n%s RDxClaw is ready!\n", logo)
+	fmt.Printf("\n%s %s is ready!\n", cfg.Branding.Mark(), cfg.Branding.Name)
 	if choice == "3" || (choice != "1" && choice != "2") {
 		fmt.Println("\nNext steps:")
 		fmt.Println("  1. Add your API key to", configPath)
@@ -420,6 +556,66 @@ func migrateHelp() {
 	fmt.Println("  rdxclaw migrate --force      Migrate without confirmation")
 }
 
+func profileCmd() {
+	if len(os.Args) < 3 {
+		profileHelp()
+		return
+	}
+
+	switch os.Args[2] {
+	case "list":
+		names, err := config.ListProfiles()
+		if err != nil {
+			fmt.Printf("Error listing profiles: %v\n", err)
+			os.Exit(1)
+		}
+		active := config.Profile()
+		marker := func(name string) string {
+			if name == active {
+				return "*"
+			}
+			return " "
+		}
+		fmt.Printf("%s default (%s)\n", marker(""), config.BaseDir())
+		for _, name := range names {
+			fmt.Printf("%s %s (%s)\n", marker(name), name, config.ProfileDir(name))
+		}
+	case "create":
+		if len(os.Args) < 4 {
+			fmt.Println("Usage: rdxclaw profile create <name>")
+			return
+		}
+		path, err := config.CreateProfile(os.Args[3])
+		if err != nil {
+			fmt.Printf("Error creating profile: %v\n", err)
+			os.Exit(1)
+		}
+		if err := config.SetProfile(os.Args[3]); err != nil {
+			fmt.Printf("Error creating profile: %v\n", err)
+			os.Exit(1)
+		}
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		createWorkspaceTemplates(cfg.WorkspacePath())
+		fmt.Printf("✓ Created profile '%s' with config %s\n", os.Args[3], path)
+		fmt.Printf("  Use it with: rdxclaw --profile %s agent\n", os.Args[3])
+	default:
+		fmt.Printf("Unknown profile command: %s\n", os.Args[2])
+		profileHelp()
+	}
+}
+
+func profileHelp() {
+	fmt.Println("Usage: rdxclaw profile <command>")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  list            List profiles; * marks the selected one")
+	fmt.Println("  create <name>   Create a profile with its own config, credentials, skills and workspace")
+}
+
 func swarmCmd() {
 	if len(os.Args) < 3 {
 		swarmHelp()
@@ -444,7 +640,7 @@ func swarmCmd() {
 
 	switch command {
 	case "list":
-		req, _ := http.NewRequest("GET", baseURL+"/agents", nil)
+		req, _ := http.NewRequest("GET", baseURL+"/agents?tree=true", nil)
 		if cfg.API.APIKey != "" {
 			req.Header.Set("Authorization", "Bearer "+cfg.API.APIKey)
 		}
@@ -463,13 +659,7 @@ func swarmCmd() {
 		}
 
 		var result struct {
-			Agents []struct {
-				ID      string `json:"id"`
-				Task    string `json:"task"`
-				Status  string `json:"status"`
-				Label   string `json:"label"`
-				Created int64  `json:"created"`
-			} `json:"agents"`
+			Agents []swarmAgentNode `json:"agents"`
 		}
 
 		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
@@ -483,15 +673,9 @@ func swarmCmd() {
 		}
 
 		fmt.Println("Active Swarm Agents:")
-		fmt.Printf("%-10s %-15s %-10s %s\n", "ID", "LABEL", "STATUS", "TASK")
-		fmt.Println(strings.Repeat("-", 60))
-		for _, a := range result.Agents {
-			taskPreview := a.Task
-			if len(taskPreview) > 30 {
-				taskPreview = taskPreview[:27] + "..."
-			}
-			fmt.Printf("%-10s %-15s %-10s %s\n", a.ID, a.Label, a.Status, taskPreview)
-		}
+		fmt.Printf("%-22s %-15s %-10s %s\n", "ID", "LABEL", "STATUS", "TASK")
+		fmt.Println(strings.Repeat("-", 72))
+		printSwarmAgents(result.Agents, "")
 
 	case "kill":
 		if len(os.Args) < 4 {
@@ -525,6 +709,29 @@ func swarmCmd() {
 	}
 }
 
+// swarmAgentNode is an agent as returned by GET /v1/agents?tree=true.
+type swarmAgentNode struct {
+	ID       string           `json:"id"`
+	Task     string           `json:"task"`
+	Status   string           `json:"status"`
+	Label    string           `json:"label"`
+	Created  int64            `json:"created"`
+	Children []swarmAgentNode `json:"children"`
+}
+
+// printSwarmAgents prints one row per agent, indenting spawned agents
+// beneath their parent.
+func printSwarmAgents(agents []swarmAgentNode, indent string) {
+	for _, a := range agents {
+		taskPreview := a.Task
+		if len(taskPreview) > 30 {
+			taskPreview = taskPreview[:27] + "..."
+		}
+		fmt.Printf("%-22s %-15s %-10s %s\n", indent+a.ID, a.Label, a.Status, taskPreview)
+		printSwarmAgents(a.Children, indent+"  ")
+	}
+}
+
 func swarmHelp() {
 	fmt.Println("\nManage Swarm Agents")
 	fmt.Println()
@@ -535,9 +742,165 @@ func swarmHelp() {
 	fmt.Println("  kill <id>         Terminate a running agent")
 }
 
+// knowledgeCmd inspects the workspace's knowledge collections directly on
+// disk, so it works without a running server.
+func knowledgeCmd() {
+	if len(os.Args) < 3 {
+		knowledgeHelp()
+		return
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	store, err := knowledge.NewStore(config.KnowledgeDir(cfg.WorkspacePath()))
+	if err != nil {
+		fmt.Printf("Error opening knowledge store: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch os.Args[2] {
+	case "stats":
+		if len(os.Args) < 4 {
+			fmt.Println("Usage: rdxclaw knowledge stats <collection>")
+			return
+		}
+		knowledgeStatsCmd(store, os.Args[3])
+	case "compact":
+		if len(os.Args) < 4 {
+			fmt.Println("Usage: rdxclaw knowledge compact <collection>")
+			return
+		}
+		knowledgeCompactCmd(store, os.Args[3])
+	default:
+		fmt.Printf("Unknown knowledge command: %s\n", os.Args[2])
+		knowledgeHelp()
+	}
+}
+
+func knowledgeHelp() {
+	fmt.Println("\nInspect Knowledge Collections")
+	fmt.Println()
+	fmt.Println("Usage: rdxclaw knowledge <command> [args]")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  stats <collection>   Show index statistics and the most common terms")
+	fmt.Println("  compact <collection> Rebuild the index, dropping orphaned postings")
+}
+
+func knowledgeCompactCmd(store *knowledge.Store, collection string) {
+	result, err := store.Compact(collection)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("✓ Compacted %s: %d orphaned postings removed\n", result.Collection, result.PostingsRemoved)
+	fmt.Printf("  %d → %d bytes (%d saved)\n", result.BytesBefore, result.BytesAfter, result.BytesSaved())
+}
+
+func knowledgeStatsCmd(store *knowledge.Store, collection string) {
+	stats, err := store.Stats(collection)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Collection: %s\n", stats.Name)
+	fmt.Printf("Tokenizer: %s\n", stats.Tokenizer)
+	fmt.Printf("Documents: %d\n", stats.Documents)
+	fmt.Printf("Chunks: %d\n", stats.Chunks)
+	fmt.Printf("Vocabulary: %d terms\n", stats.VocabularySize)
+	fmt.Printf("Postings: %d\n", stats.TotalPostings)
+	fmt.Printf("Tokens: %d (%.1f per chunk)\n", stats.TotalTokens, stats.AvgDocLength)
+	if len(stats.TopTerms) == 0 {
+		return
+	}
+
+	fmt.Println("\nTop terms by document frequency:")
+	fmt.Printf("%-24s %10s %10s\n", "TERM", "CHUNKS", "COUNT")
+	for _, t := range stats.TopTerms {
+		fmt.Printf("%-24s %10d %10d\n", t.Term, t.DocFreq, t.TotalFreq)
+	}
+}
+
+// usageCmd prints the per-model token usage reported by the running
+// server's /v1/status. Counters start from zero when the server restarts.
+func usageCmd() {
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	baseURL := fmt.Sprintf("http://%s:%d/v1", cfg.API.Host, cfg.API.Port)
+	if cfg.API.Host == "" {
+		baseURL = fmt.Sprintf("http://localhost:%d/v1", cfg.API.Port)
+	}
+
+	req, _ := http.NewRequest("GET", baseURL+"/status", nil)
+	if cfg.API.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+cfg.API.APIKey)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		fmt.Printf("Error connecting to server: %v\n", err)
+		os.Exit(1)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		fmt.Printf("Error: Server returned %s\n%s\n", resp.Status, string(body))
+		os.Exit(1)
+	}
+
+	var status struct {
+		Uptime string                          `json:"uptime"`
+		Usage  map[string]providers.ModelUsage `json:"usage"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		fmt.Printf("Error parsing response: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(status.Usage) == 0 {
+		fmt.Printf("No LLM requests since the server started (up %s).\n", status.Uptime)
+		return
+	}
+
+	models := make([]string, 0, len(status.Usage))
+	for model := range status.Usage {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+
+	fmt.Printf("LLM usage since the server started (up %s):\n", status.Uptime)
+	fmt.Printf("%-30s %10s %14s %14s %14s\n", "MODEL", "REQUESTS", "PROMPT", "COMPLETION", "TOTAL")
+	fmt.Println(strings.Repeat("-", 86))
+	var total providers.ModelUsage
+	for _, model := range models {
+		u := status.Usage[model]
+		fmt.Printf("%-30s %10d %14d %14d %14d\n", model, u.Requests, u.PromptTokens, u.CompletionTokens, u.TotalTokens)
+		total.Requests += u.Requests
+		total.PromptTokens += u.PromptTokens
+		total.CompletionTokens += u.CompletionTokens
+		total.TotalTokens += u.TotalTokens
+	}
+	if len(models) > 1 {
+		fmt.Println(strings.Repeat("-", 86))
+		fmt.Printf("%-30s %10d %14d %14d %14d\n", "total", total.Requests, total.PromptTokens, total.CompletionTokens, total.TotalTokens)
+	}
+}
+
 func agentCmd() {
 	message := ""
 	sessionKey := "cli:default"
+	noHistory := false
 
 	args := os.Args[2:]
 	for i := 0; i < len(args); i++ {
@@ -555,6 +918,8 @@ func agentCmd() {
 				sessionKey = args[i+1]
 				i++
 			}
+		case "--no-history":
+			noHistory = true
 		}
 	}
 
@@ -564,11 +929,7 @@ func agentCmd() {
 		os.Exit(1)
 	}
 
-	provider, err := providers.CreateProvider(cfg)
-	if err != nil {
-		fmt.Printf("Error creating provider: %v\n", err)
-		os.Exit(1)
-	}
+	provider := createProvider(cfg, false)
 
 	msgBus := bus.NewMessageBus()
 	agentLoop := agent.NewAgentLoop(cfg, msgBus, provider)
@@ -577,9 +938,9 @@ func agentCmd() {
 	startupInfo := agentLoop.GetStartupInfo()
 	logger.InfoCF("agent", "Agent initialized",
 		map[string]interface{}{
-			"tools_count":      startupInfo["tools"].(map[string]interface{})["count"],
-			"skills_total":     startupInfo["skills"].(map[string]interface{})["total"],
-			"skills_available": startupInfo["skills"].(map[string]interface{})["available"],
+			"tools_count":      startupInfo.Tools.Count,
+			"skills_total":     startupInfo.Skills.Total,
+			"skills_available": startupInfo.Skills.Available,
 		})
 
 	if message != "" {
@@ -589,32 +950,65 @@ func agentCmd() {
 			fmt.Printf("Error: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Printf("\n%s %s\n", logo, response)
+		fmt.Printf("\n%s\n", formatReply(brand, response))
 	} else {
-		fmt.Printf("%s Interactive mode (Ctrl+C to exit)\n\n", logo)
-		interactiveMode(agentLoop, sessionKey)
+		historyFile := cfg.HistoryFilePath()
+		if noHistory {
+			historyFile = ""
+		}
+		fmt.Printf("%s Interactive mode (Ctrl+C to exit)\n\n", brand.Mark())
+		interactiveMode(agentLoop, sessionKey, historyFile)
 	}
 }
 
-func interactiveMode(agentLoop *agent.AgentLoop, sessionKey string) {
-	prompt := fmt.Sprintf("%s You: ", logo)
+func interactiveMode(agentLoop *agent.AgentLoop, sessionKey, historyFile string) {
+	rl, err := newREPLReadline(historyFile, nil, nil)
+	if err != nil {
+		fmt.Printf("Error initializing readline: %v\n", err)
+		fmt.Println("Falling back to simple input mode...")
+		simpleInteractiveMode(agentLoop, sessionKey)
+		return
+	}
+	defer rl.Close()
 
-	rl, err := readline.NewEx(&readline.Config{
-		Prompt:          prompt,
-		HistoryFile:     filepath.Join(os.TempDir(), ".RDXCLAW_history"),
+	runREPL(rl, func(input string) (string, error) {
+		return agentLoop.ProcessDirect(context.Background(), input, sessionKey)
+	})
+}
+
+// newREPLReadline sets up line editing for the interactive agent. History
+// is kept in memory only when historyFile is empty or cannot be written;
+// nil stdin and stdout use the terminal.
+func newREPLReadline(historyFile string, stdin io.ReadCloser, stdout io.Writer) (*readline.Instance, error) {
+	return readline.NewEx(&readline.Config{
+		Prompt:          fmt.Sprintf("%s You: ", brand.Mark()),
+		HistoryFile:     writableHistoryFile(historyFile),
 		HistoryLimit:    100,
 		InterruptPrompt: "^C",
 		EOFPrompt:       "exit",
+		Stdin:           stdin,
+		Stdout:          stdout,
 	})
+}
 
+// writableHistoryFile returns path if history can be appended to it, and
+// "" otherwise so that readline falls back to in-memory history.
+func writableHistoryFile(path string) string {
+	if path == "" {
+		return ""
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
 	if err != nil {
-		fmt.Printf("Error initializing readline: %v\n", err)
-		fmt.Println("Falling back to simple input mode...")
-		simpleInteractiveMode(agentLoop, sessionKey)
-		return
+		logger.WarnCF("cli", "History file is not writable, keeping history in memory",
+			map[string]interface{}{"path": path, "error": err.Error()})
+		return ""
 	}
-	defer rl.Close()
+	f.Close()
+	return path
+}
 
+// runREPL reads lines until exit or EOF and prints process's reply to each.
+func runREPL(rl *readline.Instance, process func(input string) (string, error)) {
 	for {
 		line, err := rl.Readline()
 		if err != nil {
@@ -636,21 +1030,20 @@ func interactiveMode(agentLoop *agent.AgentLoop, sessionKey string) {
 			return
 		}
 
-		ctx := context.Background()
-		response, err := agentLoop.ProcessDirect(ctx, input, sessionKey)
+		response, err := process(input)
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
 			continue
 		}
 
-		fmt.Printf("\n%s %s\n\n", logo, response)
+		fmt.Printf("\n%s\n\n", formatReply(brand, response))
 	}
 }
 
 func simpleInteractiveMode(agentLoop *agent.AgentLoop, sessionKey string) {
 	reader := bufio.NewReader(os.Stdin)
 	for {
-		fmt.Print(fmt.Sprintf("%s You: ", logo))
+		fmt.Print(fmt.Sprintf("%s You: ", brand.Mark()))
 		line, err := reader.ReadString('\n')
 		if err != nil {
 			if err == io.EOF {
@@ -678,19 +1071,20 @@ func simpleInteractiveMode(agentLoop *agent.AgentLoop, sessionKey string) {
 			continue
 		}
 
-		fmt.Printf("\n%s %s\n\n", logo, response)
+		fmt.Printf("\n%s\n\n", formatReply(brand, response))
 	}
 }
 
 func gatewayCmd() {
-	// Check for --debug flag
-	args := os.Args[2:]
-	for _, arg := range args {
-		if arg == "--debug" || arg == "-d" {
-			logger.SetLevel(logger.DEBUG)
-			fmt.Println("🔍 Debug mode enabled")
-			break
-		}
+	flags, err := parseServeFlags(os.Args[2:], false)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		fmt.Println("Usage: rdxclaw gateway [--host <addr>] [--port <port>] [--debug]")
+		os.Exit(1)
+	}
+	if flags.debug {
+		logger.SetLevel(logger.DEBUG)
+		fmt.Println("🔍 Debug mode enabled")
 	}
 
 	cfg, err := loadConfig()
@@ -698,12 +1092,9 @@ func gatewayCmd() {
 		fmt.Printf("Error loading config: %v\n", err)
 		os.Exit(1)
 	}
+	flags.apply(&cfg.Gateway.Host, &cfg.Gateway.Port)
 
-	provider, err := providers.CreateProvider(cfg)
-	if err != nil {
-		fmt.Printf("Error creating provider: %v\n", err)
-		os.Exit(1)
-	}
+	provider := createProvider(cfg, true)
 
 	msgBus := bus.NewMessageBus()
 	agentLoop := agent.NewAgentLoop(cfg, msgBus, provider)
@@ -711,23 +1102,21 @@ func gatewayCmd() {
 	// Print agent startup info
 	fmt.Println("\n📦 Agent Status:")
 	startupInfo := agentLoop.GetStartupInfo()
-	toolsInfo := startupInfo["tools"].(map[string]interface{})
-	skillsInfo := startupInfo["skills"].(map[string]interface{})
-	fmt.Printf("  • Tools: %d loaded\n", toolsInfo["count"])
+	fmt.Printf("  • Tools: %d loaded\n", startupInfo.Tools.Count)
 	fmt.Printf("  • Skills: %d/%d available\n",
-		skillsInfo["available"],
-		skillsInfo["total"])
+		startupInfo.Skills.Available,
+		startupInfo.Skills.Total)
 
 	// Log to file as well
 	logger.InfoCF("agent", "Agent initialized",
 		map[string]interface{}{
-			"tools_count":      toolsInfo["count"],
-			"skills_total":     skillsInfo["total"],
-			"skills_available": skillsInfo["available"],
+			"tools_count":      startupInfo.Tools.Count,
+			"skills_total":     startupInfo.Skills.Total,
+			"skills_available": startupInfo.Skills.Available,
 		})
 
 	// Setup cron tool and service
-	cronService := setupCronTool(agentLoop, msgBus, cfg.WorkspacePath(), cfg.Agents.Defaults.RestrictToWorkspace)
+	cronService, cronTool := setupCronTool(agentLoop, msgBus, cfg.WorkspacePath(), cfg.Agents.Defaults.RestrictToWorkspace, cfg.Tools.Cron.MaxAgentJobs)
 
 	heartbeatService := heartbeat.NewHeartbeatService(
 		cfg.WorkspacePath(),
@@ -761,6 +1150,8 @@ func gatewayCmd() {
 
 	// Inject channel manager into agent loop for command handling
 	agentLoop.SetChannelManager(channelManager)
+	// Deliver cron output directly so failures show up in the job history
+	cronTool.SetSender(channelManager)
 
 	var transcriber *voice.GroqTranscriber
 	if cfg.Providers.Groq.APIKey != "" {
@@ -796,11 +1187,12 @@ func gatewayCmd() {
 		fmt.Println("⚠ Warning: No channels enabled")
 	}
 
-	fmt.Printf("✓ Gateway started on %s:%d\n", cfg.Gateway.Host, cfg.Gateway.Port)
+	fmt.Printf("✓ Gateway started on %s\n", bindAddress(cfg.Gateway.Host, cfg.Gateway.Port))
 	fmt.Println("Press Ctrl+C to stop")
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
+	logger.WatchLevelSignals(ctx)
 
 	if err := cronService.Start(); err != nil {
 		fmt.Printf("Error starting cron service: %v\n", err)
@@ -824,67 +1216,209 @@ func gatewayCmd() {
 		fmt.Println("✓ Device event service started")
 	}
 
-	if err := channelManager.StartAll(ctx); err != nil {
-		fmt.Printf("Error starting channels: %v\n", err)
+	if cfg.API.Enabled && cfg.API.Port == cfg.Gateway.Port {
+		fmt.Printf("⚠️  Warning: gateway and API server are both configured for port %d; only one of them can run at a time\n", cfg.Gateway.Port)
 	}
 
+	// /ready stays false until the provider answers a health check; channels
+	// consult the same signal and tell users when the provider is down.
 	healthServer := health.NewServer(cfg.Gateway.Host, cfg.Gateway.Port)
+	healthServer.SetCheck("provider", false, "pending")
+	channelManager.SetReadiness(healthServer)
+	channelManager.SetEventHandler(printChannelEvent)
+	go healthServer.Monitor(ctx, "provider", providerCheckInterval, providerCheck(provider))
 	go func() {
 		if err := healthServer.Start(); err != nil && err != http.ErrServerClosed {
 			logger.ErrorCF("health", "Health server error", map[string]interface{}{"error": err.Error()})
 		}
 	}()
-	fmt.Printf("✓ Health endpoints available at http://%s:%d/health and /ready\n", cfg.Gateway.Host, cfg.Gateway.Port)
+	fmt.Printf("✓ Health endpoints available at http://%s/health and /ready\n", bindAddress(cfg.Gateway.Host, cfg.Gateway.Port))
+
+	go func() {
+		if ready, reason := healthServer.IsReady(); !ready {
+			fmt.Printf("⏳ Waiting for provider before starting channels (%s)\n", reason)
+		}
+		if err := healthServer.WaitReady(ctx); err != nil {
+			return
+		}
+		if err := channelManager.StartAll(ctx); err != nil {
+			fmt.Printf("Error starting channels: %v\n", err)
+		}
+	}()
 
 	go agentLoop.Run(ctx)
 
 	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 	<-sigChan
 
 	fmt.Println("\nShutting down...")
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancelShutdown()
+	unfinished := shutdown(shutdownCtx, shutdownStageTimeout,
+		// Stop whatever feeds the agent new work
+		[]shutdownStep{
+			{name: "health", stop: healthServer.Stop},
+			step("devices", deviceService.Stop),
+			step("heartbeat", heartbeatService.Stop),
+			step("cron", cronService.Stop),
+		},
+		// Let the agent finish its current message; the reply still needs
+		// the channels and the bus
+		[]shutdownStep{{name: "agent", stop: agentLoop.Drain}},
+		[]shutdownStep{{name: "channels", stop: channelManager.StopAll}},
+		[]shutdownStep{step("bus", msgBus.Close)},
+	)
 	cancel()
-	healthServer.Stop(context.Background())
-	deviceService.Stop()
-	heartbeatService.Stop()
-	cronService.Stop()
-	agentLoop.Stop()
-	channelManager.StopAll(ctx)
-	fmt.Println("✓ Gateway stopped")
+	reportShutdown("Gateway", unfinished)
 }
 
-func serverCmd() {
-	// Parse args
-	args := os.Args[2:]
-	for _, arg := range args {
-		if arg == "--debug" || arg == "-d" {
-			logger.SetLevel(logger.DEBUG)
-			fmt.Println("🔍 Debug mode enabled")
+// providerCheckInterval is how often the gateway and API server re-check
+// that the LLM provider is reachable.
+const providerCheckInterval = 30 * time.Second
+
+// statusCheckTimeout bounds the provider health check of the status command.
+const statusCheckTimeout = 5 * time.Second
+
+// createProvider creates the configured LLM provider, exiting on failure.
+// When no provider is configured and allowMissing is set, it warns and
+// returns a placeholder that fails every call instead, so a server can
+// start and report the problem on /ready.
+func createProvider(cfg *config.Config, allowMissing bool) providers.LLMProvider {
+	provider, err := providers.CreateProvider(cfg)
+	switch {
+	case err == nil:
+		return provider
+	case errors.Is(err, providers.ErrNoProviderConfigured) && allowMissing:
+		fmt.Printf("⚠️  %s\n", providers.DescribeHealth(err))
+		return providers.NewUnconfiguredProvider(err)
+	case errors.Is(err, providers.ErrNoProviderConfigured):
+		fmt.Printf("Error: %s\n", providers.DescribeHealth(err))
+	default:
+		fmt.Printf("Error creating provider: %v\n", err)
+	}
+	os.Exit(1)
+	return nil
+}
+
+// providerCheck is the readiness check for provider. Its failures say
+// whether the provider needs configuring or is temporarily unavailable.
+func providerCheck(provider providers.LLMProvider) func(context.Context) error {
+	return func(ctx context.Context) error {
+		if err := providers.CheckHealth(ctx, provider); err != nil {
+			return errors.New(providers.DescribeHealth(err))
 		}
+		return nil
 	}
+}
 
-	cfg, err := loadConfig()
+// providerStatus reports whether the configured provider can serve
+// requests, for the status command.
+func providerStatus(ctx context.Context, cfg *config.Config) string {
+	provider, err := providers.CreateProvider(cfg)
+	if err == nil {
+		err = providers.CheckHealth(ctx, provider)
+	}
 	if err != nil {
-		fmt.Printf("Error loading config: %v\n", err)
-		os.Exit(1)
+		return "✗ " + providers.DescribeHealth(err)
 	}
+	return "✓"
+}
 
-	// Override config with flags if provided
-	// (Simple argument parsing for now, could be improved with flag package)
+// serveFlags are the overrides accepted by the long-running gateway and
+// server commands. Unset flags leave the config alone.
+type serveFlags struct {
+	host   string
+	port   int
+	apiKey string
+	debug  bool
+}
+
+// parseServeFlags parses --host, --port, --debug (-d) and, when withAPIKey
+// is set, --api-key. Values may follow as the next argument or after "=".
+func parseServeFlags(args []string, withAPIKey bool) (serveFlags, error) {
+	var flags serveFlags
 	for i := 0; i < len(args); i++ {
-		if args[i] == "--port" && i+1 < len(args) {
-			fmt.Sscanf(args[i+1], "%d", &cfg.API.Port)
-			i++
+		arg := args[i]
+		if arg == "--debug" || arg == "-d" {
+			flags.debug = true
+			continue
 		}
-		if args[i] == "--host" && i+1 < len(args) {
-			cfg.API.Host = args[i+1]
-			i++
+
+		name, value, hasValue := strings.Cut(arg, "=")
+		if name != "--host" && name != "--port" && (name != "--api-key" || !withAPIKey) {
+			return flags, fmt.Errorf("unknown flag %q", arg)
 		}
-		if args[i] == "--api-key" && i+1 < len(args) {
-			cfg.API.APIKey = args[i+1]
+		if !hasValue {
+			if i+1 >= len(args) {
+				return flags, fmt.Errorf("%s requires a value", name)
+			}
 			i++
+			value = args[i]
+		}
+
+		switch name {
+		case "--host":
+			flags.host = value
+		case "--port":
+			port, err := strconv.Atoi(value)
+			if err != nil || port < 1 || port > 65535 {
+				return flags, fmt.Errorf("invalid port %q: must be a number between 1 and 65535", value)
+			}
+			flags.port = port
+		case "--api-key":
+			flags.apiKey = value
 		}
 	}
+	return flags, nil
+}
+
+// apply overrides host and port with the flags that were given.
+func (f serveFlags) apply(host *string, port *int) {
+	if f.host != "" {
+		*host = f.host
+	}
+	if f.port != 0 {
+		*port = f.port
+	}
+}
+
+// bindAddress formats host and port for display, bracketing IPv6 hosts.
+func bindAddress(host string, port int) string {
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// printChannelEvent reports a channel losing or regaining its connection.
+func printChannelEvent(e channels.ChannelEvent) {
+	switch e.Type {
+	case channels.EventDisconnected:
+		fmt.Printf("⚠️  %s disconnected (%s); reconnecting\n", e.Channel, e.Error)
+	case channels.EventReconnected:
+		fmt.Printf("✓ %s reconnected after %d attempt(s)\n", e.Channel, e.Attempts)
+	}
+}
+
+func serverCmd() {
+	flags, err := parseServeFlags(os.Args[2:], true)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		fmt.Println("Usage: rdxclaw server [--host <addr>] [--port <port>] [--api-key <key>] [--debug]")
+		os.Exit(1)
+	}
+	if flags.debug {
+		logger.SetLevel(logger.DEBUG)
+		fmt.Println("🔍 Debug mode enabled")
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	flags.apply(&cfg.API.Host, &cfg.API.Port)
+	if flags.apiKey != "" {
+		cfg.API.APIKey = flags.apiKey
+	}
 
 	// Override with env var if flag not set and config empty
 	if cfg.API.APIKey == "" {
@@ -896,11 +1430,7 @@ func serverCmd() {
 		cfg.API.Enabled = true
 	}
 
-	provider, err := providers.CreateProvider(cfg)
-	if err != nil {
-		fmt.Printf("Error creating provider: %v\n", err)
-		os.Exit(1)
-	}
+	provider := createProvider(cfg, true)
 
 	msgBus := bus.NewMessageBus()
 	agentLoop := agent.NewAgentLoop(cfg, msgBus, provider)
@@ -913,7 +1443,7 @@ func serverCmd() {
 	skillsLoader := skills.NewSkillsLoader(workspace, globalSkillsDir, builtinSkillsDir)
 
 	// Setup cron service
-	cronService := setupCronTool(agentLoop, msgBus, cfg.WorkspacePath(), cfg.Agents.Defaults.RestrictToWorkspace)
+	cronService, _ := setupCronTool(agentLoop, msgBus, cfg.WorkspacePath(), cfg.Agents.Defaults.RestrictToWorkspace, cfg.Tools.Cron.MaxAgentJobs)
 
 	// Setup heartbeat
 	heartbeatService := heartbeat.NewHeartbeatService(
@@ -932,18 +1462,44 @@ func serverCmd() {
 	})
 
 	serverConfig := api.ServerConfig{
-		Host:        cfg.API.Host,
-		Port:        cfg.API.Port,
-		APIKey:      cfg.API.APIKey,
-		RateLimit:   cfg.API.RateLimit,
-		CORSOrigins: cfg.API.CORSOrigins,
+		Host:           cfg.API.Host,
+		Port:           cfg.API.Port,
+		APIKey:         cfg.API.APIKey,
+		APIKeys:        cfg.API.APIKeys,
+		RateLimit:      cfg.API.RateLimit,
+		CORSOrigins:    cfg.API.CORSOrigins,
+		UICORSOrigins:  cfg.API.UICORSOrigins,
+		EventRetention: cfg.API.EventRetention,
+		Build: api.BuildInfo{
+			Version:   version,
+			GitCommit: gitCommit,
+			BuildTime: buildTime,
+			GoVersion: goVersion,
+		},
+		WebhookReplaySize: cfg.API.WebhookReplaySize,
+		RequestTimeout:    time.Duration(cfg.API.RequestTimeout) * time.Second,
+		ChatTimeout:       time.Duration(cfg.API.ChatTimeout) * time.Second,
+		SkillTimeout:      time.Duration(cfg.API.SkillTimeout) * time.Second,
+		WebhookTimeout:    time.Duration(cfg.API.WebhookTimeout) * time.Second,
+		MaxUploadBytes:    int64(cfg.API.MaxUploadMB) << 20,
+		MaxWebhookBytes:   int64(cfg.API.MaxWebhookKB) << 10,
+		ShutdownDelay:     time.Duration(cfg.API.ShutdownDelay) * time.Second,
+		Models:            configuredModels(cfg),
+		Alerts: api.AlertConfig{
+			Threshold:  cfg.API.Alerts.Threshold,
+			Window:     time.Duration(cfg.API.Alerts.Window) * time.Second,
+			Cooldown:   time.Duration(cfg.API.Alerts.Cooldown) * time.Second,
+			WebhookURL: cfg.API.Alerts.WebhookURL,
+			Channel:    cfg.API.Alerts.Channel,
+			ChatID:     cfg.API.Alerts.ChatID,
+		},
 	}
 
 	srv := api.NewServer(agentLoop, msgBus, skillsLoader, serverConfig)
 
-	fmt.Printf("%s RDxClaw API Server v%s\n", logo, version)
-	fmt.Printf("✓ Listening on %s:%d\n", cfg.API.Host, cfg.API.Port)
-	if cfg.API.APIKey != "" {
+	fmt.Printf("%s %s API Server v%s\n", brand.Mark(), brand.Name, version)
+	fmt.Printf("✓ Listening on %s\n", bindAddress(cfg.API.Host, cfg.API.Port))
+	if cfg.API.APIKey != "" || len(cfg.API.APIKeys) > 0 {
 		fmt.Println("🔒 API Key protection enabled")
 	} else {
 		fmt.Println("⚠️  Warning: No API Key configured (running in insecure mode)")
@@ -960,25 +1516,42 @@ func serverCmd() {
 	// Start agent loop in background
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
+	logger.WatchLevelSignals(ctx)
 	go agentLoop.Run(ctx)
+	go srv.Health().Monitor(ctx, "provider", providerCheckInterval, providerCheck(provider))
 
-	// Setup graceful shutdown
+	serveErr := make(chan error, 1)
 	go func() {
-		sigChan := make(chan os.Signal, 1)
-		signal.Notify(sigChan, os.Interrupt)
-		<-sigChan
-		fmt.Println("\nShutting down...")
-		cronService.Stop()
-		heartbeatService.Stop()
-		agentLoop.Stop()
-		os.Exit(0)
+		serveErr <- srv.Start()
 	}()
 
-	// Start server (blocks)
-	if err := srv.Start(); err != nil {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	select {
+	case err := <-serveErr:
 		fmt.Printf("Server error: %v\n", err)
 		os.Exit(1)
+	case <-sigChan:
 	}
+
+	fmt.Println("\nShutting down...")
+	// Requests in flight may run for as long as their own timeouts, which
+	// are usually longer than a shutdown stage
+	drain := srv.DrainTimeout()
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout+drain)
+	defer cancelShutdown()
+	unfinished := shutdown(shutdownCtx, shutdownStageTimeout,
+		// Stop taking new work; requests in flight, chat completions
+		// included, get to finish
+		[]shutdownStep{
+			{name: "api", stop: srv.Stop, timeout: drain},
+			step("cron", cronService.Stop),
+			step("heartbeat", heartbeatService.Stop),
+		},
+		[]shutdownStep{{name: "agent", stop: agentLoop.Drain}},
+	)
+	cancel()
+	reportShutdown("Server", unfinished)
 }
 
 func statusCmd() {
@@ -990,7 +1563,7 @@ func statusCmd() {
 
 	configPath := getConfigPath()
 
-	fmt.Printf("%s rdxclaw Status\n", logo)
+	fmt.Printf("%s %s Status\n", brand.Mark(), brand.Name)
 	fmt.Printf("Version: %s\n", formatVersion())
 	build, _ := formatBuildInfo()
 	if build != "" {
@@ -1014,6 +1587,10 @@ func statusCmd() {
 	if _, err := os.Stat(configPath); err == nil {
 		fmt.Printf("Model: %s\n", cfg.Agents.Defaults.Model)
 
+		ctx, cancel := context.WithTimeout(context.Background(), statusCheckTimeout)
+		fmt.Println("Provider:", providerStatus(ctx, cfg))
+		cancel()
+
 		hasOpenRouter := cfg.Providers.OpenRouter.APIKey != ""
 		hasAnthropic := cfg.Providers.Anthropic.APIKey != ""
 		hasOpenAI := cfg.Providers.OpenAI.APIKey != ""
@@ -1054,6 +1631,55 @@ func statusCmd() {
 			}
 		}
 	}
+
+	printSkillsHealth(workspace)
+	printChannelStatus(workspace)
+}
+
+// printSkillsHealth lists workspace skills that cannot run as installed.
+func printSkillsHealth(workspace string) {
+	reports, err := skills.NewSkillInstaller(workspace).CheckAllHealth()
+	if err != nil || len(reports) == 0 {
+		return
+	}
+
+	var unhealthy []skills.HealthReport
+	for _, report := range reports {
+		if !report.Healthy() {
+			unhealthy = append(unhealthy, report)
+		}
+	}
+	fmt.Printf("\nSkills: %d installed, %d unhealthy\n", len(reports), len(unhealthy))
+	for _, report := range unhealthy {
+		fmt.Printf("  %s: %s\n", report.Skill, strings.Join(report.Problems, "; "))
+	}
+}
+
+// printChannelStatus shows the channel states last persisted by the gateway.
+func printChannelStatus(workspace string) {
+	status, err := channels.LoadChannelStatus(workspace)
+	if err != nil {
+		fmt.Printf("\nChannels: %v\n", err)
+		return
+	}
+	if len(status) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(status))
+	for name := range status {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Println("\nChannels:")
+	for _, name := range names {
+		st := status[name]
+		fmt.Printf("  %s: %s (since %s)\n", name, st.State, st.UpdatedAt.Format(time.RFC3339))
+		if st.LastError != "" && st.LastErrorAt != nil {
+			fmt.Printf("    last error at %s: %s\n", st.LastErrorAt.Format(time.RFC3339), st.LastError)
+		}
+	}
 }
 
 func authCmd() {
@@ -1275,19 +1901,41 @@ func authStatusCmd() {
 	}
 }
 
+// getConfigPath returns the config of the selected profile, or
+// ~/.rdxclaw/config.json without one.
 func getConfigPath() string {
-	home, _ := os.UserHomeDir()
-	return filepath.Join(home, ".rdxclaw", "config.json")
+	return filepath.Join(config.HomeDir(), "config.json")
+}
+
+// configuredModels returns the per-channel models and the models declared
+// in config, sorted, for GET /v1/models.
+func configuredModels(cfg *config.Config) []string {
+	seen := map[string]bool{}
+	for _, override := range cfg.Agents.Channels {
+		if override.Model != "" {
+			seen[override.Model] = true
+		}
+	}
+	for name := range cfg.Models {
+		seen[name] = true
+	}
+	models := make([]string, 0, len(seen))
+	for name := range seen {
+		models = append(models, name)
+	}
+	sort.Strings(models)
+	return models
 }
 
-func setupCronTool(agentLoop *agent.AgentLoop, msgBus *bus.MessageBus, workspace string, restrict bool) *cron.CronService {
-	cronStorePath := filepath.Join(workspace, "cron", "jobs.json")
+func setupCronTool(agentLoop *agent.AgentLoop, msgBus *bus.MessageBus, workspace string, restrict bool, maxAgentJobs int) (*cron.CronService, *tools.CronTool) {
+	cronStorePath := config.CronStorePath(workspace)
 
 	// Create cron service
 	cronService := cron.NewCronService(cronStorePath, nil)
 
 	// Create and register CronTool
 	cronTool := tools.NewCronTool(cronService, agentLoop, msgBus, workspace, restrict)
+	cronTool.SetMaxAgentJobs(maxAgentJobs)
 	agentLoop.RegisterTool(cronTool)
 
 	// Set the onJob handler
@@ -1296,11 +1944,36 @@ func setupCronTool(agentLoop *agent.AgentLoop, msgBus *bus.MessageBus, workspace
 		return result, nil
 	})
 
-	return cronService
+	return cronService, cronTool
 }
 
 func loadConfig() (*config.Config, error) {
-	return config.LoadConfig(getConfigPath())
+	if profile := config.Profile(); profile != "" {
+		if _, err := os.Stat(getConfigPath()); os.IsNotExist(err) {
+			return nil, fmt.Errorf("profile %q does not exist; create it with: rdxclaw profile create %s", profile, profile)
+		}
+	}
+	cfg, err := config.LoadConfig(getConfigPath())
+	if err != nil {
+		return nil, err
+	}
+	if err := logger.SetComponentLevels(cfg.Logging.Components); err != nil {
+		return nil, fmt.Errorf("logging.components: %w", err)
+	}
+	if err := applyWorkspaceOverride(cfg); err != nil {
+		return nil, err
+	}
+	brand = cfg.Branding
+	return cfg, nil
+}
+
+// loadBranding applies the configured branding for output that is printed
+// without loading the rest of the config, such as the help. If the config
+// cannot be read the defaults are kept.
+func loadBranding() {
+	if cfg, err := config.LoadConfig(getConfigPath()); err == nil && cfg.Branding.Name != "" {
+		brand = cfg.Branding
+	}
 }
 
 func cronCmd() {
@@ -1318,7 +1991,7 @@ func cronCmd() {
 		return
 	}
 
-	cronStorePath := filepath.Join(cfg.WorkspacePath(), "cron", "jobs.json")
+	cronStorePath := config.CronStorePath(cfg.WorkspacePath())
 
 	switch subcommand {
 	case "list":
@@ -1327,7 +2000,12 @@ func cronCmd() {
 		cronAddCmd(cronStorePath)
 	case "remove":
 		if len(os.Args) < 4 {
-			fmt.Println("Usage: rdxclaw cron remove <job_id>")
+			fmt.Println("Usage: rdxclaw cron remove <job_id> | --agent")
+			return
+		}
+		if os.Args[3] == "--agent" {
+			n := cron.NewCronService(cronStorePath, nil).RemoveJobsCreatedBy(cron.CreatedByAgent)
+			fmt.Printf("✓ Removed %d agent-created job(s)\n", n)
 			return
 		}
 		cronRemoveCmd(cronStorePath, os.Args[3])
@@ -1346,6 +2024,7 @@ func cronHelp() {
 	fmt.Println("  list              List all scheduled jobs")
 	fmt.Println("  add              Add a new scheduled job")
 	fmt.Println("  remove <id>       Remove a job by ID")
+	fmt.Println("  remove --agent    Remove all jobs the agent created")
 	fmt.Println("  enable <id>      Enable a job")
 	fmt.Println("  disable <id>     Disable a job")
 	fmt.Println()
@@ -1357,6 +2036,8 @@ func cronHelp() {
 	fmt.Println("  -d, --deliver     Deliver response to channel")
 	fmt.Println("  --to             Recipient for delivery")
 	fmt.Println("  --channel        Channel for delivery")
+	fmt.Println("  --deliver-to     Delivery target as channel:recipient (repeatable)")
+	fmt.Println("  --catch-up       Missed runs on startup: skip (default), run-once, run-all")
 }
 
 func cronListCmd(storePath string) {
@@ -1395,6 +2076,27 @@ func cronListCmd(storePath string) {
 		fmt.Printf("    Schedule: %s\n", schedule)
 		fmt.Printf("    Status: %s\n", status)
 		fmt.Printf("    Next run: %s\n", nextRun)
+		if job.CatchUp != "" {
+			fmt.Printf("    Catch-up: %s\n", job.CatchUp)
+		}
+		if job.CreatedBy != "" {
+			fmt.Printf("    Created by: %s\n", job.CreatedBy)
+		}
+		if len(job.Payload.Targets) > 0 {
+			targets := make([]string, len(job.Payload.Targets))
+			for i, target := range job.Payload.Targets {
+				targets[i] = target.String()
+			}
+			fmt.Printf("    Deliver to: %s\n", strings.Join(targets, ", "))
+		}
+		if job.State.LastStatus != "" {
+			fmt.Printf("    Last run: %s\n", job.State.LastStatus)
+			for _, d := range job.State.LastDeliveries {
+				if d.Status != "ok" {
+					fmt.Printf("      %s:%s failed: %s\n", d.Channel, d.To, d.Error)
+				}
+			}
+		}
 	}
 }
 
@@ -1406,6 +2108,8 @@ func cronAddCmd(storePath string) {
 	deliver := false
 	channel := ""
 	to := ""
+	var targets []cron.DeliveryTarget
+	catchUp := ""
 
 	args := os.Args[3:]
 	for i := 0; i < len(args); i++ {
@@ -1444,9 +2148,29 @@ func cronAddCmd(storePath string) {
 				channel = args[i+1]
 				i++
 			}
+		case "--deliver-to":
+			if i+1 < len(args) {
+				target, err := cron.ParseDeliveryTarget(args[i+1])
+				if err != nil {
+					fmt.Printf("Error: %v\n", err)
+					return
+				}
+				targets = append(targets, target)
+				i++
+			}
+		case "--catch-up":
+			if i+1 < len(args) {
+				catchUp = args[i+1]
+				i++
+			}
 		}
 	}
 
+	if !cron.ValidCatchUp(catchUp) {
+		fmt.Printf("Error: unknown --catch-up policy %q (use skip, run-once or run-all)\n", catchUp)
+		return
+	}
+
 	if name == "" {
 		fmt.Println("Error: --name is required")
 		return
@@ -1476,12 +2200,26 @@ func cronAddCmd(storePath string) {
 		}
 	}
 
+	if len(targets) == 0 {
+		targets = []cron.DeliveryTarget{{Channel: channel, To: to}}
+	} else if channel != "" || to != "" {
+		fmt.Println("Error: --deliver-to cannot be combined with --channel/--to")
+		return
+	}
+
 	cs := cron.NewCronService(storePath, nil)
-	job, err := cs.AddJob(name, schedule, message, deliver, channel, to)
+	job, err := cs.AddJobTargets(name, schedule, message, deliver, targets)
 	if err != nil {
 		fmt.Printf("Error adding job: %v\n", err)
 		return
 	}
+	if catchUp != "" {
+		job.CatchUp = catchUp
+		if err := cs.UpdateJob(job); err != nil {
+			fmt.Printf("Error saving catch-up policy: %v\n", err)
+			return
+		}
+	}
 
 	fmt.Printf("✓ Added job '%s' (%s)\n", job.Name, job.ID)
 }
@@ -1520,7 +2258,9 @@ func cronEnableCmd(storePath string, disable bool) {
 func skillsHelp() {
 	fmt.Println("\nSkills commands:")
 	fmt.Println("  list                    List installed skills")
-	fmt.Println("  install <repo>          Install skill from GitHub")
+	fmt.Println("  install <repo>          Install skill from GitHub (--timeout 5m, --allow-untrusted)")
+	fmt.Println("  sync                    Install, update and remove skills to match skills.lock (--yes)")
+	fmt.Println("  check [name|--all]      Check that installed skills can run")
 	fmt.Println("  install-builtin          Install all builtin skills to workspace")
 	fmt.Println("  list-builtin             List available builtin skills")
 	fmt.Println("  remove <name>           Remove installed skill")
@@ -1530,12 +2270,15 @@ func skillsHelp() {
 	fmt.Println("Examples:")
 	fmt.Println("  rdxclaw skills list")
 	fmt.Println("  rdxclaw skills install Sterlites/rdxclaw-skills/weather")
+	fmt.Println("  rdxclaw skills install Sterlites/rdxclaw-skills/weather@v1.2.0")
+	fmt.Println("  rdxclaw skills sync")
+	fmt.Println("  rdxclaw skills check weather")
 	fmt.Println("  rdxclaw skills install-builtin")
 	fmt.Println("  rdxclaw skills list-builtin")
 	fmt.Println("  rdxclaw skills remove weather")
 }
 
-func skillsListCmd(loader *skills.SkillsLoader) {
+func skillsListCmd(loader *skills.SkillsLoader, installer *skills.SkillInstaller) {
 	allSkills := loader.ListSkills()
 
 	if len(allSkills) == 0 {
@@ -1546,32 +2289,230 @@ func skillsListCmd(loader *skills.SkillsLoader) {
 	fmt.Println("\nInstalled Skills:")
 	fmt.Println("------------------")
 	for _, skill := range allSkills {
-		fmt.Printf("  ✓ %s (%s)\n", skill.Name, skill.Source)
+		// Only workspace skills are checked; the others ship with rdxclaw.
+		mark := "✓"
+		var problems []string
+		if skill.Source == "workspace" {
+			if report, err := installer.CheckHealth(skill.QualifiedName); err == nil && !report.Healthy() {
+				mark, problems = "⚠", report.Problems
+			}
+		}
+		if skill.Version != "" {
+			fmt.Printf("  %s %s v%s (%s)\n", mark, skill.Name, strings.TrimPrefix(skill.Version, "v"), skill.Source)
+		} else {
+			fmt.Printf("  %s %s (%s)\n", mark, skill.Name, skill.Source)
+		}
+		for _, p := range problems {
+			fmt.Printf("    ✗ %s\n", p)
+		}
 		if skill.Description != "" {
 			fmt.Printf("    %s\n", skill.Description)
 		}
+		if skill.Author != "" {
+			fmt.Printf("    Author: %s\n", skill.Author)
+		}
+		if len(skill.Tags) > 0 {
+			fmt.Printf("    Tags: %s\n", strings.Join(skill.Tags, ", "))
+		}
 	}
 }
 
 func skillsInstallCmd(installer *skills.SkillInstaller) {
 	if len(os.Args) < 4 {
-		fmt.Println("Usage: rdxclaw skills install <github-repo>")
-		fmt.Println("Example: rdxclaw skills install Sterlites/rdxclaw-skills/weather")
+		fmt.Println("Usage: rdxclaw skills install <github-repo> [--timeout <duration>] [--allow-untrusted]")
+		fmt.Println("Example: rdxclaw skills install Sterlites/rdxclaw-skills/weather --timeout 10m")
 		return
 	}
 
 	repo := os.Args[3]
+	timeout := skills.DefaultInstallTimeout
+	args := os.Args[4:]
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--timeout", "-t":
+			if i+1 < len(args) {
+				d, err := parseInstallTimeout(args[i+1])
+				if err != nil {
+					fmt.Printf("Invalid --timeout %q: %v\n", args[i+1], err)
+					os.Exit(1)
+				}
+				timeout = d
+				i++
+			}
+		case "--allow-untrusted":
+			installer.SetAllowUntrusted(true)
+		}
+	}
+
 	fmt.Printf("Installing skill from %s...\n", repo)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	progress := installProgressPrinter()
+	installer.SetProgress(progress)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	if _, err := installer.InstallFromGitHub(ctx, repo); err != nil {
-		fmt.Printf("✗ Failed to install skill: %v\n", err)
+	result, err := installer.InstallFromGitHub(ctx, repo)
+	if progress != nil {
+		fmt.Print("\r\033[K") // clear the progress line
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, skills.ErrInstallTimeout):
+			fmt.Printf("✗ Install timed out after %s. Nothing was installed; retry with a longer --timeout.\n", timeout)
+		case errors.Is(err, skills.ErrUnsignedSkill), errors.Is(err, skills.ErrUntrustedPublisher):
+			fmt.Printf("✗ %v. Skills run scripts on this machine; rerun with --allow-untrusted to install it anyway, or add its publisher to skills.trusted_publishers.\n", err)
+		default:
+			fmt.Printf("✗ Failed to install skill: %v\n", err)
+		}
 		os.Exit(1)
 	}
 
 	fmt.Printf("✓ Skill '%s' installed successfully!\n", filepath.Base(repo))
+	if result.Publisher != "" {
+		fmt.Printf("  Signed by trusted publisher: %s\n", result.Publisher)
+	}
+}
+
+// installProgressPrinter renders install progress on a single terminal line.
+// It returns nil when stdout is not a terminal, so piped output stays clean.
+func installProgressPrinter() skills.ProgressFunc {
+	if fi, err := os.Stdout.Stat(); err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return nil
+	}
+
+	var last time.Time
+	return func(p skills.InstallProgress) {
+		if time.Since(last) < 100*time.Millisecond {
+			return
+		}
+		last = time.Now()
+
+		switch p.Phase {
+		case skills.PhaseDownload:
+			if p.BytesTotal > 0 {
+				fmt.Printf("\r\033[K  Downloading %.1f / %.1f MB", float64(p.BytesDownloaded)/1024/1024, float64(p.BytesTotal)/1024/1024)
+			} else {
+				fmt.Printf("\r\033[K  Downloading %.1f MB", float64(p.BytesDownloaded)/1024/1024)
+			}
+		case skills.PhaseExtract:
+			fmt.Printf("\r\033[K  Extracting %d files", p.FilesExtracted)
+		}
+	}
+}
+
+// parseInstallTimeout accepts a Go duration ("90s", "10m") or a plain
+// number of seconds.
+func parseInstallTimeout(s string) (time.Duration, error) {
+	if secs, err := strconv.Atoi(s); err == nil {
+		s = fmt.Sprintf("%ds", secs)
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("must be positive")
+	}
+	return d, nil
+}
+
+func skillsCheckCmd(installer *skills.SkillInstaller) {
+	var reports []skills.HealthReport
+	if len(os.Args) < 4 || os.Args[3] == "--all" {
+		all, err := installer.CheckAllHealth()
+		if err != nil {
+			fmt.Printf("✗ Failed to check skills: %v\n", err)
+			os.Exit(1)
+		}
+		if len(all) == 0 {
+			fmt.Println("No skills installed.")
+			return
+		}
+		reports = all
+	} else {
+		report, err := installer.CheckHealth(os.Args[3])
+		if err != nil {
+			fmt.Printf("✗ %v\n", err)
+			os.Exit(1)
+		}
+		reports = []skills.HealthReport{report}
+	}
+
+	unhealthy := 0
+	for _, report := range reports {
+		if report.Healthy() {
+			fmt.Printf("  ✓ %s\n", report.Skill)
+			continue
+		}
+		unhealthy++
+		fmt.Printf("  ✗ %s\n", report.Skill)
+		for _, p := range report.Problems {
+			fmt.Printf("    - %s\n", p)
+		}
+	}
+	if unhealthy > 0 {
+		os.Exit(1)
+	}
+}
+
+func skillsSyncCmd(installer *skills.SkillInstaller, workspace string) {
+	yes := false
+	for _, arg := range os.Args[3:] {
+		switch arg {
+		case "--yes", "-y":
+			yes = true
+		case "--allow-untrusted":
+			installer.SetAllowUntrusted(true)
+		}
+	}
+
+	lockPath := filepath.Join(workspace, skills.LockfileName)
+	lock, err := skills.LoadLockfile(lockPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			fmt.Printf("No %s found in %s\n", skills.LockfileName, workspace)
+		} else {
+			fmt.Printf("✗ %v\n", err)
+		}
+		os.Exit(1)
+	}
+
+	plan, err := installer.PlanSync(lock)
+	if err != nil {
+		fmt.Printf("✗ Failed to read installed skills: %v\n", err)
+		os.Exit(1)
+	}
+	if plan.Empty() {
+		fmt.Println("✓ Skills already match the lockfile.")
+		return
+	}
+
+	for _, src := range plan.Install {
+		fmt.Printf("  + %s\n", src)
+	}
+	for _, src := range plan.Update {
+		fmt.Printf("  ~ %s\n", src)
+	}
+	for _, name := range plan.Remove {
+		fmt.Printf("  - %s\n", name)
+	}
+
+	if len(plan.Remove) > 0 && !yes {
+		fmt.Printf("Remove %d skill(s) not listed in %s? (y/n): ", len(plan.Remove), skills.LockfileName)
+		var response string
+		fmt.Scanln(&response)
+		if response != "y" {
+			fmt.Println("Aborted.")
+			return
+		}
+	}
+
+	if err := installer.Sync(context.Background(), plan); err != nil {
+		fmt.Printf("✗ Sync incomplete:\n%v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("✓ Skills synced with the lockfile.")
 }
 
 func skillsRemoveCmd(installer *skills.SkillInstaller, skillName string) {
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Sterlites/RDxClaw/pkg/config"
+)
+
+func TestREPLWithUnwritableHistory(t *testing.T) {
+	// A path beneath a regular file cannot be created, even by root
+	blocker := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	historyFile := filepath.Join(blocker, ".RDXCLAW_history")
+
+	if got := writableHistoryFile(historyFile); got != "" {
+		t.Fatalf("writableHistoryFile(%q) = %q, want in-memory history", historyFile, got)
+	}
+
+	rl, err := newREPLReadline(historyFile, io.NopCloser(strings.NewReader("hello\nagain\nexit\n")), io.Discard)
+	if err != nil {
+		t.Fatalf("readline should start without a writable history file: %v", err)
+	}
+	defer rl.Close()
+
+	var inputs []string
+	runREPL(rl, func(input string) (string, error) {
+		inputs = append(inputs, input)
+		return "ok", nil
+	})
+
+	if strings.Join(inputs, ",") != "hello,again" {
+		t.Errorf("REPL processed %q, want hello and again", inputs)
+	}
+}
+
+func TestWritableHistoryFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	if got := writableHistoryFile(path); got != path {
+		t.Errorf("writableHistoryFile(%q) = %q", path, got)
+	}
+	if got := writableHistoryFile(""); got != "" {
+		t.Errorf("empty path should disable the history file, got %q", got)
+	}
+}
+
+func TestParseGlobalFlags(t *testing.T) {
+	rest, flags := parseGlobalFlags([]string{"rdxclaw", "--workspace", "/tmp/a", "agent", "-m", "hi"})
+	if flags.workspace != "/tmp/a" || strings.Join(rest, " ") != "rdxclaw agent -m hi" {
+		t.Errorf("got %q, %+v", rest, flags)
+	}
+
+	rest, flags = parseGlobalFlags([]string{"rdxclaw", "--profile=work", "cron", "list", "--workspace=/tmp/b"})
+	if flags.workspace != "/tmp/b" || flags.profile != "work" || strings.Join(rest, " ") != "rdxclaw cron list" {
+		t.Errorf("got %q, %+v", rest, flags)
+	}
+
+	rest, flags = parseGlobalFlags([]string{"rdxclaw", "--data-dir", "/srv/rdxclaw", "server", "--data-dir=/var/lib/rdxclaw"})
+	if flags.dataDir != "/var/lib/rdxclaw" || strings.Join(rest, " ") != "rdxclaw server" {
+		t.Errorf("got %q, %+v", rest, flags)
+	}
+}
+
+func TestParseServeFlags(t *testing.T) {
+	flags, err := parseServeFlags([]string{"--host", "127.0.0.1", "--port=9000", "-d", "--api-key", "secret"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flags != (serveFlags{host: "127.0.0.1", port: 9000, apiKey: "secret", debug: true}) {
+		t.Errorf("got %+v", flags)
+	}
+
+	cfg := config.DefaultConfig()
+	flags.apply(&cfg.Gateway.Host, &cfg.Gateway.Port)
+	if got := bindAddress(cfg.Gateway.Host, cfg.Gateway.Port); got != "127.0.0.1:9000" {
+		t.Errorf("bind address = %q", got)
+	}
+
+	// Unset flags keep the configured values
+	cfg = config.DefaultConfig()
+	host, port := cfg.Gateway.Host, cfg.Gateway.Port
+	serveFlags{debug: true}.apply(&cfg.Gateway.Host, &cfg.Gateway.Port)
+	if cfg.Gateway.Host != host || cfg.Gateway.Port != port {
+		t.Errorf("config changed without flags: %s:%d", cfg.Gateway.Host, cfg.Gateway.Port)
+	}
+
+	for _, args := range [][]string{
+		{"--port", "0"},
+		{"--port", "70000"},
+		{"--port=http"},
+		{"--host"},
+		{"--api-key", "secret"}, // gateway does not take an API key
+		{"--verbose"},
+	} {
+		if _, err := parseServeFlags(args, false); err == nil {
+			t.Errorf("parseServeFlags(%q) should fail", args)
+		}
+	}
+
+	if got := bindAddress("::1", 8080); got != "[::1]:8080" {
+		t.Errorf("IPv6 bind address = %q", got)
+	}
+}
+
+func TestApplyWorkspaceOverride(t *testing.T) {
+	defer func() { workspaceFlag = "" }()
+	cfg := config.DefaultConfig()
+	configured := cfg.WorkspacePath()
+
+	t.Setenv("RDXCLAW_WORKSPACE", "")
+	if err := applyWorkspaceOverride(cfg); err != nil || cfg.WorkspacePath() != configured {
+		t.Fatalf("without an override the config must win, got %q (%v)", cfg.WorkspacePath(), err)
+	}
+
+	fromEnv := filepath.Join(t.TempDir(), "env")
+	t.Setenv("RDXCLAW_WORKSPACE", fromEnv)
+	if err := applyWorkspaceOverride(cfg); err != nil || cfg.WorkspacePath() != fromEnv {
+		t.Fatalf("got %q (%v), want %q", cfg.WorkspacePath(), err, fromEnv)
+	}
+	if info, err := os.Stat(fromEnv); err != nil || !info.IsDir() {
+		t.Errorf("workspace should be created: %v", err)
+	}
+
+	workspaceFlag = filepath.Join(t.TempDir(), "flag")
+	if err := applyWorkspaceOverride(cfg); err != nil || cfg.WorkspacePath() != workspaceFlag {
+		t.Fatalf("the flag must win over the env var, got %q (%v)", cfg.WorkspacePath(), err)
+	}
+
+	blocker := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	workspaceFlag = filepath.Join(blocker, "workspace")
+	if err := applyWorkspaceOverride(cfg); err == nil {
+		t.Error("expected an error for a workspace that cannot be created")
+	}
+}
+
+func TestProviderStatus(t *testing.T) {
+	got := providerStatus(context.Background(), config.DefaultConfig())
+	if !strings.Contains(got, "configuration problem") || !strings.Contains(got, "onboard") {
+		t.Errorf("Expected an unconfigured provider to point at onboarding, got %q", got)
+	}
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	cfg := config.DefaultConfig()
+	cfg.Providers.VLLM.APIKey = "key"
+	cfg.Providers.VLLM.APIBase = server.URL
+	cfg.Agents.Defaults.Provider = "vllm"
+	got = providerStatus(context.Background(), cfg)
+	if !strings.Contains(got, "temporary outage") {
+		t.Errorf("Expected an unreachable provider to be reported as an outage, got %q", got)
+	}
+}
+
+func TestLoadBranding(t *testing.T) {
+	dataDir := t.TempDir()
+	t.Setenv(config.DataDirEnv, dataDir)
+	if err := os.WriteFile(filepath.Join(dataDir, "config.json"), []byte(`{"branding": {"name": "Jarvis", "emoji": "🤖"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer func(saved config.BrandingConfig) { brand = saved }(brand)
+
+	loadBranding()
+	if brand.Name != "Jarvis" || brand.Emoji != "🤖" {
+		t.Errorf("loadBranding() set %+v, want the configured branding", brand)
+	}
+}
+
+func TestFormatReplyBranding(t *testing.T) {
+	if got := formatReply(config.DefaultBranding(), "hi"); got != "🦾 hi" {
+		t.Errorf("formatReply() with the default branding = %q", got)
+	}
+	if got := formatReply(config.BrandingConfig{Name: "Jarvis", Emoji: "🤖"}, "hi"); got != "🤖 hi" {
+		t.Errorf("formatReply() = %q, want the configured emoji", got)
+	}
+	if got := formatReply(config.BrandingConfig{Name: "Jarvis"}, "hi"); got != "Jarvis hi" {
+		t.Errorf("formatReply() without an emoji = %q, want the configured name", got)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Sterlites/RDxClaw/pkg/logger"
+)
+
+// shutdownTimeout bounds a graceful shutdown of the gateway or server, and
+// shutdownStageTimeout each of its stages, so one hung service cannot use
+// up the time of the stages after it.
+const (
+	shutdownTimeout      = 30 * time.Second
+	shutdownStageTimeout = 10 * time.Second
+)
+
+// shutdownStep stops one service. stop should give up when ctx ends.
+type shutdownStep struct {
+	name string
+	stop func(ctx context.Context) error
+	// timeout replaces the stage timeout for this step, e.g. for a server
+	// draining requests that may run longer than a stage.
+	timeout time.Duration
+}
+
+// step adapts a Stop method that takes no context.
+func step(name string, stop func()) shutdownStep {
+	return shutdownStep{name: name, stop: func(context.Context) error {
+		stop()
+		return nil
+	}}
+}
+
+// shutdown runs the stages in order and the steps of each stage
+// concurrently. Each step gets stageTimeout, or its own timeout, within the
+// overall ctx; a stage ends when all of its steps have had theirs. A step
+// still running when its stage runs out of time is logged and left behind
+// so shutdown can proceed; shutdown returns the names of those steps.
+func shutdown(ctx context.Context, stageTimeout time.Duration, stages ...[]shutdownStep) []string {
+	var unfinished []string
+	for _, stage := range stages {
+		unfinished = append(unfinished, runShutdownStage(ctx, stageTimeout, stage)...)
+	}
+	return unfinished
+}
+
+// runShutdownStage stops the steps of one stage concurrently and returns
+// those that did not finish within their timeout.
+func runShutdownStage(parent context.Context, timeout time.Duration, stage []shutdownStep) []string {
+	stageTimeout := timeout
+	for _, s := range stage {
+		stageTimeout = max(stageTimeout, s.timeout)
+	}
+	ctx, cancel := context.WithTimeout(parent, stageTimeout)
+	defer cancel()
+
+	finished := make(chan int, len(stage))
+	for i, s := range stage {
+		stepTimeout := timeout
+		if s.timeout > 0 {
+			stepTimeout = s.timeout
+		}
+		go func(i int, s shutdownStep) {
+			stepCtx, cancel := context.WithTimeout(ctx, stepTimeout)
+			defer cancel()
+			if err := s.stop(stepCtx); err != nil {
+				logger.WarnCF("shutdown", "Service stopped with an error",
+					map[string]interface{}{"service": s.name, "error": err.Error()})
+			}
+			finished <- i
+		}(i, s)
+	}
+
+	done := make([]bool, len(stage))
+	for pending := len(stage); pending > 0 && ctx.Err() == nil; {
+		select {
+		case i := <-finished:
+			done[i] = true
+			pending--
+		case <-ctx.Done():
+		}
+	}
+	// Count steps that finished as time ran out.
+	for len(finished) > 0 {
+		done[<-finished] = true
+	}
+
+	var unfinished []string
+	for i, s := range stage {
+		if !done[i] {
+			unfinished = append(unfinished, s.name)
+			logger.WarnCF("shutdown", "Service did not stop in time",
+				map[string]interface{}{"service": s.name})
+		}
+	}
+	return unfinished
+}
+
+// reportShutdown prints the outcome of shutdown for the named command.
+func reportShutdown(command string, unfinished []string) {
+	if len(unfinished) > 0 {
+		fmt.Printf("⚠️  %s stopped without waiting for: %v\n", command, unfinished)
+		return
+	}
+	fmt.Printf("✓ %s stopped\n", command)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestShutdown(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, name)
+	}
+
+	release := make(chan struct{})
+	defer close(release)
+	slow := shutdownStep{name: "slow", stop: func(ctx context.Context) error {
+		<-release // ignores ctx, like a hung channel
+		return nil
+	}}
+
+	start := time.Now()
+	unfinished := shutdown(context.Background(), 100*time.Millisecond,
+		[]shutdownStep{
+			step("cron", func() { record("cron") }),
+			slow,
+			{name: "failing", stop: func(context.Context) error {
+				record("failing")
+				return errors.New("boom")
+			}},
+		},
+		[]shutdownStep{step("agent", func() { record("agent") })},
+		[]shutdownStep{step("bus", func() { record("bus") })},
+	)
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("shutdown took %v; the slow service must not block it", elapsed)
+	}
+	if strings.Join(unfinished, ",") != "slow" {
+		t.Errorf("unfinished = %v, want [slow]", unfinished)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(order) != 4 {
+		t.Fatalf("stopped %v, want every fast service stopped", order)
+	}
+	if order[2] != "agent" || order[3] != "bus" {
+		t.Errorf("stop order = %v, want later stages after the first", order)
+	}
+}
+
+func TestShutdownOverallDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	block := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	start := time.Now()
+	shutdown(ctx, time.Minute,
+		[]shutdownStep{{name: "first", stop: block}},
+		[]shutdownStep{{name: "second", stop: block}},
+	)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("shutdown took %v; the overall deadline must bound every stage", elapsed)
+	}
+}
+
+func TestShutdownStepTimeout(t *testing.T) {
+	var drained time.Duration
+	unfinished := shutdown(context.Background(), 20*time.Millisecond,
+		[]shutdownStep{
+			{name: "api", timeout: 300 * time.Millisecond, stop: func(ctx context.Context) error {
+				start := time.Now()
+				select {
+				case <-time.After(100 * time.Millisecond): // a long request finishing
+				case <-ctx.Done():
+				}
+				drained = time.Since(start)
+				return nil
+			}},
+			step("cron", func() {}),
+		},
+	)
+	if len(unfinished) != 0 {
+		t.Errorf("unfinished = %v, want none; the api step has its own timeout", unfinished)
+	}
+	if drained < 100*time.Millisecond {
+		t.Errorf("api step was cut off after %v, before its own timeout", drained)
+	}
+}
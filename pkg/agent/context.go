@@ -8,6 +8,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Sterlites/RDxClaw/pkg/bus"
+	"github.com/Sterlites/RDxClaw/pkg/config"
 	"github.com/Sterlites/RDxClaw/pkg/logger"
 	"github.com/Sterlites/RDxClaw/pkg/providers"
 	"github.com/Sterlites/RDxClaw/pkg/skills"
@@ -18,15 +20,33 @@ type ContextBuilder struct {
 	workspace    string
 	skillsLoader *skills.SkillsLoader
 	memory       *MemoryStore
+	facts        tools.FactStore     // Long-term key-value memory, may be nil
 	tools        *tools.ToolRegistry // Direct reference to tool registry
+
+	// systemPromptFile is a workspace-relative file appended to the system
+	// prompt, set from agent.json.
+	systemPromptFile string
+
+	// silentToken is the reply that tells the channel layer to send nothing.
+	silentToken string
+
+	// hideTime leaves the current time out of the system prompt; location
+	// and locale describe the user's clock, and now reads it (time.Now
+	// outside tests).
+	hideTime bool
+	location *time.Location
+	locale   string
+	now      func() time.Time
+
+	// brand names the assistant in the system prompt's header.
+	brand config.BrandingConfig
 }
 
+// maxFactsChars bounds the remembered facts injected into the system prompt.
+const maxFactsChars = 2000
+
 func getGlobalConfigDir() string {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return ""
-	}
-	return filepath.Join(home, ".rdxclaw")
+	return config.HomeDir()
 }
 
 func NewContextBuilder(workspace string) *ContextBuilder {
@@ -40,30 +60,75 @@ func NewContextBuilder(workspace string) *ContextBuilder {
 		workspace:    workspace,
 		skillsLoader: skills.NewSkillsLoader(workspace, globalSkillsDir, builtinSkillsDir),
 		memory:       NewMemoryStore(workspace),
+		location:     time.Local,
+		now:          time.Now,
+		brand:        config.DefaultBranding(),
 	}
 }
 
+// WatchSkills reloads the skills summary whenever a skill file changes,
+// so edits are picked up on the next turn without a restart.
+func (cb *ContextBuilder) WatchSkills() error {
+	return cb.skillsLoader.Watch(skills.DefaultWatchDebounce)
+}
+
+// StopWatchingSkills stops the watcher started by WatchSkills.
+func (cb *ContextBuilder) StopWatchingSkills() {
+	cb.skillsLoader.StopWatching()
+}
+
 // SetToolsRegistry sets the tools registry for dynamic tool summary generation.
 func (cb *ContextBuilder) SetToolsRegistry(registry *tools.ToolRegistry) {
 	cb.tools = registry
 }
 
+// SetFactStore sets the key-value memory whose facts are injected into
+// every system prompt.
+func (cb *ContextBuilder) SetFactStore(store tools.FactStore) {
+	cb.facts = store
+}
+
+// SetTimeContext controls the "Current Time" section of the system prompt.
+// It is rebuilt on every turn in the given location; a nil location means
+// the host's zone. A non-empty locale is mentioned alongside it.
+func (cb *ContextBuilder) SetTimeContext(enabled bool, location *time.Location, locale string) {
+	if location == nil {
+		location = time.Local
+	}
+	cb.hideTime = !enabled
+	cb.location = location
+	cb.locale = locale
+}
+
+// timeSection returns the "Current Time" section for this turn, or "" when
+// it is disabled.
+func (cb *ContextBuilder) timeSection() string {
+	if cb.hideTime {
+		return ""
+	}
+	now := cb.now().In(cb.location)
+	section := fmt.Sprintf("## Current Time\n%s, %s (UTC%s)",
+		now.Format("2006-01-02 15:04 (Monday)"), cb.location, now.Format("-07:00"))
+	if cb.locale != "" {
+		section += fmt.Sprintf("\nLocale: %s (use it to format dates, times and numbers)", cb.locale)
+	}
+	return section + "\n\n"
+}
+
 func (cb *ContextBuilder) getIdentity() string {
-	now := time.Now().Format("2006-01-02 15:04 (Monday)")
 	workspacePath, _ := filepath.Abs(filepath.Join(cb.workspace))
 	runtime := fmt.Sprintf("%s %s, Go %s", runtime.GOOS, runtime.GOARCH, runtime.Version())
 
 	// Build tools section dynamically
 	toolsSection := cb.buildToolsSection()
 
-	return fmt.Sprintf(`## RDxClaw Agentic AI Framework 🦾
+	header := strings.TrimSpace(fmt.Sprintf("%s Agentic AI Framework %s", cb.brand.Name, cb.brand.Emoji))
 
-You are an autonomous agent running on the RDxClaw framework—the world's most efficient Agentic AI system for Edge Intelligence. Your goal is to create real-world business value by bridging LLM intelligence with physical and digital execution.
+	return fmt.Sprintf(`## %s
 
-## Current Time
-%s
+You are an autonomous agent running on the RDxClaw framework—the world's most efficient Agentic AI system for Edge Intelligence. Your goal is to create real-world business value by bridging LLM intelligence with physical and digital execution.
 
-## Runtime
+%s## Runtime
 %s
 
 ## Workspace
@@ -80,8 +145,17 @@ Your workspace is at: %s
 
 2. **Be helpful and accurate** - When using tools, briefly explain what you're doing.
 
-3. **Memory** - When remembering something, write to %s/memory/MEMORY.md`,
-		now, runtime, workspacePath, workspacePath, workspacePath, workspacePath, toolsSection, workspacePath)
+3. **Memory** - When remembering something, write to %s/memory/MEMORY.md%s`,
+		header, cb.timeSection(), runtime, workspacePath, workspacePath, workspacePath, workspacePath, toolsSection, workspacePath, cb.silentReplyRule())
+}
+
+// silentReplyRule tells the model how to stay quiet on turns that need no
+// answer, such as a heartbeat that found nothing to report.
+func (cb *ContextBuilder) silentReplyRule() string {
+	if cb.silentToken == "" {
+		return ""
+	}
+	return fmt.Sprintf("\n\n4. **Staying silent** - If a message needs no reply, respond with exactly %s and nothing else. That reply is never sent to the user.", cb.silentToken)
 }
 
 func (cb *ContextBuilder) buildToolsSection() string {
@@ -118,6 +192,13 @@ func (cb *ContextBuilder) BuildSystemPrompt() string {
 		parts = append(parts, bootstrapContent)
 	}
 
+	// System prompt file named by agent.json, re-read so edits apply live
+	if cb.systemPromptFile != "" {
+		if data, err := os.ReadFile(filepath.Join(cb.workspace, cb.systemPromptFile)); err == nil {
+			parts = append(parts, string(data))
+		}
+	}
+
 	// Skills - show summary, AI can read full content with read_file tool
 	skillsSummary := cb.skillsLoader.BuildSkillsSummary()
 	if skillsSummary != "" {
@@ -134,10 +215,37 @@ The following skills extend your capabilities. To use a skill, read its SKILL.md
 		parts = append(parts, "# Memory\n\n"+memoryContext)
 	}
 
+	// Remembered facts from the memory tool
+	if cb.facts != nil {
+		if facts := cb.facts.ListMemory(); len(facts) > 0 {
+			parts = append(parts, "# Remembered Facts\n\n"+tools.FormatFacts(facts, maxFactsChars))
+		}
+	}
+
 	// Join with "---" separator
 	return strings.Join(parts, "\n\n---\n\n")
 }
 
+// SetSystemPromptFile appends a workspace-relative file to every system
+// prompt; an empty name disables it.
+func (cb *ContextBuilder) SetSystemPromptFile(name string) {
+	cb.systemPromptFile = name
+}
+
+// SetBranding sets the name and emoji the system prompt introduces the
+// assistant with. Branding without a name keeps the default.
+func (cb *ContextBuilder) SetBranding(b config.BrandingConfig) {
+	if b.Name != "" {
+		cb.brand = b
+	}
+}
+
+// SetSilentReplyToken sets the reply the model uses to stay silent; an empty
+// token leaves the rule out of the system prompt.
+func (cb *ContextBuilder) SetSilentReplyToken(token string) {
+	cb.silentToken = token
+}
+
 func (cb *ContextBuilder) LoadBootstrapFiles() string {
 	bootstrapFiles := []string{
 		"AGENTS.md",
@@ -234,6 +342,41 @@ func (cb *ContextBuilder) AddAssistantMessage(messages []providers.Message, cont
 	return messages
 }
 
+// AddAttachments attaches inbound images to the last user message. Models
+// without vision support get a text note instead, so they can tell the user
+// the image could not be viewed.
+func (cb *ContextBuilder) AddAttachments(messages []providers.Message, attachments []bus.Attachment, vision bool) []providers.Message {
+	if len(attachments) == 0 || len(messages) == 0 {
+		return messages
+	}
+
+	last := &messages[len(messages)-1]
+	if last.Role != "user" {
+		return messages
+	}
+
+	for _, att := range attachments {
+		if att.Type != "image" || len(att.Data) == 0 {
+			continue
+		}
+		if vision {
+			last.Parts = append(last.Parts, providers.ContentPart{
+				Type:     "image",
+				MimeType: att.MimeType,
+				Data:     att.Data,
+			})
+			continue
+		}
+		note := fmt.Sprintf("[An image was attached (%s), but the current model cannot view images]", att.Filename)
+		if last.Content != "" {
+			last.Content += "\n"
+		}
+		last.Content += note
+	}
+
+	return messages
+}
+
 func (cb *ContextBuilder) loadSkills() string {
 	allSkills := cb.skillsLoader.ListSkills()
 	if len(allSkills) == 0 {
@@ -254,15 +397,15 @@ func (cb *ContextBuilder) loadSkills() string {
 }
 
 // GetSkillsInfo returns information about loaded skills.
-func (cb *ContextBuilder) GetSkillsInfo() map[string]interface{} {
+func (cb *ContextBuilder) GetSkillsInfo() SkillsInfo {
 	allSkills := cb.skillsLoader.ListSkills()
 	skillNames := make([]string, 0, len(allSkills))
 	for _, s := range allSkills {
 		skillNames = append(skillNames, s.Name)
 	}
-	return map[string]interface{}{
-		"total":     len(allSkills),
-		"available": len(allSkills),
-		"names":     skillNames,
+	return SkillsInfo{
+		Total:     len(allSkills),
+		Available: len(allSkills),
+		Names:     skillNames,
 	}
 }
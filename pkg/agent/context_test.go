@@ -0,0 +1,58 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sterlites/RDxClaw/pkg/config"
+)
+
+func TestContextBuilder_TimeReflectsTurn(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	cb := NewContextBuilder(t.TempDir())
+	cb.SetTimeContext(true, berlin, "de-DE")
+
+	turn := time.Date(2026, 3, 2, 8, 30, 0, 0, time.UTC)
+	cb.now = func() time.Time { return turn }
+
+	prompt := cb.BuildSystemPrompt()
+	if !strings.Contains(prompt, "2026-03-02 09:30 (Monday), Europe/Berlin (UTC+01:00)") {
+		t.Errorf("system prompt lacks the turn time in Berlin:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "Locale: de-DE") {
+		t.Error("system prompt lacks the locale")
+	}
+
+	// A later turn sees a later time; nothing is cached from the first one.
+	turn = turn.Add(36 * time.Hour)
+	prompt = cb.BuildSystemPrompt()
+	if !strings.Contains(prompt, "2026-03-03 21:30 (Tuesday)") {
+		t.Errorf("system prompt was not updated for the next turn:\n%s", prompt)
+	}
+}
+
+func TestContextBuilder_TimeDisabled(t *testing.T) {
+	cb := NewContextBuilder(t.TempDir())
+	cb.SetTimeContext(false, nil, "")
+
+	if strings.Contains(cb.BuildSystemPrompt(), "## Current Time") {
+		t.Error("time section should be left out when disabled")
+	}
+}
+
+func TestContextBuilder_Branding(t *testing.T) {
+	cb := NewContextBuilder(t.TempDir())
+	if !strings.HasPrefix(cb.BuildSystemPrompt(), "## RDxClaw Agentic AI Framework 🦾\n") {
+		t.Error("system prompt should open with the default branding")
+	}
+
+	cb.SetBranding(config.BrandingConfig{Name: "Acme"})
+	if !strings.HasPrefix(cb.BuildSystemPrompt(), "## Acme Agentic AI Framework\n") {
+		t.Errorf("system prompt should open with the configured branding:\n%s", cb.BuildSystemPrompt())
+	}
+}
@@ -26,7 +26,9 @@ import (
 	"github.com/Sterlites/RDxClaw/pkg/knowledge"
 	"github.com/Sterlites/RDxClaw/pkg/logger"
 	"github.com/Sterlites/RDxClaw/pkg/providers"
+	"github.com/Sterlites/RDxClaw/pkg/reqctx"
 	"github.com/Sterlites/RDxClaw/pkg/session"
+	"github.com/Sterlites/RDxClaw/pkg/skills"
 	"github.com/Sterlites/RDxClaw/pkg/state"
 	"github.com/Sterlites/RDxClaw/pkg/swarm"
 	"github.com/Sterlites/RDxClaw/pkg/tools"
@@ -40,26 +42,71 @@ type AgentLoop struct {
 	model          string
 	contextWindow  int // Maximum context window size in tokens
 	maxIterations  int
+	continuations  int     // Times a reply cut off at max_tokens is continued
+	temperature    float64 // Sampling temperature for agent turns
+	maxTokens      int     // Maximum tokens per model response
+	silentToken    string  // Reply meaning "send nothing"; empty disables
 	sessions       *session.SessionManager
+	traces         *tools.TraceRecorder // Tool calls per session, for self_trace
+	outputLimit    tools.ToolOutputLimit
 	state          *state.Manager
 	contextBuilder *ContextBuilder
 	tools          *tools.ToolRegistry
 	running        atomic.Bool
-	summarizing    sync.Map // Tracks which sessions are currently being summarized
+	runMu          sync.Mutex
+	stopped        bool               // Stop was called; a later Run returns at once
+	stopConsume    context.CancelFunc // stops Run waiting for the next message
+	runDone        chan struct{}      // closed when Run returns
+	summarizing    sync.Map           // Tracks which sessions are currently being summarized
 	channelManager *channels.Manager
 	swarmManager   *swarm.Manager
+	channelModels  map[string]channelModel                  // Per-channel provider/model overrides
+	modelProvider  func(model string) providers.LLMProvider // Provider for a per-request model override
+	pauseMu        sync.Mutex
+	resumeCh       chan struct{} // non-nil while paused; closed on resume
+	turns          turnRegistry
+	summary        summaryPolicy
+}
+
+// summaryPolicy decides when a session's history is summarized.
+type summaryPolicy struct {
+	enabled      bool
+	maxMessages  int // summarize once history has more messages than this
+	tokenPercent int // or once it exceeds this share of the context window
+}
+
+func newSummaryPolicy(d config.AgentDefaults) summaryPolicy {
+	p := summaryPolicy{
+		enabled:      d.SummarizeHistory,
+		maxMessages:  d.SummarizeAfterMessages,
+		tokenPercent: d.SummarizeTokenPercent,
+	}
+	if p.maxMessages <= 0 {
+		p.maxMessages = 20
+	}
+	if p.tokenPercent <= 0 || p.tokenPercent > 100 {
+		p.tokenPercent = 75
+	}
+	return p
+}
+
+// channelModel is the provider and model used for messages from a channel.
+type channelModel struct {
+	provider providers.LLMProvider
+	model    string
 }
 
 // processOptions configures how a message is processed
 type processOptions struct {
-	SessionKey      string // Session identifier for history/context
-	Channel         string // Target channel for tool execution
-	ChatID          string // Target chat ID for tool execution
-	UserMessage     string // User message content (may include prefix)
-	DefaultResponse string // Response when LLM returns empty
-	EnableSummary   bool   // Whether to trigger summarization
-	SendResponse    bool   // Whether to send response via bus
-	NoHistory       bool   // If true, don't load session history (for heartbeat)
+	SessionKey      string           // Session identifier for history/context
+	Channel         string           // Target channel for tool execution
+	ChatID          string           // Target chat ID for tool execution
+	UserMessage     string           // User message content (may include prefix)
+	Attachments     []bus.Attachment // Inbound attachments (images) for the current message
+	DefaultResponse string           // Response when LLM returns empty
+	EnableSummary   bool             // Whether to trigger summarization
+	SendResponse    bool             // Whether to send response via bus
+	NoHistory       bool             // If true, don't load session history (for heartbeat)
 }
 
 // createToolRegistry creates a tool registry with common tools.
@@ -106,10 +153,25 @@ func createToolRegistry(workspace string, restrict bool, cfg *config.Config, msg
 	registry.Register(messageTool)
 
 	// Knowledge Tool (RAG)
-	knowledgeDir := filepath.Join(workspace, "knowledge")
+	knowledgeDir := config.KnowledgeDir(workspace)
 	// Initialize store (ignore error for now, just log if fails)
 	if store, err := knowledge.NewStore(knowledgeDir); err == nil {
-		registry.Register(tools.NewKnowledgeTool(store))
+		configureRedaction(store, cfg.Tools.Knowledge)
+		store.SetSearchCacheSize(cfg.Tools.Knowledge.SearchCacheSize)
+		store.SetIngestWorkers(cfg.Tools.Knowledge.IngestWorkers)
+		store.SetIngestLimits(cfg.Tools.Knowledge.IngestMaxFiles, int64(cfg.Tools.Knowledge.IngestMaxMB)<<20)
+		for _, collection := range cfg.Tools.Knowledge.QueryLogCollections {
+			store.SetQueryLogging(collection, true)
+		}
+		for collection, mode := range cfg.Tools.Knowledge.Tokenizers {
+			if err := store.SetTokenizer(collection, mode); err != nil {
+				logger.ErrorCF("agent", "Invalid knowledge tokenizer", map[string]interface{}{
+					"collection": collection,
+					"error":      err.Error(),
+				})
+			}
+		}
+		registry.Register(tools.NewKnowledgeTool(store, workspace, restrict))
 	} else {
 		// We can't use logger here easily as we don't pass it context, but we can print to stderr or just skip
 		// Better to just skip for now or use global logger if available
@@ -119,7 +181,41 @@ func createToolRegistry(workspace string, restrict bool, cfg *config.Config, msg
 	return registry
 }
 
+// configureRedaction enables PII redaction on the collections that opted in.
+// An invalid detector configuration is logged and leaves redaction off.
+func configureRedaction(store *knowledge.Store, kc config.KnowledgeConfig) {
+	if len(kc.RedactCollections) == 0 {
+		return
+	}
+	redactor, err := knowledge.NewRedactor(kc.RedactDetectors, kc.RedactPatterns)
+	if err != nil {
+		logger.ErrorCF("agent", "Knowledge PII redaction disabled", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	for _, collection := range kc.RedactCollections {
+		store.SetRedactor(collection, redactor)
+	}
+}
+
+// registerSkillTools exposes the scripts of installed skills as tools. A
+// script whose name is already taken by another tool is skipped. Skills
+// added later are picked up on the next restart.
+func registerSkillTools(registry *tools.ToolRegistry, loader *skills.SkillsLoader) {
+	for _, tool := range loader.ScriptTools() {
+		if _, exists := registry.Get(tool.Name()); exists {
+			logger.WarnCF("agent", "Skipping skill script that shadows a tool", map[string]interface{}{"tool": tool.Name()})
+			continue
+		}
+		registry.Register(tool)
+	}
+}
+
 func NewAgentLoop(cfg *config.Config, msgBus *bus.MessageBus, provider providers.LLMProvider) *AgentLoop {
+	providers.RegisterConfiguredModels(cfg)
+	if cfg.Providers.LogRequests {
+		provider = providers.NewLoggingProvider(provider)
+	}
+
 	workspace := cfg.WorkspacePath()
 	os.MkdirAll(workspace, 0755)
 
@@ -128,8 +224,46 @@ func NewAgentLoop(cfg *config.Config, msgBus *bus.MessageBus, provider providers
 	// Create tool registry for main agent
 	toolsRegistry := createToolRegistry(workspace, restrict, cfg, msgBus)
 
+	model := cfg.Agents.Defaults.Model
+	temperature := cfg.Agents.Defaults.Temperature
+	maxTokens := defaultResponseTokens
+	maxIterations := defaultMaxIterations(cfg.Agents.Defaults.MaxToolIterations, defaultToolIterations)
+
+	// agent.json in the workspace overrides config.json for behaviour
+	settings, err := LoadWorkspaceSettings(workspace)
+	if err != nil {
+		logger.ErrorCF("agent", "Ignoring workspace agent settings", map[string]interface{}{"error": err.Error()})
+	}
+	if settings != nil {
+		if settings.Model != "" {
+			model = settings.Model
+		}
+		if settings.Temperature != nil {
+			temperature = *settings.Temperature
+		}
+		if settings.MaxTokens > 0 {
+			maxTokens = settings.MaxTokens
+		}
+		if settings.MaxIterations > 0 {
+			maxIterations = settings.MaxIterations
+		}
+	}
+
+	// The provider passed in serves config.json's model; a different model
+	// from agent.json may need another one, resolved like a request's.
+	modelProvider := newModelProviders(cfg, provider)
+	if model != cfg.Agents.Defaults.Model {
+		provider = modelProvider(model)
+	}
+
 	// Create subagent/swarm manager with its own tool registry
-	swarmManager := swarm.NewManager(provider, cfg.Agents.Defaults.Model, workspace, msgBus)
+	swarmManager := swarm.NewManager(provider, model, workspace, msgBus)
+	swarmManager.SetModelOptions(temperature, maxTokens)
+	swarmManager.SetMemoryThresholds(
+		uint64(max(cfg.Agents.Defaults.SwarmMemoryLimitMB, 0))<<20,
+		uint64(max(cfg.Agents.Defaults.SwarmMinHeadroomMB, 0))<<20,
+	)
+	swarmManager.SetMaxIterations(defaultMaxIterations(cfg.Agents.Defaults.SwarmMaxIterations, swarm.DefaultMaxIterations))
 	subagentTools := createToolRegistry(workspace, restrict, cfg, msgBus)
 	// Subagent doesn't need spawn/subagent tools to avoid recursion
 	swarmManager.SetToolRegistry(subagentTools)
@@ -146,49 +280,258 @@ func NewAgentLoop(cfg *config.Config, msgBus *bus.MessageBus, provider providers
 	swarmTool := swarm.NewSwarmTool(swarmManager)
 	toolsRegistry.Register(swarmTool)
 
+	// Shared scratchpad, for the main agent and its subagents alike
+	scratchpadTool := swarm.NewScratchpadTool(swarmManager)
+	toolsRegistry.Register(scratchpadTool)
+	subagentTools.Register(scratchpadTool)
+
 	sessionsManager := session.NewSessionManager(filepath.Join(workspace, "sessions"))
 
 	// Create state manager for atomic state persistence
 	stateManager := state.NewManager(workspace)
 
+	// Long-term key-value memory lives in the persisted state
+	toolsRegistry.Register(tools.NewMemoryTool(stateManager))
+
+	// Per-session trace of tool calls, readable by the agent itself
+	traces := tools.NewTraceRecorder(filepath.Join(workspace, "traces"))
+	toolsRegistry.Register(tools.NewSelfTraceTool(traces))
+
 	// Create context builder and set tools registry
 	contextBuilder := NewContextBuilder(workspace)
 	contextBuilder.SetToolsRegistry(toolsRegistry)
+	contextBuilder.SetFactStore(stateManager)
+	registerSkillTools(toolsRegistry, contextBuilder.skillsLoader)
+	if cfg.Agents.Defaults.WatchSkills {
+		if err := contextBuilder.WatchSkills(); err != nil {
+			logger.WarnCF("agent", "Skill hot-reload disabled", map[string]interface{}{"error": err.Error()})
+		}
+	}
+
+	if settings != nil {
+		contextBuilder.SetSystemPromptFile(settings.SystemPromptFile)
+	}
+	outputLimit := tools.ToolOutputLimit{MaxChars: cfg.Agents.Defaults.MaxToolOutputChars}
+	if cfg.Agents.Defaults.StashToolOutput {
+		outputLimit.StashDir = filepath.Join(workspace, "tool_outputs")
+	}
+	swarmManager.SetToolOutputLimit(outputLimit)
+	swarmManager.SetMaxContinuations(cfg.Agents.Defaults.MaxContinuations)
+
+	silentToken := cfg.Agents.Defaults.SilentReplyToken
+	contextBuilder.SetSilentReplyToken(silentToken)
+	contextBuilder.SetBranding(cfg.Branding)
+	contextBuilder.SetTimeContext(cfg.Agents.Defaults.InjectDateTime, loadTimezone(cfg.Agents.Defaults.Timezone), cfg.Agents.Defaults.Locale)
 
 	return &AgentLoop{
 		bus:            msgBus,
 		provider:       provider,
+		channelModels:  buildChannelModels(cfg, provider, model),
+		modelProvider:  modelProvider,
 		workspace:      workspace,
-		model:          cfg.Agents.Defaults.Model,
+		model:          model,
 		contextWindow:  cfg.Agents.Defaults.MaxTokens, // Restore context window for summarization
-		maxIterations:  cfg.Agents.Defaults.MaxToolIterations,
+		maxIterations:  maxIterations,
+		continuations:  cfg.Agents.Defaults.MaxContinuations,
+		temperature:    temperature,
+		maxTokens:      maxTokens,
+		silentToken:    silentToken,
 		sessions:       sessionsManager,
+		traces:         traces,
+		outputLimit:    outputLimit,
 		state:          stateManager,
 		contextBuilder: contextBuilder,
 		tools:          toolsRegistry,
 		summarizing:    sync.Map{},
 		swarmManager:   swarmManager,
+		summary:        newSummaryPolicy(cfg.Agents.Defaults),
+	}
+}
+
+// defaultToolIterations is the agent's tool iteration cap when the config
+// leaves it unset.
+const defaultToolIterations = 20
+
+// defaultMaxIterations bounds the configured tool iteration cap to the range
+// tools.ClampMaxIterations allows, warning when the configured value is out of
+// range. Zero or less means def.
+func defaultMaxIterations(n, def int) int {
+	clamped := tools.ClampMaxIterations(n)
+	if clamped == 0 {
+		clamped = def
+	}
+	if n > 0 && clamped != n {
+		logger.WarnCF("agent", "max_tool_iterations out of range, clamping",
+			map[string]interface{}{
+				"configured": n,
+				"using":      clamped,
+			})
+	}
+	return clamped
+}
+
+// loadTimezone resolves the configured timezone, falling back to the host's
+// zone when it is empty or unknown.
+func loadTimezone(name string) *time.Location {
+	if name == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		logger.WarnCF("agent", "Unknown timezone, using local time", map[string]interface{}{
+			"timezone": name,
+			"error":    err.Error(),
+		})
+		return time.Local
+	}
+	return loc
+}
+
+// buildChannelModels resolves the per-channel overrides from config. An
+// override without a provider reuses the default provider with its own model,
+// and one without a model uses defaultModel, the agent's model after
+// agent.json; if the override provider cannot be created the channel falls
+// back to the default provider.
+func buildChannelModels(cfg *config.Config, defaultProvider providers.LLMProvider, defaultModel string) map[string]channelModel {
+	models := make(map[string]channelModel, len(cfg.Agents.Channels))
+	for channel, override := range cfg.Agents.Channels {
+		model := override.Model
+		if model == "" {
+			model = defaultModel
+		}
+
+		provider := defaultProvider
+		if override.Provider != "" {
+			p, err := providers.CreateProviderFor(cfg, override.Provider, model)
+			if err != nil {
+				logger.WarnCF("agent", "Failed to create channel provider, using default", map[string]interface{}{
+					"channel":  channel,
+					"provider": override.Provider,
+					"error":    err.Error(),
+				})
+			} else if cfg.Providers.LogRequests {
+				provider = providers.NewLoggingProvider(p)
+			} else {
+				provider = p
+			}
+		}
+
+		models[channel] = channelModel{provider: provider, model: model}
+	}
+	return models
+}
+
+// newModelProviders returns a function resolving the provider for a model a
+// request asks for. With a provider configured, that provider serves every
+// model, as it does for channel overrides. Otherwise the provider is detected
+// from the model name, as for the default model, and falls back to the default
+// provider if none is configured for it. Providers are created once per model.
+func newModelProviders(cfg *config.Config, defaultProvider providers.LLMProvider) func(model string) providers.LLMProvider {
+	if cfg.Agents.Defaults.Provider != "" {
+		return func(string) providers.LLMProvider { return defaultProvider }
+	}
+
+	var mu sync.Mutex
+	created := make(map[string]providers.LLMProvider)
+	return func(model string) providers.LLMProvider {
+		mu.Lock()
+		defer mu.Unlock()
+		if p, ok := created[model]; ok {
+			return p
+		}
+
+		provider := defaultProvider
+		p, err := providers.CreateProviderFor(cfg, "", model)
+		if err != nil {
+			logger.WarnCF("agent", "Failed to create provider for requested model, using default", map[string]interface{}{
+				"model": model,
+				"error": err.Error(),
+			})
+		} else if cfg.Providers.LogRequests {
+			provider = providers.NewLoggingProvider(p)
+		} else {
+			provider = p
+		}
+		created[model] = provider
+		return provider
+	}
+}
+
+// modelFor returns the provider and model to use for messages from channel.
+func (al *AgentLoop) modelFor(channel string) (providers.LLMProvider, string) {
+	if cm, ok := al.channelModels[channel]; ok {
+		return cm.provider, cm.model
+	}
+	return al.provider, al.model
+}
+
+// requestModelFor is modelFor, honouring a model set by the request's
+// RequestOptions.
+func (al *AgentLoop) requestModelFor(ctx context.Context, channel string) (providers.LLMProvider, string) {
+	provider, model := al.modelFor(channel)
+	if override := requestOptionsFrom(ctx).Model; override != "" && override != model {
+		return al.modelProvider(override), override
 	}
+	return provider, model
+}
+
+// contextWindowFor returns the context window used to decide when to
+// summarize a channel's sessions: the configured window, reduced to the
+// model's own window when the registry knows it to be smaller.
+func (al *AgentLoop) contextWindowFor(ctx context.Context, channel string) int {
+	_, model := al.requestModelFor(ctx, channel)
+	if info, ok := providers.LookupModel(model); ok && info.ContextWindow > 0 && info.ContextWindow < al.contextWindow {
+		return info.ContextWindow
+	}
+	return al.contextWindow
 }
 
 func (al *AgentLoop) Run(ctx context.Context) error {
+	// Stop cancels only the wait for the next message, so a message being
+	// processed can finish.
+	consumeCtx, stopConsume := context.WithCancel(ctx)
+	defer stopConsume()
+	done := make(chan struct{})
+	al.runMu.Lock()
+	if al.stopped {
+		// Stopped before it started, e.g. by a shutdown right after
+		// startup; Drain has nothing to wait for.
+		al.runMu.Unlock()
+		return nil
+	}
+	al.stopConsume, al.runDone = stopConsume, done
 	al.running.Store(true)
+	al.runMu.Unlock()
+	defer close(done)
 
 	for al.running.Load() {
 		select {
 		case <-ctx.Done():
 			return nil
 		default:
-			msg, ok := al.bus.ConsumeInbound(ctx)
+			msg, ok := al.bus.ConsumeInbound(consumeCtx)
 			if !ok {
 				continue
 			}
 
+			// Hold the message if the loop was paused while waiting for it.
+			if !al.waitIfPaused(ctx) {
+				return nil
+			}
+
 			response, err := al.processMessage(ctx, msg)
 			if err != nil {
 				response = fmt.Sprintf("Error processing message: %v", err)
 			}
 
+			if msg.Reply != nil {
+				select {
+				case msg.Reply <- response:
+				default:
+				}
+				continue
+			}
+
 			if response != "" {
 				// Check if the message tool already sent a response during this round.
 				// If so, skip publishing to avoid duplicate messages to the user.
@@ -214,7 +557,33 @@ func (al *AgentLoop) Run(ctx context.Context) error {
 }
 
 func (al *AgentLoop) Stop() {
+	al.runMu.Lock()
+	al.stopped = true
 	al.running.Store(false)
+	if al.stopConsume != nil {
+		al.stopConsume()
+	}
+	al.runMu.Unlock()
+	al.contextBuilder.StopWatchingSkills()
+}
+
+// Drain stops the loop and waits for Run to return, which it does once the
+// message being processed, if any, is finished. A Run that has not started
+// yet returns without processing anything. It gives up when ctx ends.
+func (al *AgentLoop) Drain(ctx context.Context) error {
+	al.Stop()
+	al.runMu.Lock()
+	done := al.runDone
+	al.runMu.Unlock()
+	if done == nil {
+		return nil
+	}
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 func (al *AgentLoop) RegisterTool(tool tools.Tool) {
@@ -225,6 +594,38 @@ func (al *AgentLoop) SetChannelManager(cm *channels.Manager) {
 	al.channelManager = cm
 }
 
+// GetChannelStatus returns the state of the chat channels. Without a
+// channel manager, e.g. under `rdxclaw server`, it reports what the gateway
+// last persisted for this workspace.
+func (al *AgentLoop) GetChannelStatus() map[string]channels.ChannelStatus {
+	if al.channelManager != nil {
+		return al.channelManager.GetChannelStatus()
+	}
+	status, err := channels.LoadChannelStatus(al.workspace)
+	if err != nil {
+		logger.WarnCF("agent", "Failed to load channel status", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	return status
+}
+
+// GetKnowledgeStore returns the knowledge store behind the agent's
+// knowledge tool, or nil if the store could not be opened.
+func (al *AgentLoop) GetKnowledgeStore() *knowledge.Store {
+	if tool, ok := al.tools.Get("knowledge"); ok {
+		if kt, ok := tool.(*tools.KnowledgeTool); ok {
+			return kt.Store()
+		}
+	}
+	return nil
+}
+
+// GetProvider returns the provider used for the default model.
+func (al *AgentLoop) GetProvider() providers.LLMProvider {
+	return al.provider
+}
+
 func (al *AgentLoop) GetSwarmManager() *swarm.Manager {
 	return al.swarmManager
 }
@@ -273,6 +674,16 @@ func (al *AgentLoop) ProcessHeartbeat(ctx context.Context, content, channel, cha
 }
 
 func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage) (string, error) {
+	// Messages arriving over the bus lose their request context; recover the
+	// ID from metadata when the publisher recorded one.
+	if id := msg.Metadata["request_id"]; id != "" && reqctx.RequestID(ctx) == "" {
+		ctx = reqctx.WithRequestID(ctx, id)
+	}
+	ctx = reqctx.EnsureRequestID(ctx)
+	if msg.SessionKey != "" {
+		ctx = reqctx.WithSessionKey(ctx, msg.SessionKey)
+	}
+
 	// Add message preview to log (show full content for error messages)
 	var logContent string
 	if strings.Contains(msg.Content, "Error:") || strings.Contains(msg.Content, "error") {
@@ -280,7 +691,7 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 	} else {
 		logContent = utils.Truncate(msg.Content, 80)
 	}
-	logger.InfoCF("agent", fmt.Sprintf("Processing message from %s:%s: %s", msg.Channel, msg.SenderID, logContent),
+	logger.InfoCtx(ctx, "agent", fmt.Sprintf("Processing message from %s:%s: %s", msg.Channel, msg.SenderID, logContent),
 		map[string]interface{}{
 			"channel":     msg.Channel,
 			"chat_id":     msg.ChatID,
@@ -304,6 +715,7 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 		Channel:         msg.Channel,
 		ChatID:          msg.ChatID,
 		UserMessage:     msg.Content,
+		Attachments:     msg.Attachments,
 		DefaultResponse: "I've completed processing but have no response to give.",
 		EnableSummary:   true,
 		SendResponse:    false,
@@ -316,16 +728,17 @@ func (al *AgentLoop) processSystemMessage(ctx context.Context, msg bus.InboundMe
 		return "", fmt.Errorf("processSystemMessage called with non-system message channel: %s", msg.Channel)
 	}
 
-	logger.InfoCF("agent", "Processing system message",
+	logger.InfoCtx(ctx, "agent", "Processing system message",
 		map[string]interface{}{
 			"sender_id": msg.SenderID,
 			"chat_id":   msg.ChatID,
 		})
 
 	// Parse origin channel from chat_id (format: "channel:chat_id")
-	var originChannel string
+	var originChannel, originChatID string
 	if idx := strings.Index(msg.ChatID, ":"); idx > 0 {
 		originChannel = msg.ChatID[:idx]
+		originChatID = msg.ChatID[idx+1:]
 	} else {
 		// Fallback
 		originChannel = "cli"
@@ -340,7 +753,7 @@ func (al *AgentLoop) processSystemMessage(ctx context.Context, msg bus.InboundMe
 
 	// Skip internal channels - only log, don't send to user
 	if constants.IsInternalChannel(originChannel) {
-		logger.InfoCF("agent", "Subagent completed (internal channel)",
+		logger.InfoCtx(ctx, "agent", "Subagent completed (internal channel)",
 			map[string]interface{}{
 				"sender_id":   msg.SenderID,
 				"content_len": len(content),
@@ -349,29 +762,45 @@ func (al *AgentLoop) processSystemMessage(ctx context.Context, msg bus.InboundMe
 		return "", nil
 	}
 
-	// Agent acts as dispatcher only - subagent handles user interaction via message tool
-	// Don't forward result here, subagent should use message tool to communicate with user
-	logger.InfoCF("agent", "Subagent completed",
+	logger.InfoCtx(ctx, "agent", "Subagent completed",
 		map[string]interface{}{
 			"sender_id":   msg.SenderID,
 			"channel":     originChannel,
 			"content_len": len(content),
 		})
 
-	// Agent only logs, does not respond to user
+	// Spawned swarm agents run detached from the turn that spawned them,
+	// which has usually returned by now, so deliver the result straight to
+	// the chat that asked for it. The manager does not announce results a
+	// caller waits for, such as delegate_task's, so these are all async.
+	if strings.HasPrefix(msg.SenderID, "swarm:") && originChatID != "" {
+		al.bus.PublishOutbound(bus.OutboundMessage{
+			Channel: originChannel,
+			ChatID:  originChatID,
+			Content: msg.Content,
+		})
+		return "", nil
+	}
+
+	// Other async tools handle user interaction themselves via the message tool
 	return "", nil
 }
 
 // runAgentLoop is the core message processing logic.
 // It handles context building, LLM calls, tool execution, and response handling.
 func (al *AgentLoop) runAgentLoop(ctx context.Context, opts processOptions) (string, error) {
+	ctx = reqctx.WithSessionKey(reqctx.EnsureRequestID(ctx), opts.SessionKey)
+
+	done := al.turns.begin(opts.Channel, opts.SessionKey)
+	defer done()
+
 	// 0. Record last channel for heartbeat notifications (skip internal channels)
 	if opts.Channel != "" && opts.ChatID != "" {
 		// Don't record internal channels (cli, system, subagent)
 		if !constants.IsInternalChannel(opts.Channel) {
 			channelKey := fmt.Sprintf("%s:%s", opts.Channel, opts.ChatID)
 			if err := al.RecordLastChannel(channelKey); err != nil {
-				logger.WarnCF("agent", "Failed to record last channel: %v", map[string]interface{}{"error": err.Error()})
+				logger.WarnCtx(ctx, "agent", "Failed to record last channel: %v", map[string]interface{}{"error": err.Error()})
 			}
 		}
 	}
@@ -394,6 +823,8 @@ func (al *AgentLoop) runAgentLoop(ctx context.Context, opts processOptions) (str
 		opts.Channel,
 		opts.ChatID,
 	)
+	_, model := al.requestModelFor(ctx, opts.Channel)
+	messages = al.contextBuilder.AddAttachments(messages, opts.Attachments, providers.SupportsVision(model))
 
 	// 3. Save user message to session
 	al.sessions.AddMessage(opts.SessionKey, "user", opts.UserMessage)
@@ -417,8 +848,19 @@ func (al *AgentLoop) runAgentLoop(ctx context.Context, opts processOptions) (str
 	al.sessions.Save(opts.SessionKey)
 
 	// 7. Optional: summarization
-	if opts.EnableSummary {
-		al.maybeSummarize(opts.SessionKey, opts.Channel, opts.ChatID)
+	if opts.EnableSummary && al.summary.enabled {
+		al.maybeSummarize(ctx, opts.SessionKey, opts.Channel, opts.ChatID)
+	}
+
+	// The agent chose not to reply; keep the turn in history but send nothing
+	if config.IsSilentReply(finalContent, al.silentToken) {
+		logger.InfoCtx(ctx, "agent", "Reply suppressed",
+			map[string]interface{}{
+				"session_key": opts.SessionKey,
+				"channel":     opts.Channel,
+				"iterations":  iteration,
+			})
+		return "", nil
 	}
 
 	// 8. Optional: send response via bus
@@ -432,7 +874,7 @@ func (al *AgentLoop) runAgentLoop(ctx context.Context, opts processOptions) (str
 
 	// 9. Log response
 	responsePreview := utils.Truncate(finalContent, 120)
-	logger.InfoCF("agent", fmt.Sprintf("Response: %s", responsePreview),
+	logger.InfoCtx(ctx, "agent", fmt.Sprintf("Response: %s", responsePreview),
 		map[string]interface{}{
 			"session_key":  opts.SessionKey,
 			"iterations":   iteration,
@@ -445,35 +887,49 @@ func (al *AgentLoop) runAgentLoop(ctx context.Context, opts processOptions) (str
 // runLLMIteration executes the LLM call loop with tool handling.
 // Returns the final content, iteration count, and any error.
 func (al *AgentLoop) runLLMIteration(ctx context.Context, messages []providers.Message, opts processOptions) (string, int, error) {
+	provider, model := al.requestModelFor(ctx, opts.Channel)
+	maxIterations := tools.MaxIterationsFromContext(ctx, al.maxIterations)
+	temperature, maxTokens := al.temperature, al.maxTokens
+	reqOpts := requestOptionsFrom(ctx)
+	if reqOpts.Temperature != nil {
+		temperature = *reqOpts.Temperature
+	}
+	if reqOpts.MaxTokens > 0 {
+		maxTokens = reqOpts.MaxTokens
+	}
+	sink := streamSinkFrom(ctx)
 	iteration := 0
-	var finalContent string
+	reply := tools.Continuation{Max: al.continuations}
 
-	for iteration < al.maxIterations {
+	for iteration < maxIterations {
 		iteration++
 
-		logger.DebugCF("agent", "LLM iteration",
+		logger.DebugCtx(ctx, "agent", "LLM iteration",
 			map[string]interface{}{
 				"iteration": iteration,
-				"max":       al.maxIterations,
+				"max":       maxIterations,
 			})
 
-		// Build tool definitions
-		providerToolDefs := al.tools.ToProviderDefs()
+		// Build tool definitions, unless the model cannot call tools
+		var providerToolDefs []providers.ToolDefinition
+		if info, _ := providers.LookupModel(model); info.SupportsTools {
+			providerToolDefs = al.tools.ToProviderDefs()
+		}
 
 		// Log LLM request details
-		logger.DebugCF("agent", "LLM request",
+		logger.DebugCtx(ctx, "agent", "LLM request",
 			map[string]interface{}{
 				"iteration":         iteration,
-				"model":             al.model,
+				"model":             model,
 				"messages_count":    len(messages),
 				"tools_count":       len(providerToolDefs),
-				"max_tokens":        8192,
-				"temperature":       0.7,
+				"max_tokens":        maxTokens,
+				"temperature":       temperature,
 				"system_prompt_len": len(messages[0].Content),
 			})
 
 		// Log full messages (detailed)
-		logger.DebugCF("agent", "Full LLM request",
+		logger.DebugCtx(ctx, "agent", "Full LLM request",
 			map[string]interface{}{
 				"iteration":     iteration,
 				"messages_json": formatMessagesForLog(messages),
@@ -483,29 +939,59 @@ func (al *AgentLoop) runLLMIteration(ctx context.Context, messages []providers.M
 		var response *providers.LLMResponse
 		var err error
 
-		// Retry loop for context/token errors
+		// Retry loop for context/token errors and transient provider failures
 		maxRetries := 2
 		for retry := 0; retry <= maxRetries; retry++ {
-			response, err = al.provider.Chat(ctx, messages, providerToolDefs, al.model, map[string]interface{}{
-				"max_tokens":  8192,
-				"temperature": 0.7,
-			})
+			options := map[string]interface{}{
+				"max_tokens":  maxTokens,
+				"temperature": temperature,
+			}
+			streamed := 0
+			if sink != nil {
+				sink.begin(len(providerToolDefs) == 0)
+				streamed = sink.sent
+				response, err = providers.ChatStream(ctx, provider, messages, providerToolDefs, model, options, sink.add)
+			} else {
+				response, err = provider.Chat(ctx, messages, providerToolDefs, model, options)
+			}
 
 			if err == nil {
+				providers.RecordUsage(ctx, model, response.Usage)
 				break // Success
 			}
 
+			// Part of the reply already reached the stream; a retry would
+			// send it again.
+			if sink != nil && sink.sent > streamed {
+				break
+			}
+
+			if providers.IsRetriable(err) && ctx.Err() == nil && retry < maxRetries {
+				delay := retryDelay(err, retry)
+				logger.WarnCtx(ctx, "agent", "Transient provider error, retrying", map[string]interface{}{
+					"error": err.Error(),
+					"retry": retry,
+					"delay": delay.String(),
+				})
+				if waitErr := sleepCtx(ctx, delay); waitErr == nil {
+					continue
+				}
+			}
+
 			errMsg := strings.ToLower(err.Error())
-			// Check for context window errors (provider specific, but usually contain "token" or "invalid")
+			// Check for context window errors (provider specific, but usually contain "token" or "invalid").
+			// Only a rejected request can be one; rate limits also talk about tokens.
+			kind := providers.ErrorKindOf(err)
 			isContextWindowError := (strings.Contains(errMsg, "token") ||
 				strings.Contains(errMsg, "context") ||
 				strings.Contains(errMsg, "invalidparameter") ||
 				strings.Contains(errMsg, "length")) &&
+				(kind == "" || kind == providers.ErrorBadRequest) &&
 				!errors.Is(err, context.Canceled) &&
 				!errors.Is(err, context.DeadlineExceeded)
 
 			if isContextWindowError && retry < maxRetries {
-				logger.WarnCF("agent", "Context window error detected, attempting compression", map[string]interface{}{
+				logger.WarnCtx(ctx, "agent", "Context window error detected, attempting compression", map[string]interface{}{
 					"error": err.Error(),
 					"retry": retry,
 				})
@@ -609,7 +1095,7 @@ func (al *AgentLoop) runLLMIteration(ctx context.Context, messages []providers.M
 		}
 
 		if err != nil {
-			logger.ErrorCF("agent", "LLM call failed",
+			logger.ErrorCtx(ctx, "agent", "LLM call failed",
 				map[string]interface{}{
 					"iteration": iteration,
 					"error":     err.Error(),
@@ -619,11 +1105,22 @@ func (al *AgentLoop) runLLMIteration(ctx context.Context, messages []providers.M
 
 		// Check if no tool calls - we're done
 		if len(response.ToolCalls) == 0 {
-			finalContent = response.Content
-			logger.InfoCF("agent", "LLM response without tool calls (direct answer)",
+			if sink != nil {
+				sink.commit()
+			}
+			var more bool
+			if messages, more = reply.Add(messages, response); more {
+				logger.InfoCtx(ctx, "agent", "LLM reply cut off at max_tokens, continuing",
+					map[string]interface{}{
+						"iteration":    iteration,
+						"continuation": reply.Count(),
+					})
+				continue
+			}
+			logger.InfoCtx(ctx, "agent", "LLM response without tool calls (direct answer)",
 				map[string]interface{}{
 					"iteration":     iteration,
-					"content_chars": len(finalContent),
+					"content_chars": len(reply.Content()),
 				})
 			break
 		}
@@ -633,7 +1130,7 @@ func (al *AgentLoop) runLLMIteration(ctx context.Context, messages []providers.M
 		for _, tc := range response.ToolCalls {
 			toolNames = append(toolNames, tc.Name)
 		}
-		logger.InfoCF("agent", "LLM requested tool calls",
+		logger.InfoCtx(ctx, "agent", "LLM requested tool calls",
 			map[string]interface{}{
 				"tools":     toolNames,
 				"count":     len(response.ToolCalls),
@@ -666,7 +1163,7 @@ func (al *AgentLoop) runLLMIteration(ctx context.Context, messages []providers.M
 			// Log tool call with arguments preview
 			argsJSON, _ := json.Marshal(tc.Arguments)
 			argsPreview := utils.Truncate(string(argsJSON), 200)
-			logger.InfoCF("agent", fmt.Sprintf("Tool call: %s(%s)", tc.Name, argsPreview),
+			logger.InfoCtx(ctx, "agent", fmt.Sprintf("Tool call: %s(%s)", tc.Name, argsPreview),
 				map[string]interface{}{
 					"tool":      tc.Name,
 					"iteration": iteration,
@@ -680,7 +1177,7 @@ func (al *AgentLoop) runLLMIteration(ctx context.Context, messages []providers.M
 				// Log the async completion but don't send directly to user
 				// The agent will handle user notification via processSystemMessage
 				if !result.Silent && result.ForUser != "" {
-					logger.InfoCF("agent", "Async tool completed, agent will handle notification",
+					logger.InfoCtx(ctx, "agent", "Async tool completed, agent will handle notification",
 						map[string]interface{}{
 							"tool":        tc.Name,
 							"content_len": len(result.ForUser),
@@ -688,7 +1185,11 @@ func (al *AgentLoop) runLLMIteration(ctx context.Context, messages []providers.M
 				}
 			}
 
+			toolStart := time.Now()
 			toolResult := al.tools.ExecuteWithContext(ctx, tc.Name, tc.Arguments, opts.Channel, opts.ChatID, asyncCallback)
+			if err := al.traces.Record(opts.SessionKey, tc.Name, tc.Arguments, toolResult, time.Since(toolStart)); err != nil {
+				logger.WarnCtx(ctx, "agent", "Failed to record tool trace", map[string]interface{}{"error": err.Error()})
+			}
 
 			// Send ForUser content to user immediately if not Silent
 			if !toolResult.Silent && toolResult.ForUser != "" && opts.SendResponse {
@@ -697,7 +1198,7 @@ func (al *AgentLoop) runLLMIteration(ctx context.Context, messages []providers.M
 					ChatID:  opts.ChatID,
 					Content: toolResult.ForUser,
 				})
-				logger.DebugCF("agent", "Sent tool result to user",
+				logger.DebugCtx(ctx, "agent", "Sent tool result to user",
 					map[string]interface{}{
 						"tool":        tc.Name,
 						"content_len": len(toolResult.ForUser),
@@ -709,6 +1210,7 @@ func (al *AgentLoop) runLLMIteration(ctx context.Context, messages []providers.M
 			if contentForLLM == "" && toolResult.Err != nil {
 				contentForLLM = toolResult.Err.Error()
 			}
+			contentForLLM = al.outputLimit.Apply(tc.Name, tc.ID, contentForLLM)
 
 			toolResultMsg := providers.Message{
 				Role:       "tool",
@@ -722,7 +1224,17 @@ func (al *AgentLoop) runLLMIteration(ctx context.Context, messages []providers.M
 		}
 	}
 
-	return finalContent, iteration, nil
+	if reply.Truncated() {
+		logger.WarnCtx(ctx, "agent", "LLM reply truncated at max_tokens",
+			map[string]interface{}{
+				"iteration":     iteration,
+				"continuations": reply.Count(),
+			})
+		if report := turnReportFrom(ctx); report != nil {
+			report.Truncated = true
+		}
+	}
+	return reply.Content(), iteration, nil
 }
 
 // updateToolContexts updates the context for tools that need channel/chatID info.
@@ -746,12 +1258,12 @@ func (al *AgentLoop) updateToolContexts(channel, chatID string) {
 }
 
 // maybeSummarize triggers summarization if the session history exceeds thresholds.
-func (al *AgentLoop) maybeSummarize(sessionKey, channel, chatID string) {
+func (al *AgentLoop) maybeSummarize(ctx context.Context, sessionKey, channel, chatID string) {
 	newHistory := al.sessions.GetHistory(sessionKey)
 	tokenEstimate := al.estimateTokens(newHistory)
-	threshold := al.contextWindow * 75 / 100
+	threshold := al.contextWindowFor(ctx, channel) * al.summary.tokenPercent / 100
 
-	if len(newHistory) > 20 || tokenEstimate > threshold {
+	if len(newHistory) > al.summary.maxMessages || tokenEstimate > threshold {
 		if _, loading := al.summarizing.LoadOrStore(sessionKey, true); !loading {
 			go func() {
 				defer al.summarizing.Delete(sessionKey)
@@ -829,21 +1341,37 @@ func (al *AgentLoop) forceCompression(sessionKey string) {
 	})
 }
 
-// GetStartupInfo returns information about loaded tools and skills for logging.
-func (al *AgentLoop) GetStartupInfo() map[string]interface{} {
-	info := make(map[string]interface{})
+// StartupInfo summarises what the agent loaded at startup.
+type StartupInfo struct {
+	Model  string     `json:"model"`
+	Tools  ToolsInfo  `json:"tools"`
+	Skills SkillsInfo `json:"skills"`
+}
 
-	// Tools info
-	tools := al.tools.List()
-	info["tools"] = map[string]interface{}{
-		"count": len(tools),
-		"names": tools,
-	}
+// ToolsInfo lists the registered tools.
+type ToolsInfo struct {
+	Count int      `json:"count"`
+	Names []string `json:"names"`
+}
 
-	// Skills info
-	info["skills"] = al.contextBuilder.GetSkillsInfo()
+// SkillsInfo lists the skills visible to the agent.
+type SkillsInfo struct {
+	Total     int      `json:"total"`
+	Available int      `json:"available"`
+	Names     []string `json:"names"`
+}
 
-	return info
+// GetStartupInfo returns information about loaded tools and skills for logging.
+func (al *AgentLoop) GetStartupInfo() StartupInfo {
+	tools := al.tools.List()
+	return StartupInfo{
+		Model: al.model,
+		Tools: ToolsInfo{
+			Count: len(tools),
+			Names: tools,
+		},
+		Skills: al.contextBuilder.GetSkillsInfo(),
+	}
 }
 
 // formatMessagesForLog formats messages for logging
@@ -914,7 +1442,7 @@ func (al *AgentLoop) summarizeSession(sessionKey string) {
 
 	// Oversized Message Guard
 	// Skip messages larger than 50% of context window to prevent summarizer overflow
-	maxMessageTokens := al.contextWindow / 2
+	maxMessageTokens := al.contextWindowFor(context.Background(), "") / 2
 	validMessages := make([]providers.Message, 0)
 	omitted := false
 
@@ -953,6 +1481,7 @@ func (al *AgentLoop) summarizeSession(sessionKey string) {
 			"temperature": 0.3,
 		})
 		if err == nil {
+			providers.RecordUsage(ctx, al.model, resp.Usage)
 			finalSummary = resp.Content
 		} else {
 			finalSummary = s1 + " " + s2
@@ -990,6 +1519,7 @@ func (al *AgentLoop) summarizeBatch(ctx context.Context, batch []providers.Messa
 	if err != nil {
 		return "", err
 	}
+	providers.RecordUsage(ctx, al.model, response.Usage)
 	return response.Content, nil
 }
 
@@ -1026,7 +1556,8 @@ func (al *AgentLoop) handleCommand(ctx context.Context, msg bus.InboundMessage)
 		}
 		switch args[0] {
 		case "model":
-			return fmt.Sprintf("Current model: %s", al.model), true
+			_, model := al.modelFor(msg.Channel)
+			return fmt.Sprintf("Current model: %s", model), true
 		case "channel":
 			return fmt.Sprintf("Current channel: %s", msg.Channel), true
 		default:
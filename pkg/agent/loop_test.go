@@ -5,12 +5,16 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/Sterlites/RDxClaw/pkg/bus"
 	"github.com/Sterlites/RDxClaw/pkg/config"
 	"github.com/Sterlites/RDxClaw/pkg/providers"
+	"github.com/Sterlites/RDxClaw/pkg/reqctx"
+	"github.com/Sterlites/RDxClaw/pkg/session"
+	"github.com/Sterlites/RDxClaw/pkg/skills"
 	"github.com/Sterlites/RDxClaw/pkg/tools"
 )
 
@@ -185,8 +189,7 @@ func TestToolRegistry_ToolRegistration(t *testing.T) {
 	// Verify tool is registered by checking it doesn't panic on GetStartupInfo
 	// (actual tool retrieval is tested in tools package tests)
 	info := al.GetStartupInfo()
-	toolsInfo := info["tools"].(map[string]interface{})
-	toolsList := toolsInfo["names"].([]string)
+	toolsList := info.Tools.Names
 
 	// Check that our custom tool name is in the list
 	found := false
@@ -201,6 +204,43 @@ func TestToolRegistry_ToolRegistration(t *testing.T) {
 	}
 }
 
+// TestAgentLoop_RegistersSkillScripts verifies that manifest scripts of
+// installed skills are offered to the model as tools
+func TestAgentLoop_RegistersSkillScripts(t *testing.T) {
+	tmpDir := t.TempDir()
+	skillDir := filepath.Join(tmpDir, "skills", "echo")
+	if err := os.MkdirAll(skillDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	manifest := &skills.SkillManifest{
+		Name: "echo", Version: "1.0.0", Description: "Echo input",
+		Scripts: []skills.ScriptSpec{{Path: "echo.sh", Runtime: "shell", Description: "Echo the arguments"}},
+	}
+	if err := skills.SaveManifest(skillDir, manifest); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         tmpDir,
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), &mockProvider{})
+
+	tool, ok := al.tools.Get("echo_echo")
+	if !ok {
+		t.Fatal("Expected skill script to be registered as a tool")
+	}
+	if tool.Description() != "Echo the arguments" {
+		t.Errorf("Description = %q", tool.Description())
+	}
+}
+
 // TestToolContext_Updates verifies tool context is updated with channel/chatID
 func TestToolContext_Updates(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "agent-test-*")
@@ -260,8 +300,7 @@ func TestToolRegistry_GetDefinitions(t *testing.T) {
 	al.RegisterTool(testTool)
 
 	info := al.GetStartupInfo()
-	toolsInfo := info["tools"].(map[string]interface{})
-	toolsList := toolsInfo["names"].([]string)
+	toolsList := info.Tools.Names
 
 	// Check that our custom tool name is in the list
 	found := false
@@ -301,26 +340,17 @@ func TestAgentLoop_GetStartupInfo(t *testing.T) {
 
 	info := al.GetStartupInfo()
 
-	// Verify tools info exists
-	toolsInfo, ok := info["tools"]
-	if !ok {
-		t.Fatal("Expected 'tools' key in startup info")
-	}
-
-	toolsMap, ok := toolsInfo.(map[string]interface{})
-	if !ok {
-		t.Fatal("Expected 'tools' to be a map")
-	}
-
-	count, ok := toolsMap["count"]
-	if !ok {
-		t.Fatal("Expected 'count' in tools info")
+	if info.Model != "test-model" {
+		t.Errorf("Expected model test-model, got %q", info.Model)
 	}
 
 	// Should have default tools registered
-	if count.(int) == 0 {
+	if info.Tools.Count == 0 {
 		t.Error("Expected at least some tools to be registered")
 	}
+	if info.Tools.Count != len(info.Tools.Names) {
+		t.Errorf("Tools count %d does not match %d names", info.Tools.Count, len(info.Tools.Names))
+	}
 }
 
 // TestAgentLoop_Stop verifies Stop() sets running to false
@@ -624,3 +654,767 @@ func TestAgentLoop_ContextExhaustionRetry(t *testing.T) {
 		t.Errorf("Expected history to be compressed (len < 8), got %d", len(finalHistory))
 	}
 }
+
+// capturingMockProvider records the messages of the last Chat call
+type capturingMockProvider struct {
+	messages []providers.Message
+}
+
+func (m *capturingMockProvider) Chat(ctx context.Context, messages []providers.Message, tools []providers.ToolDefinition, model string, opts map[string]interface{}) (*providers.LLMResponse, error) {
+	m.messages = messages
+	return &providers.LLMResponse{Content: "I see it"}, nil
+}
+
+func (m *capturingMockProvider) GetDefaultModel() string {
+	return "mock-model"
+}
+
+// TestAgentLoop_ImageAttachments verifies images reach vision models as content parts
+// and non-vision models as a text note.
+func TestAgentLoop_ImageAttachments(t *testing.T) {
+	tests := []struct {
+		name      string
+		model     string
+		wantParts int
+		wantNote  bool
+	}{
+		{name: "vision model", model: "gpt-4o", wantParts: 1},
+		{name: "text-only model", model: "llama3", wantNote: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				Agents: config.AgentsConfig{
+					Defaults: config.AgentDefaults{
+						Workspace:         t.TempDir(),
+						Model:             tt.model,
+						MaxTokens:         4096,
+						MaxToolIterations: 10,
+					},
+				},
+			}
+
+			provider := &capturingMockProvider{}
+			al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+
+			msg := bus.InboundMessage{
+				Channel:    "telegram",
+				SenderID:   "user1",
+				ChatID:     "chat1",
+				Content:    "what is this?",
+				SessionKey: "telegram:chat1",
+				Attachments: []bus.Attachment{
+					{Type: "image", Filename: "cat.png", MimeType: "image/png", Data: []byte("png-bytes")},
+				},
+			}
+			testHelper{al: al}.executeAndGetResponse(t, context.Background(), msg)
+
+			last := provider.messages[len(provider.messages)-1]
+			if len(last.Parts) != tt.wantParts {
+				t.Fatalf("Expected %d content parts, got %d", tt.wantParts, len(last.Parts))
+			}
+			if tt.wantParts > 0 && last.Parts[0].MimeType != "image/png" {
+				t.Errorf("Expected image/png part, got %q", last.Parts[0].MimeType)
+			}
+			hasNote := strings.Contains(last.Content, "cannot view images")
+			if hasNote != tt.wantNote {
+				t.Errorf("Expected note=%v, got content %q", tt.wantNote, last.Content)
+			}
+
+			for _, h := range al.sessions.GetHistory("telegram:chat1") {
+				if len(h.Parts) > 0 {
+					t.Error("Image parts must not be persisted in session history")
+				}
+			}
+		})
+	}
+}
+
+// modelRecordingProvider records the model of every Chat call
+type modelRecordingProvider struct {
+	models []string
+}
+
+func (m *modelRecordingProvider) Chat(ctx context.Context, messages []providers.Message, tools []providers.ToolDefinition, model string, opts map[string]interface{}) (*providers.LLMResponse, error) {
+	m.models = append(m.models, model)
+	return &providers.LLMResponse{Content: "ok"}, nil
+}
+
+func (m *modelRecordingProvider) GetDefaultModel() string {
+	return "mock-model"
+}
+
+// TestAgentLoop_ChannelModelOverride verifies each channel uses its configured model
+func TestAgentLoop_ChannelModelOverride(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "default-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+			Channels: map[string]config.ChannelAgentConfig{
+				"discord":  {Model: "fast-model"},
+				"telegram": {Model: "strong-model"},
+			},
+		},
+	}
+
+	provider := &modelRecordingProvider{}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+	helper := testHelper{al: al}
+
+	for _, channel := range []string{"discord", "telegram", "slack"} {
+		helper.executeAndGetResponse(t, context.Background(), bus.InboundMessage{
+			Channel:    channel,
+			SenderID:   "user1",
+			ChatID:     "chat1",
+			Content:    "hello",
+			SessionKey: channel + ":chat1",
+		})
+	}
+
+	want := []string{"fast-model", "strong-model", "default-model"}
+	if len(provider.models) != len(want) {
+		t.Fatalf("Expected %d calls, got %d", len(want), len(provider.models))
+	}
+	for i, model := range want {
+		if provider.models[i] != model {
+			t.Errorf("Call %d: expected model %q, got %q", i, model, provider.models[i])
+		}
+	}
+}
+
+// TestAgentLoop_RequestModelOverride verifies a request's model is resolved
+// to a provider and used for the turn
+func TestAgentLoop_RequestModelOverride(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "default-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+		Providers: config.ProvidersConfig{
+			Anthropic: config.ProviderConfig{APIKey: "test-key"},
+		},
+	}
+
+	provider := &modelRecordingProvider{}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+
+	ctx := WithRequestOptions(context.Background(), RequestOptions{Model: "local-model"})
+	if _, err := al.ProcessDirectWithChannel(ctx, "hello", "api:1", "api", "api"); err != nil {
+		t.Fatalf("ProcessDirectWithChannel failed: %v", err)
+	}
+	if len(provider.models) != 1 || provider.models[0] != "local-model" {
+		t.Errorf("Expected the default provider to serve an undetected model, got %v", provider.models)
+	}
+
+	ctx = WithRequestOptions(context.Background(), RequestOptions{Model: "claude-sonnet-4"})
+	p, model := al.requestModelFor(ctx, "api")
+	if model != "claude-sonnet-4" {
+		t.Errorf("Expected model claude-sonnet-4, got %q", model)
+	}
+	if p == providers.LLMProvider(provider) {
+		t.Error("Expected a provider detected from the model name")
+	}
+	if again, _ := al.requestModelFor(ctx, "api"); again != p {
+		t.Error("Expected the provider to be created once per model")
+	}
+
+	cfg.Agents.Defaults.Provider = "anthropic"
+	al = NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+	if p, _ := al.requestModelFor(ctx, "api"); p != providers.LLMProvider(provider) {
+		t.Error("Expected the configured provider to serve every model")
+	}
+}
+
+// TestAgentLoop_PauseResume verifies inbound messages are held while paused
+// and processed in order after resume.
+func TestAgentLoop_PauseResume(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+
+	msgBus := bus.NewMessageBus()
+	al := NewAgentLoop(cfg, msgBus, &simpleMockProvider{response: "pong"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	al.Pause()
+	if !al.IsPaused() {
+		t.Fatal("Expected loop to report paused")
+	}
+	go al.Run(ctx)
+
+	for _, content := range []string{"first", "second"} {
+		msgBus.PublishInbound(bus.InboundMessage{
+			Channel:    "test",
+			SenderID:   "user1",
+			ChatID:     "chat1",
+			Content:    content,
+			SessionKey: "test:chat1",
+		})
+	}
+
+	heldCtx, heldCancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer heldCancel()
+	if out, ok := msgBus.SubscribeOutbound(heldCtx); ok {
+		t.Fatalf("Expected no response while paused, got %+v", out)
+	}
+
+	al.Resume()
+	if al.IsPaused() {
+		t.Fatal("Expected loop to report resumed")
+	}
+
+	for i := 0; i < 2; i++ {
+		outCtx, outCancel := context.WithTimeout(ctx, 2*time.Second)
+		out, ok := msgBus.SubscribeOutbound(outCtx)
+		outCancel()
+		if !ok {
+			t.Fatalf("Expected response %d after resume", i+1)
+		}
+		if out.Content != "pong" {
+			t.Errorf("Expected 'pong', got %q", out.Content)
+		}
+	}
+}
+
+// toolLoopingProvider requests a tool call on every turn, so the agent only
+// stops when it reaches its iteration cap.
+type toolLoopingProvider struct {
+	calls int
+}
+
+func (m *toolLoopingProvider) Chat(ctx context.Context, messages []providers.Message, tools []providers.ToolDefinition, model string, opts map[string]interface{}) (*providers.LLMResponse, error) {
+	m.calls++
+	return &providers.LLMResponse{
+		ToolCalls: []providers.ToolCall{{
+			ID:        fmt.Sprintf("call-%d", m.calls),
+			Name:      "no_such_tool",
+			Arguments: map[string]interface{}{},
+		}},
+	}, nil
+}
+
+func (m *toolLoopingProvider) GetDefaultModel() string {
+	return "mock-model"
+}
+
+// TestAgentLoop_MaxIterationsOverride verifies a per-request cap replaces
+// the configured default.
+func TestAgentLoop_MaxIterationsOverride(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		override int
+		want     int
+	}{
+		{"config default", 0, 10},
+		{"request override", 3, 3},
+		{"clamped to limit", tools.MaxIterationsLimit + 50, tools.MaxIterationsLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &toolLoopingProvider{}
+			al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+
+			ctx := tools.WithMaxIterations(context.Background(), tt.override)
+			if _, err := al.ProcessDirectWithChannel(ctx, "loop forever", "test-session", "test", "chat1"); err != nil {
+				t.Fatalf("ProcessDirectWithChannel failed: %v", err)
+			}
+
+			if provider.calls != tt.want {
+				t.Errorf("Expected %d LLM calls, got %d", tt.want, provider.calls)
+			}
+		})
+	}
+}
+
+func TestDefaultMaxIterations(t *testing.T) {
+	tests := []struct {
+		configured, want int
+	}{
+		{0, defaultToolIterations},
+		{-5, defaultToolIterations},
+		{7, 7},
+		{tools.MaxIterationsLimit + 1, tools.MaxIterationsLimit},
+	}
+	for _, tt := range tests {
+		if got := defaultMaxIterations(tt.configured, defaultToolIterations); got != tt.want {
+			t.Errorf("defaultMaxIterations(%d) = %d, want %d", tt.configured, got, tt.want)
+		}
+	}
+}
+
+// TestBuiltinToolsAreReserved keeps tools.IsReservedName in sync with the
+// tools the agent actually registers, so skills cannot shadow them.
+func TestBuiltinToolsAreReserved(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), &mockProvider{})
+
+	for _, name := range al.tools.List() {
+		if !tools.IsReservedName(name) {
+			t.Errorf("built-in tool %q is missing from the reserved names in pkg/tools/reserved.go", name)
+		}
+	}
+}
+
+// TestAgentLoop_SummarizesLongHistory verifies that a long session is
+// summarized into a persisted memory that replaces its oldest turns.
+func TestAgentLoop_SummarizesLongHistory(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		t.Run(fmt.Sprintf("enabled=%v", enabled), func(t *testing.T) {
+			workspace := t.TempDir()
+			cfg := &config.Config{
+				Agents: config.AgentsConfig{
+					Defaults: config.AgentDefaults{
+						Workspace:              workspace,
+						Model:                  "test-model",
+						MaxTokens:              4096,
+						MaxToolIterations:      10,
+						SummarizeHistory:       enabled,
+						SummarizeAfterMessages: 10,
+					},
+				},
+			}
+			al := NewAgentLoop(cfg, bus.NewMessageBus(), &simpleMockProvider{response: "the user planned a trip"})
+
+			const sessionKey = "cli:long"
+			for i := 0; i < 30; i++ {
+				al.sessions.AddMessage(sessionKey, "user", fmt.Sprintf("question %d", i))
+				al.sessions.AddMessage(sessionKey, "assistant", fmt.Sprintf("answer %d", i))
+			}
+
+			if _, err := al.ProcessDirectWithChannel(context.Background(), "what next?", sessionKey, "cli", "direct"); err != nil {
+				t.Fatalf("ProcessDirectWithChannel failed: %v", err)
+			}
+
+			if !enabled {
+				time.Sleep(50 * time.Millisecond)
+				if summary := al.sessions.GetSummary(sessionKey); summary != "" {
+					t.Errorf("summarization disabled but got summary %q", summary)
+				}
+				return
+			}
+
+			// Summarization runs in the background; wait for it to finish.
+			deadline := time.Now().Add(5 * time.Second)
+			for time.Now().Before(deadline) {
+				_, busy := al.summarizing.Load(sessionKey)
+				if !busy && al.sessions.GetSummary(sessionKey) != "" {
+					break
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+			if al.sessions.GetSummary(sessionKey) == "" {
+				t.Fatal("expected history to be summarized")
+			}
+			if n := len(al.sessions.GetHistory(sessionKey)); n > 10 {
+				t.Errorf("expected history to shrink, still %d messages", n)
+			}
+
+			reloaded := session.NewSessionManager(filepath.Join(workspace, "sessions"))
+			if reloaded.GetSummary(sessionKey) == "" {
+				t.Error("summary was not persisted with the session")
+			}
+		})
+	}
+}
+
+// TestAgentLoop_DeliversSwarmResultToOrigin verifies a background agent's
+// result reaches the chat that spawned it after the spawning turn is over.
+func TestAgentLoop_DeliversSwarmResultToOrigin(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+
+	msgBus := bus.NewMessageBus()
+	al := NewAgentLoop(cfg, msgBus, &simpleMockProvider{response: "report ready"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go al.Run(ctx)
+
+	if _, err := al.GetSwarmManager().Spawn(ctx, "Write the report", "reporter", "telegram", "chat42", nil); err != nil {
+		t.Fatalf("Spawn failed: %v", err)
+	}
+
+	outCtx, outCancel := context.WithTimeout(ctx, 2*time.Second)
+	defer outCancel()
+	out, ok := msgBus.SubscribeOutbound(outCtx)
+	if !ok {
+		t.Fatal("Expected the agent's result to be delivered")
+	}
+	if out.Channel != "telegram" || out.ChatID != "chat42" {
+		t.Errorf("Expected delivery to telegram:chat42, got %s:%s", out.Channel, out.ChatID)
+	}
+	if !strings.Contains(out.Content, "report ready") {
+		t.Errorf("Expected result in delivered message, got %q", out.Content)
+	}
+}
+
+// silentMockProvider stays silent on "ping" and answers everything else.
+type silentMockProvider struct{}
+
+func (m *silentMockProvider) Chat(ctx context.Context, messages []providers.Message, tools []providers.ToolDefinition, model string, opts map[string]interface{}) (*providers.LLMResponse, error) {
+	if messages[len(messages)-1].Content == "ping" {
+		return &providers.LLMResponse{Content: " NO_REPLY\n"}, nil
+	}
+	return &providers.LLMResponse{Content: "pong"}, nil
+}
+
+func (m *silentMockProvider) GetDefaultModel() string {
+	return "mock-model"
+}
+
+// TestAgentLoop_SilentReplyNotSent verifies a silent reply is kept in the
+// session but never published to the channel.
+func TestAgentLoop_SilentReplyNotSent(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+				SilentReplyToken:  config.DefaultSilentReplyToken,
+			},
+		},
+	}
+
+	msgBus := bus.NewMessageBus()
+	al := NewAgentLoop(cfg, msgBus, &silentMockProvider{})
+
+	if !strings.Contains(al.contextBuilder.BuildSystemPrompt(), "respond with exactly NO_REPLY") {
+		t.Error("Expected the silent reply rule in the system prompt")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), responseTimeout)
+	defer cancel()
+	go al.Run(ctx)
+	defer al.Stop()
+
+	for _, content := range []string{"ping", "hello"} {
+		msgBus.PublishInbound(bus.InboundMessage{
+			Channel:    "telegram",
+			SenderID:   "user1",
+			ChatID:     "chat1",
+			Content:    content,
+			SessionKey: "telegram:chat1",
+		})
+	}
+
+	// Messages are handled in order, so the first outbound must be the
+	// answer to "hello".
+	out, ok := msgBus.SubscribeOutbound(ctx)
+	if !ok {
+		t.Fatal("Timed out waiting for outbound message")
+	}
+	if out.Content != "pong" {
+		t.Errorf("Expected only the reply to hello to be sent, got %q", out.Content)
+	}
+
+	history := al.sessions.GetHistory("telegram:chat1")
+	if len(history) < 2 || !config.IsSilentReply(history[1].Content, config.DefaultSilentReplyToken) {
+		t.Errorf("Expected the silent reply to be kept in the session, got %+v", history)
+	}
+}
+
+type usageMockProvider struct{}
+
+func (m *usageMockProvider) Chat(ctx context.Context, messages []providers.Message, tools []providers.ToolDefinition, model string, opts map[string]interface{}) (*providers.LLMResponse, error) {
+	return &providers.LLMResponse{
+		Content: "done",
+		Usage:   &providers.UsageInfo{PromptTokens: 10, CompletionTokens: 3, TotalTokens: 13},
+	}, nil
+}
+
+func (m *usageMockProvider) GetDefaultModel() string {
+	return "usage-test-model"
+}
+
+func TestAgentLoop_RecordsUsage(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "usage-test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), &usageMockProvider{})
+
+	before := providers.DefaultUsage.Snapshot()["usage-test-model"]
+	for i := 0; i < 2; i++ {
+		if _, err := al.ProcessDirect(context.Background(), "hi", "test-session"); err != nil {
+			t.Fatalf("ProcessDirect failed: %v", err)
+		}
+	}
+	after := providers.DefaultUsage.Snapshot()["usage-test-model"]
+
+	if got := after.Requests - before.Requests; got != 2 {
+		t.Errorf("requests grew by %d, want 2", got)
+	}
+	if got := after.PromptTokens - before.PromptTokens; got != 20 {
+		t.Errorf("prompt tokens grew by %d, want 20", got)
+	}
+	if got := after.CompletionTokens - before.CompletionTokens; got != 6 {
+		t.Errorf("completion tokens grew by %d, want 6", got)
+	}
+}
+
+// toolThenAnswerProvider calls the memory tool once, then answers.
+type toolThenAnswerProvider struct {
+	calls int
+}
+
+func (m *toolThenAnswerProvider) Chat(ctx context.Context, messages []providers.Message, tools []providers.ToolDefinition, model string, opts map[string]interface{}) (*providers.LLMResponse, error) {
+	m.calls++
+	if m.calls == 1 {
+		return &providers.LLMResponse{
+			ToolCalls: []providers.ToolCall{{
+				ID:        "call_1",
+				Type:      "function",
+				Name:      "memory",
+				Arguments: map[string]interface{}{"action": "set", "key": "favorite_color", "value": "teal"},
+			}},
+		}, nil
+	}
+	return &providers.LLMResponse{Content: "Remembered."}, nil
+}
+
+func (m *toolThenAnswerProvider) GetDefaultModel() string {
+	return "mock-model"
+}
+
+func TestAgentLoop_SelfTraceReturnsToolCalls(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), &toolThenAnswerProvider{})
+
+	if _, err := al.ProcessDirect(context.Background(), "remember teal", "cli:trace"); err != nil {
+		t.Fatalf("ProcessDirect failed: %v", err)
+	}
+
+	ctx := reqctx.WithSessionKey(context.Background(), "cli:trace")
+	result := al.tools.Execute(ctx, "self_trace", map[string]interface{}{})
+	if result.IsError {
+		t.Fatalf("self_trace failed: %s", result.ForLLM)
+	}
+	if !strings.Contains(result.ForLLM, "memory(") || !strings.Contains(result.ForLLM, "favorite_color") {
+		t.Errorf("self_trace did not return the memory call:\n%s", result.ForLLM)
+	}
+
+	// Traces are scoped to the session that made the calls.
+	other := reqctx.WithSessionKey(context.Background(), "cli:other")
+	result = al.tools.Execute(other, "self_trace", map[string]interface{}{})
+	if strings.Contains(result.ForLLM, "favorite_color") {
+		t.Error("self_trace leaked another session's calls")
+	}
+}
+
+// gatedMockProvider signals each call on started and answers once release
+// is closed.
+type gatedMockProvider struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (m *gatedMockProvider) Chat(ctx context.Context, messages []providers.Message, tools []providers.ToolDefinition, model string, opts map[string]interface{}) (*providers.LLMResponse, error) {
+	m.started <- struct{}{}
+	<-m.release
+	return &providers.LLMResponse{Content: "done"}, nil
+}
+
+func (m *gatedMockProvider) GetDefaultModel() string {
+	return "mock-model"
+}
+
+// TestAgentLoop_DrainFinishesCurrentMessage verifies Drain waits for the
+// message being processed and that its reply is still published.
+func TestAgentLoop_DrainFinishesCurrentMessage(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+
+	msgBus := bus.NewMessageBus()
+	provider := &gatedMockProvider{started: make(chan struct{}, 1), release: make(chan struct{})}
+	al := NewAgentLoop(cfg, msgBus, provider)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go al.Run(ctx)
+
+	msgBus.PublishInbound(bus.InboundMessage{
+		Channel:    "test",
+		SenderID:   "user1",
+		ChatID:     "chat1",
+		Content:    "work",
+		SessionKey: "test:chat1",
+	})
+	<-provider.started
+
+	drained := make(chan error, 1)
+	go func() { drained <- al.Drain(context.Background()) }()
+
+	select {
+	case err := <-drained:
+		t.Fatalf("Drain returned (%v) while a message was in flight", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(provider.release)
+	select {
+	case err := <-drained:
+		if err != nil {
+			t.Fatalf("Drain() error = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Drain did not return after the message finished")
+	}
+
+	outCtx, outCancel := context.WithTimeout(ctx, time.Second)
+	defer outCancel()
+	out, ok := msgBus.SubscribeOutbound(outCtx)
+	if !ok || out.Content != "done" {
+		t.Fatalf("Expected the drained message's reply, got %+v (ok=%v)", out, ok)
+	}
+}
+
+// TestAgentLoop_DrainIdle verifies Drain returns promptly when the loop is
+// waiting for messages, and when it never ran, and that a loop started
+// after Drain stays stopped.
+func TestAgentLoop_DrainIdle(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+
+	// A Run that starts after Drain returned must not process anything.
+	msgBus := bus.NewMessageBus()
+	late := NewAgentLoop(cfg, msgBus, &mockProvider{})
+	if err := late.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain() before Run error = %v", err)
+	}
+	msgBus.PublishInbound(bus.InboundMessage{Channel: "test", ChatID: "chat1", Content: "hi", SessionKey: "test:chat1"})
+	lateDone := make(chan struct{})
+	go func() {
+		late.Run(context.Background())
+		close(lateDone)
+	}()
+	select {
+	case <-lateDone:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run started after Drain did not return")
+	}
+	outCtx, outCancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer outCancel()
+	if out, ok := msgBus.SubscribeOutbound(outCtx); ok {
+		t.Fatalf("Expected no reply from a drained loop, got %+v", out)
+	}
+
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), &mockProvider{})
+	runDone := make(chan struct{})
+	go func() {
+		al.Run(context.Background())
+		close(runDone)
+	}()
+	for {
+		al.runMu.Lock()
+		started := al.runDone != nil
+		al.runMu.Unlock()
+		if started {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := al.Drain(ctx); err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+	<-runDone
+}
+
+func TestRetryDelay(t *testing.T) {
+	rateLimited := &providers.Error{Kind: providers.ErrorRateLimit}
+	for retry, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		if got := retryDelay(rateLimited, retry); got != want {
+			t.Errorf("retryDelay(retry %d) = %v, want %v", retry, got, want)
+		}
+	}
+	if got := retryDelay(rateLimited, 10); got != maxRetryDelay {
+		t.Errorf("retryDelay(retry 10) = %v, want the cap %v", got, maxRetryDelay)
+	}
+
+	asked := &providers.Error{Kind: providers.ErrorRateLimit, RetryAfter: 20 * time.Second}
+	if got := retryDelay(fmt.Errorf("wrapped: %w", asked), 0); got != 20*time.Second {
+		t.Errorf("retryDelay() = %v, want the provider's Retry-After of 20s", got)
+	}
+}
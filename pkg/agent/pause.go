@@ -0,0 +1,60 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/Sterlites/RDxClaw/pkg/logger"
+)
+
+// Pause stops the loop from processing inbound bus messages. Messages keep
+// queueing on the bus and channels stay connected; they are processed in
+// order once Resume is called. Direct calls such as ProcessDirect are not
+// affected.
+func (al *AgentLoop) Pause() {
+	al.pauseMu.Lock()
+	defer al.pauseMu.Unlock()
+
+	if al.resumeCh != nil {
+		return
+	}
+	al.resumeCh = make(chan struct{})
+	logger.InfoC("agent", "Agent loop paused")
+}
+
+// Resume continues processing inbound messages after Pause.
+func (al *AgentLoop) Resume() {
+	al.pauseMu.Lock()
+	defer al.pauseMu.Unlock()
+
+	if al.resumeCh == nil {
+		return
+	}
+	close(al.resumeCh)
+	al.resumeCh = nil
+	logger.InfoC("agent", "Agent loop resumed")
+}
+
+// IsPaused reports whether the loop is paused.
+func (al *AgentLoop) IsPaused() bool {
+	al.pauseMu.Lock()
+	defer al.pauseMu.Unlock()
+	return al.resumeCh != nil
+}
+
+// waitIfPaused blocks while the loop is paused. It returns false if ctx is
+// done before the loop is resumed.
+func (al *AgentLoop) waitIfPaused(ctx context.Context) bool {
+	al.pauseMu.Lock()
+	resumeCh := al.resumeCh
+	al.pauseMu.Unlock()
+
+	if resumeCh == nil {
+		return true
+	}
+	select {
+	case <-resumeCh:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,36 @@
+package agent
+
+import (
+	"context"
+	"time"
+
+	"github.com/Sterlites/RDxClaw/pkg/providers"
+)
+
+// Backoff between retries of a transient provider failure. retryBaseDelay
+// is a variable so tests can shorten it.
+var retryBaseDelay = time.Second
+
+const maxRetryDelay = time.Minute
+
+// retryDelay is how long to wait before retry number retry+1 after err:
+// the provider's Retry-After when it sent one, otherwise a delay doubling
+// from retryBaseDelay. Both are capped at maxRetryDelay.
+func retryDelay(err error, retry int) time.Duration {
+	if after := providers.RetryAfterOf(err); after > 0 {
+		return min(after, maxRetryDelay)
+	}
+	return min(retryBaseDelay<<retry, maxRetryDelay)
+}
+
+// sleepCtx waits for d, or returns ctx's error if it ends first.
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
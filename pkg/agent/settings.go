@@ -0,0 +1,117 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Sterlites/RDxClaw/pkg/tools"
+)
+
+// WorkspaceSettingsFile is the workspace file that describes how the agent
+// behaves, as opposed to config.json, which describes the infrastructure
+// it runs on.
+const WorkspaceSettingsFile = "agent.json"
+
+// defaultResponseTokens caps each model response unless agent.json or the
+// request sets max_tokens.
+const defaultResponseTokens = 8192
+
+// WorkspaceSettings is the content of agent.json. Unset fields fall back to
+// config.json; per-request options (see WithRequestOptions) override both.
+type WorkspaceSettings struct {
+	Model         string   `json:"model,omitempty"`
+	Temperature   *float64 `json:"temperature,omitempty"`
+	MaxTokens     int      `json:"max_tokens,omitempty"`
+	MaxIterations int      `json:"max_iterations,omitempty"`
+	// SystemPromptFile is a workspace-relative file appended to the system
+	// prompt on every turn, e.g. a persona.
+	SystemPromptFile string `json:"system_prompt_file,omitempty"`
+}
+
+// LoadWorkspaceSettings reads and validates agent.json from workspace. It
+// returns nil, nil when the file does not exist.
+func LoadWorkspaceSettings(workspace string) (*WorkspaceSettings, error) {
+	data, err := os.ReadFile(filepath.Join(workspace, WorkspaceSettingsFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var settings WorkspaceSettings
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&settings); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", WorkspaceSettingsFile, err)
+	}
+	if err := settings.Validate(workspace); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", WorkspaceSettingsFile, err)
+	}
+	return &settings, nil
+}
+
+// Validate checks the settings' ranges and that the system prompt file is
+// a readable file inside workspace.
+func (s *WorkspaceSettings) Validate(workspace string) error {
+	var errs []string
+	if err := (RequestOptions{Temperature: s.Temperature, MaxTokens: s.MaxTokens}).Validate(); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if err := tools.ValidateMaxIterations(s.MaxIterations); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if s.SystemPromptFile != "" {
+		if !filepath.IsLocal(s.SystemPromptFile) {
+			errs = append(errs, "system_prompt_file must be a path inside the workspace")
+		} else if info, err := os.Stat(filepath.Join(workspace, s.SystemPromptFile)); err != nil || info.IsDir() {
+			errs = append(errs, fmt.Sprintf("system_prompt_file %q is not a readable file", s.SystemPromptFile))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// RequestOptions override the model and sampling settings for one request.
+// Zero values keep the agent's defaults.
+type RequestOptions struct {
+	Model       string
+	Temperature *float64
+	MaxTokens   int
+}
+
+// Validate checks that the temperature is between 0 and 2 and max_tokens
+// is not negative.
+func (o RequestOptions) Validate() error {
+	var errs []string
+	if o.Temperature != nil && (*o.Temperature < 0 || *o.Temperature > 2) {
+		errs = append(errs, "temperature must be between 0 and 2")
+	}
+	if o.MaxTokens < 0 {
+		errs = append(errs, "max_tokens must not be negative")
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+type requestOptionsKey struct{}
+
+// WithRequestOptions returns a copy of ctx whose agent turns use opts.
+func WithRequestOptions(ctx context.Context, opts RequestOptions) context.Context {
+	return context.WithValue(ctx, requestOptionsKey{}, opts)
+}
+
+func requestOptionsFrom(ctx context.Context) RequestOptions {
+	opts, _ := ctx.Value(requestOptionsKey{}).(RequestOptions)
+	return opts
+}
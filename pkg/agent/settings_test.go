@@ -0,0 +1,161 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Sterlites/RDxClaw/pkg/bus"
+	"github.com/Sterlites/RDxClaw/pkg/config"
+	"github.com/Sterlites/RDxClaw/pkg/providers"
+	"github.com/Sterlites/RDxClaw/pkg/swarm"
+)
+
+// recordingProvider remembers the model, options and system prompt of the
+// last call.
+type recordingProvider struct {
+	model  string
+	opts   map[string]interface{}
+	system string
+}
+
+func (p *recordingProvider) Chat(ctx context.Context, messages []providers.Message, tools []providers.ToolDefinition, model string, opts map[string]interface{}) (*providers.LLMResponse, error) {
+	p.model, p.opts, p.system = model, opts, messages[0].Content
+	return &providers.LLMResponse{Content: "done"}, nil
+}
+
+func (p *recordingProvider) GetDefaultModel() string { return "mock-model" }
+
+func settingsTestConfig(t *testing.T) *config.Config {
+	return &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "config-model",
+				MaxTokens:         4096,
+				Temperature:       0.7,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+}
+
+func TestWorkspaceSettingsPrecedence(t *testing.T) {
+	cfg := settingsTestConfig(t)
+	workspace := cfg.WorkspacePath()
+
+	// Without agent.json the config applies
+	provider := &recordingProvider{}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+	if _, err := al.ProcessDirect(context.Background(), "hi", "test-session"); err != nil {
+		t.Fatal(err)
+	}
+	if provider.model != "config-model" || provider.opts["temperature"] != 0.7 || provider.opts["max_tokens"] != defaultResponseTokens {
+		t.Errorf("config defaults not applied: model=%s opts=%v", provider.model, provider.opts)
+	}
+
+	// agent.json overrides config
+	os.WriteFile(filepath.Join(workspace, "persona.md"), []byte("You are a terse pirate."), 0644)
+	os.WriteFile(filepath.Join(workspace, WorkspaceSettingsFile), []byte(`{
+		"model": "workspace-model",
+		"temperature": 0,
+		"max_tokens": 512,
+		"max_iterations": 3,
+		"system_prompt_file": "persona.md"
+	}`), 0644)
+	provider = &recordingProvider{}
+	al = NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+	if _, err := al.ProcessDirect(context.Background(), "hi", "test-session"); err != nil {
+		t.Fatal(err)
+	}
+	if provider.model != "workspace-model" || provider.opts["temperature"] != 0.0 || provider.opts["max_tokens"] != 512 {
+		t.Errorf("agent.json not applied: model=%s opts=%v", provider.model, provider.opts)
+	}
+	if al.maxIterations != 3 {
+		t.Errorf("maxIterations = %d, want 3", al.maxIterations)
+	}
+	if !strings.Contains(provider.system, "You are a terse pirate.") {
+		t.Error("system prompt file should be part of the system prompt")
+	}
+
+	// Subagents use agent.json's model and options too
+	task := &swarm.SubagentTask{ID: "agent-1", Task: "hi", Status: "running"}
+	if _, err := al.swarmManager.RunTask(context.Background(), task); err != nil {
+		t.Fatal(err)
+	}
+	if provider.model != "workspace-model" || provider.opts["temperature"] != 0.0 || provider.opts["max_tokens"] != 512 {
+		t.Errorf("agent.json not applied to subagents: model=%s opts=%v", provider.model, provider.opts)
+	}
+
+	// Request options override agent.json
+	temp := 1.2
+	ctx := WithRequestOptions(context.Background(), RequestOptions{Model: "request-model", Temperature: &temp, MaxTokens: 64})
+	if _, err := al.ProcessDirect(ctx, "hi", "test-session"); err != nil {
+		t.Fatal(err)
+	}
+	if provider.model != "request-model" || provider.opts["temperature"] != 1.2 || provider.opts["max_tokens"] != 64 {
+		t.Errorf("request options not applied: model=%s opts=%v", provider.model, provider.opts)
+	}
+}
+
+// TestWorkspaceSettingsModelProvider verifies a model from agent.json that
+// config.json's provider does not serve gets a provider of its own, shared
+// by subagents and by channels without a model override.
+func TestWorkspaceSettingsModelProvider(t *testing.T) {
+	cfg := settingsTestConfig(t)
+	cfg.Providers.Anthropic = config.ProviderConfig{APIKey: "test-key"}
+	cfg.Agents.Channels = map[string]config.ChannelAgentConfig{
+		"telegram": {},
+		"discord":  {Model: "discord-model"},
+	}
+	workspace := cfg.WorkspacePath()
+	os.WriteFile(filepath.Join(workspace, WorkspaceSettingsFile), []byte(`{"model": "claude-sonnet-4"}`), 0644)
+
+	provider := &recordingProvider{}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+	if al.provider == providers.LLMProvider(provider) {
+		t.Fatal("Expected a provider detected from agent.json's model")
+	}
+	if p, model := al.modelFor("telegram"); p != al.provider || model != "claude-sonnet-4" {
+		t.Errorf("Expected a channel without a model to use agent.json's model and provider, got %q", model)
+	}
+	if p, model := al.modelFor("discord"); p != al.provider || model != "discord-model" {
+		t.Errorf("Expected a channel model to keep the agent's provider, got %q", model)
+	}
+	if p, _ := al.requestModelFor(WithRequestOptions(context.Background(), RequestOptions{Model: "claude-sonnet-4"}), "api"); p != al.provider {
+		t.Error("Expected requests for agent.json's model to reuse its provider")
+	}
+
+	// A model config.json's provider serves keeps it
+	os.WriteFile(filepath.Join(workspace, WorkspaceSettingsFile), []byte(`{"model": "config-model"}`), 0644)
+	if al := NewAgentLoop(cfg, bus.NewMessageBus(), provider); al.provider != providers.LLMProvider(provider) {
+		t.Error("Expected config.json's provider for config.json's model")
+	}
+}
+
+func TestWorkspaceSettingsInvalidFallsBack(t *testing.T) {
+	cfg := settingsTestConfig(t)
+	workspace := cfg.WorkspacePath()
+
+	for name, content := range map[string]string{
+		"temperature":   `{"model": "bad-model", "temperature": 3}`,
+		"escaping file": `{"model": "bad-model", "system_prompt_file": "../secrets.md"}`,
+		"unknown field": `{"model": "bad-model", "temprature": 0.2}`,
+	} {
+		os.WriteFile(filepath.Join(workspace, WorkspaceSettingsFile), []byte(content), 0644)
+		if _, err := LoadWorkspaceSettings(workspace); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+
+		provider := &recordingProvider{}
+		al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+		if _, err := al.ProcessDirect(context.Background(), "hi", "test-session"); err != nil {
+			t.Fatal(err)
+		}
+		if provider.model != "config-model" {
+			t.Errorf("%s: an invalid agent.json must fall back to config, got model %s", name, provider.model)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package agent
+
+import "context"
+
+// streamSink forwards reply content of one turn to a channel as the
+// provider produces it. Text of a model call that may request tools is
+// held back until the call returns: only calls that end without tool calls
+// make up the reply, so the text of tool iterations is never streamed.
+type streamSink struct {
+	ctx     context.Context
+	deltas  chan<- string
+	sent    int      // pieces of content that reached deltas
+	live    bool     // the current call cannot use tools; forward at once
+	pending []string // the current call's content, held until commit
+}
+
+// begin starts a model call. live says it was offered no tools, so all of
+// its content belongs to the reply.
+func (s *streamSink) begin(live bool) {
+	s.live = live
+	s.pending = nil
+}
+
+// add receives content of the current model call.
+func (s *streamSink) add(delta string) {
+	if s.live {
+		s.send(delta)
+		return
+	}
+	s.pending = append(s.pending, delta)
+}
+
+// commit forwards the held content once the call turned out to be part of
+// the reply.
+func (s *streamSink) commit() {
+	for _, delta := range s.pending {
+		s.send(delta)
+	}
+	s.pending = nil
+}
+
+// send delivers delta unless the turn's context ends first, so a reader
+// that gave up cannot block the turn.
+func (s *streamSink) send(delta string) {
+	if delta == "" {
+		return
+	}
+	select {
+	case s.deltas <- delta:
+		s.sent++
+	case <-s.ctx.Done():
+	}
+}
+
+type streamSinkKey struct{}
+
+func withStreamSink(ctx context.Context, sink *streamSink) context.Context {
+	return context.WithValue(ctx, streamSinkKey{}, sink)
+}
+
+func streamSinkFrom(ctx context.Context) *streamSink {
+	sink, _ := ctx.Value(streamSinkKey{}).(*streamSink)
+	return sink
+}
+
+// ProcessDirectStream processes a message like ProcessDirectWithChannel,
+// sending the reply to deltas piece by piece. Only the final answer is
+// sent, never the text of iterations that called tools; as that is only
+// known once a model call returns, a call offered tools is sent when it
+// completes, and only a call without tools streams as the provider
+// produces it. Providers that cannot stream deliver each reply in one
+// piece, and so do replies that bypass the model, such as commands. deltas
+// is closed when the turn ends; the complete reply is returned as well.
+func (al *AgentLoop) ProcessDirectStream(ctx context.Context, content, sessionKey, channel, chatID string, deltas chan<- string) (string, error) {
+	defer close(deltas)
+
+	sink := &streamSink{ctx: ctx, deltas: deltas}
+	response, err := al.ProcessDirectWithChannel(withStreamSink(ctx, sink), content, sessionKey, channel, chatID)
+	if err == nil && sink.sent == 0 {
+		sink.send(response)
+	}
+	return response, err
+}
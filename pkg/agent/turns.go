@@ -0,0 +1,103 @@
+package agent
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ActivityStats describes the agent turns currently in flight.
+type ActivityStats struct {
+	InFlight      int            // total turns being processed
+	ByChannel     map[string]int // turns being processed per channel
+	OldestStarted time.Time      // start of the oldest in-flight turn; zero when idle
+	OldestSession string         // session of the oldest in-flight turn
+}
+
+// OldestAge returns how long the oldest in-flight turn has been running.
+func (s ActivityStats) OldestAge(now time.Time) time.Duration {
+	if s.OldestStarted.IsZero() {
+		return 0
+	}
+	return now.Sub(s.OldestStarted)
+}
+
+// turnRegistry tracks agent turns that are in progress.
+type turnRegistry struct {
+	mu     sync.Mutex
+	nextID uint64
+	active map[uint64]activeTurn
+}
+
+type activeTurn struct {
+	channel    string
+	sessionKey string
+	started    time.Time
+}
+
+// begin records a turn as started and returns a function that marks it done.
+func (r *turnRegistry) begin(channel, sessionKey string) func() {
+	if channel == "" {
+		channel = "unknown"
+	}
+
+	r.mu.Lock()
+	if r.active == nil {
+		r.active = make(map[uint64]activeTurn)
+	}
+	r.nextID++
+	id := r.nextID
+	r.active[id] = activeTurn{channel: channel, sessionKey: sessionKey, started: time.Now()}
+	r.mu.Unlock()
+
+	return func() {
+		r.mu.Lock()
+		delete(r.active, id)
+		r.mu.Unlock()
+	}
+}
+
+func (r *turnRegistry) stats() ActivityStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := ActivityStats{
+		InFlight:  len(r.active),
+		ByChannel: make(map[string]int),
+	}
+	for _, turn := range r.active {
+		stats.ByChannel[turn.channel]++
+		if stats.OldestStarted.IsZero() || turn.started.Before(stats.OldestStarted) {
+			stats.OldestStarted = turn.started
+			stats.OldestSession = turn.sessionKey
+		}
+	}
+	return stats
+}
+
+// Activity reports the agent turns currently in flight, to help spot
+// overload and stuck turns.
+func (al *AgentLoop) Activity() ActivityStats {
+	return al.turns.stats()
+}
+
+// TurnReport describes how a turn ended, beyond its reply. Attach one with
+// WithTurnReport to learn about a turn run through ProcessDirect and its
+// variants; the turn fills it in as it runs.
+type TurnReport struct {
+	// Truncated is set when the model cut the reply off at max_tokens and
+	// continuations (agents.defaults.max_continuations) did not complete it.
+	Truncated bool
+}
+
+type turnReportKey struct{}
+
+// WithTurnReport returns a copy of ctx whose turn fills in report.
+func WithTurnReport(ctx context.Context, report *TurnReport) context.Context {
+	return context.WithValue(ctx, turnReportKey{}, report)
+}
+
+func turnReportFrom(ctx context.Context) *TurnReport {
+	report, _ := ctx.Value(turnReportKey{}).(*TurnReport)
+	return report
+}
@@ -0,0 +1,44 @@
+package agent
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTurnRegistry(t *testing.T) {
+	var r turnRegistry
+
+	if stats := r.stats(); stats.InFlight != 0 || stats.OldestAge(time.Now()) != 0 {
+		t.Fatalf("expected idle registry, got %+v", stats)
+	}
+
+	doneA := r.begin("telegram", "telegram:1")
+	time.Sleep(5 * time.Millisecond)
+	doneB := r.begin("telegram", "telegram:2")
+	doneC := r.begin("", "cli:default")
+
+	stats := r.stats()
+	if stats.InFlight != 3 {
+		t.Errorf("InFlight = %d, want 3", stats.InFlight)
+	}
+	if stats.ByChannel["telegram"] != 2 || stats.ByChannel["unknown"] != 1 {
+		t.Errorf("ByChannel = %v", stats.ByChannel)
+	}
+	if stats.OldestSession != "telegram:1" {
+		t.Errorf("OldestSession = %q, want telegram:1", stats.OldestSession)
+	}
+	if stats.OldestAge(time.Now()) < 5*time.Millisecond {
+		t.Errorf("OldestAge = %v, want at least 5ms", stats.OldestAge(time.Now()))
+	}
+
+	doneA()
+	if stats := r.stats(); stats.OldestSession != "telegram:2" || stats.InFlight != 2 {
+		t.Errorf("after first turn finished got %+v", stats)
+	}
+
+	doneB()
+	doneC()
+	if stats := r.stats(); stats.InFlight != 0 || len(stats.ByChannel) != 0 {
+		t.Errorf("expected idle registry, got %+v", stats)
+	}
+}
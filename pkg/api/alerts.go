@@ -0,0 +1,164 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sterlites/RDxClaw/pkg/bus"
+)
+
+// Alerting defaults, used when the config leaves them unset.
+const (
+	defaultAlertWindow   = 5 * time.Minute
+	defaultAlertCooldown = 15 * time.Minute
+)
+
+// alertSampleSize is how many recent error messages an alert quotes.
+const alertSampleSize = 5
+
+// AlertConfig raises an alert when error activity events spike. Alerting is
+// off while Threshold is 0.
+//
+// Only the server's own activity events count: errors from its chat, skill,
+// webhook, knowledge and embeddings endpoints. Failures in the gateway's
+// chat channels, or in agent turns the server did not start, do not raise
+// alerts.
+type AlertConfig struct {
+	// Threshold is how many error events Window may hold without an
+	// alert; one more raises it.
+	Threshold int
+	Window    time.Duration // default 5m
+	// Cooldown is the least time between two alerts (default 15m), so a
+	// sustained outage pages once rather than on every error.
+	Cooldown time.Duration
+
+	// WebhookURL receives the alert as a JSON POST. Channel and ChatID
+	// deliver its summary through the message bus instead, or as well.
+	WebhookURL string
+	Channel    string
+	ChatID     string
+}
+
+// Alert is what the alerting hook sends when errors spike.
+type Alert struct {
+	Summary   string    `json:"summary"`
+	Errors    int       `json:"errors"`
+	Window    string    `json:"window"`
+	Timestamp time.Time `json:"timestamp"`
+	Recent    []string  `json:"recent"` // the latest error messages, newest first
+}
+
+// alerter counts error events in a sliding window and decides when to
+// alert.
+type alerter struct {
+	cfg AlertConfig
+
+	mu        sync.Mutex
+	errors    []ActivityEvent // error events within the window, oldest first
+	lastAlert time.Time
+}
+
+// newAlerter returns nil when alerting is off.
+func newAlerter(cfg AlertConfig) *alerter {
+	if cfg.Threshold <= 0 {
+		return nil
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = defaultAlertWindow
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = defaultAlertCooldown
+	}
+	if cfg.WebhookURL == "" && (cfg.Channel == "" || cfg.ChatID == "") {
+		slog.Warn("alerting has no destination; set a webhook URL or a channel and chat ID")
+	}
+	return &alerter{cfg: cfg}
+}
+
+// observe records an event and returns the alert to send, if this event
+// took the count past the threshold outside the cooldown.
+func (a *alerter) observe(event ActivityEvent) *Alert {
+	if event.Type != "error" {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	cutoff := event.Timestamp.Add(-a.cfg.Window)
+	kept := a.errors[:0]
+	for _, e := range a.errors {
+		if e.Timestamp.After(cutoff) {
+			kept = append(kept, e)
+		}
+	}
+	a.errors = append(kept, event)
+
+	if len(a.errors) <= a.cfg.Threshold {
+		return nil
+	}
+	if !a.lastAlert.IsZero() && event.Timestamp.Sub(a.lastAlert) < a.cfg.Cooldown {
+		return nil
+	}
+	a.lastAlert = event.Timestamp
+
+	alert := &Alert{
+		Summary:   fmt.Sprintf("%d errors in the last %v", len(a.errors), a.cfg.Window),
+		Errors:    len(a.errors),
+		Window:    a.cfg.Window.String(),
+		Timestamp: event.Timestamp,
+	}
+	for i := len(a.errors) - 1; i >= 0 && len(alert.Recent) < alertSampleSize; i-- {
+		alert.Recent = append(alert.Recent, fmt.Sprintf("[%s] %s", a.errors[i].Source, a.errors[i].Message))
+	}
+	return alert
+}
+
+// dispatch sends an alert to the configured webhook and channel. Failures
+// are logged; alerting must never affect the request that raised it.
+func (a *alerter) dispatch(alert *Alert, msgBus *bus.MessageBus) {
+	if a.cfg.WebhookURL != "" {
+		if err := postAlert(a.cfg.WebhookURL, alert); err != nil {
+			slog.Error("failed to send alert", "url", a.cfg.WebhookURL, "error", err)
+		}
+	}
+	if a.cfg.Channel != "" && a.cfg.ChatID != "" && msgBus != nil {
+		msgBus.PublishOutbound(bus.OutboundMessage{
+			Channel: a.cfg.Channel,
+			ChatID:  a.cfg.ChatID,
+			Content: "🚨 " + alert.Summary + "\n" + strings.Join(alert.Recent, "\n"),
+		})
+	}
+}
+
+func postAlert(url string, alert *Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
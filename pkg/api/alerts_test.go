@@ -0,0 +1,72 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlerter(t *testing.T) {
+	assert.Nil(t, newAlerter(AlertConfig{}), "alerting is off without a threshold")
+
+	a := newAlerter(AlertConfig{Threshold: 2, Window: time.Minute, Cooldown: 10 * time.Minute})
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	errorAt := func(d time.Duration) *Alert {
+		return a.observe(ActivityEvent{Timestamp: start.Add(d), Source: "agent", Type: "error", Message: "boom"})
+	}
+
+	assert.Nil(t, a.observe(ActivityEvent{Timestamp: start, Type: "warning"}), "only errors count")
+	assert.Nil(t, errorAt(0))
+	assert.Nil(t, errorAt(30*time.Second))
+	assert.Nil(t, errorAt(70*time.Second), "the first error has left the window")
+	assert.Len(t, a.errors, 2, "reaching the threshold is not enough")
+
+	alert := errorAt(75 * time.Second)
+	require.NotNil(t, alert)
+	assert.Equal(t, 3, alert.Errors)
+	assert.Equal(t, "1m0s", alert.Window)
+	assert.Equal(t, []string{"[agent] boom", "[agent] boom", "[agent] boom"}, alert.Recent)
+
+	assert.Nil(t, errorAt(80*time.Second), "within the cooldown")
+	assert.Nil(t, errorAt(5*time.Minute))
+
+	for _, d := range []time.Duration{12 * time.Minute, 12*time.Minute + time.Second} {
+		assert.Nil(t, errorAt(d))
+	}
+	assert.NotNil(t, errorAt(12*time.Minute+2*time.Second), "the cooldown has passed")
+}
+
+func TestAlertWebhook(t *testing.T) {
+	received := make(chan Alert, 10)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert Alert
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&alert))
+		received <- alert
+	}))
+	defer hook.Close()
+
+	s := newTestServer(t, &echoProvider{}, ServerConfig{
+		Alerts: AlertConfig{Threshold: 2, WebhookURL: hook.URL},
+	})
+	for i := 0; i < 10; i++ {
+		s.recordEvent("skill", "error", "skill failed")
+	}
+
+	select {
+	case alert := <-received:
+		assert.Equal(t, 3, alert.Errors)
+		assert.Len(t, alert.Recent, 3)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no alert was sent")
+	}
+	select {
+	case <-received:
+		t.Fatal("a burst of errors should raise one alert")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
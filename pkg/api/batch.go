@@ -0,0 +1,80 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Bounds on one batch of chat completions.
+const (
+	maxBatchItems     = 100
+	batchConcurrency  = 4
+	maxBatchBodyBytes = 4 << 20
+)
+
+// handleChatCompletionBatch runs an array of chat completion requests, a
+// few at a time, and answers with one item per request in the same order.
+// Each request gets the chat timeout of its own, and a failed request is
+// reported in its item instead of failing the batch. Every request counts
+// against the rate limit; those beyond the remaining allowance fail with
+// status 429.
+func (s *Server) handleChatCompletionBatch(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBatchBodyBytes)
+
+	var reqs []ChatCompletionRequest
+	if err := decodeJSON(r, &reqs); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "body must be an array of chat completion requests: "+err.Error())
+		return
+	}
+	if len(reqs) == 0 {
+		writeError(w, http.StatusBadRequest, "invalid_request", "batch must not be empty")
+		return
+	}
+	if len(reqs) > maxBatchItems {
+		writeError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("batch has %d requests, the limit is %d", len(reqs), maxBatchItems))
+		return
+	}
+
+	// The rate limit middleware already counted the batch as one request.
+	allowed := len(reqs)
+	if s.limiter != nil {
+		allowed = 1 + s.limiter.Take(rateLimitIdentity(r), len(reqs)-1)
+	}
+
+	items := make([]BatchChatCompletionItem, len(reqs))
+	batchID := time.Now().UnixNano()
+	sem := make(chan struct{}, batchConcurrency)
+	var wg sync.WaitGroup
+	for i, req := range reqs {
+		items[i].Index = i
+		if i >= allowed {
+			items[i].Status = http.StatusTooManyRequests
+			items[i].Error = &ErrorDetail{Message: "Rate limit exceeded. Please retry later.", Type: "api_error", Code: "rate_limit_exceeded"}
+			continue
+		}
+		if req.SessionKey == "" {
+			req.SessionKey = fmt.Sprintf("api-%d-%d", batchID, i)
+		}
+
+		wg.Add(1)
+		go func(i int, req ChatCompletionRequest) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			resp, failure := s.completeChat(r.Context(), req)
+			if failure != nil {
+				items[i].Status = failure.status
+				items[i].Error = failure.detail()
+				return
+			}
+			items[i].Status = http.StatusOK
+			items[i].Response = resp
+		}(i, req)
+	}
+	wg.Wait()
+
+	writeJSON(w, http.StatusOK, items)
+}
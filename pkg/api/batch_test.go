@@ -0,0 +1,115 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Sterlites/RDxClaw/pkg/providers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingProvider echoes like echoProvider but fails any message
+// containing "fail".
+type failingProvider struct{}
+
+func (p *failingProvider) Chat(ctx context.Context, messages []providers.Message, tools []providers.ToolDefinition, model string, opts map[string]interface{}) (*providers.LLMResponse, error) {
+	last := messages[len(messages)-1].Content
+	if strings.Contains(last, "fail") {
+		return nil, errors.New("provider exploded")
+	}
+	return &providers.LLMResponse{Content: "handled: " + last}, nil
+}
+
+func (p *failingProvider) GetDefaultModel() string { return "mock-model" }
+
+func postBatch(s *Server, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions/batch", strings.NewReader(body))
+	req.RemoteAddr = "192.0.2.1:1234"
+	w := httptest.NewRecorder()
+	s.handleChatCompletionBatch(w, req)
+	return w
+}
+
+func TestChatCompletionBatch(t *testing.T) {
+	s := newTestServer(t, &failingProvider{}, ServerConfig{})
+
+	w := postBatch(s, `[
+		{"messages":[{"role":"user","content":"first"}]},
+		{"messages":[{"role":"user","content":"please fail"}]},
+		{"messages":[]},
+		{"messages":[{"role":"user","content":"last"}]}
+	]`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var items []BatchChatCompletionItem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &items))
+	require.Len(t, items, 4)
+	for i, item := range items {
+		assert.Equal(t, i, item.Index)
+	}
+
+	assert.Equal(t, http.StatusOK, items[0].Status)
+	require.NotNil(t, items[0].Response)
+	assert.Equal(t, "handled: first", items[0].Response.Choices[0].Message.Content)
+	assert.Nil(t, items[0].Error)
+
+	assert.Equal(t, http.StatusInternalServerError, items[1].Status)
+	require.NotNil(t, items[1].Error)
+	assert.Equal(t, "processing_error", items[1].Error.Code)
+	assert.Nil(t, items[1].Response)
+
+	assert.Equal(t, http.StatusBadRequest, items[2].Status)
+	require.NotNil(t, items[2].Error)
+	assert.Equal(t, "invalid_request", items[2].Error.Code)
+
+	assert.Equal(t, http.StatusOK, items[3].Status)
+	require.NotNil(t, items[3].Response)
+	assert.Equal(t, "handled: last", items[3].Response.Choices[0].Message.Content)
+}
+
+func TestChatCompletionBatchValidation(t *testing.T) {
+	s := newTestServer(t, &echoProvider{}, ServerConfig{})
+
+	tooMany := "[" + strings.TrimSuffix(strings.Repeat(`{"messages":[{"role":"user","content":"x"}]},`, maxBatchItems+1), ",") + "]"
+	for name, body := range map[string]string{
+		"not an array": `{"messages":[{"role":"user","content":"hi"}]}`,
+		"empty":        `[]`,
+		"too many":     tooMany,
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, http.StatusBadRequest, postBatch(s, body).Code)
+		})
+	}
+}
+
+func TestChatCompletionBatchRateLimit(t *testing.T) {
+	s := newTestServer(t, &echoProvider{}, ServerConfig{RateLimit: 3})
+
+	// The middleware takes one request for the batch itself.
+	require.True(t, s.limiter.Allow("192.0.2.1"))
+
+	var reqs []string
+	for i := 0; i < 4; i++ {
+		reqs = append(reqs, fmt.Sprintf(`{"messages":[{"role":"user","content":"item %d"}]}`, i))
+	}
+	w := postBatch(s, "["+strings.Join(reqs, ",")+"]")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var items []BatchChatCompletionItem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &items))
+	require.Len(t, items, 4)
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, items[i].Status, "item %d", i)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, items[3].Status)
+	require.NotNil(t, items[3].Error)
+	assert.Equal(t, "rate_limit_exceeded", items[3].Error.Code)
+	assert.False(t, s.limiter.Allow("192.0.2.1"), "every item counts against the limit")
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Sterlites/RDxClaw/pkg/providers"
+)
+
+// Bounds on one embeddings request.
+const (
+	maxEmbeddingBodyBytes  = 4 << 20
+	maxEmbeddingInputs     = 256
+	maxEmbeddingInputChars = 32000
+)
+
+// handleEmbeddings computes embeddings with the agent's provider, in
+// OpenAI's format.
+func (s *Server) handleEmbeddings(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxEmbeddingBodyBytes)
+
+	var req EmbeddingRequest
+	if err := decodeJSON(r, &req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, "request_too_large", fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit))
+			return
+		}
+		writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	if strings.TrimSpace(req.Model) == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "model is required")
+		return
+	}
+	inputs, err := embeddingInputs(req.Input)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.requestTimeout)
+	defer cancel()
+
+	result, err := providers.Embed(ctx, s.agentLoop.GetProvider(), inputs, req.Model)
+	if err != nil {
+		switch {
+		case errors.Is(err, providers.ErrEmbeddingsNotSupported):
+			writeError(w, http.StatusNotImplemented, "unsupported", err.Error())
+		case errors.Is(err, context.DeadlineExceeded) || errors.As(err, new(*providers.TimeoutError)):
+			writeError(w, http.StatusGatewayTimeout, "timeout", err.Error())
+		default:
+			s.recordEvent("agent", "error", fmt.Sprintf("Embeddings error: %v", err))
+			writeError(w, http.StatusBadGateway, "provider_error", err.Error())
+		}
+		return
+	}
+
+	resp := EmbeddingResponse{Object: "list", Data: make([]EmbeddingObject, len(result.Embeddings)), Model: result.Model}
+	for i, vec := range result.Embeddings {
+		resp.Data[i] = EmbeddingObject{Object: "embedding", Index: i, Embedding: vec}
+	}
+	if result.Usage != nil {
+		resp.Usage = EmbeddingUsage{PromptTokens: result.Usage.PromptTokens, TotalTokens: result.Usage.TotalTokens}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// embeddingInputs accepts a string or an array of strings and checks the
+// count and size limits.
+func embeddingInputs(raw json.RawMessage) ([]string, error) {
+	var inputs []string
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		inputs = []string{single}
+	} else if err := json.Unmarshal(raw, &inputs); err != nil {
+		return nil, fmt.Errorf("input must be a string or an array of strings")
+	}
+
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("input must not be empty")
+	}
+	if len(inputs) > maxEmbeddingInputs {
+		return nil, fmt.Errorf("input has %d items, the limit is %d", len(inputs), maxEmbeddingInputs)
+	}
+	for i, in := range inputs {
+		if strings.TrimSpace(in) == "" {
+			return nil, fmt.Errorf("input[%d] is empty", i)
+		}
+		if n := len([]rune(in)); n > maxEmbeddingInputChars {
+			return nil, fmt.Errorf("input[%d] has %d characters, the limit is %d", i, n, maxEmbeddingInputChars)
+		}
+	}
+	return inputs, nil
+}
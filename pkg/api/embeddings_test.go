@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Sterlites/RDxClaw/pkg/providers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// embedProvider embeds each text as [len(text), index].
+type embedProvider struct {
+	echoProvider
+}
+
+func (p *embedProvider) Embed(ctx context.Context, texts []string, model string) (*providers.EmbeddingResponse, error) {
+	resp := &providers.EmbeddingResponse{Model: model, Usage: &providers.UsageInfo{PromptTokens: len(texts), TotalTokens: len(texts)}}
+	for i, text := range texts {
+		resp.Embeddings = append(resp.Embeddings, []float64{float64(len(text)), float64(i)})
+	}
+	return resp, nil
+}
+
+func TestEmbeddings(t *testing.T) {
+	s := newTestServer(t, &embedProvider{}, ServerConfig{})
+
+	embed := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.handleEmbeddings(w, httptest.NewRequest(http.MethodPost, "/v1/embeddings", strings.NewReader(body)))
+		return w
+	}
+
+	t.Run("array input", func(t *testing.T) {
+		w := embed(`{"model":"text-embedding-3-small","input":["hello","hi"]}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var resp map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "list", resp["object"])
+		assert.Equal(t, "text-embedding-3-small", resp["model"])
+		assert.Equal(t, map[string]interface{}{"prompt_tokens": 2.0, "total_tokens": 2.0}, resp["usage"])
+
+		data := resp["data"].([]interface{})
+		require.Len(t, data, 2)
+		for i, item := range data {
+			obj := item.(map[string]interface{})
+			assert.Equal(t, "embedding", obj["object"])
+			assert.Equal(t, float64(i), obj["index"])
+		}
+		assert.Equal(t, []interface{}{5.0, 0.0}, data[0].(map[string]interface{})["embedding"])
+		assert.Equal(t, []interface{}{2.0, 1.0}, data[1].(map[string]interface{})["embedding"])
+	})
+
+	t.Run("string input", func(t *testing.T) {
+		w := embed(`{"model":"m","input":"hello"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp EmbeddingResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp.Data, 1)
+		assert.Equal(t, []float64{5, 0}, resp.Data[0].Embedding)
+	})
+
+	for name, body := range map[string]string{
+		"missing model":  `{"input":"hello"}`,
+		"empty input":    `{"model":"m","input":[]}`,
+		"blank item":     `{"model":"m","input":["ok",""]}`,
+		"non-text input": `{"model":"m","input":[1,2]}`,
+		"too many items": `{"model":"m","input":[` + strings.TrimSuffix(strings.Repeat(`"x",`, maxEmbeddingInputs+1), ",") + `]}`,
+		"item too long":  `{"model":"m","input":"` + strings.Repeat("x", maxEmbeddingInputChars+1) + `"}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, http.StatusBadRequest, embed(body).Code)
+		})
+	}
+
+	t.Run("provider without embeddings", func(t *testing.T) {
+		s := newTestServer(t, &echoProvider{}, ServerConfig{})
+		w := httptest.NewRecorder()
+		s.handleEmbeddings(w, httptest.NewRequest(http.MethodPost, "/v1/embeddings", strings.NewReader(`{"model":"m","input":"hi"}`)))
+		assert.Equal(t, http.StatusNotImplemented, w.Code)
+	})
+}
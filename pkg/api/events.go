@@ -0,0 +1,55 @@
+package api
+
+import "sync/atomic"
+
+// defaultEventRetention is how many activity events are kept in memory.
+const defaultEventRetention = 50
+
+// eventRing is a fixed-size ring buffer of activity events. Writers claim a
+// slot with a single atomic increment and publish the event with an atomic
+// store, so recording never blocks and readers never hold up writers.
+type eventRing struct {
+	slots []atomic.Pointer[ringEntry]
+	next  atomic.Uint64 // total number of events ever recorded
+}
+
+// ringEntry tags an event with its sequence number so readers can detect a
+// slot that was overwritten while they were reading.
+type ringEntry struct {
+	seq   uint64
+	event ActivityEvent
+}
+
+func newEventRing(size int) *eventRing {
+	if size <= 0 {
+		size = defaultEventRetention
+	}
+	return &eventRing{slots: make([]atomic.Pointer[ringEntry], size)}
+}
+
+// Record adds an event, overwriting the oldest one once the ring is full.
+func (r *eventRing) Record(event ActivityEvent) {
+	seq := r.next.Add(1) - 1
+	r.slots[seq%uint64(len(r.slots))].Store(&ringEntry{seq: seq, event: event})
+}
+
+// Recent returns the retained events, newest first. Events that are being
+// overwritten or not yet published by a concurrent writer are skipped.
+func (r *eventRing) Recent() []ActivityEvent {
+	end := r.next.Load()
+	size := uint64(len(r.slots))
+	start := uint64(0)
+	if end > size {
+		start = end - size
+	}
+
+	events := make([]ActivityEvent, 0, end-start)
+	for seq := end; seq > start; seq-- {
+		entry := r.slots[(seq-1)%size].Load()
+		if entry == nil || entry.seq != seq-1 {
+			continue
+		}
+		events = append(events, entry.event)
+	}
+	return events
+}
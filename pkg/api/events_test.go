@@ -0,0 +1,121 @@
+package api
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventRing(t *testing.T) {
+	t.Run("returns newest first", func(t *testing.T) {
+		r := newEventRing(3)
+		assert.Empty(t, r.Recent())
+
+		r.Record(ActivityEvent{Message: "a"})
+		r.Record(ActivityEvent{Message: "b"})
+		assert.Equal(t, []string{"b", "a"}, messages(r.Recent()))
+	})
+
+	t.Run("drops oldest when full", func(t *testing.T) {
+		r := newEventRing(3)
+		for _, m := range []string{"a", "b", "c", "d", "e"} {
+			r.Record(ActivityEvent{Message: m})
+		}
+		assert.Equal(t, []string{"e", "d", "c"}, messages(r.Recent()))
+	})
+
+	t.Run("concurrent record and read", func(t *testing.T) {
+		r := newEventRing(16)
+		var wg sync.WaitGroup
+		for w := 0; w < 8; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < 1000; i++ {
+					r.Record(ActivityEvent{Message: fmt.Sprintf("%d-%d", w, i)})
+					if i%10 == 0 {
+						assert.LessOrEqual(t, len(r.Recent()), 16)
+					}
+				}
+			}(w)
+		}
+		wg.Wait()
+		assert.Len(t, r.Recent(), 16)
+	})
+}
+
+func messages(events []ActivityEvent) []string {
+	out := make([]string, len(events))
+	for i, e := range events {
+		out[i] = e.Message
+	}
+	return out
+}
+
+// mutexEventLog is the previous slice-and-lock implementation, kept to
+// compare against in BenchmarkEventRecording.
+type mutexEventLog struct {
+	mu     sync.RWMutex
+	events []ActivityEvent
+}
+
+func (l *mutexEventLog) Record(e ActivityEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, e)
+	if len(l.events) > defaultEventRetention {
+		l.events = l.events[1:]
+	}
+}
+
+func (l *mutexEventLog) Recent() []ActivityEvent {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	out := make([]ActivityEvent, len(l.events))
+	copy(out, l.events)
+	return out
+}
+
+// BenchmarkEventRecording records events from parallel goroutines while
+// one in every 16 operations reads the recent events, as the status
+// endpoint does.
+func BenchmarkEventRecording(b *testing.B) {
+	logs := map[string]interface {
+		Record(ActivityEvent)
+		Recent() []ActivityEvent
+	}{
+		"ring":  newEventRing(defaultEventRetention),
+		"mutex": &mutexEventLog{},
+	}
+
+	for _, name := range []string{"mutex", "ring"} {
+		log := logs[name]
+		b.Run(name, func(b *testing.B) {
+			event := ActivityEvent{Source: "api", Type: "info", Message: "Processed user request"}
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					if i%16 == 0 {
+						log.Recent()
+					} else {
+						log.Record(event)
+					}
+					i++
+				}
+			})
+		})
+	}
+}
+
+func TestEventRetention(t *testing.T) {
+	assert.Equal(t, defaultEventRetention, eventRetention(0))
+	assert.Equal(t, defaultEventRetention, eventRetention(-5))
+	assert.Equal(t, 200, eventRetention(200))
+
+	s := NewServer(nil, nil, nil, ServerConfig{EventRetention: 2})
+	s.recordEvent("api", "info", "one")
+	s.recordEvent("api", "info", "two")
+	assert.Equal(t, []string{"two", "one"}, messages(s.events.Recent()))
+}
@@ -0,0 +1,230 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sterlites/RDxClaw/pkg/knowledge"
+)
+
+// defaultMaxUploadBytes caps a knowledge upload when none is configured.
+const defaultMaxUploadBytes = 10 << 20
+
+// defaultAnalyticsWindow and defaultAnalyticsLimit apply when the
+// analytics request does not set window or limit.
+const (
+	defaultAnalyticsWindow = 7 * 24 * time.Hour
+	defaultAnalyticsLimit  = 10
+)
+
+// handleKnowledgeIngest indexes an uploaded file into a knowledge
+// collection. The file is sent either as the "file" field of a multipart
+// form or as the raw request body named by an X-Filename header. An
+// optional doc_id field (or X-Document-Id header) sets the document ID;
+// uploading the same filename again otherwise replaces the earlier copy.
+func (s *Server) handleKnowledgeIngest(w http.ResponseWriter, r *http.Request) {
+	store := s.agentLoop.GetKnowledgeStore()
+	if store == nil {
+		writeError(w, http.StatusServiceUnavailable, "knowledge_unavailable", "knowledge store is not available")
+		return
+	}
+	collection := strings.ToLower(strings.TrimSpace(r.PathValue("collection")))
+
+	r.Body = http.MaxBytesReader(w, r.Body, s.maxUploadBytes)
+	defer r.Body.Close()
+
+	var filename, contentType, docID string
+	var data []byte
+	var err error
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		file, header, ferr := r.FormFile("file")
+		if ferr != nil {
+			s.writeUploadError(w, ferr, "a multipart \"file\" field is required")
+			return
+		}
+		defer file.Close()
+		filename = header.Filename
+		contentType = header.Header.Get("Content-Type")
+		docID = r.FormValue("doc_id")
+		data, err = io.ReadAll(file)
+	} else {
+		filename = r.Header.Get("X-Filename")
+		if filename == "" {
+			writeError(w, http.StatusBadRequest, "invalid_request", "X-Filename header is required for a raw upload")
+			return
+		}
+		contentType = r.Header.Get("Content-Type")
+		docID = r.Header.Get("X-Document-Id")
+		data, err = io.ReadAll(r.Body)
+	}
+	if err != nil {
+		s.writeUploadError(w, err, "failed to read upload")
+		return
+	}
+	filename = filepath.Base(filename)
+
+	text, docType, err := knowledge.ExtractText(filename, contentType, data)
+	if err != nil {
+		writeError(w, http.StatusUnsupportedMediaType, "unsupported_type", err.Error())
+		return
+	}
+	if strings.TrimSpace(text) == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "file contains no text")
+		return
+	}
+
+	if docID == "" {
+		docID = uploadDocID(filename)
+	}
+	doc := knowledge.Document{
+		ID:      docID,
+		Title:   filename,
+		Content: text,
+		Source:  filename,
+		Type:    docType,
+		Metadata: map[string]interface{}{
+			"title":    filename,
+			"filename": filename,
+		},
+	}
+	if err := store.AddDocument(collection, doc); err != nil {
+		if errors.Is(err, knowledge.ErrInvalidCollection) {
+			writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
+		s.recordEvent("knowledge", "error", fmt.Sprintf("Ingest of %s failed: %v", filename, err))
+		writeError(w, http.StatusInternalServerError, "ingest_error", err.Error())
+		return
+	}
+	chunks, _ := store.ChunkCount(collection, docID)
+
+	s.recordEvent("knowledge", "success", fmt.Sprintf("Ingested %s into %s", filename, collection))
+	writeJSON(w, http.StatusOK, KnowledgeIngestResponse{
+		Collection: collection,
+		DocumentID: docID,
+		Title:      filename,
+		Type:       docType,
+		Chunks:     chunks,
+	})
+}
+
+// handleKnowledgeAnalytics summarizes the logged searches of a collection.
+// ?window=<duration> (default 168h) bounds how far back to look and
+// ?limit=N (default 10) how many queries each list holds.
+func (s *Server) handleKnowledgeAnalytics(w http.ResponseWriter, r *http.Request) {
+	store := s.agentLoop.GetKnowledgeStore()
+	if store == nil {
+		writeError(w, http.StatusServiceUnavailable, "knowledge_unavailable", "knowledge store is not available")
+		return
+	}
+
+	window := defaultAnalyticsWindow
+	if v := r.URL.Query().Get("window"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid_request", "window must be a positive duration such as 24h")
+			return
+		}
+		window = d
+	}
+	limit := defaultAnalyticsLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid_request", "limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+
+	report, err := store.QueryAnalytics(r.PathValue("collection"), time.Now().Add(-window), limit)
+	if err != nil {
+		if errors.Is(err, knowledge.ErrInvalidCollection) {
+			writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "analytics_error", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// handleKnowledgeStats reports the index statistics of a collection:
+// vocabulary size, postings, average chunk length and the top terms.
+func (s *Server) handleKnowledgeStats(w http.ResponseWriter, r *http.Request) {
+	store := s.agentLoop.GetKnowledgeStore()
+	if store == nil {
+		writeError(w, http.StatusServiceUnavailable, "knowledge_unavailable", "knowledge store is not available")
+		return
+	}
+
+	stats, err := store.Stats(r.PathValue("collection"))
+	if err != nil {
+		switch {
+		case errors.Is(err, knowledge.ErrInvalidCollection):
+			writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		case errors.Is(err, knowledge.ErrCollectionNotFound):
+			writeError(w, http.StatusNotFound, "collection_not_found", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "stats_error", err.Error())
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// handleKnowledgeDelete removes a document from a collection, e.g. after
+// its source file changed or was deleted.
+func (s *Server) handleKnowledgeDelete(w http.ResponseWriter, r *http.Request) {
+	store := s.agentLoop.GetKnowledgeStore()
+	if store == nil {
+		writeError(w, http.StatusServiceUnavailable, "knowledge_unavailable", "knowledge store is not available")
+		return
+	}
+
+	collection, docID := r.PathValue("collection"), r.PathValue("id")
+	if err := store.DeleteDocument(collection, docID); err != nil {
+		switch {
+		case errors.Is(err, knowledge.ErrInvalidCollection):
+			writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		case errors.Is(err, knowledge.ErrCollectionNotFound):
+			writeError(w, http.StatusNotFound, "collection_not_found", err.Error())
+		case errors.Is(err, knowledge.ErrDocumentNotFound):
+			writeError(w, http.StatusNotFound, "document_not_found", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "delete_error", err.Error())
+		}
+		return
+	}
+
+	s.recordEvent("api", "info", fmt.Sprintf("Knowledge document deleted: %s/%s", collection, docID))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeUploadError reports a failed upload read, telling an oversized
+// upload apart from a malformed one.
+func (s *Server) writeUploadError(w http.ResponseWriter, err error, message string) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeError(w, http.StatusRequestEntityTooLarge, "file_too_large",
+			fmt.Sprintf("upload exceeds the %d byte limit", s.maxUploadBytes))
+		return
+	}
+	writeError(w, http.StatusBadRequest, "invalid_request", message)
+}
+
+// uploadDocID derives a document ID from an uploaded file's name, so
+// uploading the same file again replaces it.
+func uploadDocID(filename string) string {
+	sum := sha256.Sum256([]byte(filename))
+	return "upload_" + hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,167 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Sterlites/RDxClaw/pkg/knowledge"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ingestRequest(collection string, body *bytes.Buffer, contentType string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/v1/knowledge/"+collection+"/ingest", body)
+	req.SetPathValue("collection", collection)
+	req.Header.Set("Content-Type", contentType)
+	return req
+}
+
+func TestKnowledgeIngestUpload(t *testing.T) {
+	s := newTestServer(t, &echoProvider{}, ServerConfig{})
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("file", "runbook.md")
+	require.NoError(t, err)
+	_, err = part.Write([]byte("# Runbook\n\nRestart the ingestion worker with systemctl restart ingestor.\n"))
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+
+	w := httptest.NewRecorder()
+	s.handleKnowledgeIngest(w, ingestRequest("Ops", &body, mw.FormDataContentType()))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp KnowledgeIngestResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "ops", resp.Collection)
+	assert.Equal(t, "markdown", resp.Type)
+	assert.Equal(t, 1, resp.Chunks)
+	assert.NotEmpty(t, resp.DocumentID)
+
+	results, err := s.agentLoop.GetKnowledgeStore().Search("ops", "ingestion worker", 5)
+	require.NoError(t, err)
+	require.NotEmpty(t, results)
+	assert.Equal(t, resp.DocumentID, results[0].DocumentID)
+	assert.Equal(t, "runbook.md", results[0].Chunk.Title)
+}
+
+func TestKnowledgeIngestRaw(t *testing.T) {
+	s := newTestServer(t, &echoProvider{}, ServerConfig{MaxUploadBytes: 64})
+
+	t.Run("html is converted to text", func(t *testing.T) {
+		req := ingestRequest("web", bytes.NewBufferString("<html><body><p>Hello world</p></body></html>"), "text/html")
+		req.Header.Set("X-Filename", "page.html")
+		w := httptest.NewRecorder()
+		s.handleKnowledgeIngest(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		results, err := s.agentLoop.GetKnowledgeStore().Search("web", "hello", 1)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "Hello world", results[0].Chunk.Content)
+	})
+
+	t.Run("missing filename", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.handleKnowledgeIngest(w, ingestRequest("web", bytes.NewBufferString("text"), "text/plain"))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		req := ingestRequest("web", bytes.NewBufferString(strings.Repeat("a", 100)), "text/plain")
+		req.Header.Set("X-Filename", "big.txt")
+		w := httptest.NewRecorder()
+		s.handleKnowledgeIngest(w, req)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+
+	t.Run("pdf is unsupported", func(t *testing.T) {
+		req := ingestRequest("web", bytes.NewBufferString("%PDF-1.4"), "application/pdf")
+		req.Header.Set("X-Filename", "doc.pdf")
+		w := httptest.NewRecorder()
+		s.handleKnowledgeIngest(w, req)
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	})
+}
+
+func TestKnowledgeAnalytics(t *testing.T) {
+	s := newTestServer(t, &echoProvider{}, ServerConfig{})
+	store := s.agentLoop.GetKnowledgeStore()
+	store.SetQueryLogging("ops", true)
+	for _, q := range []string{"restart", "restart", "missing topic"} {
+		_, err := store.Search("ops", q, 5)
+		require.NoError(t, err)
+	}
+
+	analytics := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/knowledge/ops/analytics"+query, nil)
+		req.SetPathValue("collection", "ops")
+		w := httptest.NewRecorder()
+		s.handleKnowledgeAnalytics(w, req)
+		return w
+	}
+
+	w := analytics("?window=1h")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var report knowledge.QueryAnalytics
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, 3, report.TotalQueries)
+	assert.Equal(t, 3, report.ZeroResultQueries)
+	require.NotEmpty(t, report.TopQueries)
+	assert.Equal(t, "restart", report.TopQueries[0].Query)
+
+	assert.Equal(t, http.StatusBadRequest, analytics("?window=forever").Code)
+	assert.Equal(t, http.StatusBadRequest, analytics("?limit=0").Code)
+}
+
+func TestKnowledgeStats(t *testing.T) {
+	s := newTestServer(t, &echoProvider{}, ServerConfig{})
+	store := s.agentLoop.GetKnowledgeStore()
+	require.NoError(t, store.AddDocument("ops", knowledge.Document{ID: "d1", Content: "restart the api server"}))
+
+	stats := func(collection string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/knowledge/"+collection+"/stats", nil)
+		req.SetPathValue("collection", collection)
+		w := httptest.NewRecorder()
+		s.handleKnowledgeStats(w, req)
+		return w
+	}
+
+	w := stats("ops")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp knowledge.IndexStats
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "ops", resp.Name)
+	assert.Equal(t, 1, resp.Documents)
+	assert.Equal(t, 4, resp.VocabularySize)
+
+	assert.Equal(t, http.StatusNotFound, stats("nope").Code)
+}
+
+func TestKnowledgeDelete(t *testing.T) {
+	s := newTestServer(t, &echoProvider{}, ServerConfig{})
+	store := s.agentLoop.GetKnowledgeStore()
+	require.NoError(t, store.AddDocument("ops", knowledge.Document{ID: "d1", Content: "restart the api server"}))
+
+	del := func(collection, id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodDelete, "/v1/knowledge/"+collection+"/documents/"+id, nil)
+		req.SetPathValue("collection", collection)
+		req.SetPathValue("id", id)
+		w := httptest.NewRecorder()
+		s.handleKnowledgeDelete(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusNoContent, del("ops", "d1").Code)
+	count, err := store.ChunkCount("ops", "d1")
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	assert.Equal(t, http.StatusNotFound, del("ops", "d1").Code)
+	assert.Equal(t, http.StatusNotFound, del("missing", "d1").Code)
+}
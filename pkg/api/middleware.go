@@ -1,17 +1,25 @@
 package api
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"log/slog"
 	"net/http"
+	"slices"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/Sterlites/RDxClaw/pkg/reqctx"
 )
 
 // --- API Key Authentication ---
 
-// AuthMiddleware validates the API key from the Authorization header.
-func AuthMiddleware(apiKey string, next http.Handler) http.Handler {
+// AuthMiddleware validates the API key from the Authorization header
+// against apiKeys. Each key is its own identity, so tenants given separate
+// keys are told apart, e.g. for rate limiting.
+func AuthMiddleware(apiKeys []string, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Public endpoints that don't require auth
 		if r.URL.Path == "/v1/status" || r.URL.Path == "/health" || r.URL.Path == "/ready" ||
@@ -20,7 +28,7 @@ func AuthMiddleware(apiKey string, next http.Handler) http.Handler {
 			return
 		}
 
-		if apiKey == "" {
+		if len(apiKeys) == 0 {
 			// No API key configured — allow all requests
 			next.ServeHTTP(w, r)
 			return
@@ -45,15 +53,31 @@ func AuthMiddleware(apiKey string, next http.Handler) http.Handler {
 			return
 		}
 
-		if parts[1] != apiKey {
+		if !slices.Contains(apiKeys, parts[1]) {
 			writeError(w, http.StatusUnauthorized, "invalid_api_key", "Invalid API key")
 			return
 		}
 
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(w, r.WithContext(withAuthIdentity(r.Context(), parts[1])))
 	})
 }
 
+type authIdentityKey struct{}
+
+// withAuthIdentity marks a request as authenticated with key. Only the
+// key's hash is stored, so nothing downstream holds the key itself.
+func withAuthIdentity(ctx context.Context, key string) context.Context {
+	sum := sha256.Sum256([]byte(key))
+	return context.WithValue(ctx, authIdentityKey{}, "key:"+hex.EncodeToString(sum[:8]))
+}
+
+// authIdentity returns the identity AuthMiddleware verified for a request,
+// or "" when it let the request through without checking a key.
+func authIdentity(ctx context.Context) string {
+	id, _ := ctx.Value(authIdentityKey{}).(string)
+	return id
+}
+
 // --- CORS ---
 
 // CORSMiddleware adds CORS headers for browser-based clients.
@@ -85,9 +109,31 @@ func CORSMiddleware(origins []string, next http.Handler) http.Handler {
 	})
 }
 
+// SplitCORSMiddleware applies apiOrigins to /v1/* routes and uiOrigins to
+// everything else, such as the bundled web UI, so the API can be locked to
+// specific origins without affecting the same-origin UI. An empty list
+// adds no CORS handling for its routes.
+func SplitCORSMiddleware(apiOrigins, uiOrigins []string, next http.Handler) http.Handler {
+	api, ui := next, next
+	if len(apiOrigins) > 0 {
+		api = CORSMiddleware(apiOrigins, next)
+	}
+	if len(uiOrigins) > 0 {
+		ui = CORSMiddleware(uiOrigins, next)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/v1/") {
+			api.ServeHTTP(w, r)
+			return
+		}
+		ui.ServeHTTP(w, r)
+	})
+}
+
 // --- Rate Limiting ---
 
-// RateLimiter implements a simple per-IP token bucket rate limiter.
+// RateLimiter implements a simple token bucket rate limiter with one bucket
+// per client identity (see rateLimitIdentity).
 type RateLimiter struct {
 	mu      sync.Mutex
 	buckets map[string]*bucket
@@ -101,7 +147,7 @@ type bucket struct {
 	lastReset time.Time
 }
 
-// NewRateLimiter creates a rate limiter allowing `rate` requests per `window` per IP.
+// NewRateLimiter creates a rate limiter allowing `rate` requests per `window` per identity.
 func NewRateLimiter(rate int, window time.Duration) *RateLimiter {
 	rl := &RateLimiter{
 		buckets: make(map[string]*bucket),
@@ -113,24 +159,31 @@ func NewRateLimiter(rate int, window time.Duration) *RateLimiter {
 	return rl
 }
 
-func (rl *RateLimiter) Allow(ip string) bool {
+// Allow consumes one request from identity's allowance and reports whether
+// there was one left.
+func (rl *RateLimiter) Allow(identity string) bool {
+	return rl.Take(identity, 1) == 1
+}
+
+// Take consumes up to n requests from identity's allowance and returns how
+// many were granted.
+func (rl *RateLimiter) Take(identity string, n int) int {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
-	b, exists := rl.buckets[ip]
+	b, exists := rl.buckets[identity]
 	now := time.Now()
 
 	if !exists || now.Sub(b.lastReset) >= rl.window {
-		rl.buckets[ip] = &bucket{tokens: rl.rate - 1, lastReset: now}
-		return true
+		b = &bucket{tokens: rl.rate, lastReset: now}
+		rl.buckets[identity] = b
 	}
 
-	if b.tokens > 0 {
-		b.tokens--
-		return true
+	if n > b.tokens {
+		n = b.tokens
 	}
-
-	return false
+	b.tokens -= n
+	return n
 }
 
 func (rl *RateLimiter) cleanupLoop() {
@@ -140,44 +193,29 @@ func (rl *RateLimiter) cleanupLoop() {
 	for range ticker.C {
 		rl.mu.Lock()
 		cutoff := time.Now().Add(-2 * rl.window)
-		for ip, b := range rl.buckets {
+		for identity, b := range rl.buckets {
 			if b.lastReset.Before(cutoff) {
-				delete(rl.buckets, ip)
+				delete(rl.buckets, identity)
 			}
 		}
 		rl.mu.Unlock()
 	}
 }
 
-// RateLimitMiddleware limits requests per IP using a token bucket.
+// RateLimitMiddleware limits requests per client (see rateLimitIdentity)
+// using a token bucket. It must run inside AuthMiddleware.
 func RateLimitMiddleware(limiter *RateLimiter, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Public/Static assets should not be rate limited to ensure UI remains functional
-		if r.URL.Path == "/" || strings.HasPrefix(r.URL.Path, "/css/") || 
-		   strings.HasPrefix(r.URL.Path, "/js/") || r.URL.Path == "/health" {
+		if r.URL.Path == "/" || strings.HasPrefix(r.URL.Path, "/css/") ||
+			strings.HasPrefix(r.URL.Path, "/js/") || r.URL.Path == "/health" {
 			next.ServeHTTP(w, r)
 			return
 		}
 
-		rawIP := r.RemoteAddr
-		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
-			rawIP = strings.Split(forwarded, ",")[0]
-		}
-
-		// Strip port if present
-		ip := strings.TrimSpace(rawIP)
-		if lastColon := strings.LastIndex(ip, ":"); lastColon != -1 && !strings.Contains(ip, "]") {
-			// IPv4 or simple hostname
-			ip = ip[:lastColon]
-		} else if strings.HasPrefix(ip, "[") && strings.Contains(ip, "]:") {
-			// IPv6 with port
-			if lastBracket := strings.LastIndex(ip, "]"); lastBracket != -1 {
-				ip = ip[:lastBracket+1]
-			}
-		}
-
-		if !limiter.Allow(ip) {
-			slog.Warn("rate_limit_tripped", "ip", ip, "path", r.URL.Path)
+		identity := rateLimitIdentity(r)
+		if !limiter.Allow(identity) {
+			slog.Warn("rate_limit_tripped", "identity", identity, "path", r.URL.Path)
 			writeError(w, http.StatusTooManyRequests, "rate_limit_exceeded", "Rate limit exceeded. Please retry later.")
 			return
 		}
@@ -186,6 +224,71 @@ func RateLimitMiddleware(limiter *RateLimiter, next http.Handler) http.Handler {
 	})
 }
 
+// rateLimitIdentity names the allowance a request draws on: the API key
+// AuthMiddleware verified, wherever the request comes from, or the client
+// IP for requests without one. Only a verified key counts, so an arbitrary
+// bearer token cannot buy a fresh allowance.
+func rateLimitIdentity(r *http.Request) string {
+	if id := authIdentity(r.Context()); id != "" {
+		return id
+	}
+	return clientIP(r)
+}
+
+// clientIP returns the address rate limits are counted against: the
+// first X-Forwarded-For hop if present, else the remote address, without
+// the port.
+func clientIP(r *http.Request) string {
+	rawIP := r.RemoteAddr
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		rawIP = strings.Split(forwarded, ",")[0]
+	}
+
+	// Strip port if present
+	ip := strings.TrimSpace(rawIP)
+	if lastColon := strings.LastIndex(ip, ":"); lastColon != -1 && !strings.Contains(ip, "]") {
+		// IPv4 or simple hostname
+		ip = ip[:lastColon]
+	} else if strings.HasPrefix(ip, "[") && strings.Contains(ip, "]:") {
+		// IPv6 with port
+		if lastBracket := strings.LastIndex(ip, "]"); lastBracket != -1 {
+			ip = ip[:lastBracket+1]
+		}
+	}
+	return ip
+}
+
+// --- Request ID ---
+
+// RequestIDHeader is the header used to pass and return request IDs.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDMiddleware assigns every request an ID, reusing a well-formed
+// X-Request-ID from the client, and stores it in the request context so
+// downstream agent, tool and provider logs can be correlated.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = reqctx.NewRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(reqctx.WithRequestID(r.Context(), id)))
+	})
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
+
 // --- Request Logging ---
 
 // LoggingMiddleware logs every API request.
@@ -201,6 +304,7 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 			"status", sw.status,
 			"duration_ms", time.Since(start).Milliseconds(),
 			"remote", r.RemoteAddr,
+			"request_id", reqctx.RequestID(r.Context()),
 		)
 	})
 }
@@ -214,3 +318,9 @@ func (sw *statusWriter) WriteHeader(code int) {
 	sw.status = code
 	sw.ResponseWriter.WriteHeader(code)
 }
+
+// Unwrap lets http.ResponseController reach the underlying writer, e.g. to
+// flush streamed responses.
+func (sw *statusWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
@@ -7,6 +7,9 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sterlites/RDxClaw/pkg/reqctx"
 )
 
 func TestAuthMiddleware(t *testing.T) {
@@ -15,7 +18,7 @@ func TestAuthMiddleware(t *testing.T) {
 	})
 
 	t.Run("allows request with valid key", func(t *testing.T) {
-		middleware := AuthMiddleware("test-key", handler)
+		middleware := AuthMiddleware([]string{"test-key"}, handler)
 		req := httptest.NewRequest("GET", "/v1/skills", nil)
 		req.Header.Set("Authorization", "Bearer test-key")
 		rr := httptest.NewRecorder()
@@ -24,7 +27,7 @@ func TestAuthMiddleware(t *testing.T) {
 	})
 
 	t.Run("rejects request with invalid key", func(t *testing.T) {
-		middleware := AuthMiddleware("test-key", handler)
+		middleware := AuthMiddleware([]string{"test-key"}, handler)
 		req := httptest.NewRequest("GET", "/v1/skills", nil)
 		req.Header.Set("Authorization", "Bearer wrong-key")
 		rr := httptest.NewRecorder()
@@ -33,7 +36,7 @@ func TestAuthMiddleware(t *testing.T) {
 	})
 
 	t.Run("rejects request without auth header", func(t *testing.T) {
-		middleware := AuthMiddleware("test-key", handler)
+		middleware := AuthMiddleware([]string{"test-key"}, handler)
 		req := httptest.NewRequest("GET", "/v1/skills", nil)
 		rr := httptest.NewRecorder()
 		middleware.ServeHTTP(rr, req)
@@ -41,7 +44,7 @@ func TestAuthMiddleware(t *testing.T) {
 	})
 
 	t.Run("allows unauthenticated request when no key configured", func(t *testing.T) {
-		middleware := AuthMiddleware("", handler)
+		middleware := AuthMiddleware(nil, handler)
 		req := httptest.NewRequest("GET", "/v1/skills", nil)
 		rr := httptest.NewRecorder()
 		middleware.ServeHTTP(rr, req)
@@ -49,7 +52,7 @@ func TestAuthMiddleware(t *testing.T) {
 	})
 
 	t.Run("health endpoint bypasses auth", func(t *testing.T) {
-		middleware := AuthMiddleware("test-key", handler)
+		middleware := AuthMiddleware([]string{"test-key"}, handler)
 		req := httptest.NewRequest("GET", "/health", nil)
 		rr := httptest.NewRecorder()
 		middleware.ServeHTTP(rr, req)
@@ -57,7 +60,7 @@ func TestAuthMiddleware(t *testing.T) {
 	})
 
 	t.Run("status endpoint bypasses auth", func(t *testing.T) {
-		middleware := AuthMiddleware("test-key", handler)
+		middleware := AuthMiddleware([]string{"test-key"}, handler)
 		req := httptest.NewRequest("GET", "/v1/status", nil)
 		rr := httptest.NewRecorder()
 		middleware.ServeHTTP(rr, req)
@@ -96,6 +99,39 @@ func TestRateLimiter(t *testing.T) {
 	})
 }
 
+func TestRateLimitMiddlewareIdentity(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	limited := RateLimitMiddleware(NewRateLimiter(1, time.Minute), handler)
+
+	serve := func(h http.Handler, remoteAddr, token string) int {
+		req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+		req.RemoteAddr = remoteAddr
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	t.Run("unverified tokens do not buy a fresh allowance", func(t *testing.T) {
+		middleware := AuthMiddleware(nil, limited)
+		assert.Equal(t, http.StatusOK, serve(middleware, "203.0.113.7:1000", "fake-1"))
+		assert.Equal(t, http.StatusTooManyRequests, serve(middleware, "203.0.113.7:1001", "fake-2"))
+		assert.Equal(t, http.StatusTooManyRequests, serve(middleware, "203.0.113.7:1002", ""))
+	})
+
+	t.Run("each key has one allowance whatever the client IP", func(t *testing.T) {
+		middleware := AuthMiddleware([]string{"tenant-a", "tenant-b"}, RateLimitMiddleware(NewRateLimiter(1, time.Minute), handler))
+		assert.Equal(t, http.StatusOK, serve(middleware, "198.51.100.1:1000", "tenant-a"))
+		assert.Equal(t, http.StatusTooManyRequests, serve(middleware, "198.51.100.2:1000", "tenant-a"))
+		// Another tenant behind the same NAT has its own allowance
+		assert.Equal(t, http.StatusOK, serve(middleware, "198.51.100.1:1001", "tenant-b"))
+	})
+}
+
 func TestCORSMiddleware(t *testing.T) {
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -128,3 +164,112 @@ func TestCORSMiddleware(t *testing.T) {
 		assert.Equal(t, "http://anything.com", rr.Header().Get("Access-Control-Allow-Origin"))
 	})
 }
+
+func TestSplitCORSMiddleware(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	middleware := SplitCORSMiddleware([]string{"https://dash.example.com"}, nil, handler)
+
+	serve := func(method, path, origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		rr := httptest.NewRecorder()
+		middleware.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("API allows the configured origin", func(t *testing.T) {
+		rr := serve("GET", "/v1/skills", "https://dash.example.com")
+		assert.Equal(t, "https://dash.example.com", rr.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("API rejects other origins", func(t *testing.T) {
+		rr := serve("GET", "/v1/skills", "https://evil.example.com")
+		assert.Empty(t, rr.Header().Get("Access-Control-Allow-Origin"))
+
+		rr = serve("OPTIONS", "/v1/chat/completions", "https://evil.example.com")
+		assert.Empty(t, rr.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("static assets are unaffected", func(t *testing.T) {
+		for _, path := range []string{"/", "/css/style.css", "/js/app.js"} {
+			rr := serve("GET", path, "")
+			assert.Equal(t, http.StatusOK, rr.Code, path)
+			assert.Empty(t, rr.Header().Get("Access-Control-Allow-Origin"), path)
+
+			// The API origin gets no CORS headers on UI routes either.
+			rr = serve("GET", path, "https://dash.example.com")
+			assert.Equal(t, http.StatusOK, rr.Code, path)
+			assert.Empty(t, rr.Header().Get("Access-Control-Allow-Origin"), path)
+		}
+	})
+
+	t.Run("UI policy applies to UI routes only", func(t *testing.T) {
+		middleware := SplitCORSMiddleware(nil, []string{"*"}, handler)
+		req := httptest.NewRequest("GET", "/js/app.js", nil)
+		req.Header.Set("Origin", "https://cdn.example.com")
+		rr := httptest.NewRecorder()
+		middleware.ServeHTTP(rr, req)
+		assert.Equal(t, "https://cdn.example.com", rr.Header().Get("Access-Control-Allow-Origin"))
+
+		req = httptest.NewRequest("GET", "/v1/skills", nil)
+		req.Header.Set("Origin", "https://cdn.example.com")
+		rr = httptest.NewRecorder()
+		middleware.ServeHTTP(rr, req)
+		assert.Empty(t, rr.Header().Get("Access-Control-Allow-Origin"))
+	})
+}
+
+func TestCORSPreflightWithAuth(t *testing.T) {
+	s := newTestServer(t, &echoProvider{}, ServerConfig{APIKey: "secret", CORSOrigins: []string{"https://dash.example.com"}})
+	handler, err := s.handler()
+	require.NoError(t, err)
+
+	serve := func(method string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/v1/chat/completions", nil)
+		req.Header.Set("Origin", "https://dash.example.com")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := serve(http.MethodOptions)
+	assert.Equal(t, http.StatusNoContent, rr.Code, "preflights carry no credentials")
+	assert.Equal(t, "https://dash.example.com", rr.Header().Get("Access-Control-Allow-Origin"))
+
+	rr = serve(http.MethodPost)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Equal(t, "https://dash.example.com", rr.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var seen string
+	handler := RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = reqctx.RequestID(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	t.Run("propagates client request ID", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/health", nil)
+		req.Header.Set(RequestIDHeader, "abc-123")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		assert.Equal(t, "abc-123", seen)
+		assert.Equal(t, "abc-123", rr.Header().Get(RequestIDHeader))
+	})
+
+	t.Run("generates ID when missing or malformed", func(t *testing.T) {
+		for _, header := range []string{"", "bad id\nwith newline"} {
+			req := httptest.NewRequest("GET", "/health", nil)
+			req.Header.Set(RequestIDHeader, header)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			assert.NotEmpty(t, seen)
+			assert.NotEqual(t, header, seen)
+			assert.Equal(t, seen, rr.Header().Get(RequestIDHeader))
+		}
+	})
+}
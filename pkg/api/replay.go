@@ -0,0 +1,45 @@
+package api
+
+import "sync"
+
+// defaultWebhookReplaySize is how many payloads are kept per webhook path.
+const defaultWebhookReplaySize = 5
+
+// webhookReplay keeps the most recent payloads received on each webhook
+// path so they can be replayed while developing a skill.
+type webhookReplay struct {
+	mu     sync.Mutex
+	size   int
+	events map[string][]WebhookEvent // oldest first
+}
+
+func newWebhookReplay(size int) *webhookReplay {
+	if size <= 0 {
+		size = defaultWebhookReplaySize
+	}
+	return &webhookReplay{size: size, events: make(map[string][]WebhookEvent)}
+}
+
+// Add stores an event, dropping the oldest one for its path when full.
+func (b *webhookReplay) Add(event WebhookEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	events := append(b.events[event.Path], event)
+	if len(events) > b.size {
+		events = append([]WebhookEvent(nil), events[len(events)-b.size:]...)
+	}
+	b.events[event.Path] = events
+}
+
+// Get returns a stored event for path; index 0 is the most recent.
+func (b *webhookReplay) Get(path string, index int) (WebhookEvent, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	events := b.events[path]
+	if index < 0 || index >= len(events) {
+		return WebhookEvent{}, false
+	}
+	return events[len(events)-1-index], true
+}
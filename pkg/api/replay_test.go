@@ -0,0 +1,229 @@
+package api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookReplayBuffer(t *testing.T) {
+	b := newWebhookReplay(2)
+	_, ok := b.Get("/shopify", 0)
+	assert.False(t, ok)
+
+	for _, body := range []string{"a", "b", "c"} {
+		b.Add(WebhookEvent{Path: "/shopify", RawBody: body})
+	}
+	b.Add(WebhookEvent{Path: "/github", RawBody: "x"})
+
+	latest, ok := b.Get("/shopify", 0)
+	require.True(t, ok)
+	assert.Equal(t, "c", latest.RawBody)
+	older, ok := b.Get("/shopify", 1)
+	require.True(t, ok)
+	assert.Equal(t, "b", older.RawBody)
+	_, ok = b.Get("/shopify", 2)
+	assert.False(t, ok, "oldest payload should have been dropped")
+}
+
+func TestWebhookReplay(t *testing.T) {
+	s := newTestServer(t, &echoProvider{}, ServerConfig{})
+
+	w := httptest.NewRecorder()
+	s.handleWebhook(w, httptest.NewRequest(http.MethodPost, "/v1/webhooks/shopify", strings.NewReader(`{"order":42}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	s.handleWebhook(w, httptest.NewRequest(http.MethodPost, "/v1/webhooks/shopify/replay", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp WebhookReplayResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "/shopify", resp.Path)
+	assert.Equal(t, `{"order":42}`, resp.Event.RawBody)
+	assert.Contains(t, resp.Response, "[Webhook received on /shopify]")
+	assert.Empty(t, resp.Error)
+
+	t.Run("unknown path", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.handleWebhook(w, httptest.NewRequest(http.MethodPost, "/v1/webhooks/github/replay", nil))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("invalid index", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.handleWebhook(w, httptest.NewRequest(http.MethodPost, "/v1/webhooks/shopify/replay?index=-1", nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestWebhookSignature(t *testing.T) {
+	t.Setenv("GITHUB_WEBHOOK_SECRET", "s3cret")
+
+	workspace := t.TempDir()
+	skillDir := filepath.Join(workspace, "skills", "github")
+	require.NoError(t, os.MkdirAll(skillDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(skillDir, "SKILL.md"),
+		[]byte("---\nname: github\ndescription: GitHub events\n---\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(skillDir, "manifest.json"), []byte(`{
+		"name": "github", "version": "1.0.0", "description": "GitHub events",
+		"webhooks": [{"path": "/github", "signature": {
+			"secret_env": "GITHUB_WEBHOOK_SECRET", "header": "X-Hub-Signature-256", "prefix": "sha256="}}]}`), 0644))
+	s := newTestServerIn(t, workspace, &echoProvider{}, ServerConfig{})
+
+	body := `{"action":"opened"}`
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte(body))
+	valid := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	tests := []struct {
+		name         string
+		path         string
+		signature    string
+		wantStatus   int
+		wantVerified bool
+	}{
+		{name: "valid signature", path: "/v1/webhooks/github", signature: valid, wantStatus: http.StatusOK, wantVerified: true},
+		{name: "missing signature", path: "/v1/webhooks/github", wantStatus: http.StatusUnauthorized},
+		{name: "wrong signature", path: "/v1/webhooks/github", signature: "sha256=" + strings.Repeat("0", 64), wantStatus: http.StatusUnauthorized},
+		{name: "unverified path", path: "/v1/webhooks/other", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(body))
+			if tt.signature != "" {
+				req.Header.Set("X-Hub-Signature-256", tt.signature)
+			}
+			w := httptest.NewRecorder()
+			s.handleWebhook(w, req)
+			require.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			var resp map[string]any
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.wantVerified, resp["verified"])
+		})
+	}
+
+	// Rejected deliveries never reach the agent or the replay buffer.
+	_, stored := s.webhooks.Get("/github", 1)
+	assert.False(t, stored, "only the valid delivery may be stored")
+}
+
+func TestWebhookStripeSignature(t *testing.T) {
+	t.Setenv("STRIPE_WEBHOOK_SECRET", "whsec")
+
+	workspace := t.TempDir()
+	skillDir := filepath.Join(workspace, "skills", "stripe")
+	require.NoError(t, os.MkdirAll(skillDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(skillDir, "SKILL.md"),
+		[]byte("---\nname: stripe\ndescription: Stripe events\n---\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(skillDir, "manifest.json"), []byte(`{
+		"name": "stripe", "version": "1.0.0", "description": "Stripe events",
+		"webhooks": [{"path": "/stripe", "signature": {
+			"secret_env": "STRIPE_WEBHOOK_SECRET", "header": "Stripe-Signature", "scheme": "stripe"}}]}`), 0644))
+	s := newTestServerIn(t, workspace, &echoProvider{}, ServerConfig{APIKey: "api-key"})
+	handler, err := s.handler()
+	require.NoError(t, err)
+
+	body := `{"type":"charge.succeeded"}`
+	sign := func(at time.Time) string {
+		ts := strconv.FormatInt(at.Unix(), 10)
+		mac := hmac.New(sha256.New, []byte("whsec"))
+		mac.Write([]byte(ts + "." + body))
+		return "t=" + ts + ",v1=" + strings.Repeat("0", 64) + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		signature  string
+		wantStatus int
+	}{
+		{name: "valid signature needs no API key", path: "/v1/webhooks/stripe", signature: sign(time.Now()), wantStatus: http.StatusOK},
+		{name: "expired signature", path: "/v1/webhooks/stripe", signature: sign(time.Now().Add(-10 * time.Minute)), wantStatus: http.StatusUnauthorized},
+		{name: "body signed without timestamp", path: "/v1/webhooks/stripe", signature: "v1=" + strings.Repeat("0", 64), wantStatus: http.StatusUnauthorized},
+		{name: "missing signature", path: "/v1/webhooks/stripe", wantStatus: http.StatusUnauthorized},
+		{name: "replay still needs the API key", path: "/v1/webhooks/stripe/replay", wantStatus: http.StatusUnauthorized},
+		{name: "unverified path still needs the API key", path: "/v1/webhooks/other", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(body))
+			if tt.signature != "" {
+				req.Header.Set("Stripe-Signature", tt.signature)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestWebhookBodyLimit(t *testing.T) {
+	t.Setenv("STRIPE_WEBHOOK_SECRET", "whsec")
+
+	workspace := t.TempDir()
+	skillDir := filepath.Join(workspace, "skills", "stripe")
+	require.NoError(t, os.MkdirAll(skillDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(skillDir, "SKILL.md"),
+		[]byte("---\nname: stripe\ndescription: Stripe events\n---\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(skillDir, "manifest.json"), []byte(`{
+		"name": "stripe", "version": "1.0.0", "description": "Stripe events",
+		"webhooks": [{"path": "/stripe", "signature": {
+			"secret_env": "STRIPE_WEBHOOK_SECRET", "header": "Stripe-Signature", "scheme": "stripe"}}]}`), 0644))
+	s := newTestServerIn(t, workspace, &echoProvider{}, ServerConfig{APIKey: "api-key", MaxWebhookBytes: 64})
+	handler, err := s.handler()
+	require.NoError(t, err)
+
+	// The signed path skips API-key auth, so the body is bounded before
+	// the signature is looked at.
+	req := httptest.NewRequest(http.MethodPost, "/v1/webhooks/stripe", strings.NewReader(strings.Repeat("x", 65)))
+	req.Header.Set("Stripe-Signature", "t=1,v1="+strings.Repeat("0", 64))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code, w.Body.String())
+
+	var resp ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "request_too_large", resp.Error.Code)
+}
+
+func TestWebhookSync(t *testing.T) {
+	s := newTestServer(t, &echoProvider{}, ServerConfig{WebhookTimeout: 5 * time.Second})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.agentLoop.Run(ctx)
+
+	w := httptest.NewRecorder()
+	s.handleWebhook(w, httptest.NewRequest(http.MethodPost, "/v1/webhooks/slack?sync=1", strings.NewReader(`{"text":"hi"}`)))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "[Webhook received on /slack]", "the body is the agent's reply")
+
+	t.Run("timeout", func(t *testing.T) {
+		s := newTestServer(t, &blockingProvider{}, ServerConfig{WebhookTimeout: 50 * time.Millisecond})
+		go s.agentLoop.Run(ctx)
+
+		w := httptest.NewRecorder()
+		s.handleWebhook(w, httptest.NewRequest(http.MethodPost, "/v1/webhooks/slack?sync=1", strings.NewReader(`{}`)))
+		assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	})
+}
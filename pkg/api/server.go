@@ -4,19 +4,26 @@ import (
 	"context"
 	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"log/slog"
+	"net"
 	"net/http"
 	"runtime"
+	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/Sterlites/RDxClaw/pkg/agent"
 	"github.com/Sterlites/RDxClaw/pkg/bus"
+	"github.com/Sterlites/RDxClaw/pkg/health"
+	"github.com/Sterlites/RDxClaw/pkg/providers"
+	"github.com/Sterlites/RDxClaw/pkg/reqctx"
 	"github.com/Sterlites/RDxClaw/pkg/skills"
+	"github.com/Sterlites/RDxClaw/pkg/swarm"
+	"github.com/Sterlites/RDxClaw/pkg/tools"
 )
 
 //go:embed web/*
@@ -32,17 +39,87 @@ type Server struct {
 	config    ServerConfig
 	startedAt time.Time
 	version   string
-	events    []ActivityEvent
-	eventsMu  sync.RWMutex
+	events    *eventRing
+	health    *health.Handler
+	webhooks  *webhookReplay
+	limiter   *RateLimiter // nil when rate limiting is off
+	alerts    *alerter     // nil when alerting is off
+	server    *http.Server
+
+	// Bounds on one synchronous agent run, per endpoint
+	requestTimeout time.Duration
+	chatTimeout    time.Duration
+	skillTimeout   time.Duration
+	webhookTimeout time.Duration
+
+	maxUploadBytes  int64
+	maxWebhookBytes int64
 }
 
+// defaultRequestTimeout bounds how long a request waits for the agent.
+const defaultRequestTimeout = 5 * time.Minute
+
+// statusClientClosedRequest is the non-standard status for a request the
+// client abandoned before the agent finished.
+const statusClientClosedRequest = 499
+
 // ServerConfig holds configuration for the API server.
 type ServerConfig struct {
-	Host        string
-	Port        int
-	APIKey      string
-	RateLimit   int // requests per minute (0 = unlimited)
-	CORSOrigins []string
+	Host           string
+	Port           int
+	APIKey         string
+	APIKeys        []string // further keys accepted besides APIKey, e.g. one per tenant
+	RateLimit      int      // requests per minute (0 = unlimited)
+	CORSOrigins    []string // for /v1/* routes
+	EventRetention int      // activity events kept in memory (default 50)
+	Build          BuildInfo
+
+	// WebhookReplaySize is how many payloads are kept per webhook path for
+	// replay (default 5).
+	WebhookReplaySize int
+
+	// RequestTimeout bounds how long a request waits for the agent (default
+	// 5m). ChatTimeout, SkillTimeout and WebhookTimeout override it per
+	// endpoint; WebhookTimeout applies to synchronous webhooks.
+	RequestTimeout time.Duration
+	ChatTimeout    time.Duration
+	SkillTimeout   time.Duration
+	WebhookTimeout time.Duration
+
+	// MaxUploadBytes caps a knowledge file upload (default 10 MiB).
+	MaxUploadBytes int64
+
+	// MaxWebhookBytes caps a webhook body (default 1 MiB). Signed webhook
+	// paths are open to unauthenticated senders, so the body is bounded
+	// before its signature is checked.
+	MaxWebhookBytes int64
+
+	// ShutdownDelay is how long Stop keeps serving after it reports the
+	// server not ready, so load balancers notice before connections are
+	// refused.
+	ShutdownDelay time.Duration
+
+	// UICORSOrigins is the CORS policy for the web UI and other non-/v1
+	// routes (none by default, as the UI is served same-origin).
+	UICORSOrigins []string
+
+	// Models are listed by GET /v1/models after the agent's own model,
+	// e.g. per-channel models and those declared in config.
+	Models []string
+
+	// Alerts raises an alert when error events spike (off by default).
+	Alerts AlertConfig
+}
+
+// apiKeys returns every key the server accepts.
+func (c ServerConfig) apiKeys() []string {
+	var keys []string
+	for _, key := range append([]string{c.APIKey}, c.APIKeys...) {
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
 }
 
 // NewServer creates a new API server instance.
@@ -53,47 +130,149 @@ func NewServer(agentLoop *agent.AgentLoop, msgBus *bus.MessageBus, loader *skill
 		loader:    loader,
 		config:    cfg,
 		startedAt: time.Now(),
-		version:   "1.0.0",
-		events:    make([]ActivityEvent, 0),
+		version:   cfg.Build.withDefaults().Version,
+		events:    newEventRing(eventRetention(cfg.EventRetention)),
+		health:    health.NewHandler(),
+		webhooks:  newWebhookReplay(cfg.WebhookReplaySize),
+		alerts:    newAlerter(cfg.Alerts),
+		server:    &http.Server{Addr: fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)},
+	}
+	s.requestTimeout = requestTimeout("request", cfg.RequestTimeout, defaultRequestTimeout)
+	s.chatTimeout = requestTimeout("chat", cfg.ChatTimeout, s.requestTimeout)
+	s.skillTimeout = requestTimeout("skill", cfg.SkillTimeout, s.requestTimeout)
+	s.webhookTimeout = requestTimeout("webhook", cfg.WebhookTimeout, s.requestTimeout)
+	s.maxUploadBytes = cfg.MaxUploadBytes
+	if s.maxUploadBytes <= 0 {
+		s.maxUploadBytes = defaultMaxUploadBytes
+	}
+	s.maxWebhookBytes = cfg.MaxWebhookBytes
+	if s.maxWebhookBytes <= 0 {
+		s.maxWebhookBytes = defaultMaxWebhookBytes
+	}
+	if cfg.RateLimit > 0 {
+		s.limiter = NewRateLimiter(cfg.RateLimit, time.Minute)
 	}
 	s.recordEvent("system", "success", "RDxClaw Mission Control initialized")
 	return s
 }
 
-func (s *Server) recordEvent(source, eventType, message string) {
-	s.eventsMu.Lock()
-	defer s.eventsMu.Unlock()
+// withDefaults fills in what an unstamped build leaves empty.
+func (b BuildInfo) withDefaults() BuildInfo {
+	if b.Version == "" {
+		b.Version = "dev"
+	}
+	if b.GoVersion == "" {
+		b.GoVersion = runtime.Version()
+	}
+	return b
+}
 
+// eventRetention validates the configured retention count, falling back to
+// the default when it is unset or not positive.
+func eventRetention(n int) int {
+	if n < 0 {
+		slog.Warn("invalid event retention, using default", "event_retention", n, "default", defaultEventRetention)
+	}
+	if n <= 0 {
+		return defaultEventRetention
+	}
+	return n
+}
+
+// requestTimeout validates a configured timeout, falling back to def when
+// it is unset or not positive.
+func requestTimeout(endpoint string, d, def time.Duration) time.Duration {
+	if d < 0 {
+		slog.Warn("invalid request timeout, using default", "endpoint", endpoint, "timeout", d, "default", def)
+	}
+	if d <= 0 {
+		return def
+	}
+	return d
+}
+
+func (s *Server) recordEvent(source, eventType, message string) {
 	event := ActivityEvent{
 		Timestamp: time.Now(),
 		Source:    source,
 		Type:      eventType,
 		Message:   message,
 	}
-
-	// Keep only the last 50 events
-	s.events = append(s.events, event)
-	if len(s.events) > 50 {
-		s.events = s.events[1:]
+	s.events.Record(event)
+	if s.alerts == nil {
+		return
+	}
+	if alert := s.alerts.observe(event); alert != nil {
+		go s.alerts.dispatch(alert, s.msgBus)
 	}
 }
 
-// Start starts the API server (blocking).
+// Start starts the API server and blocks until it fails or Stop is called,
+// in which case it returns nil.
 func (s *Server) Start() error {
+	ln, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return err
+	}
+	return s.serve(ln)
+}
+
+// serve answers requests on ln until the server is stopped.
+func (s *Server) serve(ln net.Listener) error {
+	handler, err := s.handler()
+	if err != nil {
+		ln.Close()
+		return err
+	}
+	s.server.Handler = handler
+
+	slog.Info("API server starting", "addr", ln.Addr().String())
+	s.health.SetReady(true)
+	defer s.health.SetReady(false)
+	if err := s.server.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Stop stops accepting connections and waits for the requests in flight,
+// including chat completions, to finish or for ctx to end. The server
+// reports itself not ready first and keeps serving for ShutdownDelay, so
+// load balancers stop sending traffic. Allow it DrainTimeout.
+func (s *Server) Stop(ctx context.Context) error {
+	s.health.SetReady(false)
+	if s.config.ShutdownDelay > 0 {
+		select {
+		case <-time.After(s.config.ShutdownDelay):
+		case <-ctx.Done():
+		}
+	}
+	return s.server.Shutdown(ctx)
+}
+
+// DrainTimeout is how long Stop may take to let every request in flight
+// run out its timeout.
+func (s *Server) DrainTimeout() time.Duration {
+	longest := max(s.requestTimeout, s.chatTimeout, s.skillTimeout, s.webhookTimeout)
+	return s.config.ShutdownDelay + longest
+}
+
+// handler builds the routes and the middleware stack in front of them.
+func (s *Server) handler() (http.Handler, error) {
 	mux := http.NewServeMux()
 
 	// Embed Frontend Web UI
 	webContent, err := fs.Sub(embeddedWebFS, "web")
 	if err != nil {
-		return fmt.Errorf("failed to prepare embedded web fs: %v", err)
+		return nil, fmt.Errorf("failed to prepare embedded web fs: %v", err)
 	}
-	
-	// Serve static files from the embedded FS. 
+
+	// Serve static files from the embedded FS.
 	// The pattern "GET /" acts as a catch-all for GET requests not matched by other routes.
-	
+
 	// Serve static files from the embedded FS.
 	fileServer := http.FileServer(http.FS(webContent))
-	
+
 	// Specific handler for root to ensure index.html is served correctly without redirect loops
 	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
 		index, err := fs.ReadFile(webContent, "index.html")
@@ -111,33 +290,46 @@ func (s *Server) Start() error {
 
 	// Register routes
 	mux.HandleFunc("POST /v1/chat/completions", s.handleChatCompletion)
+	mux.HandleFunc("POST /v1/chat/completions/batch", s.handleChatCompletionBatch)
 	mux.HandleFunc("POST /v1/skills/{skill}/execute", s.handleSkillExecute)
-	mux.HandleFunc("POST /v1/webhooks/", s.handleWebhook) // catch-all for webhook paths
+	mux.HandleFunc("POST /v1/webhooks/", s.handleWebhook) // catch-all for webhook paths; "<path>/replay" replays
 	mux.HandleFunc("GET /v1/status", s.handleStatus)
+	mux.HandleFunc("GET /v1/models", s.handleListModels)
+	mux.HandleFunc("GET /v1/models/{model...}", s.handleGetModel)
+	mux.HandleFunc("POST /v1/embeddings", s.handleEmbeddings)
 	mux.HandleFunc("GET /v1/skills", s.handleListSkills)
+	mux.HandleFunc("GET /v1/skills/{skill}/schema", s.handleSkillSchema)
 	mux.HandleFunc("GET /v1/agents", s.handleListAgents)
 	mux.HandleFunc("DELETE /v1/agents/{id}", s.handleKillAgent)
-	mux.HandleFunc("GET /health", s.handleHealth)
-	mux.HandleFunc("GET /ready", s.handleHealth)
+	mux.HandleFunc("POST /v1/agent/pause", s.handlePauseAgent)
+	mux.HandleFunc("POST /v1/agent/resume", s.handleResumeAgent)
+	mux.HandleFunc("POST /v1/knowledge/{collection}/ingest", s.handleKnowledgeIngest)
+	mux.HandleFunc("GET /v1/knowledge/{collection}/analytics", s.handleKnowledgeAnalytics)
+	mux.HandleFunc("GET /v1/knowledge/{collection}/stats", s.handleKnowledgeStats)
+	mux.HandleFunc("DELETE /v1/knowledge/{collection}/documents/{id}", s.handleKnowledgeDelete)
+	s.health.Register(mux)
 
 	// Apply middleware stack
 	var handler http.Handler = mux
 	handler = LoggingMiddleware(handler)
 
-	if s.config.RateLimit > 0 {
-		limiter := NewRateLimiter(s.config.RateLimit, time.Minute)
-		handler = RateLimitMiddleware(limiter, handler)
+	if s.limiter != nil {
+		handler = RateLimitMiddleware(s.limiter, handler)
 	}
 
-	if len(s.config.CORSOrigins) > 0 {
-		handler = CORSMiddleware(s.config.CORSOrigins, handler)
-	}
+	handler = s.exemptVerifiedWebhooks(AuthMiddleware(s.config.apiKeys(), handler), handler)
 
-	handler = AuthMiddleware(s.config.APIKey, handler)
+	// CORS wraps auth: browsers send preflights without credentials, and
+	// need the headers on a 401 to read it.
+	handler = SplitCORSMiddleware(s.config.CORSOrigins, s.config.UICORSOrigins, handler)
+	handler = RequestIDMiddleware(handler)
+	return handler, nil
+}
 
-	addr := fmt.Sprintf("%s:%d", s.config.Host, s.config.Port)
-	slog.Info("API server starting", "addr", addr)
-	return http.ListenAndServe(addr, handler)
+// Health returns the handler behind /health and /ready, so callers can
+// register readiness checks.
+func (s *Server) Health() *health.Handler {
+	return s.health
 }
 
 // --- Handlers ---
@@ -149,10 +341,47 @@ func (s *Server) handleChatCompletion(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(req.Messages) == 0 {
-		writeError(w, http.StatusBadRequest, "invalid_request", "messages array is required and must not be empty")
+	if req.Stream {
+		s.streamChat(w, r, req)
+		return
+	}
+
+	resp, failure := s.completeChat(r.Context(), req)
+	if failure != nil {
+		writeError(w, failure.status, failure.code, failure.message)
 		return
 	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// chatFailure is a chat completion that could not be produced, with the
+// HTTP status it maps to.
+type chatFailure struct {
+	status  int
+	code    string
+	message string
+}
+
+func (f *chatFailure) detail() *ErrorDetail {
+	return &ErrorDetail{Message: f.message, Type: "api_error", Code: f.code}
+}
+
+// chatTurn is a validated chat completion request, ready to run.
+type chatTurn struct {
+	content       string // the last user message
+	sessionKey    string
+	channel       string
+	maxIterations int
+	options       agent.RequestOptions // model and sampling settings set by the request
+}
+
+// newChatTurn validates a chat completion request and fills in its
+// session key and channel. A model, if given, must be one GET /v1/models
+// lists.
+func (s *Server) newChatTurn(req ChatCompletionRequest) (chatTurn, *chatFailure) {
+	if len(req.Messages) == 0 {
+		return chatTurn{}, &chatFailure{http.StatusBadRequest, "invalid_request", "messages array is required and must not be empty"}
+	}
 
 	// Extract the last user message
 	var userContent string
@@ -164,8 +393,20 @@ func (s *Server) handleChatCompletion(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if userContent == "" {
-		writeError(w, http.StatusBadRequest, "invalid_request", "at least one user message is required")
-		return
+		return chatTurn{}, &chatFailure{http.StatusBadRequest, "invalid_request", "at least one user message is required"}
+	}
+
+	if err := tools.ValidateMaxIterations(req.MaxIterations); err != nil {
+		return chatTurn{}, &chatFailure{http.StatusBadRequest, "invalid_request", err.Error()}
+	}
+
+	if req.Model != "" && !s.hasModel(req.Model) {
+		return chatTurn{}, &chatFailure{http.StatusBadRequest, "model_not_found", fmt.Sprintf("model '%s' not found", req.Model)}
+	}
+
+	options := agent.RequestOptions{Model: req.Model, Temperature: req.Temperature, MaxTokens: req.MaxTokens}
+	if err := options.Validate(); err != nil {
+		return chatTurn{}, &chatFailure{http.StatusBadRequest, "invalid_request", err.Error()}
 	}
 
 	// Generate session key
@@ -179,19 +420,53 @@ func (s *Server) handleChatCompletion(w http.ResponseWriter, r *http.Request) {
 		channel = "api"
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Minute)
+	return chatTurn{
+		content:       userContent,
+		sessionKey:    sessionKey,
+		channel:       channel,
+		maxIterations: req.MaxIterations,
+		options:       options,
+	}, nil
+}
+
+// chatContext returns the context a chat turn runs in, bounded by the chat
+// timeout. The model and sampling settings the request leaves unset keep
+// the agent's defaults.
+func (s *Server) chatContext(parent context.Context, turn chatTurn) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(reqctx.WithSessionKey(parent, turn.sessionKey), s.chatTimeout)
+	ctx = agent.WithRequestOptions(ctx, turn.options)
+	if turn.maxIterations > 0 {
+		ctx = tools.WithMaxIterations(ctx, turn.maxIterations)
+	}
+	return ctx, cancel
+}
+
+// completeChat validates a chat completion request and runs it through the
+// agent, bounded by the chat timeout.
+func (s *Server) completeChat(parent context.Context, req ChatCompletionRequest) (*ChatCompletionResponse, *chatFailure) {
+	turn, failure := s.newChatTurn(req)
+	if failure != nil {
+		return nil, failure
+	}
+
+	ctx, cancel := s.chatContext(parent, turn)
 	defer cancel()
 
-	response, err := s.agentLoop.ProcessDirectWithChannel(ctx, userContent, sessionKey, channel, "api")
+	// Every model call of the turn, tool iterations included, counts
+	// towards the usage reported back.
+	usage := providers.NewUsageTracker()
+	ctx = providers.WithUsageTracker(ctx, usage)
+	report := &agent.TurnReport{}
+	ctx = agent.WithTurnReport(ctx, report)
+
+	response, err := s.agentLoop.ProcessDirectWithChannel(ctx, turn.content, turn.sessionKey, turn.channel, "api")
 	if err != nil {
-		s.recordEvent("agent", "error", fmt.Sprintf("Chat error: %v", err))
-		writeError(w, http.StatusInternalServerError, "processing_error", err.Error())
-		return
+		return nil, s.chatError(ctx, err)
 	}
 
 	s.recordEvent("agent", "info", "Processed user request")
 
-	writeJSON(w, http.StatusOK, ChatCompletionResponse{
+	return &ChatCompletionResponse{
 		ID:      fmt.Sprintf("chatcmpl-%d", time.Now().UnixNano()),
 		Object:  "chat.completion",
 		Created: time.Now().Unix(),
@@ -200,10 +475,62 @@ func (s *Server) handleChatCompletion(w http.ResponseWriter, r *http.Request) {
 			{
 				Index:        0,
 				Message:      ChatMessage{Role: "assistant", Content: response},
-				FinishReason: "stop",
+				FinishReason: finishReason(report),
 			},
 		},
-	})
+		Usage: chatUsage(usage.Total()),
+	}, nil
+}
+
+// finishReason tells clients whether the reply is complete ("stop") or was
+// cut off at max_tokens ("length").
+func finishReason(report *agent.TurnReport) string {
+	if report.Truncated {
+		return "length"
+	}
+	return "stop"
+}
+
+// chatUsage converts the usage accumulated over a turn for the response.
+func chatUsage(total providers.ModelUsage) *ChatCompletionUsage {
+	return &ChatCompletionUsage{
+		PromptTokens:     int(total.PromptTokens),
+		CompletionTokens: int(total.CompletionTokens),
+		TotalTokens:      int(total.TotalTokens),
+	}
+}
+
+// chatError classifies a failed chat run. Running out of time (504) and
+// the client going away (499) are told apart from genuine processing errors
+// so clients can decide whether to retry.
+func (s *Server) chatError(ctx context.Context, err error) *chatFailure {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded):
+		msg := fmt.Sprintf("request timed out after %v", s.chatTimeout)
+		s.recordEvent("agent", "warning", "Chat "+msg)
+		return &chatFailure{http.StatusGatewayTimeout, "timeout", msg}
+	case errors.Is(err, context.Canceled) || errors.Is(ctx.Err(), context.Canceled):
+		s.recordEvent("agent", "warning", "Chat request cancelled by the client")
+		return &chatFailure{statusClientClosedRequest, "cancelled", "request was cancelled before the agent finished"}
+	case providers.ErrorKindOf(err) != "":
+		s.recordEvent("agent", "error", fmt.Sprintf("Chat provider error: %v", err))
+		return providerFailure(err)
+	default:
+		s.recordEvent("agent", "error", fmt.Sprintf("Chat error: %v", err))
+		return &chatFailure{http.StatusInternalServerError, "processing_error", err.Error()}
+	}
+}
+
+// providerFailure reports a classified provider error. Rate limits keep
+// their status so clients back off; everything else is the upstream's
+// failure.
+func providerFailure(err error) *chatFailure {
+	kind := providers.ErrorKindOf(err)
+	status := http.StatusBadGateway
+	if kind == providers.ErrorRateLimit {
+		status = http.StatusTooManyRequests
+	}
+	return &chatFailure{status, "provider_" + string(kind), err.Error()}
 }
 
 func (s *Server) handleSkillExecute(w http.ResponseWriter, r *http.Request) {
@@ -226,17 +553,41 @@ func (s *Server) handleSkillExecute(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	manifest := s.loader.GetSkillManifest(skillName)
+	if manifest != nil {
+		if missing := manifest.MissingInputs(req.Params); len(missing) > 0 {
+			writeError(w, http.StatusBadRequest, "invalid_request", "missing required inputs: "+strings.Join(missing, ", "))
+			return
+		}
+	}
+
 	// Build the prompt with skill context
 	prompt := fmt.Sprintf("[Using skill: %s]\n\n%s", skillName, req.Input)
+	if len(req.Params) > 0 {
+		params, err := json.MarshalIndent(req.Params, "", "  ")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_request", "invalid params: "+err.Error())
+			return
+		}
+		prompt += "\n\nInputs:\n" + string(params)
+	}
 
 	sessionKey := req.SessionKey
 	if sessionKey == "" {
 		sessionKey = fmt.Sprintf("skill-%s-%d", skillName, time.Now().UnixNano())
 	}
 
+	timeout := s.skillTimeout
+	if manifest != nil && manifest.MaxDuration > 0 {
+		timeout = time.Duration(manifest.MaxDuration) * time.Second
+	}
+
 	startTime := time.Now()
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Minute)
+	ctx, cancel := context.WithTimeout(reqctx.WithSessionKey(r.Context(), sessionKey), timeout)
 	defer cancel()
+	if manifest != nil && manifest.MaxIterations > 0 {
+		ctx = tools.WithMaxIterations(ctx, manifest.MaxIterations)
+	}
 
 	response, err := s.agentLoop.ProcessDirectWithChannel(ctx, prompt, sessionKey, "api", "api")
 	duration := time.Since(startTime).Milliseconds()
@@ -259,17 +610,57 @@ func (s *Server) handleSkillExecute(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// webhookDeliveryHeaders carry a sender's delivery ID, which stays the same
+// when the sender retries.
+var webhookDeliveryHeaders = []string{"Idempotency-Key", "X-GitHub-Delivery", "X-Shopify-Webhook-Id"}
+
+// defaultMaxWebhookBytes caps a webhook body when none is configured.
+const defaultMaxWebhookBytes = 1 << 20
+
+// webhookDeliveryID returns the idempotency key of a webhook delivery, or
+// "" if the sender did not provide one.
+func webhookDeliveryID(path string, header http.Header) string {
+	for _, name := range webhookDeliveryHeaders {
+		if id := header.Get(name); id != "" {
+			return path + ":" + id
+		}
+	}
+	return ""
+}
+
 func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
 	// Extract the webhook path (everything after /v1/webhooks/)
 	webhookPath := strings.TrimPrefix(r.URL.Path, "/v1/webhooks")
+	if path, ok := strings.CutSuffix(webhookPath, "/replay"); ok {
+		s.handleWebhookReplay(w, r, path)
+		return
+	}
 
+	r.Body = http.MaxBytesReader(w, r.Body, s.maxWebhookBytes)
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, "request_too_large", fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit))
+			return
+		}
 		writeError(w, http.StatusBadRequest, "invalid_request", "failed to read request body")
 		return
 	}
 	defer r.Body.Close()
 
+	var spec skills.WebhookSpec
+	if s.loader != nil {
+		spec, _ = s.loader.Webhook(webhookPath)
+	}
+	verified, err := verifyWebhook(spec, r.Header, body)
+	if err != nil {
+		slog.Warn("webhook rejected", "path", webhookPath, "error", err)
+		s.recordEvent("api", "warning", fmt.Sprintf("Webhook rejected: %s: %v", webhookPath, err))
+		writeError(w, http.StatusUnauthorized, "invalid_signature", "webhook signature verification failed")
+		return
+	}
+
 	// Parse body as JSON
 	var bodyMap map[string]interface{}
 	_ = json.Unmarshal(body, &bodyMap)
@@ -290,28 +681,160 @@ func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
 		Timestamp: time.Now().UnixMilli(),
 	}
 
+	// A synchronous delivery waits for the agent's reply to this message.
+	var reply chan string
+	if wantSync, _ := strconv.ParseBool(r.URL.Query().Get("sync")); spec.Sync || wantSync {
+		reply = make(chan string, 1)
+	}
+
 	// Publish to message bus as an inbound message so the agent processes it
-	eventJSON, _ := json.Marshal(event)
-	s.msgBus.PublishInbound(bus.InboundMessage{
+	published := s.msgBus.PublishInbound(bus.InboundMessage{
 		Channel:    "webhook",
 		SenderID:   "webhook",
 		ChatID:     webhookPath,
-		Content:    fmt.Sprintf("[Webhook received on %s]\n\n%s", webhookPath, string(eventJSON)),
+		Content:    webhookPrompt(event),
 		SessionKey: fmt.Sprintf("webhook-%s", webhookPath),
+		Metadata: map[string]string{
+			"request_id": reqctx.RequestID(r.Context()),
+			"verified":   strconv.FormatBool(verified),
+		},
+		IdempotencyKey: webhookDeliveryID(webhookPath, r.Header),
+		Reply:          reply,
 	})
 
+	if !published {
+		s.recordEvent("api", "info", fmt.Sprintf("Duplicate webhook ignored: %s", webhookPath))
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"received":  true,
+			"duplicate": true,
+			"path":      webhookPath,
+			"verified":  verified,
+		})
+		return
+	}
+
+	s.webhooks.Add(event)
 	s.recordEvent("api", "info", fmt.Sprintf("Webhook received: %s", webhookPath))
-	
+
+	if reply != nil {
+		s.awaitWebhookReply(w, r, webhookPath, reply)
+		return
+	}
+
 	writeJSON(w, http.StatusOK, map[string]interface{}{
 		"received": true,
 		"path":     webhookPath,
+		"verified": verified,
 	})
 }
 
+// awaitWebhookReply answers a synchronous delivery with the agent's reply
+// as plain text, once the agent has processed it.
+func (s *Server) awaitWebhookReply(w http.ResponseWriter, r *http.Request, webhookPath string, reply <-chan string) {
+	timer := time.NewTimer(s.webhookTimeout)
+	defer timer.Stop()
+
+	select {
+	case response := <-reply:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, response)
+	case <-timer.C:
+		msg := fmt.Sprintf("agent did not reply within %v", s.webhookTimeout)
+		s.recordEvent("api", "warning", fmt.Sprintf("Webhook %s: %s", webhookPath, msg))
+		writeError(w, http.StatusGatewayTimeout, "timeout", msg)
+	case <-r.Context().Done():
+	}
+}
+
+// exemptVerifiedWebhooks sends deliveries to webhook paths that declare a
+// signature to next without the API key check in authed: the provider
+// cannot send the key, and handleWebhook refuses the delivery unless its
+// signature verifies. Replays and unverified paths still need the key.
+func (s *Server) exemptVerifiedWebhooks(authed, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.isVerifiedWebhook(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		authed.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) isVerifiedWebhook(r *http.Request) bool {
+	path, ok := strings.CutPrefix(r.URL.Path, "/v1/webhooks/")
+	if !ok || r.Method != http.MethodPost || s.loader == nil || strings.HasSuffix(path, "/replay") {
+		return false
+	}
+	spec, ok := s.loader.Webhook(path)
+	return ok && spec.Signature != nil
+}
+
+// verifyWebhook checks a delivery against the signature spec requires.
+// Paths no skill declares with a signature are unverified: they accept any
+// POST and report verified false. An error means the delivery must be
+// refused.
+func verifyWebhook(spec skills.WebhookSpec, header http.Header, body []byte) (bool, error) {
+	if spec.Signature == nil {
+		return false, nil
+	}
+	if err := spec.Signature.Verify(header, body); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// webhookPrompt is the message the agent receives for a webhook event.
+func webhookPrompt(event WebhookEvent) string {
+	eventJSON, _ := json.Marshal(event)
+	return fmt.Sprintf("[Webhook received on %s]\n\n%s", event.Path, string(eventJSON))
+}
+
+// handleWebhookReplay re-runs a stored payload for webhookPath through the
+// agent and returns what it did. ?index=N picks an older payload (0 is the
+// most recent).
+func (s *Server) handleWebhookReplay(w http.ResponseWriter, r *http.Request, webhookPath string) {
+	index := 0
+	if v := r.URL.Query().Get("index"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "invalid_request", "index must be a non-negative integer")
+			return
+		}
+		index = n
+	}
+
+	event, ok := s.webhooks.Get(webhookPath, index)
+	if !ok {
+		writeError(w, http.StatusNotFound, "webhook_not_found", fmt.Sprintf("no stored payload for webhook '%s'", webhookPath))
+		return
+	}
+
+	sessionKey := fmt.Sprintf("webhook-%s", webhookPath)
+	startTime := time.Now()
+	ctx, cancel := context.WithTimeout(reqctx.WithSessionKey(r.Context(), sessionKey), s.requestTimeout)
+	defer cancel()
+
+	response, err := s.agentLoop.ProcessDirectWithChannel(ctx, webhookPrompt(event), sessionKey, "webhook", webhookPath)
+	result := WebhookReplayResponse{
+		Path:     webhookPath,
+		Event:    event,
+		Response: response,
+		Duration: time.Since(startTime).Milliseconds(),
+	}
+	if err != nil {
+		s.recordEvent("api", "error", fmt.Sprintf("Webhook replay failed: %s: %v", webhookPath, err))
+		result.Error = err.Error()
+		writeJSON(w, http.StatusOK, result)
+		return
+	}
+
+	s.recordEvent("api", "info", fmt.Sprintf("Webhook replayed: %s", webhookPath))
+	writeJSON(w, http.StatusOK, result)
+}
+
 func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 	startupInfo := s.agentLoop.GetStartupInfo()
-	toolsInfo := startupInfo["tools"].(map[string]interface{})
-	skillsInfo := startupInfo["skills"].(map[string]interface{})
 
 	allSkills := s.loader.ListSkills()
 	skillNames := make([]string, len(allSkills))
@@ -319,15 +842,8 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 		skillNames[i] = skill.Name
 	}
 
-	s.eventsMu.RLock()
-	recentEvents := make([]ActivityEvent, len(s.events))
-	copy(recentEvents, s.events)
-	s.eventsMu.RUnlock()
-
-	// Reverse events for display (newest first)
-	for i, j := 0, len(recentEvents)-1; i < j; i, j = i+1, j-1 {
-		recentEvents[i], recentEvents[j] = recentEvents[j], recentEvents[i]
-	}
+	// Newest first, for display
+	recentEvents := s.events.Recent()
 
 	swarmCount := 0
 	if manager := s.agentLoop.GetSwarmManager(); manager != nil {
@@ -335,10 +851,12 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 	}
 
 	modelName := "Default"
-	if m, ok := startupInfo["model"].(string); ok {
-		modelName = m
+	if startupInfo.Model != "" {
+		modelName = startupInfo.Model
 	}
 
+	activity := s.agentLoop.Activity()
+
 	var m runtime.MemStats
 	runtime.ReadMemStats(&m)
 	memUsage := fmt.Sprintf("%.2f MB", float64(m.Alloc)/1024/1024)
@@ -347,37 +865,123 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, StatusResponse{
 		Status:    "ok",
 		Version:   s.version,
+		Build:     s.config.Build.withDefaults(),
 		Uptime:    time.Since(s.startedAt).Round(time.Second).String(),
 		StartedAt: s.startedAt,
 		Agent: AgentStatus{
 			Model:       modelName,
-			ToolsLoaded: toolsInfo["count"].(int),
+			ToolsLoaded: startupInfo.Tools.Count,
+			Paused:      s.agentLoop.IsPaused(),
 		},
 		Skills: SkillsStatus{
-			Total:     skillsInfo["total"].(int),
-			Available: skillsInfo["available"].(int),
+			Total:     startupInfo.Skills.Total,
+			Available: startupInfo.Skills.Available,
 			Names:     skillNames,
 		},
 		ActiveAgents: swarmCount,
 		RecentEvents: recentEvents,
+		Activity: ActivityStatus{
+			InFlight:         activity.InFlight,
+			ByChannel:        activity.ByChannel,
+			OldestAgeSeconds: activity.OldestAge(time.Now()).Seconds(),
+			OldestSession:    activity.OldestSession,
+		},
+		Channels: s.agentLoop.GetChannelStatus(),
 		System: SystemStats{
 			MemoryUsage: memUsage,
 			Goroutines:  runtime.NumGoroutine(),
 			Threads:     numThreads,
 		},
+		Usage: providers.DefaultUsage.Snapshot(),
 	})
 }
 
+// handleListModels lists the agent's model and the configured models in
+// OpenAI's format, so SDKs that enumerate models first can connect.
+func (s *Server) handleListModels(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, ModelList{Object: "list", Data: s.models()})
+}
+
+// handleGetModel returns one of the listed models, as OpenAI's
+// GET /v1/models/{model} does. Model names may contain slashes.
+func (s *Server) handleGetModel(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("model")
+	for _, m := range s.models() {
+		if m.ID == id {
+			writeJSON(w, http.StatusOK, m)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "model_not_found", fmt.Sprintf("model '%s' not found", id))
+}
+
+// hasModel reports whether name is one of the listed models.
+func (s *Server) hasModel(name string) bool {
+	for _, m := range s.models() {
+		if m.ID == name {
+			return true
+		}
+	}
+	return false
+}
+
+// models returns the agent's model followed by the configured ones, without
+// duplicates.
+func (s *Server) models() []ModelObject {
+	names := append([]string{s.agentLoop.GetStartupInfo().Model}, s.config.Models...)
+
+	seen := make(map[string]bool, len(names))
+	models := []ModelObject{}
+	for _, name := range names {
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		models = append(models, ModelObject{
+			ID:      name,
+			Object:  "model",
+			Created: s.startedAt.Unix(),
+			OwnedBy: "rdxclaw",
+		})
+	}
+	return models
+}
+
+// handleSkillSchema returns a skill's declared input schema so the web UI
+// can render a form for it.
+func (s *Server) handleSkillSchema(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("skill")
+	for _, skill := range s.loader.ListSkills() {
+		if skill.Name != name {
+			continue
+		}
+		resp := SkillSchemaResponse{Name: skill.Name, Description: skill.Description}
+		if skill.Manifest != nil {
+			resp.InputSchema = skill.Manifest.Inputs
+		}
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+	writeError(w, http.StatusNotFound, "skill_not_found", fmt.Sprintf("skill '%s' not found", name))
+}
+
 func (s *Server) handleListSkills(w http.ResponseWriter, r *http.Request) {
 	allSkills := s.loader.ListSkills()
-	items := make([]SkillListItem, len(allSkills))
-	for i, skill := range allSkills {
-		items[i] = SkillListItem{
+	tag := r.URL.Query().Get("tag")
+	items := make([]SkillListItem, 0, len(allSkills))
+	for _, skill := range allSkills {
+		if tag != "" && !skill.HasTag(tag) {
+			continue
+		}
+		items = append(items, SkillListItem{
 			Name:         skill.Name,
 			Description:  skill.Description,
 			Source:       skill.Source,
 			Capabilities: skill.Capabilities,
-		}
+			Tags:         skill.Tags,
+			Author:       skill.Author,
+			Version:      skill.Version,
+		})
 	}
 
 	writeJSON(w, http.StatusOK, map[string]interface{}{
@@ -394,6 +998,14 @@ func (s *Server) handleListAgents(w http.ResponseWriter, r *http.Request) {
 	}
 
 	agents := manager.ListAgents()
+	if r.URL.Query().Get("tree") == "true" {
+		// Nest spawned agents under their parent; count stays the total
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"agents": manager.AgentTree(),
+			"count":  len(agents),
+		})
+		return
+	}
 	writeJSON(w, http.StatusOK, map[string]interface{}{
 		"agents": agents,
 		"count":  len(agents),
@@ -415,9 +1027,12 @@ func (s *Server) handleKillAgent(w http.ResponseWriter, r *http.Request) {
 
 	err := manager.KillAgent(id)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
+		switch {
+		case errors.Is(err, swarm.ErrAgentNotFound):
 			writeError(w, http.StatusNotFound, "agent_not_found", err.Error())
-		} else {
+		case errors.Is(err, swarm.ErrAgentNotRunning):
+			writeError(w, http.StatusConflict, "agent_not_running", err.Error())
+		default:
 			writeError(w, http.StatusBadRequest, "kill_failed", err.Error())
 		}
 		return
@@ -429,9 +1044,19 @@ func (s *Server) handleKillAgent(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
-	writeJSON(w, http.StatusOK, map[string]string{
-		"status": "ok",
+func (s *Server) handlePauseAgent(w http.ResponseWriter, r *http.Request) {
+	s.agentLoop.Pause()
+	s.recordEvent("agent", "warning", "Agent paused; inbound messages are queued")
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"paused": true,
+	})
+}
+
+func (s *Server) handleResumeAgent(w http.ResponseWriter, r *http.Request) {
+	s.agentLoop.Resume()
+	s.recordEvent("agent", "info", "Agent resumed")
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"paused": false,
 	})
 }
 
@@ -0,0 +1,661 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Sterlites/RDxClaw/pkg/agent"
+	"github.com/Sterlites/RDxClaw/pkg/bus"
+	"github.com/Sterlites/RDxClaw/pkg/config"
+	"github.com/Sterlites/RDxClaw/pkg/providers"
+	"github.com/Sterlites/RDxClaw/pkg/skills"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// echoProvider answers with the last message it was given.
+type echoProvider struct{}
+
+func (p *echoProvider) Chat(ctx context.Context, messages []providers.Message, tools []providers.ToolDefinition, model string, opts map[string]interface{}) (*providers.LLMResponse, error) {
+	return &providers.LLMResponse{Content: "handled: " + messages[len(messages)-1].Content}, nil
+}
+
+func (p *echoProvider) GetDefaultModel() string { return "mock-model" }
+
+func newTestServer(t *testing.T, provider providers.LLMProvider, cfg ServerConfig) *Server {
+	return newTestServerIn(t, t.TempDir(), provider, cfg)
+}
+
+func newTestServerIn(t *testing.T, workspace string, provider providers.LLMProvider, cfg ServerConfig) *Server {
+	agentCfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         workspace,
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+	msgBus := bus.NewMessageBus()
+	al := agent.NewAgentLoop(agentCfg, msgBus, provider)
+	return NewServer(al, msgBus, skills.NewSkillsLoader(workspace, "", ""), cfg)
+}
+
+// blockingProvider never answers; it returns once the request context ends.
+type blockingProvider struct{}
+
+func (p *blockingProvider) Chat(ctx context.Context, messages []providers.Message, tools []providers.ToolDefinition, model string, opts map[string]interface{}) (*providers.LLMResponse, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (p *blockingProvider) GetDefaultModel() string { return "mock-model" }
+
+func TestChatCompletionTimeout(t *testing.T) {
+	s := newTestServer(t, &blockingProvider{}, ServerConfig{ChatTimeout: 50 * time.Millisecond})
+
+	chat := func(ctx context.Context) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions",
+			strings.NewReader(`{"messages":[{"role":"user","content":"hi"}]}`)).WithContext(ctx)
+		w := httptest.NewRecorder()
+		s.handleChatCompletion(w, req)
+		return w
+	}
+
+	t.Run("deadline", func(t *testing.T) {
+		w := chat(context.Background())
+		require.Equal(t, http.StatusGatewayTimeout, w.Code)
+
+		var resp ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "timeout", resp.Error.Code)
+		assert.Equal(t, "request timed out after 50ms", resp.Error.Message)
+
+		events := s.events.Recent()
+		require.NotEmpty(t, events)
+		assert.Equal(t, "warning", events[0].Type)
+	})
+
+	t.Run("client cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		w := chat(ctx)
+		assert.Equal(t, statusClientClosedRequest, w.Code)
+	})
+}
+
+func TestRequestTimeouts(t *testing.T) {
+	s := newTestServer(t, &echoProvider{}, ServerConfig{})
+	assert.Equal(t, defaultRequestTimeout, s.chatTimeout)
+	assert.Equal(t, defaultRequestTimeout, s.skillTimeout)
+
+	s = newTestServer(t, &echoProvider{}, ServerConfig{
+		RequestTimeout: time.Minute,
+		SkillTimeout:   10 * time.Second,
+		ChatTimeout:    -time.Second,
+	})
+	assert.Equal(t, time.Minute, s.chatTimeout, "a negative override falls back to the global timeout")
+	assert.Equal(t, 10*time.Second, s.skillTimeout)
+	assert.Equal(t, time.Minute, s.requestTimeout)
+}
+
+func TestSkillExecuteTimeout(t *testing.T) {
+	workspace := t.TempDir()
+	skillDir := filepath.Join(workspace, "skills", "research")
+	require.NoError(t, os.MkdirAll(skillDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(skillDir, "SKILL.md"),
+		[]byte("---\nname: research\ndescription: deep research\n---\n\n# research\n"), 0644))
+
+	s := newTestServerIn(t, workspace, &blockingProvider{}, ServerConfig{
+		ChatTimeout:  time.Minute,
+		SkillTimeout: 50 * time.Millisecond,
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/skills/research/execute", strings.NewReader(`{"input":"go"}`))
+	req.SetPathValue("skill", "research")
+	w := httptest.NewRecorder()
+	start := time.Now()
+	s.handleSkillExecute(w, req)
+
+	assert.Less(t, time.Since(start), 5*time.Second, "the skill timeout, not the chat timeout, must apply")
+	var resp SkillExecuteResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Contains(t, resp.Error, "deadline exceeded")
+}
+
+func TestSkillSchemaAndInputs(t *testing.T) {
+	workspace := t.TempDir()
+	for name, manifest := range map[string]string{
+		"refund": `{"name":"refund","version":"1.0.0","description":"Refund an order","inputs":{
+			"type":"object",
+			"properties":{"order_id":{"type":"string"},"amount":{"type":"number"}},
+			"required":["order_id"]}}`,
+		"notes": "",
+	} {
+		skillDir := filepath.Join(workspace, "skills", name)
+		require.NoError(t, os.MkdirAll(skillDir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(skillDir, "SKILL.md"),
+			[]byte("---\nname: "+name+"\ndescription: "+name+" skill\n---\n\n# "+name+"\n"), 0644))
+		if manifest != "" {
+			require.NoError(t, os.WriteFile(filepath.Join(skillDir, "manifest.json"), []byte(manifest), 0644))
+		}
+	}
+	s := newTestServerIn(t, workspace, &echoProvider{}, ServerConfig{})
+
+	schema := func(name string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/skills/"+name+"/schema", nil)
+		req.SetPathValue("skill", name)
+		w := httptest.NewRecorder()
+		s.handleSkillSchema(w, req)
+		return w
+	}
+
+	t.Run("declared schema", func(t *testing.T) {
+		w := schema("refund")
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp SkillSchemaResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "refund", resp.Name)
+		assert.Equal(t, "object", resp.InputSchema["type"])
+		assert.Contains(t, resp.InputSchema["properties"], "order_id")
+	})
+
+	t.Run("free-form skill", func(t *testing.T) {
+		w := schema("notes")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"input_schema":null`)
+	})
+
+	t.Run("unknown skill", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, schema("missing").Code)
+	})
+
+	execute := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/skills/refund/execute", strings.NewReader(body))
+		req.SetPathValue("skill", "refund")
+		w := httptest.NewRecorder()
+		s.handleSkillExecute(w, req)
+		return w
+	}
+
+	t.Run("missing required input", func(t *testing.T) {
+		w := execute(`{"params":{"amount":5}}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "order_id")
+	})
+
+	t.Run("inputs reach the agent", func(t *testing.T) {
+		w := execute(`{"params":{"order_id":"A-1","amount":5}}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp SkillExecuteResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Contains(t, resp.Result, `"order_id": "A-1"`)
+	})
+}
+
+func TestListModels(t *testing.T) {
+	s := newTestServer(t, &echoProvider{}, ServerConfig{Models: []string{"fast-model", "test-model", ""}})
+
+	w := httptest.NewRecorder()
+	s.handleListModels(w, httptest.NewRequest(http.MethodGet, "/v1/models", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp ModelList
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "list", resp.Object)
+	require.Len(t, resp.Data, 2, "duplicate and empty names should be dropped")
+	assert.Equal(t, "test-model", resp.Data[0].ID)
+	assert.Equal(t, "fast-model", resp.Data[1].ID)
+	for _, m := range resp.Data {
+		assert.Equal(t, "model", m.Object)
+		assert.Equal(t, "rdxclaw", m.OwnedBy)
+		assert.NotZero(t, m.Created)
+	}
+
+	get := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/models/"+id, nil)
+		req.SetPathValue("model", id)
+		w := httptest.NewRecorder()
+		s.handleGetModel(w, req)
+		return w
+	}
+
+	w = get("fast-model")
+	require.Equal(t, http.StatusOK, w.Code)
+	var model ModelObject
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &model))
+	assert.Equal(t, "fast-model", model.ID)
+
+	assert.Equal(t, http.StatusNotFound, get("missing-model").Code)
+}
+
+// modelProvider records the model each call asks for.
+type modelProvider struct {
+	mu     sync.Mutex
+	models []string
+}
+
+func (p *modelProvider) Chat(ctx context.Context, messages []providers.Message, tools []providers.ToolDefinition, model string, opts map[string]interface{}) (*providers.LLMResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.models = append(p.models, model)
+	return &providers.LLMResponse{Content: "ok"}, nil
+}
+
+func (p *modelProvider) GetDefaultModel() string { return "mock-model" }
+
+func TestChatCompletionModel(t *testing.T) {
+	provider := &modelProvider{}
+	s := newTestServer(t, provider, ServerConfig{Models: []string{"fast-model"}})
+
+	chat := func(model string) *httptest.ResponseRecorder {
+		body := `{"model":"` + model + `","messages":[{"role":"user","content":"hi"}]}`
+		w := httptest.NewRecorder()
+		s.handleChatCompletion(w, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body)))
+		return w
+	}
+
+	require.Equal(t, http.StatusOK, chat("fast-model").Code)
+	require.Equal(t, http.StatusOK, chat("").Code)
+	assert.Equal(t, []string{"fast-model", "test-model"}, provider.models)
+
+	w := chat("missing-model")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	var resp ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "model_not_found", resp.Error.Code)
+	assert.Len(t, provider.models, 2, "an unknown model must not reach the provider")
+}
+
+// streamingProvider streams its reply word by word.
+type streamingProvider struct{}
+
+func (p *streamingProvider) Chat(ctx context.Context, messages []providers.Message, tools []providers.ToolDefinition, model string, opts map[string]interface{}) (*providers.LLMResponse, error) {
+	return &providers.LLMResponse{Content: "one two three"}, nil
+}
+
+func (p *streamingProvider) ChatStream(ctx context.Context, messages []providers.Message, tools []providers.ToolDefinition, model string, opts map[string]interface{}, onDelta func(string)) (*providers.LLMResponse, error) {
+	for _, word := range []string{"one", " two", " three"} {
+		onDelta(word)
+	}
+	return &providers.LLMResponse{Content: "one two three"}, nil
+}
+
+func (p *streamingProvider) GetDefaultModel() string { return "mock-model" }
+
+// narratingToolProvider streams some text along with a tool call before
+// streaming its answer.
+type narratingToolProvider struct{ toolCallingProvider }
+
+func (p *narratingToolProvider) ChatStream(ctx context.Context, messages []providers.Message, tools []providers.ToolDefinition, model string, opts map[string]interface{}, onDelta func(string)) (*providers.LLMResponse, error) {
+	resp, err := p.Chat(ctx, messages, tools, model, opts)
+	if len(resp.ToolCalls) > 0 {
+		onDelta("Let me look.")
+	} else {
+		onDelta(resp.Content)
+	}
+	return resp, err
+}
+
+// readChatStream returns the chunks of a streamed chat completion and
+// whether it ended with [DONE].
+func readChatStream(t *testing.T, body string) ([]ChatCompletionChunk, bool) {
+	t.Helper()
+	var chunks []ChatCompletionChunk
+	for _, event := range strings.Split(strings.TrimSpace(body), "\n\n") {
+		data, ok := strings.CutPrefix(event, "data: ")
+		require.True(t, ok, "event %q", event)
+		if data == "[DONE]" {
+			return chunks, true
+		}
+		var chunk ChatCompletionChunk
+		require.NoError(t, json.Unmarshal([]byte(data), &chunk))
+		chunks = append(chunks, chunk)
+	}
+	return chunks, false
+}
+
+func TestChatCompletionStream(t *testing.T) {
+	stream := func(t *testing.T, s *Server) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions",
+			strings.NewReader(`{"model":"test-model","stream":true,"messages":[{"role":"user","content":"hi"}]}`))
+		w := httptest.NewRecorder()
+		s.handleChatCompletion(w, req)
+		return w
+	}
+
+	t.Run("streaming provider", func(t *testing.T) {
+		w := stream(t, newTestServer(t, &streamingProvider{}, ServerConfig{}))
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+		assert.True(t, w.Flushed)
+
+		chunks, done := readChatStream(t, w.Body.String())
+		require.True(t, done, "stream must end with [DONE]")
+		require.Len(t, chunks, 4)
+		assert.Equal(t, ChatDelta{Role: "assistant", Content: "one"}, chunks[0].Choices[0].Delta)
+		assert.Equal(t, " two", chunks[1].Choices[0].Delta.Content)
+		assert.Equal(t, " three", chunks[2].Choices[0].Delta.Content)
+		assert.Nil(t, chunks[2].Choices[0].FinishReason)
+		require.NotNil(t, chunks[3].Choices[0].FinishReason)
+		assert.Equal(t, "stop", *chunks[3].Choices[0].FinishReason)
+		for _, c := range chunks {
+			assert.Equal(t, "chat.completion.chunk", c.Object)
+			assert.Equal(t, chunks[0].ID, c.ID)
+			assert.Equal(t, "test-model", c.Model)
+		}
+	})
+
+	t.Run("tool iterations are not streamed", func(t *testing.T) {
+		w := stream(t, newTestServer(t, &narratingToolProvider{}, ServerConfig{}))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		chunks, done := readChatStream(t, w.Body.String())
+		require.True(t, done)
+		require.Len(t, chunks, 2)
+		assert.Equal(t, "done", chunks[0].Choices[0].Delta.Content)
+		assert.Nil(t, chunks[1].Usage, "usage is only sent when asked for")
+	})
+
+	t.Run("usage chunk", func(t *testing.T) {
+		s := newTestServer(t, &narratingToolProvider{}, ServerConfig{})
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions",
+			strings.NewReader(`{"stream":true,"stream_options":{"include_usage":true},"messages":[{"role":"user","content":"hi"}]}`))
+		w := httptest.NewRecorder()
+		s.handleChatCompletion(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		chunks, done := readChatStream(t, w.Body.String())
+		require.True(t, done)
+		require.Len(t, chunks, 3)
+		require.NotNil(t, chunks[1].Choices[0].FinishReason)
+		last := chunks[2]
+		assert.Empty(t, last.Choices)
+		assert.Equal(t, chunks[0].ID, last.ID)
+		require.NotNil(t, last.Usage)
+		assert.Equal(t, ChatCompletionUsage{PromptTokens: 30, CompletionTokens: 8, TotalTokens: 38}, *last.Usage)
+	})
+
+	t.Run("provider without streaming", func(t *testing.T) {
+		w := stream(t, newTestServer(t, &echoProvider{}, ServerConfig{}))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		chunks, done := readChatStream(t, w.Body.String())
+		require.True(t, done)
+		require.Len(t, chunks, 2)
+		assert.Equal(t, "handled: hi", chunks[0].Choices[0].Delta.Content)
+	})
+
+	t.Run("failure before the first chunk", func(t *testing.T) {
+		w := stream(t, newTestServer(t, &blockingProvider{}, ServerConfig{ChatTimeout: 50 * time.Millisecond}))
+		require.Equal(t, http.StatusGatewayTimeout, w.Code)
+
+		var resp ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "timeout", resp.Error.Code)
+	})
+}
+
+// recordingProvider remembers the options of the last call.
+type recordingProvider struct {
+	opts map[string]interface{}
+}
+
+func (p *recordingProvider) Chat(ctx context.Context, messages []providers.Message, tools []providers.ToolDefinition, model string, opts map[string]interface{}) (*providers.LLMResponse, error) {
+	p.opts = opts
+	return &providers.LLMResponse{Content: "ok"}, nil
+}
+
+func (p *recordingProvider) GetDefaultModel() string { return "mock-model" }
+
+func TestChatCompletionSamplingOptions(t *testing.T) {
+	provider := &recordingProvider{}
+	s := newTestServer(t, provider, ServerConfig{})
+
+	chat := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		s.handleChatCompletion(w, req)
+		return w
+	}
+
+	w := chat(`{"messages":[{"role":"user","content":"hi"}]}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	defaults := provider.opts
+
+	w = chat(`{"messages":[{"role":"user","content":"hi"}],"temperature":1.5,"max_tokens":256}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, 1.5, provider.opts["temperature"])
+	assert.Equal(t, 256, provider.opts["max_tokens"])
+
+	// Zero is a temperature of its own, not "unset"
+	w = chat(`{"messages":[{"role":"user","content":"hi"}],"temperature":0}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 0.0, provider.opts["temperature"])
+	assert.Equal(t, defaults["max_tokens"], provider.opts["max_tokens"])
+	assert.NotEqual(t, 256, defaults["max_tokens"])
+
+	for _, body := range []string{
+		`{"messages":[{"role":"user","content":"hi"}],"temperature":2.5}`,
+		`{"messages":[{"role":"user","content":"hi"}],"temperature":-0.1}`,
+		`{"messages":[{"role":"user","content":"hi"}],"max_tokens":-1}`,
+	} {
+		w := chat(body)
+		require.Equal(t, http.StatusBadRequest, w.Code, body)
+		var resp ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "invalid_request", resp.Error.Code)
+	}
+}
+
+// toolCallingProvider asks for one tool call before answering, reporting
+// usage for both calls.
+type toolCallingProvider struct{}
+
+func (p *toolCallingProvider) Chat(ctx context.Context, messages []providers.Message, tools []providers.ToolDefinition, model string, opts map[string]interface{}) (*providers.LLMResponse, error) {
+	if messages[len(messages)-1].Role != "tool" {
+		return &providers.LLMResponse{
+			ToolCalls: []providers.ToolCall{{ID: "call_1", Name: "list_dir", Arguments: map[string]interface{}{"path": "."}}},
+			Usage:     &providers.UsageInfo{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+		}, nil
+	}
+	return &providers.LLMResponse{
+		Content: "done",
+		Usage:   &providers.UsageInfo{PromptTokens: 20, CompletionTokens: 3, TotalTokens: 23},
+	}, nil
+}
+
+func (p *toolCallingProvider) GetDefaultModel() string { return "mock-model" }
+
+func TestChatCompletionUsage(t *testing.T) {
+	s := newTestServer(t, &toolCallingProvider{}, ServerConfig{})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions",
+		strings.NewReader(`{"messages":[{"role":"user","content":"what is here?"}]}`))
+	w := httptest.NewRecorder()
+	s.handleChatCompletion(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp ChatCompletionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "done", resp.Choices[0].Message.Content)
+	require.NotNil(t, resp.Usage)
+	assert.Equal(t, ChatCompletionUsage{PromptTokens: 30, CompletionTokens: 8, TotalTokens: 38}, *resp.Usage)
+}
+
+// gatedProvider answers once release is closed, announcing each call on
+// started.
+type gatedProvider struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (p *gatedProvider) Chat(ctx context.Context, messages []providers.Message, tools []providers.ToolDefinition, model string, opts map[string]interface{}) (*providers.LLMResponse, error) {
+	p.started <- struct{}{}
+	<-p.release
+	return &providers.LLMResponse{Content: "finished"}, nil
+}
+
+func (p *gatedProvider) GetDefaultModel() string { return "mock-model" }
+
+func TestServerStopDrainsRequests(t *testing.T) {
+	provider := &gatedProvider{started: make(chan struct{}, 1), release: make(chan struct{})}
+	s := newTestServer(t, provider, ServerConfig{})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	served := make(chan error, 1)
+	go func() { served <- s.serve(ln) }()
+	url := "http://" + ln.Addr().String()
+
+	type result struct {
+		status int
+		body   string
+		err    error
+	}
+	chat := make(chan result, 1)
+	go func() {
+		resp, err := http.Post(url+"/v1/chat/completions", "application/json",
+			strings.NewReader(`{"messages":[{"role":"user","content":"hi"}]}`))
+		if err != nil {
+			chat <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		chat <- result{status: resp.StatusCode, body: string(body)}
+	}()
+	<-provider.started
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- s.Stop(context.Background()) }()
+
+	require.Eventually(t, func() bool {
+		ready, _ := s.Health().IsReady()
+		return !ready
+	}, time.Second, 10*time.Millisecond)
+	select {
+	case err := <-stopped:
+		t.Fatalf("Stop returned with a request in flight: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(provider.release)
+	res := <-chat
+	require.NoError(t, res.err)
+	assert.Equal(t, http.StatusOK, res.status)
+	assert.Contains(t, res.body, "finished")
+	require.NoError(t, <-stopped)
+	require.NoError(t, <-served)
+
+	_, err = http.Get(url + "/health")
+	assert.Error(t, err, "a stopped server should refuse connections")
+}
+
+func TestServerStopDelay(t *testing.T) {
+	s := newTestServer(t, &echoProvider{}, ServerConfig{
+		ChatTimeout:   time.Minute,
+		ShutdownDelay: 200 * time.Millisecond,
+	})
+	assert.Equal(t, 5*time.Minute+200*time.Millisecond, s.DrainTimeout(), "the longest request timeout plus the delay")
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	served := make(chan error, 1)
+	go func() { served <- s.serve(ln) }()
+	url := "http://" + ln.Addr().String()
+	require.Eventually(t, func() bool {
+		ready, _ := s.Health().IsReady()
+		return ready
+	}, time.Second, 10*time.Millisecond)
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- s.Stop(context.Background()) }()
+	require.Eventually(t, func() bool {
+		ready, _ := s.Health().IsReady()
+		return !ready
+	}, time.Second, 10*time.Millisecond)
+
+	// Still serving while load balancers notice /ready failing
+	resp, err := http.Get(url + "/ready")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	require.NoError(t, <-stopped)
+	require.NoError(t, <-served)
+}
+
+// erroringProvider fails every call with err.
+type erroringProvider struct {
+	err error
+}
+
+func (p *erroringProvider) Chat(ctx context.Context, messages []providers.Message, tools []providers.ToolDefinition, model string, opts map[string]interface{}) (*providers.LLMResponse, error) {
+	return nil, p.err
+}
+
+func (p *erroringProvider) GetDefaultModel() string { return "mock-model" }
+
+func TestChatCompletionProviderErrors(t *testing.T) {
+	tests := []struct {
+		kind   providers.ErrorKind
+		status int
+	}{
+		{providers.ErrorRateLimit, http.StatusTooManyRequests},
+		{providers.ErrorAuth, http.StatusBadGateway},
+		{providers.ErrorContentFiltered, http.StatusBadGateway},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.kind), func(t *testing.T) {
+			err := &providers.Error{Kind: tt.kind, Message: "upstream said no"}
+			s := newTestServer(t, &erroringProvider{err: err}, ServerConfig{})
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions",
+				strings.NewReader(`{"messages":[{"role":"user","content":"hi"}]}`))
+			w := httptest.NewRecorder()
+			s.handleChatCompletion(w, req)
+			require.Equal(t, tt.status, w.Code)
+
+			var resp ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, "provider_"+string(tt.kind), resp.Error.Code)
+		})
+	}
+}
+
+// cutOffProvider always stops at max_tokens.
+type cutOffProvider struct{}
+
+func (p *cutOffProvider) Chat(ctx context.Context, messages []providers.Message, tools []providers.ToolDefinition, model string, opts map[string]interface{}) (*providers.LLMResponse, error) {
+	return &providers.LLMResponse{Content: "The answer is", FinishReason: "length"}, nil
+}
+
+func (p *cutOffProvider) GetDefaultModel() string { return "mock-model" }
+
+func TestChatCompletionTruncated(t *testing.T) {
+	s := newTestServer(t, &cutOffProvider{}, ServerConfig{})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions",
+		strings.NewReader(`{"messages":[{"role":"user","content":"explain"}]}`))
+	w := httptest.NewRecorder()
+	s.handleChatCompletion(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp ChatCompletionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "The answer is", resp.Choices[0].Message.Content)
+	assert.Equal(t, "length", resp.Choices[0].FinishReason)
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Sterlites/RDxClaw/pkg/agent"
+	"github.com/Sterlites/RDxClaw/pkg/providers"
+)
+
+// streamChat answers a chat completion request with stream=true as
+// Server-Sent Events: chat.completion.chunk objects carrying the reply as
+// the agent produces it, then a "[DONE]" line. With
+// stream_options.include_usage, a chunk with the token usage of the turn
+// and no choices precedes "[DONE]". Failures before the first
+// chunk are answered like non-streaming ones, with an error status; later
+// ones end the stream with an error event.
+func (s *Server) streamChat(w http.ResponseWriter, r *http.Request, req ChatCompletionRequest) {
+	turn, failure := s.newChatTurn(req)
+	if failure != nil {
+		writeError(w, failure.status, failure.code, failure.message)
+		return
+	}
+
+	ctx, cancel := s.chatContext(r.Context(), turn)
+	defer cancel()
+	usage := providers.NewUsageTracker()
+	ctx = providers.WithUsageTracker(ctx, usage)
+	report := &agent.TurnReport{}
+	ctx = agent.WithTurnReport(ctx, report)
+
+	deltas := make(chan string)
+	done := make(chan error, 1)
+	go func() {
+		_, err := s.agentLoop.ProcessDirectStream(ctx, turn.content, turn.sessionKey, turn.channel, "api", deltas)
+		done <- err
+	}()
+
+	stream := &chatStream{
+		w:       w,
+		rc:      http.NewResponseController(w),
+		id:      fmt.Sprintf("chatcmpl-%d", time.Now().UnixNano()),
+		created: time.Now().Unix(),
+		model:   req.Model,
+	}
+	for delta := range deltas {
+		stream.send(ChatDelta{Content: delta}, nil)
+	}
+
+	if err := <-done; err != nil {
+		failure := s.chatError(ctx, err)
+		if !stream.started {
+			writeError(w, failure.status, failure.code, failure.message)
+			return
+		}
+		stream.event(ErrorResponse{Error: *failure.detail()})
+		stream.done()
+		return
+	}
+
+	s.recordEvent("agent", "info", "Processed user request")
+	reason := finishReason(report)
+	stream.send(ChatDelta{}, &reason)
+	if req.StreamOptions != nil && req.StreamOptions.IncludeUsage {
+		stream.event(stream.chunk(nil, chatUsage(usage.Total())))
+	}
+	stream.done()
+}
+
+// chatStream writes the events of one streamed chat completion.
+type chatStream struct {
+	w       http.ResponseWriter
+	rc      *http.ResponseController
+	id      string
+	created int64
+	model   string
+	started bool // whether the response headers were sent
+}
+
+// send writes a chunk; the first one also announces the assistant role.
+func (cs *chatStream) send(delta ChatDelta, finishReason *string) {
+	if !cs.started {
+		delta.Role = "assistant"
+	}
+	cs.event(cs.chunk([]ChatCompletionChunkChoice{{Delta: delta, FinishReason: finishReason}}, nil))
+}
+
+// chunk builds a chunk of this stream; the usage chunk has no choices.
+func (cs *chatStream) chunk(choices []ChatCompletionChunkChoice, usage *ChatCompletionUsage) ChatCompletionChunk {
+	if choices == nil {
+		choices = []ChatCompletionChunkChoice{}
+	}
+	return ChatCompletionChunk{
+		ID:      cs.id,
+		Object:  "chat.completion.chunk",
+		Created: cs.created,
+		Model:   cs.model,
+		Choices: choices,
+		Usage:   usage,
+	}
+}
+
+// event writes v as one data event and flushes it to the client.
+func (cs *chatStream) event(v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	cs.write("data: " + string(data) + "\n\n")
+}
+
+// done ends the stream.
+func (cs *chatStream) done() {
+	cs.write("data: [DONE]\n\n")
+}
+
+func (cs *chatStream) write(line string) {
+	if !cs.started {
+		h := cs.w.Header()
+		h.Set("Content-Type", "text/event-stream")
+		h.Set("Cache-Control", "no-cache")
+		h.Set("Connection", "keep-alive")
+		cs.w.WriteHeader(http.StatusOK)
+		cs.started = true
+	}
+	// A client that went away cancels the request context, which ends
+	// the turn; write errors need no handling of their own.
+	fmt.Fprint(cs.w, line)
+	cs.rc.Flush()
+}
@@ -1,18 +1,32 @@
 package api
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/Sterlites/RDxClaw/pkg/channels"
+	"github.com/Sterlites/RDxClaw/pkg/providers"
+)
 
 // --- OpenAI-Compatible Chat Completion Types ---
 
 // ChatCompletionRequest mirrors the OpenAI chat completion request format.
 type ChatCompletionRequest struct {
-	Model       string        `json:"model,omitempty"`
-	Messages    []ChatMessage `json:"messages"`
-	Stream      bool          `json:"stream,omitempty"`
-	MaxTokens   int           `json:"max_tokens,omitempty"`
-	Temperature *float64      `json:"temperature,omitempty"`
-	SessionKey  string        `json:"session_key,omitempty"` // RDxClaw extension
-	Channel     string        `json:"channel,omitempty"`     // RDxClaw extension
+	Model         string             `json:"model,omitempty"`
+	Messages      []ChatMessage      `json:"messages"`
+	Stream        bool               `json:"stream,omitempty"`
+	StreamOptions *ChatStreamOptions `json:"stream_options,omitempty"`
+	MaxTokens     int                `json:"max_tokens,omitempty"`
+	Temperature   *float64           `json:"temperature,omitempty"`
+	SessionKey    string             `json:"session_key,omitempty"`    // RDxClaw extension
+	Channel       string             `json:"channel,omitempty"`        // RDxClaw extension
+	MaxIterations int                `json:"max_iterations,omitempty"` // RDxClaw extension: tool loop cap, 1-100
+}
+
+// ChatStreamOptions mirrors OpenAI's stream_options. With IncludeUsage set,
+// a streamed reply ends with a chunk carrying the token usage of the turn.
+type ChatStreamOptions struct {
+	IncludeUsage bool `json:"include_usage,omitempty"`
 }
 
 // ChatMessage represents a single message in the chat.
@@ -38,6 +52,32 @@ type ChatCompletionChoice struct {
 	FinishReason string      `json:"finish_reason"`
 }
 
+// ChatCompletionChunk is one Server-Sent Event of a streamed chat
+// completion, mirroring OpenAI's chat.completion.chunk.
+type ChatCompletionChunk struct {
+	ID      string                      `json:"id"`
+	Object  string                      `json:"object"` // always "chat.completion.chunk"
+	Created int64                       `json:"created"`
+	Model   string                      `json:"model"`
+	Choices []ChatCompletionChunkChoice `json:"choices"`
+	Usage   *ChatCompletionUsage        `json:"usage,omitempty"` // only on the usage chunk, whose Choices is empty
+}
+
+// ChatCompletionChunkChoice carries the part of the reply a chunk adds.
+// FinishReason is only set on the last chunk.
+type ChatCompletionChunkChoice struct {
+	Index        int       `json:"index"`
+	Delta        ChatDelta `json:"delta"`
+	FinishReason *string   `json:"finish_reason"`
+}
+
+// ChatDelta is the content a chunk appends to the reply. Role is only set
+// on the first chunk.
+type ChatDelta struct {
+	Role    string `json:"role,omitempty"`
+	Content string `json:"content,omitempty"`
+}
+
 // ChatCompletionUsage tracks token usage.
 type ChatCompletionUsage struct {
 	PromptTokens     int `json:"prompt_tokens"`
@@ -45,6 +85,58 @@ type ChatCompletionUsage struct {
 	TotalTokens      int `json:"total_tokens"`
 }
 
+// BatchChatCompletionItem is the outcome of one request in a batch: either
+// Response or Error is set, and Status is the HTTP status the request would
+// have had on its own.
+type BatchChatCompletionItem struct {
+	Index    int                     `json:"index"`
+	Status   int                     `json:"status"`
+	Response *ChatCompletionResponse `json:"response,omitempty"`
+	Error    *ErrorDetail            `json:"error,omitempty"`
+}
+
+// ModelList mirrors OpenAI's GET /v1/models response.
+type ModelList struct {
+	Object string        `json:"object"` // always "list"
+	Data   []ModelObject `json:"data"`
+}
+
+// ModelObject is one entry of a ModelList.
+type ModelObject struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"` // always "model"
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+}
+
+// EmbeddingRequest mirrors OpenAI's embeddings request. Input is a string
+// or an array of strings.
+type EmbeddingRequest struct {
+	Model string          `json:"model"`
+	Input json.RawMessage `json:"input"`
+}
+
+// EmbeddingResponse mirrors OpenAI's embeddings response.
+type EmbeddingResponse struct {
+	Object string            `json:"object"` // always "list"
+	Data   []EmbeddingObject `json:"data"`
+	Model  string            `json:"model"`
+	Usage  EmbeddingUsage    `json:"usage"`
+}
+
+// EmbeddingObject is the vector for one input.
+type EmbeddingObject struct {
+	Object    string    `json:"object"` // always "embedding"
+	Index     int       `json:"index"`
+	Embedding []float64 `json:"embedding"`
+}
+
+// EmbeddingUsage tracks the tokens an embeddings request consumed.
+type EmbeddingUsage struct {
+	PromptTokens int `json:"prompt_tokens"`
+	TotalTokens  int `json:"total_tokens"`
+}
+
 // --- Skill Execution Types ---
 
 // SkillExecuteRequest triggers a skill by name with optional input.
@@ -73,20 +165,61 @@ type WebhookEvent struct {
 	Timestamp int64                  `json:"timestamp"`
 }
 
+// KnowledgeIngestResponse describes a file indexed into a collection.
+type KnowledgeIngestResponse struct {
+	Collection string `json:"collection"`
+	DocumentID string `json:"document_id"`
+	Title      string `json:"title"`
+	Type       string `json:"type"`
+	Chunks     int    `json:"chunks"`
+}
+
+// WebhookReplayResponse is the result of replaying a stored webhook payload.
+type WebhookReplayResponse struct {
+	Path     string       `json:"path"`
+	Event    WebhookEvent `json:"event"`
+	Response string       `json:"response"`
+	Duration int64        `json:"duration_ms"`
+	Error    string       `json:"error,omitempty"`
+}
+
 // --- Status Types ---
 
 // StatusResponse contains the server health and agent status.
 type StatusResponse struct {
-	Status       string          `json:"status"`
-	Version      string          `json:"version"`
-	Uptime       string          `json:"uptime"`
-	StartedAt    time.Time       `json:"started_at"`
-	Agent        AgentStatus     `json:"agent"`
-	Skills       SkillsStatus    `json:"skills"`
-	ActiveAgents int             `json:"active_agents"`
-	RecentEvents []ActivityEvent `json:"recent_events,omitempty"`
+	Status       string                 `json:"status"`
+	Version      string                 `json:"version"`
+	Uptime       string                 `json:"uptime"`
+	StartedAt    time.Time              `json:"started_at"`
+	Agent        AgentStatus            `json:"agent"`
+	Skills       SkillsStatus           `json:"skills"`
+	ActiveAgents int                    `json:"active_agents"`
+	RecentEvents []ActivityEvent        `json:"recent_events,omitempty"`
 	Cron         map[string]interface{} `json:"cron,omitempty"`
-	System       SystemStats     `json:"system"`
+	Activity     ActivityStatus         `json:"activity"`
+	// Channels holds the state and last error of each chat channel.
+	Channels map[string]channels.ChannelStatus `json:"channels,omitempty"`
+	System   SystemStats                       `json:"system"`
+	Build    BuildInfo                         `json:"build"`
+	// Usage holds the LLM usage of this process per model since it started.
+	Usage map[string]providers.ModelUsage `json:"usage"`
+}
+
+// BuildInfo identifies the running binary. main fills it from the values
+// stamped in by the linker.
+type BuildInfo struct {
+	Version   string `json:"version"`
+	GitCommit string `json:"git_commit,omitempty"`
+	BuildTime string `json:"build_time,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// ActivityStatus reports the agent turns currently being processed.
+type ActivityStatus struct {
+	InFlight         int            `json:"in_flight"`
+	ByChannel        map[string]int `json:"by_channel"`
+	OldestAgeSeconds float64        `json:"oldest_age_seconds"`
+	OldestSession    string         `json:"oldest_session,omitempty"`
 }
 
 // SystemStats contains Go runtime statistics.
@@ -108,6 +241,7 @@ type ActivityEvent struct {
 type AgentStatus struct {
 	Model       string `json:"model"`
 	ToolsLoaded int    `json:"tools_loaded"`
+	Paused      bool   `json:"paused"`
 }
 
 // SkillsStatus contains skills summary.
@@ -119,10 +253,22 @@ type SkillsStatus struct {
 
 // SkillListItem is used in the GET /v1/skills response.
 type SkillListItem struct {
-	Name         string `json:"name"`
-	Description  string `json:"description"`
-	Source       string `json:"source"`
-	Capabilities string `json:"capabilities,omitempty"`
+	Name         string   `json:"name"`
+	Description  string   `json:"description"`
+	Source       string   `json:"source"`
+	Capabilities string   `json:"capabilities,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	Author       string   `json:"author,omitempty"`
+	Version      string   `json:"version,omitempty"`
+}
+
+// SkillSchemaResponse is returned by GET /v1/skills/{skill}/schema.
+// InputSchema is the manifest's "inputs" JSON schema, or null when the
+// skill only takes free-form input.
+type SkillSchemaResponse struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"input_schema"`
 }
 
 // --- Error Types ---
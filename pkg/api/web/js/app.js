@@ -51,6 +51,15 @@ async function loadStatus() {
   document.getElementById('uptimeVal').innerText = data.uptime || 'N/A';
   document.getElementById('versionVal').innerText = data.version || 'v1.0.0';
   document.getElementById('sidebarVersion').innerText = `Framework ${data.version || 'v1.0.0'}`;
+  if (data.build) {
+    const build = [
+      data.build.git_commit && `Commit ${data.build.git_commit}`,
+      data.build.build_time && `Built ${data.build.build_time}`,
+      data.build.go_version,
+    ].filter(Boolean).join(' · ');
+    document.getElementById('versionVal').title = build;
+    document.getElementById('buildVal').innerText = build || '--';
+  }
   document.getElementById('modelVal').innerText = data.agent?.model || 'N/A';
   document.getElementById('agentsVal').innerText = data.active_agents || '0';
   document.getElementById('skillsVal').innerText = data.skills?.total || '0';
@@ -145,50 +154,161 @@ async function loadSkills() {
       <td><strong>${name}</strong><div class="skill-desc">${desc}</div></td>
       <td><span class="badge badge-info">${source}</span></td>
       <td>${caps}</td>
-      <td><button onclick="executeSkill('${name}')">Run Test</button></td>
+      <td><button onclick="openSkillForm('${name}')">Run</button></td>
     `;
     tbody.appendChild(tr);
   });
 }
 
-async function executeSkill(skillName) {
-  const input = prompt(`Enter test payload for ${skillName}:`, "Ping");
-  if (!input) return;
+// --- Skill runner ---
+let activeSkill = null;
 
-  const loader = document.getElementById('chatLoader');
-  if (loader) loader.classList.add('active');
+// openSkillForm renders a form from the skill's declared input schema.
+// Skills without one get a single free-form input field.
+async function openSkillForm(skillName) {
+  const data = await fetchJSON(`/skills/${encodeURIComponent(skillName)}/schema`);
+  if (!data) {
+    alert(`Could not load the input schema of ${skillName}.`);
+    return;
+  }
+  activeSkill = data;
+
+  document.getElementById('skillRunnerTitle').innerText = `Run ${data.name}`;
+  document.getElementById('skillRunnerDesc').innerText = data.description || '';
+  document.getElementById('skillResult').hidden = true;
+
+  const fields = document.getElementById('skillFormFields');
+  fields.innerHTML = '';
+  const schema = data.input_schema;
+  const required = new Set((schema && schema.required) || []);
+  Object.entries((schema && schema.properties) || {}).forEach(([name, prop]) => {
+    fields.appendChild(renderSkillField(name, prop, required.has(name)));
+  });
+  fields.appendChild(renderSkillField('input', {
+    type: 'string',
+    description: schema ? 'Additional instructions (optional)' : 'What should the skill do?',
+    multiline: true,
+  }, !schema, '__input'));
+
+  const runner = document.getElementById('skillRunner');
+  runner.hidden = false;
+  runner.scrollIntoView({ behavior: 'smooth' });
+}
 
+function renderSkillField(name, prop, required, id = `skill-input-${name}`) {
+  const wrap = document.createElement('div');
+  wrap.className = 'form-field';
+
+  const label = document.createElement('label');
+  label.htmlFor = id;
+  label.innerText = (prop.title || name) + (required ? ' *' : '');
+  wrap.appendChild(label);
+
+  let input;
+  if (Array.isArray(prop.enum)) {
+    input = document.createElement('select');
+    if (!required) input.appendChild(new Option('', ''));
+    prop.enum.forEach(v => input.appendChild(new Option(String(v), String(v))));
+  } else if (prop.type === 'boolean') {
+    input = document.createElement('input');
+    input.type = 'checkbox';
+  } else if (prop.type === 'number' || prop.type === 'integer') {
+    input = document.createElement('input');
+    input.type = 'number';
+    if (prop.type === 'integer') input.step = '1';
+  } else if (prop.type === 'string' && !prop.multiline) {
+    input = document.createElement('input');
+    input.type = 'text';
+  } else {
+    // Multi-line text, or JSON for arrays and objects
+    input = document.createElement('textarea');
+    input.rows = 3;
+    if (prop.type === 'array' || prop.type === 'object') input.placeholder = 'JSON';
+  }
+  input.id = id;
+  input.dataset.name = name;
+  input.dataset.type = prop.type;
+  if (required && prop.type !== 'boolean') input.required = true;
+  if (prop.default !== undefined) {
+    if (prop.type === 'boolean') input.checked = !!prop.default;
+    else input.value = typeof prop.default === 'object' ? JSON.stringify(prop.default) : prop.default;
+  }
+  wrap.appendChild(input);
+
+  if (prop.description) {
+    const desc = document.createElement('div');
+    desc.className = 'skill-desc';
+    desc.innerText = prop.description;
+    wrap.appendChild(desc);
+  }
+  return wrap;
+}
+
+// collectSkillParams reads the form back into typed params.
+function collectSkillParams() {
+  const params = {};
+  document.querySelectorAll('#skillFormFields [data-name]').forEach(el => {
+    if (el.id === '__input') return;
+    const type = el.dataset.type;
+    if (type === 'boolean') {
+      params[el.dataset.name] = el.checked;
+      return;
+    }
+    if (el.value === '') return;
+    if (type === 'number' || type === 'integer') params[el.dataset.name] = Number(el.value);
+    else if (type === 'array' || type === 'object') params[el.dataset.name] = JSON.parse(el.value);
+    else params[el.dataset.name] = el.value;
+  });
+  return params;
+}
+
+async function submitSkillForm(e) {
+  e.preventDefault();
+  if (!activeSkill) return;
+
+  const resultEl = document.getElementById('skillResult');
+  let params;
   try {
-    const res = await fetch(`${API_BASE}/skills/${skillName}/execute`, {
+    params = collectSkillParams();
+  } catch (err) {
+    resultEl.className = 'skill-result error';
+    resultEl.innerText = `Invalid JSON: ${err.message}`;
+    resultEl.hidden = false;
+    return;
+  }
+  const input = document.getElementById('__input').value;
+
+  const loader = document.getElementById('skillLoader');
+  loader.classList.add('active');
+  try {
+    const res = await fetch(`${API_BASE}/skills/${encodeURIComponent(activeSkill.name)}/execute`, {
       method: 'POST',
       headers: { 'Content-Type': 'application/json' },
-      body: JSON.stringify({ input: input })
+      body: JSON.stringify({ input: input, params: params })
     });
     const data = await res.json();
-    
-    // Use correct casing from Go JSON tags
-    const resultText = data.result || data.error || "Skill returned empty response";
-    
-    // Integrate with Chat Terminal for comprehensive visibility
-    chatMessages.push({ role: 'user', content: `[Manual Skill Test: ${skillName}]\nInput: ${input}` });
-    chatMessages.push({ role: 'assistant', content: resultText });
-    renderChat();
-
-    // Still show alert if user isn't looking at the chat
-    const activeSection = document.querySelector('.section.active').id;
-    if (activeSection !== 'chat') {
-      alert(`Skill Execution: ${skillName}\n\n${resultText}`);
-    }
-
+    const failed = !res.ok || data.error;
+    const message = data.error?.message || data.error || data.result || 'Skill returned empty response';
+    resultEl.className = failed ? 'skill-result error' : 'skill-result';
+    resultEl.innerText = data.duration_ms !== undefined ? `${message}\n\n(${data.duration_ms} ms)` : message;
   } catch (err) {
-    console.error("Skill execution error:", err);
-    alert("Skill execution failed. See console for details.");
+    console.error('Skill execution error:', err);
+    resultEl.className = 'skill-result error';
+    resultEl.innerText = 'Skill execution failed. See console for details.';
   } finally {
-    if (loader) loader.classList.remove('active');
+    resultEl.hidden = false;
+    loader.classList.remove('active');
     loadStatus(); // Instant refresh of activity feed
   }
 }
 
+function closeSkillForm() {
+  activeSkill = null;
+  document.getElementById('skillRunner').hidden = true;
+}
+
+document.getElementById('skillForm').addEventListener('submit', submitSkillForm);
+
 // --- Chat functionality ---
 let chatMessages = [
   { 
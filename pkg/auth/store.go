@@ -5,6 +5,8 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/Sterlites/RDxClaw/pkg/config"
 )
 
 type AuthCredential struct {
@@ -35,8 +37,7 @@ func (c *AuthCredential) NeedsRefresh() bool {
 }
 
 func authFilePath() string {
-	home, _ := os.UserHomeDir()
-	return filepath.Join(home, ".rdxclaw", "auth.json")
+	return filepath.Join(config.HomeDir(), "auth.json")
 }
 
 func LoadStore() (*AuthStore, error) {
@@ -3,6 +3,7 @@ package bus
 import (
 	"context"
 	"sync"
+	"time"
 )
 
 type MessageBus struct {
@@ -11,6 +12,7 @@ type MessageBus struct {
 	handlers map[string]MessageHandler
 	closed   bool
 	mu       sync.RWMutex
+	dedup    *dedupCache // nil when deduplication is disabled
 }
 
 func NewMessageBus() *MessageBus {
@@ -18,16 +20,36 @@ func NewMessageBus() *MessageBus {
 		inbound:  make(chan InboundMessage, 100),
 		outbound: make(chan OutboundMessage, 100),
 		handlers: make(map[string]MessageHandler),
+		dedup:    newDedupCache(DefaultDedupSize, DefaultDedupTTL),
 	}
 }
 
-func (mb *MessageBus) PublishInbound(msg InboundMessage) {
+// SetDedup configures how many idempotency keys are remembered and for how
+// long. A size or ttl <= 0 disables deduplication.
+func (mb *MessageBus) SetDedup(size int, ttl time.Duration) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+	if size <= 0 || ttl <= 0 {
+		mb.dedup = nil
+		return
+	}
+	mb.dedup = newDedupCache(size, ttl)
+}
+
+// PublishInbound queues msg for the agent. A message whose IdempotencyKey
+// was already published on the same channel within the dedup TTL is
+// dropped; PublishInbound then returns false.
+func (mb *MessageBus) PublishInbound(msg InboundMessage) bool {
 	mb.mu.RLock()
 	defer mb.mu.RUnlock()
 	if mb.closed {
-		return
+		return false
+	}
+	if msg.IdempotencyKey != "" && mb.dedup != nil && mb.dedup.duplicate(msg.Channel+"\x00"+msg.IdempotencyKey) {
+		return false
 	}
 	mb.inbound <- msg
+	return true
 }
 
 func (mb *MessageBus) ConsumeInbound(ctx context.Context) (InboundMessage, bool) {
@@ -0,0 +1,73 @@
+package bus
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPublishInboundDropsDuplicates(t *testing.T) {
+	mb := NewMessageBus()
+	msg := InboundMessage{Channel: "telegram", ChatID: "42", Content: "hello", IdempotencyKey: "42:1001"}
+
+	if !mb.PublishInbound(msg) {
+		t.Fatal("first delivery should be published")
+	}
+	if mb.PublishInbound(msg) {
+		t.Error("re-delivery with the same key should be dropped")
+	}
+
+	// The same key on another channel, and unkeyed messages, are not duplicates
+	other := msg
+	other.Channel = "discord"
+	if !mb.PublishInbound(other) {
+		t.Error("keys are scoped to their channel")
+	}
+	unkeyed := InboundMessage{Channel: "telegram", Content: "hello"}
+	if !mb.PublishInbound(unkeyed) || !mb.PublishInbound(unkeyed) {
+		t.Error("messages without a key are never deduplicated")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	delivered := 0
+	for {
+		if _, ok := mb.ConsumeInbound(ctx); !ok {
+			break
+		}
+		delivered++
+	}
+	if delivered != 4 {
+		t.Errorf("delivered %d messages, want 4", delivered)
+	}
+}
+
+func TestDedupCacheBounds(t *testing.T) {
+	c := newDedupCache(2, time.Minute)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	c.duplicate("a")
+	c.duplicate("b")
+	c.duplicate("c") // evicts a
+	if c.duplicate("a") {
+		t.Error("evicted key should not count as a duplicate")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if c.duplicate("a") {
+		t.Error("expired key should not count as a duplicate")
+	}
+	if !c.duplicate("a") {
+		t.Error("key seen again within the TTL should be a duplicate")
+	}
+}
+
+func TestSetDedupDisables(t *testing.T) {
+	mb := NewMessageBus()
+	mb.SetDedup(0, 0)
+	msg := InboundMessage{Channel: "webhook", IdempotencyKey: "delivery-1"}
+	if !mb.PublishInbound(msg) || !mb.PublishInbound(msg) {
+		t.Error("deduplication should be disabled")
+	}
+}
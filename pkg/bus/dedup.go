@@ -0,0 +1,66 @@
+package bus
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultDedupSize is how many idempotency keys the bus remembers.
+	DefaultDedupSize = 1024
+	// DefaultDedupTTL is how long a key is remembered.
+	DefaultDedupTTL = 10 * time.Minute
+)
+
+// dedupCache remembers recently seen idempotency keys: a bounded LRU whose
+// entries also expire after ttl.
+type dedupCache struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	order *list.List // front is most recently seen
+	seen  map[string]*list.Element
+	now   func() time.Time
+}
+
+type dedupEntry struct {
+	key    string
+	expiry time.Time
+}
+
+func newDedupCache(size int, ttl time.Duration) *dedupCache {
+	return &dedupCache{
+		size:  size,
+		ttl:   ttl,
+		order: list.New(),
+		seen:  make(map[string]*list.Element),
+		now:   time.Now,
+	}
+}
+
+// duplicate records key and reports whether it was already seen within the
+// TTL.
+func (c *dedupCache) duplicate(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if el, ok := c.seen[key]; ok {
+		entry := el.Value.(*dedupEntry)
+		if now.Before(entry.expiry) {
+			c.order.MoveToFront(el)
+			return true
+		}
+		c.order.Remove(el)
+		delete(c.seen, key)
+	}
+
+	c.seen[key] = c.order.PushFront(&dedupEntry{key: key, expiry: now.Add(c.ttl)})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.seen, oldest.Value.(*dedupEntry).key)
+	}
+	return false
+}
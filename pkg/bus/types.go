@@ -1,14 +1,35 @@
 package bus
 
 type InboundMessage struct {
-	Type       string            `json:"type,omitempty"` // "text", "event", "command", "webhook"
-	Channel    string            `json:"channel"`
-	SenderID   string            `json:"sender_id"`
-	ChatID     string            `json:"chat_id"`
-	Content    string            `json:"content"`
-	Media      []string          `json:"media,omitempty"`
-	SessionKey string            `json:"session_key"`
-	Metadata   map[string]string `json:"metadata,omitempty"`
+	Type        string            `json:"type,omitempty"` // "text", "event", "command", "webhook"
+	Channel     string            `json:"channel"`
+	SenderID    string            `json:"sender_id"`
+	ChatID      string            `json:"chat_id"`
+	Content     string            `json:"content"`
+	Media       []string          `json:"media,omitempty"`
+	Attachments []Attachment      `json:"attachments,omitempty"`
+	SessionKey  string            `json:"session_key"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+
+	// IdempotencyKey identifies the platform event, e.g. its message ID.
+	// Messages re-delivered with the same key on the same channel are
+	// dropped by the bus.
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+
+	// Reply, if set, receives the agent's response instead of the outbound
+	// queue, so a caller can wait for the answer to this message. It must
+	// be buffered; the agent never blocks on it.
+	Reply chan<- string `json:"-"`
+}
+
+// Attachment is a file received with an inbound message. The content is
+// read into memory by the channel because downloaded media is cleaned up
+// as soon as the channel handler returns.
+type Attachment struct {
+	Type     string `json:"type"` // "image" or "file"
+	Filename string `json:"filename,omitempty"`
+	MimeType string `json:"mime_type,omitempty"`
+	Data     []byte `json:"data,omitempty"`
 }
 
 type OutboundMessage struct {
@@ -0,0 +1,64 @@
+package channels
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/Sterlites/RDxClaw/pkg/bus"
+	"github.com/Sterlites/RDxClaw/pkg/logger"
+	"github.com/Sterlites/RDxClaw/pkg/utils"
+)
+
+// maxImageAttachmentSize is the largest image forwarded to the model.
+// Providers reject larger images (Anthropic caps them at 5MB).
+const maxImageAttachmentSize = 5 * 1024 * 1024
+
+// loadImageAttachment reads a downloaded image into an attachment.
+// It returns false if the file is not an image, is too large, or cannot be read.
+func loadImageAttachment(path, filename, contentType string) (bus.Attachment, bool) {
+	if path == "" {
+		return bus.Attachment{}, false
+	}
+	if filename == "" {
+		filename = filepath.Base(path)
+	}
+	if !utils.IsImageFile(filename, contentType) && !utils.IsImageFile(path, "") {
+		return bus.Attachment{}, false
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return bus.Attachment{}, false
+	}
+	if info.Size() > maxImageAttachmentSize {
+		logger.WarnCF("channels", "Image attachment too large, skipping", map[string]interface{}{
+			"file": filename,
+			"size": info.Size(),
+		})
+		return bus.Attachment{}, false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		logger.DebugCF("channels", "Failed to read image attachment", map[string]interface{}{
+			"file":  path,
+			"error": err.Error(),
+		})
+		return bus.Attachment{}, false
+	}
+
+	// Prefer the sniffed type: platforms often report generic content types
+	// such as application/octet-stream for photos.
+	mimeType := http.DetectContentType(data)
+	if mimeType == "application/octet-stream" && contentType != "" {
+		mimeType = contentType
+	}
+
+	return bus.Attachment{
+		Type:     "image",
+		Filename: filename,
+		MimeType: mimeType,
+		Data:     data,
+	}, true
+}
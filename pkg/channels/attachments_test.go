@@ -0,0 +1,67 @@
+package channels
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Sterlites/RDxClaw/pkg/bus"
+)
+
+// pngHeader is enough of a PNG file for content sniffing.
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestLoadImageAttachment(t *testing.T) {
+	dir := t.TempDir()
+
+	imgPath := filepath.Join(dir, "download.bin")
+	if err := os.WriteFile(imgPath, pngHeader, 0644); err != nil {
+		t.Fatal(err)
+	}
+	att, ok := loadImageAttachment(imgPath, "photo.png", "application/octet-stream")
+	if !ok {
+		t.Fatal("expected image attachment to be loaded")
+	}
+	if att.Type != "image" || att.MimeType != "image/png" || att.Filename != "photo.png" {
+		t.Errorf("unexpected attachment: %+v", att)
+	}
+	if len(att.Data) != len(pngHeader) {
+		t.Errorf("expected %d bytes, got %d", len(pngHeader), len(att.Data))
+	}
+
+	txtPath := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(txtPath, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := loadImageAttachment(txtPath, "notes.txt", "text/plain"); ok {
+		t.Error("text file should not be loaded as an image")
+	}
+
+	if _, ok := loadImageAttachment(filepath.Join(dir, "missing.png"), "", ""); ok {
+		t.Error("missing file should not be loaded")
+	}
+}
+
+func TestHandleMessageWithAttachments(t *testing.T) {
+	msgBus := bus.NewMessageBus()
+	ch := NewBaseChannel("telegram", nil, msgBus, nil)
+
+	attachments := []bus.Attachment{{Type: "image", Filename: "a.png", MimeType: "image/png", Data: pngHeader}}
+	ch.HandleMessageWithAttachments("user1", "chat1", "look", nil, attachments, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	msg, ok := msgBus.ConsumeInbound(ctx)
+	if !ok {
+		t.Fatal("expected inbound message")
+	}
+	if len(msg.Attachments) != 1 || msg.Attachments[0].Filename != "a.png" {
+		t.Errorf("attachments not carried on inbound message: %+v", msg.Attachments)
+	}
+	if msg.SessionKey != "telegram:chat1" {
+		t.Errorf("unexpected session key %q", msg.SessionKey)
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync/atomic"
 
 	"github.com/Sterlites/RDxClaw/pkg/bus"
+	"github.com/Sterlites/RDxClaw/pkg/logger"
 )
 
 type Channel interface {
@@ -20,9 +22,15 @@ type Channel interface {
 type BaseChannel struct {
 	config    interface{}
 	bus       *bus.MessageBus
-	running   bool
+	running   atomic.Bool
 	name      string
 	allowList []string
+	readiness Readiness
+	// watermarks, when set, drops messages redelivered after a reconnect.
+	watermarks *watermarkStore
+	// disconnects carries the error a connection dropped with; see
+	// reportDisconnect.
+	disconnects chan error
 }
 
 func NewBaseChannel(name string, config interface{}, bus *bus.MessageBus, allowList []string) *BaseChannel {
@@ -31,7 +39,8 @@ func NewBaseChannel(name string, config interface{}, bus *bus.MessageBus, allowL
 		bus:       bus,
 		name:      name,
 		allowList: allowList,
-		running:   false,
+
+		disconnects: make(chan error, 1),
 	}
 }
 
@@ -40,7 +49,7 @@ func (c *BaseChannel) Name() string {
 }
 
 func (c *BaseChannel) IsRunning() bool {
-	return c.running
+	return c.running.Load()
 }
 
 func (c *BaseChannel) IsAllowed(senderID string) bool {
@@ -83,26 +92,87 @@ func (c *BaseChannel) IsAllowed(senderID string) bool {
 }
 
 func (c *BaseChannel) HandleMessage(senderID, chatID, content string, media []string, metadata map[string]string) {
+	c.HandleMessageWithAttachments(senderID, chatID, content, media, nil, metadata)
+}
+
+// HandleMessageWithAttachments publishes an inbound message that carries
+// in-memory attachments such as images alongside the media paths.
+func (c *BaseChannel) HandleMessageWithAttachments(senderID, chatID, content string, media []string, attachments []bus.Attachment, metadata map[string]string) {
 	if !c.IsAllowed(senderID) {
 		return
 	}
 
+	if c.watermarks != nil && !c.watermarks.advance(c.name, chatID, nativeMessageID(metadata)) {
+		logger.DebugCF("channels", "Skipped message redelivered after reconnect", map[string]interface{}{
+			"channel":    c.name,
+			"chat_id":    chatID,
+			"message_id": nativeMessageID(metadata),
+		})
+		return
+	}
+
+	if c.rejectIfDegraded(chatID) {
+		return
+	}
+
 	// Build session key: channel:chatID
 	sessionKey := fmt.Sprintf("%s:%s", c.name, chatID)
 
 	msg := bus.InboundMessage{
-		Channel:    c.name,
-		SenderID:   senderID,
-		ChatID:     chatID,
-		Content:    content,
-		Media:      media,
-		SessionKey: sessionKey,
-		Metadata:   metadata,
+		Channel:     c.name,
+		SenderID:    senderID,
+		ChatID:      chatID,
+		Content:     content,
+		Media:       media,
+		Attachments: attachments,
+		SessionKey:  sessionKey,
+		Metadata:    metadata,
+
+		IdempotencyKey: idempotencyKey(chatID, metadata),
 	}
 
 	c.bus.PublishInbound(msg)
 }
 
+// idempotencyKey derives a key from the platform's native message ID so
+// the bus can drop re-deliveries. IDs are only unique per chat on some
+// platforms, so the chat ID is part of the key.
+func idempotencyKey(chatID string, metadata map[string]string) string {
+	id := nativeMessageID(metadata)
+	if id == "" {
+		return ""
+	}
+	return chatID + ":" + id
+}
+
+// nativeMessageID returns the platform's ID for an inbound message, if the
+// channel recorded one.
+func nativeMessageID(metadata map[string]string) string {
+	if id := metadata["message_id"]; id != "" {
+		return id
+	}
+	return metadata["message_ts"] // Slack
+}
+
 func (c *BaseChannel) setRunning(running bool) {
-	c.running = running
+	c.running.Store(running)
+}
+
+// Disconnects reports connections that dropped while the channel was
+// running, so the manager can restart it.
+func (c *BaseChannel) Disconnects() <-chan error {
+	return c.disconnects
+}
+
+// reportDisconnect tells the manager the platform connection ended on its
+// own. It is ignored once Stop has run, and reports made before the
+// manager reacts collapse into one.
+func (c *BaseChannel) reportDisconnect(err error) {
+	if !c.IsRunning() || c.disconnects == nil {
+		return
+	}
+	select {
+	case c.disconnects <- err:
+	default:
+	}
 }
@@ -50,3 +50,15 @@ func TestBaseChannelIsAllowed(t *testing.T) {
 		})
 	}
 }
+
+func TestIdempotencyKey(t *testing.T) {
+	if got := idempotencyKey("chat-1", map[string]string{"message_id": "7"}); got != "chat-1:7" {
+		t.Errorf("got %q", got)
+	}
+	if got := idempotencyKey("C1", map[string]string{"message_ts": "1700000000.000100"}); got != "C1:1700000000.000100" {
+		t.Errorf("got %q", got)
+	}
+	if got := idempotencyKey("chat-1", nil); got != "" {
+		t.Errorf("messages without a platform ID should have no key, got %q", got)
+	}
+}
@@ -26,6 +26,9 @@ type DiscordChannel struct {
 	config      config.DiscordConfig
 	transcriber *voice.GroqTranscriber
 	ctx         context.Context
+
+	// removeHandlers unregisters the session handlers added by Start.
+	removeHandlers []func()
 }
 
 func NewDiscordChannel(cfg config.DiscordConfig, bus *bus.MessageBus) (*DiscordChannel, error) {
@@ -57,12 +60,24 @@ func (c *DiscordChannel) getContext() context.Context {
 }
 
 func (c *DiscordChannel) Start(ctx context.Context) error {
+	if c.IsRunning() {
+		return nil
+	}
 	logger.InfoC("discord", "Starting Discord bot")
 
 	c.ctx = ctx
-	c.session.AddHandler(c.handleMessage)
+	// The channel manager reconnects, with backoff and status reporting
+	c.session.ShouldReconnectOnError = false
+	c.clearHandlers()
+	c.removeHandlers = append(c.removeHandlers,
+		c.session.AddHandler(c.handleMessage),
+		c.session.AddHandler(func(_ *discordgo.Session, _ *discordgo.Disconnect) {
+			c.reportDisconnect(fmt.Errorf("discord gateway connection closed"))
+		}),
+	)
 
 	if err := c.session.Open(); err != nil {
+		c.clearHandlers()
 		return fmt.Errorf("failed to open discord session: %w", err)
 	}
 
@@ -83,6 +98,7 @@ func (c *DiscordChannel) Start(ctx context.Context) error {
 func (c *DiscordChannel) Stop(ctx context.Context) error {
 	logger.InfoC("discord", "Stopping Discord bot")
 	c.setRunning(false)
+	c.clearHandlers()
 
 	if err := c.session.Close(); err != nil {
 		return fmt.Errorf("failed to close discord session: %w", err)
@@ -91,6 +107,15 @@ func (c *DiscordChannel) Stop(ctx context.Context) error {
 	return nil
 }
 
+// clearHandlers removes the handlers of the previous run, so a restart does
+// not handle every message twice.
+func (c *DiscordChannel) clearHandlers() {
+	for _, remove := range c.removeHandlers {
+		remove()
+	}
+	c.removeHandlers = nil
+}
+
 func (c *DiscordChannel) Send(ctx context.Context, msg bus.OutboundMessage) error {
 	if !c.IsRunning() {
 		return fmt.Errorf("discord bot not running")
@@ -305,6 +330,7 @@ func (c *DiscordChannel) handleMessage(s *discordgo.Session, m *discordgo.Messag
 	content := m.Content
 	mediaPaths := make([]string, 0, len(m.Attachments))
 	localFiles := make([]string, 0, len(m.Attachments))
+	var attachments []bus.Attachment
 
 	// 确保临时文件在函数返回时被清理
 	defer func() {
@@ -356,6 +382,16 @@ func (c *DiscordChannel) handleMessage(s *discordgo.Session, m *discordgo.Messag
 				mediaPaths = append(mediaPaths, attachment.URL)
 				content = appendContent(content, fmt.Sprintf("[attachment: %s]", attachment.URL))
 			}
+		} else if utils.IsImageFile(attachment.Filename, attachment.ContentType) {
+			mediaPaths = append(mediaPaths, attachment.URL)
+			localPath := c.downloadAttachment(attachment.URL, attachment.Filename)
+			if localPath != "" {
+				localFiles = append(localFiles, localPath)
+				if att, ok := loadImageAttachment(localPath, attachment.Filename, attachment.ContentType); ok {
+					attachments = append(attachments, att)
+				}
+			}
+			content = appendContent(content, fmt.Sprintf("[image: %s]", attachment.Filename))
 		} else {
 			mediaPaths = append(mediaPaths, attachment.URL)
 			content = appendContent(content, fmt.Sprintf("[attachment: %s]", attachment.URL))
@@ -386,7 +422,7 @@ func (c *DiscordChannel) handleMessage(s *discordgo.Session, m *discordgo.Messag
 		"is_dm":        fmt.Sprintf("%t", m.GuildID == ""),
 	}
 
-	c.HandleMessage(senderID, m.ChannelID, content, mediaPaths, metadata)
+	c.HandleMessageWithAttachments(senderID, m.ChannelID, content, mediaPaths, attachments, metadata)
 }
 
 func (c *DiscordChannel) downloadAttachment(url, filename string) string {
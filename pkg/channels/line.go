@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
 	"strings"
@@ -69,6 +70,9 @@ func NewLINEChannel(cfg config.LINEConfig, messageBus *bus.MessageBus) (*LINECha
 
 // Start launches the HTTP webhook server.
 func (c *LINEChannel) Start(ctx context.Context) error {
+	if c.IsRunning() {
+		return nil
+	}
 	logger.InfoC("line", "Starting LINE channel (Webhook Mode)")
 
 	c.ctx, c.cancel = context.WithCancel(ctx)
@@ -99,17 +103,24 @@ func (c *LINEChannel) Start(ctx context.Context) error {
 		Handler: mux,
 	}
 
-	go func() {
+	// Bind now so a port in use fails the start rather than the run
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	go func(server *http.Server) {
 		logger.InfoCF("line", "LINE webhook server listening", map[string]interface{}{
 			"addr": addr,
 			"path": path,
 		})
-		if err := c.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := server.Serve(ln); err != nil && err != http.ErrServerClosed {
 			logger.ErrorCF("line", "Webhook server error", map[string]interface{}{
 				"error": err.Error(),
 			})
+			c.reportDisconnect(err)
 		}
-	}()
+	}(c.httpServer)
 
 	c.setRunning(true)
 	logger.InfoC("line", "LINE channel started (Webhook Mode)")
@@ -578,6 +589,14 @@ func (c *LINEChannel) callAPI(ctx context.Context, endpoint string, payload inte
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusTooManyRequests {
+		respBody, _ := io.ReadAll(resp.Body)
+		return &RetryAfterError{
+			After: parseRetryAfterHeader(resp.Header.Get("Retry-After")),
+			Err:   fmt.Errorf("LINE API error (status %d): %s", resp.StatusCode, string(respBody)),
+		}
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
 		return fmt.Errorf("LINE API error (status %d): %s", resp.StatusCode, string(respBody))
@@ -10,6 +10,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/Sterlites/RDxClaw/pkg/bus"
 	"github.com/Sterlites/RDxClaw/pkg/config"
@@ -22,7 +23,20 @@ type Manager struct {
 	bus          *bus.MessageBus
 	config       *config.Config
 	dispatchTask *asyncTask
+	limiters     map[string]*sendLimiter
+	readiness    Readiness
 	mu           sync.RWMutex
+	status       map[string]*ChannelStatus
+	statusMu     sync.Mutex
+	onEvent      func(ChannelEvent)
+	watermarks   *watermarkStore // nil when not filtering redeliveries
+	supervisors  sync.WaitGroup
+
+	// Zero values mean the defaults in reconnect.go; tests shorten them.
+	reconnectMin  time.Duration
+	reconnectMax  time.Duration
+	checkInterval time.Duration
+	chatIdle      time.Duration // see chatQueueIdle
 }
 
 type asyncTask struct {
@@ -31,15 +45,26 @@ type asyncTask struct {
 
 func NewManager(cfg *config.Config, messageBus *bus.MessageBus) (*Manager, error) {
 	m := &Manager{
-		channels: make(map[string]Channel),
-		bus:      messageBus,
-		config:   cfg,
+		channels:   make(map[string]Channel),
+		limiters:   make(map[string]*sendLimiter),
+		bus:        messageBus,
+		config:     cfg,
+		watermarks: newWatermarkStore(watermarkPath(cfg.WorkspacePath())),
 	}
 
 	if err := m.initChannels(); err != nil {
 		return nil, err
 	}
 
+	// Replace whatever a previous run left behind, even when no channel
+	// reports anything this time.
+	m.statusMu.Lock()
+	if m.status == nil {
+		m.status = make(map[string]*ChannelStatus)
+	}
+	m.saveChannelStatusLocked()
+	m.statusMu.Unlock()
+
 	return m, nil
 }
 
@@ -53,6 +78,7 @@ func (m *Manager) initChannels() error {
 			logger.ErrorCF("channels", "Failed to initialize Telegram channel", map[string]interface{}{
 				"error": err.Error(),
 			})
+			m.setChannelState("telegram", "", StateFailed, err)
 		} else {
 			m.channels["telegram"] = telegram
 			logger.InfoC("channels", "Telegram channel enabled successfully")
@@ -66,6 +92,7 @@ func (m *Manager) initChannels() error {
 			logger.ErrorCF("channels", "Failed to initialize WhatsApp channel", map[string]interface{}{
 				"error": err.Error(),
 			})
+			m.setChannelState("whatsapp", "", StateFailed, err)
 		} else {
 			m.channels["whatsapp"] = whatsapp
 			logger.InfoC("channels", "WhatsApp channel enabled successfully")
@@ -79,6 +106,7 @@ func (m *Manager) initChannels() error {
 			logger.ErrorCF("channels", "Failed to initialize Discord channel", map[string]interface{}{
 				"error": err.Error(),
 			})
+			m.setChannelState("discord", "", StateFailed, err)
 		} else {
 			m.channels["discord"] = discord
 			logger.InfoC("channels", "Discord channel enabled successfully")
@@ -92,6 +120,7 @@ func (m *Manager) initChannels() error {
 			logger.ErrorCF("channels", "Failed to initialize Slack channel", map[string]interface{}{
 				"error": err.Error(),
 			})
+			m.setChannelState("slack", "", StateFailed, err)
 		} else {
 			m.channels["slack"] = slackCh
 			logger.InfoC("channels", "Slack channel enabled successfully")
@@ -105,6 +134,7 @@ func (m *Manager) initChannels() error {
 			logger.ErrorCF("channels", "Failed to initialize LINE channel", map[string]interface{}{
 				"error": err.Error(),
 			})
+			m.setChannelState("line", "", StateFailed, err)
 		} else {
 			m.channels["line"] = line
 			logger.InfoC("channels", "LINE channel enabled successfully")
@@ -138,12 +168,26 @@ func (m *Manager) StartAll(ctx context.Context) error {
 		logger.InfoCF("channels", "Starting channel", map[string]interface{}{
 			"channel": name,
 		})
-		if err := channel.Start(ctx); err != nil {
+		if m.watermarks != nil {
+			setChannelWatermarks(channel, m.watermarks)
+			m.watermarks.beginRun(name)
+		}
+
+		// Each run of a channel gets its own context so that a restart
+		// does not leave the previous run's goroutines behind.
+		runCtx, stopRun := context.WithCancel(dispatchCtx)
+		err := channel.Start(runCtx)
+		if err != nil {
+			stopRun()
 			logger.ErrorCF("channels", "Failed to start channel", map[string]interface{}{
 				"channel": name,
 				"error":   err.Error(),
 			})
 		}
+		m.setChannelState(name, StateConnected, StateFailed, err)
+
+		m.supervisors.Add(1)
+		go m.supervise(dispatchCtx, name, channel, stopRun, err)
 	}
 
 	logger.InfoC("channels", "All channels started")
@@ -161,6 +205,18 @@ func (m *Manager) StopAll(ctx context.Context) error {
 		m.dispatchTask = nil
 	}
 
+	// Wait for the supervisors so none restarts a channel stopped below.
+	supervisorsDone := make(chan struct{})
+	go func() {
+		m.supervisors.Wait()
+		close(supervisorsDone)
+	}()
+	select {
+	case <-supervisorsDone:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
 	for name, channel := range m.channels {
 		logger.InfoCF("channels", "Stopping channel", map[string]interface{}{
 			"channel": name,
@@ -171,6 +227,10 @@ func (m *Manager) StopAll(ctx context.Context) error {
 				"error":   err.Error(),
 			})
 		}
+		m.setChannelState(name, StateStopped, "", nil)
+	}
+	if m.watermarks != nil {
+		m.watermarks.flush()
 	}
 
 	logger.InfoC("channels", "All channels stopped")
@@ -180,6 +240,10 @@ func (m *Manager) StopAll(ctx context.Context) error {
 func (m *Manager) dispatchOutbound(ctx context.Context) {
 	logger.InfoC("channels", "Outbound dispatcher started")
 
+	// Each channel gets its own queue so that a throttled platform does not
+	// hold back messages destined for the others.
+	queues := make(map[string]chan bus.OutboundMessage)
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -196,6 +260,15 @@ func (m *Manager) dispatchOutbound(ctx context.Context) {
 				continue
 			}
 
+			// The agent's "nothing to say" reply, e.g. relayed by a tool
+			if config.IsSilentReply(msg.Content, m.config.Agents.Defaults.SilentReplyToken) {
+				logger.DebugCF("channels", "Suppressed silent reply", map[string]interface{}{
+					"channel": msg.Channel,
+					"chat_id": msg.ChatID,
+				})
+				continue
+			}
+
 			m.mu.RLock()
 			channel, exists := m.channels[msg.Channel]
 			m.mu.RUnlock()
@@ -207,13 +280,144 @@ func (m *Manager) dispatchOutbound(ctx context.Context) {
 				continue
 			}
 
-			if err := channel.Send(ctx, msg); err != nil {
+			queue, ok := queues[msg.Channel]
+			if !ok {
+				queue = make(chan bus.OutboundMessage, 100)
+				queues[msg.Channel] = queue
+				go m.sendLoop(ctx, channel, queue)
+			}
+
+			select {
+			case queue <- msg:
+			case <-ctx.Done():
+			}
+		}
+	}
+}
+
+// chatQueueIdle is how long a chat's send worker waits for another message
+// before it exits.
+const chatQueueIdle = time.Minute
+
+// sendLoop drains the outbound queue of a single channel. Each chat gets a
+// worker of its own, so a chat held back by its rate limit or by a slow
+// send does not delay the others; messages to one chat keep their order.
+func (m *Manager) sendLoop(ctx context.Context, channel Channel, queue <-chan bus.OutboundMessage) {
+	chats := make(map[string]chan bus.OutboundMessage)
+	idle := make(chan string)
+	idleAfter := m.chatIdle
+	if idleAfter <= 0 {
+		idleAfter = chatQueueIdle
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case chatID := <-idle:
+			// Nothing else writes to the chat's queue while we are here, so
+			// a message that raced the worker's exit is still picked up.
+			if len(chats[chatID]) > 0 {
+				go m.chatSendLoop(ctx, channel, chatID, chats[chatID], idle, idleAfter)
+			} else {
+				delete(chats, chatID)
+			}
+		case msg := <-queue:
+			chat, ok := chats[msg.ChatID]
+			if !ok {
+				chat = make(chan bus.OutboundMessage, 100)
+				chats[msg.ChatID] = chat
+				go m.chatSendLoop(ctx, channel, msg.ChatID, chat, idle, idleAfter)
+			}
+			select {
+			case chat <- msg:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// chatSendLoop sends the messages queued for one chat, respecting the
+// channel's rate limit. After idleAfter without messages it reports the
+// chat on idle and exits.
+func (m *Manager) chatSendLoop(ctx context.Context, channel Channel, chatID string, queue <-chan bus.OutboundMessage, idle chan<- string, idleAfter time.Duration) {
+	timer := time.NewTimer(idleAfter)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-queue:
+			if err := m.sendLimited(ctx, channel, msg); err != nil {
 				logger.ErrorCF("channels", "Error sending message to channel", map[string]interface{}{
 					"channel": msg.Channel,
 					"error":   err.Error(),
 				})
 			}
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(idleAfter)
+		case <-timer.C:
+			select {
+			case idle <- chatID:
+			case <-ctx.Done():
+			}
+			return
+		}
+	}
+}
+
+// limiterFor returns the send limiter for the named channel, creating it on
+// first use. Channels without documented limits are not throttled.
+func (m *Manager) limiterFor(name string) *sendLimiter {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if l, ok := m.limiters[name]; ok {
+		return l
+	}
+
+	var l *sendLimiter
+	if limit, ok := defaultRateLimits[name]; ok {
+		l = newSendLimiter(limit)
+	}
+	m.limiters[name] = l
+	return l
+}
+
+// sendLimited sends msg through channel once its rate limit allows it. When
+// the platform answers with a Retry-After, the whole channel is paused for
+// that long and the message is retried. The outcome updates the channel's
+// status.
+func (m *Manager) sendLimited(ctx context.Context, channel Channel, msg bus.OutboundMessage) error {
+	limiter := m.limiterFor(channel.Name())
+
+	for attempt := 0; ; attempt++ {
+		if limiter != nil {
+			if err := limiter.Wait(ctx, msg.ChatID); err != nil {
+				return err
+			}
+		}
+
+		err := channel.Send(ctx, msg)
+		delay, limited := retryAfter(err)
+		if !limited || limiter == nil || attempt >= maxSendRetries {
+			m.recordSend(ctx, channel.Name(), err)
+			return err
 		}
+
+		logger.WarnCF("channels", "Rate limited by platform, pausing channel", map[string]interface{}{
+			"channel":     channel.Name(),
+			"retry_after": delay.String(),
+			"attempt":     attempt + 1,
+		})
+		limiter.Pause(delay)
 	}
 }
 
@@ -228,11 +432,18 @@ func (m *Manager) GetStatus() map[string]interface{} {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
+	degraded := false
+	if m.readiness != nil {
+		ready, _ := m.readiness.IsReady()
+		degraded = !ready
+	}
+
 	status := make(map[string]interface{})
 	for name, channel := range m.channels {
 		status[name] = map[string]interface{}{
-			"enabled": true,
-			"running": channel.IsRunning(),
+			"enabled":  true,
+			"running":  channel.IsRunning(),
+			"degraded": degraded,
 		}
 	}
 	return status
@@ -252,6 +463,9 @@ func (m *Manager) GetEnabledChannels() []string {
 func (m *Manager) RegisterChannel(name string, channel Channel) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	if m.readiness != nil {
+		setChannelReadiness(channel, m.readiness)
+	}
 	m.channels[name] = channel
 }
 
@@ -276,5 +490,5 @@ func (m *Manager) SendToChannel(ctx context.Context, channelName, chatID, conten
 		Content: content,
 	}
 
-	return channel.Send(ctx, msg)
+	return m.sendLimited(ctx, channel, msg)
 }
@@ -0,0 +1,230 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/mymmrac/telego/telegoapi"
+	"github.com/slack-go/slack"
+)
+
+// RateLimit describes the outbound send limits of a platform.
+// Rates are expressed in messages per second; a zero rate disables
+// the corresponding bucket.
+type RateLimit struct {
+	Rate         float64 // messages per second across the whole channel
+	Burst        int
+	PerChatRate  float64 // messages per second to a single chat
+	PerChatBurst int
+}
+
+// defaultRateLimits holds the documented send limits of each platform.
+//   - Telegram: ~30 msgs/sec per bot, 20 msgs/min per group.
+//   - Discord: 50 requests/sec global, 5 msgs per 5 seconds per channel.
+//   - Slack: chat.postMessage allows roughly 1 msg/sec per channel.
+//   - LINE: push API allows 2000 requests/sec.
+var defaultRateLimits = map[string]RateLimit{
+	"telegram": {Rate: 30, Burst: 30, PerChatRate: 20.0 / 60.0, PerChatBurst: 20},
+	"discord":  {Rate: 50, Burst: 50, PerChatRate: 1, PerChatBurst: 5},
+	"slack":    {PerChatRate: 1, PerChatBurst: 3},
+	"line":     {Rate: 2000, Burst: 2000},
+}
+
+// maxSendRetries bounds how many times a message is re-sent after the
+// platform answers with a Retry-After.
+const maxSendRetries = 3
+
+// RetryAfterError is returned by a channel when the platform rejected a send
+// because of rate limiting. The manager pauses the channel queue for After
+// before trying again.
+type RetryAfterError struct {
+	After time.Duration
+	Err   error
+}
+
+func (e *RetryAfterError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("rate limited, retry after %s: %v", e.After, e.Err)
+	}
+	return fmt.Sprintf("rate limited, retry after %s", e.After)
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// retryAfter extracts the platform-provided retry delay from a send error.
+func retryAfter(err error) (time.Duration, bool) {
+	if err == nil {
+		return 0, false
+	}
+
+	var raErr *RetryAfterError
+	if errors.As(err, &raErr) {
+		return raErr.After, true
+	}
+
+	var slackErr *slack.RateLimitedError
+	if errors.As(err, &slackErr) {
+		return slackErr.RetryAfter, true
+	}
+
+	var tgErr *telegoapi.Error
+	if errors.As(err, &tgErr) && tgErr.Parameters != nil && tgErr.Parameters.RetryAfter > 0 {
+		return time.Duration(tgErr.Parameters.RetryAfter) * time.Second, true
+	}
+
+	var dgErr *discordgo.RateLimitError
+	if errors.As(err, &dgErr) && dgErr.RateLimit != nil && dgErr.TooManyRequests != nil {
+		return dgErr.RetryAfter, true
+	}
+
+	return 0, false
+}
+
+// parseRetryAfterHeader parses an HTTP Retry-After header given in seconds
+// or as an HTTP date. It falls back to one second when the header is absent.
+func parseRetryAfterHeader(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if secs, err := strconv.Atoi(value); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return time.Second
+}
+
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int, now time.Time) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: now}
+}
+
+// delay refills the bucket and reports how long to wait for the next token.
+func (b *tokenBucket) delay(now time.Time) time.Duration {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+	}
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+func (b *tokenBucket) take() {
+	b.tokens--
+}
+
+// sendLimiter throttles outbound messages of a single channel.
+type sendLimiter struct {
+	limit       RateLimit
+	global      *tokenBucket
+	chats       map[string]*tokenBucket
+	pausedUntil time.Time
+	now         func() time.Time
+	mu          sync.Mutex
+}
+
+func newSendLimiter(limit RateLimit) *sendLimiter {
+	l := &sendLimiter{
+		limit: limit,
+		chats: make(map[string]*tokenBucket),
+		now:   time.Now,
+	}
+	if limit.Rate > 0 {
+		l.global = newTokenBucket(limit.Rate, limit.Burst, l.now())
+	}
+	return l
+}
+
+// reserve takes a send slot for chatID if one is available, otherwise it
+// returns how long the caller should wait before asking again.
+func (l *sendLimiter) reserve(chatID string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if wait := l.pausedUntil.Sub(now); wait > 0 {
+		return wait
+	}
+
+	var wait time.Duration
+	if l.global != nil {
+		wait = l.global.delay(now)
+	}
+
+	var chat *tokenBucket
+	if l.limit.PerChatRate > 0 {
+		chat = l.chats[chatID]
+		if chat == nil {
+			chat = newTokenBucket(l.limit.PerChatRate, l.limit.PerChatBurst, now)
+			l.chats[chatID] = chat
+		}
+		if d := chat.delay(now); d > wait {
+			wait = d
+		}
+	}
+
+	if wait > 0 {
+		return wait
+	}
+
+	if l.global != nil {
+		l.global.take()
+	}
+	if chat != nil {
+		chat.take()
+	}
+	return 0
+}
+
+// Wait blocks until a message may be sent to chatID or ctx is done.
+func (l *sendLimiter) Wait(ctx context.Context, chatID string) error {
+	for {
+		wait := l.reserve(chatID)
+		if wait <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Pause holds back every send on this channel for d, as requested by the
+// platform through Retry-After.
+func (l *sendLimiter) Pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if until := l.now().Add(d); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+}
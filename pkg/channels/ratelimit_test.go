@@ -0,0 +1,218 @@
+package channels
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mymmrac/telego/telegoapi"
+	"github.com/slack-go/slack"
+
+	"github.com/Sterlites/RDxClaw/pkg/bus"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func newTestLimiter(limit RateLimit, clock *fakeClock) *sendLimiter {
+	l := newSendLimiter(limit)
+	l.now = clock.Now
+	if l.global != nil {
+		l.global.last = clock.now
+	}
+	return l
+}
+
+func TestSendLimiterPerChatBurst(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	l := newTestLimiter(RateLimit{PerChatRate: 1, PerChatBurst: 2}, clock)
+
+	if wait := l.reserve("a"); wait != 0 {
+		t.Fatalf("first send should pass, got wait %v", wait)
+	}
+	if wait := l.reserve("a"); wait != 0 {
+		t.Fatalf("second send within burst should pass, got wait %v", wait)
+	}
+	if wait := l.reserve("a"); wait <= 0 {
+		t.Fatal("third send should be throttled")
+	}
+	if wait := l.reserve("b"); wait != 0 {
+		t.Fatalf("other chat should not be throttled, got wait %v", wait)
+	}
+
+	clock.now = clock.now.Add(time.Second)
+	if wait := l.reserve("a"); wait != 0 {
+		t.Fatalf("send after refill should pass, got wait %v", wait)
+	}
+}
+
+func TestSendLimiterGlobalRate(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	l := newTestLimiter(RateLimit{Rate: 2, Burst: 1}, clock)
+
+	if wait := l.reserve("a"); wait != 0 {
+		t.Fatalf("first send should pass, got wait %v", wait)
+	}
+	wait := l.reserve("b")
+	if wait != 500*time.Millisecond {
+		t.Fatalf("expected 500ms wait across chats, got %v", wait)
+	}
+}
+
+func TestSendLimiterPause(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	l := newTestLimiter(RateLimit{Rate: 100, Burst: 100}, clock)
+
+	l.Pause(3 * time.Second)
+	if wait := l.reserve("a"); wait != 3*time.Second {
+		t.Fatalf("expected paused wait of 3s, got %v", wait)
+	}
+
+	clock.now = clock.now.Add(3 * time.Second)
+	if wait := l.reserve("a"); wait != 0 {
+		t.Fatalf("send after pause should pass, got wait %v", wait)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want time.Duration
+		ok   bool
+	}{
+		{name: "nil", err: nil},
+		{name: "plain error", err: fmt.Errorf("boom")},
+		{
+			name: "retry after error",
+			err:  fmt.Errorf("wrapped: %w", &RetryAfterError{After: 2 * time.Second}),
+			want: 2 * time.Second,
+			ok:   true,
+		},
+		{
+			name: "slack",
+			err:  fmt.Errorf("failed to send slack message: %w", &slack.RateLimitedError{RetryAfter: 5 * time.Second}),
+			want: 5 * time.Second,
+			ok:   true,
+		},
+		{
+			name: "telegram",
+			err:  &telegoapi.Error{ErrorCode: 429, Parameters: &telegoapi.ResponseParameters{RetryAfter: 7}},
+			want: 7 * time.Second,
+			ok:   true,
+		},
+		{
+			name: "telegram without retry",
+			err:  &telegoapi.Error{ErrorCode: 400},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := retryAfter(tt.err)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("retryAfter() = (%v, %v), want (%v, %v)", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+type flakyChannel struct {
+	*BaseChannel
+	failures int
+	sent     []bus.OutboundMessage
+}
+
+func (c *flakyChannel) Start(ctx context.Context) error { return nil }
+func (c *flakyChannel) Stop(ctx context.Context) error  { return nil }
+
+func (c *flakyChannel) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	if c.failures > 0 {
+		c.failures--
+		return &RetryAfterError{After: time.Millisecond}
+	}
+	c.sent = append(c.sent, msg)
+	return nil
+}
+
+func TestManagerSendRetriesAfterRateLimit(t *testing.T) {
+	ch := &flakyChannel{BaseChannel: NewBaseChannel("slack", nil, nil, nil), failures: 1}
+	m := &Manager{
+		channels: map[string]Channel{"slack": ch},
+		limiters: make(map[string]*sendLimiter),
+	}
+
+	if err := m.SendToChannel(context.Background(), "slack", "C1", "hello"); err != nil {
+		t.Fatalf("SendToChannel() error = %v", err)
+	}
+	if len(ch.sent) != 1 || ch.sent[0].Content != "hello" {
+		t.Fatalf("expected message to be delivered after retry, got %+v", ch.sent)
+	}
+}
+
+// gatedChannel blocks sends to the chat "slow" until release is closed.
+type gatedChannel struct {
+	*BaseChannel
+	release chan struct{}
+	sent    chan bus.OutboundMessage
+}
+
+func (c *gatedChannel) Start(ctx context.Context) error { return nil }
+func (c *gatedChannel) Stop(ctx context.Context) error  { return nil }
+
+func (c *gatedChannel) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	if msg.ChatID == "slow" {
+		<-c.release
+	}
+	c.sent <- msg
+	return nil
+}
+
+func TestManagerSendLoopPerChat(t *testing.T) {
+	ch := &gatedChannel{
+		BaseChannel: NewBaseChannel("test", nil, nil, nil),
+		release:     make(chan struct{}),
+		sent:        make(chan bus.OutboundMessage, 10),
+	}
+	m := &Manager{
+		channels: map[string]Channel{"test": ch},
+		limiters: make(map[string]*sendLimiter),
+		chatIdle: 20 * time.Millisecond,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	queue := make(chan bus.OutboundMessage, 10)
+	go m.sendLoop(ctx, ch, queue)
+
+	received := func() string {
+		select {
+		case msg := <-ch.sent:
+			return msg.Content
+		case <-time.After(2 * time.Second):
+			t.Fatal("message was not sent")
+			return ""
+		}
+	}
+
+	queue <- bus.OutboundMessage{Channel: "test", ChatID: "slow", Content: "stuck"}
+	queue <- bus.OutboundMessage{Channel: "test", ChatID: "fast", Content: "first"}
+	if got := received(); got != "first" {
+		t.Fatalf("a blocked chat held back another: got %q", got)
+	}
+
+	// The fast chat's worker exits when idle and a new one takes over.
+	time.Sleep(5 * m.chatIdle)
+	queue <- bus.OutboundMessage{Channel: "test", ChatID: "fast", Content: "second"}
+	if got := received(); got != "second" {
+		t.Fatalf("got %q, want second", got)
+	}
+
+	close(ch.release)
+	if got := received(); got != "stuck" {
+		t.Fatalf("got %q, want stuck", got)
+	}
+}
@@ -0,0 +1,62 @@
+package channels
+
+import (
+	"github.com/Sterlites/RDxClaw/pkg/bus"
+	"github.com/Sterlites/RDxClaw/pkg/logger"
+)
+
+// Readiness reports whether the agent can currently answer messages, for
+// example because its LLM provider is reachable. health.Handler implements it.
+type Readiness interface {
+	IsReady() (ready bool, reason string)
+}
+
+// degradedReply is sent instead of forwarding a message to the agent while
+// the agent is not ready, so users are not left without an answer.
+const degradedReply = "⚠️ I can't reach my language model right now, so I can't answer. Please try again in a few minutes."
+
+// SetReadiness makes channels consult r before handing messages to the
+// agent. Call it before StartAll.
+func (m *Manager) SetReadiness(r Readiness) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.readiness = r
+	for _, channel := range m.channels {
+		setChannelReadiness(channel, r)
+	}
+}
+
+func setChannelReadiness(channel Channel, r Readiness) {
+	if rc, ok := channel.(interface{ setReadiness(Readiness) }); ok {
+		rc.setReadiness(r)
+	}
+}
+
+func (c *BaseChannel) setReadiness(r Readiness) {
+	c.readiness = r
+}
+
+// rejectIfDegraded answers the chat with degradedReply and reports true when
+// the agent is not ready to process messages.
+func (c *BaseChannel) rejectIfDegraded(chatID string) bool {
+	if c.readiness == nil {
+		return false
+	}
+	ready, reason := c.readiness.IsReady()
+	if ready {
+		return false
+	}
+
+	logger.WarnCF("channels", "Agent not ready, replying with degraded notice", map[string]interface{}{
+		"channel": c.name,
+		"chat_id": chatID,
+		"reason":  reason,
+	})
+	c.bus.PublishOutbound(bus.OutboundMessage{
+		Channel: c.name,
+		ChatID:  chatID,
+		Content: degradedReply,
+	})
+	return true
+}
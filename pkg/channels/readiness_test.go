@@ -0,0 +1,56 @@
+package channels
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Sterlites/RDxClaw/pkg/bus"
+)
+
+type stubReadiness struct {
+	ready bool
+}
+
+func (r *stubReadiness) IsReady() (bool, string) {
+	if r.ready {
+		return true, ""
+	}
+	return false, "provider: unreachable"
+}
+
+func TestBaseChannelDegradedReply(t *testing.T) {
+	msgBus := bus.NewMessageBus()
+	readiness := &stubReadiness{}
+
+	m := &Manager{channels: map[string]Channel{}, limiters: make(map[string]*sendLimiter)}
+	ch := &flakyChannel{BaseChannel: NewBaseChannel("telegram", nil, msgBus, nil)}
+	m.RegisterChannel("telegram", ch)
+	m.SetReadiness(readiness)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	ch.HandleMessage("user", "chat-1", "hello", nil, nil)
+
+	out, ok := msgBus.SubscribeOutbound(ctx)
+	if !ok {
+		t.Fatal("expected a degraded reply to be published")
+	}
+	if out.ChatID != "chat-1" || out.Content != degradedReply {
+		t.Errorf("unexpected reply %+v", out)
+	}
+
+	status := m.GetStatus()["telegram"].(map[string]interface{})
+	if status["degraded"] != true {
+		t.Errorf("expected channel status to report degraded, got %v", status)
+	}
+
+	readiness.ready = true
+	ch.HandleMessage("user", "chat-1", "hello again", nil, nil)
+
+	in, ok := msgBus.ConsumeInbound(ctx)
+	if !ok || in.Content != "hello again" {
+		t.Fatalf("expected message to reach the agent once ready, got %+v", in)
+	}
+}
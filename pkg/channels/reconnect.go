@@ -0,0 +1,226 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Sterlites/RDxClaw/pkg/logger"
+)
+
+const (
+	// Delays between attempts to restart a channel double from
+	// reconnectMinDelay up to reconnectMaxDelay; attempts never stop.
+	reconnectMinDelay = time.Second
+	reconnectMaxDelay = 5 * time.Minute
+
+	// connectionCheckInterval is how often a running channel is checked for
+	// a dropped connection.
+	connectionCheckInterval = 5 * time.Second
+)
+
+// errConnectionLost is recorded when a running channel stops on its own
+// without saying why.
+var errConnectionLost = errors.New("connection lost")
+
+// disconnectReporter is implemented by channels that report a dropped
+// connection, which BaseChannel does through reportDisconnect.
+type disconnectReporter interface {
+	Disconnects() <-chan error
+}
+
+// Channel events passed to the handler set with SetEventHandler.
+const (
+	EventDisconnected = "disconnected"
+	EventReconnected  = "reconnected"
+)
+
+// ChannelEvent reports a change in a channel's connection.
+type ChannelEvent struct {
+	Channel   string
+	Type      string // EventDisconnected or EventReconnected
+	Attempts  int    // restart attempts it took to reconnect
+	Error     string // why the channel went down
+	Timestamp time.Time
+}
+
+// SetEventHandler registers fn to be called when a channel loses its
+// connection or comes back. fn must not block.
+func (m *Manager) SetEventHandler(fn func(ChannelEvent)) {
+	m.statusMu.Lock()
+	defer m.statusMu.Unlock()
+	m.onEvent = fn
+}
+
+func (m *Manager) emitEvent(event ChannelEvent) {
+	m.statusMu.Lock()
+	fn := m.onEvent
+	m.statusMu.Unlock()
+
+	if fn != nil {
+		event.Timestamp = time.Now()
+		fn(event)
+	}
+}
+
+// reconnectDelay returns how long to wait before restart attempt n (1-based).
+func (m *Manager) reconnectDelay(attempt int) time.Duration {
+	delay, max := m.reconnectMin, m.reconnectMax
+	if delay <= 0 {
+		delay = reconnectMinDelay
+	}
+	if max <= 0 {
+		max = reconnectMaxDelay
+	}
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
+
+// supervise keeps a channel running until ctx ends. It restarts the channel
+// with exponential backoff when its first start failed (startErr) or when
+// it later stops on its own, e.g. after a dropped connection. stopRun ends
+// the context the channel is currently running with.
+func (m *Manager) supervise(ctx context.Context, name string, channel Channel, stopRun context.CancelFunc, startErr error) {
+	defer m.supervisors.Done()
+
+	err := startErr
+	everConnected := err == nil
+	attempt := 0
+	for {
+		if err == nil {
+			if attempt > 0 {
+				m.markReconnected(name, attempt)
+			}
+			attempt = 0
+
+			lost, dropErr := m.waitForDisconnect(ctx, channel)
+			if !lost {
+				stopRun()
+				return
+			}
+			stopRun()
+			err = dropErr
+			logger.WarnCF("channels", "Channel disconnected, reconnecting", map[string]interface{}{
+				"channel": name,
+				"error":   err.Error(),
+			})
+			m.emitEvent(ChannelEvent{Channel: name, Type: EventDisconnected, Error: err.Error()})
+			// Release whatever the dropped connection still holds.
+			if stopErr := channel.Stop(ctx); stopErr != nil {
+				logger.DebugCF("channels", "Error stopping disconnected channel", map[string]interface{}{
+					"channel": name,
+					"error":   stopErr.Error(),
+				})
+			}
+		}
+
+		attempt++
+		delay := m.reconnectDelay(attempt)
+		m.markRetrying(name, everConnected, attempt, delay, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		if m.watermarks != nil {
+			m.watermarks.beginRun(name)
+		}
+		drainDisconnects(channel)
+		runCtx, cancelRun := context.WithCancel(ctx)
+		if err = channel.Start(runCtx); err != nil {
+			cancelRun()
+			logger.WarnCF("channels", "Failed to restart channel", map[string]interface{}{
+				"channel": name,
+				"attempt": attempt,
+				"error":   err.Error(),
+			})
+			continue
+		}
+		stopRun = cancelRun
+		everConnected = true
+	}
+}
+
+// waitForDisconnect blocks until channel reports a dropped connection or
+// stops running, reporting true and the cause, or ctx ends, reporting
+// false.
+func (m *Manager) waitForDisconnect(ctx context.Context, channel Channel) (bool, error) {
+	interval := m.checkInterval
+	if interval <= 0 {
+		interval = connectionCheckInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var dropped <-chan error
+	if r, ok := channel.(disconnectReporter); ok {
+		dropped = r.Disconnects()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false, nil
+		case err := <-dropped:
+			if err == nil {
+				err = errConnectionLost
+			}
+			return true, err
+		case <-ticker.C:
+			if !channel.IsRunning() {
+				return true, errConnectionLost
+			}
+		}
+	}
+}
+
+// drainDisconnects discards a drop reported by the run being replaced, so
+// it is not taken for a drop of the next one.
+func drainDisconnects(channel Channel) {
+	r, ok := channel.(disconnectReporter)
+	if !ok {
+		return
+	}
+	select {
+	case <-r.Disconnects():
+	default:
+	}
+}
+
+func (m *Manager) markRetrying(name string, everConnected bool, attempt int, delay time.Duration, err error) {
+	state := StateFailed
+	if everConnected {
+		state = StateReconnecting
+	}
+	m.updateChannelStatus(name, func(st *ChannelStatus, now time.Time) bool {
+		next := now.Add(delay)
+		st.State = state
+		st.LastError = err.Error()
+		st.LastErrorAt = &now
+		st.RetryAttempt = attempt
+		st.NextRetryAt = &next
+		return true
+	})
+}
+
+func (m *Manager) markReconnected(name string, attempts int) {
+	logger.InfoCF("channels", "Channel reconnected", map[string]interface{}{
+		"channel":  name,
+		"attempts": attempts,
+	})
+	m.updateChannelStatus(name, func(st *ChannelStatus, now time.Time) bool {
+		st.State = StateConnected
+		st.RetryAttempt = 0
+		st.NextRetryAt = nil
+		st.Reconnects++
+		return true
+	})
+	m.emitEvent(ChannelEvent{Channel: name, Type: EventReconnected, Attempts: attempts})
+}
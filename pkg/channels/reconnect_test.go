@@ -0,0 +1,223 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Sterlites/RDxClaw/pkg/bus"
+)
+
+// droppingChannel fails its first starts, then runs until dropped.
+type droppingChannel struct {
+	*BaseChannel
+	mu         sync.Mutex
+	failStarts int
+	starts     int
+}
+
+func (c *droppingChannel) Start(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.starts++
+	if c.failStarts > 0 {
+		c.failStarts--
+		return errors.New("network unreachable")
+	}
+	c.setRunning(true)
+	return nil
+}
+
+func (c *droppingChannel) Stop(ctx context.Context) error {
+	c.setRunning(false)
+	return nil
+}
+
+func (c *droppingChannel) Send(ctx context.Context, msg bus.OutboundMessage) error { return nil }
+
+func (c *droppingChannel) startCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.starts
+}
+
+func newReconnectTestManager(msgBus *bus.MessageBus) *Manager {
+	return &Manager{
+		channels:      map[string]Channel{},
+		limiters:      make(map[string]*sendLimiter),
+		bus:           msgBus,
+		reconnectMin:  time.Millisecond,
+		reconnectMax:  4 * time.Millisecond,
+		checkInterval: time.Millisecond,
+	}
+}
+
+func waitForEvent(t *testing.T, events <-chan ChannelEvent, eventType string) ChannelEvent {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case event := <-events:
+			if event.Type == eventType {
+				return event
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for %s event", eventType)
+		}
+	}
+}
+
+func TestManagerReconnectsDroppedChannel(t *testing.T) {
+	msgBus := bus.NewMessageBus()
+	m := newReconnectTestManager(msgBus)
+	ch := &droppingChannel{BaseChannel: NewBaseChannel("telegram", nil, msgBus, nil)}
+	m.RegisterChannel("telegram", ch)
+
+	events := make(chan ChannelEvent, 10)
+	m.SetEventHandler(func(e ChannelEvent) { events <- e })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := m.StartAll(ctx); err != nil {
+		t.Fatalf("StartAll() error = %v", err)
+	}
+
+	// Drop the connection; the next two restarts fail before one succeeds.
+	ch.mu.Lock()
+	ch.failStarts = 2
+	ch.mu.Unlock()
+	ch.setRunning(false)
+
+	if e := waitForEvent(t, events, EventDisconnected); e.Channel != "telegram" {
+		t.Errorf("disconnect event = %+v", e)
+	}
+	e := waitForEvent(t, events, EventReconnected)
+	if e.Attempts != 3 {
+		t.Errorf("reconnect took %d attempts, want 3", e.Attempts)
+	}
+	if !ch.IsRunning() || ch.startCount() != 4 {
+		t.Errorf("running = %v after %d starts, want running after 4", ch.IsRunning(), ch.startCount())
+	}
+
+	st := m.GetChannelStatus()["telegram"]
+	if st.State != StateConnected || st.Reconnects != 1 || st.RetryAttempt != 0 || st.NextRetryAt != nil {
+		t.Errorf("status after reconnect = %+v", st)
+	}
+	if st.LastError != "network unreachable" {
+		t.Errorf("last error = %q, want the failed restart", st.LastError)
+	}
+
+	if err := m.StopAll(ctx); err != nil {
+		t.Fatalf("StopAll() error = %v", err)
+	}
+	starts := ch.startCount()
+	time.Sleep(20 * time.Millisecond)
+	if ch.startCount() != starts || ch.IsRunning() {
+		t.Error("channel was restarted after StopAll")
+	}
+}
+
+func TestManagerReconnectsReportedDrop(t *testing.T) {
+	msgBus := bus.NewMessageBus()
+	m := newReconnectTestManager(msgBus)
+	m.checkInterval = time.Hour // only the report can reveal the drop
+	ch := &droppingChannel{BaseChannel: NewBaseChannel("discord", nil, msgBus, nil)}
+	m.RegisterChannel("discord", ch)
+
+	events := make(chan ChannelEvent, 10)
+	m.SetEventHandler(func(e ChannelEvent) { events <- e })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := m.StartAll(ctx); err != nil {
+		t.Fatalf("StartAll() error = %v", err)
+	}
+
+	// The platform connection dropped while the channel still looks running
+	ch.reportDisconnect(errors.New("gateway closed"))
+
+	if e := waitForEvent(t, events, EventDisconnected); e.Error != "gateway closed" {
+		t.Errorf("disconnect event = %+v, want the reported error", e)
+	}
+	if e := waitForEvent(t, events, EventReconnected); e.Attempts != 1 {
+		t.Errorf("reconnect took %d attempts, want 1", e.Attempts)
+	}
+	if ch.startCount() != 2 {
+		t.Errorf("channel started %d times, want 2", ch.startCount())
+	}
+
+	// Reports after Stop are ignored
+	if err := m.StopAll(ctx); err != nil {
+		t.Fatalf("StopAll() error = %v", err)
+	}
+	ch.reportDisconnect(errors.New("late"))
+	select {
+	case <-ch.Disconnects():
+		t.Error("a disconnect was reported after Stop")
+	default:
+	}
+}
+
+func TestTelegoLoggerReportsPollFailures(t *testing.T) {
+	var reported []error
+	l := &telegoLogger{token: "123:secret", pollFailed: func(err error) { reported = append(reported, err) }}
+
+	l.Errorf("Getting updates: %s", "Post https://api.telegram.org/bot123:secret/getUpdates: EOF")
+	l.Errorf("Retrying getting updates in %s...", "8s")
+
+	if len(reported) != 1 {
+		t.Fatalf("reported %d failures, want 1", len(reported))
+	}
+	if got := reported[0].Error(); strings.Contains(got, "secret") {
+		t.Errorf("reported error leaks the bot token: %q", got)
+	}
+}
+
+func TestManagerRetriesFailedStart(t *testing.T) {
+	msgBus := bus.NewMessageBus()
+	m := newReconnectTestManager(msgBus)
+	m.reconnectMin = time.Hour // only the first retry is scheduled
+	ch := &droppingChannel{BaseChannel: NewBaseChannel("discord", nil, msgBus, nil), failStarts: 1}
+	m.RegisterChannel("discord", ch)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := m.StartAll(ctx); err != nil {
+		t.Fatalf("StartAll() error = %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		st := m.GetChannelStatus()["discord"]
+		if st.RetryAttempt == 1 {
+			if st.State != StateFailed || st.NextRetryAt == nil || st.LastError != "network unreachable" {
+				t.Errorf("status while retrying = %+v", st)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("no retry scheduled, status = %+v", st)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := m.StopAll(ctx); err != nil {
+		t.Fatalf("StopAll() error = %v", err)
+	}
+}
+
+func TestReconnectDelay(t *testing.T) {
+	m := &Manager{}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}
+	for i, w := range want {
+		if got := m.reconnectDelay(i + 1); got != w {
+			t.Errorf("reconnectDelay(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+	if got := m.reconnectDelay(100); got != reconnectMaxDelay {
+		t.Errorf("reconnectDelay(100) = %v, want the cap %v", got, reconnectMaxDelay)
+	}
+}
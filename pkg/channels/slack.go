@@ -63,6 +63,9 @@ func (c *SlackChannel) SetTranscriber(transcriber *voice.GroqTranscriber) {
 }
 
 func (c *SlackChannel) Start(ctx context.Context) error {
+	if c.IsRunning() {
+		return nil
+	}
 	logger.InfoC("slack", "Starting Slack channel (Socket Mode)")
 
 	c.ctx, c.cancel = context.WithCancel(ctx)
@@ -80,15 +83,19 @@ func (c *SlackChannel) Start(ctx context.Context) error {
 
 	go c.eventLoop()
 
-	go func() {
-		if err := c.socketClient.RunContext(c.ctx); err != nil {
-			if c.ctx.Err() == nil {
-				logger.ErrorCF("slack", "Socket Mode connection error", map[string]interface{}{
-					"error": err.Error(),
-				})
-			}
+	go func(ctx context.Context) {
+		err := c.socketClient.RunContext(ctx)
+		if ctx.Err() != nil {
+			return
 		}
-	}()
+		if err == nil {
+			err = fmt.Errorf("socket mode connection closed")
+		}
+		logger.ErrorCF("slack", "Socket Mode connection error", map[string]interface{}{
+			"error": err.Error(),
+		})
+		c.reportDisconnect(err)
+	}(c.ctx)
 
 	c.setRunning(true)
 	logger.InfoC("slack", "Slack channel started (Socket Mode)")
@@ -230,6 +237,7 @@ func (c *SlackChannel) handleMessageEvent(ev *slackevents.MessageEvent) {
 	content = c.stripBotMention(content)
 
 	var mediaPaths []string
+	var attachments []bus.Attachment
 	localFiles := []string{} // 跟踪需要清理的本地文件
 
 	// 确保临时文件在函数返回时被清理
@@ -264,6 +272,9 @@ func (c *SlackChannel) handleMessageEvent(ev *slackevents.MessageEvent) {
 				} else {
 					content += fmt.Sprintf("\n[voice transcription: %s]", result.Text)
 				}
+			} else if att, ok := loadImageAttachment(localPath, file.Name, file.Mimetype); ok {
+				attachments = append(attachments, att)
+				content += fmt.Sprintf("\n[image: %s]", file.Name)
 			} else {
 				content += fmt.Sprintf("\n[file: %s]", file.Name)
 			}
@@ -288,7 +299,7 @@ func (c *SlackChannel) handleMessageEvent(ev *slackevents.MessageEvent) {
 		"has_thread": threadTS != "",
 	})
 
-	c.HandleMessage(senderID, chatID, content, mediaPaths, metadata)
+	c.HandleMessageWithAttachments(senderID, chatID, content, mediaPaths, attachments, metadata)
 }
 
 func (c *SlackChannel) handleAppMention(ev *slackevents.AppMentionEvent) {
@@ -0,0 +1,146 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Sterlites/RDxClaw/pkg/logger"
+)
+
+// Channel states reported by GetChannelStatus.
+const (
+	StateConnected    = "connected"    // started, and the last send (if any) succeeded
+	StateDegraded     = "degraded"     // running, but the last send failed
+	StateFailed       = "failed"       // could not be initialized or started
+	StateReconnecting = "reconnecting" // lost its connection, being restarted
+	StateStopped      = "stopped"      // stopped by StopAll
+)
+
+// ChannelStatus is what the manager last observed about a channel.
+type ChannelStatus struct {
+	State       string     `json:"state"`
+	LastError   string     `json:"last_error,omitempty"`
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	// RetryAttempt and NextRetryAt are set while a failed or dropped
+	// channel is being restarted.
+	RetryAttempt int        `json:"retry_attempt,omitempty"`
+	NextRetryAt  *time.Time `json:"next_retry_at,omitempty"`
+	// Reconnects counts how often the channel came back after a failure.
+	Reconnects int `json:"reconnects,omitempty"`
+}
+
+// channelStatusPath is where the gateway persists channel status so that
+// other processes, such as `rdxclaw status`, can read it.
+func channelStatusPath(workspace string) string {
+	return filepath.Join(workspace, "state", "channels.json")
+}
+
+// LoadChannelStatus reads the channel status last persisted by a gateway
+// running on workspace. It returns nil when there is none.
+func LoadChannelStatus(workspace string) (map[string]ChannelStatus, error) {
+	data, err := os.ReadFile(channelStatusPath(workspace))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read channel status: %w", err)
+	}
+	var status map[string]ChannelStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		return nil, fmt.Errorf("failed to parse channel status: %w", err)
+	}
+	return status, nil
+}
+
+// GetChannelStatus returns the state and last error of every channel the
+// manager tried to bring up, including those that failed to initialize.
+func (m *Manager) GetChannelStatus() map[string]ChannelStatus {
+	m.statusMu.Lock()
+	defer m.statusMu.Unlock()
+
+	status := make(map[string]ChannelStatus, len(m.status))
+	for name, st := range m.status {
+		status[name] = *st
+	}
+	return status
+}
+
+// setChannelState records the outcome of an operation on a channel. A nil
+// err moves the channel to ok, otherwise to failState with err as its last
+// error; the previous error is kept after a recovery. Changes are persisted.
+func (m *Manager) setChannelState(name, ok, failState string, err error) {
+	m.updateChannelStatus(name, func(st *ChannelStatus, now time.Time) bool {
+		if err == nil {
+			changed := st.State != ok
+			st.State = ok
+			return changed
+		}
+		changed := st.State != failState || st.LastError != err.Error()
+		st.State = failState
+		st.LastError = err.Error()
+		st.LastErrorAt = &now
+		return changed
+	})
+}
+
+// updateChannelStatus applies update to the named channel's status and
+// persists it when update reports a change.
+func (m *Manager) updateChannelStatus(name string, update func(st *ChannelStatus, now time.Time) bool) {
+	m.statusMu.Lock()
+	defer m.statusMu.Unlock()
+
+	if m.status == nil {
+		m.status = make(map[string]*ChannelStatus)
+	}
+	st, exists := m.status[name]
+	if !exists {
+		st = &ChannelStatus{}
+		m.status[name] = st
+	}
+
+	now := time.Now()
+	if !update(st, now) && exists {
+		return
+	}
+	st.UpdatedAt = now
+	m.saveChannelStatusLocked()
+}
+
+// recordSend updates a channel's state after a send. Sends cut short by
+// shutdown say nothing about the channel and are ignored.
+func (m *Manager) recordSend(ctx context.Context, name string, err error) {
+	if err != nil && (ctx.Err() != nil || errors.Is(err, context.Canceled)) {
+		return
+	}
+	m.setChannelState(name, StateConnected, StateDegraded, err)
+}
+
+func (m *Manager) saveChannelStatusLocked() {
+	if m.config == nil {
+		return
+	}
+	path := channelStatusPath(m.config.WorkspacePath())
+	data, err := json.MarshalIndent(m.status, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(path), 0755)
+	}
+	if err == nil {
+		tmp := path + ".tmp"
+		if err = os.WriteFile(tmp, data, 0644); err == nil {
+			if err = os.Rename(tmp, path); err != nil {
+				os.Remove(tmp)
+			}
+		}
+	}
+	if err != nil {
+		logger.WarnCF("channels", "Failed to persist channel status", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Sterlites/RDxClaw/pkg/bus"
+	"github.com/Sterlites/RDxClaw/pkg/config"
+)
+
+type brokenChannel struct {
+	*BaseChannel
+	startErr error
+	sendErr  error
+}
+
+func (c *brokenChannel) Start(ctx context.Context) error {
+	if c.startErr != nil {
+		return c.startErr
+	}
+	c.setRunning(true)
+	return nil
+}
+
+func (c *brokenChannel) Stop(ctx context.Context) error {
+	c.setRunning(false)
+	return nil
+}
+
+func (c *brokenChannel) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	return c.sendErr
+}
+
+func TestManagerChannelStatus(t *testing.T) {
+	workspace := t.TempDir()
+	cfg := &config.Config{Agents: config.AgentsConfig{Defaults: config.AgentDefaults{Workspace: workspace}}}
+	msgBus := bus.NewMessageBus()
+	m := &Manager{
+		channels: map[string]Channel{},
+		limiters: make(map[string]*sendLimiter),
+		bus:      msgBus,
+		config:   cfg,
+	}
+	telegram := &brokenChannel{
+		BaseChannel: NewBaseChannel("telegram", nil, msgBus, nil),
+		startErr:    errors.New("401 Unauthorized: invalid token"),
+	}
+	discord := &brokenChannel{BaseChannel: NewBaseChannel("discord", nil, msgBus, nil)}
+	m.RegisterChannel("telegram", telegram)
+	m.RegisterChannel("discord", discord)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := m.StartAll(ctx); err != nil {
+		t.Fatalf("StartAll() error = %v", err)
+	}
+
+	status := m.GetChannelStatus()
+	if st := status["telegram"]; st.State != StateFailed || st.LastError != "401 Unauthorized: invalid token" || st.LastErrorAt == nil {
+		t.Errorf("telegram status = %+v, want failed with the start error", st)
+	}
+	if st := status["discord"]; st.State != StateConnected || st.LastError != "" {
+		t.Errorf("discord status = %+v, want connected", st)
+	}
+
+	// A failed send degrades the channel; the next successful one restores
+	// it but keeps the error for reference.
+	discord.sendErr = errors.New("connection reset")
+	if err := m.SendToChannel(ctx, "discord", "c1", "hi"); err == nil {
+		t.Fatal("expected the send to fail")
+	}
+	if st := m.GetChannelStatus()["discord"]; st.State != StateDegraded || st.LastError != "connection reset" {
+		t.Errorf("discord status after failed send = %+v, want degraded", st)
+	}
+	discord.sendErr = nil
+	if err := m.SendToChannel(ctx, "discord", "c1", "hi"); err != nil {
+		t.Fatalf("SendToChannel() error = %v", err)
+	}
+	if st := m.GetChannelStatus()["discord"]; st.State != StateConnected || st.LastError != "connection reset" {
+		t.Errorf("discord status after recovery = %+v, want connected with last error kept", st)
+	}
+
+	// Other processes see the same status.
+	persisted, err := LoadChannelStatus(workspace)
+	if err != nil {
+		t.Fatalf("LoadChannelStatus() error = %v", err)
+	}
+	if persisted["telegram"].State != StateFailed || persisted["discord"].State != StateConnected {
+		t.Errorf("persisted status = %+v", persisted)
+	}
+
+	if err := m.StopAll(ctx); err != nil {
+		t.Fatalf("StopAll() error = %v", err)
+	}
+	if st := m.GetChannelStatus()["telegram"]; st.State != StateStopped || st.LastError == "" {
+		t.Errorf("telegram status after stop = %+v, want stopped with last error kept", st)
+	}
+}
+
+func TestLoadChannelStatusMissing(t *testing.T) {
+	status, err := LoadChannelStatus(t.TempDir())
+	if err != nil || status != nil {
+		t.Errorf("LoadChannelStatus() = %v, %v; want nil, nil", status, err)
+	}
+}
@@ -33,6 +33,9 @@ type TelegramChannel struct {
 	transcriber  *voice.GroqTranscriber
 	placeholders sync.Map // chatID -> messageID
 	stopThinking sync.Map // chatID -> thinkingCancel
+
+	// stopPolling ends the long polling started by Start.
+	stopPolling context.CancelFunc
 }
 
 type thinkingCancel struct {
@@ -61,12 +64,16 @@ func NewTelegramChannel(cfg *config.Config, bus *bus.MessageBus) (*TelegramChann
 		}))
 	}
 
+	pollLog := &telegoLogger{token: telegramCfg.Token}
+	opts = append(opts, telego.WithLogger(pollLog))
+
 	bot, err := telego.NewBot(telegramCfg.Token, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create telegram bot: %w", err)
 	}
 
 	base := NewBaseChannel("telegram", telegramCfg, bus, telegramCfg.AllowFrom)
+	pollLog.pollFailed = base.reportDisconnect
 
 	return &TelegramChannel{
 		BaseChannel:  base,
@@ -85,19 +92,26 @@ func (c *TelegramChannel) SetTranscriber(transcriber *voice.GroqTranscriber) {
 }
 
 func (c *TelegramChannel) Start(ctx context.Context) error {
+	if c.IsRunning() {
+		return nil
+	}
 	logger.InfoC("telegram", "Starting Telegram bot (polling mode)...")
 
+	ctx, cancel := context.WithCancel(ctx)
 	updates, err := c.bot.UpdatesViaLongPolling(ctx, &telego.GetUpdatesParams{
 		Timeout: 30,
 	})
 	if err != nil {
+		cancel()
 		return fmt.Errorf("failed to start long polling: %w", err)
 	}
 
 	bh, err := telegohandler.NewBotHandler(c.bot, updates)
 	if err != nil {
+		cancel()
 		return fmt.Errorf("failed to create bot handler: %w", err)
 	}
+	c.stopPolling = cancel
 
 	bh.HandleMessage(func(ctx *th.Context, message telego.Message) error {
 		c.commands.Help(ctx, message)
@@ -124,11 +138,16 @@ func (c *TelegramChannel) Start(ctx context.Context) error {
 		"username": c.bot.Username(),
 	})
 
-	go bh.Start()
+	go func() {
+		_ = bh.Start()
+		if ctx.Err() == nil {
+			c.reportDisconnect(fmt.Errorf("telegram update polling stopped"))
+		}
+	}()
 
 	go func() {
 		<-ctx.Done()
-		bh.Stop()
+		_ = bh.Stop()
 	}()
 
 	return nil
@@ -136,9 +155,33 @@ func (c *TelegramChannel) Start(ctx context.Context) error {
 func (c *TelegramChannel) Stop(ctx context.Context) error {
 	logger.InfoC("telegram", "Stopping Telegram bot...")
 	c.setRunning(false)
+	if c.stopPolling != nil {
+		c.stopPolling()
+	}
 	return nil
 }
 
+// telegoLogger passes telego's errors to the logger, without the bot
+// token, and reports failed update polls as dropped connections. telego
+// would otherwise retry them quietly forever.
+type telegoLogger struct {
+	token      string
+	pollFailed func(error)
+}
+
+func (l *telegoLogger) Debugf(format string, args ...any) {}
+
+func (l *telegoLogger) Errorf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	if l.token != "" {
+		msg = strings.ReplaceAll(msg, l.token, "BOT_TOKEN")
+	}
+	logger.ErrorCF("telegram", "telego: "+msg, nil)
+	if strings.HasPrefix(format, "Getting updates") && l.pollFailed != nil {
+		l.pollFailed(fmt.Errorf("%s", msg))
+	}
+}
+
 func (c *TelegramChannel) Send(ctx context.Context, msg bus.OutboundMessage) error {
 	if !c.IsRunning() {
 		return fmt.Errorf("telegram bot not running")
@@ -214,6 +257,7 @@ func (c *TelegramChannel) handleMessage(ctx context.Context, message *telego.Mes
 
 	content := ""
 	mediaPaths := []string{}
+	var attachments []bus.Attachment
 	localFiles := []string{} // 跟踪需要清理的本地文件
 
 	// 确保临时文件在函数返回时被清理
@@ -245,6 +289,9 @@ func (c *TelegramChannel) handleMessage(ctx context.Context, message *telego.Mes
 		if photoPath != "" {
 			localFiles = append(localFiles, photoPath)
 			mediaPaths = append(mediaPaths, photoPath)
+			if att, ok := loadImageAttachment(photoPath, "photo.jpg", "image/jpeg"); ok {
+				attachments = append(attachments, att)
+			}
 			if content != "" {
 				content += "\n"
 			}
@@ -307,7 +354,12 @@ func (c *TelegramChannel) handleMessage(ctx context.Context, message *telego.Mes
 			if content != "" {
 				content += "\n"
 			}
-			content += "[file]"
+			if att, ok := loadImageAttachment(docPath, message.Document.FileName, message.Document.MimeType); ok {
+				attachments = append(attachments, att)
+				content += fmt.Sprintf("[image: %s]", att.Filename)
+			} else {
+				content += "[file]"
+			}
 		}
 	}
 
@@ -355,7 +407,7 @@ func (c *TelegramChannel) handleMessage(ctx context.Context, message *telego.Mes
 		"is_group":   fmt.Sprintf("%t", message.Chat.Type != "private"),
 	}
 
-	c.HandleMessage(fmt.Sprintf("%d", user.ID), fmt.Sprintf("%d", chatID), content, mediaPaths, metadata)
+	c.HandleMessageWithAttachments(fmt.Sprintf("%d", user.ID), fmt.Sprintf("%d", chatID), content, mediaPaths, attachments, metadata)
 	return nil
 }
 
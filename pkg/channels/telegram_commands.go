@@ -54,7 +54,7 @@ func (c *cmd) Help(ctx context.Context, message telego.Message) error {
 func (c *cmd) Start(ctx context.Context, message telego.Message) error {
 	_, err := c.bot.SendMessage(ctx, &telego.SendMessageParams{
 		ChatID: telego.ChatID{ID: message.Chat.ID},
-		Text:   "Hello! I am rdxclaw 🦾",
+		Text:   "Hello! I am " + c.config.Branding.Title(),
 		ReplyParameters: &telego.ReplyParameters{
 			MessageID: message.MessageID,
 		},
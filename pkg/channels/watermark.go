@@ -0,0 +1,211 @@
+package channels
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sterlites/RDxClaw/pkg/logger"
+)
+
+// watermarkSaveDelay batches the mark changes of a burst of messages into
+// one write of the watermarks file.
+const watermarkSaveDelay = time.Second
+
+// watermarkStore remembers the highest platform message ID seen in each
+// chat, so that messages a platform redelivers after a reconnect or a
+// restart are not answered twice. IDs that are not numbers (e.g. WhatsApp's)
+// cannot be ordered and are never filtered.
+//
+// Messages are only checked against the mark taken when the channel's
+// current run started: within a run, handlers may see messages slightly
+// out of order, and the bus already drops exact repeats.
+//
+// Raised marks are written out at most once per save delay; flush writes
+// any pending change right away.
+type watermarkStore struct {
+	mu        sync.Mutex
+	path      string                       // "" keeps marks in memory only
+	marks     map[string]map[string]string // channel -> chat -> highest ID seen
+	floors    map[string]map[string]string // marks when the current run began
+	saveDelay time.Duration
+	saveTimer *time.Timer // pending save, nil when the file is up to date
+}
+
+func setChannelWatermarks(channel Channel, w *watermarkStore) {
+	if wc, ok := channel.(interface{ setWatermarks(*watermarkStore) }); ok {
+		wc.setWatermarks(w)
+	}
+}
+
+func (c *BaseChannel) setWatermarks(w *watermarkStore) {
+	c.watermarks = w
+}
+
+func watermarkPath(workspace string) string {
+	return filepath.Join(workspace, "state", "channel_watermarks.json")
+}
+
+// newWatermarkStore loads the marks persisted at path. The loaded marks
+// also become the floors, as a restart is a reconnect of every channel.
+func newWatermarkStore(path string) *watermarkStore {
+	w := &watermarkStore{
+		path:      path,
+		marks:     make(map[string]map[string]string),
+		floors:    make(map[string]map[string]string),
+		saveDelay: watermarkSaveDelay,
+	}
+	if path == "" {
+		return w
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.WarnCF("channels", "Failed to read message watermarks", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+		return w
+	}
+	if err := json.Unmarshal(data, &w.marks); err != nil || w.marks == nil {
+		logger.WarnCF("channels", "Ignoring unreadable message watermarks", map[string]interface{}{
+			"path": path,
+		})
+		w.marks = make(map[string]map[string]string)
+	}
+	for channel := range w.marks {
+		w.beginRunLocked(channel)
+	}
+	return w
+}
+
+// beginRun is called before a channel (re)starts: anything at or before
+// the marks seen so far is a redelivery from now on.
+func (w *watermarkStore) beginRun(channel string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.beginRunLocked(channel)
+}
+
+func (w *watermarkStore) beginRunLocked(channel string) {
+	floors := make(map[string]string, len(w.marks[channel]))
+	for chat, id := range w.marks[channel] {
+		floors[chat] = id
+	}
+	w.floors[channel] = floors
+}
+
+// advance reports whether message id in chat is new, and raises the chat's
+// mark when it is.
+func (w *watermarkStore) advance(channel, chatID, id string) bool {
+	if !isMessageNumber(id) {
+		return true
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if floor, ok := w.floors[channel][chatID]; ok && compareMessageIDs(id, floor) <= 0 {
+		return false
+	}
+	marks := w.marks[channel]
+	if marks == nil {
+		marks = make(map[string]string)
+		w.marks[channel] = marks
+	}
+	if last, ok := marks[chatID]; !ok || compareMessageIDs(id, last) > 0 {
+		marks[chatID] = id
+		w.scheduleSaveLocked()
+	}
+	return true
+}
+
+// scheduleSaveLocked saves the marks after the save delay, unless a save
+// is already pending.
+func (w *watermarkStore) scheduleSaveLocked() {
+	if w.path == "" || w.saveTimer != nil {
+		return
+	}
+	w.saveTimer = time.AfterFunc(w.saveDelay, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		w.saveTimer = nil
+		w.saveLocked()
+	})
+}
+
+// flush writes a pending change of the marks now.
+func (w *watermarkStore) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.saveTimer == nil {
+		return
+	}
+	w.saveTimer.Stop()
+	w.saveTimer = nil
+	w.saveLocked()
+}
+
+func (w *watermarkStore) saveLocked() {
+	if w.path == "" {
+		return
+	}
+	data, err := json.Marshal(w.marks)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(w.path), 0755)
+	}
+	if err == nil {
+		tmp := w.path + ".tmp"
+		if err = os.WriteFile(tmp, data, 0644); err == nil {
+			if err = os.Rename(tmp, w.path); err != nil {
+				os.Remove(tmp)
+			}
+		}
+	}
+	if err != nil {
+		logger.WarnCF("channels", "Failed to persist message watermarks", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+// isMessageNumber reports whether id is a non-negative decimal number, like
+// Telegram's message IDs, Discord's snowflakes or Slack's "1700000000.000100"
+// timestamps.
+func isMessageNumber(id string) bool {
+	whole, frac, _ := strings.Cut(id, ".")
+	return whole != "" && isDigits(whole) && isDigits(frac)
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// compareMessageIDs compares two numeric message IDs without parsing them,
+// as snowflakes and Slack timestamps do not fit a float64 exactly.
+func compareMessageIDs(a, b string) int {
+	aWhole, aFrac, _ := strings.Cut(a, ".")
+	bWhole, bFrac, _ := strings.Cut(b, ".")
+	aWhole = strings.TrimLeft(aWhole, "0")
+	bWhole = strings.TrimLeft(bWhole, "0")
+	if len(aWhole) != len(bWhole) {
+		if len(aWhole) < len(bWhole) {
+			return -1
+		}
+		return 1
+	}
+	if c := strings.Compare(aWhole, bWhole); c != 0 {
+		return c
+	}
+	aFrac = strings.TrimRight(aFrac, "0")
+	bFrac = strings.TrimRight(bFrac, "0")
+	return strings.Compare(aFrac, bFrac)
+}
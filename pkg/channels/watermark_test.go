@@ -0,0 +1,125 @@
+package channels
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Sterlites/RDxClaw/pkg/bus"
+)
+
+func TestWatermarksSkipRedeliveryAfterReconnect(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "channel_watermarks.json")
+	msgBus := bus.NewMessageBus()
+	msgBus.SetDedup(0, 0) // only the watermarks may drop messages here
+
+	ch := &flakyChannel{BaseChannel: NewBaseChannel("telegram", nil, msgBus, nil)}
+	marks := newWatermarkStore(path)
+	setChannelWatermarks(ch, marks)
+	marks.beginRun("telegram")
+
+	deliver := func(chatID, messageID, content string) {
+		ch.HandleMessage("user", chatID, content, nil, map[string]string{"message_id": messageID})
+	}
+	expect := func(want ...string) {
+		t.Helper()
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		for _, w := range want {
+			msg, ok := msgBus.ConsumeInbound(ctx)
+			if !ok || msg.Content != w {
+				t.Fatalf("expected %q to reach the agent, got %+v", w, msg)
+			}
+		}
+		if msg, ok := msgBus.ConsumeInbound(ctx); ok {
+			t.Fatalf("unexpected message %+v", msg)
+		}
+	}
+
+	// Within a run, slightly out-of-order messages still get through.
+	deliver("chat-1", "10", "ten")
+	deliver("chat-1", "9", "nine")
+	deliver("chat-2", "3", "three")
+	expect("ten", "nine", "three")
+
+	// After a reconnect the platform redelivers the last update.
+	marks.beginRun("telegram")
+	deliver("chat-1", "10", "ten again")
+	deliver("chat-1", "11", "eleven")
+	deliver("chat-2", "4", "four")
+	expect("eleven", "four")
+
+	// IDs that cannot be ordered are never filtered.
+	deliver("chat-1", "wamid.ABC", "opaque")
+	deliver("chat-1", "wamid.ABC", "opaque again")
+	expect("opaque", "opaque again")
+
+	// A restart picks up the persisted marks.
+	marks.flush()
+	restarted := newWatermarkStore(path)
+	setChannelWatermarks(ch, restarted)
+	deliver("chat-1", "11", "eleven again")
+	deliver("chat-2", "4", "four again")
+	deliver("chat-2", "5", "five")
+	expect("five")
+}
+
+func TestCompareMessageIDs(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"9", "10", -1},
+		{"10", "10", 0},
+		{"1234567890123456789", "1234567890123456788", 1}, // Discord snowflakes
+		{"1700000000.000200", "1700000000.000100", 1},     // Slack timestamps
+		{"1700000000.1", "1700000000.100000", 0},
+		{"1700000000.05", "1700000000.1", -1},
+		{"1699999999.999999", "1700000000.000001", -1},
+	}
+	for _, tt := range tests {
+		if got := compareMessageIDs(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareMessageIDs(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+	for _, id := range []string{"", "abc", "12a", "1.2.3", ".5", "-1"} {
+		if isMessageNumber(id) {
+			t.Errorf("isMessageNumber(%q) = true", id)
+		}
+	}
+}
+
+func TestWatermarksBatchSaves(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "channel_watermarks.json")
+	marks := newWatermarkStore(path)
+	marks.saveDelay = 50 * time.Millisecond
+
+	for i := 1; i <= 100; i++ {
+		marks.advance("telegram", "chat-1", strconv.Itoa(i))
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatal("marks were written before the save delay passed")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if restored := newWatermarkStore(path); restored.marks["telegram"]["chat-1"] == "100" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("marks were not saved after the delay")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// flush writes a pending change without waiting
+	marks.saveDelay = time.Hour
+	marks.advance("telegram", "chat-1", "101")
+	marks.flush()
+	if restored := newWatermarkStore(path); restored.marks["telegram"]["chat-1"] != "101" {
+		t.Errorf("flush did not save the marks: %v", restored.marks)
+	}
+}
@@ -121,9 +121,18 @@ func (c *WhatsAppChannel) listen(ctx context.Context) {
 
 			_, message, err := conn.ReadMessage()
 			if err != nil {
-				log.Printf("WhatsApp read error: %v", err)
-				time.Sleep(2 * time.Second)
-				continue
+				if ctx.Err() != nil {
+					return
+				}
+				// A websocket that failed a read stays broken; report the
+				// channel as down so the manager reconnects it.
+				log.Printf("WhatsApp read error, connection lost: %v", err)
+				c.mu.Lock()
+				c.connected = false
+				c.mu.Unlock()
+				c.reportDisconnect(err)
+				c.setRunning(false)
+				return
 			}
 
 			var msg map[string]interface{}
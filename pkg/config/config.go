@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 
 	"github.com/caarlos0/env/v11"
@@ -52,11 +53,39 @@ type Config struct {
 	Tools     ToolsConfig     `json:"tools"`
 	Heartbeat HeartbeatConfig `json:"heartbeat"`
 	Devices   DevicesConfig   `json:"devices"`
-	mu        sync.RWMutex
+	Skills    SkillsConfig    `json:"skills"`
+	Logging   LoggingConfig   `json:"logging"`
+	CLI       CLIConfig       `json:"cli"`
+	Branding  BrandingConfig  `json:"branding"`
+	// Models extends or overrides the built-in model capability registry,
+	// keyed by exact model name. Useful for custom and local models.
+	Models map[string]ModelConfig `json:"models,omitempty"`
+	mu     sync.RWMutex
+}
+
+// ModelConfig declares the capabilities of a model. Unset fields keep the
+// value the built-in registry has for the name, or its defaults.
+type ModelConfig struct {
+	ContextWindow  int      `json:"context_window,omitempty"`
+	SupportsTools  *bool    `json:"supports_tools,omitempty"`
+	SupportsVision *bool    `json:"supports_vision,omitempty"`
+	SupportsJSON   *bool    `json:"supports_json,omitempty"`
+	InputCost      *float64 `json:"input_cost,omitempty"`  // USD per million tokens
+	OutputCost     *float64 `json:"output_cost,omitempty"` // USD per million tokens
 }
 
 type AgentsConfig struct {
 	Defaults AgentDefaults `json:"defaults"`
+	// Channels overrides the provider/model per originating channel
+	// (e.g. "telegram", "discord"). Channels without an entry use Defaults.
+	Channels map[string]ChannelAgentConfig `json:"channels,omitempty"`
+}
+
+// ChannelAgentConfig overrides model selection for a single channel.
+// When Provider is empty, the default provider is used with Model.
+type ChannelAgentConfig struct {
+	Provider string `json:"provider,omitempty"`
+	Model    string `json:"model,omitempty"`
 }
 
 type AgentDefaults struct {
@@ -67,6 +96,54 @@ type AgentDefaults struct {
 	MaxTokens           int     `json:"max_tokens" env:"RDXCLAW_AGENTS_DEFAULTS_MAX_TOKENS"`
 	Temperature         float64 `json:"temperature" env:"RDXCLAW_AGENTS_DEFAULTS_TEMPERATURE"`
 	MaxToolIterations   int     `json:"max_tool_iterations" env:"RDXCLAW_AGENTS_DEFAULTS_MAX_TOOL_ITERATIONS"`
+	WatchSkills         bool    `json:"watch_skills" env:"RDXCLAW_AGENTS_DEFAULTS_WATCH_SKILLS"`
+
+	// MaxContinuations is how many times a reply the model cut off at
+	// max_tokens is continued and stitched together. 0 disables it; such
+	// replies are only reported as truncated.
+	MaxContinuations int `json:"max_continuations" env:"RDXCLAW_AGENTS_DEFAULTS_MAX_CONTINUATIONS"`
+
+	// SummarizeHistory replaces the oldest turns of a long session with a
+	// summary once it has more than SummarizeAfterMessages messages or its
+	// estimated size exceeds SummarizeTokenPercent of the context window.
+	SummarizeHistory       bool `json:"summarize_history" env:"RDXCLAW_AGENTS_DEFAULTS_SUMMARIZE_HISTORY"`
+	SummarizeAfterMessages int  `json:"summarize_after_messages" env:"RDXCLAW_AGENTS_DEFAULTS_SUMMARIZE_AFTER_MESSAGES"`
+	SummarizeTokenPercent  int  `json:"summarize_token_percent" env:"RDXCLAW_AGENTS_DEFAULTS_SUMMARIZE_TOKEN_PERCENT"`
+
+	// Swarm agents are queued instead of started while less than
+	// SwarmMinHeadroomMB of memory remains below SwarmMemoryLimitMB. A zero
+	// limit uses GOMEMLIMIT when set; a zero headroom disables queueing.
+	SwarmMemoryLimitMB int `json:"swarm_memory_limit_mb" env:"RDXCLAW_AGENTS_DEFAULTS_SWARM_MEMORY_LIMIT_MB"`
+	SwarmMinHeadroomMB int `json:"swarm_min_headroom_mb" env:"RDXCLAW_AGENTS_DEFAULTS_SWARM_MIN_HEADROOM_MB"`
+	// SwarmMaxIterations is the tool iteration cap of swarm agents, unless
+	// the request or skill that spawned them set one.
+	SwarmMaxIterations int `json:"swarm_max_iterations" env:"RDXCLAW_AGENTS_DEFAULTS_SWARM_MAX_ITERATIONS"`
+
+	// SilentReplyToken is what the agent answers when a turn needs no
+	// reply. Such replies are never sent to a channel. Empty disables it.
+	SilentReplyToken string `json:"silent_reply_token" env:"RDXCLAW_AGENTS_DEFAULTS_SILENT_REPLY_TOKEN"`
+
+	// InjectDateTime puts the current date and time into the system prompt
+	// of every turn. Timezone is an IANA name (empty means the host's zone)
+	// and Locale, e.g. "en-GB", tells the model how to format dates.
+	InjectDateTime bool   `json:"inject_datetime" env:"RDXCLAW_AGENTS_DEFAULTS_INJECT_DATETIME"`
+	Timezone       string `json:"timezone" env:"RDXCLAW_AGENTS_DEFAULTS_TIMEZONE"`
+	Locale         string `json:"locale" env:"RDXCLAW_AGENTS_DEFAULTS_LOCALE"`
+
+	// MaxToolOutputChars caps each tool result fed back to the model; 0
+	// disables the cap. With StashToolOutput the full output of a cut
+	// result is kept in <workspace>/tool_outputs for the agent to read.
+	MaxToolOutputChars int  `json:"max_tool_output_chars" env:"RDXCLAW_AGENTS_DEFAULTS_MAX_TOOL_OUTPUT_CHARS"`
+	StashToolOutput    bool `json:"stash_tool_output" env:"RDXCLAW_AGENTS_DEFAULTS_STASH_TOOL_OUTPUT"`
+}
+
+// DefaultSilentReplyToken is the default AgentDefaults.SilentReplyToken.
+const DefaultSilentReplyToken = "NO_REPLY"
+
+// IsSilentReply reports whether content is the silent reply token, i.e.
+// the agent decided there is nothing to send.
+func IsSilentReply(content, token string) bool {
+	return token != "" && strings.TrimSpace(content) == token
 }
 
 type ChannelsConfig struct {
@@ -135,6 +212,12 @@ type ProvidersConfig struct {
 	DeepSeek      ProviderConfig `json:"deepseek"`
 	GitHubCopilot ProviderConfig `json:"github_copilot"`
 	ShengSuanYun  ProviderConfig `json:"shengsuanyun"`
+	// RequestTimeout bounds each HTTP provider call, in seconds. It is
+	// separate from the overall turn deadline. Defaults to 120.
+	RequestTimeout int `json:"request_timeout" env:"RDXCLAW_PROVIDERS_REQUEST_TIMEOUT"`
+	// LogRequests logs a hash of every prompt with its token counts and
+	// latency, to spot repeated prompts. Prompt contents are never logged.
+	LogRequests bool `json:"log_requests" env:"RDXCLAW_PROVIDERS_LOG_REQUESTS"`
 }
 
 type ProviderConfig struct {
@@ -155,8 +238,56 @@ type APIConfig struct {
 	Host        string              `json:"host" env:"RDXCLAW_API_HOST"`
 	Port        int                 `json:"port" env:"RDXCLAW_API_PORT"`
 	APIKey      string              `json:"api_key" env:"RDXCLAW_API_KEY"`
-	RateLimit   int                 `json:"rate_limit" env:"RDXCLAW_API_RATE_LIMIT"` // requests per minute
+	APIKeys     FlexibleStringSlice `json:"api_keys" env:"RDXCLAW_API_KEYS"`         // further accepted keys, e.g. one per tenant
+	RateLimit   int                 `json:"rate_limit" env:"RDXCLAW_API_RATE_LIMIT"` // requests per minute, per API key or per IP without one
 	CORSOrigins FlexibleStringSlice `json:"cors_origins" env:"RDXCLAW_API_CORS_ORIGINS"`
+	// EventRetention is how many activity events are kept in memory for the
+	// status endpoint.
+	EventRetention int `json:"event_retention" env:"RDXCLAW_API_EVENT_RETENTION"`
+	// WebhookReplaySize is how many payloads are kept per webhook path for
+	// POST /v1/webhooks/{path}/replay.
+	WebhookReplaySize int `json:"webhook_replay_size" env:"RDXCLAW_API_WEBHOOK_REPLAY_SIZE"`
+
+	// RequestTimeout bounds how long a request waits for the agent, in
+	// seconds. ChatTimeout, SkillTimeout and WebhookTimeout override it for
+	// /v1/chat/completions, /v1/skills/{skill}/execute and synchronous
+	// webhooks; 0 inherits it.
+	RequestTimeout int `json:"request_timeout" env:"RDXCLAW_API_REQUEST_TIMEOUT"`
+	ChatTimeout    int `json:"chat_timeout" env:"RDXCLAW_API_CHAT_TIMEOUT"`
+	SkillTimeout   int `json:"skill_timeout" env:"RDXCLAW_API_SKILL_TIMEOUT"`
+	WebhookTimeout int `json:"webhook_timeout" env:"RDXCLAW_API_WEBHOOK_TIMEOUT"`
+
+	// MaxUploadMB caps a file uploaded to /v1/knowledge/{collection}/ingest.
+	MaxUploadMB int `json:"max_upload_mb" env:"RDXCLAW_API_MAX_UPLOAD_MB"`
+
+	// MaxWebhookKB caps the body of a request to /v1/webhooks/{path}.
+	MaxWebhookKB int `json:"max_webhook_kb" env:"RDXCLAW_API_MAX_WEBHOOK_KB"`
+
+	// ShutdownDelay is how many seconds the server keeps serving after
+	// /ready starts failing on shutdown, so load balancers can drain it.
+	ShutdownDelay int `json:"shutdown_delay" env:"RDXCLAW_API_SHUTDOWN_DELAY"`
+
+	// CORSOrigins applies to the /v1/* API. UICORSOrigins applies to the
+	// bundled web UI and other routes, which are served same-origin and
+	// need none by default.
+	UICORSOrigins FlexibleStringSlice `json:"ui_cors_origins" env:"RDXCLAW_API_UI_CORS_ORIGINS"`
+
+	// Alerts raises an alert when error activity events spike.
+	Alerts AlertsConfig `json:"alerts"`
+}
+
+// AlertsConfig raises an alert when more than Threshold error events occur
+// within Window seconds, at most once per Cooldown seconds. It is off while
+// Threshold is 0. Alerts go to WebhookURL as JSON, to Channel and ChatID
+// as a message, or both. Only errors seen by the API server count, not
+// those of the gateway's chat channels.
+type AlertsConfig struct {
+	Threshold  int    `json:"threshold" env:"RDXCLAW_API_ALERTS_THRESHOLD"`
+	Window     int    `json:"window" env:"RDXCLAW_API_ALERTS_WINDOW"`
+	Cooldown   int    `json:"cooldown" env:"RDXCLAW_API_ALERTS_COOLDOWN"`
+	WebhookURL string `json:"webhook_url,omitempty" env:"RDXCLAW_API_ALERTS_WEBHOOK_URL"`
+	Channel    string `json:"channel,omitempty" env:"RDXCLAW_API_ALERTS_CHANNEL"`
+	ChatID     string `json:"chat_id,omitempty" env:"RDXCLAW_API_ALERTS_CHAT_ID"`
 }
 
 type BraveConfig struct {
@@ -175,8 +306,105 @@ type WebToolsConfig struct {
 	DuckDuckGo DuckDuckGoConfig `json:"duckduckgo"`
 }
 
+// CLIConfig controls the interactive command line.
+type CLIConfig struct {
+	// HistoryFile is where the interactive agent keeps input history.
+	// Empty uses .RDXCLAW_history in the temp directory. History is kept
+	// in memory when the file cannot be written.
+	HistoryFile string `json:"history_file,omitempty" env:"RDXCLAW_CLI_HISTORY_FILE"`
+	// NoHistory keeps history in memory only, like --no-history.
+	NoHistory bool `json:"no_history,omitempty" env:"RDXCLAW_CLI_NO_HISTORY"`
+}
+
+// BrandingConfig is how the assistant presents itself in the CLI, in
+// channel greetings and in the system prompt's header. Machine-readable
+// output, such as API responses, does not use it.
+type BrandingConfig struct {
+	Name  string `json:"name" env:"RDXCLAW_BRANDING_NAME"`
+	Emoji string `json:"emoji" env:"RDXCLAW_BRANDING_EMOJI"` // may be empty
+}
+
+// DefaultBranding returns the branding used when none is configured.
+func DefaultBranding() BrandingConfig {
+	return BrandingConfig{Name: "RDxClaw", Emoji: "🦾"}
+}
+
+// Mark prefixes the assistant's output: its emoji, or its name when it
+// has none.
+func (b BrandingConfig) Mark() string {
+	if b.Emoji != "" {
+		return b.Emoji
+	}
+	return b.Name
+}
+
+// Title is the name followed by the emoji, e.g. "RDxClaw 🦾".
+func (b BrandingConfig) Title() string {
+	return strings.TrimSpace(b.Name + " " + b.Emoji)
+}
+
+// LoggingConfig controls log output.
+type LoggingConfig struct {
+	// Components overrides the log level per component, e.g.
+	// {"channels": "debug", "health": "warn"}. Other components use the
+	// global level. The env var takes "channels:debug,health:warn".
+	Components map[string]string `json:"components,omitempty" env:"RDXCLAW_LOG_COMPONENTS"`
+}
+
+// SkillsConfig controls skill installation.
+type SkillsConfig struct {
+	// TrustedPublishers maps a publisher name to its base64 Ed25519 public
+	// key. Only skills signed by one of these publishers install without
+	// --allow-untrusted.
+	TrustedPublishers map[string]string `json:"trusted_publishers,omitempty"`
+
+	// AllowUntrusted installs unsigned skills and skills from other
+	// publishers as if --allow-untrusted were always passed.
+	AllowUntrusted bool `json:"allow_untrusted,omitempty" env:"RDXCLAW_SKILLS_ALLOW_UNTRUSTED"`
+}
+
+// KnowledgeConfig controls the knowledge base (RAG) tool.
+type KnowledgeConfig struct {
+	// RedactCollections lists the collections whose documents are scrubbed
+	// of PII before indexing. Redaction is off for all others.
+	RedactCollections FlexibleStringSlice `json:"redact_collections" env:"RDXCLAW_TOOLS_KNOWLEDGE_REDACT_COLLECTIONS"`
+	// RedactDetectors selects built-in detectors: email, phone, credit_card,
+	// api_key. Empty enables all of them unless RedactPatterns is set.
+	RedactDetectors FlexibleStringSlice `json:"redact_detectors" env:"RDXCLAW_TOOLS_KNOWLEDGE_REDACT_DETECTORS"`
+	// RedactPatterns adds custom detectors as name -> regular expression.
+	RedactPatterns map[string]string `json:"redact_patterns,omitempty"`
+
+	// SearchCacheSize is how many recent searches each collection caches.
+	// Zero disables the cache.
+	SearchCacheSize int `json:"search_cache_size" env:"RDXCLAW_TOOLS_KNOWLEDGE_SEARCH_CACHE_SIZE"`
+
+	// QueryLogCollections lists the collections whose searches are logged
+	// for GET /v1/knowledge/{collection}/analytics. Off for all others.
+	QueryLogCollections FlexibleStringSlice `json:"query_log_collections" env:"RDXCLAW_TOOLS_KNOWLEDGE_QUERY_LOG_COLLECTIONS"`
+
+	// Tokenizers selects the tokenizer of a collection as name ->
+	// "unicode" (the default), "cjk_chars" or "cjk_bigrams". The CJK modes
+	// segment Chinese, Japanese and Korean text by character.
+	Tokenizers map[string]string `json:"tokenizers,omitempty"`
+
+	// IngestWorkers is how many files a directory ingest reads and chunks
+	// at once. Zero uses one per CPU.
+	IngestWorkers int `json:"ingest_workers" env:"RDXCLAW_TOOLS_KNOWLEDGE_INGEST_WORKERS"`
+	// IngestMaxFiles and IngestMaxMB cap one directory ingest. Zero uses
+	// the defaults of 10000 files and 512 MB.
+	IngestMaxFiles int `json:"ingest_max_files" env:"RDXCLAW_TOOLS_KNOWLEDGE_INGEST_MAX_FILES"`
+	IngestMaxMB    int `json:"ingest_max_mb" env:"RDXCLAW_TOOLS_KNOWLEDGE_INGEST_MAX_MB"`
+}
+
+type CronToolsConfig struct {
+	// MaxAgentJobs caps how many jobs the agent may schedule itself.
+	MaxAgentJobs int `json:"max_agent_jobs" env:"RDXCLAW_TOOLS_CRON_MAX_AGENT_JOBS"`
+}
+
 type ToolsConfig struct {
-	Web WebToolsConfig `json:"web"`
+	Web       WebToolsConfig  `json:"web"`
+	Knowledge KnowledgeConfig `json:"knowledge"`
+	Cron      CronToolsConfig `json:"cron"`
 }
 
 func DefaultConfig() *Config {
@@ -189,7 +417,20 @@ func DefaultConfig() *Config {
 				Model:               "gpt-4o",
 				MaxTokens:           8192,
 				Temperature:         0.7,
+				SilentReplyToken:    DefaultSilentReplyToken,
 				MaxToolIterations:   20,
+
+				SummarizeHistory:       true,
+				SummarizeAfterMessages: 20,
+				SummarizeTokenPercent:  75,
+
+				SwarmMinHeadroomMB: 64,
+				SwarmMaxIterations: 10,
+
+				InjectDateTime: true,
+
+				MaxToolOutputChars: 16000,
+				StashToolOutput:    true,
 			},
 		},
 		Channels: ChannelsConfig{
@@ -232,18 +473,30 @@ func DefaultConfig() *Config {
 			VLLM:       ProviderConfig{},
 			Gemini:     ProviderConfig{},
 			Nvidia:     ProviderConfig{},
+
+			RequestTimeout: 120,
 		},
 		Gateway: GatewayConfig{
 			Host: "0.0.0.0",
 			Port: 18790,
 		},
 		API: APIConfig{
-			Enabled:     true,
-			Host:        "0.0.0.0",
-			Port:        8080,
-			APIKey:      "", // generated or set by user
-			RateLimit:   60,
-			CORSOrigins: FlexibleStringSlice{"*"},
+			Enabled:        true,
+			Host:           "0.0.0.0",
+			Port:           8080,
+			APIKey:         "", // generated or set by user
+			RateLimit:      60,
+			CORSOrigins:    FlexibleStringSlice{"*"},
+			EventRetention: 50,
+
+			WebhookReplaySize: 5,
+			RequestTimeout:    300,
+			MaxUploadMB:       10,
+			ShutdownDelay:     5,
+			Alerts: AlertsConfig{
+				Window:   300,
+				Cooldown: 900,
+			},
 		},
 		Tools: ToolsConfig{
 			Web: WebToolsConfig{
@@ -257,6 +510,12 @@ func DefaultConfig() *Config {
 					MaxResults: 5,
 				},
 			},
+			Knowledge: KnowledgeConfig{
+				SearchCacheSize: 128,
+			},
+			Cron: CronToolsConfig{
+				MaxAgentJobs: 20,
+			},
 		},
 		Heartbeat: HeartbeatConfig{
 			Enabled:  true,
@@ -266,6 +525,7 @@ func DefaultConfig() *Config {
 			Enabled:    false,
 			MonitorUSB: true,
 		},
+		Branding: DefaultBranding(),
 	}
 }
 
@@ -314,6 +574,30 @@ func (c *Config) WorkspacePath() string {
 	return expandHome(c.Agents.Defaults.Workspace)
 }
 
+// KnowledgeDir returns the knowledge store directory of a workspace.
+func KnowledgeDir(workspace string) string {
+	return filepath.Join(workspace, "knowledge")
+}
+
+// CronStorePath returns the file a workspace's cron jobs are kept in.
+func CronStorePath(workspace string) string {
+	return filepath.Join(workspace, "cron", "jobs.json")
+}
+
+// HistoryFilePath returns the interactive history file, or "" when history
+// should stay in memory.
+func (c *Config) HistoryFilePath() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.CLI.NoHistory {
+		return ""
+	}
+	if c.CLI.HistoryFile == "" {
+		return filepath.Join(os.TempDir(), ".RDXCLAW_history")
+	}
+	return expandHome(c.CLI.HistoryFile)
+}
+
 func (c *Config) GetAPIKey() string {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -356,10 +640,16 @@ func (c *Config) GetAPIBase() string {
 	return ""
 }
 
+// expandHome expands a leading ~ to the home directory. ~/.rdxclaw stands
+// for the data directory, so the default workspace follows it when the data
+// directory is moved (see BaseDir).
 func expandHome(path string) string {
 	if path == "" {
 		return path
 	}
+	if path == "~/.rdxclaw" || strings.HasPrefix(path, "~/.rdxclaw/") {
+		return BaseDir() + path[len("~/.rdxclaw"):]
+	}
 	if path[0] == '~' {
 		home, _ := os.UserHomeDir()
 		if len(path) > 1 && path[1] == '/' {
@@ -192,3 +192,21 @@ func TestConfig_Complete(t *testing.T) {
 		t.Error("Heartbeat should be enabled by default")
 	}
 }
+
+func TestIsSilentReply(t *testing.T) {
+	cases := []struct {
+		content, token string
+		want           bool
+	}{
+		{"NO_REPLY", "NO_REPLY", true},
+		{"  NO_REPLY\n", "NO_REPLY", true},
+		{"NO_REPLY, nothing new", "NO_REPLY", false},
+		{"", "", false},
+		{"NO_REPLY", "", false},
+	}
+	for _, c := range cases {
+		if got := IsSilentReply(c.content, c.token); got != c.want {
+			t.Errorf("IsSilentReply(%q, %q) = %v, want %v", c.content, c.token, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// DataDirEnv moves the data directory when none is set with SetDataDir.
+const DataDirEnv = "RDXCLAW_DATA_DIR"
+
+var (
+	dataDirMu sync.RWMutex
+	dataDir   string
+)
+
+// SetDataDir moves the data directory returned by BaseDir, overriding
+// DataDirEnv. Relative paths are made absolute; an empty dir falls back to
+// DataDirEnv.
+func SetDataDir(dir string) error {
+	if dir != "" {
+		abs, err := filepath.Abs(expandUserHome(dir))
+		if err != nil {
+			return err
+		}
+		dir = abs
+	}
+	dataDirMu.Lock()
+	defer dataDirMu.Unlock()
+	dataDir = dir
+	return nil
+}
+
+// DataDir returns the data directory chosen by SetDataDir or DataDirEnv,
+// or "" when it is the default ~/.rdxclaw.
+func DataDir() string {
+	dataDirMu.RLock()
+	dir := dataDir
+	dataDirMu.RUnlock()
+	if dir != "" {
+		return dir
+	}
+	if env := os.Getenv(DataDirEnv); env != "" {
+		if abs, err := filepath.Abs(expandUserHome(env)); err == nil {
+			return abs
+		}
+		return env
+	}
+	return ""
+}
+
+// CheckDataDir creates the data directory if needed and verifies that it
+// is writable, so a misconfigured service fails at startup rather than on
+// its first write.
+func CheckDataDir() error {
+	dir := BaseDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("data directory %s: %w", dir, err)
+	}
+	f, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return fmt.Errorf("data directory %s is not writable: %w", dir, err)
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// expandUserHome expands a leading ~ to the home directory. Unlike
+// expandHome it does not resolve ~/.rdxclaw, which depends on the data
+// directory being resolved here.
+func expandUserHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, _ := os.UserHomeDir()
+		return home + path[1:]
+	}
+	return path
+}
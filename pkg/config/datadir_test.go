@@ -0,0 +1,84 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDataDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(ProfileEnv, "")
+	t.Setenv(DataDirEnv, "")
+	defer SetDataDir("")
+
+	cfg := DefaultConfig()
+	if got, want := cfg.WorkspacePath(), filepath.Join(home, ".rdxclaw", "workspace"); got != want {
+		t.Errorf("default workspace = %q, want %q", got, want)
+	}
+
+	data := filepath.Join(t.TempDir(), "state")
+	t.Setenv(DataDirEnv, data)
+	if got := BaseDir(); got != data {
+		t.Errorf("BaseDir() with %s = %q, want %q", DataDirEnv, got, data)
+	}
+
+	override := filepath.Join(t.TempDir(), "service")
+	if err := SetDataDir(override); err != nil {
+		t.Fatal(err)
+	}
+	for name, got := range map[string]string{
+		"home":      HomeDir(),
+		"profile":   ProfileDir("work"),
+		"workspace": cfg.WorkspacePath(),
+		"knowledge": KnowledgeDir(cfg.WorkspacePath()),
+		"cron":      CronStorePath(cfg.WorkspacePath()),
+	} {
+		if rel, err := filepath.Rel(override, got); err != nil || strings.HasPrefix(rel, "..") {
+			t.Errorf("%s path %q is outside the data directory %q", name, got, override)
+		}
+	}
+
+	// Workspaces outside ~/.rdxclaw are left alone
+	cfg.Agents.Defaults.Workspace = "~/projects/agent"
+	if got, want := cfg.WorkspacePath(), filepath.Join(home, "projects", "agent"); got != want {
+		t.Errorf("workspace = %q, want %q", got, want)
+	}
+
+	path, err := CreateProfile("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := path, filepath.Join(override, "profiles", "work", "config.json"); got != want {
+		t.Errorf("profile config = %q, want %q", got, want)
+	}
+}
+
+func TestCheckDataDir(t *testing.T) {
+	t.Setenv(DataDirEnv, "")
+	defer SetDataDir("")
+
+	dir := filepath.Join(t.TempDir(), "new", "state")
+	if err := SetDataDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckDataDir(); err != nil {
+		t.Fatalf("CheckDataDir() = %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("the write check left files behind: %v", entries)
+	}
+
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetDataDir(filepath.Join(file, "state")); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckDataDir(); err == nil {
+		t.Error("expected an error for a data directory under a file")
+	}
+}
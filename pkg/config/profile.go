@@ -0,0 +1,136 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// ProfileEnv selects a profile when none is set with SetProfile.
+const ProfileEnv = "RDXCLAW_PROFILE"
+
+var (
+	// ErrProfileExists is returned when creating a profile that already exists.
+	ErrProfileExists = errors.New("profile already exists")
+	// ErrInvalidProfile is returned for profile names that are not safe
+	// directory names.
+	ErrInvalidProfile = errors.New("profile name must match [a-zA-Z0-9_-]+")
+)
+
+var (
+	profileMu     sync.RWMutex
+	activeProfile string
+
+	profileNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+)
+
+// SetProfile selects the profile used by HomeDir, overriding ProfileEnv.
+// An empty name falls back to ProfileEnv.
+func SetProfile(name string) error {
+	if name != "" && !profileNamePattern.MatchString(name) {
+		return ErrInvalidProfile
+	}
+	profileMu.Lock()
+	defer profileMu.Unlock()
+	activeProfile = name
+	return nil
+}
+
+// Profile returns the selected profile, or "" for the default setup. A
+// ProfileEnv value that is not a valid name is ignored, so it can never
+// lead outside the profiles directory; CheckProfile reports it.
+func Profile() string {
+	profileMu.RLock()
+	defer profileMu.RUnlock()
+	if activeProfile != "" {
+		return activeProfile
+	}
+	if name := os.Getenv(ProfileEnv); profileNamePattern.MatchString(name) {
+		return name
+	}
+	return ""
+}
+
+// CheckProfile verifies that ProfileEnv, unless overridden by SetProfile,
+// names a valid profile, so a bad value fails at startup instead of
+// silently selecting the default setup.
+func CheckProfile() error {
+	profileMu.RLock()
+	defer profileMu.RUnlock()
+	if name := os.Getenv(ProfileEnv); activeProfile == "" && name != "" && !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("%s: %w", ProfileEnv, ErrInvalidProfile)
+	}
+	return nil
+}
+
+// BaseDir returns the data directory, which holds the default setup and
+// all profiles: the one set with SetDataDir or DataDirEnv, or ~/.rdxclaw.
+func BaseDir() string {
+	if dir := DataDir(); dir != "" {
+		return dir
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".rdxclaw")
+}
+
+// HomeDir returns the directory holding the config, credentials and global
+// skills of the selected profile: ~/.rdxclaw/profiles/<name>, or
+// ~/.rdxclaw when no profile is selected.
+func HomeDir() string {
+	if profile := Profile(); profile != "" {
+		return ProfileDir(profile)
+	}
+	return BaseDir()
+}
+
+// ProfileDir returns the directory of a named profile.
+func ProfileDir(name string) string {
+	return filepath.Join(BaseDir(), "profiles", name)
+}
+
+// ListProfiles returns the names of the existing profiles, sorted.
+func ListProfiles() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(BaseDir(), "profiles"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(ProfileDir(entry.Name()), "config.json")); err == nil {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// CreateProfile writes a default config for a new profile whose workspace
+// lives inside the profile directory, and returns the config path.
+func CreateProfile(name string) (string, error) {
+	if !profileNamePattern.MatchString(name) {
+		return "", ErrInvalidProfile
+	}
+	dir := ProfileDir(name)
+	path := filepath.Join(dir, "config.json")
+	if _, err := os.Stat(path); err == nil {
+		return "", fmt.Errorf("%w: %s", ErrProfileExists, name)
+	}
+
+	cfg := DefaultConfig()
+	cfg.Agents.Defaults.Workspace = filepath.Join(dir, "workspace")
+	if err := SaveConfig(path, cfg); err != nil {
+		return "", err
+	}
+	return path, nil
+}
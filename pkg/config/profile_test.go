@@ -0,0 +1,83 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestProfiles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(ProfileEnv, "")
+	defer SetProfile("")
+
+	if got, want := HomeDir(), filepath.Join(home, ".rdxclaw"); got != want {
+		t.Errorf("HomeDir() without a profile = %q, want %q", got, want)
+	}
+
+	path, err := CreateProfile("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CreateProfile("work"); !errors.Is(err, ErrProfileExists) {
+		t.Errorf("expected ErrProfileExists, got %v", err)
+	}
+	if _, err := CreateProfile("../escape"); !errors.Is(err, ErrInvalidProfile) {
+		t.Errorf("expected ErrInvalidProfile, got %v", err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := cfg.WorkspacePath(), filepath.Join(home, ".rdxclaw", "profiles", "work", "workspace"); got != want {
+		t.Errorf("profile workspace = %q, want %q", got, want)
+	}
+
+	t.Setenv(ProfileEnv, "work")
+	if got, want := HomeDir(), filepath.Join(home, ".rdxclaw", "profiles", "work"); got != want {
+		t.Errorf("HomeDir() with %s = %q, want %q", ProfileEnv, got, want)
+	}
+	if err := SetProfile("personal"); err != nil {
+		t.Fatal(err)
+	}
+	if Profile() != "personal" {
+		t.Errorf("SetProfile should win over %s, got %q", ProfileEnv, Profile())
+	}
+
+	names, err := ListProfiles()
+	if err != nil || len(names) != 1 || names[0] != "work" {
+		t.Errorf("ListProfiles() = %v, %v", names, err)
+	}
+}
+
+func TestProfileEnvValidated(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	defer SetProfile("")
+
+	for _, name := range []string{"../x", "a/b", ".."} {
+		t.Setenv(ProfileEnv, name)
+		if err := CheckProfile(); !errors.Is(err, ErrInvalidProfile) {
+			t.Errorf("CheckProfile() with %s=%q = %v, want ErrInvalidProfile", ProfileEnv, name, err)
+		}
+		if got, want := HomeDir(), filepath.Join(home, ".rdxclaw"); got != want {
+			t.Errorf("HomeDir() with %s=%q = %q, want %q", ProfileEnv, name, got, want)
+		}
+	}
+
+	// --profile overrides the environment, bad value or not
+	if err := SetProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckProfile(); err != nil {
+		t.Errorf("CheckProfile() with --profile set = %v", err)
+	}
+
+	t.Setenv(ProfileEnv, "work")
+	SetProfile("")
+	if err := CheckProfile(); err != nil {
+		t.Errorf("CheckProfile() with a valid %s = %v", ProfileEnv, err)
+	}
+}
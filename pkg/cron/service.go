@@ -8,6 +8,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -29,13 +30,61 @@ type CronPayload struct {
 	Deliver bool   `json:"deliver"`
 	Channel string `json:"channel,omitempty"`
 	To      string `json:"to,omitempty"`
+
+	// Targets lists every place the job delivers to when there is more
+	// than one; Channel and To then hold the first of them.
+	Targets []DeliveryTarget `json:"targets,omitempty"`
+}
+
+// DeliveryTarget is a channel and a recipient (chat ID) on it.
+type DeliveryTarget struct {
+	Channel string `json:"channel"`
+	To      string `json:"to"`
+}
+
+func (t DeliveryTarget) String() string {
+	return t.Channel + ":" + t.To
+}
+
+// ParseDeliveryTarget parses "channel:recipient". The recipient may itself
+// contain colons.
+func ParseDeliveryTarget(s string) (DeliveryTarget, error) {
+	channel, to, ok := strings.Cut(s, ":")
+	if !ok || channel == "" || to == "" {
+		return DeliveryTarget{}, fmt.Errorf("invalid delivery target %q, want channel:recipient", s)
+	}
+	return DeliveryTarget{Channel: channel, To: to}, nil
+}
+
+// DeliveryTargets returns where the job delivers to: Targets when set,
+// otherwise the single Channel/To pair, or nil when neither is set. The
+// returned slice is a copy.
+func (p CronPayload) DeliveryTargets() []DeliveryTarget {
+	if len(p.Targets) > 0 {
+		return append([]DeliveryTarget(nil), p.Targets...)
+	}
+	if p.Channel == "" && p.To == "" {
+		return nil
+	}
+	return []DeliveryTarget{{Channel: p.Channel, To: p.To}}
+}
+
+// DeliveryResult records how delivering one run to one target went.
+type DeliveryResult struct {
+	Channel string `json:"channel"`
+	To      string `json:"to"`
+	Status  string `json:"status"` // "ok" or "error"
+	Error   string `json:"error,omitempty"`
 }
 
 type CronJobState struct {
 	NextRunAtMS *int64 `json:"nextRunAtMs,omitempty"`
 	LastRunAtMS *int64 `json:"lastRunAtMs,omitempty"`
-	LastStatus  string `json:"lastStatus,omitempty"`
+	LastStatus  string `json:"lastStatus,omitempty"` // "ok", "error" or "partial"
 	LastError   string `json:"lastError,omitempty"`
+
+	// LastDeliveries holds the per-target outcome of the last run.
+	LastDeliveries []DeliveryResult `json:"lastDeliveries,omitempty"`
 }
 
 type CronJob struct {
@@ -49,6 +98,37 @@ type CronJob struct {
 	UpdatedAtMS    int64        `json:"updatedAtMs"`
 	DeleteAfterRun bool         `json:"deleteAfterRun"`
 	SkillID        string       `json:"skillId,omitempty"` // Links job to owning skill (empty = user-created)
+
+	// CatchUp decides what happens to runs missed while the service was
+	// down; empty means CatchUpSkip.
+	CatchUp string `json:"catchUp,omitempty"`
+	// CreatedBy is CreatedByAgent for jobs the agent scheduled itself and
+	// empty for jobs created by a user or a skill.
+	CreatedBy string `json:"createdBy,omitempty"`
+}
+
+// CreatedByAgent tags jobs created through the agent's cron tool.
+const CreatedByAgent = "agent"
+
+// Catch-up policies for runs missed while the service was not running.
+const (
+	CatchUpSkip    = "skip"     // wait for the next scheduled time
+	CatchUpRunOnce = "run-once" // run once on startup, however many were missed
+	CatchUpRunAll  = "run-all"  // run every missed occurrence on startup
+)
+
+// maxCatchUpRuns bounds how many missed runs CatchUpRunAll replays, so a
+// short interval after a long outage cannot flood the channels.
+const maxCatchUpRuns = 100
+
+// ValidCatchUp reports whether policy is a known catch-up policy. The
+// empty string is valid and means CatchUpSkip.
+func ValidCatchUp(policy string) bool {
+	switch policy {
+	case "", CatchUpSkip, CatchUpRunOnce, CatchUpRunAll:
+		return true
+	}
+	return false
 }
 
 type CronStore struct {
@@ -56,6 +136,9 @@ type CronStore struct {
 	Jobs    []CronJob `json:"jobs"`
 }
 
+// JobHandler runs a due job. It receives a copy of the job; a handler
+// that delivers output may record the outcome per target in
+// job.State.LastDeliveries, which is saved with the job's state.
 type JobHandler func(job *CronJob) (string, error)
 
 type CronService struct {
@@ -91,6 +174,7 @@ func (cs *CronService) Start() error {
 		return fmt.Errorf("failed to load store: %w", err)
 	}
 
+	catchUp := cs.missedRuns(time.Now().UnixMilli())
 	cs.recomputeNextRuns()
 	if err := cs.saveStoreUnsafe(); err != nil {
 		return fmt.Errorf("failed to save store: %w", err)
@@ -98,11 +182,50 @@ func (cs *CronService) Start() error {
 
 	cs.stopChan = make(chan struct{})
 	cs.running = true
-	go cs.runLoop(cs.stopChan)
+	go cs.runLoop(cs.stopChan, catchUp)
 
 	return nil
 }
 
+// missedRuns returns the IDs of jobs to run on startup according to their
+// catch-up policy, one entry per run. A job missed a run when its stored
+// next run, or the occurrence after its last run, is in the past.
+func (cs *CronService) missedRuns(nowMS int64) []string {
+	var runs []string
+	for i := range cs.store.Jobs {
+		job := &cs.store.Jobs[i]
+		if !job.Enabled || job.CatchUp == "" || job.CatchUp == CatchUpSkip {
+			continue
+		}
+
+		next := job.State.NextRunAtMS
+		if next == nil && job.State.LastRunAtMS != nil {
+			next = cs.computeNextRun(&job.Schedule, *job.State.LastRunAtMS)
+		}
+		if next == nil && job.Schedule.Kind == "at" && job.State.LastRunAtMS == nil {
+			next = job.Schedule.AtMS
+		}
+		if next == nil || *next > nowMS {
+			continue
+		}
+
+		missed := 1
+		if job.CatchUp == CatchUpRunAll && job.Schedule.Kind != "at" {
+			t := cs.computeNextRun(&job.Schedule, *next)
+			for t != nil && *t <= nowMS && missed < maxCatchUpRuns {
+				missed++
+				t = cs.computeNextRun(&job.Schedule, *t)
+			}
+		}
+
+		log.Printf("[cron] job %s missed %d run(s), catching up (%s)", job.ID, missed, job.CatchUp)
+		for n := 0; n < missed; n++ {
+			runs = append(runs, job.ID)
+		}
+	}
+	return runs
+}
+
 func (cs *CronService) Stop() {
 	cs.mu.Lock()
 	defer cs.mu.Unlock()
@@ -118,7 +241,16 @@ func (cs *CronService) Stop() {
 	}
 }
 
-func (cs *CronService) runLoop(stopChan chan struct{}) {
+func (cs *CronService) runLoop(stopChan chan struct{}, catchUp []string) {
+	for _, jobID := range catchUp {
+		select {
+		case <-stopChan:
+			return
+		default:
+		}
+		cs.executeJobByID(jobID)
+	}
+
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
 
@@ -183,6 +315,9 @@ func (cs *CronService) executeJobByID(jobID string) {
 		job := &cs.store.Jobs[i]
 		if job.ID == jobID {
 			jobCopy := *job
+			// Deliveries are reported afresh each run; a run that delivers
+			// nothing must not inherit the previous run's outcome.
+			jobCopy.State.LastDeliveries = nil
 			callbackJob = &jobCopy
 			break
 		}
@@ -216,10 +351,17 @@ func (cs *CronService) executeJobByID(jobID string) {
 
 	job.State.LastRunAtMS = &startTime
 	job.UpdatedAtMS = time.Now().UnixMilli()
+	job.State.LastDeliveries = callbackJob.State.LastDeliveries
 
 	if err != nil {
 		job.State.LastStatus = "error"
 		job.State.LastError = err.Error()
+	} else if failed := failedDeliveries(job.State.LastDeliveries); len(failed) > 0 {
+		job.State.LastStatus = "partial"
+		if len(failed) == len(job.State.LastDeliveries) {
+			job.State.LastStatus = "error"
+		}
+		job.State.LastError = strings.Join(failed, "; ")
 	} else {
 		job.State.LastStatus = "ok"
 		job.State.LastError = ""
@@ -243,6 +385,63 @@ func (cs *CronService) executeJobByID(jobID string) {
 	}
 }
 
+// failedDeliveries describes each failed delivery as "channel:to: error".
+func failedDeliveries(results []DeliveryResult) []string {
+	var failed []string
+	for _, r := range results {
+		if r.Status != "ok" {
+			failed = append(failed, fmt.Sprintf("%s:%s: %s", r.Channel, r.To, r.Error))
+		}
+	}
+	return failed
+}
+
+// ValidateSchedule checks that a schedule can fire: a one-time job must be
+// in the future, an interval must be positive and a cron expression must
+// parse.
+func ValidateSchedule(schedule CronSchedule, nowMS int64) error {
+	switch schedule.Kind {
+	case "at":
+		if schedule.AtMS == nil || *schedule.AtMS <= nowMS {
+			return fmt.Errorf("one-time schedule must be in the future")
+		}
+	case "every":
+		if schedule.EveryMS == nil || *schedule.EveryMS <= 0 {
+			return fmt.Errorf("interval must be positive")
+		}
+	case "cron":
+		return ValidateExpr(schedule.Expr, schedule.TZ)
+	default:
+		return fmt.Errorf("unknown schedule kind %q", schedule.Kind)
+	}
+	return nil
+}
+
+// ValidateExpr checks that a cron expression parses and that tz, if set,
+// is a known IANA time zone such as "Europe/Berlin".
+func ValidateExpr(expr, tz string) error {
+	if !gronx.New().IsValid(expr) {
+		return fmt.Errorf("invalid cron expression %q", expr)
+	}
+	if _, err := scheduleLocation(tz); err != nil {
+		return err
+	}
+	return nil
+}
+
+// scheduleLocation returns the time zone a cron expression is evaluated
+// in: tz, or the local time zone when it is empty.
+func scheduleLocation(tz string) (*time.Location, error) {
+	if tz == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q", tz)
+	}
+	return loc, nil
+}
+
 func (cs *CronService) computeNextRun(schedule *CronSchedule, nowMS int64) *int64 {
 	if schedule.Kind == "at" {
 		if schedule.AtMS != nil && *schedule.AtMS > nowMS {
@@ -264,8 +463,14 @@ func (cs *CronService) computeNextRun(schedule *CronSchedule, nowMS int64) *int6
 			return nil
 		}
 
+		loc, err := scheduleLocation(schedule.TZ)
+		if err != nil {
+			log.Printf("[cron] %v, using local time for expr '%s'", err, schedule.Expr)
+			loc = time.Local
+		}
+
 		// Use gronx to calculate next run time
-		now := time.UnixMilli(nowMS)
+		now := time.UnixMilli(nowMS).In(loc)
 		nextTime, err := gronx.NextTickAfter(schedule.Expr, now, false)
 		if err != nil {
 			log.Printf("[cron] failed to compute next run for expr '%s': %v", schedule.Expr, err)
@@ -345,6 +550,19 @@ func (cs *CronService) saveStoreUnsafe() error {
 }
 
 func (cs *CronService) AddJob(name string, schedule CronSchedule, message string, deliver bool, channel, to string) (*CronJob, error) {
+	return cs.AddJobTargets(name, schedule, message, deliver, []DeliveryTarget{{Channel: channel, To: to}})
+}
+
+// AddJobTargets is AddJob for a job that delivers to several targets.
+func (cs *CronService) AddJobTargets(name string, schedule CronSchedule, message string, deliver bool, targets []DeliveryTarget) (*CronJob, error) {
+	var first DeliveryTarget
+	if len(targets) > 0 {
+		first = targets[0]
+	}
+	if len(targets) < 2 {
+		targets = nil
+	}
+
 	cs.mu.Lock()
 	defer cs.mu.Unlock()
 
@@ -362,8 +580,9 @@ func (cs *CronService) AddJob(name string, schedule CronSchedule, message string
 			Kind:    "agent_turn",
 			Message: message,
 			Deliver: deliver,
-			Channel: channel,
-			To:      to,
+			Channel: first.Channel,
+			To:      first.To,
+			Targets: targets,
 		},
 		State: CronJobState{
 			NextRunAtMS: cs.computeNextRun(&schedule, now),
@@ -448,6 +667,45 @@ func (cs *CronService) RemoveJobsBySkill(skillID string) int {
 	return removed
 }
 
+// CountJobsCreatedBy returns how many jobs carry the given CreatedBy tag.
+func (cs *CronService) CountJobsCreatedBy(creator string) int {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	count := 0
+	for _, job := range cs.store.Jobs {
+		if job.CreatedBy == creator {
+			count++
+		}
+	}
+	return count
+}
+
+// RemoveJobsCreatedBy removes every job with the given CreatedBy tag and
+// returns how many were removed.
+func (cs *CronService) RemoveJobsCreatedBy(creator string) int {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	before := len(cs.store.Jobs)
+	var jobs []CronJob
+	for _, job := range cs.store.Jobs {
+		if job.CreatedBy != creator {
+			jobs = append(jobs, job)
+		}
+	}
+	cs.store.Jobs = jobs
+	removed := before - len(cs.store.Jobs)
+
+	if removed > 0 {
+		if err := cs.saveStoreUnsafe(); err != nil {
+			log.Printf("[cron] failed to save store after job removal: %v", err)
+		}
+	}
+
+	return removed
+}
+
 func (cs *CronService) EnableJob(jobID string, enabled bool) *CronJob {
 	cs.mu.Lock()
 	defer cs.mu.Unlock()
@@ -4,7 +4,9 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestSaveStore_FilePermissions(t *testing.T) {
@@ -33,6 +35,214 @@ func TestSaveStore_FilePermissions(t *testing.T) {
 	}
 }
 
+func TestParseDeliveryTarget(t *testing.T) {
+	target, err := ParseDeliveryTarget("slack:C123:thread")
+	if err != nil {
+		t.Fatalf("ParseDeliveryTarget failed: %v", err)
+	}
+	if target.Channel != "slack" || target.To != "C123:thread" {
+		t.Errorf("got %+v, want slack / C123:thread", target)
+	}
+
+	for _, bad := range []string{"", "slack", "slack:", ":C123"} {
+		if _, err := ParseDeliveryTarget(bad); err == nil {
+			t.Errorf("ParseDeliveryTarget(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestExecuteJob_PartialDelivery(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "jobs.json")
+	cs := NewCronService(storePath, func(job *CronJob) (string, error) {
+		job.State.LastDeliveries = []DeliveryResult{
+			{Channel: "slack", To: "C1", Status: "ok"},
+			{Channel: "email", To: "ops@example.com", Status: "error", Error: "smtp down"},
+		}
+		return "ok", nil
+	})
+
+	job, err := cs.AddJobTargets("digest", CronSchedule{Kind: "every", EveryMS: int64Ptr(60000)}, "digest", true,
+		[]DeliveryTarget{{Channel: "slack", To: "C1"}, {Channel: "email", To: "ops@example.com"}})
+	if err != nil {
+		t.Fatalf("AddJobTargets failed: %v", err)
+	}
+	if job.Payload.Channel != "slack" || job.Payload.To != "C1" || len(job.Payload.Targets) != 2 {
+		t.Fatalf("unexpected payload %+v", job.Payload)
+	}
+
+	cs.executeJobByID(job.ID)
+
+	got := cs.ListJobs(true)[0]
+	if got.State.LastStatus != "partial" {
+		t.Errorf("LastStatus = %q, want partial", got.State.LastStatus)
+	}
+	if got.State.LastError != "email:ops@example.com: smtp down" {
+		t.Errorf("LastError = %q", got.State.LastError)
+	}
+	if len(got.State.LastDeliveries) != 2 {
+		t.Errorf("LastDeliveries = %+v, want 2 entries", got.State.LastDeliveries)
+	}
+
+	// A reloaded service sees the same run history.
+	reloaded := NewCronService(storePath, nil)
+	if n := len(reloaded.ListJobs(true)[0].State.LastDeliveries); n != 2 {
+		t.Errorf("reloaded LastDeliveries has %d entries, want 2", n)
+	}
+}
+
+func TestExecuteJob_ResetsDeliveries(t *testing.T) {
+	fail := true
+	cs := NewCronService(filepath.Join(t.TempDir(), "jobs.json"), func(job *CronJob) (string, error) {
+		if len(job.State.LastDeliveries) != 0 {
+			t.Errorf("handler saw the previous run's deliveries %+v", job.State.LastDeliveries)
+		}
+		if fail {
+			job.State.LastDeliveries = []DeliveryResult{{Channel: "slack", To: "C1", Status: "error", Error: "down"}}
+		}
+		return "ok", nil
+	})
+	job, err := cs.AddJob("digest", CronSchedule{Kind: "every", EveryMS: int64Ptr(60000)}, "digest", false, "slack", "C1")
+	if err != nil {
+		t.Fatalf("AddJob failed: %v", err)
+	}
+
+	cs.executeJobByID(job.ID)
+	if got := cs.ListJobs(true)[0].State.LastStatus; got != "error" {
+		t.Fatalf("LastStatus = %q, want error", got)
+	}
+
+	// The next run delivers nothing, so it must not repeat the failure.
+	fail = false
+	cs.executeJobByID(job.ID)
+	got := cs.ListJobs(true)[0]
+	if got.State.LastStatus != "ok" || len(got.State.LastDeliveries) != 0 {
+		t.Errorf("state = %+v, want ok with no deliveries", got.State)
+	}
+}
+
+func TestDeliveryTargets_SingleTargetFallback(t *testing.T) {
+	p := CronPayload{Channel: "telegram", To: "42"}
+	targets := p.DeliveryTargets()
+	if len(targets) != 1 || targets[0] != (DeliveryTarget{Channel: "telegram", To: "42"}) {
+		t.Errorf("DeliveryTargets() = %+v", targets)
+	}
+	if targets := (CronPayload{}).DeliveryTargets(); targets != nil {
+		t.Errorf("DeliveryTargets() of empty payload = %+v, want nil", targets)
+	}
+}
+
+// addMissedJob stores an hourly job that was last due 2.5 hours ago, as if
+// the service had been down across three scheduled runs.
+func addMissedJob(t *testing.T, storePath, policy string) {
+	t.Helper()
+	cs := NewCronService(storePath, nil)
+	job, err := cs.AddJob("report", CronSchedule{Kind: "every", EveryMS: int64Ptr(time.Hour.Milliseconds())}, "send the daily report", true, "cli", "direct")
+	if err != nil {
+		t.Fatalf("AddJob failed: %v", err)
+	}
+	job.CatchUp = policy
+	job.State.NextRunAtMS = int64Ptr(time.Now().Add(-150 * time.Minute).UnixMilli())
+	if err := cs.UpdateJob(job); err != nil {
+		t.Fatalf("UpdateJob failed: %v", err)
+	}
+}
+
+func TestStart_CatchUpPolicies(t *testing.T) {
+	tests := []struct {
+		policy string
+		runs   int32
+	}{
+		{policy: "", runs: 0},
+		{policy: CatchUpSkip, runs: 0},
+		{policy: CatchUpRunOnce, runs: 1},
+		{policy: CatchUpRunAll, runs: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run("policy="+tt.policy, func(t *testing.T) {
+			storePath := filepath.Join(t.TempDir(), "jobs.json")
+			addMissedJob(t, storePath, tt.policy)
+
+			var runs atomic.Int32
+			cs := NewCronService(storePath, func(job *CronJob) (string, error) {
+				runs.Add(1)
+				return "ok", nil
+			})
+			if err := cs.Start(); err != nil {
+				t.Fatalf("Start failed: %v", err)
+			}
+			defer cs.Stop()
+
+			deadline := time.Now().Add(2 * time.Second)
+			for runs.Load() < tt.runs && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+			// Give a skipped or over-eager catch-up the chance to show up.
+			time.Sleep(50 * time.Millisecond)
+			if got := runs.Load(); got != tt.runs {
+				t.Errorf("ran %d times, want %d", got, tt.runs)
+			}
+
+			job := cs.ListJobs(true)[0]
+			if job.State.NextRunAtMS == nil || *job.State.NextRunAtMS <= time.Now().UnixMilli() {
+				t.Errorf("next run should be rescheduled into the future, got %v", job.State.NextRunAtMS)
+			}
+		})
+	}
+}
+
+func TestMissedRuns_NotMissed(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "jobs.json")
+	cs := NewCronService(storePath, nil)
+	job, err := cs.AddJob("report", CronSchedule{Kind: "every", EveryMS: int64Ptr(time.Hour.Milliseconds())}, "report", true, "cli", "direct")
+	if err != nil {
+		t.Fatalf("AddJob failed: %v", err)
+	}
+	job.CatchUp = CatchUpRunAll
+	if err := cs.UpdateJob(job); err != nil {
+		t.Fatalf("UpdateJob failed: %v", err)
+	}
+
+	if runs := cs.missedRuns(time.Now().UnixMilli()); len(runs) != 0 {
+		t.Errorf("missedRuns() = %v, want none for a job due in the future", runs)
+	}
+}
+
+func TestValidateExpr(t *testing.T) {
+	tests := []struct {
+		expr, tz string
+		wantErr  bool
+	}{
+		{expr: "0 9 * * *"},
+		{expr: "0 9 * * *", tz: "America/New_York"},
+		{expr: "0 25 * * *", wantErr: true},
+		{expr: "0 9 * * *", tz: "Mars/Olympus_Mons", wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := ValidateExpr(tt.expr, tt.tz); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateExpr(%q, %q) = %v, wantErr %v", tt.expr, tt.tz, err, tt.wantErr)
+		}
+	}
+}
+
+func TestComputeNextRun_TimeZone(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	cs := NewCronService(filepath.Join(t.TempDir(), "jobs.json"), nil)
+	now := time.Date(2025, 12, 31, 12, 0, 0, 0, time.UTC) // 21:00 in Tokyo
+
+	next := cs.computeNextRun(&CronSchedule{Kind: "cron", Expr: "0 9 * * *", TZ: "Asia/Tokyo"}, now.UnixMilli())
+	if next == nil {
+		t.Fatal("computeNextRun() = nil")
+	}
+	want := time.Date(2026, 1, 1, 9, 0, 0, 0, tokyo)
+	if got := time.UnixMilli(*next); !got.Equal(want) {
+		t.Errorf("next run = %v, want %v", got.UTC(), want.UTC())
+	}
+}
+
 func int64Ptr(v int64) *int64 {
 	return &v
 }
@@ -0,0 +1,217 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Handler serves the liveness (/health) and readiness (/ready) endpoints.
+// It is shared by the standalone health Server used by the gateway and by
+// the API server, so both report health the same way.
+type Handler struct {
+	mu        sync.RWMutex
+	ready     bool
+	checks    map[string]Check
+	startTime time.Time
+	changed   chan struct{} // closed and replaced whenever readiness may have changed
+}
+
+// NewHandler returns a Handler that reports live but not ready until
+// SetReady(true) is called.
+func NewHandler() *Handler {
+	return &Handler{
+		checks:    make(map[string]Check),
+		startTime: time.Now(),
+		changed:   make(chan struct{}),
+	}
+}
+
+// Register mounts /health and /ready on mux.
+func (h *Handler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /health", h.healthHandler)
+	mux.HandleFunc("GET /ready", h.readyHandler)
+}
+
+// ServeHTTP dispatches /health and /ready, so the Handler can also be used
+// on its own.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/health":
+		h.healthHandler(w, r)
+	case "/ready":
+		h.readyHandler(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h *Handler) SetReady(ready bool) {
+	h.mu.Lock()
+	h.ready = ready
+	h.notifyLocked()
+	h.mu.Unlock()
+}
+
+func (h *Handler) RegisterCheck(name string, checkFn func() (bool, string)) {
+	status, msg := checkFn()
+	h.SetCheck(name, status, msg)
+}
+
+// SetCheck records the latest result of a named check.
+func (h *Handler) SetCheck(name string, ok bool, message string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.checks[name] = Check{
+		Name:      name,
+		Status:    statusString(ok),
+		Message:   message,
+		Timestamp: time.Now(),
+	}
+	h.notifyLocked()
+}
+
+func (h *Handler) notifyLocked() {
+	close(h.changed)
+	h.changed = make(chan struct{})
+}
+
+// IsReady reports whether the process is ready and every check passes. When
+// not ready, reason explains why.
+func (h *Handler) IsReady() (ready bool, reason string) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.isReadyLocked()
+}
+
+func (h *Handler) isReadyLocked() (bool, string) {
+	if !h.ready {
+		return false, "starting"
+	}
+	var failing []string
+	for name, check := range h.checks {
+		if check.Status == "fail" {
+			if check.Message != "" {
+				failing = append(failing, name+": "+check.Message)
+			} else {
+				failing = append(failing, name)
+			}
+		}
+	}
+	if len(failing) > 0 {
+		sort.Strings(failing)
+		return false, strings.Join(failing, "; ")
+	}
+	return true, ""
+}
+
+// WaitReady blocks until IsReady reports true or ctx is done.
+func (h *Handler) WaitReady(ctx context.Context) error {
+	for {
+		h.mu.RLock()
+		ready, _ := h.isReadyLocked()
+		changed := h.changed
+		h.mu.RUnlock()
+
+		if ready {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// Monitor runs check immediately and then every interval until ctx is done,
+// recording each result as the named check. A failing check makes /ready
+// report not ready until it passes again.
+func (h *Handler) Monitor(ctx context.Context, name string, interval time.Duration, check func(context.Context) error) {
+	run := func() {
+		checkCtx, cancel := context.WithTimeout(ctx, interval)
+		defer cancel()
+		if err := check(checkCtx); err != nil {
+			h.SetCheck(name, false, err.Error())
+		} else {
+			h.SetCheck(name, true, "")
+		}
+	}
+
+	run()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			run()
+		}
+	}
+}
+
+func (h *Handler) healthHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	uptime := time.Since(h.startTime)
+	resp := StatusResponse{
+		Status: "ok",
+		Uptime: uptime.String(),
+	}
+
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (h *Handler) readyHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	h.mu.RLock()
+	ready := h.ready
+	checks := make(map[string]Check)
+	for k, v := range h.checks {
+		checks[k] = v
+	}
+	h.mu.RUnlock()
+
+	if !ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(StatusResponse{
+			Status: "not ready",
+			Checks: checks,
+		})
+		return
+	}
+
+	for _, check := range checks {
+		if check.Status == "fail" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(StatusResponse{
+				Status: "not ready",
+				Checks: checks,
+			})
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	uptime := time.Since(h.startTime)
+	json.NewEncoder(w).Encode(StatusResponse{
+		Status: "ready",
+		Uptime: uptime.String(),
+		Checks: checks,
+	})
+}
+
+func statusString(ok bool) string {
+	if ok {
+		return "ok"
+	}
+	return "fail"
+}
@@ -0,0 +1,104 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func get(t *testing.T, h http.Handler, path string) (int, StatusResponse) {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+
+	var resp StatusResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode %s: %v", path, err)
+	}
+	return rr.Code, resp
+}
+
+func TestHandlerLiveAndReady(t *testing.T) {
+	h := NewHandler()
+	mux := http.NewServeMux()
+	h.Register(mux)
+
+	for name, handler := range map[string]http.Handler{"direct": h, "mux": mux} {
+		t.Run(name, func(t *testing.T) {
+			h.SetReady(false)
+
+			if code, resp := get(t, handler, "/health"); code != http.StatusOK || resp.Status != "ok" {
+				t.Errorf("/health = %d %q, want 200 ok", code, resp.Status)
+			}
+			if code, resp := get(t, handler, "/ready"); code != http.StatusServiceUnavailable || resp.Status != "not ready" {
+				t.Errorf("/ready before SetReady = %d %q, want 503 not ready", code, resp.Status)
+			}
+
+			h.SetReady(true)
+			if code, resp := get(t, handler, "/ready"); code != http.StatusOK || resp.Status != "ready" {
+				t.Errorf("/ready = %d %q, want 200 ready", code, resp.Status)
+			}
+		})
+	}
+}
+
+func TestHandlerFailingCheck(t *testing.T) {
+	h := NewHandler()
+	h.SetReady(true)
+	h.RegisterCheck("provider", func() (bool, string) { return false, "unreachable" })
+
+	code, resp := get(t, h, "/ready")
+	if code != http.StatusServiceUnavailable {
+		t.Fatalf("/ready = %d, want 503", code)
+	}
+	if resp.Checks["provider"].Message != "unreachable" {
+		t.Errorf("expected failing check in response, got %+v", resp.Checks)
+	}
+
+	// Liveness does not depend on checks.
+	if code, _ := get(t, h, "/health"); code != http.StatusOK {
+		t.Errorf("/health = %d, want 200", code)
+	}
+}
+
+func TestHandlerMonitorUnreachableProvider(t *testing.T) {
+	h := NewHandler()
+	h.SetReady(true)
+	h.SetCheck("provider", false, "pending")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var reachable atomic.Bool
+	go h.Monitor(ctx, "provider", 10*time.Millisecond, func(context.Context) error {
+		if reachable.Load() {
+			return nil
+		}
+		return errors.New("dial tcp: connection refused")
+	})
+
+	waitCtx, waitCancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer waitCancel()
+	if err := h.WaitReady(waitCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("WaitReady() = %v, want deadline exceeded while provider is unreachable", err)
+	}
+	if ready, reason := h.IsReady(); ready || !strings.Contains(reason, "connection refused") {
+		t.Errorf("IsReady() = %v, %q; want not ready with provider reason", ready, reason)
+	}
+	if code, _ := get(t, h, "/ready"); code != http.StatusServiceUnavailable {
+		t.Errorf("/ready = %d, want 503", code)
+	}
+
+	reachable.Store(true)
+	readyCtx, readyCancel := context.WithTimeout(ctx, time.Second)
+	defer readyCancel()
+	if err := h.WaitReady(readyCtx); err != nil {
+		t.Fatalf("WaitReady() = %v after provider recovered", err)
+	}
+}
@@ -2,19 +2,17 @@ package health
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
-	"sync"
 	"time"
 )
 
+// Server is a standalone listener for the health endpoints, used when no
+// API server is running to mount the Handler.
 type Server struct {
-	server    *http.Server
-	mu        sync.RWMutex
-	ready     bool
-	checks    map[string]Check
-	startTime time.Time
+	*Handler
+	server *http.Server
 }
 
 type Check struct {
@@ -31,42 +29,46 @@ type StatusResponse struct {
 }
 
 func NewServer(host string, port int) *Server {
-	mux := http.NewServeMux()
-	s := &Server{
-		ready:     false,
-		checks:    make(map[string]Check),
-		startTime: time.Now(),
-	}
+	return NewServerWithHandler(host, port, NewHandler())
+}
 
-	mux.HandleFunc("/health", s.healthHandler)
-	mux.HandleFunc("/ready", s.readyHandler)
+// NewServerWithHandler creates a Server that serves an existing Handler.
+func NewServerWithHandler(host string, port int, h *Handler) *Server {
+	mux := http.NewServeMux()
+	h.Register(mux)
 
 	addr := fmt.Sprintf("%s:%d", host, port)
-	s.server = &http.Server{
-		Addr:         addr,
-		Handler:      mux,
-		ReadTimeout:  5 * time.Second,
-		WriteTimeout: 5 * time.Second,
+	return &Server{
+		Handler: h,
+		server: &http.Server{
+			Addr:         addr,
+			Handler:      mux,
+			ReadTimeout:  5 * time.Second,
+			WriteTimeout: 5 * time.Second,
+		},
 	}
-
-	return s
 }
 
+// Start binds the listener, marks the handler ready and serves until Stop.
 func (s *Server) Start() error {
-	s.mu.Lock()
-	s.ready = true
-	s.mu.Unlock()
-	return s.server.ListenAndServe()
+	ln, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return err
+	}
+	s.SetReady(true)
+	return s.server.Serve(ln)
 }
 
 func (s *Server) StartContext(ctx context.Context) error {
-	s.mu.Lock()
-	s.ready = true
-	s.mu.Unlock()
+	ln, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return err
+	}
+	s.SetReady(true)
 
 	errCh := make(chan error, 1)
 	go func() {
-		errCh <- s.server.ListenAndServe()
+		errCh <- s.server.Serve(ln)
 	}()
 
 	select {
@@ -78,87 +80,6 @@ func (s *Server) StartContext(ctx context.Context) error {
 }
 
 func (s *Server) Stop(ctx context.Context) error {
-	s.mu.Lock()
-	s.ready = false
-	s.mu.Unlock()
+	s.SetReady(false)
 	return s.server.Shutdown(ctx)
 }
-
-func (s *Server) SetReady(ready bool) {
-	s.mu.Lock()
-	s.ready = ready
-	s.mu.Unlock()
-}
-
-func (s *Server) RegisterCheck(name string, checkFn func() (bool, string)) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	status, msg := checkFn()
-	s.checks[name] = Check{
-		Name:      name,
-		Status:    statusString(status),
-		Message:   msg,
-		Timestamp: time.Now(),
-	}
-}
-
-func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-
-	uptime := time.Since(s.startTime)
-	resp := StatusResponse{
-		Status: "ok",
-		Uptime: uptime.String(),
-	}
-
-	json.NewEncoder(w).Encode(resp)
-}
-
-func (s *Server) readyHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-
-	s.mu.RLock()
-	ready := s.ready
-	checks := make(map[string]Check)
-	for k, v := range s.checks {
-		checks[k] = v
-	}
-	s.mu.RUnlock()
-
-	if !ready {
-		w.WriteHeader(http.StatusServiceUnavailable)
-		json.NewEncoder(w).Encode(StatusResponse{
-			Status: "not ready",
-			Checks: checks,
-		})
-		return
-	}
-
-	for _, check := range checks {
-		if check.Status == "fail" {
-			w.WriteHeader(http.StatusServiceUnavailable)
-			json.NewEncoder(w).Encode(StatusResponse{
-				Status: "not ready",
-				Checks: checks,
-			})
-			return
-		}
-	}
-
-	w.WriteHeader(http.StatusOK)
-	uptime := time.Since(s.startTime)
-	json.NewEncoder(w).Encode(StatusResponse{
-		Status: "ready",
-		Uptime: uptime.String(),
-		Checks: checks,
-	})
-}
-
-func statusString(ok bool) string {
-	if ok {
-		return "ok"
-	}
-	return "fail"
-}
@@ -0,0 +1,177 @@
+package knowledge
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// QueryLogEntry is one search recorded in a collection's query log.
+type QueryLogEntry struct {
+	Query     string    `json:"query"`
+	Results   int       `json:"results"`
+	TopScore  float64   `json:"top_score"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// QueryStat summarizes the searches for one (normalized) query.
+type QueryStat struct {
+	Query    string    `json:"query"`
+	Count    int       `json:"count"`
+	TopScore float64   `json:"top_score"` // best top score seen
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// QueryAnalytics summarizes a collection's query log over a window.
+type QueryAnalytics struct {
+	Collection        string      `json:"collection"`
+	Since             time.Time   `json:"since"`
+	TotalQueries      int         `json:"total_queries"`
+	ZeroResultQueries int         `json:"zero_result_queries"`
+	TopQueries        []QueryStat `json:"top_queries"`
+	ZeroResults       []QueryStat `json:"zero_results"`
+}
+
+// SetQueryLogging turns the query log of a collection on or off. Logging is
+// off by default because queries may contain personal data.
+func (s *Store) SetQueryLogging(collection string, enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	collection = strings.ToLower(strings.TrimSpace(collection))
+	if enabled {
+		s.queryLogs[collection] = true
+	} else {
+		delete(s.queryLogs, collection)
+	}
+}
+
+func (s *Store) queryLogEnabled(collection string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.queryLogs[collection]
+}
+
+func (s *Store) queryLogPath(collection string) string {
+	return filepath.Join(s.baseDir, collection+".queries.jsonl")
+}
+
+// RecordQuery appends a search to the collection's query log. It does
+// nothing unless logging was enabled with SetQueryLogging.
+func (s *Store) RecordQuery(collection, query string, results []SearchResult) error {
+	collection = strings.ToLower(strings.TrimSpace(collection))
+	if !s.queryLogEnabled(collection) {
+		return nil
+	}
+
+	entry := QueryLogEntry{Query: query, Results: len(results), Timestamp: time.Now()}
+	if len(results) > 0 {
+		entry.TopScore = results[0].Score
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	s.logMu.Lock()
+	defer s.logMu.Unlock()
+
+	f, err := os.OpenFile(s.queryLogPath(collection), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open query log: %w", err)
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// QueryAnalytics summarizes the queries logged for a collection since the
+// given time: the limit most frequent queries and the limit most frequent
+// ones that returned nothing.
+func (s *Store) QueryAnalytics(collection string, since time.Time, limit int) (*QueryAnalytics, error) {
+	collection = strings.ToLower(strings.TrimSpace(collection))
+	if collection == "" {
+		return nil, ErrInvalidCollection
+	}
+	report := &QueryAnalytics{
+		Collection:  collection,
+		Since:       since,
+		TopQueries:  []QueryStat{},
+		ZeroResults: []QueryStat{},
+	}
+
+	s.logMu.Lock()
+	defer s.logMu.Unlock()
+
+	f, err := os.Open(s.queryLogPath(collection))
+	if os.IsNotExist(err) {
+		return report, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open query log: %w", err)
+	}
+	defer f.Close()
+
+	all := make(map[string]*QueryStat)
+	empty := make(map[string]*QueryStat)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry QueryLogEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil || entry.Timestamp.Before(since) {
+			continue
+		}
+		report.TotalQueries++
+		addQueryStat(all, entry)
+		if entry.Results == 0 {
+			report.ZeroResultQueries++
+			addQueryStat(empty, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read query log: %w", err)
+	}
+
+	report.TopQueries = rankQueryStats(all, limit)
+	report.ZeroResults = rankQueryStats(empty, limit)
+	return report, nil
+}
+
+func addQueryStat(stats map[string]*QueryStat, entry QueryLogEntry) {
+	key := strings.ToLower(strings.Join(strings.Fields(entry.Query), " "))
+	st, ok := stats[key]
+	if !ok {
+		st = &QueryStat{Query: key}
+		stats[key] = st
+	}
+	st.Count++
+	if entry.TopScore > st.TopScore {
+		st.TopScore = entry.TopScore
+	}
+	if entry.Timestamp.After(st.LastSeen) {
+		st.LastSeen = entry.Timestamp
+	}
+}
+
+// rankQueryStats orders stats by count, most recent first on ties, and
+// keeps at most limit of them (all when limit <= 0).
+func rankQueryStats(stats map[string]*QueryStat, limit int) []QueryStat {
+	ranked := make([]QueryStat, 0, len(stats))
+	for _, st := range stats {
+		ranked = append(ranked, *st)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Count != ranked[j].Count {
+			return ranked[i].Count > ranked[j].Count
+		}
+		return ranked[i].LastSeen.After(ranked[j].LastSeen)
+	})
+	if limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
@@ -0,0 +1,80 @@
+package knowledge
+
+import (
+	"container/list"
+	"sync"
+)
+
+// searchCache is a bounded LRU of recent search results. Searches run
+// concurrently under the index's read lock, so the cache has its own mutex.
+type searchCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // front is most recently used
+	entries map[searchKey]*list.Element
+}
+
+type searchKey struct {
+	query  string
+	limit  int
+	offset int
+	perDoc int // chunks kept per document; 0 means ungrouped
+}
+
+type cacheEntry struct {
+	key  searchKey
+	page SearchPage
+}
+
+func newSearchCache(size int) *searchCache {
+	return &searchCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[searchKey]*list.Element),
+	}
+}
+
+func (c *searchCache) get(key searchKey) (SearchPage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return SearchPage{}, false
+	}
+	c.order.MoveToFront(el)
+	return copyPage(el.Value.(*cacheEntry).page), true
+}
+
+func (c *searchCache) put(key searchKey, page SearchPage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*cacheEntry).page = copyPage(page)
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, page: copyPage(page)})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+func (c *searchCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	clear(c.entries)
+}
+
+// copyPage keeps callers from mutating cached slices.
+func copyPage(page SearchPage) SearchPage {
+	results := make([]SearchResult, len(page.Results))
+	copy(results, page.Results)
+	page.Results = results
+	return page
+}
@@ -0,0 +1,81 @@
+package knowledge
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CompactResult reports what compacting a collection reclaimed.
+type CompactResult struct {
+	Collection      string `json:"collection"`
+	PostingsRemoved int    `json:"postings_removed"`
+	BytesBefore     int64  `json:"bytes_before"`
+	BytesAfter      int64  `json:"bytes_after"`
+}
+
+// BytesSaved returns how much smaller the index file became.
+func (r CompactResult) BytesSaved() int64 {
+	return r.BytesBefore - r.BytesAfter
+}
+
+// Compact rebuilds the postings, chunk lengths and counts from the chunks
+// the index holds, dropping whatever refers to chunks that are gone, such
+// as postings left behind by versions that did not clean up on replace.
+// It returns how many postings were dropped.
+func (idx *Index) Compact() int {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	before := 0
+	for _, postings := range idx.InvertedIdx {
+		before += len(postings)
+	}
+	idx.rebuildLocked()
+	idx.DocCount = len(idx.Docs)
+
+	after := 0
+	for _, postings := range idx.InvertedIdx {
+		after += len(postings)
+	}
+	return before - after
+}
+
+// Compact rebuilds an existing collection's index (see Index.Compact) and
+// saves it, atomically replacing the old file. Changes awaiting Flush are
+// saved with it.
+func (s *Store) Compact(collection string) (CompactResult, error) {
+	name := strings.ToLower(strings.TrimSpace(collection))
+	if name == "" {
+		return CompactResult{}, ErrInvalidCollection
+	}
+	if !s.hasCollection(name) {
+		return CompactResult{}, ErrCollectionNotFound
+	}
+
+	idx, err := s.GetIndex(name)
+	if err != nil {
+		return CompactResult{}, err
+	}
+
+	path := filepath.Join(s.baseDir, name+".index.json")
+	result := CompactResult{Collection: name, BytesBefore: fileSize(path)}
+	result.PostingsRemoved = idx.Compact()
+	if err := idx.Save(s.baseDir); err != nil {
+		return result, err
+	}
+	s.mu.Lock()
+	delete(s.dirty, name)
+	s.mu.Unlock()
+	result.BytesAfter = fileSize(path)
+	return result, nil
+}
+
+// fileSize returns the size of a file, or 0 when it cannot be read.
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
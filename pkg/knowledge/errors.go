@@ -0,0 +1,28 @@
+package knowledge
+
+import "errors"
+
+var (
+	// ErrInvalidCollection is returned when a collection name is empty.
+	ErrInvalidCollection = errors.New("index name cannot be empty")
+
+	// ErrCollectionNotFound is returned when reading a collection that
+	// does not exist.
+	ErrCollectionNotFound = errors.New("collection not found")
+
+	// ErrUnsupportedType is returned when no text can be extracted from a
+	// file.
+	ErrUnsupportedType = errors.New("unsupported document type")
+
+	// ErrDocumentNotFound is returned when deleting a document that is not
+	// indexed.
+	ErrDocumentNotFound = errors.New("document not found")
+
+	// ErrEmptyQuery is returned when a search query is empty or has only
+	// whitespace and punctuation, leaving nothing to match.
+	ErrEmptyQuery = errors.New("query has no searchable terms")
+
+	// ErrIngestTooLarge is returned when a directory holds more files or
+	// bytes than one ingest may index.
+	ErrIngestTooLarge = errors.New("directory is too large to ingest")
+)
@@ -0,0 +1,32 @@
+package knowledge
+
+import (
+	"fmt"
+	"mime"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Sterlites/RDxClaw/pkg/utils"
+)
+
+// ExtractText turns an uploaded file into indexable text, choosing the
+// extractor from the file extension and falling back to the content type.
+// It returns the text and the document type recorded on the Document.
+func ExtractText(filename, contentType string, data []byte) (string, string, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	switch ext := strings.ToLower(filepath.Ext(filename)); {
+	case ext == ".html" || ext == ".htm" || mediaType == "text/html":
+		return utils.HTMLToText(string(data)), "html", nil
+	case ext == ".pdf" || mediaType == "application/pdf":
+		return "", "", fmt.Errorf("%w: PDF text extraction is not available", ErrUnsupportedType)
+	case ext == ".md" || ext == ".markdown" || mediaType == "text/markdown":
+		return string(data), "markdown", nil
+	}
+
+	if !utf8.Valid(data) {
+		return "", "", fmt.Errorf("%w: %s is not a text file", ErrUnsupportedType, filename)
+	}
+	return string(data), "text", nil
+}
@@ -1,12 +1,14 @@
 package knowledge
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"math"
 	"os"
 	"path/filepath"
-	"regexp"
+	"runtime"
 	"sort"
 	"strings"
 	"sync"
@@ -36,7 +38,14 @@ type Index struct {
 	DocLengths  map[string]int       `json:"doc_lengths"`  // Map of ChunkID -> WordCount
 	DocCount    int                  `json:"doc_count"`
 	SumDocLen   int                  `json:"sum_doc_len"` // Sum of all document lengths
-	mu          sync.RWMutex
+	// Tokenizer is one of the Tokenizer constants. Indexes saved before it
+	// existed have none and are re-tokenized when loaded.
+	Tokenizer string `json:"tokenizer,omitempty"`
+	mu        sync.RWMutex
+	saveMu    sync.Mutex // serializes Save
+
+	// cache holds recent search results; nil when caching is disabled.
+	cache *searchCache
 }
 
 // NewIndex creates a new search index.
@@ -46,62 +55,402 @@ func NewIndex(name string) *Index {
 		Docs:        make(map[string]Chunk),
 		InvertedIdx: make(map[string][]Posting),
 		DocLengths:  make(map[string]int),
+		Tokenizer:   TokenizerUnicode,
 	}
 }
 
-// AddDocument chunks a document and adds it to the index.
-func (idx *Index) AddDocument(doc Document) error {
+// SetTokenizer switches the index to another tokenizer (see the Tokenizer
+// constants), re-tokenizing every chunk already indexed.
+func (idx *Index) SetTokenizer(mode string) error {
+	mode, err := normalizeTokenizer(mode)
+	if err != nil {
+		return err
+	}
+
 	idx.mu.Lock()
 	defer idx.mu.Unlock()
 
-	chunks := chunkText(doc.Content, chunkSize, chunkOverlap)
-	for i, content := range chunks {
-		chunkID := fmt.Sprintf("%s_chk_%d", doc.ID, i)
-		chunk := Chunk{
-			ID:         chunkID,
-			DocumentID: doc.ID,
-			Content:    content,
-			Index:      i,
-			Metadata:   doc.Metadata,
-		}
-
-		// Store chunk
-		idx.Docs[chunkID] = chunk
-
-		// Tokenize and calculate TF
-		tokens := tokenize(content)
-		docLen := len(tokens)
-		idx.DocLengths[chunkID] = docLen
-		idx.SumDocLen += docLen
-		idx.DocCount++
-
-		termFreqs := make(map[string]int)
-		for _, token := range tokens {
-			termFreqs[token]++
-		}
-
-		// Update inverted index with postings
-		for term, count := range termFreqs {
-			idx.InvertedIdx[term] = append(idx.InvertedIdx[term], Posting{
-				ChunkID: chunkID,
-				TF:      count,
-			})
+	if idx.Tokenizer == mode {
+		return nil
+	}
+	idx.Tokenizer = mode
+	idx.rebuildLocked()
+	return nil
+}
+
+// tokenize splits text with the index's tokenizer.
+func (idx *Index) tokenize(text string) []string {
+	return tokenizeWith(idx.Tokenizer, text)
+}
+
+// rebuildLocked recomputes the postings and lengths of every chunk, e.g.
+// after the tokenizer changed. Callers hold idx.mu for writing.
+func (idx *Index) rebuildLocked() {
+	if idx.cache != nil {
+		idx.cache.clear()
+	}
+	idx.InvertedIdx = make(map[string][]Posting)
+	idx.DocLengths = make(map[string]int, len(idx.Docs))
+	idx.SumDocLen = 0
+	for chunkID, chunk := range idx.Docs {
+		idx.indexChunkLocked(chunkID, idx.tokenize(chunk.Content))
+	}
+}
+
+// SetCacheSize enables an LRU cache of the n most recent (query, limit)
+// searches. The cache is cleared whenever the index changes; n <= 0
+// disables it.
+func (idx *Index) SetCacheSize(n int) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if n <= 0 {
+		idx.cache = nil
+		return
+	}
+	idx.cache = newSearchCache(n)
+}
+
+// AddDocument chunks a document and adds it to the index. Chunks of an
+// earlier version with the same ID are replaced.
+func (idx *Index) AddDocument(doc Document) error {
+	return idx.addDocument(doc, strings.NewReader(doc.Content), nil)
+}
+
+// AddDocumentReader is AddDocument for content read from r. The content is
+// chunked as it is read, so it is never held in memory as a whole;
+// doc.Content is ignored.
+func (idx *Index) AddDocumentReader(doc Document, r io.Reader) error {
+	return idx.addDocument(doc, r, nil)
+}
+
+// addDocument indexes the content read from r as doc, passing it through
+// transform first when that is set. A read error leaves the index as it
+// was, including any earlier version of the document.
+func (idx *Index) addDocument(doc Document, r io.Reader, transform func(string) string) error {
+	chunked, err := idx.chunkDocument(doc, r, transform)
+	if err != nil {
+		return err
+	}
+	idx.addChunked(chunked)
+	return nil
+}
+
+// chunkedDocument is a document split into tokenized chunks, ready to be
+// added to an index without further reading.
+type chunkedDocument struct {
+	doc       Document
+	spans     []span
+	tokens    [][]string // tokens of each span
+	tokenizer string     // the tokenizer that produced tokens
+
+	// upsert also replaces documents from the same source under other IDs.
+	upsert bool
+}
+
+// chunkDocument reads, chunks and tokenizes a document without holding
+// the index lock, so several documents can be prepared at once. A
+// transform, such as PII redaction, sees the whole text before it is
+// chunked, since a match may straddle a chunk boundary.
+func (idx *Index) chunkDocument(doc Document, r io.Reader, transform func(string) string) (*chunkedDocument, error) {
+	idx.mu.RLock()
+	mode := idx.Tokenizer
+	idx.mu.RUnlock()
+
+	if transform != nil {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read document %s: %w", doc.ID, err)
 		}
+		r = strings.NewReader(transform(string(data)))
+	}
+
+	chunked := &chunkedDocument{doc: doc, tokenizer: mode}
+	err := chunkStream(r, chunkSize, chunkOverlap, func(span span) error {
+		chunked.spans = append(chunked.spans, span)
+		chunked.tokens = append(chunked.tokens, tokenizeWith(mode, span.text))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read document %s: %w", doc.ID, err)
 	}
+	return chunked, nil
+}
+
+// UpsertDocument is AddDocument that also replaces every document from the
+// same source, whatever its ID, so a file ingested again under another ID
+// is not indexed twice. Documents without a source, or added by hand, are
+// matched by ID only.
+func (idx *Index) UpsertDocument(doc Document) error {
+	return idx.upsertDocument(doc, strings.NewReader(doc.Content), nil)
+}
+
+// UpsertDocumentReader is UpsertDocument for content read from r.
+func (idx *Index) UpsertDocumentReader(doc Document, r io.Reader) error {
+	return idx.upsertDocument(doc, r, nil)
+}
 
+func (idx *Index) upsertDocument(doc Document, r io.Reader, transform func(string) string) error {
+	chunked, err := idx.chunkDocument(doc, r, transform)
+	if err != nil {
+		return err
+	}
+	chunked.upsert = true
+	idx.addChunked(chunked)
 	return nil
 }
 
-// Search searches the index using BM25.
+// addChunked adds a chunked document, replacing an earlier version with
+// the same ID and, when upserting, those from the same source.
+func (idx *Index) addChunked(chunked *chunkedDocument) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if idx.cache != nil {
+		idx.cache.clear()
+	}
+	idx.removeDocumentLocked(chunked.doc.ID)
+	if chunked.upsert {
+		for _, docID := range idx.documentsFromLocked(chunked.doc.Source) {
+			idx.removeDocumentLocked(docID)
+		}
+	}
+
+	// The tokenizer may have changed while the document was chunked
+	retokenize := chunked.tokenizer != idx.Tokenizer
+	for i, span := range chunked.spans {
+		tokens := chunked.tokens[i]
+		if retokenize {
+			tokens = idx.tokenize(span.text)
+		}
+		idx.addChunkLocked(chunked.doc, i, span, tokens)
+	}
+}
+
+// addChunkLocked indexes the i-th chunk of a document, whose text splits
+// into tokens. Callers hold idx.mu for writing.
+func (idx *Index) addChunkLocked(doc Document, i int, span span, tokens []string) {
+	chunkID := fmt.Sprintf("%s_chk_%d", doc.ID, i)
+	content := span.text
+	chunk := Chunk{
+		ID:         chunkID,
+		DocumentID: doc.ID,
+		Content:    content,
+		Index:      i,
+		Metadata:   doc.Metadata,
+		Source:     doc.Source,
+		Title:      doc.Title,
+		Start:      span.start,
+		End:        span.end,
+		StartLine:  span.startLine,
+		EndLine:    span.endLine,
+	}
+
+	// Store chunk
+	idx.Docs[chunkID] = chunk
+
+	idx.DocCount++
+	idx.indexChunkLocked(chunkID, tokens)
+}
+
+// indexChunkLocked adds the postings and length of a stored chunk, given
+// its tokens. Callers hold idx.mu for writing.
+func (idx *Index) indexChunkLocked(chunkID string, tokens []string) {
+	// Calculate TF
+	docLen := len(tokens)
+	idx.DocLengths[chunkID] = docLen
+	idx.SumDocLen += docLen
+
+	termFreqs := make(map[string]int)
+	for _, token := range tokens {
+		termFreqs[token]++
+	}
+
+	// Update inverted index with postings
+	for term, count := range termFreqs {
+		idx.InvertedIdx[term] = append(idx.InvertedIdx[term], Posting{
+			ChunkID: chunkID,
+			TF:      count,
+		})
+	}
+}
+
+// DeleteDocument removes every chunk of a document from the index, with
+// its postings and its share of the length statistics, and returns how
+// many chunks it had. It fails with ErrDocumentNotFound if none are
+// indexed.
+func (idx *Index) DeleteDocument(docID string) (int, error) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	n := idx.removeDocumentLocked(docID)
+	if n == 0 {
+		return 0, fmt.Errorf("%w: %s", ErrDocumentNotFound, docID)
+	}
+	if idx.cache != nil {
+		idx.cache.clear()
+	}
+	return n, nil
+}
+
+// removeDocumentLocked drops every chunk of a document from the index and
+// its statistics, returning how many there were. Callers hold idx.mu for
+// writing.
+func (idx *Index) removeDocumentLocked(docID string) int {
+	removed := 0
+	for chunkID, chunk := range idx.Docs {
+		if chunk.DocumentID != docID {
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, term := range idx.tokenize(chunk.Content) {
+			if seen[term] {
+				continue
+			}
+			seen[term] = true
+			postings := idx.InvertedIdx[term]
+			for i, p := range postings {
+				if p.ChunkID == chunkID {
+					postings = append(postings[:i], postings[i+1:]...)
+					break
+				}
+			}
+			if len(postings) == 0 {
+				delete(idx.InvertedIdx, term)
+			} else {
+				idx.InvertedIdx[term] = postings
+			}
+		}
+		idx.SumDocLen -= idx.DocLengths[chunkID]
+		idx.DocCount--
+		delete(idx.DocLengths, chunkID)
+		delete(idx.Docs, chunkID)
+		removed++
+	}
+	return removed
+}
+
+// documentsFromLocked returns the IDs of the documents indexed from
+// source. Notes added by hand all share the "manual" source, so they never
+// match. Callers hold idx.mu.
+func (idx *Index) documentsFromLocked(source string) []string {
+	if source == "" || source == "manual" {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var ids []string
+	for _, chunk := range idx.Docs {
+		if chunk.Source == source && !seen[chunk.DocumentID] {
+			seen[chunk.DocumentID] = true
+			ids = append(ids, chunk.DocumentID)
+		}
+	}
+	return ids
+}
+
+// ChunkCount returns how many chunks of a document are indexed.
+func (idx *Index) ChunkCount(docID string) int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	n := 0
+	for _, chunk := range idx.Docs {
+		if chunk.DocumentID == docID {
+			n++
+		}
+	}
+	return n
+}
+
+// Search searches the index using BM25. Results for repeated queries are
+// served from the cache when one is enabled. A query without any terms
+// fails with ErrEmptyQuery.
 func (idx *Index) Search(query string, limit int) ([]SearchResult, error) {
+	page, err := idx.SearchPaged(query, SearchOptions{Limit: limit})
+	return page.Results, err
+}
+
+// SearchGrouped searches like Search but returns at most perDoc chunks of
+// each document, so one long document cannot fill every slot. limit caps
+// the number of documents. Each result carries its document's aggregate
+// score and documents are ordered by it.
+func (idx *Index) SearchGrouped(query string, limit, perDoc int) ([]SearchResult, error) {
+	if perDoc < 1 {
+		perDoc = 1
+	}
+	page, err := idx.SearchPaged(query, SearchOptions{Limit: limit, PerDoc: perDoc})
+	return page.Results, err
+}
+
+// SearchPaged searches like Search, or like SearchGrouped when
+// opts.PerDoc is set, and returns the page of results opts selects along
+// with the number of matches on all pages. Results with equal scores are
+// ordered by chunk ID so pages do not overlap.
+func (idx *Index) SearchPaged(query string, opts SearchOptions) (SearchPage, error) {
+	if opts.Offset < 0 {
+		opts.Offset = 0
+	}
+	if opts.Limit < 0 {
+		opts.Limit = 0
+	}
+	if opts.PerDoc < 0 {
+		opts.PerDoc = 0
+	}
+	return idx.cachedSearch(searchKey{query: query, limit: opts.Limit, offset: opts.Offset, perDoc: opts.PerDoc})
+}
+
+func (idx *Index) cachedSearch(key searchKey) (SearchPage, error) {
 	idx.mu.RLock()
 	defer idx.mu.RUnlock()
 
+	if len(idx.tokenize(key.query)) == 0 {
+		return SearchPage{}, ErrEmptyQuery
+	}
 	if idx.DocCount == 0 {
-		return []SearchResult{}, nil
+		return SearchPage{Results: []SearchResult{}, Offset: key.offset}, nil
 	}
 
-	queryTokens := tokenize(query)
+	if idx.cache == nil {
+		return idx.search(key), nil
+	}
+	if page, ok := idx.cache.get(key); ok {
+		return page, nil
+	}
+	// Writers hold the exclusive lock, so these results cannot be stale
+	// by the time they are cached.
+	page := idx.search(key)
+	idx.cache.put(key, page)
+	return page, nil
+}
+
+// search runs the query described by key. Callers hold idx.mu.
+func (idx *Index) search(key searchKey) SearchPage {
+	results := idx.score(key.query)
+	if key.perDoc > 0 {
+		grouped, total := groupByDocument(results, key.offset, key.limit, key.perDoc)
+		return SearchPage{Results: grouped, Total: total, Offset: key.offset}
+	}
+	return SearchPage{Results: paginate(results, key.offset, key.limit), Total: len(results), Offset: key.offset}
+}
+
+// paginate returns the page of items starting at offset, at most limit
+// long. Negative arguments count as zero.
+func paginate[T any](items []T, offset, limit int) []T {
+	offset, limit = max(offset, 0), max(limit, 0)
+	if offset >= len(items) {
+		return items[:0]
+	}
+	items = items[offset:]
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
+
+// score returns every chunk matching the query, best first. Callers hold
+// idx.mu.
+func (idx *Index) score(query string) []SearchResult {
+	queryTokens := idx.tokenize(query)
 	scores := make(map[string]float64)
 	avgDocLen := float64(idx.SumDocLen) / float64(idx.DocCount)
 
@@ -144,18 +493,59 @@ func (idx *Index) Search(query string, limit int) ([]SearchResult, error) {
 	}
 
 	sort.Slice(results, func(i, j int) bool {
-		return results[i].Score > results[j].Score // Descending
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score // Descending
+		}
+		return results[i].Chunk.ID < results[j].Chunk.ID
 	})
 
-	if len(results) > limit {
-		results = results[:limit]
+	return results
+}
+
+// groupByDocument keeps the perDoc best chunks of each document from
+// results, which must be sorted by score. A document's aggregate score is
+// the sum of its kept chunk scores. It returns the chunks of the page of
+// documents selected by offset and limit, and the number of documents.
+func groupByDocument(results []SearchResult, offset, limit, perDoc int) ([]SearchResult, int) {
+	groups := make(map[string][]SearchResult)
+	var order []string
+	for _, res := range results {
+		group, seen := groups[res.DocumentID]
+		if !seen {
+			order = append(order, res.DocumentID)
+		}
+		if len(group) < perDoc {
+			groups[res.DocumentID] = append(group, res)
+		}
 	}
 
-	return results, nil
+	totals := make(map[string]float64, len(order))
+	for docID, group := range groups {
+		for _, res := range group {
+			totals[docID] += res.Score
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return totals[order[i]] > totals[order[j]]
+	})
+
+	grouped := []SearchResult{}
+	for _, docID := range paginate(order, offset, limit) {
+		for _, res := range groups[docID] {
+			res.DocumentScore = totals[docID]
+			grouped = append(grouped, res)
+		}
+	}
+	return grouped, len(order)
 }
 
-// Save persists the index to disk.
+// Save persists the index to disk. The file is written in full to a
+// temporary file and then renamed over the old one, so a crash while saving
+// leaves the previous version intact.
 func (idx *Index) Save(dir string) error {
+	// Saves run one at a time so an older snapshot never replaces a newer one
+	idx.saveMu.Lock()
+	defer idx.saveMu.Unlock()
 	idx.mu.RLock()
 	defer idx.mu.RUnlock()
 
@@ -164,15 +554,45 @@ func (idx *Index) Save(dir string) error {
 	}
 
 	filename := filepath.Join(dir, fmt.Sprintf("%s.index.json", idx.Name))
-	file, err := os.Create(filename)
+	file, err := os.CreateTemp(dir, filepath.Base(filename)+".*.tmp")
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	tmp := file.Name()
+	defer os.Remove(tmp) // no-op once renamed
+
+	w := bufio.NewWriter(file)
+	err = json.NewEncoder(w).Encode(idx)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filename); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
 
-	encoder := json.NewEncoder(file)
-	// encoder.SetIndent("", "  ") // Save space
-	return encoder.Encode(idx)
+// syncDir flushes dir's entries, so a rename into it survives a crash.
+// Windows cannot sync a directory; there the rename is left to the OS.
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
 }
 
 // Load loads an index from disk.
@@ -192,35 +612,93 @@ func LoadIndex(name, dir string) (*Index, error) {
 		return nil, err
 	}
 	idx.Name = name // Ensure name matches
+	if idx.Tokenizer == "" {
+		// Saved by the ASCII-only tokenizer; its postings would not match
+		// what the current one produces for the same chunks.
+		idx.Tokenizer = TokenizerUnicode
+		idx.rebuildLocked()
+	}
 	return &idx, nil
 }
 
 // --- Helpers ---
 
-var tokenRegexp = regexp.MustCompile(`[a-zA-Z0-9]+`)
+// span is a chunk of text with its rune offsets and 1-based line range.
+type span struct {
+	text               string
+	start, end         int
+	startLine, endLine int
+}
 
-func tokenize(text string) []string {
-	matches := tokenRegexp.FindAllString(strings.ToLower(text), -1)
-	return matches
+// chunkSpans splits text into chunks of size runes that overlap by
+// overlap runes.
+func chunkSpans(text string, size, overlap int) []span {
+	var spans []span
+	_ = chunkStream(strings.NewReader(text), size, overlap, func(s span) error {
+		spans = append(spans, s)
+		return nil
+	})
+	return spans
 }
 
-func chunkText(text string, size, overlap int) []string {
-	if len(text) <= size {
-		return []string{text}
-	}
+// chunkStream splits the text read from r into chunks of size runes that
+// overlap by overlap runes, calling emit for each in order. Only a window
+// of size+1 runes is held in memory at a time. Text of at most size runes,
+// including empty text, is a single chunk.
+func chunkStream(r io.Reader, size, overlap int, emit func(span) error) error {
+	br := bufio.NewReader(r)
+	step := size - overlap
+	window := make([]rune, 0, size+1)
+	start, line := 0, 1 // rune offset and line number of window[0]
+	eof := false
+
+	for {
+		// Fill one rune past the chunk to learn whether this is the last one
+		for !eof && len(window) <= size {
+			c, _, err := br.ReadRune()
+			if err == io.EOF {
+				eof = true
+				break
+			}
+			if err != nil {
+				return err
+			}
+			window = append(window, c)
+		}
 
-	var chunks []string
-	runes := []rune(text)
+		if len(window) <= size {
+			content := string(window)
+			return emit(span{
+				text:      content,
+				start:     start,
+				end:       start + len(window),
+				startLine: line,
+				endLine:   line + countLines(content),
+			})
+		}
 
-	for i := 0; i < len(runes); i += (size - overlap) {
-		end := i + size
-		if end > len(runes) {
-			end = len(runes)
+		content := string(window[:size])
+		if err := emit(span{
+			text:      content,
+			start:     start,
+			end:       start + size,
+			startLine: line,
+			endLine:   line + countLines(content),
+		}); err != nil {
+			return err
 		}
-		chunks = append(chunks, string(runes[i:end]))
-		if end >= len(runes) {
-			break
+
+		for _, c := range window[:step] {
+			if c == '\n' {
+				line++
+			}
 		}
+		window = window[:copy(window, window[step:])]
+		start += step
 	}
-	return chunks
+}
+
+// countLines counts the line breaks inside text, ignoring a trailing one.
+func countLines(text string) int {
+	return strings.Count(strings.TrimSuffix(text, "\n"), "\n")
 }
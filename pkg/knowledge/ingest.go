@@ -0,0 +1,231 @@
+package knowledge
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// FileResult is the outcome of ingesting one file of a directory. Error is
+// set when the file could not be ingested.
+type FileResult struct {
+	Path       string `json:"path"`
+	DocumentID string `json:"document_id"`
+	Chunks     int    `json:"chunks"`
+	Error      string `json:"error,omitempty"`
+}
+
+// FileDocumentID derives a stable document ID from a file path so that
+// ingesting the same file again replaces it instead of duplicating it.
+func FileDocumentID(path string) string {
+	sum := sha256.Sum256([]byte(FileSource(path)))
+	return "file_" + hex.EncodeToString(sum[:8])
+}
+
+// FileSource is the Source recorded for a file ingested from path: the
+// cleaned absolute path, so that "docs/a.md" and "./docs/../docs/a.md" are
+// the same source to an upsert.
+func FileSource(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+// Default limits of one IngestDir call, so a directory such as / fails
+// fast instead of being indexed whole.
+const (
+	DefaultIngestMaxFiles = 10000
+	DefaultIngestMaxBytes = 512 << 20
+)
+
+// SetIngestWorkers sets how many files IngestDir reads and chunks at once.
+// Non-positive values use one per CPU.
+func (s *Store) SetIngestWorkers(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ingestWorkers = n
+}
+
+func (s *Store) ingestWorkerCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.ingestWorkers <= 0 {
+		return runtime.NumCPU()
+	}
+	return s.ingestWorkers
+}
+
+// SetIngestLimits caps how many files, and how many bytes in total, one
+// IngestDir call may index. Non-positive values use the defaults.
+func (s *Store) SetIngestLimits(maxFiles int, maxBytes int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ingestMaxFiles = maxFiles
+	s.ingestMaxBytes = maxBytes
+}
+
+func (s *Store) ingestLimits() (int, int64) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	maxFiles, maxBytes := s.ingestMaxFiles, s.ingestMaxBytes
+	if maxFiles <= 0 {
+		maxFiles = DefaultIngestMaxFiles
+	}
+	if maxBytes <= 0 {
+		maxBytes = DefaultIngestMaxBytes
+	}
+	return maxFiles, maxBytes
+}
+
+// IngestDir indexes the files under dir, recursively, into a collection.
+// Files are read, extracted and chunked by a pool of workers (see
+// SetIngestWorkers) and added to the index as they finish; each one's
+// document ID is derived from its path, so the result does not depend on
+// the order they finish in. Hidden files and directories are skipped.
+//
+// The results list every file in path order. A file that cannot be
+// ingested, e.g. because it is not text, is reported in its result and
+// does not stop the others. The index is saved once, at the end (or at the
+// next Flush when auto-save is off). A directory over the limits set by
+// SetIngestLimits fails with ErrIngestTooLarge before anything is indexed.
+func (s *Store) IngestDir(collection, dir string) ([]FileResult, error) {
+	return s.ingestDir(collection, dir, false)
+}
+
+// UpsertDir is IngestDir with upsert semantics: each file also replaces
+// documents ingested from the same path under other IDs.
+func (s *Store) UpsertDir(collection, dir string) ([]FileResult, error) {
+	return s.ingestDir(collection, dir, true)
+}
+
+func (s *Store) ingestDir(collection, dir string, upsert bool) ([]FileResult, error) {
+	idx, err := s.GetIndex(collection)
+	if err != nil {
+		return nil, err
+	}
+	maxFiles, maxBytes := s.ingestLimits()
+	paths, err := listFiles(dir, maxFiles, maxBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	var redact func(string) string
+	if rd := s.redactorFor(collection); rd != nil {
+		redact = rd.Redact
+	}
+
+	results := make([]FileResult, len(paths))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := min(s.ingestWorkerCount(), len(paths)); w > 0; w-- {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = ingestFile(idx, paths[i], redact, upsert)
+			}
+		}()
+	}
+	for i := range paths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, res := range results {
+		if res.Error == "" {
+			return results, s.persist(idx)
+		}
+	}
+	return results, nil
+}
+
+// ingestFile extracts the text of one file and adds it to idx.
+func ingestFile(idx *Index, path string, redact func(string) string, upsert bool) FileResult {
+	result := FileResult{Path: path, DocumentID: FileDocumentID(path)}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	filename := filepath.Base(path)
+	text, docType, err := ExtractText(filename, "", data)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	doc := Document{
+		ID:     result.DocumentID,
+		Title:  filename,
+		Source: FileSource(path),
+		Type:   docType,
+		Metadata: map[string]interface{}{
+			"title":    filename,
+			"filename": filename,
+			"path":     path,
+		},
+	}
+	prepareDocument(&doc)
+	if redact != nil {
+		doc.Title = redact(doc.Title)
+	}
+
+	chunked, err := idx.chunkDocument(doc, strings.NewReader(text), redact)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	chunked.upsert = upsert
+	idx.addChunked(chunked)
+	result.Chunks = len(chunked.spans)
+	return result
+}
+
+// listFiles returns the regular files under dir in lexical order, which is
+// the order WalkDir visits them in, skipping hidden files and directories.
+// It stops with ErrIngestTooLarge once there are more than maxFiles files
+// or maxBytes bytes.
+func listFiles(dir string, maxFiles int, maxBytes int64) ([]string, error) {
+	var paths []string
+	var total int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		paths = append(paths, path)
+		total += info.Size()
+		if len(paths) > maxFiles {
+			return fmt.Errorf("%w: more than %d files", ErrIngestTooLarge, maxFiles)
+		}
+		if total > maxBytes {
+			return fmt.Errorf("%w: more than %d bytes", ErrIngestTooLarge, maxBytes)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
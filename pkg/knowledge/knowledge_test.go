@@ -1,8 +1,13 @@
 package knowledge
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 	"time"
 
@@ -138,3 +143,789 @@ func TestLargeDocChunking(t *testing.T) {
 		assert.Equal(t, "large1", chunk.DocumentID)
 	}
 }
+
+func TestStoreInvalidCollection(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	require.NoError(t, err)
+
+	_, err = store.GetIndex("   ")
+	assert.ErrorIs(t, err, ErrInvalidCollection)
+}
+
+func TestStoreRedactsPII(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	require.NoError(t, err)
+
+	redactor, err := NewRedactor(nil, nil)
+	require.NoError(t, err)
+	store.SetRedactor("Private", redactor)
+
+	content := "Invoice contact jane.doe@example.com paid with card 4111 1111 1111 1111 for the quarterly renewal."
+	for _, collection := range []string{"private", "public"} {
+		require.NoError(t, store.AddDocument(collection, Document{ID: "invoice", Content: content}))
+	}
+
+	results, err := store.Search("private", "quarterly renewal", 5)
+	require.NoError(t, err)
+	require.Len(t, results, 1, "redacted document must stay searchable")
+	got := results[0].Chunk.Content
+	assert.NotContains(t, got, "jane.doe@example.com")
+	assert.NotContains(t, got, "4111")
+	assert.Contains(t, got, "[REDACTED_EMAIL]")
+	assert.Contains(t, got, "[REDACTED_CREDIT_CARD]")
+
+	// Redaction is opt-in per collection
+	results, err = store.Search("public", "quarterly renewal", 5)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Contains(t, results[0].Chunk.Content, "jane.doe@example.com")
+}
+
+func TestNewRedactorPatterns(t *testing.T) {
+	r, err := NewRedactor([]string{"email"}, map[string]string{"employee_id": `EMP-\d{6}`})
+	require.NoError(t, err)
+	assert.Equal(t, "[REDACTED_EMAIL] is [REDACTED_EMPLOYEE_ID], call 555-123-4567",
+		r.Redact("bob@corp.io is EMP-123456, call 555-123-4567"))
+
+	_, err = NewRedactor([]string{"ssn"}, nil)
+	assert.Error(t, err)
+	_, err = NewRedactor(nil, map[string]string{"bad": "("})
+	assert.Error(t, err)
+}
+
+func TestSearchCache(t *testing.T) {
+	idx := NewIndex("test")
+	idx.SetCacheSize(2)
+	require.NoError(t, idx.AddDocument(Document{ID: "doc1", Content: "The quick brown fox"}))
+
+	first, err := idx.Search("fox", 10)
+	require.NoError(t, err)
+	require.Len(t, first, 1)
+	first[0].DocumentID = "mutated"
+
+	cached, err := idx.Search("fox", 10)
+	require.NoError(t, err)
+	assert.Equal(t, "doc1", cached[0].DocumentID, "cached results must not alias caller slices")
+
+	// Adding a document invalidates cached results
+	require.NoError(t, idx.AddDocument(Document{ID: "doc2", Content: "A red fox"}))
+	results, err := idx.Search("fox", 10)
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	// The limit is part of the key
+	results, err = idx.Search("fox", 1)
+	require.NoError(t, err)
+	assert.Len(t, results, 1)
+
+	// Oldest entries are evicted beyond the configured size
+	_, err = idx.Search("red", 10)
+	require.NoError(t, err)
+	assert.Equal(t, 2, idx.cache.order.Len())
+	_, ok := idx.cache.get(searchKey{query: "fox", limit: 10})
+	assert.False(t, ok)
+}
+
+func benchmarkSearch(b *testing.B, cacheSize int) {
+	words := []string{"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel", "india", "juliet", "kilo", "lima"}
+	idx := NewIndex("bench")
+	idx.SetCacheSize(cacheSize)
+	for i := 0; i < 5000; i++ {
+		var sb strings.Builder
+		for j := 0; j < 150; j++ {
+			sb.WriteString(words[(i*7+j*j)%len(words)])
+			sb.WriteString(fmt.Sprintf(" term%d ", (i+j)%997))
+		}
+		require.NoError(b, idx.AddDocument(Document{ID: fmt.Sprintf("doc%d", i), Content: sb.String()}))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := idx.Search("alpha delta term42 kilo", 10); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSearchCold(b *testing.B)   { benchmarkSearch(b, 0) }
+func BenchmarkSearchCached(b *testing.B) { benchmarkSearch(b, 128) }
+
+func TestAddDocumentReplacesExisting(t *testing.T) {
+	idx := NewIndex("test")
+	require.NoError(t, idx.AddDocument(Document{ID: "keep", Content: "shared words stay"}))
+	require.NoError(t, idx.AddDocument(Document{ID: "doc", Content: strings.Repeat("old shared text ", 200)}))
+	require.NoError(t, idx.AddDocument(Document{ID: "doc", Content: "new shared text"}))
+
+	assert.Equal(t, 2, idx.DocCount)
+	assert.Equal(t, len(tokenize("shared words stay"))+len(tokenize("new shared text")), idx.SumDocLen)
+	assert.NotContains(t, idx.InvertedIdx, "old")
+	assert.Len(t, idx.InvertedIdx["shared"], 2)
+}
+
+func TestUpsertDocument(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir)
+	require.NoError(t, err)
+	require.NoError(t, store.AddDocument("ops", Document{ID: "note", Source: "manual", Content: "shared words stay"}))
+	require.NoError(t, store.AddDocument("ops", Document{ID: "old-id", Source: "/docs/runbook.md", Content: strings.Repeat("old shared text ", 200)}))
+
+	// Upserting from the same source under a new ID replaces the old copy
+	require.NoError(t, store.UpsertDocumentReader("ops", Document{ID: "new-id", Source: "/docs/runbook.md"}, strings.NewReader("new shared text")))
+	// Notes added by hand are never matched by source
+	require.NoError(t, store.UpsertDocument("ops", Document{ID: "other-note", Source: "manual", Content: "another note"}))
+
+	idx, err := LoadIndex("ops", dir)
+	require.NoError(t, err)
+	assert.Equal(t, 0, idx.ChunkCount("old-id"))
+	assert.Equal(t, 1, idx.ChunkCount("new-id"))
+	assert.Equal(t, 1, idx.ChunkCount("note"))
+	assert.Equal(t, 3, idx.DocCount)
+	assert.Equal(t, len(tokenize("shared words stay"))+len(tokenize("new shared text"))+len(tokenize("another note")), idx.SumDocLen)
+	assert.NotContains(t, idx.InvertedIdx, "old")
+	assert.Len(t, idx.InvertedIdx["shared"], 2)
+}
+
+func TestAddDocuments(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir)
+	require.NoError(t, err)
+
+	docs := make([]Document, 50)
+	for i := range docs {
+		docs[i] = Document{ID: fmt.Sprintf("doc-%d", i), Content: fmt.Sprintf("batch document number%d", i)}
+	}
+	require.NoError(t, store.AddDocuments("batch", docs))
+
+	idx, err := LoadIndex("batch", dir)
+	require.NoError(t, err)
+	assert.Equal(t, 50, idx.DocCount)
+	assert.Len(t, idx.InvertedIdx["batch"], 50)
+
+	// Saving leaves only the index behind
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "batch.index.json")}, files)
+}
+
+func TestStoreFlush(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir)
+	require.NoError(t, err)
+	require.NoError(t, store.AddDocument("ops", Document{ID: "saved", Content: "saved right away"}))
+
+	require.NoError(t, store.SetAutoSave(false))
+	require.NoError(t, store.AddDocument("ops", Document{ID: "pending", Content: "pending until flushed"}))
+	require.NoError(t, store.DeleteDocument("ops", "saved"))
+
+	// A crash now would find the last saved version
+	idx, err := LoadIndex("ops", dir)
+	require.NoError(t, err)
+	assert.Equal(t, 1, idx.ChunkCount("saved"))
+	assert.Equal(t, 0, idx.ChunkCount("pending"))
+
+	require.NoError(t, store.Flush())
+	idx, err = LoadIndex("ops", dir)
+	require.NoError(t, err)
+	assert.Equal(t, 0, idx.ChunkCount("saved"))
+	assert.Equal(t, 1, idx.ChunkCount("pending"))
+	assert.Equal(t, 1, idx.DocCount)
+
+	// Nothing is pending after a flush
+	require.NoError(t, store.Flush())
+
+	// Turning auto-save back on saves what is pending
+	require.NoError(t, store.AddDocument("ops", Document{ID: "late", Content: "added before auto-save returns"}))
+	require.NoError(t, store.SetAutoSave(true))
+	idx, err = LoadIndex("ops", dir)
+	require.NoError(t, err)
+	assert.Equal(t, 1, idx.ChunkCount("late"))
+}
+
+func TestCompact(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir)
+	require.NoError(t, err)
+	for i := 0; i < 20; i++ {
+		content := fmt.Sprintf("deploy runbook step%d restart service%d", i, i)
+		require.NoError(t, store.AddDocument("ops", Document{ID: fmt.Sprintf("doc-%d", i), Content: content}))
+	}
+	for i := 0; i < 10; i++ {
+		require.NoError(t, store.DeleteDocument("ops", fmt.Sprintf("doc-%d", i)))
+	}
+	before, err := store.Search("ops", "restart service15", 5)
+	require.NoError(t, err)
+
+	// Leave what older versions did when replacing documents: postings
+	// and lengths of chunks that are gone, and a stale count
+	idx, err := store.GetIndex("ops")
+	require.NoError(t, err)
+	idx.mu.Lock()
+	for i := 0; i < 10; i++ {
+		chunkID := fmt.Sprintf("doc-%d_chk_0", i)
+		for _, term := range []string{"deploy", "runbook", "restart", fmt.Sprintf("step%d", i)} {
+			idx.InvertedIdx[term] = append(idx.InvertedIdx[term], Posting{ChunkID: chunkID, TF: 1})
+		}
+		idx.DocLengths[chunkID] = 6
+		idx.SumDocLen += 6
+		idx.DocCount++
+	}
+	idx.mu.Unlock()
+	require.NoError(t, idx.Save(dir))
+
+	result, err := store.Compact("ops")
+	require.NoError(t, err)
+	assert.Equal(t, 40, result.PostingsRemoved)
+	assert.Positive(t, result.BytesSaved())
+
+	idx, err = LoadIndex("ops", dir)
+	require.NoError(t, err)
+	assert.Equal(t, 10, idx.DocCount)
+	assert.Len(t, idx.DocLengths, 10)
+	assert.Len(t, idx.InvertedIdx["deploy"], 10)
+	assert.NotContains(t, idx.InvertedIdx, "step3")
+
+	after, err := store.Search("ops", "restart service15", 5)
+	require.NoError(t, err)
+	require.NotEmpty(t, after)
+	assert.Equal(t, before, after)
+
+	_, err = store.Compact("missing")
+	assert.ErrorIs(t, err, ErrCollectionNotFound)
+}
+
+func TestDeleteDocument(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir)
+	require.NoError(t, err)
+	require.NoError(t, store.AddDocument("ops", Document{ID: "keep", Content: "shared words stay"}))
+	require.NoError(t, store.AddDocument("ops", Document{ID: "stale", Content: strings.Repeat("stale shared text ", 200)}))
+
+	// Warm the cache so a stale result would show up
+	results, err := store.Search("ops", "stale", 5)
+	require.NoError(t, err)
+	require.NotEmpty(t, results)
+
+	require.NoError(t, store.DeleteDocument("ops", "stale"))
+	assert.ErrorIs(t, store.DeleteDocument("ops", "stale"), ErrDocumentNotFound)
+	assert.ErrorIs(t, store.DeleteDocument("missing", "stale"), ErrCollectionNotFound)
+
+	results, err = store.Search("ops", "stale", 5)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	// The deletion is persisted
+	idx, err := LoadIndex("ops", dir)
+	require.NoError(t, err)
+	assert.Equal(t, 1, idx.DocCount)
+	assert.Equal(t, len(tokenize("shared words stay")), idx.SumDocLen)
+	assert.NotContains(t, idx.InvertedIdx, "stale")
+	assert.Len(t, idx.InvertedIdx["shared"], 1)
+}
+
+func TestSearchGroupedByDocument(t *testing.T) {
+	idx := NewIndex("test")
+	idx.SetCacheSize(8)
+	require.NoError(t, idx.AddDocument(Document{ID: "manual", Content: strings.Repeat("deploy the service with care. ", 200)}))
+	require.NoError(t, idx.AddDocument(Document{ID: "faq", Content: "How do I deploy?"}))
+	require.NoError(t, idx.AddDocument(Document{ID: "notes", Content: "deploy notes"}))
+
+	results, err := idx.Search("deploy", 3)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	for _, res := range results {
+		assert.Equal(t, "manual", res.DocumentID, "per-chunk mode is dominated by the long document")
+	}
+
+	results, err = idx.SearchGrouped("deploy", 3, 1)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	docs := map[string]bool{}
+	for _, res := range results {
+		docs[res.DocumentID] = true
+		assert.Equal(t, res.Score, res.DocumentScore)
+	}
+	assert.Len(t, docs, 3)
+
+	results, err = idx.SearchGrouped("deploy", 1, 2)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, results[0].DocumentID, results[1].DocumentID)
+	assert.InDelta(t, results[0].Score+results[1].Score, results[0].DocumentScore, 1e-9)
+
+	// A negative limit selects no documents instead of panicking.
+	results, err = idx.SearchGrouped("deploy", -1, 1)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestSearchResultOffsets(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	require.NoError(t, err)
+
+	var sb strings.Builder
+	for i := 0; i < 120; i++ {
+		fmt.Fprintf(&sb, "line %d of the handbook ✓\n", i)
+	}
+	sb.WriteString("The escalation phone tree is kept by the night shift.\n")
+	content := sb.String()
+	require.NoError(t, store.AddDocument("docs", Document{ID: "handbook", Source: "docs/handbook.md", Title: "Handbook", Content: content}))
+
+	// Reload from disk so the offsets are known to persist
+	store, err = NewStore(store.baseDir)
+	require.NoError(t, err)
+	results, err := store.Search("docs", "escalation tree", 1)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	chunk := results[0].Chunk
+	assert.Equal(t, "docs/handbook.md", chunk.Source)
+	assert.Equal(t, "Handbook", chunk.Title)
+	runes := []rune(content)
+	assert.Equal(t, chunk.Content, string(runes[chunk.Start:chunk.End]))
+	assert.Equal(t, strings.Count(string(runes[:chunk.Start]), "\n")+1, chunk.StartLine)
+	assert.Equal(t, 121, chunk.EndLine)
+	assert.Equal(t, fmt.Sprintf("docs/handbook.md:%d-%d (lines %d-121)", chunk.Start, chunk.End, chunk.StartLine), chunk.Citation())
+}
+
+func TestQueryAnalytics(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir)
+	require.NoError(t, err)
+	require.NoError(t, store.AddDocument("docs", Document{ID: "d1", Content: "How to reset the router"}))
+
+	// Logging is opt-in
+	_, err = store.Search("docs", "router", 5)
+	require.NoError(t, err)
+	_, err = os.Stat(store.queryLogPath("docs"))
+	assert.True(t, os.IsNotExist(err), "no query log without opting in")
+
+	store.SetQueryLogging("Docs", true)
+	for _, q := range []string{"router", "Router ", "reset router", "vpn", "vpn"} {
+		_, err := store.Search("docs", q, 5)
+		require.NoError(t, err)
+	}
+
+	report, err := store.QueryAnalytics("docs", time.Now().Add(-time.Hour), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 5, report.TotalQueries)
+	assert.Equal(t, 2, report.ZeroResultQueries)
+	require.Len(t, report.TopQueries, 3)
+	assert.Equal(t, 2, report.TopQueries[0].Count)
+	assert.Greater(t, report.TopQueries[0].TopScore+report.TopQueries[1].TopScore, 0.0)
+	require.Len(t, report.ZeroResults, 1)
+	assert.Equal(t, QueryStat{Query: "vpn", Count: 2, LastSeen: report.ZeroResults[0].LastSeen}, report.ZeroResults[0])
+
+	// Entries outside the window are ignored, and limit caps the lists
+	report, err = store.QueryAnalytics("docs", time.Now().Add(time.Hour), 10)
+	require.NoError(t, err)
+	assert.Zero(t, report.TotalQueries)
+	report, err = store.QueryAnalytics("docs", time.Time{}, 1)
+	require.NoError(t, err)
+	assert.Len(t, report.TopQueries, 1)
+}
+
+// generatedReader produces n bytes of repeating text without holding them.
+type generatedReader struct {
+	remaining int
+	line      []byte
+	pos       int
+}
+
+func (g *generatedReader) Read(p []byte) (int, error) {
+	if g.remaining == 0 {
+		return 0, io.EOF
+	}
+	n := 0
+	for n < len(p) && g.remaining > 0 {
+		p[n] = g.line[g.pos]
+		g.pos = (g.pos + 1) % len(g.line)
+		n++
+		g.remaining--
+	}
+	return n, nil
+}
+
+func TestChunkStreamBoundedMemory(t *testing.T) {
+	const total = 64 << 20
+	r := &generatedReader{remaining: total, line: []byte("streaming ingestion keeps memory flat\n")}
+
+	runtime.GC()
+	var before, now runtime.MemStats
+	runtime.ReadMemStats(&before)
+	peak := before.HeapAlloc
+
+	chunks, lastEnd := 0, 0
+	err := chunkStream(r, chunkSize, chunkOverlap, func(s span) error {
+		chunks++
+		lastEnd = s.end
+		if chunks%1000 == 0 {
+			runtime.ReadMemStats(&now)
+			if now.HeapAlloc > peak {
+				peak = now.HeapAlloc
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, total, lastEnd)
+	step := chunkSize - chunkOverlap
+	assert.Equal(t, (total-chunkSize+step-1)/step+1, chunks)
+	assert.Less(t, peak-before.HeapAlloc, uint64(16<<20), "chunking must not buffer the whole input")
+}
+
+// failingReader returns some text and then an error.
+type failingReader struct{ done bool }
+
+func (f *failingReader) Read(p []byte) (int, error) {
+	if f.done {
+		return 0, errors.New("disk error")
+	}
+	f.done = true
+	return copy(p, strings.Repeat("partial content ", 200)), nil
+}
+
+func TestAddDocumentReader(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	require.NoError(t, err)
+
+	content := strings.Repeat("line about streaming chunks\n", 200)
+	require.NoError(t, store.AddDocumentReader("docs", Document{ID: "streamed", Title: "s.txt"}, strings.NewReader(content)))
+	require.NoError(t, store.AddDocument("docs", Document{ID: "buffered", Title: "b.txt", Content: content}))
+
+	streamed, err := store.ChunkCount("docs", "streamed")
+	require.NoError(t, err)
+	buffered, err := store.ChunkCount("docs", "buffered")
+	require.NoError(t, err)
+	assert.Equal(t, buffered, streamed)
+
+	err = store.AddDocumentReader("docs", Document{ID: "broken"}, &failingReader{})
+	require.Error(t, err)
+	n, err := store.ChunkCount("docs", "broken")
+	require.NoError(t, err)
+	assert.Zero(t, n, "a failed read must not leave a partial document")
+
+	err = store.AddDocumentReader("docs", Document{ID: "streamed"}, &failingReader{})
+	require.Error(t, err)
+	n, err = store.ChunkCount("docs", "streamed")
+	require.NoError(t, err)
+	assert.Equal(t, buffered, n, "a failed read must keep the earlier version")
+}
+
+func TestAddDocumentReaderRedactsAcrossChunks(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	require.NoError(t, err)
+	redactor, err := NewRedactor([]string{"email"}, nil)
+	require.NoError(t, err)
+	store.SetRedactor("private", redactor)
+
+	// Put the address across the end of the first chunk and into the
+	// overlap, where redacting chunk by chunk would miss part of it.
+	email := "jane.doe@example.com"
+	padding := strings.Repeat("x", chunkSize-chunkOverlap-len(email)/2-1)
+	content := padding + " " + email + " " + strings.Repeat("tail words ", 100)
+	require.NoError(t, store.AddDocumentReader("private", Document{ID: "doc"}, strings.NewReader(content)))
+
+	idx, err := store.GetIndex("private")
+	require.NoError(t, err)
+	for _, chunk := range idx.Docs {
+		assert.NotContains(t, chunk.Content, "jane", "chunk %d leaks PII", chunk.Index)
+		assert.NotContains(t, chunk.Content, "ample.com", "chunk %d leaks PII", chunk.Index)
+	}
+}
+
+func TestIndexStats(t *testing.T) {
+	idx := NewIndex("stats")
+	empty := idx.Stats()
+	assert.Equal(t, 0, empty.Chunks)
+	assert.Zero(t, empty.AvgDocLength)
+	assert.Empty(t, empty.TopTerms)
+
+	require.NoError(t, idx.AddDocument(Document{ID: "a", Content: "the cat sat on the mat"}))
+	require.NoError(t, idx.AddDocument(Document{ID: "b", Content: "the dog sat"}))
+
+	stats := idx.Stats()
+	assert.Equal(t, "stats", stats.Name)
+	assert.Equal(t, 2, stats.Documents)
+	assert.Equal(t, 2, stats.Chunks)
+	// the, cat, sat, on, mat, dog
+	assert.Equal(t, 6, stats.VocabularySize)
+	// a: the, cat, sat, on, mat; b: the, dog, sat
+	assert.Equal(t, 8, stats.TotalPostings)
+	assert.Equal(t, 9, stats.TotalTokens)
+	assert.InDelta(t, 4.5, stats.AvgDocLength, 1e-9)
+
+	require.Len(t, stats.TopTerms, 6)
+	assert.Equal(t, TermStat{Term: "the", DocFreq: 2, TotalFreq: 3}, stats.TopTerms[0])
+	assert.Equal(t, TermStat{Term: "sat", DocFreq: 2, TotalFreq: 2}, stats.TopTerms[1])
+	assert.Equal(t, TermStat{Term: "cat", DocFreq: 1, TotalFreq: 1}, stats.TopTerms[2])
+}
+
+func TestStoreStats(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	require.NoError(t, err)
+
+	_, err = store.Stats("missing")
+	assert.ErrorIs(t, err, ErrCollectionNotFound)
+	collections, err := store.ListCollections()
+	require.NoError(t, err)
+	assert.Empty(t, collections, "Stats must not create the collection")
+
+	require.NoError(t, store.AddDocument("notes", Document{ID: "n1", Content: "hello world"}))
+	stats, err := store.Stats("Notes")
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.VocabularySize)
+}
+
+func TestTokenizeUnicode(t *testing.T) {
+	assert.Equal(t, []string{"café", "naïve", "straße", "2024"}, tokenize("Café, NAÏVE Straße! 2024"))
+	assert.Equal(t, []string{"привет", "мир"}, tokenize("Привет, мир"))
+	// Without a CJK mode a run of CJK characters is one token.
+	assert.Equal(t, []string{"東京タワー"}, tokenize("東京タワー"))
+}
+
+func TestTokenizeCJK(t *testing.T) {
+	assert.Equal(t, []string{"東", "京", "に", "行", "く"}, tokenizeWith(TokenizerCJKChars, "東京に行く"))
+	assert.Equal(t, []string{"東京", "京に", "に行", "行く"}, tokenizeWith(TokenizerCJKBigrams, "東京に行く"))
+	assert.Equal(t, []string{"go", "语言", "v2"}, tokenizeWith(TokenizerCJKBigrams, "Go语言 v2"))
+	assert.Equal(t, []string{"한국", "국어", "字"}, tokenizeWith(TokenizerCJKBigrams, "한국어 字"))
+}
+
+func TestIndexCJKSearch(t *testing.T) {
+	idx := NewIndex("cjk")
+	require.NoError(t, idx.AddDocument(Document{ID: "tokyo", Content: "東京タワーは東京の観光名所です"}))
+	require.NoError(t, idx.AddDocument(Document{ID: "kyoto", Content: "京都の寺"}))
+
+	results, err := idx.Search("東京", 5)
+	require.NoError(t, err)
+	assert.Empty(t, results, "the unicode tokenizer does not segment CJK text")
+
+	require.NoError(t, idx.SetTokenizer(TokenizerCJKBigrams))
+	results, err = idx.Search("東京", 5)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "tokyo", results[0].DocumentID)
+
+	assert.ErrorIs(t, idx.SetTokenizer("words"), ErrUnknownTokenizer)
+}
+
+func TestStoreTokenizerPersisted(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, store.AddDocument("docs", Document{ID: "d1", Content: "東京の天気"}))
+	require.NoError(t, store.SetTokenizer("docs", TokenizerCJKChars))
+
+	store2, err := NewStore(dir)
+	require.NoError(t, err)
+	stats, err := store2.Stats("docs")
+	require.NoError(t, err)
+	assert.Equal(t, TokenizerCJKChars, stats.Tokenizer)
+	results, err := store2.Search("docs", "天気", 5)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "d1", results[0].DocumentID)
+}
+
+func TestSearchEmptyQuery(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, store.AddDocument("notes", Document{ID: "n1", Content: "hello world"}))
+
+	for _, query := range []string{"", "   \t\n", "?!... --- ***"} {
+		_, err := store.Search("notes", query, 5)
+		assert.ErrorIs(t, err, ErrEmptyQuery, "query %q", query)
+		_, err = store.SearchGrouped("notes", query, 5, 1)
+		assert.ErrorIs(t, err, ErrEmptyQuery, "query %q", query)
+	}
+
+	// An empty index still rejects the query rather than finding nothing.
+	_, err = NewIndex("empty").Search(" ", 5)
+	assert.ErrorIs(t, err, ErrEmptyQuery)
+}
+
+func TestSearchPaged(t *testing.T) {
+	idx := NewIndex("paged")
+	idx.SetCacheSize(8)
+	// Identical chunks score the same, so only the tie-break orders them.
+	for i := 0; i < 7; i++ {
+		require.NoError(t, idx.AddDocument(Document{ID: fmt.Sprintf("doc%d", i), Content: "shared term"}))
+	}
+
+	first, err := idx.SearchPaged("shared", SearchOptions{Limit: 4})
+	require.NoError(t, err)
+	second, err := idx.SearchPaged("shared", SearchOptions{Limit: 4, Offset: 4})
+	require.NoError(t, err)
+
+	assert.Equal(t, 7, first.Total)
+	assert.Equal(t, 7, second.Total)
+	assert.Equal(t, 4, second.Offset)
+	require.Len(t, first.Results, 4)
+	require.Len(t, second.Results, 3)
+
+	seen := make(map[string]bool)
+	for _, res := range append(first.Results, second.Results...) {
+		assert.False(t, seen[res.Chunk.ID], "chunk %s on both pages", res.Chunk.ID)
+		seen[res.Chunk.ID] = true
+	}
+	assert.Len(t, seen, 7)
+
+	// The same page comes back in the same order, cached or not.
+	again, err := idx.SearchPaged("shared", SearchOptions{Limit: 4, Offset: 4})
+	require.NoError(t, err)
+	assert.Equal(t, second.Results, again.Results)
+	idx.SetCacheSize(0)
+	again, err = idx.SearchPaged("shared", SearchOptions{Limit: 4, Offset: 4})
+	require.NoError(t, err)
+	assert.Equal(t, second.Results, again.Results)
+
+	// Offset 0 matches Search.
+	results, err := idx.Search("shared", 4)
+	require.NoError(t, err)
+	assert.Equal(t, first.Results, results)
+
+	past, err := idx.SearchPaged("shared", SearchOptions{Limit: 4, Offset: 10})
+	require.NoError(t, err)
+	assert.Empty(t, past.Results)
+	assert.Equal(t, 7, past.Total)
+
+	// Negative arguments count as zero instead of panicking.
+	negative, err := idx.SearchPaged("shared", SearchOptions{Limit: -1, Offset: -3})
+	require.NoError(t, err)
+	assert.Empty(t, negative.Results)
+	assert.Equal(t, 0, negative.Offset)
+	assert.Equal(t, 7, negative.Total)
+
+	grouped, err := idx.SearchPaged("shared", SearchOptions{Limit: 5, Offset: 5, PerDoc: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 7, grouped.Total)
+	assert.Len(t, grouped.Results, 2)
+}
+
+// writeCorpus writes n small text files to dir, spread over subdirectories.
+func writeCorpus(tb testing.TB, dir string, n int) {
+	tb.Helper()
+	for i := 0; i < n; i++ {
+		sub := filepath.Join(dir, fmt.Sprintf("part%d", i%4))
+		require.NoError(tb, os.MkdirAll(sub, 0755))
+		content := strings.Repeat(fmt.Sprintf("note %d about edge deployment and indexing\n", i), 20)
+		require.NoError(tb, os.WriteFile(filepath.Join(sub, fmt.Sprintf("note%03d.md", i)), []byte(content), 0644))
+	}
+}
+
+func TestIngestDir(t *testing.T) {
+	dir := t.TempDir()
+	writeCorpus(t, dir, 10)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "image.bin"), []byte{0xff, 0xfe, 0x00}, 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".git", "HEAD"), []byte("ref: main"), 0644))
+
+	ingest := func(workers int) (*Store, []FileResult) {
+		store, err := NewStore(t.TempDir())
+		require.NoError(t, err)
+		store.SetIngestWorkers(workers)
+		results, err := store.IngestDir("docs", dir)
+		require.NoError(t, err)
+		return store, results
+	}
+
+	store, results := ingest(4)
+	require.Len(t, results, 11, "hidden files are skipped")
+	assert.Equal(t, filepath.Join(dir, "image.bin"), results[0].Path)
+	assert.ErrorContains(t, errors.New(results[0].Error), "not a text file")
+	for _, res := range results[1:] {
+		assert.Empty(t, res.Error, res.Path)
+		assert.Equal(t, FileDocumentID(res.Path), res.DocumentID)
+		assert.Equal(t, 1, res.Chunks)
+	}
+
+	page, err := store.SearchPaged("docs", "note 7", SearchOptions{Limit: 20})
+	require.NoError(t, err)
+	assert.Equal(t, 10, page.Total)
+	assert.Equal(t, FileDocumentID(filepath.Join(dir, "part3", "note007.md")), page.Results[0].DocumentID)
+
+	// Ingesting again, with any number of workers, gives the same index.
+	sequential, again := ingest(1)
+	assert.Equal(t, results, again)
+	a, err := store.GetIndex("docs")
+	require.NoError(t, err)
+	b, err := sequential.GetIndex("docs")
+	require.NoError(t, err)
+	assert.Equal(t, a.Stats(), b.Stats())
+
+	_, err = store.IngestDir("docs", filepath.Join(dir, "missing"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestUpsertDir(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	path := filepath.Join(dir, "runbook.md")
+	require.NoError(t, os.WriteFile(path, []byte("fresh steps"), 0644))
+
+	store, err := NewStore(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, store.AddDocument("docs", Document{ID: "runbook", Source: path, Content: "stale steps"}))
+
+	// A relative directory records the same absolute source
+	t.Chdir(dir)
+	results, err := store.IngestDir("docs", ".")
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	idx, err := store.GetIndex("docs")
+	require.NoError(t, err)
+	assert.Equal(t, 1, idx.ChunkCount("runbook"), "a plain ingest keeps other IDs")
+	for _, chunk := range idx.Docs {
+		assert.Equal(t, path, chunk.Source)
+	}
+
+	_, err = store.UpsertDir("docs", ".")
+	require.NoError(t, err)
+	assert.Equal(t, 0, idx.ChunkCount("runbook"))
+	assert.Equal(t, 1, idx.ChunkCount(FileDocumentID(path)))
+	assert.Equal(t, 1, idx.DocCount)
+	assert.Equal(t, len(tokenize("fresh steps")), idx.SumDocLen)
+}
+
+func TestIngestDirLimits(t *testing.T) {
+	dir := t.TempDir()
+	writeCorpus(t, dir, 10)
+
+	store, err := NewStore(t.TempDir())
+	require.NoError(t, err)
+
+	store.SetIngestLimits(5, 0)
+	_, err = store.IngestDir("docs", dir)
+	assert.ErrorIs(t, err, ErrIngestTooLarge)
+
+	store.SetIngestLimits(0, 1024)
+	_, err = store.IngestDir("docs", dir)
+	assert.ErrorIs(t, err, ErrIngestTooLarge)
+
+	store.SetIngestLimits(10, 0)
+	results, err := store.IngestDir("docs", dir)
+	require.NoError(t, err)
+	assert.Len(t, results, 10)
+}
+
+// BenchmarkIngestDir ingests a directory of 200 small files with growing
+// worker pools; the speedup levels off at the number of CPUs.
+func BenchmarkIngestDir(b *testing.B) {
+	dir := b.TempDir()
+	writeCorpus(b, dir, 200)
+
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			store, err := NewStore(b.TempDir())
+			require.NoError(b, err)
+			store.SetIngestWorkers(workers)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := store.IngestDir("docs", dir); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package knowledge
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// builtinDetectors are the PII patterns available by name. Matches are
+// replaced with a placeholder such as [REDACTED_EMAIL], so the rest of the
+// document stays searchable.
+var builtinDetectors = map[string]*regexp.Regexp{
+	"email":       regexp.MustCompile(`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`),
+	"credit_card": regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`),
+	"phone":       regexp.MustCompile(`(?:\+\d{1,3}[ \-.]?)?\(?\d{3}\)?[ \-.]?\d{3}[ \-.]?\d{4}\b`),
+	"api_key":     regexp.MustCompile(`\b(?:sk|pk|rk|ghp|gho|xox[abp])[-_][A-Za-z0-9_\-]{16,}\b|\bAKIA[0-9A-Z]{16}\b`),
+}
+
+// detectorOrder applies card numbers before phone numbers, since a phone
+// pattern would otherwise match part of a card number.
+var detectorOrder = []string{"email", "api_key", "credit_card", "phone"}
+
+type detector struct {
+	name        string
+	pattern     *regexp.Regexp
+	placeholder string
+}
+
+// Redactor replaces personally identifiable information in document text
+// with placeholders before it is indexed.
+type Redactor struct {
+	detectors []detector
+}
+
+// NewRedactor builds a redactor from built-in detector names and custom
+// name -> regular expression patterns. If both are empty, all built-in
+// detectors are used.
+func NewRedactor(builtins []string, custom map[string]string) (*Redactor, error) {
+	if len(builtins) == 0 && len(custom) == 0 {
+		builtins = detectorOrder
+	}
+
+	r := &Redactor{}
+	wanted := make(map[string]bool, len(builtins))
+	for _, name := range builtins {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := builtinDetectors[name]; !ok {
+			return nil, fmt.Errorf("unknown PII detector %q", name)
+		}
+		wanted[name] = true
+	}
+	for _, name := range detectorOrder {
+		if wanted[name] {
+			r.add(name, builtinDetectors[name])
+		}
+	}
+
+	// Custom patterns run after the built-ins, in name order so results are
+	// deterministic.
+	names := make([]string, 0, len(custom))
+	for name := range custom {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		re, err := regexp.Compile(custom[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid PII pattern %q: %w", name, err)
+		}
+		r.add(name, re)
+	}
+
+	return r, nil
+}
+
+func (r *Redactor) add(name string, re *regexp.Regexp) {
+	r.detectors = append(r.detectors, detector{
+		name:        name,
+		pattern:     re,
+		placeholder: "[REDACTED_" + strings.ToUpper(name) + "]",
+	})
+}
+
+// Redact returns text with every detector match replaced by its placeholder.
+func (r *Redactor) Redact(text string) string {
+	if r == nil {
+		return text
+	}
+	for _, d := range r.detectors {
+		text = d.pattern.ReplaceAllLiteralString(text, d.placeholder)
+	}
+	return text
+}
@@ -0,0 +1,110 @@
+package knowledge
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// statsTopTerms is how many terms IndexStats lists.
+const statsTopTerms = 20
+
+// TermStat describes how common a term is in an index.
+type TermStat struct {
+	Term      string `json:"term"`
+	DocFreq   int    `json:"doc_freq"`   // chunks containing the term
+	TotalFreq int    `json:"total_freq"` // occurrences across all chunks
+}
+
+// IndexStats summarizes the internals of a BM25 index, e.g. to see whether
+// common words dominate it.
+type IndexStats struct {
+	Name           string     `json:"name"`
+	Tokenizer      string     `json:"tokenizer"`
+	Documents      int        `json:"documents"`
+	Chunks         int        `json:"chunks"`
+	VocabularySize int        `json:"vocabulary_size"`
+	TotalPostings  int        `json:"total_postings"`
+	TotalTokens    int        `json:"total_tokens"`
+	AvgDocLength   float64    `json:"avg_doc_length"` // tokens per chunk
+	TopTerms       []TermStat `json:"top_terms"`      // by document frequency
+}
+
+// Stats returns the index's size figures and its most widespread terms.
+func (idx *Index) Stats() IndexStats {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	stats := IndexStats{
+		Name:           idx.Name,
+		Tokenizer:      idx.Tokenizer,
+		Chunks:         idx.DocCount,
+		VocabularySize: len(idx.InvertedIdx),
+		TotalTokens:    idx.SumDocLen,
+		TopTerms:       []TermStat{},
+	}
+	if idx.DocCount > 0 {
+		stats.AvgDocLength = float64(idx.SumDocLen) / float64(idx.DocCount)
+	}
+
+	docs := make(map[string]bool)
+	for _, chunk := range idx.Docs {
+		docs[chunk.DocumentID] = true
+	}
+	stats.Documents = len(docs)
+
+	terms := make([]TermStat, 0, len(idx.InvertedIdx))
+	for term, postings := range idx.InvertedIdx {
+		stats.TotalPostings += len(postings)
+		ts := TermStat{Term: term, DocFreq: len(postings)}
+		for _, p := range postings {
+			ts.TotalFreq += p.TF
+		}
+		terms = append(terms, ts)
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if terms[i].DocFreq != terms[j].DocFreq {
+			return terms[i].DocFreq > terms[j].DocFreq
+		}
+		if terms[i].TotalFreq != terms[j].TotalFreq {
+			return terms[i].TotalFreq > terms[j].TotalFreq
+		}
+		return terms[i].Term < terms[j].Term
+	})
+	if len(terms) > statsTopTerms {
+		terms = terms[:statsTopTerms]
+	}
+	stats.TopTerms = append(stats.TopTerms, terms...)
+	return stats
+}
+
+// Stats returns the statistics of an existing collection. Unlike the other
+// methods it does not create the collection when it is missing.
+func (s *Store) Stats(collection string) (IndexStats, error) {
+	name := strings.ToLower(strings.TrimSpace(collection))
+	if name == "" {
+		return IndexStats{}, ErrInvalidCollection
+	}
+	if !s.hasCollection(name) {
+		return IndexStats{}, ErrCollectionNotFound
+	}
+
+	idx, err := s.GetIndex(name)
+	if err != nil {
+		return IndexStats{}, err
+	}
+	return idx.Stats(), nil
+}
+
+// hasCollection reports whether a collection is loaded or saved on disk.
+func (s *Store) hasCollection(name string) bool {
+	s.mu.RLock()
+	_, loaded := s.indexes[name]
+	s.mu.RUnlock()
+	if loaded {
+		return true
+	}
+	_, err := os.Stat(filepath.Join(s.baseDir, name+".index.json"))
+	return err == nil
+}
@@ -1,7 +1,9 @@
 package knowledge
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"sync"
@@ -13,6 +15,31 @@ type Store struct {
 	baseDir string
 	indexes map[string]*Index
 	mu      sync.RWMutex
+
+	// redactors holds the PII redactor for each collection that opted in.
+	redactors map[string]*Redactor
+	// cacheSize is the search cache size given to every index.
+	cacheSize int
+	// tokenizers holds the tokenizer chosen for collections that do not
+	// use the default one.
+	tokenizers map[string]string
+	// ingestWorkers is how many files IngestDir processes at once; 0 means
+	// one per CPU.
+	ingestWorkers int
+	// ingestMaxFiles and ingestMaxBytes cap one IngestDir call; 0 means
+	// the defaults.
+	ingestMaxFiles int
+	ingestMaxBytes int64
+
+	// queryLogs holds the collections whose searches are logged for
+	// analytics; logMu serializes access to the log files.
+	queryLogs map[string]bool
+	logMu     sync.Mutex
+
+	// manualFlush defers saving changed indexes until Flush; dirty holds
+	// the collections changed since.
+	manualFlush bool
+	dirty       map[string]bool
 }
 
 // NewStore initializes a new knowledge store in the given directory.
@@ -22,11 +49,83 @@ func NewStore(baseDir string) (*Store, error) {
 	}
 
 	return &Store{
-		baseDir: baseDir,
-		indexes: make(map[string]*Index),
+		baseDir:    baseDir,
+		indexes:    make(map[string]*Index),
+		redactors:  make(map[string]*Redactor),
+		queryLogs:  make(map[string]bool),
+		tokenizers: make(map[string]string),
+		dirty:      make(map[string]bool),
 	}, nil
 }
 
+// SetRedactor enables PII redaction for a collection. Documents added to it
+// afterwards are scrubbed before chunking; a nil redactor disables it.
+func (s *Store) SetRedactor(collection string, r *Redactor) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	collection = strings.ToLower(strings.TrimSpace(collection))
+	if r == nil {
+		delete(s.redactors, collection)
+		return
+	}
+	s.redactors[collection] = r
+}
+
+// SetSearchCacheSize enables a search cache of n entries on every
+// collection, including ones loaded later; n <= 0 disables it.
+func (s *Store) SetSearchCacheSize(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cacheSize = n
+	for _, idx := range s.indexes {
+		idx.SetCacheSize(n)
+	}
+}
+
+// SetTokenizer selects the tokenizer of a collection (see the Tokenizer
+// constants). An existing collection that used another one is re-indexed
+// and saved; a new one is created with it.
+func (s *Store) SetTokenizer(collection, mode string) error {
+	name := strings.ToLower(strings.TrimSpace(collection))
+	if name == "" {
+		return ErrInvalidCollection
+	}
+	mode, err := normalizeTokenizer(mode)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.tokenizers[name] = mode
+	s.mu.Unlock()
+
+	if !s.hasCollection(name) {
+		return nil
+	}
+	idx, err := s.GetIndex(name)
+	if err != nil {
+		return err
+	}
+	idx.mu.RLock()
+	unchanged := idx.Tokenizer == mode
+	idx.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+	if err := idx.SetTokenizer(mode); err != nil {
+		return err
+	}
+	return idx.Save(s.baseDir)
+}
+
+func (s *Store) redactorFor(collection string) *Redactor {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.redactors[strings.ToLower(strings.TrimSpace(collection))]
+}
+
 // GetIndex returns an index by name, loading it from disk if necessary.
 func (s *Store) GetIndex(name string) (*Index, error) {
 	s.mu.Lock()
@@ -35,7 +134,7 @@ func (s *Store) GetIndex(name string) (*Index, error) {
 	// Normalize name
 	name = strings.ToLower(strings.TrimSpace(name))
 	if name == "" {
-		return nil, fmt.Errorf("index name cannot be empty")
+		return nil, ErrInvalidCollection
 	}
 
 	// Check memory cache
@@ -46,12 +145,17 @@ func (s *Store) GetIndex(name string) (*Index, error) {
 	// Try loading from disk
 	idx, err := LoadIndex(name, s.baseDir)
 	if err == nil {
+		idx.SetCacheSize(s.cacheSize)
 		s.indexes[name] = idx
 		return idx, nil
 	}
 
 	// Create new index
 	idx = NewIndex(name)
+	if mode, ok := s.tokenizers[name]; ok {
+		idx.Tokenizer = mode
+	}
+	idx.SetCacheSize(s.cacheSize)
 	s.indexes[name] = idx
 
 	// Save immediately to ensure file exists
@@ -64,12 +168,190 @@ func (s *Store) GetIndex(name string) (*Index, error) {
 
 // AddDocument adds a document to a specific collection.
 func (s *Store) AddDocument(collection string, doc Document) error {
+	return s.addDocument(collection, doc, false)
+}
+
+// UpsertDocument adds a document to a collection, first removing any
+// indexed from the same source under another ID (see
+// Index.UpsertDocument).
+func (s *Store) UpsertDocument(collection string, doc Document) error {
+	return s.addDocument(collection, doc, true)
+}
+
+func (s *Store) addDocument(collection string, doc Document, upsert bool) error {
+	idx, err := s.GetIndex(collection)
+	if err != nil {
+		return err
+	}
+
+	prepareDocument(&doc)
+	if r := s.redactorFor(collection); r != nil {
+		doc.Title = r.Redact(doc.Title)
+		doc.Content = r.Redact(doc.Content)
+	}
+
+	add := idx.AddDocument
+	if upsert {
+		add = idx.UpsertDocument
+	}
+	if err := add(doc); err != nil {
+		return err
+	}
+
+	// Persist index after modification
+	return s.persist(idx)
+}
+
+// AddDocumentReader adds a document whose content is streamed from r, so
+// large files are indexed without loading them whole. When the collection
+// redacts PII, the content is read whole and redacted before chunking, so
+// a match split across chunks is still caught. A read error keeps the
+// earlier version of the document.
+func (s *Store) AddDocumentReader(collection string, doc Document, r io.Reader) error {
+	return s.addDocumentReader(collection, doc, r, false)
+}
+
+// UpsertDocumentReader is UpsertDocument for content streamed from r.
+func (s *Store) UpsertDocumentReader(collection string, doc Document, r io.Reader) error {
+	return s.addDocumentReader(collection, doc, r, true)
+}
+
+func (s *Store) addDocumentReader(collection string, doc Document, r io.Reader, upsert bool) error {
+	idx, err := s.GetIndex(collection)
+	if err != nil {
+		return err
+	}
+
+	prepareDocument(&doc)
+	var redact func(string) string
+	if rd := s.redactorFor(collection); rd != nil {
+		doc.Title = rd.Redact(doc.Title)
+		redact = rd.Redact
+	}
+
+	add := idx.addDocument
+	if upsert {
+		add = idx.upsertDocument
+	}
+	if err := add(doc, r, redact); err != nil {
+		return err
+	}
+
+	return s.persist(idx)
+}
+
+// AddDocuments adds several documents to a collection and saves it once,
+// which is much faster than calling AddDocument for each when the index is
+// large. Every document is chunked before any is added, so an error leaves
+// the collection unchanged.
+func (s *Store) AddDocuments(collection string, docs []Document) error {
 	idx, err := s.GetIndex(collection)
 	if err != nil {
 		return err
 	}
 
-	// Ensure document has ID and timestamps
+	redactor := s.redactorFor(collection)
+	chunked := make([]*chunkedDocument, 0, len(docs))
+	for _, doc := range docs {
+		prepareDocument(&doc)
+		if redactor != nil {
+			doc.Title = redactor.Redact(doc.Title)
+			doc.Content = redactor.Redact(doc.Content)
+		}
+		c, err := idx.chunkDocument(doc, strings.NewReader(doc.Content), nil)
+		if err != nil {
+			return err
+		}
+		chunked = append(chunked, c)
+	}
+	for _, c := range chunked {
+		idx.addChunked(c)
+	}
+
+	return s.persist(idx)
+}
+
+// SetAutoSave controls whether every change to a collection is saved right
+// away, which is the default. With it off, changes are kept in memory until
+// Flush; a crash loses them but leaves the last saved version of each
+// index intact. Turning it back on flushes the pending changes and returns
+// the Flush error.
+func (s *Store) SetAutoSave(enabled bool) error {
+	s.mu.Lock()
+	s.manualFlush = !enabled
+	s.mu.Unlock()
+	if enabled {
+		return s.Flush()
+	}
+	return nil
+}
+
+// Flush saves every collection changed since the last save. A collection
+// that fails to save stays pending, and the errors are returned joined.
+func (s *Store) Flush() error {
+	s.mu.Lock()
+	pending := make([]*Index, 0, len(s.dirty))
+	for name := range s.dirty {
+		if idx, ok := s.indexes[name]; ok {
+			pending = append(pending, idx)
+		}
+		delete(s.dirty, name)
+	}
+	s.mu.Unlock()
+
+	var errs []error
+	for _, idx := range pending {
+		if err := idx.Save(s.baseDir); err != nil {
+			s.markDirty(idx.Name)
+			errs = append(errs, fmt.Errorf("failed to save index '%s': %w", idx.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// persist saves a changed index, or marks it for the next Flush when
+// auto-save is off.
+func (s *Store) persist(idx *Index) error {
+	s.mu.RLock()
+	deferred := s.manualFlush
+	s.mu.RUnlock()
+	if deferred {
+		s.markDirty(idx.Name)
+		return nil
+	}
+	return idx.Save(s.baseDir)
+}
+
+func (s *Store) markDirty(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dirty[name] = true
+}
+
+// DeleteDocument removes a document from an existing collection and
+// persists the index. Unlike AddDocument it does not create the
+// collection when it is missing.
+func (s *Store) DeleteDocument(collection, docID string) error {
+	name := strings.ToLower(strings.TrimSpace(collection))
+	if name == "" {
+		return ErrInvalidCollection
+	}
+	if !s.hasCollection(name) {
+		return ErrCollectionNotFound
+	}
+
+	idx, err := s.GetIndex(name)
+	if err != nil {
+		return err
+	}
+	if _, err := idx.DeleteDocument(docID); err != nil {
+		return err
+	}
+	return s.persist(idx)
+}
+
+// prepareDocument ensures a document has an ID and timestamps.
+func prepareDocument(doc *Document) {
 	if doc.ID == "" {
 		doc.ID = fmt.Sprintf("doc_%d", time.Now().UnixNano())
 	}
@@ -78,23 +360,48 @@ func (s *Store) AddDocument(collection string, doc Document) error {
 		doc.CreatedAt = now
 	}
 	doc.UpdatedAt = now
+}
 
-	if err := idx.AddDocument(doc); err != nil {
-		return err
+// ChunkCount returns how many chunks of a document a collection holds.
+func (s *Store) ChunkCount(collection, docID string) (int, error) {
+	idx, err := s.GetIndex(collection)
+	if err != nil {
+		return 0, err
 	}
 
-	// Persist index after modification
-	return idx.Save(s.baseDir)
+	return idx.ChunkCount(docID), nil
 }
 
 // Search searches a specific collection.
 func (s *Store) Search(collection, query string, limit int) ([]SearchResult, error) {
+	page, err := s.SearchPaged(collection, query, SearchOptions{Limit: limit})
+	return page.Results, err
+}
+
+// SearchGrouped searches a specific collection, keeping at most perDoc
+// chunks of each document. See Index.SearchGrouped.
+func (s *Store) SearchGrouped(collection, query string, limit, perDoc int) ([]SearchResult, error) {
+	if perDoc < 1 {
+		perDoc = 1
+	}
+	page, err := s.SearchPaged(collection, query, SearchOptions{Limit: limit, PerDoc: perDoc})
+	return page.Results, err
+}
+
+// SearchPaged returns a page of the results of searching a specific
+// collection. See Index.SearchPaged.
+func (s *Store) SearchPaged(collection, query string, opts SearchOptions) (SearchPage, error) {
 	idx, err := s.GetIndex(collection)
 	if err != nil {
-		return nil, err
+		return SearchPage{}, err
 	}
 
-	return idx.Search(query, limit)
+	page, err := idx.SearchPaged(query, opts)
+	if err == nil {
+		// Analytics must never fail a search
+		_ = s.RecordQuery(collection, query, page.Results)
+	}
+	return page, err
 }
 
 // ListCollections returns a list of available collections.
@@ -131,6 +438,7 @@ func (s *Store) ListCollections() ([]Collection, error) {
 					docCount = idx.DocCount
 					chunkCount = len(idx.Docs)
 					// Cache it
+					idx.SetCacheSize(s.cacheSize)
 					s.indexes[name] = idx
 				}
 			}
@@ -0,0 +1,96 @@
+package knowledge
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Tokenizers an index can use. The choice is saved with the index.
+const (
+	// TokenizerUnicode splits text into runs of letters and digits in any
+	// script. It is the default.
+	TokenizerUnicode = "unicode"
+	// TokenizerCJKChars also splits Chinese, Japanese and Korean text,
+	// where spaces do not separate words reliably, into single characters.
+	TokenizerCJKChars = "cjk_chars"
+	// TokenizerCJKBigrams splits CJK text into overlapping character
+	// pairs, which rank phrases better than single characters.
+	TokenizerCJKBigrams = "cjk_bigrams"
+)
+
+// ErrUnknownTokenizer is returned for a tokenizer name that is not one of
+// the Tokenizer constants.
+var ErrUnknownTokenizer = fmt.Errorf("unknown tokenizer (use %s, %s or %s)",
+	TokenizerUnicode, TokenizerCJKChars, TokenizerCJKBigrams)
+
+// normalizeTokenizer maps "" to the default and rejects unknown names.
+func normalizeTokenizer(mode string) (string, error) {
+	switch mode = strings.ToLower(strings.TrimSpace(mode)); mode {
+	case "":
+		return TokenizerUnicode, nil
+	case TokenizerUnicode, TokenizerCJKChars, TokenizerCJKBigrams:
+		return mode, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownTokenizer, mode)
+}
+
+// tokenize lowercases text and splits it into words with the default
+// tokenizer.
+func tokenize(text string) []string {
+	return tokenizeWith(TokenizerUnicode, text)
+}
+
+// tokenizeWith splits text into lowercased words: runs of letters, digits
+// and combining marks. The CJK modes further segment runs of CJK
+// characters.
+func tokenizeWith(mode, text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && !unicode.IsMark(r)
+	})
+	if mode != TokenizerCJKChars && mode != TokenizerCJKBigrams {
+		return words
+	}
+
+	tokens := make([]string, 0, len(words))
+	for _, word := range words {
+		tokens = appendCJKTokens(tokens, word, mode == TokenizerCJKBigrams)
+	}
+	return tokens
+}
+
+// appendCJKTokens appends the tokens of word, splitting it where it
+// switches between CJK and other characters. CJK runs become single
+// characters or, with bigrams, overlapping pairs; a one-character run is
+// kept as is.
+func appendCJKTokens(tokens []string, word string, bigrams bool) []string {
+	runes := []rune(word)
+	for start := 0; start < len(runes); {
+		cjk := isCJK(runes[start])
+		end := start + 1
+		for end < len(runes) && isCJK(runes[end]) == cjk {
+			end++
+		}
+
+		switch {
+		case !cjk:
+			tokens = append(tokens, string(runes[start:end]))
+		case bigrams && end-start > 1:
+			for i := start; i < end-1; i++ {
+				tokens = append(tokens, string(runes[i:i+2]))
+			}
+		default:
+			for i := start; i < end; i++ {
+				tokens = append(tokens, string(runes[i]))
+			}
+		}
+		start = end
+	}
+	return tokens
+}
+
+// isCJK reports whether r is a Chinese, Japanese or Korean character,
+// whose words spaces do not reliably separate.
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
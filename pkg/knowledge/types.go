@@ -1,6 +1,9 @@
 package knowledge
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 // Document represents a source document (file, web page, etc.)
 type Document struct {
@@ -21,6 +24,38 @@ type Chunk struct {
 	Content    string                 `json:"content"`
 	Index      int                    `json:"index"` // Order in document
 	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+
+	// Source and Title are copied from the document so results can be
+	// cited without looking the document up.
+	Source string `json:"source,omitempty"`
+	Title  string `json:"title,omitempty"`
+	// Start and End are the chunk's character (rune) offsets in the
+	// document content, End exclusive. StartLine and EndLine are the
+	// 1-based lines they fall on.
+	Start     int `json:"start"`
+	End       int `json:"end"`
+	StartLine int `json:"start_line,omitempty"`
+	EndLine   int `json:"end_line,omitempty"`
+}
+
+// Citation describes where a chunk came from, e.g.
+// "notes/runbook.md:120-980 (lines 4-31)".
+func (c Chunk) Citation() string {
+	origin := c.Source
+	if origin == "" || origin == "manual" {
+		origin = c.Title
+	}
+	if origin == "" {
+		origin = c.DocumentID
+	}
+	if c.End == 0 {
+		return origin // indexed before offsets were recorded
+	}
+	citation := fmt.Sprintf("%s:%d-%d", origin, c.Start, c.End)
+	if c.StartLine > 0 {
+		citation += fmt.Sprintf(" (lines %d-%d)", c.StartLine, c.EndLine)
+	}
+	return citation
 }
 
 // SearchResult represents a matched chunk with score
@@ -29,6 +64,25 @@ type SearchResult struct {
 	Score      float64 `json:"score"`
 	DocumentID string  `json:"document_id"`
 	Source     string  `json:"source"`
+
+	// DocumentScore is the aggregate score of the chunk's document; it is
+	// only set by grouped searches.
+	DocumentScore float64 `json:"document_score,omitempty"`
+}
+
+// SearchOptions selects a page of search results.
+type SearchOptions struct {
+	Limit  int // results per page; documents when grouping
+	Offset int // results, or documents when grouping, to skip
+	PerDoc int // chunks kept per document; 0 leaves results ungrouped
+}
+
+// SearchPage is one page of search results. Total counts the matches on
+// all pages: chunks, or documents when grouping.
+type SearchPage struct {
+	Results []SearchResult `json:"results"`
+	Total   int            `json:"total"`
+	Offset  int            `json:"offset"`
 }
 
 // Collection represents a grouping of documents (e.g., "codebase", "notes")
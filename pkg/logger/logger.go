@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -9,6 +10,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/Sterlites/RDxClaw/pkg/reqctx"
 )
 
 type LogLevel int
@@ -34,6 +37,9 @@ var (
 	logger       *Logger
 	once         sync.Once
 	mu           sync.RWMutex
+
+	// componentLevels overrides currentLevel for individual components.
+	componentLevels = map[string]LogLevel{}
 )
 
 type Logger struct {
@@ -67,6 +73,93 @@ func GetLevel() LogLevel {
 	return currentLevel
 }
 
+// LevelName returns the name of a level, such as "DEBUG".
+func LevelName(level LogLevel) string {
+	return logLevelNames[level]
+}
+
+// ToggleDebug switches the global level to DEBUG, or back to INFO if it
+// already is DEBUG, and returns the new level.
+func ToggleDebug() LogLevel {
+	mu.Lock()
+	defer mu.Unlock()
+	if currentLevel == DEBUG {
+		currentLevel = INFO
+	} else {
+		currentLevel = DEBUG
+	}
+	return currentLevel
+}
+
+// CycleLevel moves the global level to the next of DEBUG, INFO, WARN and
+// ERROR, wrapping back to DEBUG, and returns the new level.
+func CycleLevel() LogLevel {
+	mu.Lock()
+	defer mu.Unlock()
+	if currentLevel >= ERROR {
+		currentLevel = DEBUG
+	} else {
+		currentLevel++
+	}
+	return currentLevel
+}
+
+// SetComponentLevel sets the minimum level logged for one component,
+// overriding the global level in either direction.
+func SetComponentLevel(component string, level LogLevel) {
+	mu.Lock()
+	defer mu.Unlock()
+	componentLevels[component] = level
+}
+
+// ClearComponentLevel makes a component follow the global level again.
+func ClearComponentLevel(component string) {
+	mu.Lock()
+	defer mu.Unlock()
+	delete(componentLevels, component)
+}
+
+// SetComponentLevels applies component -> level name overrides such as
+// {"voice": "debug", "health": "warn"}. Nothing is applied if any level
+// name is invalid.
+func SetComponentLevels(levels map[string]string) error {
+	parsed := make(map[string]LogLevel, len(levels))
+	for component, name := range levels {
+		level, err := ParseLevel(name)
+		if err != nil {
+			return fmt.Errorf("component %q: %w", component, err)
+		}
+		parsed[component] = level
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for component, level := range parsed {
+		componentLevels[component] = level
+	}
+	return nil
+}
+
+// ParseLevel parses a level name such as "debug" or "WARN".
+func ParseLevel(name string) (LogLevel, error) {
+	for level, levelName := range logLevelNames {
+		if strings.EqualFold(strings.TrimSpace(name), levelName) {
+			return level, nil
+		}
+	}
+	return INFO, fmt.Errorf("unknown log level %q", name)
+}
+
+// enabled reports whether a message at level from component is logged.
+func enabled(level LogLevel, component string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	if min, ok := componentLevels[component]; ok && component != "" {
+		return level >= min
+	}
+	return level >= currentLevel
+}
+
 func EnableFileLogging(filePath string) error {
 	mu.Lock()
 	defer mu.Unlock()
@@ -97,10 +190,15 @@ func DisableFileLogging() {
 }
 
 func logMessage(level LogLevel, component string, message string, fields map[string]interface{}) {
-	if level < currentLevel {
+	if !enabled(level, component) {
 		return
 	}
+	writeEntry(3, level, component, message, fields)
+}
 
+// writeEntry logs a message regardless of the configured levels. skip is
+// the number of stack frames between writeEntry and the caller to report.
+func writeEntry(skip int, level LogLevel, component string, message string, fields map[string]interface{}) {
 	entry := LogEntry{
 		Level:     logLevelNames[level],
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
@@ -109,7 +207,7 @@ func logMessage(level LogLevel, component string, message string, fields map[str
 		Fields:    fields,
 	}
 
-	if pc, file, line, ok := runtime.Caller(2); ok {
+	if pc, file, line, ok := runtime.Caller(skip); ok {
 		fn := runtime.FuncForPC(pc)
 		if fn != nil {
 			entry.Caller = fmt.Sprintf("%s:%d (%s)", file, line, fn.Name())
@@ -222,6 +320,46 @@ func ErrorCF(component string, message string, fields map[string]interface{}) {
 	logMessage(ERROR, component, message, fields)
 }
 
+// withContextFields adds the request ID and session key carried by ctx to
+// fields, without modifying the caller's map.
+func withContextFields(ctx context.Context, fields map[string]interface{}) map[string]interface{} {
+	requestID := reqctx.RequestID(ctx)
+	sessionKey := reqctx.SessionKey(ctx)
+	if requestID == "" && sessionKey == "" {
+		return fields
+	}
+
+	merged := make(map[string]interface{}, len(fields)+2)
+	for k, v := range fields {
+		merged[k] = v
+	}
+	if requestID != "" {
+		merged["request_id"] = requestID
+	}
+	if sessionKey != "" {
+		if _, ok := merged["session_key"]; !ok {
+			merged["session_key"] = sessionKey
+		}
+	}
+	return merged
+}
+
+func DebugCtx(ctx context.Context, component string, message string, fields map[string]interface{}) {
+	logMessage(DEBUG, component, message, withContextFields(ctx, fields))
+}
+
+func InfoCtx(ctx context.Context, component string, message string, fields map[string]interface{}) {
+	logMessage(INFO, component, message, withContextFields(ctx, fields))
+}
+
+func WarnCtx(ctx context.Context, component string, message string, fields map[string]interface{}) {
+	logMessage(WARN, component, message, withContextFields(ctx, fields))
+}
+
+func ErrorCtx(ctx context.Context, component string, message string, fields map[string]interface{}) {
+	logMessage(ERROR, component, message, withContextFields(ctx, fields))
+}
+
 func Fatal(message string) {
 	logMessage(FATAL, "", message, nil)
 }
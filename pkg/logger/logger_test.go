@@ -1,7 +1,14 @@
 package logger
 
 import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"strings"
 	"testing"
+
+	"github.com/Sterlites/RDxClaw/pkg/reqctx"
 )
 
 func TestLogLevelFiltering(t *testing.T) {
@@ -116,6 +123,31 @@ func TestSetGetLevel(t *testing.T) {
 	}
 }
 
+func TestToggleAndCycleLevel(t *testing.T) {
+	initialLevel := GetLevel()
+	defer SetLevel(initialLevel)
+
+	SetLevel(WARN)
+	if got := ToggleDebug(); got != DEBUG {
+		t.Errorf("ToggleDebug() from WARN = %v, want DEBUG", got)
+	}
+	if got := ToggleDebug(); got != INFO {
+		t.Errorf("ToggleDebug() from DEBUG = %v, want INFO", got)
+	}
+
+	want := []LogLevel{WARN, ERROR, DEBUG, INFO}
+	for _, level := range want {
+		if got := CycleLevel(); got != level || GetLevel() != level {
+			t.Errorf("CycleLevel() = %v, want %v", got, level)
+		}
+	}
+
+	SetLevel(FATAL)
+	if got := CycleLevel(); got != DEBUG {
+		t.Errorf("CycleLevel() from FATAL = %v, want DEBUG", got)
+	}
+}
+
 func TestLoggerHelperFunctions(t *testing.T) {
 	initialLevel := GetLevel()
 	defer SetLevel(initialLevel)
@@ -137,3 +169,89 @@ func TestLoggerHelperFunctions(t *testing.T) {
 	DebugC("test", "Debug with component")
 	WarnF("Warning with fields", map[string]interface{}{"key": "value"})
 }
+
+func TestWithContextFields(t *testing.T) {
+	fields := map[string]interface{}{"tool": "exec"}
+
+	if got := withContextFields(context.Background(), fields); len(got) != 1 {
+		t.Errorf("expected fields unchanged without context values, got %v", got)
+	}
+
+	ctx := reqctx.WithSessionKey(reqctx.WithRequestID(context.Background(), "req-1"), "cli:default")
+	got := withContextFields(ctx, fields)
+	if got["request_id"] != "req-1" {
+		t.Errorf("request_id = %v, want req-1", got["request_id"])
+	}
+	if got["session_key"] != "cli:default" {
+		t.Errorf("session_key = %v, want cli:default", got["session_key"])
+	}
+	if got["tool"] != "exec" {
+		t.Errorf("tool = %v, want exec", got["tool"])
+	}
+	if _, ok := fields["request_id"]; ok {
+		t.Error("caller's fields map should not be modified")
+	}
+}
+
+func TestComponentLevelOverride(t *testing.T) {
+	initialLevel := GetLevel()
+	defer SetLevel(initialLevel)
+	defer ClearComponentLevel("channels")
+	defer ClearComponentLevel("health")
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	SetLevel(INFO)
+	SetComponentLevel("channels", DEBUG)
+	SetComponentLevel("health", ERROR)
+
+	DebugC("channels", "channels debug")
+	DebugC("agent", "agent debug")
+	InfoC("health", "health ping")
+	ErrorC("health", "health failure")
+	InfoC("agent", "agent info")
+
+	out := buf.String()
+	for msg, want := range map[string]bool{
+		"channels debug": true,
+		"agent debug":    false,
+		"health ping":    false,
+		"health failure": true,
+		"agent info":     true,
+	} {
+		if got := strings.Contains(out, msg); got != want {
+			t.Errorf("%q logged = %v, want %v", msg, got, want)
+		}
+	}
+
+	// Overrides hold regardless of later global level changes
+	buf.Reset()
+	SetLevel(ERROR)
+	DebugC("channels", "still debugging")
+	if !strings.Contains(buf.String(), "still debugging") {
+		t.Error("component override should not follow the global level")
+	}
+
+	ClearComponentLevel("channels")
+	buf.Reset()
+	DebugC("channels", "after clear")
+	if buf.Len() != 0 {
+		t.Errorf("cleared component should follow the global level, got %q", buf.String())
+	}
+}
+
+func TestSetComponentLevels(t *testing.T) {
+	defer ClearComponentLevel("voice")
+
+	if err := SetComponentLevels(map[string]string{"voice": "bogus"}); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+	if err := SetComponentLevels(map[string]string{"voice": "Debug"}); err != nil {
+		t.Fatal(err)
+	}
+	if !enabled(DEBUG, "voice") {
+		t.Error("voice should log at DEBUG")
+	}
+}
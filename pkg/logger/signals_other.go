@@ -0,0 +1,9 @@
+//go:build !unix
+
+package logger
+
+import "context"
+
+// WatchLevelSignals is a no-op on platforms without SIGUSR1 and SIGUSR2;
+// use --debug or the logging config to set the level there.
+func WatchLevelSignals(ctx context.Context) {}
@@ -0,0 +1,36 @@
+//go:build unix
+
+package logger
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// WatchLevelSignals changes the global log level at runtime until ctx is
+// done: SIGUSR1 toggles between DEBUG and INFO, and SIGUSR2 cycles through
+// DEBUG, INFO, WARN and ERROR. Each change is logged whatever the level.
+func WatchLevelSignals(ctx context.Context) {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGUSR1, syscall.SIGUSR2)
+	go func() {
+		defer signal.Stop(sigChan)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case sig := <-sigChan:
+				var level LogLevel
+				if sig == syscall.SIGUSR1 {
+					level = ToggleDebug()
+				} else {
+					level = CycleLevel()
+				}
+				writeEntry(1, INFO, "logger", "Log level changed",
+					map[string]interface{}{"level": LevelName(level), "signal": sig.String()})
+			}
+		}
+	}()
+}
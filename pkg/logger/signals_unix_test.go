@@ -0,0 +1,41 @@
+//go:build unix
+
+package logger
+
+import (
+	"context"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestWatchLevelSignals(t *testing.T) {
+	initialLevel := GetLevel()
+	defer SetLevel(initialLevel)
+	SetLevel(INFO)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	WatchLevelSignals(ctx)
+
+	waitLevel := func(want LogLevel) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for GetLevel() != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("level = %v, want %v", GetLevel(), want)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+	waitLevel(DEBUG)
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR2); err != nil {
+		t.Fatal(err)
+	}
+	waitLevel(INFO)
+}
@@ -333,11 +333,7 @@ func resolverdxclawHome(override string) (string, error) {
 	if envHome := os.Getenv("RDXCLAW_HOME"); envHome != "" {
 		return expandHome(envHome), nil
 	}
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("resolving home directory: %w", err)
-	}
-	return filepath.Join(home, ".rdxclaw"), nil
+	return config.BaseDir(), nil
 }
 
 func resolveWorkspace(homeDir string) string {
@@ -3,6 +3,7 @@ package providers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/Sterlites/RDxClaw/pkg/auth"
@@ -46,7 +47,11 @@ func (p *ClaudeProvider) Chat(ctx context.Context, messages []Message, tools []T
 
 	resp, err := p.client.Messages.New(ctx, params, opts...)
 	if err != nil {
-		return nil, fmt.Errorf("claude API call: %w", err)
+		var apiErr *anthropic.Error
+		if errors.As(err, &apiErr) {
+			return nil, responseError("claude API call", apiErr.StatusCode, apiErr.Response, apiErr.RawJSON(), err)
+		}
+		return nil, callError("claude API call", err)
 	}
 
 	return parseClaudeResponse(resp), nil
@@ -69,6 +74,8 @@ func buildClaudeParams(messages []Message, tools []ToolDefinition, model string,
 				anthropicMessages = append(anthropicMessages,
 					anthropic.NewUserMessage(anthropic.NewToolResultBlock(msg.ToolCallID, msg.Content, false)),
 				)
+			} else if len(msg.Parts) > 0 {
+				anthropicMessages = append(anthropicMessages, anthropic.NewUserMessage(claudeContentBlocks(msg)...))
 			} else {
 				anthropicMessages = append(anthropicMessages,
 					anthropic.NewUserMessage(anthropic.NewTextBlock(msg.Content)),
@@ -122,6 +129,21 @@ func buildClaudeParams(messages []Message, tools []ToolDefinition, model string,
 	return params, nil
 }
 
+// claudeContentBlocks builds the text and image blocks of a multimodal message.
+func claudeContentBlocks(msg Message) []anthropic.ContentBlockParamUnion {
+	blocks := make([]anthropic.ContentBlockParamUnion, 0, len(msg.Parts)+1)
+	for _, part := range msg.Parts {
+		if part.Type != "image" {
+			continue
+		}
+		blocks = append(blocks, anthropic.NewImageBlockBase64(part.MimeType, part.Base64()))
+	}
+	if msg.Content != "" {
+		blocks = append(blocks, anthropic.NewTextBlock(msg.Content))
+	}
+	return blocks
+}
+
 func translateToolsForClaude(tools []ToolDefinition) []anthropic.ToolUnionParam {
 	result := make([]anthropic.ToolUnionParam, 0, len(tools))
 	for _, t := range tools {
@@ -208,3 +208,61 @@ func createAnthropicTestClient(baseURL, token string) *anthropic.Client {
 	)
 	return &c
 }
+
+func TestBuildClaudeParams_ImageParts(t *testing.T) {
+	messages := []Message{
+		{
+			Role:    "user",
+			Content: "What is in this picture?",
+			Parts:   []ContentPart{{Type: "image", MimeType: "image/png", Data: []byte("png")}},
+		},
+	}
+	params, err := buildClaudeParams(messages, nil, "claude-sonnet-4-5-20250929", map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("buildClaudeParams() error: %v", err)
+	}
+	if len(params.Messages) != 1 {
+		t.Fatalf("len(Messages) = %d, want 1", len(params.Messages))
+	}
+	blocks := params.Messages[0].Content
+	if len(blocks) != 2 {
+		t.Fatalf("len(Content) = %d, want 2", len(blocks))
+	}
+	if blocks[0].OfImage == nil {
+		t.Fatal("Content[0] should be an image block")
+	}
+	if blocks[1].OfText == nil || blocks[1].OfText.Text != "What is in this picture?" {
+		t.Errorf("Content[1] should be the text block")
+	}
+}
+
+func TestClaudeProvider_ChatErrorKinds(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   ErrorKind
+	}{
+		{"bad key", http.StatusUnauthorized, `{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`, ErrorAuth},
+		{"invalid request", http.StatusBadRequest, `{"type":"error","error":{"type":"invalid_request_error","message":"max_tokens: field required"}}`, ErrorBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			provider := NewClaudeProvider("test-token")
+			provider.client = createAnthropicTestClient(server.URL, "test-token")
+
+			_, err := provider.Chat(t.Context(), []Message{{Role: "user", Content: "Hello"}}, nil, "claude-sonnet-4-5-20250929", nil)
+			if got := ErrorKindOf(err); got != tt.want {
+				t.Errorf("ErrorKindOf(%v) = %q, want %q", err, got, tt.want)
+			}
+		})
+	}
+}
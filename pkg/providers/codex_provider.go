@@ -119,7 +119,10 @@ func (p *CodexProvider) Chat(ctx context.Context, messages []Message, tools []To
 			}
 		}
 		logger.ErrorCF("provider.codex", "Codex API call failed", fields)
-		return nil, fmt.Errorf("codex API call: %w", err)
+		if apiErr != nil {
+			return nil, responseError("codex API call", apiErr.StatusCode, apiErr.Response, apiErr.RawJSON(), err)
+		}
+		return nil, callError("codex API call", err)
 	}
 	if resp == nil {
 		fields := map[string]interface{}{
@@ -192,6 +195,13 @@ func buildCodexParams(messages []Message, tools []ToolDefinition, model string,
 						Output: responses.ResponseInputItemFunctionCallOutputOutputUnionParam{OfString: openai.Opt(msg.Content)},
 					},
 				})
+			} else if len(msg.Parts) > 0 {
+				inputItems = append(inputItems, responses.ResponseInputItemUnionParam{
+					OfMessage: &responses.EasyInputMessageParam{
+						Role:    responses.EasyInputMessageRoleUser,
+						Content: responses.EasyInputMessageContentUnionParam{OfInputItemContentList: codexContentList(msg)},
+					},
+				})
 			} else {
 				inputItems = append(inputItems, responses.ResponseInputItemUnionParam{
 					OfMessage: &responses.EasyInputMessageParam{
@@ -265,6 +275,23 @@ func buildCodexParams(messages []Message, tools []ToolDefinition, model string,
 	return params
 }
 
+// codexContentList builds the input_text and input_image parts of a multimodal message.
+func codexContentList(msg Message) responses.ResponseInputMessageContentListParam {
+	content := make(responses.ResponseInputMessageContentListParam, 0, len(msg.Parts)+1)
+	if msg.Content != "" {
+		content = append(content, responses.ResponseInputContentParamOfInputText(msg.Content))
+	}
+	for _, part := range msg.Parts {
+		if part.Type != "image" {
+			continue
+		}
+		image := responses.ResponseInputContentParamOfInputImage(responses.ResponseInputImageDetailAuto)
+		image.OfInputImage.ImageURL = openai.Opt(part.DataURL())
+		content = append(content, image)
+	}
+	return content
+}
+
 func translateToolsForCodex(tools []ToolDefinition) []responses.ToolUnionParam {
 	result := make([]responses.ToolUnionParam, 0, len(tools))
 	for _, t := range tools {
@@ -0,0 +1,106 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+)
+
+// ErrEmbeddingsNotSupported is returned by Embed for providers that cannot
+// compute embeddings.
+var ErrEmbeddingsNotSupported = errors.New("provider does not support embeddings")
+
+// EmbeddingResponse holds one vector per input text, in input order.
+type EmbeddingResponse struct {
+	Embeddings [][]float64
+	Model      string
+	Usage      *UsageInfo
+}
+
+// Embedder is implemented by providers that can compute text embeddings.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string, model string) (*EmbeddingResponse, error)
+}
+
+// Embed computes embeddings for texts with p, or returns
+// ErrEmbeddingsNotSupported if p does not implement Embedder.
+func Embed(ctx context.Context, p LLMProvider, texts []string, model string) (*EmbeddingResponse, error) {
+	if e, ok := p.(Embedder); ok {
+		return e.Embed(ctx, texts, model)
+	}
+	return nil, ErrEmbeddingsNotSupported
+}
+
+// Embed calls the /embeddings endpoint of the OpenAI-compatible API.
+func (p *HTTPProvider) Embed(ctx context.Context, texts []string, model string) (*EmbeddingResponse, error) {
+	if p.apiBase == "" {
+		return nil, fmt.Errorf("API base not configured")
+	}
+
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"model": model,
+		"input": texts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(callCtx, "POST", p.apiBase+"/embeddings", bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		if p.callTimedOut(ctx, callCtx) {
+			return nil, &TimeoutError{Timeout: p.timeout}
+		}
+		return nil, callError("failed to send request", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(resp, body)
+	}
+
+	var apiResp struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float64 `json:"embedding"`
+		} `json:"data"`
+		Model string     `json:"model"`
+		Usage *UsageInfo `json:"usage"`
+	}
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if len(apiResp.Data) != len(texts) {
+		return nil, fmt.Errorf("provider returned %d embeddings for %d inputs", len(apiResp.Data), len(texts))
+	}
+	sort.SliceStable(apiResp.Data, func(i, j int) bool { return apiResp.Data[i].Index < apiResp.Data[j].Index })
+
+	out := &EmbeddingResponse{Model: apiResp.Model, Usage: apiResp.Usage}
+	if out.Model == "" {
+		out.Model = model
+	}
+	for _, d := range apiResp.Data {
+		out.Embeddings = append(out.Embeddings, d.Embedding)
+	}
+	return out, nil
+}
@@ -0,0 +1,225 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultRequestTimeout bounds a single provider call when no timeout is
+// configured. It is separate from, and usually shorter than, the deadline
+// of the agent turn that makes the call.
+const DefaultRequestTimeout = 120 * time.Second
+
+// ErrNoProviderConfigured is returned when no provider has the API key,
+// base URL or auth method a model needs. It is a configuration problem,
+// not an outage, and retrying will not help.
+var ErrNoProviderConfigured = errors.New("no LLM provider configured")
+
+// ErrProviderUnavailable is returned by health checks when a configured
+// provider cannot be reached or is failing on its side. It is usually
+// transient.
+var ErrProviderUnavailable = errors.New("provider unavailable")
+
+// TimeoutError is returned when a single provider call exceeds its timeout
+// while the caller's context is still live. It is retriable.
+type TimeoutError struct {
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("provider call timed out after %s", e.Timeout)
+}
+
+// Unwrap lets errors.Is(err, context.DeadlineExceeded) match.
+func (e *TimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// Retriable reports that the same request may succeed if sent again.
+func (e *TimeoutError) Retriable() bool {
+	return true
+}
+
+// IsRetriable reports whether err, or any error it wraps, is a transient
+// provider failure worth retrying.
+func IsRetriable(err error) bool {
+	var r interface{ Retriable() bool }
+	return errors.As(err, &r) && r.Retriable()
+}
+
+// ErrorKind classifies why a provider call failed, so callers can react:
+// retry, fall back to another model, or report a configuration problem.
+type ErrorKind string
+
+const (
+	ErrorAuth            ErrorKind = "auth"             // credentials missing, wrong or not allowed
+	ErrorRateLimit       ErrorKind = "rate_limit"       // too many requests or tokens for now
+	ErrorServer          ErrorKind = "server_error"     // the provider failed on its side
+	ErrorBadRequest      ErrorKind = "bad_request"      // the provider rejected the request
+	ErrorContentFiltered ErrorKind = "content_filtered" // a safety filter refused the content
+	ErrorNetwork         ErrorKind = "network"          // no response came back
+)
+
+// Error is a provider call that failed with a known cause. StatusCode is
+// the provider's HTTP status, or 0 when no response came back.
+type Error struct {
+	Kind       ErrorKind
+	StatusCode int
+	Message    string
+	Err        error // underlying error, if any
+	// RetryAfter is how long the provider asked callers to wait before
+	// trying again, from its Retry-After header, or 0.
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Retriable reports whether the same request may succeed if sent again:
+// rate limits, server errors and network failures usually pass.
+func (e *Error) Retriable() bool {
+	switch e.Kind {
+	case ErrorRateLimit, ErrorServer, ErrorNetwork:
+		return true
+	}
+	return false
+}
+
+// ErrorKindOf returns the kind of the provider Error in err's chain, or ""
+// when the failure was not classified.
+func ErrorKindOf(err error) ErrorKind {
+	var pe *Error
+	if errors.As(err, &pe) {
+		return pe.Kind
+	}
+	return ""
+}
+
+// RetryAfterOf returns the wait the provider asked for in err's chain, or
+// 0 when it did not ask for one.
+func RetryAfterOf(err error) time.Duration {
+	var pe *Error
+	if errors.As(err, &pe) {
+		return pe.RetryAfter
+	}
+	return 0
+}
+
+// parseRetryAfter reads a Retry-After header, given in seconds or as an
+// HTTP date.
+func parseRetryAfter(header http.Header) time.Duration {
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(secs)*time.Second, 0)
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0)
+	}
+	return 0
+}
+
+// classifyStatus maps a failed response to an ErrorKind from its status
+// code and, for rejected requests, its body, since providers report
+// safety refusals as ordinary 400s.
+func classifyStatus(status int, body string) ErrorKind {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return ErrorAuth
+	case status == http.StatusTooManyRequests:
+		return ErrorRateLimit
+	case status == http.StatusRequestTimeout || status >= 500:
+		return ErrorServer
+	case isContentFiltered(body):
+		return ErrorContentFiltered
+	}
+	return ErrorBadRequest
+}
+
+// contentFilterCodes are the error codes providers use for requests a
+// safety filter refused: OpenAI's, and the inner code of Azure OpenAI.
+var contentFilterCodes = map[string]bool{
+	"content_filter":               true,
+	"content_policy_violation":     true,
+	"responsibleaipolicyviolation": true,
+}
+
+func isContentFiltered(body string) bool {
+	for _, code := range errorCodes(body) {
+		if contentFilterCodes[strings.ToLower(code)] {
+			return true
+		}
+	}
+	return false
+}
+
+// errorCodes returns the codes in an OpenAI-style error body,
+// {"error": {"code": ..., "type": ..., "innererror": {"code": ...}}}.
+func errorCodes(body string) []string {
+	var parsed struct {
+		Error struct {
+			Code       any    `json:"code"`
+			Type       string `json:"type"`
+			InnerError struct {
+				Code string `json:"code"`
+			} `json:"innererror"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(body), &parsed); err != nil {
+		return nil
+	}
+	var codes []string
+	if code, ok := parsed.Error.Code.(string); ok {
+		codes = append(codes, code)
+	}
+	return append(codes, parsed.Error.Type, parsed.Error.InnerError.Code)
+}
+
+// statusError builds the Error for a non-OK response of an
+// OpenAI-compatible endpoint.
+func statusError(resp *http.Response, body []byte) *Error {
+	return &Error{
+		Kind:       classifyStatus(resp.StatusCode, string(body)),
+		StatusCode: resp.StatusCode,
+		Message:    fmt.Sprintf("API request failed:\n  Status: %d\n  Body:   %s", resp.StatusCode, string(body)),
+		RetryAfter: parseRetryAfter(resp.Header),
+	}
+}
+
+// responseError classifies err, returned by a provider SDK for a call the
+// provider answered with a failed status. resp may be nil.
+func responseError(message string, status int, resp *http.Response, body string, err error) *Error {
+	pe := &Error{Kind: classifyStatus(status, body), StatusCode: status, Message: message, Err: err}
+	if resp != nil {
+		pe.RetryAfter = parseRetryAfter(resp.Header)
+	}
+	return pe
+}
+
+// callError classifies err, returned by an HTTP client or provider SDK for
+// a call that got no response. Failures to reach the provider are network
+// errors; others, such as the caller's context ending, are only wrapped.
+func callError(message string, err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+		return &Error{Kind: ErrorNetwork, Message: message, Err: err}
+	}
+	return fmt.Errorf("%s: %w", message, err)
+}
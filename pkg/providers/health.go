@@ -0,0 +1,99 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// HealthChecker is implemented by providers that can verify they are
+// reachable and authorised without spending tokens.
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// CheckHealth verifies that p can serve requests. Providers that do not
+// implement HealthChecker are assumed healthy.
+func CheckHealth(ctx context.Context, p LLMProvider) error {
+	if hc, ok := p.(HealthChecker); ok {
+		return hc.HealthCheck(ctx)
+	}
+	return nil
+}
+
+// HealthCheck lists the models of the OpenAI-compatible endpoint. Servers
+// that do not implement /models (404/405) still count as reachable;
+// authentication failures and server errors do not.
+func (p *HTTPProvider) HealthCheck(ctx context.Context) error {
+	if p.apiBase == "" {
+		return fmt.Errorf("%w: API base not configured", ErrNoProviderConfigured)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", p.apiBase+"/models", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: unreachable: %w", ErrProviderUnavailable, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("provider rejected credentials: status %d", resp.StatusCode)
+	case resp.StatusCode >= 500:
+		return fmt.Errorf("%w: status %d", ErrProviderUnavailable, resp.StatusCode)
+	}
+	return nil
+}
+
+// OnboardingHint tells users how to configure a provider.
+const OnboardingHint = "run `rdxclaw onboard` or set an API key under \"providers\" in config.json"
+
+// DescribeHealth explains a failed health check or provider setup in terms
+// of what to do about it: a missing configuration needs onboarding, while
+// an unavailable provider is an outage that should pass on its own.
+func DescribeHealth(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrNoProviderConfigured):
+		return fmt.Sprintf("configuration problem: %v; %s", err, OnboardingHint)
+	case errors.Is(err, ErrProviderUnavailable):
+		return fmt.Sprintf("temporary outage: %v; will retry", err)
+	}
+	return err.Error()
+}
+
+// unconfiguredProvider stands in for a provider that could not be created
+// because none is configured, so a server can still start and report the
+// problem through its health checks.
+type unconfiguredProvider struct {
+	err error
+}
+
+// NewUnconfiguredProvider returns a provider whose calls and health checks
+// fail with err, which should wrap ErrNoProviderConfigured.
+func NewUnconfiguredProvider(err error) LLMProvider {
+	return &unconfiguredProvider{err: err}
+}
+
+func (p *unconfiguredProvider) Chat(ctx context.Context, messages []Message, tools []ToolDefinition, model string, options map[string]interface{}) (*LLMResponse, error) {
+	return nil, p.err
+}
+
+func (p *unconfiguredProvider) GetDefaultModel() string {
+	return ""
+}
+
+func (p *unconfiguredProvider) HealthCheck(ctx context.Context) error {
+	return p.err
+}
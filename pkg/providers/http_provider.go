@@ -10,6 +10,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -25,12 +26,11 @@ type HTTPProvider struct {
 	apiKey     string
 	apiBase    string
 	httpClient *http.Client
+	timeout    time.Duration // per-call timeout for Chat
 }
 
 func NewHTTPProvider(apiKey, apiBase, proxy string) *HTTPProvider {
-	client := &http.Client{
-		Timeout: 300 * time.Second, // 5 minutes to accommodate slow large models
-	}
+	client := &http.Client{}
 
 	if proxy != "" {
 		proxyURL, err := url.Parse(proxy)
@@ -45,14 +45,70 @@ func NewHTTPProvider(apiKey, apiBase, proxy string) *HTTPProvider {
 		apiKey:     apiKey,
 		apiBase:    strings.TrimRight(apiBase, "/"),
 		httpClient: client,
+		timeout:    DefaultRequestTimeout,
 	}
 }
 
+// SetTimeout sets the per-call timeout applied to each Chat request.
+// Non-positive values restore DefaultRequestTimeout.
+func (p *HTTPProvider) SetTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultRequestTimeout
+	}
+	p.timeout = timeout
+}
+
 func (p *HTTPProvider) Chat(ctx context.Context, messages []Message, tools []ToolDefinition, model string, options map[string]interface{}) (*LLMResponse, error) {
 	if p.apiBase == "" {
 		return nil, fmt.Errorf("API base not configured")
 	}
 
+	requestBody := p.chatRequestBody(messages, tools, model, options)
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(callCtx, "POST", p.apiBase+"/chat/completions", bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		if p.callTimedOut(ctx, callCtx) {
+			return nil, &TimeoutError{Timeout: p.timeout}
+		}
+		return nil, callError("failed to send request", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		if p.callTimedOut(ctx, callCtx) {
+			return nil, &TimeoutError{Timeout: p.timeout}
+		}
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(resp, body)
+	}
+
+	return p.parseResponse(body)
+}
+
+// chatRequestBody builds the chat completions request shared by Chat and
+// ChatStream.
+func (p *HTTPProvider) chatRequestBody(messages []Message, tools []ToolDefinition, model string, options map[string]interface{}) map[string]interface{} {
 	// Strip provider prefix from model name (e.g., groq/openai/gpt-4o -> openai/gpt-4o, ollama/llama3 -> llama3)
 	if idx := strings.Index(model, "/"); idx != -1 {
 		prefix := model[:idx]
@@ -63,7 +119,7 @@ func (p *HTTPProvider) Chat(ctx context.Context, messages []Message, tools []Too
 
 	requestBody := map[string]interface{}{
 		"model":    model,
-		"messages": messages,
+		"messages": openAIMessages(messages),
 	}
 
 	if len(tools) > 0 {
@@ -84,37 +140,55 @@ func (p *HTTPProvider) Chat(ctx context.Context, messages []Message, tools []Too
 		requestBody["temperature"] = temperature
 	}
 
-	jsonData, err := json.Marshal(requestBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", p.apiBase+"/chat/completions", bytes.NewReader(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
+	return requestBody
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	if p.apiKey != "" {
-		req.Header.Set("Authorization", "Bearer "+p.apiKey)
-	}
+// callTimedOut reports whether a call failed because its own timeout fired,
+// as opposed to the caller's context being cancelled or expiring.
+func (p *HTTPProvider) callTimedOut(ctx, callCtx context.Context) bool {
+	return ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded)
+}
 
-	resp, err := p.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
+// openAIMessages converts messages to the OpenAI wire format. Messages with
+// content parts are sent with an array of text and image_url parts.
+func openAIMessages(messages []Message) []interface{} {
+	result := make([]interface{}, 0, len(messages))
+	for _, msg := range messages {
+		if len(msg.Parts) == 0 {
+			result = append(result, msg)
+			continue
+		}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
+		content := make([]map[string]interface{}, 0, len(msg.Parts)+1)
+		if msg.Content != "" {
+			content = append(content, map[string]interface{}{
+				"type": "text",
+				"text": msg.Content,
+			})
+		}
+		for _, part := range msg.Parts {
+			if part.Type != "image" {
+				continue
+			}
+			content = append(content, map[string]interface{}{
+				"type":      "image_url",
+				"image_url": map[string]string{"url": part.DataURL()},
+			})
+		}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed:\n  Status: %d\n  Body:   %s", resp.StatusCode, string(body))
+		wire := map[string]interface{}{
+			"role":    msg.Role,
+			"content": content,
+		}
+		if len(msg.ToolCalls) > 0 {
+			wire["tool_calls"] = msg.ToolCalls
+		}
+		if msg.ToolCallID != "" {
+			wire["tool_call_id"] = msg.ToolCallID
+		}
+		result = append(result, wire)
 	}
-
-	return p.parseResponse(body)
+	return result
 }
 
 func (p *HTTPProvider) parseResponse(body []byte) (*LLMResponse, error) {
@@ -214,8 +288,14 @@ func createCodexAuthProvider() (LLMProvider, error) {
 }
 
 func CreateProvider(cfg *config.Config) (LLMProvider, error) {
-	model := cfg.Agents.Defaults.Model
-	providerName := strings.ToLower(cfg.Agents.Defaults.Provider)
+	return CreateProviderFor(cfg, cfg.Agents.Defaults.Provider, cfg.Agents.Defaults.Model)
+}
+
+// CreateProviderFor creates a provider for the given provider name and model,
+// using the credentials configured in cfg. An empty providerName detects the
+// provider from the model name.
+func CreateProviderFor(cfg *config.Config, providerName, model string) (LLMProvider, error) {
+	providerName = strings.ToLower(providerName)
 
 	var apiKey, apiBase, proxy string
 
@@ -403,18 +483,20 @@ func CreateProvider(cfg *config.Config) (LLMProvider, error) {
 					apiBase = "https://openrouter.ai/api/v1"
 				}
 			} else {
-				return nil, fmt.Errorf("no API key configured for model: %s", model)
+				return nil, fmt.Errorf("%w: no API key configured for model: %s", ErrNoProviderConfigured, model)
 			}
 		}
 	}
 
 	if apiKey == "" && !strings.HasPrefix(model, "bedrock/") {
-		return nil, fmt.Errorf("no API key configured for provider (model: %s)", model)
+		return nil, fmt.Errorf("%w: no API key configured for provider (model: %s)", ErrNoProviderConfigured, model)
 	}
 
 	if apiBase == "" {
-		return nil, fmt.Errorf("no API base configured for provider (model: %s)", model)
+		return nil, fmt.Errorf("%w: no API base configured for provider (model: %s)", ErrNoProviderConfigured, model)
 	}
 
-	return NewHTTPProvider(apiKey, apiBase, proxy), nil
+	provider := NewHTTPProvider(apiKey, apiBase, proxy)
+	provider.SetTimeout(time.Duration(cfg.Providers.RequestTimeout) * time.Second)
+	return provider, nil
 }
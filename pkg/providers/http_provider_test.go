@@ -0,0 +1,325 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sterlites/RDxClaw/pkg/config"
+)
+
+func TestOpenAIMessages_ImageParts(t *testing.T) {
+	messages := []Message{
+		{Role: "system", Content: "You are helpful"},
+		{
+			Role:    "user",
+			Content: "Describe this",
+			Parts:   []ContentPart{{Type: "image", MimeType: "image/jpeg", Data: []byte("jpg")}},
+		},
+	}
+
+	data, err := json.Marshal(openAIMessages(messages))
+	if err != nil {
+		t.Fatalf("Marshal() error: %v", err)
+	}
+
+	var wire []struct {
+		Role    string          `json:"role"`
+		Content json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(data, &wire); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+
+	var system string
+	if err := json.Unmarshal(wire[0].Content, &system); err != nil || system != "You are helpful" {
+		t.Errorf("system content = %s, want plain string", wire[0].Content)
+	}
+
+	var parts []struct {
+		Type     string `json:"type"`
+		Text     string `json:"text"`
+		ImageURL struct {
+			URL string `json:"url"`
+		} `json:"image_url"`
+	}
+	if err := json.Unmarshal(wire[1].Content, &parts); err != nil {
+		t.Fatalf("user content should be an array of parts: %v", err)
+	}
+	if len(parts) != 2 {
+		t.Fatalf("len(parts) = %d, want 2", len(parts))
+	}
+	if parts[0].Type != "text" || parts[0].Text != "Describe this" {
+		t.Errorf("parts[0] = %+v, want text part", parts[0])
+	}
+	if parts[1].Type != "image_url" || parts[1].ImageURL.URL != "data:image/jpeg;base64,anBn" {
+		t.Errorf("parts[1] = %+v, want image_url data URL", parts[1])
+	}
+}
+
+func TestSupportsVision(t *testing.T) {
+	tests := map[string]bool{
+		"gpt-4o":                     true,
+		"claude-sonnet-4-5-20250929": true,
+		"gemini-2.0-flash":           true,
+		"qwen2.5-vl-7b":              true,
+		"llama3":                     false,
+		"deepseek-chat":              false,
+	}
+	for model, want := range tests {
+		if got := SupportsVision(model); got != want {
+			t.Errorf("SupportsVision(%q) = %v, want %v", model, got, want)
+		}
+	}
+}
+
+func TestHTTPProviderHealthCheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		wantErr     bool
+		unavailable bool
+	}{
+		{name: "ok", status: http.StatusOK},
+		{name: "models not implemented", status: http.StatusNotFound},
+		{name: "bad key", status: http.StatusUnauthorized, wantErr: true},
+		{name: "server error", status: http.StatusBadGateway, wantErr: true, unavailable: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/models" {
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			err := CheckHealth(context.Background(), NewHTTPProvider("key", server.URL, ""))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckHealth() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrProviderUnavailable) != tt.unavailable {
+				t.Errorf("CheckHealth() error = %v, want unavailable %v", err, tt.unavailable)
+			}
+		})
+	}
+
+	t.Run("unreachable", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+		err := CheckHealth(context.Background(), NewHTTPProvider("key", server.URL, ""))
+		if !errors.Is(err, ErrProviderUnavailable) {
+			t.Errorf("expected an unavailable error for unreachable provider, got %v", err)
+		}
+		if got := DescribeHealth(err); !strings.HasPrefix(got, "temporary outage") {
+			t.Errorf("DescribeHealth() = %q, want a temporary outage", got)
+		}
+	})
+}
+
+func TestNoProviderConfigured(t *testing.T) {
+	_, err := CreateProviderFor(&config.Config{}, "", "gpt-4o")
+	if !errors.Is(err, ErrNoProviderConfigured) {
+		t.Fatalf("CreateProviderFor() error = %v, want ErrNoProviderConfigured", err)
+	}
+
+	provider := NewUnconfiguredProvider(err)
+	if health := CheckHealth(context.Background(), provider); !errors.Is(health, ErrNoProviderConfigured) {
+		t.Errorf("CheckHealth() error = %v, want ErrNoProviderConfigured", health)
+	}
+	got := DescribeHealth(err)
+	if !strings.HasPrefix(got, "configuration problem") || !strings.Contains(got, "rdxclaw onboard") {
+		t.Errorf("DescribeHealth() = %q, want a configuration problem pointing at onboarding", got)
+	}
+}
+
+func TestHTTPProviderChatTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	p := NewHTTPProvider("key", server.URL, "")
+	p.SetTimeout(50 * time.Millisecond)
+
+	start := time.Now()
+	_, err := p.Chat(context.Background(), []Message{{Role: "user", Content: "hi"}}, nil, "test-model", nil)
+	if err == nil {
+		t.Fatal("expected timeout error")
+	}
+
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("expected *TimeoutError, got %T: %v", err, err)
+	}
+	if !IsRetriable(err) {
+		t.Error("timeout should be retriable")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("timeout should match context.DeadlineExceeded")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Chat returned after %s, expected the call timeout to fire", elapsed)
+	}
+
+	// A cancelled turn is not a provider timeout and must not be retried.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = p.Chat(ctx, []Message{{Role: "user", Content: "hi"}}, nil, "test-model", nil)
+	if err == nil || IsRetriable(err) {
+		t.Errorf("cancelled call: expected non-retriable error, got %v", err)
+	}
+}
+
+func TestHTTPProviderEmbed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/embeddings" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		// Out of order on purpose: results are matched by index.
+		w.Write([]byte(`{"model":"embed-1","data":[
+			{"index":1,"embedding":[0.3,0.4]},
+			{"index":0,"embedding":[0.1,0.2]}
+		],"usage":{"prompt_tokens":4,"total_tokens":4}}`))
+	}))
+	defer server.Close()
+
+	resp, err := Embed(context.Background(), NewHTTPProvider("key", server.URL, ""), []string{"a", "b"}, "embed-1")
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	if len(resp.Embeddings) != 2 || resp.Embeddings[0][0] != 0.1 || resp.Embeddings[1][0] != 0.3 {
+		t.Errorf("Embeddings = %v, want input order", resp.Embeddings)
+	}
+	if resp.Usage == nil || resp.Usage.TotalTokens != 4 {
+		t.Errorf("Usage = %+v, want 4 total tokens", resp.Usage)
+	}
+
+	if _, err := Embed(context.Background(), &stubProvider{}, []string{"a"}, "m"); !errors.Is(err, ErrEmbeddingsNotSupported) {
+		t.Errorf("Embed() on a chat-only provider error = %v, want ErrEmbeddingsNotSupported", err)
+	}
+}
+
+func TestHTTPProviderChatStream(t *testing.T) {
+	events := []string{
+		`{"choices":[{"delta":{"role":"assistant","content":"Hel"}}]}`,
+		`{"choices":[{"delta":{"content":"lo"}}]}`,
+		`{"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"read_file","arguments":"{\"pa"}}]}}]}`,
+		`{"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"th\":\"a.txt\"}"}}]}}]}`,
+		`{"choices":[{"delta":{},"finish_reason":"tool_calls"}]}`,
+		`{"choices":[],"usage":{"prompt_tokens":7,"completion_tokens":3,"total_tokens":10}}`,
+	}
+	var stream bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		stream, _ = body["stream"].(bool)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, e := range events {
+			fmt.Fprintf(w, "data: %s\n\n", e)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	p := NewHTTPProvider("key", server.URL, "")
+	var deltas []string
+	resp, err := p.ChatStream(context.Background(), []Message{{Role: "user", Content: "hi"}}, nil, "gpt-4o", nil, func(d string) {
+		deltas = append(deltas, d)
+	})
+	if err != nil {
+		t.Fatalf("ChatStream() error: %v", err)
+	}
+	if !stream {
+		t.Error("request should set stream=true")
+	}
+	if strings.Join(deltas, "|") != "Hel|lo" {
+		t.Errorf("deltas = %q, want [Hel lo]", deltas)
+	}
+	if resp.Content != "Hello" || resp.FinishReason != "tool_calls" {
+		t.Errorf("resp = %+v, want content Hello and finish reason tool_calls", resp)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Name != "read_file" || resp.ToolCalls[0].Arguments["path"] != "a.txt" {
+		t.Errorf("tool calls = %+v, want read_file(path=a.txt)", resp.ToolCalls)
+	}
+	if resp.Usage == nil || resp.Usage.TotalTokens != 10 {
+		t.Errorf("usage = %+v, want 10 total tokens", resp.Usage)
+	}
+}
+
+func TestHTTPProviderErrorKinds(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		want      ErrorKind
+		retriable bool
+	}{
+		{"bad key", http.StatusUnauthorized, `{"error":{"message":"Incorrect API key provided","code":"invalid_api_key"}}`, ErrorAuth, false},
+		{"no access", http.StatusForbidden, `{"error":{"message":"Project does not have access to model"}}`, ErrorAuth, false},
+		{"rate limit", http.StatusTooManyRequests, `{"error":{"message":"Rate limit reached for tokens per min","type":"tokens"}}`, ErrorRateLimit, true},
+		{"overloaded", http.StatusServiceUnavailable, `{"error":{"message":"The server is overloaded"}}`, ErrorServer, true},
+		{"context length", http.StatusBadRequest, `{"error":{"message":"This model's maximum context length is 8192 tokens","code":"context_length_exceeded"}}`, ErrorBadRequest, false},
+		{"content filter", http.StatusBadRequest, `{"error":{"message":"The response was filtered","code":"content_filter"}}`, ErrorContentFiltered, false},
+		{"azure content filter", http.StatusBadRequest, `{"error":{"message":"Filtered","code":"content_filter","innererror":{"code":"ResponsibleAIPolicyViolation"}}}`, ErrorContentFiltered, false},
+		{"safety in message", http.StatusBadRequest, `{"error":{"message":"Invalid value for safety_identifier","code":"invalid_value"}}`, ErrorBadRequest, false},
+		{"unknown model", http.StatusNotFound, `{"error":{"message":"The model does not exist"}}`, ErrorBadRequest, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			_, err := NewHTTPProvider("key", server.URL, "").Chat(context.Background(), []Message{{Role: "user", Content: "hi"}}, nil, "test-model", nil)
+			var pe *Error
+			if !errors.As(err, &pe) {
+				t.Fatalf("Chat() error = %v, want a provider Error", err)
+			}
+			if pe.Kind != tt.want || pe.StatusCode != tt.status {
+				t.Errorf("Chat() error kind = %s, status %d, want %s, status %d", pe.Kind, pe.StatusCode, tt.want, tt.status)
+			}
+			if IsRetriable(err) != tt.retriable {
+				t.Errorf("IsRetriable() = %v, want %v", IsRetriable(err), tt.retriable)
+			}
+		})
+	}
+
+	t.Run("retry after", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+		_, err := NewHTTPProvider("key", server.URL, "").Chat(context.Background(), []Message{{Role: "user", Content: "hi"}}, nil, "test-model", nil)
+		if got := RetryAfterOf(err); got != 7*time.Second {
+			t.Errorf("RetryAfterOf() = %v, want 7s", got)
+		}
+	})
+
+	t.Run("network", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+		_, err := NewHTTPProvider("key", server.URL, "").Chat(context.Background(), []Message{{Role: "user", Content: "hi"}}, nil, "test-model", nil)
+		if ErrorKindOf(err) != ErrorNetwork || !IsRetriable(err) {
+			t.Errorf("Chat() error = %v, want a retriable network error", err)
+		}
+	})
+}
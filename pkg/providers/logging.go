@@ -0,0 +1,135 @@
+package providers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/Sterlites/RDxClaw/pkg/logger"
+)
+
+// maxTrackedPrompts bounds how many prompt hashes LoggingProvider counts
+// repeats for; the counts start over once it is reached.
+const maxTrackedPrompts = 4096
+
+// LoggingProvider wraps a provider and logs every Chat call with a hash of
+// its prompt, never the prompt itself, along with token counts, latency
+// and how often the same prompt was seen before. Repeated hashes point at
+// prompts that would benefit from caching.
+type LoggingProvider struct {
+	LLMProvider
+
+	mu   sync.Mutex
+	seen map[string]int
+}
+
+// NewLoggingProvider wraps p with request logging.
+func NewLoggingProvider(p LLMProvider) *LoggingProvider {
+	return &LoggingProvider{LLMProvider: p, seen: make(map[string]int)}
+}
+
+// PromptHash returns a short, stable hash of the messages and tools sent
+// to model. Identical requests hash the same.
+func PromptHash(model string, messages []Message, tools []ToolDefinition) string {
+	h := sha256.New()
+	h.Write([]byte(model))
+	h.Write([]byte{0})
+	enc := json.NewEncoder(h)
+	enc.Encode(messages)
+	for _, m := range messages {
+		for _, part := range m.Parts {
+			h.Write(part.Data)
+		}
+	}
+	enc.Encode(tools)
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// systemHash hashes only the system messages, which usually form the
+// cacheable prefix of a prompt.
+func systemHash(messages []Message) string {
+	h := sha256.New()
+	for _, m := range messages {
+		if m.Role == "system" {
+			h.Write([]byte(m.Content))
+			h.Write([]byte{0})
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+func (p *LoggingProvider) Chat(ctx context.Context, messages []Message, tools []ToolDefinition, model string, options map[string]interface{}) (*LLMResponse, error) {
+	return p.logged(messages, tools, model, func() (*LLMResponse, error) {
+		return p.LLMProvider.Chat(ctx, messages, tools, model, options)
+	})
+}
+
+// ChatStream forwards to the wrapped provider so wrapping does not hide
+// it from ChatStream, and logs the call like Chat.
+func (p *LoggingProvider) ChatStream(ctx context.Context, messages []Message, tools []ToolDefinition, model string, options map[string]interface{}, onDelta func(string)) (*LLMResponse, error) {
+	return p.logged(messages, tools, model, func() (*LLMResponse, error) {
+		return ChatStream(ctx, p.LLMProvider, messages, tools, model, options, onDelta)
+	})
+}
+
+// logged runs call and logs it as a request for model.
+func (p *LoggingProvider) logged(messages []Message, tools []ToolDefinition, model string, call func() (*LLMResponse, error)) (*LLMResponse, error) {
+	hash := PromptHash(model, messages, tools)
+	repeats := p.countPrompt(hash)
+
+	start := time.Now()
+	resp, err := call()
+
+	fields := map[string]interface{}{
+		"model":       model,
+		"prompt_hash": hash,
+		"system_hash": systemHash(messages),
+		"messages":    len(messages),
+		"repeats":     repeats,
+		"latency_ms":  time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+		logger.WarnCF("provider.requests", "LLM request failed", fields)
+		return resp, err
+	}
+	if resp.Usage != nil {
+		fields["prompt_tokens"] = resp.Usage.PromptTokens
+		fields["completion_tokens"] = resp.Usage.CompletionTokens
+		if repeats > 0 {
+			// Tokens a response cache keyed on the prompt would have saved.
+			fields["cacheable_tokens"] = resp.Usage.PromptTokens + resp.Usage.CompletionTokens
+		}
+	}
+	logger.InfoCF("provider.requests", "LLM request", fields)
+	return resp, err
+}
+
+// HealthCheck forwards to the wrapped provider so wrapping does not hide
+// it from CheckHealth.
+func (p *LoggingProvider) HealthCheck(ctx context.Context) error {
+	return CheckHealth(ctx, p.LLMProvider)
+}
+
+// Embed forwards to the wrapped provider so wrapping does not hide it
+// from Embed.
+func (p *LoggingProvider) Embed(ctx context.Context, texts []string, model string) (*EmbeddingResponse, error) {
+	return Embed(ctx, p.LLMProvider, texts, model)
+}
+
+// countPrompt records a prompt hash and returns how many times it was seen
+// before.
+func (p *LoggingProvider) countPrompt(hash string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.seen[hash]; !ok && len(p.seen) >= maxTrackedPrompts {
+		p.seen = make(map[string]int)
+	}
+	n := p.seen[hash]
+	p.seen[hash] = n + 1
+	return n
+}
@@ -0,0 +1,65 @@
+package providers
+
+import (
+	"context"
+	"testing"
+)
+
+type stubProvider struct{}
+
+func (stubProvider) Chat(ctx context.Context, messages []Message, tools []ToolDefinition, model string, options map[string]interface{}) (*LLMResponse, error) {
+	return &LLMResponse{Content: "ok", Usage: &UsageInfo{PromptTokens: 10, CompletionTokens: 2}}, nil
+}
+
+func (stubProvider) GetDefaultModel() string { return "stub" }
+
+func TestPromptHash(t *testing.T) {
+	messages := []Message{
+		{Role: "system", Content: "You are helpful."},
+		{Role: "user", Content: "What is the weather?"},
+	}
+	same := []Message{
+		{Role: "system", Content: "You are helpful."},
+		{Role: "user", Content: "What is the weather?"},
+	}
+	other := []Message{
+		{Role: "system", Content: "You are helpful."},
+		{Role: "user", Content: "What is the time?"},
+	}
+
+	h := PromptHash("gpt-4o", messages, nil)
+	if got := PromptHash("gpt-4o", same, nil); got != h {
+		t.Errorf("identical prompts hash differently: %s vs %s", h, got)
+	}
+	if got := PromptHash("gpt-4o", other, nil); got == h {
+		t.Error("distinct prompts share a hash")
+	}
+	if got := PromptHash("gpt-4o-mini", messages, nil); got == h {
+		t.Error("the same prompt to another model shares a hash")
+	}
+	tools := []ToolDefinition{{Type: "function", Function: ToolFunctionDefinition{Name: "search"}}}
+	if got := PromptHash("gpt-4o", messages, tools); got == h {
+		t.Error("a prompt with tools shares a hash with one without")
+	}
+	if got := systemHash(other); got != systemHash(messages) {
+		t.Error("prompts with the same system message should share the system hash")
+	}
+}
+
+func TestLoggingProvider_CountsRepeats(t *testing.T) {
+	p := NewLoggingProvider(stubProvider{})
+	messages := []Message{{Role: "user", Content: "hi"}}
+
+	for i := 0; i < 3; i++ {
+		resp, err := p.Chat(context.Background(), messages, nil, "stub", nil)
+		if err != nil || resp.Content != "ok" {
+			t.Fatalf("Chat() = %v, %v", resp, err)
+		}
+	}
+	if n := p.countPrompt(PromptHash("stub", messages, nil)); n != 3 {
+		t.Errorf("prompt seen %d times before, want 3", n)
+	}
+	if err := CheckHealth(context.Background(), p); err != nil {
+		t.Errorf("CheckHealth() = %v, want nil for a provider without health checks", err)
+	}
+}
@@ -0,0 +1,147 @@
+package providers
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/Sterlites/RDxClaw/pkg/config"
+)
+
+// ModelInfo describes what a model can do. Costs are in USD per million
+// tokens; zero means unknown or free.
+type ModelInfo struct {
+	ContextWindow  int
+	SupportsTools  bool
+	SupportsVision bool
+	SupportsJSON   bool
+	InputCost      float64
+	OutputCost     float64
+}
+
+// DefaultModelInfo is assumed for models the registry does not know: a
+// modest context window, tool calling, and no images or JSON mode.
+var DefaultModelInfo = ModelInfo{
+	ContextWindow: 8192,
+	SupportsTools: true,
+}
+
+// builtinModels maps model name fragments to their capabilities. A model
+// matches every fragment it contains; the longest fragment wins, so
+// "gpt-4o-mini" is preferred over "gpt-4o".
+var builtinModels = map[string]ModelInfo{
+	// OpenAI
+	"gpt-3.5-turbo": {ContextWindow: 16385, SupportsTools: true, SupportsJSON: true, InputCost: 0.5, OutputCost: 1.5},
+	"gpt-4o":        {ContextWindow: 128000, SupportsTools: true, SupportsVision: true, SupportsJSON: true, InputCost: 2.5, OutputCost: 10},
+	"gpt-4o-mini":   {ContextWindow: 128000, SupportsTools: true, SupportsVision: true, SupportsJSON: true, InputCost: 0.15, OutputCost: 0.6},
+	"gpt-4.1":       {ContextWindow: 1047576, SupportsTools: true, SupportsVision: true, SupportsJSON: true, InputCost: 2, OutputCost: 8},
+	"gpt-4.1-mini":  {ContextWindow: 1047576, SupportsTools: true, SupportsVision: true, SupportsJSON: true, InputCost: 0.4, OutputCost: 1.6},
+	"gpt-5":         {ContextWindow: 400000, SupportsTools: true, SupportsVision: true, SupportsJSON: true, InputCost: 1.25, OutputCost: 10},
+	"o1":            {ContextWindow: 200000, SupportsTools: true, SupportsVision: true, SupportsJSON: true, InputCost: 15, OutputCost: 60},
+	"o3":            {ContextWindow: 200000, SupportsTools: true, SupportsVision: true, SupportsJSON: true, InputCost: 2, OutputCost: 8},
+	"o4":            {ContextWindow: 200000, SupportsTools: true, SupportsVision: true, SupportsJSON: true, InputCost: 1.1, OutputCost: 4.4},
+
+	// Anthropic
+	"claude-3":        {ContextWindow: 200000, SupportsTools: true, SupportsVision: true, InputCost: 3, OutputCost: 15},
+	"claude-3-haiku":  {ContextWindow: 200000, SupportsTools: true, SupportsVision: true, InputCost: 0.25, OutputCost: 1.25},
+	"claude-3-opus":   {ContextWindow: 200000, SupportsTools: true, SupportsVision: true, InputCost: 15, OutputCost: 75},
+	"claude-sonnet-4": {ContextWindow: 200000, SupportsTools: true, SupportsVision: true, InputCost: 3, OutputCost: 15},
+	"claude-opus-4":   {ContextWindow: 200000, SupportsTools: true, SupportsVision: true, InputCost: 15, OutputCost: 75},
+	"claude-haiku-4":  {ContextWindow: 200000, SupportsTools: true, SupportsVision: true, InputCost: 1, OutputCost: 5},
+
+	// Google
+	"gemini":           {ContextWindow: 1048576, SupportsTools: true, SupportsVision: true, SupportsJSON: true},
+	"gemini-2.5-pro":   {ContextWindow: 1048576, SupportsTools: true, SupportsVision: true, SupportsJSON: true, InputCost: 1.25, OutputCost: 10},
+	"gemini-2.5-flash": {ContextWindow: 1048576, SupportsTools: true, SupportsVision: true, SupportsJSON: true, InputCost: 0.3, OutputCost: 2.5},
+
+	// DeepSeek
+	"deepseek-chat":     {ContextWindow: 65536, SupportsTools: true, SupportsJSON: true, InputCost: 0.27, OutputCost: 1.1},
+	"deepseek-reasoner": {ContextWindow: 65536, SupportsJSON: true, InputCost: 0.55, OutputCost: 2.19},
+
+	// Open-weight models, as served by Groq, NVIDIA, vLLM or Ollama
+	"llama3":     {ContextWindow: 8192, SupportsTools: true},
+	"llama-3.1":  {ContextWindow: 131072, SupportsTools: true, SupportsJSON: true},
+	"llama3.1":   {ContextWindow: 131072, SupportsTools: true, SupportsJSON: true},
+	"llama-3.3":  {ContextWindow: 131072, SupportsTools: true, SupportsJSON: true},
+	"llama3.3":   {ContextWindow: 131072, SupportsTools: true, SupportsJSON: true},
+	"mistral":    {ContextWindow: 32768, SupportsTools: true, SupportsJSON: true},
+	"mixtral":    {ContextWindow: 32768, SupportsTools: true, SupportsJSON: true},
+	"qwen":       {ContextWindow: 32768, SupportsTools: true, SupportsJSON: true},
+	"qwen2.5-vl": {ContextWindow: 32768, SupportsTools: true, SupportsVision: true, SupportsJSON: true},
+	"llava":      {ContextWindow: 4096, SupportsVision: true},
+	"pixtral":    {ContextWindow: 128000, SupportsTools: true, SupportsVision: true, SupportsJSON: true},
+	"vision":     {ContextWindow: 8192, SupportsTools: true, SupportsVision: true},
+	"-vl":        {ContextWindow: 32768, SupportsTools: true, SupportsVision: true},
+}
+
+var (
+	modelsMu     sync.RWMutex
+	customModels = map[string]ModelInfo{}
+)
+
+// LookupModel returns the capabilities of model. Models registered with
+// RegisterModel are matched exactly (ignoring case) and take precedence;
+// otherwise the longest built-in fragment contained in the name is used.
+// The second result is false when DefaultModelInfo was returned.
+func LookupModel(model string) (ModelInfo, bool) {
+	lower := strings.ToLower(model)
+
+	modelsMu.RLock()
+	info, ok := customModels[lower]
+	modelsMu.RUnlock()
+	if ok {
+		return info, true
+	}
+
+	best := ""
+	for fragment, candidate := range builtinModels {
+		if len(fragment) > len(best) && strings.Contains(lower, fragment) {
+			best, info = fragment, candidate
+		}
+	}
+	if best == "" {
+		return DefaultModelInfo, false
+	}
+	return info, true
+}
+
+// RegisterModel adds or replaces the capabilities of a model by exact name.
+func RegisterModel(model string, info ModelInfo) {
+	modelsMu.Lock()
+	defer modelsMu.Unlock()
+	customModels[strings.ToLower(model)] = info
+}
+
+// RegisterConfiguredModels applies the "models" section of cfg. Each entry
+// starts from what LookupModel already knows about the name and overrides
+// only the fields that are set, so a custom model can be declared with just
+// a context window or a known one adjusted.
+func RegisterConfiguredModels(cfg *config.Config) {
+	for name, mc := range cfg.Models {
+		info, _ := LookupModel(name)
+		if mc.ContextWindow > 0 {
+			info.ContextWindow = mc.ContextWindow
+		}
+		if mc.SupportsTools != nil {
+			info.SupportsTools = *mc.SupportsTools
+		}
+		if mc.SupportsVision != nil {
+			info.SupportsVision = *mc.SupportsVision
+		}
+		if mc.SupportsJSON != nil {
+			info.SupportsJSON = *mc.SupportsJSON
+		}
+		if mc.InputCost != nil {
+			info.InputCost = *mc.InputCost
+		}
+		if mc.OutputCost != nil {
+			info.OutputCost = *mc.OutputCost
+		}
+		RegisterModel(name, info)
+	}
+}
+
+// SupportsVision reports whether model accepts image content parts.
+func SupportsVision(model string) bool {
+	info, _ := LookupModel(model)
+	return info.SupportsVision
+}
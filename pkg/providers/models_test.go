@@ -0,0 +1,83 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/Sterlites/RDxClaw/pkg/config"
+)
+
+func TestLookupModel(t *testing.T) {
+	tests := []struct {
+		model      string
+		wantWindow int
+		wantVision bool
+		wantTools  bool
+	}{
+		{"gpt-4o", 128000, true, true},
+		{"openrouter/openai/gpt-4o-mini", 128000, true, true},
+		{"claude-sonnet-4-5-20250929", 200000, true, true},
+		{"GEMINI-2.5-PRO", 1048576, true, true},
+		{"ollama/llama3.1:8b", 131072, false, true},
+		{"deepseek-reasoner", 65536, false, false},
+	}
+	for _, tt := range tests {
+		info, ok := LookupModel(tt.model)
+		if !ok {
+			t.Errorf("LookupModel(%q) not found", tt.model)
+			continue
+		}
+		if info.ContextWindow != tt.wantWindow || info.SupportsVision != tt.wantVision || info.SupportsTools != tt.wantTools {
+			t.Errorf("LookupModel(%q) = %+v", tt.model, info)
+		}
+	}
+}
+
+func TestLookupModelLongestMatch(t *testing.T) {
+	mini, _ := LookupModel("gpt-4o-mini")
+	full, _ := LookupModel("gpt-4o")
+	if mini.InputCost == full.InputCost {
+		t.Errorf("gpt-4o-mini should not use gpt-4o pricing: %v", mini.InputCost)
+	}
+}
+
+func TestLookupModelFallback(t *testing.T) {
+	info, ok := LookupModel("my-finetune-v2")
+	if ok {
+		t.Error("unknown model reported as known")
+	}
+	if info != DefaultModelInfo {
+		t.Errorf("LookupModel() = %+v, want default %+v", info, DefaultModelInfo)
+	}
+}
+
+func TestRegisterConfiguredModels(t *testing.T) {
+	t.Cleanup(func() {
+		modelsMu.Lock()
+		customModels = map[string]ModelInfo{}
+		modelsMu.Unlock()
+	})
+
+	yes, no := true, false
+	RegisterConfiguredModels(&config.Config{
+		Models: map[string]config.ModelConfig{
+			"local-coder": {ContextWindow: 16384, SupportsVision: &yes},
+			"gpt-4o":      {SupportsTools: &no},
+		},
+	})
+
+	info, ok := LookupModel("Local-Coder")
+	if !ok {
+		t.Fatal("configured model not found")
+	}
+	if info.ContextWindow != 16384 || !info.SupportsVision || !info.SupportsTools {
+		t.Errorf("local-coder = %+v, want window 16384 with vision and default tools", info)
+	}
+
+	info, _ = LookupModel("gpt-4o")
+	if info.SupportsTools {
+		t.Error("override should disable tools for gpt-4o")
+	}
+	if info.ContextWindow != 128000 || !info.SupportsVision {
+		t.Errorf("override should keep built-in fields, got %+v", info)
+	}
+}
@@ -0,0 +1,194 @@
+package providers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// StreamingProvider is implemented by providers that can deliver a reply
+// incrementally. ChatStream calls onDelta with each piece of content as it
+// arrives and returns the complete response, like Chat, once it is done.
+type StreamingProvider interface {
+	ChatStream(ctx context.Context, messages []Message, tools []ToolDefinition, model string, options map[string]interface{}, onDelta func(string)) (*LLMResponse, error)
+}
+
+// ChatStream runs a chat with p, streaming its content to onDelta. Providers
+// that do not implement StreamingProvider answer through Chat, and their
+// content is passed to onDelta in one piece.
+func ChatStream(ctx context.Context, p LLMProvider, messages []Message, tools []ToolDefinition, model string, options map[string]interface{}, onDelta func(string)) (*LLMResponse, error) {
+	if s, ok := p.(StreamingProvider); ok {
+		return s.ChatStream(ctx, messages, tools, model, options, onDelta)
+	}
+	resp, err := p.Chat(ctx, messages, tools, model, options)
+	if err == nil && resp.Content != "" {
+		onDelta(resp.Content)
+	}
+	return resp, err
+}
+
+// ChatStream calls the chat completions endpoint with stream=true and reads
+// its Server-Sent Events.
+func (p *HTTPProvider) ChatStream(ctx context.Context, messages []Message, tools []ToolDefinition, model string, options map[string]interface{}, onDelta func(string)) (*LLMResponse, error) {
+	if p.apiBase == "" {
+		return nil, fmt.Errorf("API base not configured")
+	}
+
+	requestBody := p.chatRequestBody(messages, tools, model, options)
+	requestBody["stream"] = true
+	requestBody["stream_options"] = map[string]interface{}{"include_usage": true}
+
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(callCtx, "POST", p.apiBase+"/chat/completions", bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		if p.callTimedOut(ctx, callCtx) {
+			return nil, &TimeoutError{Timeout: p.timeout}
+		}
+		return nil, callError("failed to send request", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, statusError(resp, body)
+	}
+
+	result, err := readChatStream(resp.Body, onDelta)
+	if err != nil {
+		if p.callTimedOut(ctx, callCtx) {
+			return nil, &TimeoutError{Timeout: p.timeout}
+		}
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return result, nil
+}
+
+// streamChunk is one chat.completion.chunk event of an OpenAI stream.
+type streamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content   string `json:"content"`
+			ToolCalls []struct {
+				Index    int    `json:"index"`
+				ID       string `json:"id"`
+				Type     string `json:"type"`
+				Function *struct {
+					Name      string `json:"name"`
+					Arguments string `json:"arguments"`
+				} `json:"function"`
+			} `json:"tool_calls"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *UsageInfo `json:"usage"`
+}
+
+// readChatStream reads an OpenAI event stream up to its [DONE] line,
+// passing content to onDelta and assembling the tool calls, whose names
+// and arguments arrive in fragments keyed by index.
+func readChatStream(r io.Reader, onDelta func(string)) (*LLMResponse, error) {
+	var content strings.Builder
+	type partialCall struct {
+		id, name  string
+		arguments strings.Builder
+	}
+	calls := make(map[int]*partialCall)
+	result := &LLMResponse{FinishReason: "stop"}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue // blank separators, comments and other fields
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk streamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal stream chunk: %w", err)
+		}
+		if chunk.Usage != nil {
+			result.Usage = chunk.Usage
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+
+		choice := chunk.Choices[0]
+		if delta := choice.Delta.Content; delta != "" {
+			content.WriteString(delta)
+			onDelta(delta)
+		}
+		for _, tc := range choice.Delta.ToolCalls {
+			call, ok := calls[tc.Index]
+			if !ok {
+				call = &partialCall{}
+				calls[tc.Index] = call
+			}
+			if tc.ID != "" {
+				call.id = tc.ID
+			}
+			if tc.Function != nil {
+				call.name += tc.Function.Name
+				call.arguments.WriteString(tc.Function.Arguments)
+			}
+		}
+		if choice.FinishReason != nil && *choice.FinishReason != "" {
+			result.FinishReason = *choice.FinishReason
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	indexes := make([]int, 0, len(calls))
+	for i := range calls {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	for _, i := range indexes {
+		call := calls[i]
+		arguments := make(map[string]interface{})
+		if raw := call.arguments.String(); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &arguments); err != nil {
+				arguments["raw"] = raw
+			}
+		}
+		result.ToolCalls = append(result.ToolCalls, ToolCall{
+			ID:        call.id,
+			Name:      call.name,
+			Arguments: arguments,
+		})
+	}
+
+	result.Content = content.String()
+	return result, nil
+}
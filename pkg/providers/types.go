@@ -1,6 +1,9 @@
 package providers
 
-import "context"
+import (
+	"context"
+	"encoding/base64"
+)
 
 type ToolCall struct {
 	ID        string                 `json:"id"`
@@ -33,6 +36,27 @@ type Message struct {
 	Content    string     `json:"content"`
 	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
 	ToolCallID string     `json:"tool_call_id,omitempty"`
+	// Parts holds non-text content sent alongside Content, such as images.
+	// Parts are only passed to the provider and are never persisted in
+	// session history.
+	Parts []ContentPart `json:"-"`
+}
+
+// ContentPart is a piece of multimodal message content.
+type ContentPart struct {
+	Type     string // "image"
+	MimeType string
+	Data     []byte
+}
+
+// Base64 returns the part data encoded as standard base64.
+func (p ContentPart) Base64() string {
+	return base64.StdEncoding.EncodeToString(p.Data)
+}
+
+// DataURL returns the part data as a data: URL.
+func (p ContentPart) DataURL() string {
+	return "data:" + p.MimeType + ";base64," + p.Base64()
 }
 
 type LLMProvider interface {
@@ -0,0 +1,104 @@
+package providers
+
+import (
+	"context"
+	"sync"
+)
+
+// ModelUsage is the usage accumulated for one model.
+type ModelUsage struct {
+	Requests         int64 `json:"requests"`
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+	TotalTokens      int64 `json:"total_tokens"`
+}
+
+// UsageTracker aggregates token usage per model. It is safe for concurrent
+// use.
+type UsageTracker struct {
+	mu     sync.Mutex
+	models map[string]*ModelUsage
+}
+
+func NewUsageTracker() *UsageTracker {
+	return &UsageTracker{models: make(map[string]*ModelUsage)}
+}
+
+// DefaultUsage aggregates the usage of every Chat call made by this
+// process. It is reset when the process restarts.
+var DefaultUsage = NewUsageTracker()
+
+type usageTrackerKey struct{}
+
+// WithUsageTracker returns a copy of ctx whose Chat calls are also recorded
+// in t, so that a caller can learn what one request cost.
+func WithUsageTracker(ctx context.Context, t *UsageTracker) context.Context {
+	return context.WithValue(ctx, usageTrackerKey{}, t)
+}
+
+// RecordUsage records a completed Chat call in DefaultUsage and in the
+// tracker attached to ctx, if any.
+func RecordUsage(ctx context.Context, model string, usage *UsageInfo) {
+	DefaultUsage.Record(model, usage)
+	if t, ok := ctx.Value(usageTrackerKey{}).(*UsageTracker); ok {
+		t.Record(model, usage)
+	}
+}
+
+// Record counts one request for model and adds its token usage. usage may
+// be nil for providers that do not report it; the request still counts.
+func (t *UsageTracker) Record(model string, usage *UsageInfo) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	m, ok := t.models[model]
+	if !ok {
+		m = &ModelUsage{}
+		t.models[model] = m
+	}
+	m.Requests++
+	if usage == nil {
+		return
+	}
+	m.PromptTokens += int64(usage.PromptTokens)
+	m.CompletionTokens += int64(usage.CompletionTokens)
+	total := usage.TotalTokens
+	if total == 0 {
+		total = usage.PromptTokens + usage.CompletionTokens
+	}
+	m.TotalTokens += int64(total)
+}
+
+// Snapshot returns a copy of the counters, keyed by model.
+func (t *UsageTracker) Snapshot() map[string]ModelUsage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := make(map[string]ModelUsage, len(t.models))
+	for model, m := range t.models {
+		out[model] = *m
+	}
+	return out
+}
+
+// Total returns the counters summed over every model.
+func (t *UsageTracker) Total() ModelUsage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var total ModelUsage
+	for _, m := range t.models {
+		total.Requests += m.Requests
+		total.PromptTokens += m.PromptTokens
+		total.CompletionTokens += m.CompletionTokens
+		total.TotalTokens += m.TotalTokens
+	}
+	return total
+}
+
+// Reset clears all counters.
+func (t *UsageTracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.models = make(map[string]*ModelUsage)
+}
@@ -0,0 +1,65 @@
+package providers
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestUsageTracker_Record(t *testing.T) {
+	tracker := NewUsageTracker()
+	tracker.Record("gpt-4o", &UsageInfo{PromptTokens: 100, CompletionTokens: 20, TotalTokens: 120})
+	tracker.Record("gpt-4o", &UsageInfo{PromptTokens: 50, CompletionTokens: 5})
+	tracker.Record("claude-cli", nil)
+
+	got := tracker.Snapshot()
+	want := map[string]ModelUsage{
+		"gpt-4o":     {Requests: 2, PromptTokens: 150, CompletionTokens: 25, TotalTokens: 175},
+		"claude-cli": {Requests: 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Snapshot() = %+v, want %+v", got, want)
+	}
+	for model, w := range want {
+		if got[model] != w {
+			t.Errorf("%s: got %+v, want %+v", model, got[model], w)
+		}
+	}
+
+	tracker.Reset()
+	if n := len(tracker.Snapshot()); n != 0 {
+		t.Errorf("Snapshot() after Reset has %d models, want 0", n)
+	}
+}
+
+func TestUsageTracker_Concurrent(t *testing.T) {
+	tracker := NewUsageTracker()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tracker.Record("m", &UsageInfo{PromptTokens: 2, CompletionTokens: 1, TotalTokens: 3})
+		}()
+	}
+	wg.Wait()
+
+	if got := tracker.Snapshot()["m"]; got.Requests != 50 || got.TotalTokens != 150 {
+		t.Errorf("got %+v, want 50 requests and 150 tokens", got)
+	}
+}
+
+func TestRecordUsageWithTracker(t *testing.T) {
+	tracker := NewUsageTracker()
+	ctx := WithUsageTracker(context.Background(), tracker)
+
+	RecordUsage(ctx, "model-a", &UsageInfo{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15})
+	RecordUsage(ctx, "model-b", &UsageInfo{PromptTokens: 3, CompletionTokens: 2})
+	RecordUsage(context.Background(), "model-a", &UsageInfo{PromptTokens: 100, CompletionTokens: 100, TotalTokens: 200})
+
+	want := ModelUsage{Requests: 2, PromptTokens: 13, CompletionTokens: 7, TotalTokens: 20}
+	if got := tracker.Total(); got != want {
+		t.Errorf("Total() = %+v, want %+v", got, want)
+	}
+}
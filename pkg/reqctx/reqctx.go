@@ -0,0 +1,76 @@
+// Package reqctx carries request-scoped identifiers, such as the request ID
+// and session key, through a context.Context so that every log line emitted
+// while handling a request can be correlated.
+package reqctx
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	sessionKeyKey
+)
+
+// WithRequestID returns a copy of ctx carrying the request ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID returns the request ID carried by ctx, or "" if none.
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// WithSessionKey returns a copy of ctx carrying the session key.
+func WithSessionKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, sessionKeyKey, key)
+}
+
+// SessionKey returns the session key carried by ctx, or "" if none.
+func SessionKey(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	key, _ := ctx.Value(sessionKeyKey).(string)
+	return key
+}
+
+// EnsureRequestID returns ctx unchanged if it already carries a request ID,
+// otherwise a copy with a freshly generated one.
+func EnsureRequestID(ctx context.Context) context.Context {
+	if RequestID(ctx) != "" {
+		return ctx
+	}
+	return WithRequestID(ctx, NewRequestID())
+}
+
+// NewRequestID generates a random 16-character hex request ID.
+func NewRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
+
+// Detach returns a background context carrying the identifiers of ctx but
+// none of its deadline or cancellation, for work that outlives the request.
+func Detach(ctx context.Context) context.Context {
+	detached := context.Background()
+	if id := RequestID(ctx); id != "" {
+		detached = WithRequestID(detached, id)
+	}
+	if key := SessionKey(ctx); key != "" {
+		detached = WithSessionKey(detached, key)
+	}
+	return detached
+}
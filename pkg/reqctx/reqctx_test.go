@@ -0,0 +1,47 @@
+package reqctx
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRequestIDRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	if RequestID(ctx) != "" {
+		t.Fatal("expected empty request ID on background context")
+	}
+
+	ctx = WithSessionKey(WithRequestID(ctx, "req-1"), "telegram:42")
+	if got := RequestID(ctx); got != "req-1" {
+		t.Errorf("RequestID() = %q, want %q", got, "req-1")
+	}
+	if got := SessionKey(ctx); got != "telegram:42" {
+		t.Errorf("SessionKey() = %q, want %q", got, "telegram:42")
+	}
+}
+
+func TestEnsureRequestID(t *testing.T) {
+	ctx := EnsureRequestID(context.Background())
+	id := RequestID(ctx)
+	if len(id) != 16 {
+		t.Fatalf("expected generated 16-char ID, got %q", id)
+	}
+	if got := RequestID(EnsureRequestID(ctx)); got != id {
+		t.Errorf("EnsureRequestID replaced existing ID %q with %q", id, got)
+	}
+}
+
+func TestDetach(t *testing.T) {
+	parent, cancel := context.WithTimeout(WithRequestID(context.Background(), "req-2"), time.Millisecond)
+	parent = WithSessionKey(parent, "s")
+	cancel()
+
+	detached := Detach(parent)
+	if detached.Err() != nil {
+		t.Error("detached context must not inherit cancellation")
+	}
+	if RequestID(detached) != "req-2" || SessionKey(detached) != "s" {
+		t.Error("detached context lost request identifiers")
+	}
+}
@@ -0,0 +1,33 @@
+package skills
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// skillsLockFile guards the workspace's skills folder and install records
+// while an install, update or uninstall changes them. It sits beside the
+// folder so the skills watcher never sees it.
+const skillsLockFile = ".skills-install.lock"
+
+// lockSkills takes the workspace's skills lock, waiting for any other
+// installer, in this process or another, to release it. Call the returned
+// function to release it.
+func (si *SkillInstaller) lockSkills() (func(), error) {
+	if err := os.MkdirAll(si.workspace, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create workspace: %w", err)
+	}
+	f, err := os.OpenFile(filepath.Join(si.workspace, skillsLockFile), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open skills lock: %w", err)
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock skills directory: %w", err)
+	}
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
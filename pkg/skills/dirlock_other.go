@@ -0,0 +1,21 @@
+//go:build !unix
+
+package skills
+
+import (
+	"os"
+	"sync"
+)
+
+// Without flock, installs are only serialized within this process.
+var skillsMu sync.Mutex
+
+func lockFile(*os.File) error {
+	skillsMu.Lock()
+	return nil
+}
+
+func unlockFile(*os.File) error {
+	skillsMu.Unlock()
+	return nil
+}
@@ -0,0 +1,22 @@
+package skills
+
+import "errors"
+
+var (
+	// ErrSkillExists is returned when installing a skill that is already installed.
+	ErrSkillExists = errors.New("skill already exists")
+	// ErrSkillNotFound is returned when a skill is not installed.
+	ErrSkillNotFound = errors.New("skill not found")
+	// ErrReservedName is returned when a skill is named after a built-in tool.
+	ErrReservedName = errors.New("skill name is reserved for a built-in tool")
+	// ErrInstallTimeout is returned when a skill install exceeds its deadline.
+	ErrInstallTimeout = errors.New("skill install timed out")
+	// ErrUnsignedSkill is returned when a skill's manifest carries no signature.
+	ErrUnsignedSkill = errors.New("skill is not signed")
+	// ErrUntrustedPublisher is returned when a skill is signed by a publisher
+	// whose key is not trusted.
+	ErrUntrustedPublisher = errors.New("skill publisher is not trusted")
+	// ErrInvalidSignature is returned when a signature does not match the
+	// skill's manifest or files, which means the skill was tampered with.
+	ErrInvalidSignature = errors.New("skill signature is invalid")
+)
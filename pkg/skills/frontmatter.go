@@ -0,0 +1,255 @@
+package skills
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// The closing --- must be on its own line; the match is non-greedy so a
+	// horizontal rule in the skill body is not mistaken for the end.
+	frontmatterPattern      = regexp.MustCompile(`(?s)^---\r?\n(.*?)\r?\n---[ \t]*(?:\r?\n|$)`)
+	frontmatterStripPattern = regexp.MustCompile(`(?s)^---\r?\n.*?\r?\n---[ \t]*(?:\r?\n|$)`)
+)
+
+func (sl *SkillsLoader) extractFrontmatter(content string) string {
+	match := frontmatterPattern.FindStringSubmatch(content)
+	if len(match) > 1 {
+		return match[1]
+	}
+	return ""
+}
+
+func (sl *SkillsLoader) stripFrontmatter(content string) string {
+	return frontmatterStripPattern.ReplaceAllString(content, "")
+}
+
+// parseSimpleYAML parses the subset of YAML used in SKILL.md frontmatter:
+// top-level "key: value" pairs where the value is a plain scalar, a single-
+// or double-quoted string (which may contain colons and span lines), or a
+// literal (|) or folded (>) block. Block scalars are returned without their
+// trailing newline. Block lists ("- item" lines) are returned in flow form,
+// "[a, b]", so they can be read with parseList. Nested mappings are ignored.
+func (sl *SkillsLoader) parseSimpleYAML(content string) map[string]string {
+	result := make(map[string]string)
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || indentOf(line) > 0 {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		var consumed int
+		switch {
+		case strings.HasPrefix(value, `"`) || strings.HasPrefix(value, "'"):
+			value, consumed = parseQuoted(value, lines[i+1:])
+		case strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">"):
+			value, consumed = parseBlock(value, lines[i+1:])
+		default:
+			value, consumed = parsePlain(value, lines[i+1:])
+		}
+		i += consumed
+		result[key] = value
+	}
+
+	return result
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+// parseQuoted reads a quoted scalar starting with first. If the closing quote
+// is not on the first line, following lines are folded into the value. It
+// returns the value and how many of the following lines were consumed.
+func parseQuoted(first string, rest []string) (string, int) {
+	quote := first[0]
+	var sb strings.Builder
+	sb.WriteString(first[1:])
+	consumed := 0
+	blank := false
+
+	for {
+		text := sb.String()
+		if end := closingQuote(text, quote); end >= 0 {
+			return unquote(text[:end], quote), consumed
+		}
+		if consumed >= len(rest) {
+			// Unterminated: use what we have rather than dropping the value.
+			return unquote(text, quote), consumed
+		}
+		next := strings.TrimSpace(rest[consumed])
+		consumed++
+		switch {
+		case next == "":
+			sb.WriteString("\n")
+			blank = true
+		case blank:
+			sb.WriteString(next)
+			blank = false
+		default:
+			sb.WriteString(" ")
+			sb.WriteString(next)
+		}
+	}
+}
+
+// closingQuote returns the index of the unescaped closing quote in s, or -1.
+func closingQuote(s string, quote byte) int {
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quote == '"' && s[i] == '\\':
+			i++
+		case quote == '\'' && s[i] == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++
+		case s[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+func unquote(s string, quote byte) string {
+	if quote == '\'' {
+		return strings.ReplaceAll(s, "''", "'")
+	}
+	if v, err := strconv.Unquote(`"` + strings.ReplaceAll(s, "\n", `\n`) + `"`); err == nil {
+		return v
+	}
+	return s
+}
+
+// parseBlock reads a literal (|) or folded (>) block scalar whose content is
+// the indented lines that follow the key.
+func parseBlock(header string, rest []string) (string, int) {
+	folded := header[0] == '>'
+
+	var block []string
+	consumed := 0
+	indent := -1
+	for consumed < len(rest) {
+		line := rest[consumed]
+		if strings.TrimSpace(line) == "" {
+			block = append(block, "")
+			consumed++
+			continue
+		}
+		n := indentOf(line)
+		if n == 0 {
+			break
+		}
+		if indent < 0 {
+			indent = n
+		}
+		if n < indent {
+			break
+		}
+		block = append(block, line[indent:])
+		consumed++
+	}
+
+	// Trailing blank lines belong to the block but carry no content.
+	for len(block) > 0 && block[len(block)-1] == "" {
+		block = block[:len(block)-1]
+	}
+
+	if !folded {
+		return strings.Join(block, "\n"), consumed
+	}
+
+	var sb strings.Builder
+	for i, line := range block {
+		switch {
+		case line == "":
+			sb.WriteString("\n")
+		case i > 0 && block[i-1] != "" && !strings.HasPrefix(line, " "):
+			sb.WriteString(" ")
+			sb.WriteString(line)
+		default:
+			sb.WriteString(line)
+		}
+	}
+	return sb.String(), consumed
+}
+
+// parsePlain reads an unquoted scalar, folding any indented continuation
+// lines into it and dropping a trailing comment. A key with no value
+// followed by a block list yields the list in flow form; one followed by a
+// nested mapping yields an empty value.
+func parsePlain(first string, rest []string) (string, int) {
+	parts := []string{stripComment(first)}
+	nested := false
+	var items []string
+	consumed := 0
+	for consumed < len(rest) {
+		line := rest[consumed]
+		if strings.TrimSpace(line) == "" || indentOf(line) == 0 {
+			break
+		}
+		text := strings.TrimSpace(line)
+		if consumed == 0 && first == "" && isNested(text) {
+			nested = true
+		}
+		if nested && (text == "-" || strings.HasPrefix(text, "- ")) {
+			items = append(items, stripComment(strings.TrimSpace(strings.TrimPrefix(text, "-"))))
+		}
+		parts = append(parts, stripComment(text))
+		consumed++
+	}
+	if nested {
+		if len(items) > 0 {
+			return "[" + strings.Join(items, ", ") + "]", consumed
+		}
+		return "", consumed
+	}
+	return strings.TrimSpace(strings.Join(parts, " ")), consumed
+}
+
+// isNested reports whether an indented line starts a list or mapping rather
+// than continuing a scalar.
+func isNested(text string) bool {
+	if text == "-" || strings.HasPrefix(text, "- ") {
+		return true
+	}
+	key, _, ok := strings.Cut(text, ":")
+	return ok && !strings.ContainsAny(key, " \t\"'")
+}
+
+func stripComment(s string) string {
+	if i := strings.Index(s, " #"); i >= 0 {
+		return strings.TrimSpace(s[:i])
+	}
+	return s
+}
+
+// parseList splits a frontmatter list value. It accepts flow lists
+// ("[a, b]") as well as plain comma-separated values ("a, b"). Quotes
+// around items are removed and empty items dropped.
+func parseList(value string) []string {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+		value = value[1 : len(value)-1]
+	}
+
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) >= 2 && (item[0] == '"' || item[0] == '\'') && item[len(item)-1] == item[0] {
+			item = unquote(item[1:len(item)-1], item[0])
+		}
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,148 @@
+package skills
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSimpleYAML(t *testing.T) {
+	sl := &SkillsLoader{}
+
+	testcases := []struct {
+		name    string
+		content string
+		want    map[string]string
+	}{
+		{
+			name:    "plain values",
+			content: "name: weather\ndescription: Get the weather # trailing comment",
+			want:    map[string]string{"name": "weather", "description": "Get the weather"},
+		},
+		{
+			name:    "quoted value containing a colon",
+			content: "name: weather\ndescription: \"Weather: current conditions\"",
+			want:    map[string]string{"name": "weather", "description": "Weather: current conditions"},
+		},
+		{
+			name:    "single quoted value with escaped quote",
+			content: "description: 'It''s sunny: 20°C'",
+			want:    map[string]string{"description": "It's sunny: 20°C"},
+		},
+		{
+			name:    "double quoted escapes",
+			content: `description: "Say \"hi\"\tthere"`,
+			want:    map[string]string{"description": "Say \"hi\"\tthere"},
+		},
+		{
+			name:    "quoted value spanning lines",
+			content: "description: \"Fetches the forecast\n  for a city: today and tomorrow\"\nname: weather",
+			want:    map[string]string{"description": "Fetches the forecast for a city: today and tomorrow", "name": "weather"},
+		},
+		{
+			name:    "folded block",
+			content: "description: >\n  Fetches the forecast\n  for a city.\n\n  Uses wttr.in: no key needed.\nname: weather",
+			want:    map[string]string{"description": "Fetches the forecast for a city.\nUses wttr.in: no key needed.", "name": "weather"},
+		},
+		{
+			name:    "literal block",
+			content: "description: |\n  Line one: first\n  Line two\n\nname: weather",
+			want:    map[string]string{"description": "Line one: first\nLine two", "name": "weather"},
+		},
+		{
+			name:    "plain continuation lines",
+			content: "description: Fetches the forecast\n  for a city\nname: weather",
+			want:    map[string]string{"description": "Fetches the forecast for a city", "name": "weather"},
+		},
+		{
+			name:    "nested mapping is skipped",
+			content: "name: weather\nmetadata:\n  emoji: sun\ndescription: Weather",
+			want:    map[string]string{"name": "weather", "metadata": "", "description": "Weather"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, sl.parseSimpleYAML(tc.content))
+		})
+	}
+}
+
+func TestFrontmatterExtractAndStrip(t *testing.T) {
+	sl := &SkillsLoader{}
+	content := "---\nname: weather\ndescription: \"Weather: now\"\n---\n\n# Weather\n\nIntro\n\n---\n\nMore\n"
+
+	assert.Equal(t, "name: weather\ndescription: \"Weather: now\"", sl.extractFrontmatter(content))
+	assert.Equal(t, "\n# Weather\n\nIntro\n\n---\n\nMore\n", sl.stripFrontmatter(content))
+
+	crlf := "---\r\nname: weather\r\n---\r\nBody"
+	assert.Equal(t, "name: weather", sl.extractFrontmatter(crlf))
+	assert.Equal(t, "Body", sl.stripFrontmatter(crlf))
+}
+
+func TestGetSkillMetadataFormats(t *testing.T) {
+	sl := &SkillsLoader{}
+	dir := t.TempDir()
+
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+
+	yamlMeta := sl.getSkillMetadata(write("yaml.md", "---\nname: weather\ndescription: >\n  Weather: current\n  conditions\n---\nBody"))
+	require.NotNil(t, yamlMeta)
+	assert.Equal(t, "weather", yamlMeta.Name)
+	assert.Equal(t, "Weather: current conditions", yamlMeta.Description)
+
+	jsonMeta := sl.getSkillMetadata(write("json.md", "---\n{\"name\": \"weather\", \"description\": \"Weather: now\"}\n---\nBody"))
+	require.NotNil(t, jsonMeta)
+	assert.Equal(t, "weather", jsonMeta.Name)
+	assert.Equal(t, "Weather: now", jsonMeta.Description)
+}
+
+func TestParseList(t *testing.T) {
+	assert.Equal(t, []string{"weather", "api"}, parseList("[weather, api]"))
+	assert.Equal(t, []string{"weather", "api"}, parseList("weather, api"))
+	assert.Equal(t, []string{"a", "b", "c"}, parseList(`["a", "b", 'c']`))
+	assert.Nil(t, parseList(""))
+	assert.Nil(t, parseList("[]"))
+}
+
+func TestSkillMetadataTagsAuthorVersion(t *testing.T) {
+	workspace := t.TempDir()
+	skillsDir := filepath.Join(workspace, "skills")
+
+	write := func(name, content string) {
+		dir := filepath.Join(skillsDir, name)
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte(content), 0644))
+	}
+	write("flow", "---\nname: flow\ndescription: Flow tags\ntags: [weather, api]\nauthor: Jane Doe\nversion: 1.2.0\n---\n")
+	write("block", "---\nname: block\ndescription: Block tags\ntags:\n  - notes\n  - \"productivity\"\n---\n")
+	write("json", "---\n{\"name\": \"json\", \"description\": \"JSON tags\", \"tags\": [\"dev\"], \"version\": \"0.1.0\"}\n---\n")
+	write("plain", "---\nname: plain\ndescription: No extra fields\n---\n")
+
+	skills := map[string]SkillInfo{}
+	for _, s := range NewSkillsLoader(workspace, "", "").ListSkills() {
+		skills[s.Name] = s
+	}
+	require.Len(t, skills, 4)
+
+	assert.Equal(t, []string{"weather", "api"}, skills["flow"].Tags)
+	assert.Equal(t, "Jane Doe", skills["flow"].Author)
+	assert.Equal(t, "1.2.0", skills["flow"].Version)
+	assert.True(t, skills["flow"].HasTag("API"))
+
+	assert.Equal(t, []string{"notes", "productivity"}, skills["block"].Tags)
+
+	assert.Equal(t, []string{"dev"}, skills["json"].Tags)
+	assert.Equal(t, "0.1.0", skills["json"].Version)
+
+	assert.Empty(t, skills["plain"].Tags)
+	assert.Empty(t, skills["plain"].Author)
+	assert.False(t, skills["plain"].HasTag("weather"))
+}
@@ -0,0 +1,112 @@
+package skills
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Sterlites/RDxClaw/pkg/config"
+)
+
+// HealthReport lists what keeps an installed skill from working. A skill
+// with no problems is healthy.
+type HealthReport struct {
+	Skill    string   `json:"skill"`
+	Problems []string `json:"problems,omitempty"`
+}
+
+// Healthy reports whether the skill has no known problems.
+func (r HealthReport) Healthy() bool {
+	return len(r.Problems) == 0
+}
+
+// CheckHealth verifies that an installed skill can run: its manifest is
+// valid, its required environment variables are set, its scripts exist
+// and their runtimes are on PATH, and the skills it depends on are
+// installed. Skills without a manifest only need a SKILL.md.
+func (si *SkillInstaller) CheckHealth(skillName string) (HealthReport, error) {
+	report := HealthReport{Skill: skillName}
+	skillDir := filepath.Join(si.workspace, "skills", skillName)
+	if _, err := os.Stat(skillDir); err != nil {
+		return report, fmt.Errorf("%w: %s", ErrSkillNotFound, skillName)
+	}
+
+	manifest, err := LoadManifest(skillDir)
+	if err != nil {
+		report.Problems = append(report.Problems, err.Error())
+		return report, nil
+	}
+	if manifest == nil {
+		if _, err := os.Stat(filepath.Join(skillDir, "SKILL.md")); err != nil {
+			report.Problems = append(report.Problems, "no SKILL.md or manifest.json")
+		}
+		return report, nil
+	}
+
+	for _, v := range manifest.RequiredEnvVars() {
+		if os.Getenv(v.Name) == "" {
+			report.Problems = append(report.Problems, fmt.Sprintf("environment variable %s is not set", v.Name))
+		}
+	}
+
+	missingRuntimes := make(map[string]bool)
+	for _, script := range manifest.Scripts {
+		if _, err := os.Stat(filepath.Join(skillDir, filepath.FromSlash(script.Path))); err != nil {
+			report.Problems = append(report.Problems, fmt.Sprintf("script %s is missing", script.Path))
+		}
+		command := runtimeCommands[script.Runtime]
+		if len(command) == 0 || missingRuntimes[script.Runtime] {
+			continue
+		}
+		if _, err := exec.LookPath(command[0]); err != nil {
+			missingRuntimes[script.Runtime] = true
+			report.Problems = append(report.Problems, fmt.Sprintf("%s runtime (%s) is not on PATH", script.Runtime, command[0]))
+		}
+	}
+
+	for _, dep := range manifest.Dependencies {
+		if !si.skillInstalled(dep) {
+			report.Problems = append(report.Problems, fmt.Sprintf("dependency %s is not installed", dep))
+		}
+	}
+
+	return report, nil
+}
+
+// CheckAllHealth checks every skill installed in the workspace.
+func (si *SkillInstaller) CheckAllHealth() ([]HealthReport, error) {
+	installed, err := si.installedSkills()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(installed))
+	for name := range installed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	reports := make([]HealthReport, 0, len(names))
+	for _, name := range names {
+		report, err := si.CheckHealth(name)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// skillInstalled reports whether a dependency resolves to a skill the
+// agent can load.
+func (si *SkillInstaller) skillInstalled(name string) bool {
+	loader := si.loader
+	if loader == nil {
+		loader = NewSkillsLoader(si.workspace,
+			filepath.Join(config.HomeDir(), "skills"),
+			filepath.Join(filepath.Dir(si.workspace), "rdxclaw", "skills"))
+	}
+	return loader.HasSkill(strings.TrimSpace(name))
+}
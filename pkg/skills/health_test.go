@@ -0,0 +1,85 @@
+package skills
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sterlites/RDxClaw/pkg/config"
+)
+
+func TestCheckHealth(t *testing.T) {
+	t.Setenv(config.DataDirEnv, t.TempDir())
+	workspace := t.TempDir()
+	installer := NewSkillInstaller(workspace)
+
+	skillsDir := filepath.Join(workspace, "skills")
+	writeSkill(t, skillsDir, "notes", "Notes")
+	writeSkill(t, skillsDir, "shopify", "Shopify")
+	require.NoError(t, os.WriteFile(filepath.Join(skillsDir, "shopify", "manifest.json"), []byte(`{
+		"name": "shopify", "version": "1.0.0", "description": "Shopify",
+		"env_vars": [
+			{"name": "RDXCLAW_TEST_SHOPIFY_KEY", "required": true},
+			{"name": "RDXCLAW_TEST_LOG_LEVEL", "required": true, "default": "info"}
+		],
+		"scripts": [{"path": "scripts/run.sh", "runtime": "shell"}],
+		"dependencies": ["notes", "payments"]
+	}`), 0644))
+
+	report, err := installer.CheckHealth("notes")
+	require.NoError(t, err)
+	assert.True(t, report.Healthy(), "problems: %v", report.Problems)
+
+	report, err = installer.CheckHealth("shopify")
+	require.NoError(t, err)
+	assert.False(t, report.Healthy())
+	assert.ElementsMatch(t, []string{
+		"environment variable RDXCLAW_TEST_SHOPIFY_KEY is not set",
+		"script scripts/run.sh is missing",
+		"dependency payments is not installed",
+	}, report.Problems)
+
+	t.Run("env var set", func(t *testing.T) {
+		t.Setenv("RDXCLAW_TEST_SHOPIFY_KEY", "secret")
+		report, err := installer.CheckHealth("shopify")
+		require.NoError(t, err)
+		assert.NotContains(t, report.Problems, "environment variable RDXCLAW_TEST_SHOPIFY_KEY is not set")
+	})
+
+	t.Run("all", func(t *testing.T) {
+		reports, err := installer.CheckAllHealth()
+		require.NoError(t, err)
+		require.Len(t, reports, 2)
+		assert.Equal(t, "notes", reports[0].Skill)
+		assert.Equal(t, "shopify", reports[1].Skill)
+	})
+
+	t.Run("dependency in the global skills", func(t *testing.T) {
+		dataDir := t.TempDir()
+		t.Setenv(config.DataDirEnv, dataDir)
+		writeSkill(t, filepath.Join(dataDir, "skills"), "payments", "Payments")
+
+		report, err := installer.CheckHealth("shopify")
+		require.NoError(t, err)
+		assert.NotContains(t, report.Problems, "dependency payments is not installed")
+	})
+
+	t.Run("dependency resolved by the loader", func(t *testing.T) {
+		global := t.TempDir()
+		writeSkill(t, global, "payments", "Payments")
+		installer := NewSkillInstaller(workspace)
+		installer.SetSkillsLoader(NewSkillsLoader(workspace, global, ""))
+
+		report, err := installer.CheckHealth("shopify")
+		require.NoError(t, err)
+		assert.NotContains(t, report.Problems, "dependency payments is not installed")
+	})
+
+	t.Run("not installed", func(t *testing.T) {
+		_, err := installer.CheckHealth("missing")
+		assert.ErrorIs(t, err, ErrSkillNotFound)
+	})
+}
@@ -5,19 +5,49 @@ import (
 	"archive/zip"
 	"compress/gzip"
 	"context"
+	"crypto/ed25519"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/Sterlites/RDxClaw/pkg/cron"
+	"github.com/Sterlites/RDxClaw/pkg/tools"
 )
 
+// DefaultInstallTimeout bounds an install from GitHub when the caller's
+// context has no deadline of its own.
+const DefaultInstallTimeout = 5 * time.Minute
+
+// zipDownloadAttempts is how many times a transient failure fetching a
+// repository zip is retried before the install is abandoned.
+const zipDownloadAttempts = 3
+
 type SkillInstaller struct {
 	workspace string
+
+	// Download endpoints and retry backoff; overridden in tests.
+	archiveBaseURL string
+	rawBaseURL     string
+	retryDelay     time.Duration
+
+	progress ProgressFunc
+
+	// trustedKeys are the publishers whose signed skills install; skills
+	// that are unsigned or signed by anyone else are refused unless
+	// allowUntrusted is set.
+	trustedKeys    map[string]ed25519.PublicKey
+	allowUntrusted bool
+
+	// loader resolves skill dependencies; see SetSkillsLoader.
+	loader *SkillsLoader
 }
 
 type AvailableSkill struct {
@@ -40,34 +70,129 @@ type InstallResult struct {
 	SkillDir     string
 	Manifest     *SkillManifest
 	FilesWritten int
+	Publisher    string // verified signer, empty if the skill was allowed untrusted
 }
 
 func NewSkillInstaller(workspace string) *SkillInstaller {
 	return &SkillInstaller{
-		workspace: workspace,
+		workspace:      workspace,
+		archiveBaseURL: "https://github.com",
+		rawBaseURL:     "https://raw.githubusercontent.com",
+		retryDelay:     time.Second,
 	}
 }
 
+// SetTrustedPublishers sets the publisher keys skills must be signed with.
+// With no keys, only skills allowed by SetAllowUntrusted install.
+func (si *SkillInstaller) SetTrustedPublishers(keys map[string]ed25519.PublicKey) {
+	si.trustedKeys = keys
+}
+
+// SetAllowUntrusted lets unsigned skills and skills from untrusted
+// publishers install anyway. It is off by default, so installing skills
+// nobody vouches for is always an explicit choice. Skills whose signature
+// does not match their contents are always refused.
+func (si *SkillInstaller) SetAllowUntrusted(allow bool) {
+	si.allowUntrusted = allow
+}
+
+// SetSkillsLoader sets the loader health checks resolve dependencies with,
+// so a dependency counts as installed wherever the agent would find it.
+// Without one, the workspace, global and builtin skills are searched.
+func (si *SkillInstaller) SetSkillsLoader(sl *SkillsLoader) {
+	si.loader = sl
+}
+
 // InstallFromGitHub downloads a skill package from a GitHub repository.
 // It first tries to download the repo as a zip archive (multi-file skill package).
-// If that fails, it falls back to downloading just the SKILL.md file (legacy behavior).
+// Only if the repository has no zip (HTTP 404 or 410) does it fall back to
+// downloading just the SKILL.md file (legacy behavior). Transient failures
+// such as network errors and 5xx responses retry the zip instead, so a
+// flaky connection never installs a degraded single-file skill.
+//
+// The install is bounded by ctx, or DefaultInstallTimeout if ctx has no
+// deadline. A timeout is reported as ErrInstallTimeout. Like every install,
+// it is atomic: on failure the skills folder is left untouched.
+//
+// repo may pin a branch, tag or commit as "owner/repo@ref"; without one the
+// main branch is installed. The source is recorded so a later sync can tell
+// whether the skill matches the lockfile.
 func (si *SkillInstaller) InstallFromGitHub(ctx context.Context, repo string) (*InstallResult, error) {
-	skillName := filepath.Base(repo)
+	src := ParseSource(repo)
+	skillName := src.Name()
 	skillDir := filepath.Join(si.workspace, "skills", skillName)
 
 	if _, err := os.Stat(skillDir); err == nil {
-		return nil, fmt.Errorf("skill '%s' already exists", skillName)
+		return nil, fmt.Errorf("%w: %s", ErrSkillExists, skillName)
 	}
 
-	// Try downloading as zip archive first (full package)
-	result, err := si.downloadRepoZip(ctx, repo, skillDir)
-	if err == nil {
-		return result, nil
+	return si.installSource(ctx, src, false)
+}
+
+// installSource installs src, bounded as InstallFromGitHub describes, and
+// records where it came from. held reports whether the caller already
+// holds the skills lock, as Update does for the whole replacement.
+func (si *SkillInstaller) installSource(ctx context.Context, src SkillSource, held bool) (*InstallResult, error) {
+	skillName := src.Name()
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultInstallTimeout)
+		defer cancel()
 	}
-	slog.Debug("zip download failed, trying SKILL.md fallback", "repo", repo, "error", err)
 
-	// Fallback: download just SKILL.md (legacy single-file skill)
-	return si.downloadSkillMD(ctx, repo, skillDir)
+	result, err := si.installFromGitHub(ctx, src, skillName, held)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInstallTimeout, src, err)
+		}
+		return nil, err
+	}
+	if !held {
+		unlock, err := si.lockSkills()
+		if err != nil {
+			slog.Warn("failed to record skill source", "skill", skillName, "error", err)
+			return result, nil
+		}
+		defer unlock()
+	}
+	if err := si.recordSource(skillName, src); err != nil {
+		slog.Warn("failed to record skill source", "skill", skillName, "error", err)
+	}
+	return result, nil
+}
+
+func (si *SkillInstaller) installFromGitHub(ctx context.Context, src SkillSource, skillName string, held bool) (*InstallResult, error) {
+	repo := src.String()
+	var err error
+	for attempt := 1; attempt <= zipDownloadAttempts; attempt++ {
+		var result *InstallResult
+		result, err = si.install(skillName, held, func(dir string) (int, error) {
+			return si.downloadRepoZip(ctx, src, dir)
+		})
+		if err == nil {
+			slog.Info("installed skill from repo zip", "repo", repo, "files", result.FilesWritten)
+			return result, nil
+		}
+		if zipUnavailable(err) {
+			// Fallback: download just SKILL.md (legacy single-file skill)
+			slog.Info("repo zip not available, falling back to SKILL.md", "repo", repo, "reason", err)
+			return si.install(skillName, held, func(dir string) (int, error) {
+				return si.downloadSkillMD(ctx, src, dir)
+			})
+		}
+		if !retriableDownload(ctx, err) || attempt == zipDownloadAttempts {
+			break
+		}
+
+		slog.Warn("repo zip download failed, retrying", "repo", repo, "attempt", attempt, "error", err)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(si.retryDelay * time.Duration(attempt)):
+		}
+	}
+
+	return nil, err
 }
 
 // InstallFromArchive installs a skill from a local zip or tar.gz archive file.
@@ -82,35 +207,108 @@ func (si *SkillInstaller) InstallFromArchive(archivePath string) (*InstallResult
 
 	skillDir := filepath.Join(si.workspace, "skills", baseName)
 	if _, err := os.Stat(skillDir); err == nil {
-		return nil, fmt.Errorf("skill '%s' already exists", baseName)
+		return nil, fmt.Errorf("%w: %s", ErrSkillExists, baseName)
 	}
 
-	if err := os.MkdirAll(skillDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create skill directory: %w", err)
-	}
-
-	var filesWritten int
-	var extractErr error
-
+	var extract func(archivePath, destDir string, onFile func(int)) (int, error)
 	switch {
 	case ext == ".zip":
-		filesWritten, extractErr = extractZip(archivePath, skillDir)
+		extract = extractZip
 	case ext == ".gz" || ext == ".tgz":
-		filesWritten, extractErr = extractTarGz(archivePath, skillDir)
+		extract = extractTarGz
 	default:
-		os.RemoveAll(skillDir)
 		return nil, fmt.Errorf("unsupported archive format: %s (supported: .zip, .tar.gz, .tgz)", ext)
 	}
 
-	if extractErr != nil {
-		os.RemoveAll(skillDir)
-		return nil, fmt.Errorf("failed to extract archive: %w", extractErr)
+	return si.install(baseName, false, func(dir string) (int, error) {
+		n, err := extract(archivePath, dir, si.onFile())
+		if err != nil {
+			return n, fmt.Errorf("failed to extract archive: %w", err)
+		}
+		return n, nil
+	})
+}
+
+// stagingDir holds in-progress installs. It lives in the workspace, outside
+// the skills folder so a half-written skill is never loaded, and on the same
+// filesystem so the final move is a rename.
+const stagingDir = ".skill-staging"
+
+// install makes installing a skill atomic. fill writes the skill's files
+// into a fresh staging directory; only if it succeeds and the result is a
+// valid skill is the directory renamed into the skills folder, under the
+// skills lock unless the caller already holds it (held). On any failure the
+// staging directory is discarded.
+func (si *SkillInstaller) install(skillName string, held bool, fill func(dir string) (int, error)) (*InstallResult, error) {
+	if tools.IsReservedName(skillName) {
+		return nil, fmt.Errorf("%w: %s", ErrReservedName, skillName)
+	}
+
+	skillsDir := filepath.Join(si.workspace, "skills")
+	skillDir := filepath.Join(skillsDir, skillName)
+
+	stagingRoot := filepath.Join(si.workspace, stagingDir)
+	if err := os.MkdirAll(stagingRoot, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	removeStaleStaging(stagingRoot)
+
+	staging, err := os.MkdirTemp(stagingRoot, skillName+"-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	filesWritten, err := fill(staging)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := LoadManifest(staging)
+	if err != nil {
+		return nil, err
+	}
+	if manifest == nil {
+		if _, err := os.Stat(filepath.Join(staging, "SKILL.md")); err != nil {
+			return nil, fmt.Errorf("%s does not contain a SKILL.md or manifest.json", skillName)
+		}
+	} else {
+		if err := validateCron(manifest); err != nil {
+			return nil, fmt.Errorf("%s: %w", skillName, err)
+		}
+		for _, script := range manifest.Scripts {
+			base := filepath.Base(script.Path)
+			if name := strings.TrimSuffix(base, filepath.Ext(base)); tools.IsReservedName(name) {
+				slog.Warn("skill script shares its name with a built-in tool", "skill", manifest.Name, "script", script.Path, "tool", name)
+			}
+		}
+	}
+
+	publisher, err := si.verify(staging, skillName)
+	if err != nil {
+		return nil, err
+	}
+
+	if !held {
+		unlock, err := si.lockSkills()
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
 	}
 
-	// Load and validate manifest if present
-	manifest, _ := LoadManifest(skillDir)
+	if err := os.MkdirAll(skillsDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create skills directory: %w", err)
+	}
+	// Rename would happily replace an empty directory, so check again.
+	if _, err := os.Stat(skillDir); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrSkillExists, skillName)
+	}
+	if err := os.Rename(staging, skillDir); err != nil {
+		return nil, fmt.Errorf("failed to move skill into place: %w", err)
+	}
 
-	name := baseName
+	name := skillName
 	if manifest != nil {
 		name = manifest.Name
 	}
@@ -120,19 +318,73 @@ func (si *SkillInstaller) InstallFromArchive(archivePath string) (*InstallResult
 		SkillDir:     skillDir,
 		Manifest:     manifest,
 		FilesWritten: filesWritten,
+		Publisher:    publisher,
 	}, nil
 }
 
+// validateCron checks the schedules of the skill's cron jobs, so a
+// malformed one is refused at install rather than when it first fires.
+func validateCron(manifest *SkillManifest) error {
+	for i, c := range manifest.Cron {
+		if err := cron.ValidateExpr(c.Expr, c.Timezone); err != nil {
+			return fmt.Errorf("cron[%d] %q: %w", i, c.Name, err)
+		}
+	}
+	return nil
+}
+
+// verify checks the staged skill's signature against the trusted
+// publishers and returns the verified publisher.
+func (si *SkillInstaller) verify(dir, skillName string) (string, error) {
+	publisher, err := VerifyManifest(dir, si.trustedKeys)
+	switch {
+	case err == nil:
+		return publisher, nil
+	case si.allowUntrusted && (errors.Is(err, ErrUnsignedSkill) || errors.Is(err, ErrUntrustedPublisher)):
+		slog.Warn("installing untrusted skill", "skill", skillName, "reason", err)
+		return "", nil
+	default:
+		return "", fmt.Errorf("%s: %w", skillName, err)
+	}
+}
+
+// removeStaleStaging deletes staging directories left behind by installs
+// that were killed, once they are old enough not to belong to a running one.
+func removeStaleStaging(stagingRoot string) {
+	entries, err := os.ReadDir(stagingRoot)
+	if err != nil {
+		return
+	}
+	cutoff := time.Now().Add(-2 * DefaultInstallTimeout)
+	for _, e := range entries {
+		if e.Name() == backupsDir {
+			continue
+		}
+		if info, err := e.Info(); err == nil && info.ModTime().Before(cutoff) {
+			os.RemoveAll(filepath.Join(stagingRoot, e.Name()))
+		}
+	}
+}
+
 func (si *SkillInstaller) Uninstall(skillName string) error {
 	skillDir := filepath.Join(si.workspace, "skills", skillName)
 
+	unlock, err := si.lockSkills()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	if _, err := os.Stat(skillDir); os.IsNotExist(err) {
-		return fmt.Errorf("skill '%s' not found", skillName)
+		return fmt.Errorf("%w: %s", ErrSkillNotFound, skillName)
 	}
 
 	if err := os.RemoveAll(skillDir); err != nil {
 		return fmt.Errorf("failed to remove skill: %w", err)
 	}
+	if err := si.forgetSource(skillName); err != nil {
+		slog.Warn("failed to update skill sources", "skill", skillName, "error", err)
+	}
 
 	return nil
 }
@@ -219,111 +471,135 @@ func (si *SkillInstaller) ListBuiltinSkills() []BuiltinSkill {
 
 // --- Internal helpers ---
 
-func (si *SkillInstaller) downloadRepoZip(ctx context.Context, repo, skillDir string) (*InstallResult, error) {
-	url := fmt.Sprintf("https://github.com/%s/archive/refs/heads/main.zip", repo)
+// downloadRepoZip downloads the repository zip at src's ref and extracts it
+// into destDir.
+func (si *SkillInstaller) downloadRepoZip(ctx context.Context, src SkillSource, destDir string) (int, error) {
+	url := fmt.Sprintf("%s/%s/archive/refs/heads/main.zip", si.archiveBaseURL, src.Repo)
+	if src.Ref != "" {
+		url = fmt.Sprintf("%s/%s/archive/%s.zip", si.archiveBaseURL, src.Repo, src.Ref)
+	}
 
-	client := &http.Client{Timeout: 60 * time.Second}
+	// No client timeout: archives with assets can be large, and the
+	// install deadline on ctx already bounds the download.
+	client := &http.Client{}
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return 0, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch repo zip: %w", err)
+		return 0, fmt.Errorf("failed to fetch repo zip: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("failed to fetch repo zip: HTTP %d", resp.StatusCode)
+		return 0, &downloadStatusError{what: "repo zip", status: resp.StatusCode}
 	}
 
 	// Write zip to temp file
 	tmpFile, err := os.CreateTemp("", "rdxclaw-skill-*.zip")
 	if err != nil {
-		return nil, fmt.Errorf("failed to create temp file: %w", err)
+		return 0, fmt.Errorf("failed to create temp file: %w", err)
 	}
 	defer os.Remove(tmpFile.Name())
 	defer tmpFile.Close()
 
-	if _, err := io.Copy(tmpFile, resp.Body); err != nil {
-		return nil, fmt.Errorf("failed to download zip: %w", err)
+	var body io.Reader = resp.Body
+	if si.progress != nil {
+		body = &progressReader{r: resp.Body, total: resp.ContentLength, report: si.progress}
+	}
+	if _, err := io.Copy(tmpFile, body); err != nil {
+		return 0, fmt.Errorf("failed to download zip: %w", err)
 	}
 	tmpFile.Close()
 
 	// Extract zip — GitHub zips have a top-level directory like "repo-main/"
-	if err := os.MkdirAll(skillDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create skill directory: %w", err)
-	}
-
-	filesWritten, err := extractZipStripRoot(tmpFile.Name(), skillDir)
+	filesWritten, err := extractZipStripRoot(tmpFile.Name(), destDir, si.onFile())
 	if err != nil {
-		os.RemoveAll(skillDir)
-		return nil, fmt.Errorf("failed to extract zip: %w", err)
+		return filesWritten, fmt.Errorf("failed to extract zip: %w", err)
 	}
-
-	manifest, _ := LoadManifest(skillDir)
-	name := filepath.Base(repo)
-	if manifest != nil {
-		name = manifest.Name
-	}
-
-	return &InstallResult{
-		Name:         name,
-		SkillDir:     skillDir,
-		Manifest:     manifest,
-		FilesWritten: filesWritten,
-	}, nil
+	return filesWritten, nil
 }
 
-func (si *SkillInstaller) downloadSkillMD(ctx context.Context, repo, skillDir string) (*InstallResult, error) {
-	url := fmt.Sprintf("https://raw.githubusercontent.com/%s/main/SKILL.md", repo)
+// downloadSkillMD downloads just the repository's SKILL.md at src's ref
+// into destDir.
+func (si *SkillInstaller) downloadSkillMD(ctx context.Context, src SkillSource, destDir string) (int, error) {
+	ref := src.Ref
+	if ref == "" {
+		ref = "main"
+	}
+	url := fmt.Sprintf("%s/%s/%s/SKILL.md", si.rawBaseURL, src.Repo, ref)
 
 	client := &http.Client{Timeout: 15 * time.Second}
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return 0, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch skill: %w", err)
+		return 0, fmt.Errorf("failed to fetch skill: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("failed to fetch skill: HTTP %d", resp.StatusCode)
+		return 0, fmt.Errorf("failed to fetch skill: HTTP %d", resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return 0, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	if err := os.MkdirAll(skillDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create skill directory: %w", err)
-	}
-
-	skillPath := filepath.Join(skillDir, "SKILL.md")
+	skillPath := filepath.Join(destDir, "SKILL.md")
 	if err := os.WriteFile(skillPath, body, 0644); err != nil {
-		return nil, fmt.Errorf("failed to write skill file: %w", err)
+		return 0, fmt.Errorf("failed to write skill file: %w", err)
 	}
+	return 1, nil
+}
 
-	return &InstallResult{
-		Name:         filepath.Base(repo),
-		SkillDir:     skillDir,
-		FilesWritten: 1,
-	}, nil
+// downloadStatusError reports an unexpected HTTP status for a download.
+type downloadStatusError struct {
+	what   string
+	status int
+}
+
+func (e *downloadStatusError) Error() string {
+	return fmt.Sprintf("failed to fetch %s: HTTP %d", e.what, e.status)
+}
+
+// zipUnavailable reports whether err means the repository has no zip to
+// download, which is the only case where the SKILL.md fallback applies.
+func zipUnavailable(err error) bool {
+	var se *downloadStatusError
+	return errors.As(err, &se) && (se.status == http.StatusNotFound || se.status == http.StatusGone)
+}
+
+// retriableDownload reports whether a failed download is worth retrying:
+// server errors, rate limiting, and network failures, but not a cancelled
+// or expired caller context.
+func retriableDownload(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var se *downloadStatusError
+	if errors.As(err, &se) {
+		return se.status >= 500 || se.status == http.StatusTooManyRequests
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
 }
 
 // extractZip extracts a zip archive to the destination directory.
-func extractZip(zipPath, destDir string) (int, error) {
-	return extractZipStripRoot(zipPath, destDir)
+// onFile, if non-nil, is called with the running count after each file.
+func extractZip(zipPath, destDir string, onFile func(int)) (int, error) {
+	return extractZipStripRoot(zipPath, destDir, onFile)
 }
 
 // extractZipStripRoot extracts a zip, stripping the top-level directory if all
 // files share one common root (as GitHub repo zips do).
-func extractZipStripRoot(zipPath, destDir string) (int, error) {
+func extractZipStripRoot(zipPath, destDir string, onFile func(int)) (int, error) {
 	r, err := zip.OpenReader(zipPath)
 	if err != nil {
 		return 0, err
@@ -334,13 +610,15 @@ func extractZipStripRoot(zipPath, destDir string) (int, error) {
 	var commonRoot string
 	for _, f := range r.File {
 		parts := strings.SplitN(f.Name, "/", 2)
-		if len(parts) >= 2 {
-			if commonRoot == "" {
-				commonRoot = parts[0] + "/"
-			} else if !strings.HasPrefix(f.Name, commonRoot) {
-				commonRoot = "" // No common root
-				break
-			}
+		if len(parts) < 2 {
+			commonRoot = "" // A top-level file means there is no wrapping folder
+			break
+		}
+		if commonRoot == "" {
+			commonRoot = parts[0] + "/"
+		} else if !strings.HasPrefix(f.Name, commonRoot) {
+			commonRoot = "" // No common root
+			break
 		}
 	}
 
@@ -388,13 +666,17 @@ func extractZipStripRoot(zipPath, destDir string) (int, error) {
 			return filesWritten, err
 		}
 		filesWritten++
+		if onFile != nil {
+			onFile(filesWritten)
+		}
 	}
 
 	return filesWritten, nil
 }
 
 // extractTarGz extracts a .tar.gz archive to the destination directory.
-func extractTarGz(archivePath, destDir string) (int, error) {
+// onFile, if non-nil, is called with the running count after each file.
+func extractTarGz(archivePath, destDir string, onFile func(int)) (int, error) {
 	file, err := os.Open(archivePath)
 	if err != nil {
 		return 0, err
@@ -443,6 +725,9 @@ func extractTarGz(archivePath, destDir string) (int, error) {
 				return filesWritten, err
 			}
 			filesWritten++
+			if onFile != nil {
+				onFile(filesWritten)
+			}
 		}
 	}
 
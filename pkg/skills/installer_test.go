@@ -0,0 +1,299 @@
+package skills
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallerErrors(t *testing.T) {
+	workspace := t.TempDir()
+	installer := NewSkillInstaller(workspace)
+	installer.SetAllowUntrusted(true)
+
+	t.Run("uninstall missing skill", func(t *testing.T) {
+		err := installer.Uninstall("missing")
+		assert.ErrorIs(t, err, ErrSkillNotFound)
+	})
+
+	t.Run("install over existing skill", func(t *testing.T) {
+		require.NoError(t, os.MkdirAll(filepath.Join(workspace, "skills", "weather"), 0755))
+
+		_, err := installer.InstallFromArchive(filepath.Join(t.TempDir(), "weather.zip"))
+		assert.ErrorIs(t, err, ErrSkillExists)
+	})
+}
+
+func TestInstallFromGitHubFallback(t *testing.T) {
+	tests := []struct {
+		name         string
+		zipStatus    int
+		wantErr      bool
+		wantZipCalls int32
+		wantFallback bool
+	}{
+		{name: "server error retries zip", zipStatus: http.StatusInternalServerError, wantErr: true, wantZipCalls: zipDownloadAttempts},
+		{name: "forbidden fails without fallback", zipStatus: http.StatusForbidden, wantErr: true, wantZipCalls: 1},
+		{name: "missing zip falls back", zipStatus: http.StatusNotFound, wantZipCalls: 1, wantFallback: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var zipCalls, mdCalls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case strings.HasSuffix(r.URL.Path, ".zip"):
+					zipCalls.Add(1)
+					w.WriteHeader(tt.zipStatus)
+				case strings.HasSuffix(r.URL.Path, "/SKILL.md"):
+					mdCalls.Add(1)
+					w.Write([]byte("---\nname: weather\ndescription: Weather\n---\n"))
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer server.Close()
+
+			workspace := t.TempDir()
+			installer := NewSkillInstaller(workspace)
+			installer.SetAllowUntrusted(true)
+			installer.archiveBaseURL = server.URL
+			installer.rawBaseURL = server.URL
+			installer.retryDelay = time.Millisecond
+
+			_, err := installer.InstallFromGitHub(context.Background(), "acme/weather")
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantZipCalls, zipCalls.Load(), "zip requests")
+
+			_, statErr := os.Stat(filepath.Join(workspace, "skills", "weather", "SKILL.md"))
+			if tt.wantFallback {
+				assert.Equal(t, int32(1), mdCalls.Load())
+				assert.NoError(t, statErr)
+			} else {
+				assert.Zero(t, mdCalls.Load(), "SKILL.md fallback must not run")
+				assert.True(t, os.IsNotExist(statErr))
+			}
+		})
+	}
+}
+
+func TestInstallFromGitHubTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Start the body, then stall until the client gives up.
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("PK"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	workspace := t.TempDir()
+	installer := NewSkillInstaller(workspace)
+	installer.SetAllowUntrusted(true)
+	installer.archiveBaseURL = server.URL
+	installer.rawBaseURL = server.URL
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err := installer.InstallFromGitHub(ctx, "acme/weather")
+	assert.ErrorIs(t, err, ErrInstallTimeout)
+
+	_, statErr := os.Stat(filepath.Join(workspace, "skills", "weather"))
+	assert.True(t, os.IsNotExist(statErr), "partial skill directory must be removed")
+}
+
+func TestInstallFromArchiveAtomic(t *testing.T) {
+	workspace := t.TempDir()
+	installer := NewSkillInstaller(workspace)
+	installer.SetAllowUntrusted(true)
+
+	// The third entry tries to create a file beneath SKILL.md, which fails
+	// after the first files have already been extracted.
+	archive := filepath.Join(t.TempDir(), "broken.zip")
+	f, err := os.Create(archive)
+	require.NoError(t, err)
+	zw := zip.NewWriter(f)
+	for _, name := range []string{"broken/SKILL.md", "broken/assets/data.txt", "broken/SKILL.md/oops"} {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		w.Write([]byte("content"))
+	}
+	require.NoError(t, zw.Close())
+	require.NoError(t, f.Close())
+
+	_, err = installer.InstallFromArchive(archive)
+	require.Error(t, err)
+
+	_, statErr := os.Stat(filepath.Join(workspace, "skills", "broken"))
+	assert.True(t, os.IsNotExist(statErr), "no skill directory may remain")
+
+	staged, _ := os.ReadDir(filepath.Join(workspace, stagingDir))
+	assert.Empty(t, staged, "staging directory must be discarded")
+}
+
+func TestInstallFromArchiveTopLevelFiles(t *testing.T) {
+	workspace := t.TempDir()
+	installer := NewSkillInstaller(workspace)
+	installer.SetAllowUntrusted(true)
+
+	// Files at the archive root: scripts/ is a folder of the skill, not a
+	// wrapping folder to strip.
+	archive := filepath.Join(t.TempDir(), "weather.zip")
+	f, err := os.Create(archive)
+	require.NoError(t, err)
+	zw := zip.NewWriter(f)
+	for _, name := range []string{"scripts/run.sh", "SKILL.md"} {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		w.Write([]byte("---\nname: weather\ndescription: Weather\n---\n"))
+	}
+	require.NoError(t, zw.Close())
+	require.NoError(t, f.Close())
+
+	_, err = installer.InstallFromArchive(archive)
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(workspace, "skills", "weather", "SKILL.md"))
+	assert.FileExists(t, filepath.Join(workspace, "skills", "weather", "scripts", "run.sh"))
+}
+
+func TestInstallProgress(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range []string{"weather-main/SKILL.md", "weather-main/scripts/run.sh"} {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		w.Write([]byte("---\nname: weather\ndescription: Weather\n---\n"))
+	}
+	require.NoError(t, zw.Close())
+	archive := buf.Bytes()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(len(archive)))
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	installer := NewSkillInstaller(t.TempDir())
+	installer.SetAllowUntrusted(true)
+	installer.archiveBaseURL = server.URL
+
+	var last InstallProgress
+	var extracted int
+	installer.SetProgress(func(p InstallProgress) {
+		switch p.Phase {
+		case PhaseDownload:
+			last = p
+		case PhaseExtract:
+			extracted = p.FilesExtracted
+		}
+	})
+
+	result, err := installer.InstallFromGitHub(context.Background(), "acme/weather")
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(archive)), last.BytesDownloaded)
+	assert.Equal(t, int64(len(archive)), last.BytesTotal)
+	assert.Equal(t, result.FilesWritten, extracted)
+}
+
+func TestInstallReservedName(t *testing.T) {
+	workspace := t.TempDir()
+	installer := NewSkillInstaller(workspace)
+	installer.SetAllowUntrusted(true)
+
+	archive := filepath.Join(t.TempDir(), "knowledge.zip")
+	f, err := os.Create(archive)
+	require.NoError(t, err)
+	zw := zip.NewWriter(f)
+	w, err := zw.Create("SKILL.md")
+	require.NoError(t, err)
+	w.Write([]byte("---\nname: knowledge\ndescription: Shadows the knowledge tool\n---\n"))
+	require.NoError(t, zw.Close())
+	require.NoError(t, f.Close())
+
+	_, err = installer.InstallFromArchive(archive)
+	assert.ErrorIs(t, err, ErrReservedName)
+
+	_, statErr := os.Stat(filepath.Join(workspace, "skills", "knowledge"))
+	assert.True(t, os.IsNotExist(statErr))
+}
+
+func TestConcurrentInstalls(t *testing.T) {
+	server := refServer(t)
+	workspace := t.TempDir()
+	names := []string{"weather", "news", "stock", "calendar"}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(names))
+	for i, name := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Separate installers, as separate CLI invocations would have.
+			installer := NewSkillInstaller(workspace)
+			installer.SetAllowUntrusted(true)
+			installer.archiveBaseURL = server.URL
+			installer.rawBaseURL = server.URL
+			_, errs[i] = installer.InstallFromGitHub(context.Background(), "acme/"+name+"@v1")
+		}()
+	}
+	wg.Wait()
+
+	installer := NewSkillInstaller(workspace)
+	installer.SetAllowUntrusted(true)
+	sources, err := installer.loadSources()
+	require.NoError(t, err)
+	for i, name := range names {
+		require.NoError(t, errs[i], name)
+		assert.Equal(t, "v1", skillRef(t, workspace, name))
+		assert.Equal(t, SkillSource{Repo: "acme/" + name, Ref: "v1"}, sources[name], "install record for %s", name)
+	}
+
+	loader := NewSkillsLoader(workspace, "", "")
+	assert.Len(t, loader.ListSkills(), len(names))
+}
+
+func TestInstallRejectsBadCron(t *testing.T) {
+	workspace := t.TempDir()
+	installer := NewSkillInstaller(workspace)
+	installer.SetAllowUntrusted(true)
+
+	archive := filepath.Join(t.TempDir(), "reports.zip")
+	f, err := os.Create(archive)
+	require.NoError(t, err)
+	zw := zip.NewWriter(f)
+	w, err := zw.Create("manifest.json")
+	require.NoError(t, err)
+	w.Write([]byte(`{"name": "reports", "version": "1.0.0", "description": "Reports",
+		"cron": [
+			{"name": "daily", "expr": "0 9 * * *", "task": "Send the report", "timezone": "Europe/Berlin"},
+			{"name": "hourly", "expr": "0 * * *", "task": "Check"}
+		]}`))
+	require.NoError(t, zw.Close())
+	require.NoError(t, f.Close())
+
+	_, err = installer.InstallFromArchive(archive)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `cron[1] "hourly"`)
+	assert.Contains(t, err.Error(), "invalid cron expression")
+
+	_, statErr := os.Stat(filepath.Join(workspace, "skills", "reports"))
+	assert.True(t, os.IsNotExist(statErr))
+}
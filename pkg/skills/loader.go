@@ -6,9 +6,12 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
+	"time"
 )
 
 var namePattern = regexp.MustCompile(`^[a-zA-Z0-9]+(-[a-zA-Z0-9]+)*$`)
@@ -18,9 +21,17 @@ const (
 	MaxDescriptionLength = 1024
 )
 
+// maxSkillDepth is how deep below a skills root a skill directory may sit,
+// counting the skill directory itself: skills/weather is 1 and
+// skills/finance/stock is 2.
+const maxSkillDepth = 3
+
 type SkillMetadata struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags,omitempty"`
+	Author      string   `json:"author,omitempty"`
+	Version     string   `json:"version,omitempty"`
 }
 
 type SkillInfo struct {
@@ -30,6 +41,23 @@ type SkillInfo struct {
 	Description  string         `json:"description"`
 	Manifest     *SkillManifest `json:"manifest,omitempty"`
 	Capabilities string         `json:"capabilities,omitempty"` // e.g. "2 script(s), 1 cron job(s)"
+	Tags         []string       `json:"tags,omitempty"`
+	Author       string         `json:"author,omitempty"`
+	Version      string         `json:"version,omitempty"`
+
+	// QualifiedName is the skill directory relative to its skills root,
+	// such as "weather", or "finance/stock" for a skill in a category folder.
+	QualifiedName string `json:"qualified_name"`
+}
+
+// HasTag reports whether the skill is tagged with tag, ignoring case.
+func (info SkillInfo) HasTag(tag string) bool {
+	for _, t := range info.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
 }
 
 func (info SkillInfo) validate() error {
@@ -58,6 +86,27 @@ type SkillsLoader struct {
 	workspaceSkills string // workspace skills (项目级别)
 	globalSkills    string // 全局 skills (~/.rdxclaw/skills)
 	builtinSkills   string // 内置 skills
+
+	// Parsed skills are cached and reused while the skill directories are
+	// unchanged. Without a watcher, changes are detected by comparing file
+	// mtimes; with one, the cache is kept until the watcher reports a change.
+	mu      sync.Mutex
+	watcher *Watcher
+	cache   *skillsCache
+	gen     uint64
+}
+
+// skillsCache holds the parsed skills for one state of the skill directories.
+type skillsCache struct {
+	fingerprint string
+	skills      []SkillInfo
+	summary     string
+	contents    map[string]skillContent // keyed by skill directory name
+}
+
+type skillContent struct {
+	content string
+	ok      bool
 }
 
 func NewSkillsLoader(workspace string, globalSkills string, builtinSkills string) *SkillsLoader {
@@ -69,65 +118,213 @@ func NewSkillsLoader(workspace string, globalSkills string, builtinSkills string
 	}
 }
 
-func (sl *SkillsLoader) ListSkills() []SkillInfo {
-	skills := make([]SkillInfo, 0)
+// Watch starts watching the skill directories and keeps the cached skills
+// until a SKILL.md or manifest changes. Call StopWatching to release the
+// watcher.
+func (sl *SkillsLoader) Watch(debounce time.Duration) error {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+
+	if sl.watcher != nil {
+		return nil
+	}
+
+	w, err := NewWatcher(sl.roots(), debounce, sl.Invalidate)
+	if err != nil {
+		return fmt.Errorf("failed to watch skills: %w", err)
+	}
+	sl.watcher = w
+	sl.cache = nil
+	sl.gen++
+	return nil
+}
+
+// StopWatching stops the watcher started by Watch, if any.
+func (sl *SkillsLoader) StopWatching() {
+	sl.mu.Lock()
+	w := sl.watcher
+	sl.watcher = nil
+	sl.cache = nil
+	sl.gen++
+	sl.mu.Unlock()
+
+	if w != nil {
+		w.Close()
+	}
+}
+
+// Invalidate drops cached skill data so the next call re-reads the skill
+// directories.
+func (sl *SkillsLoader) Invalidate() {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	sl.cache = nil
+	sl.gen++
+	slog.Debug("skills changed, cache invalidated")
+}
 
-	if sl.workspaceSkills != "" {
-		if dirs, err := os.ReadDir(sl.workspaceSkills); err == nil {
-			for _, dir := range dirs {
-				if dir.IsDir() {
-					info := sl.loadSkillInfo(sl.workspaceSkills, dir.Name(), "workspace")
-					if info != nil {
-						skills = append(skills, *info)
-					}
+func (sl *SkillsLoader) roots() []string {
+	return []string{sl.workspaceSkills, sl.globalSkills, sl.builtinSkills}
+}
+
+// snapshot returns the cached skills, rebuilding them if the skill
+// directories changed since they were loaded.
+func (sl *SkillsLoader) snapshot() *skillsCache {
+	sl.mu.Lock()
+	cached, watching, gen := sl.cache, sl.watcher != nil, sl.gen
+	sl.mu.Unlock()
+
+	var fingerprint string
+	if !watching {
+		fingerprint = sl.fingerprint()
+	}
+	if cached != nil && cached.fingerprint == fingerprint {
+		return cached
+	}
+
+	skills := sl.listSkillsStable()
+	c := &skillsCache{
+		fingerprint: fingerprint,
+		skills:      skills,
+		summary:     buildSkillsSummary(skills),
+		contents:    make(map[string]skillContent),
+	}
+
+	// Only cache if nothing was invalidated while the skills were loaded.
+	sl.mu.Lock()
+	if sl.gen == gen {
+		sl.cache = c
+	}
+	sl.mu.Unlock()
+	return c
+}
+
+// fingerprint summarises the skill directories by the names, sizes and
+// mtimes of their SKILL.md and manifest.json files. It is much cheaper than
+// reading and parsing them.
+func (sl *SkillsLoader) fingerprint() string {
+	var sb strings.Builder
+	for _, root := range sl.roots() {
+		if root == "" {
+			continue
+		}
+		if _, err := os.Stat(root); err != nil {
+			continue
+		}
+		sb.WriteString(root)
+		sb.WriteByte('\n')
+		for _, rel := range skillDirs(root) {
+			sb.WriteString(rel)
+			for _, name := range []string{"SKILL.md", "manifest.json"} {
+				if fi, err := os.Stat(filepath.Join(root, filepath.FromSlash(rel), name)); err == nil {
+					fmt.Fprintf(&sb, "|%s:%d:%d", name, fi.Size(), fi.ModTime().UnixNano())
 				}
 			}
+			sb.WriteByte('\n')
 		}
 	}
+	return sb.String()
+}
 
-	// 全局 skills (~/.rdxclaw/skills) - 被 workspace skills 覆盖
-	// Global skills (~/.rdxclaw/skills) — overridden by workspace skills
-	if sl.globalSkills != "" {
-		if dirs, err := os.ReadDir(sl.globalSkills); err == nil {
-			for _, dir := range dirs {
-				if dir.IsDir() {
-					// Check if already overridden by workspace
-					if sl.skillExists(skills, dir.Name(), "workspace") {
-						continue
-					}
-					info := sl.loadSkillInfo(sl.globalSkills, dir.Name(), "global")
-					if info != nil {
-						skills = append(skills, *info)
-					}
-				}
-			}
+func (sl *SkillsLoader) ListSkills() []SkillInfo {
+	return append([]SkillInfo(nil), sl.snapshot().skills...)
+}
+
+// listAttempts bounds how often listSkillsStable reads the skills again.
+const listAttempts = 3
+
+// listSkillsStable lists the skills, reading them again if the directories
+// changed during the read, as they do while a skill is being installed or
+// updated, so a half-seen change is not cached.
+func (sl *SkillsLoader) listSkillsStable() []SkillInfo {
+	before := sl.fingerprint()
+	for attempt := 1; ; attempt++ {
+		skills := sl.listSkills()
+		after := sl.fingerprint()
+		if after == before || attempt == listAttempts {
+			return skills
 		}
+		slog.Debug("skills changed while loading, reading again", "attempt", attempt)
+		before = after
 	}
+}
 
-	// Builtin skills — overridden by workspace or global
-	if sl.builtinSkills != "" {
-		if dirs, err := os.ReadDir(sl.builtinSkills); err == nil {
-			for _, dir := range dirs {
-				if dir.IsDir() {
-					if sl.skillExists(skills, dir.Name(), "workspace") || sl.skillExists(skills, dir.Name(), "global") {
-						continue
-					}
-					info := sl.loadSkillInfo(sl.builtinSkills, dir.Name(), "builtin")
-					if info != nil {
-						skills = append(skills, *info)
-					}
+func (sl *SkillsLoader) listSkills() []SkillInfo {
+	skills := make([]SkillInfo, 0)
+	sources := make(map[string]string) // skill name -> source it was loaded from
+
+	// Roots in precedence order: workspace skills override global skills
+	// (~/.rdxclaw/skills), which override builtin skills. Skills are matched
+	// by name, so a skill can be overridden from any category folder.
+	for _, r := range []struct{ dir, source string }{
+		{sl.workspaceSkills, "workspace"},
+		{sl.globalSkills, "global"},
+		{sl.builtinSkills, "builtin"},
+	} {
+		if r.dir == "" {
+			continue
+		}
+		for _, rel := range skillDirs(r.dir) {
+			info := sl.loadSkillInfo(r.dir, rel, r.source)
+			if info == nil {
+				continue
+			}
+			if source, ok := sources[info.Name]; ok {
+				if source == r.source {
+					slog.Warn("duplicate skill name, keeping the first", "name", info.Name, "source", r.source, "skipped", rel)
 				}
+				continue
 			}
+			sources[info.Name] = r.source
+			skills = append(skills, *info)
 		}
 	}
 
 	return skills
 }
 
-// loadSkillInfo loads a skill from a directory. A skill is detected if it has
-// either SKILL.md, manifest.json, or both. Manifest data takes priority.
-func (sl *SkillsLoader) loadSkillInfo(baseDir, dirName, source string) *SkillInfo {
-	skillDir := filepath.Join(baseDir, dirName)
+// skillDirs returns the skill directories under root as slash-separated
+// paths relative to it, in lexical order. A directory holding SKILL.md or
+// manifest.json is a skill; any other directory is a category that may
+// hold more skills. Hidden directories are skipped.
+func skillDirs(root string) []string {
+	var dirs []string
+	var walk func(rel string, depth int)
+	walk = func(rel string, depth int) {
+		entries, err := os.ReadDir(filepath.Join(root, filepath.FromSlash(rel)))
+		if err != nil {
+			return
+		}
+		for _, e := range entries {
+			if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+				continue
+			}
+			child := path.Join(rel, e.Name())
+			if isSkillDir(filepath.Join(root, filepath.FromSlash(child))) {
+				dirs = append(dirs, child)
+			} else if depth < maxSkillDepth {
+				walk(child, depth+1)
+			}
+		}
+	}
+	walk("", 1)
+	return dirs
+}
+
+func isSkillDir(dir string) bool {
+	for _, name := range []string{"SKILL.md", "manifest.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// loadSkillInfo loads a skill from a directory, given relative to baseDir.
+// A skill is detected if it has either SKILL.md, manifest.json, or both.
+// Manifest data takes priority.
+func (sl *SkillsLoader) loadSkillInfo(baseDir, rel, source string) *SkillInfo {
+	skillDir := filepath.Join(baseDir, filepath.FromSlash(rel))
 	skillFile := filepath.Join(skillDir, "SKILL.md")
 	_, err := os.Stat(skillFile)
 	hasSkillMD := err == nil
@@ -140,16 +337,17 @@ func (sl *SkillsLoader) loadSkillInfo(baseDir, dirName, source string) *SkillInf
 		return nil
 	}
 
-	path := skillFile
+	skillPath := skillFile
 	if !hasSkillMD && manifest != nil {
-		path = filepath.Join(skillDir, "manifest.json")
+		skillPath = filepath.Join(skillDir, "manifest.json")
 	}
 
 	info := SkillInfo{
-		Name:     dirName,
-		Path:     path,
-		Source:   source,
-		Manifest: manifest,
+		Name:          filepath.Base(skillDir),
+		QualifiedName: rel,
+		Path:          skillPath,
+		Source:        source,
+		Manifest:      manifest,
 	}
 
 	// Manifest data takes priority over SKILL.md frontmatter
@@ -157,6 +355,9 @@ func (sl *SkillsLoader) loadSkillInfo(baseDir, dirName, source string) *SkillInf
 		info.Name = manifest.Name
 		info.Description = manifest.Description
 		info.Capabilities = manifest.CapabilitiesSummary()
+		info.Tags = manifest.Tags
+		info.Author = manifest.Author
+		info.Version = manifest.Version
 	} else if hasSkillMD {
 		metadata := sl.getSkillMetadata(skillFile)
 		if metadata != nil {
@@ -164,6 +365,9 @@ func (sl *SkillsLoader) loadSkillInfo(baseDir, dirName, source string) *SkillInf
 			if metadata.Name != "" {
 				info.Name = metadata.Name
 			}
+			info.Tags = metadata.Tags
+			info.Author = metadata.Author
+			info.Version = metadata.Version
 		}
 		info.Capabilities = "prompt-only"
 	}
@@ -176,16 +380,6 @@ func (sl *SkillsLoader) loadSkillInfo(baseDir, dirName, source string) *SkillInf
 	return &info
 }
 
-// skillExists checks if a skill with the given directory name and source already exists.
-func (sl *SkillsLoader) skillExists(skills []SkillInfo, dirName, source string) bool {
-	for _, s := range skills {
-		if s.Name == dirName && s.Source == source {
-			return true
-		}
-	}
-	return false
-}
-
 // GetSkillManifest returns the manifest for a named skill, or nil if not found.
 func (sl *SkillsLoader) GetSkillManifest(name string) *SkillManifest {
 	for _, s := range sl.ListSkills() {
@@ -197,33 +391,73 @@ func (sl *SkillsLoader) GetSkillManifest(name string) *SkillManifest {
 }
 
 func (sl *SkillsLoader) LoadSkill(name string) (string, bool) {
-	// 1. 优先从 workspace skills 加载（项目级别）
-	if sl.workspaceSkills != "" {
-		skillFile := filepath.Join(sl.workspaceSkills, name, "SKILL.md")
-		if content, err := os.ReadFile(skillFile); err == nil {
-			return sl.stripFrontmatter(string(content)), true
-		}
+	c := sl.snapshot()
+
+	sl.mu.Lock()
+	cached, found := c.contents[name]
+	sl.mu.Unlock()
+	if found {
+		return cached.content, cached.ok
 	}
 
-	// 2. 其次从全局 skills 加载 (~/.rdxclaw/skills)
-	if sl.globalSkills != "" {
-		skillFile := filepath.Join(sl.globalSkills, name, "SKILL.md")
-		if content, err := os.ReadFile(skillFile); err == nil {
-			return sl.stripFrontmatter(string(content)), true
+	content, ok := sl.loadSkill(c.skills, name)
+
+	sl.mu.Lock()
+	c.contents[name] = skillContent{content: content, ok: ok}
+	sl.mu.Unlock()
+	return content, ok
+}
+
+// loadSkill returns the SKILL.md body of a skill. name is matched against
+// the listed skills' names, then their qualified names, and finally taken
+// as a skill directory relative to a skills root, in precedence order.
+func (sl *SkillsLoader) loadSkill(skills []SkillInfo, name string) (string, bool) {
+	for _, byName := range []bool{true, false} {
+		for _, s := range skills {
+			if (byName && s.Name == name) || (!byName && s.QualifiedName == name) {
+				if content, err := os.ReadFile(filepath.Join(filepath.Dir(s.Path), "SKILL.md")); err == nil {
+					return sl.stripFrontmatter(string(content)), true
+				}
+			}
 		}
 	}
 
-	// 3. 最后从内置 skills 加载
-	if sl.builtinSkills != "" {
-		skillFile := filepath.Join(sl.builtinSkills, name, "SKILL.md")
-		if content, err := os.ReadFile(skillFile); err == nil {
-			return sl.stripFrontmatter(string(content)), true
+	if rel := filepath.FromSlash(name); filepath.IsLocal(rel) {
+		for _, root := range sl.roots() {
+			if root == "" {
+				continue
+			}
+			if content, err := os.ReadFile(filepath.Join(root, rel, "SKILL.md")); err == nil {
+				return sl.stripFrontmatter(string(content)), true
+			}
 		}
 	}
 
 	return "", false
 }
 
+// HasSkill reports whether name resolves to a skill the way LoadSkill
+// resolves it: by name, by qualified name, or as a skill directory under
+// one of the skills roots.
+func (sl *SkillsLoader) HasSkill(name string) bool {
+	for _, s := range sl.ListSkills() {
+		if s.Name == name || s.QualifiedName == name {
+			return true
+		}
+	}
+	if rel := filepath.FromSlash(name); filepath.IsLocal(rel) {
+		for _, root := range sl.roots() {
+			if root == "" {
+				continue
+			}
+			if info, err := os.Stat(filepath.Join(root, rel)); err == nil && info.IsDir() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (sl *SkillsLoader) LoadSkillsForContext(skillNames []string) string {
 	if len(skillNames) == 0 {
 		return ""
@@ -241,7 +475,10 @@ func (sl *SkillsLoader) LoadSkillsForContext(skillNames []string) string {
 }
 
 func (sl *SkillsLoader) BuildSkillsSummary() string {
-	allSkills := sl.ListSkills()
+	return sl.snapshot().summary
+}
+
+func buildSkillsSummary(allSkills []SkillInfo) string {
 	if len(allSkills) == 0 {
 		return ""
 	}
@@ -282,15 +519,9 @@ func (sl *SkillsLoader) getSkillMetadata(skillPath string) *SkillMetadata {
 	}
 
 	// Try JSON first (for backward compatibility)
-	var jsonMeta struct {
-		Name        string `json:"name"`
-		Description string `json:"description"`
-	}
+	var jsonMeta SkillMetadata
 	if err := json.Unmarshal([]byte(frontmatter), &jsonMeta); err == nil {
-		return &SkillMetadata{
-			Name:        jsonMeta.Name,
-			Description: jsonMeta.Description,
-		}
+		return &jsonMeta
 	}
 
 	// Fall back to simple YAML parsing
@@ -298,49 +529,12 @@ func (sl *SkillsLoader) getSkillMetadata(skillPath string) *SkillMetadata {
 	return &SkillMetadata{
 		Name:        yamlMeta["name"],
 		Description: yamlMeta["description"],
+		Tags:        parseList(yamlMeta["tags"]),
+		Author:      yamlMeta["author"],
+		Version:     yamlMeta["version"],
 	}
 }
 
-// parseSimpleYAML parses simple key: value YAML format
-// Example: name: github\n description: "..."
-func (sl *SkillsLoader) parseSimpleYAML(content string) map[string]string {
-	result := make(map[string]string)
-
-	for _, line := range strings.Split(content, "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-
-		parts := strings.SplitN(line, ":", 2)
-		if len(parts) == 2 {
-			key := strings.TrimSpace(parts[0])
-			value := strings.TrimSpace(parts[1])
-			// Remove quotes if present
-			value = strings.Trim(value, "\"'")
-			result[key] = value
-		}
-	}
-
-	return result
-}
-
-func (sl *SkillsLoader) extractFrontmatter(content string) string {
-	// (?s) enables DOTALL mode so . matches newlines
-	// Match first ---, capture everything until next --- on its own line
-	re := regexp.MustCompile(`(?s)^---\n(.*)\n---`)
-	match := re.FindStringSubmatch(content)
-	if len(match) > 1 {
-		return match[1]
-	}
-	return ""
-}
-
-func (sl *SkillsLoader) stripFrontmatter(content string) string {
-	re := regexp.MustCompile(`^---\n.*?\n---\n`)
-	return re.ReplaceAllString(content, "")
-}
-
 func escapeXML(s string) string {
 	s = strings.ReplaceAll(s, "&", "&amp;")
 	s = strings.ReplaceAll(s, "<", "&lt;")
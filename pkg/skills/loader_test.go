@@ -1,9 +1,14 @@
 package skills
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSkillsInfoValidate(t *testing.T) {
@@ -75,3 +80,121 @@ func TestSkillsInfoValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestSkillsLoaderCache(t *testing.T) {
+	workspace := t.TempDir()
+	skillsDir := filepath.Join(workspace, "skills")
+	writeSkill(t, skillsDir, "weather", "old description")
+
+	sl := NewSkillsLoader(workspace, "", "")
+	skills := sl.ListSkills()
+	require.Len(t, skills, 1)
+	first := sl.snapshot()
+	assert.Same(t, first, sl.snapshot(), "unchanged directories should reuse the cache")
+
+	content, ok := sl.LoadSkill("weather")
+	require.True(t, ok)
+	assert.Contains(t, content, "# weather")
+
+	// Rewrite with a distinct mtime so the change is visible on coarse clocks.
+	writeSkill(t, skillsDir, "weather", "new description")
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(filepath.Join(skillsDir, "weather", "SKILL.md"), future, future))
+
+	assert.NotSame(t, first, sl.snapshot())
+	assert.Contains(t, sl.BuildSkillsSummary(), "new description")
+
+	writeSkill(t, skillsDir, "notes", "another skill")
+	assert.Len(t, sl.ListSkills(), 2)
+
+	require.NoError(t, os.RemoveAll(filepath.Join(skillsDir, "notes")))
+	assert.Len(t, sl.ListSkills(), 1)
+	_, ok = sl.LoadSkill("notes")
+	assert.False(t, ok)
+}
+
+func newBenchmarkLoader(b *testing.B, n int) *SkillsLoader {
+	workspace := b.TempDir()
+	for i := 0; i < n; i++ {
+		writeSkill(b, filepath.Join(workspace, "skills"), fmt.Sprintf("skill-%d", i), fmt.Sprintf("benchmark skill %d", i))
+	}
+	return NewSkillsLoader(workspace, "", "")
+}
+
+// BenchmarkListSkills compares re-reading 100 skills on every call with the
+// mtime-validated cache and the watcher-backed cache.
+func BenchmarkListSkills(b *testing.B) {
+	b.Run("uncached", func(b *testing.B) {
+		sl := newBenchmarkLoader(b, 100)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			sl.listSkills()
+		}
+	})
+
+	b.Run("cached", func(b *testing.B) {
+		sl := newBenchmarkLoader(b, 100)
+		sl.ListSkills()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			sl.ListSkills()
+		}
+	})
+
+	b.Run("watched", func(b *testing.B) {
+		sl := newBenchmarkLoader(b, 100)
+		if err := sl.Watch(0); err != nil {
+			b.Fatal(err)
+		}
+		defer sl.StopWatching()
+		sl.ListSkills()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			sl.ListSkills()
+		}
+	})
+}
+
+func TestSkillsLoaderNestedLayout(t *testing.T) {
+	workspace := t.TempDir()
+	workspaceSkills := filepath.Join(workspace, "skills")
+	globalSkills := t.TempDir()
+	builtinSkills := t.TempDir()
+
+	writeSkill(t, workspaceSkills, "weather", "flat skill")
+	writeSkill(t, filepath.Join(workspaceSkills, "finance"), "stock", "workspace stock")
+	writeSkill(t, filepath.Join(workspaceSkills, "finance", "crypto"), "wallet", "two levels deep")
+	writeSkill(t, filepath.Join(globalSkills, "markets"), "stock", "global stock")
+	writeSkill(t, filepath.Join(globalSkills, "tools"), "notes", "global notes")
+	writeSkill(t, builtinSkills, "notes", "builtin notes")
+
+	sl := NewSkillsLoader(workspace, globalSkills, builtinSkills)
+	byName := map[string]SkillInfo{}
+	for _, s := range sl.ListSkills() {
+		byName[s.Name] = s
+	}
+	require.Len(t, byName, 4)
+
+	assert.Equal(t, "weather", byName["weather"].QualifiedName)
+	assert.Equal(t, "finance/crypto/wallet", byName["wallet"].QualifiedName)
+
+	// Workspace wins over global, and global over builtin, across nesting
+	assert.Equal(t, "workspace", byName["stock"].Source)
+	assert.Equal(t, "finance/stock", byName["stock"].QualifiedName)
+	assert.Equal(t, "global", byName["notes"].Source)
+	assert.Equal(t, "tools/notes", byName["notes"].QualifiedName)
+
+	for _, name := range []string{"finance/stock", "stock"} {
+		content, ok := sl.LoadSkill(name)
+		require.True(t, ok, name)
+		assert.Contains(t, content, "# stock", name)
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(builtinSkills, "notes", "SKILL.md"),
+		[]byte("---\nname: notes\ndescription: builtin notes\n---\n\n# builtin copy\n"), 0644))
+	content, ok := sl.LoadSkill("notes")
+	require.True(t, ok)
+	assert.Contains(t, content, "# notes", "the global copy in a category folder must win")
+
+	_, ok = sl.LoadSkill("../escape")
+	assert.False(t, ok)
+}
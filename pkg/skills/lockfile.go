@@ -0,0 +1,268 @@
+package skills
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// LockfileName is the workspace file listing the skills `skills sync`
+// installs, each pinned to a ref.
+const LockfileName = "skills.lock"
+
+// sourcesFile records where each skill installed from GitHub came from. It
+// lives outside the skill directories, whose every file is covered by the
+// skill's signature.
+const sourcesFile = ".skill-sources.json"
+
+// SkillSource is a GitHub repository and the branch, tag or commit to
+// install from it. An empty Ref means the main branch.
+type SkillSource struct {
+	Repo string `json:"repo"`
+	Ref  string `json:"ref,omitempty"`
+}
+
+// ParseSource parses "owner/repo" or "owner/repo@ref".
+func ParseSource(s string) SkillSource {
+	if i := strings.LastIndex(s, "@"); i > 0 {
+		return SkillSource{Repo: s[:i], Ref: s[i+1:]}
+	}
+	return SkillSource{Repo: s}
+}
+
+// Name returns the name the skill is installed under.
+func (s SkillSource) Name() string {
+	return filepath.Base(s.Repo)
+}
+
+func (s SkillSource) String() string {
+	if s.Ref == "" {
+		return s.Repo
+	}
+	return s.Repo + "@" + s.Ref
+}
+
+// Lockfile lists the skills a workspace should have installed.
+//
+//	{"skills": [{"repo": "Sterlites/rdxclaw-skills/weather", "ref": "v1.2.0"}]}
+type Lockfile struct {
+	Skills []SkillSource `json:"skills"`
+}
+
+// LoadLockfile reads and validates a lockfile.
+func LoadLockfile(path string) (*Lockfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var lock Lockfile
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", filepath.Base(path), err)
+	}
+
+	seen := make(map[string]bool, len(lock.Skills))
+	for i, src := range lock.Skills {
+		if src.Repo == "" {
+			return nil, fmt.Errorf("invalid %s: skills[%d]: repo is required", filepath.Base(path), i)
+		}
+		if seen[src.Name()] {
+			return nil, fmt.Errorf("invalid %s: skill %q is listed twice", filepath.Base(path), src.Name())
+		}
+		seen[src.Name()] = true
+	}
+	return &lock, nil
+}
+
+// SyncPlan is what it takes to make the workspace's skills match a lockfile.
+type SyncPlan struct {
+	Install []SkillSource // listed but not installed
+	Update  []SkillSource // installed from a different repo or ref
+	Remove  []string      // installed from GitHub but no longer listed
+}
+
+// Empty reports whether the workspace already matches the lockfile.
+func (p *SyncPlan) Empty() bool {
+	return len(p.Install) == 0 && len(p.Update) == 0 && len(p.Remove) == 0
+}
+
+// PlanSync compares the workspace's skills with lock. A listed skill whose
+// source was never recorded, such as one installed from an archive, is
+// updated. Only skills with a recorded source are removed when unlisted;
+// those installed from an archive, by hand or as built-ins are left alone.
+func (si *SkillInstaller) PlanSync(lock *Lockfile) (*SyncPlan, error) {
+	installed, err := si.installedSkills()
+	if err != nil {
+		return nil, err
+	}
+	sources, err := si.loadSources()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &SyncPlan{}
+	listed := make(map[string]bool, len(lock.Skills))
+	for _, src := range lock.Skills {
+		name := src.Name()
+		listed[name] = true
+		switch recorded, ok := sources[name]; {
+		case !installed[name]:
+			plan.Install = append(plan.Install, src)
+		case !ok || recorded != src:
+			plan.Update = append(plan.Update, src)
+		}
+	}
+	for name := range installed {
+		if _, ok := sources[name]; ok && !listed[name] {
+			plan.Remove = append(plan.Remove, name)
+		}
+	}
+	sort.Strings(plan.Remove)
+	return plan, nil
+}
+
+// Sync applies plan. It carries on past a failed skill so one bad entry
+// does not block the rest, and returns every failure joined.
+func (si *SkillInstaller) Sync(ctx context.Context, plan *SyncPlan) error {
+	var errs []error
+	for _, src := range plan.Install {
+		if _, err := si.InstallFromGitHub(ctx, src.String()); err != nil {
+			errs = append(errs, fmt.Errorf("install %s: %w", src, err))
+		}
+	}
+	for _, src := range plan.Update {
+		if _, err := si.Update(ctx, src); err != nil {
+			errs = append(errs, fmt.Errorf("update %s: %w", src, err))
+		}
+	}
+	for _, name := range plan.Remove {
+		if err := si.Uninstall(name); err != nil {
+			errs = append(errs, fmt.Errorf("remove %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// backupsDir, inside the staging directory, holds the versions Update sets
+// aside. One that could not be restored stays there for the user to recover,
+// so stale-staging cleanup skips it.
+const backupsDir = "backups"
+
+// Update replaces an installed skill with the one at src. The old version
+// is set aside while the new one installs and is restored if that fails.
+// The skills lock is held throughout, so no other install, update or
+// uninstall can see the skill missing or claim its name meanwhile.
+// If even the restore fails, the old version is kept under the staging
+// directory and the error says where.
+func (si *SkillInstaller) Update(ctx context.Context, src SkillSource) (*InstallResult, error) {
+	skillName := src.Name()
+	skillDir := filepath.Join(si.workspace, "skills", skillName)
+
+	unlock, err := si.lockSkills()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if _, err := os.Stat(skillDir); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrSkillNotFound, skillName)
+	}
+
+	backupsRoot := filepath.Join(si.workspace, stagingDir, backupsDir)
+	if err := os.MkdirAll(backupsRoot, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	backupRoot, err := os.MkdirTemp(backupsRoot, skillName+"-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+
+	backup := filepath.Join(backupRoot, skillName)
+	if err := os.Rename(skillDir, backup); err != nil {
+		os.RemoveAll(backupRoot)
+		return nil, fmt.Errorf("failed to set aside installed skill: %w", err)
+	}
+
+	result, err := si.installSource(ctx, src, true)
+	if err != nil {
+		// Keep the old version if it cannot be put back, so it is not lost.
+		if restoreErr := os.Rename(backup, skillDir); restoreErr != nil {
+			return nil, fmt.Errorf("%w (restoring the previous version also failed: %v; it was kept in %s)", err, restoreErr, backup)
+		}
+		os.RemoveAll(backupRoot)
+		return nil, err
+	}
+	os.RemoveAll(backupRoot)
+	return result, nil
+}
+
+// installedSkills returns the names of the skills in the workspace.
+func (si *SkillInstaller) installedSkills() (map[string]bool, error) {
+	entries, err := os.ReadDir(filepath.Join(si.workspace, "skills"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	installed := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		if e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
+			installed[e.Name()] = true
+		}
+	}
+	return installed, nil
+}
+
+func (si *SkillInstaller) loadSources() (map[string]SkillSource, error) {
+	sources := make(map[string]SkillSource)
+	data, err := os.ReadFile(filepath.Join(si.workspace, sourcesFile))
+	if os.IsNotExist(err) {
+		return sources, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &sources); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", sourcesFile, err)
+	}
+	return sources, nil
+}
+
+func (si *SkillInstaller) saveSources(sources map[string]SkillSource) error {
+	data, err := json.MarshalIndent(sources, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(si.workspace, sourcesFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// recordSource and forgetSource rewrite the install records; callers hold
+// the skills lock.
+func (si *SkillInstaller) recordSource(skillName string, src SkillSource) error {
+	sources, err := si.loadSources()
+	if err != nil {
+		return err
+	}
+	sources[skillName] = src
+	return si.saveSources(sources)
+}
+
+func (si *SkillInstaller) forgetSource(skillName string) error {
+	sources, err := si.loadSources()
+	if err != nil {
+		return err
+	}
+	if _, ok := sources[skillName]; !ok {
+		return nil
+	}
+	delete(sources, skillName)
+	return si.saveSources(sources)
+}
@@ -0,0 +1,243 @@
+package skills
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// refServer serves a repo zip for every ref, with the ref in the skill's
+// description, except for refs listed in broken.
+func refServer(t *testing.T, broken ...string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// /<owner>/<repo>/archive/<ref>.zip or .../archive/refs/heads/main.zip
+		path, ok := strings.CutSuffix(r.URL.Path, ".zip")
+		_, ref, _ := strings.Cut(path, "/archive/")
+		if !ok || ref == "" {
+			http.NotFound(w, r)
+			return
+		}
+		ref = strings.TrimPrefix(ref, "refs/heads/")
+		for _, b := range broken {
+			if ref == b {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+		}
+
+		name := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")[1]
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		f, _ := zw.Create(name + "-" + ref + "/SKILL.md")
+		f.Write([]byte("---\nname: " + name + "\ndescription: " + ref + "\n---\n"))
+		zw.Close()
+		w.Write(buf.Bytes())
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func writeLockfile(t *testing.T, workspace, content string) *Lockfile {
+	path := filepath.Join(workspace, LockfileName)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	lock, err := LoadLockfile(path)
+	require.NoError(t, err)
+	return lock
+}
+
+func skillRef(t *testing.T, workspace, name string) string {
+	data, err := os.ReadFile(filepath.Join(workspace, "skills", name, "SKILL.md"))
+	require.NoError(t, err)
+	_, desc, _ := strings.Cut(string(data), "description: ")
+	return strings.Fields(desc)[0]
+}
+
+func TestSyncReconcilesLockfile(t *testing.T) {
+	server := refServer(t, "v3")
+	workspace := t.TempDir()
+	installer := NewSkillInstaller(workspace)
+	installer.SetAllowUntrusted(true)
+	installer.archiveBaseURL = server.URL
+	installer.rawBaseURL = server.URL
+	installer.retryDelay = time.Millisecond
+	ctx := context.Background()
+
+	// A skill installed by hand and absent from the lockfile is not sync's
+	// to remove.
+	require.NoError(t, os.MkdirAll(filepath.Join(workspace, "skills", "stale"), 0755))
+
+	lock := writeLockfile(t, workspace, `{"skills": [
+		{"repo": "acme/weather", "ref": "v1"},
+		{"repo": "acme/news"}
+	]}`)
+	plan, err := installer.PlanSync(lock)
+	require.NoError(t, err)
+	assert.Equal(t, []SkillSource{{Repo: "acme/weather", Ref: "v1"}, {Repo: "acme/news"}}, plan.Install)
+	assert.Empty(t, plan.Update)
+	assert.Empty(t, plan.Remove)
+
+	require.NoError(t, installer.Sync(ctx, plan))
+	assert.Equal(t, "v1", skillRef(t, workspace, "weather"))
+	assert.Equal(t, "main", skillRef(t, workspace, "news"))
+	assert.DirExists(t, filepath.Join(workspace, "skills", "stale"))
+
+	t.Run("in sync", func(t *testing.T) {
+		plan, err := installer.PlanSync(lock)
+		require.NoError(t, err)
+		assert.True(t, plan.Empty(), "plan: %+v", plan)
+	})
+
+	t.Run("update and remove", func(t *testing.T) {
+		lock := writeLockfile(t, workspace, `{"skills": [{"repo": "acme/weather", "ref": "v2"}]}`)
+		plan, err := installer.PlanSync(lock)
+		require.NoError(t, err)
+		assert.Empty(t, plan.Install)
+		assert.Equal(t, []SkillSource{{Repo: "acme/weather", Ref: "v2"}}, plan.Update)
+		assert.Equal(t, []string{"news"}, plan.Remove)
+
+		require.NoError(t, installer.Sync(ctx, plan))
+		assert.Equal(t, "v2", skillRef(t, workspace, "weather"))
+		assert.NoDirExists(t, filepath.Join(workspace, "skills", "news"))
+
+		sources, err := installer.loadSources()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]SkillSource{"weather": {Repo: "acme/weather", Ref: "v2"}}, sources)
+	})
+
+	t.Run("failed update keeps the installed version", func(t *testing.T) {
+		lock := writeLockfile(t, workspace, `{"skills": [{"repo": "acme/weather", "ref": "v3"}]}`)
+		plan, err := installer.PlanSync(lock)
+		require.NoError(t, err)
+		require.Len(t, plan.Update, 1)
+
+		assert.Error(t, installer.Sync(ctx, plan))
+		assert.Equal(t, "v2", skillRef(t, workspace, "weather"))
+
+		plan, err = installer.PlanSync(lock)
+		require.NoError(t, err)
+		assert.Len(t, plan.Update, 1, "the skill is still out of date")
+	})
+}
+
+func TestUpdateKeepsUnrestorableBackup(t *testing.T) {
+	refs := refServer(t)
+	workspace := t.TempDir()
+	skillDir := filepath.Join(workspace, "skills", "weather")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/archive/v2") {
+			// Something takes the skill's place, so it cannot be put back.
+			os.MkdirAll(filepath.Join(skillDir, "intruder"), 0755)
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		refs.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	installer := NewSkillInstaller(workspace)
+	installer.SetAllowUntrusted(true)
+	installer.archiveBaseURL = server.URL
+	installer.rawBaseURL = server.URL
+	ctx := context.Background()
+
+	_, err := installer.InstallFromGitHub(ctx, "acme/weather@v1")
+	require.NoError(t, err)
+
+	_, updateErr := installer.Update(ctx, SkillSource{Repo: "acme/weather", Ref: "v2"})
+	require.Error(t, updateErr)
+
+	backups, err := filepath.Glob(filepath.Join(workspace, stagingDir, backupsDir, "weather-*", "weather", "SKILL.md"))
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	assert.Contains(t, updateErr.Error(), "it was kept in "+filepath.Dir(backups[0]))
+	data, err := os.ReadFile(backups[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "description: v1")
+}
+
+func TestUpdateHoldsSkillsLock(t *testing.T) {
+	refs := refServer(t)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/archive/v2") {
+			once.Do(func() { close(started) })
+			<-release
+		}
+		refs.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	workspace := t.TempDir()
+	installer := NewSkillInstaller(workspace)
+	installer.SetAllowUntrusted(true)
+	installer.archiveBaseURL = server.URL
+	installer.rawBaseURL = server.URL
+	ctx := context.Background()
+
+	_, err := installer.InstallFromGitHub(ctx, "acme/weather@v1")
+	require.NoError(t, err)
+
+	updated := make(chan error, 1)
+	go func() {
+		_, err := installer.Update(ctx, SkillSource{Repo: "acme/weather", Ref: "v2"})
+		updated <- err
+	}()
+	<-started
+
+	// The old version is set aside, but the name is still taken.
+	installed := make(chan error, 1)
+	go func() {
+		_, err := installer.InstallFromGitHub(ctx, "other/weather@v9")
+		installed <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	require.NoError(t, <-updated)
+	assert.ErrorIs(t, <-installed, ErrSkillExists)
+	assert.Equal(t, "v2", skillRef(t, workspace, "weather"))
+
+	sources, err := installer.loadSources()
+	require.NoError(t, err)
+	assert.Equal(t, SkillSource{Repo: "acme/weather", Ref: "v2"}, sources["weather"])
+}
+
+func TestLoadLockfileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "malformed", content: `{"skills": [`, wantErr: "invalid skills.lock"},
+		{name: "missing repo", content: `{"skills": [{"ref": "v1"}]}`, wantErr: "repo is required"},
+		{name: "duplicate", content: `{"skills": [{"repo": "a/weather"}, {"repo": "b/weather"}]}`, wantErr: "listed twice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), LockfileName)
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0644))
+			_, err := LoadLockfile(path)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestParseSource(t *testing.T) {
+	assert.Equal(t, SkillSource{Repo: "acme/weather"}, ParseSource("acme/weather"))
+	assert.Equal(t, SkillSource{Repo: "acme/weather", Ref: "v1.2.0"}, ParseSource("acme/weather@v1.2.0"))
+	assert.Equal(t, "weather", ParseSource("acme/skills/weather@main").Name())
+}
@@ -7,6 +7,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/Sterlites/RDxClaw/pkg/tools"
 )
 
 // SkillManifest defines the structure of a skill's manifest.json file.
@@ -19,12 +21,26 @@ type SkillManifest struct {
 	Description  string        `json:"description"`
 	Author       string        `json:"author,omitempty"`
 	License      string        `json:"license,omitempty"`
+	Tags         []string      `json:"tags,omitempty"`
 	EnvVars      []EnvVarSpec  `json:"env_vars,omitempty"`
 	Scripts      []ScriptSpec  `json:"scripts,omitempty"`
 	Cron         []CronSpec    `json:"cron,omitempty"`
 	Webhooks     []WebhookSpec `json:"webhooks,omitempty"`
 	Assets       []string      `json:"assets,omitempty"`
 	Dependencies []string      `json:"dependencies,omitempty"`
+
+	// MaxIterations overrides the agent's tool loop iteration cap when the
+	// skill is executed directly. Zero uses the configured default; values
+	// above the global limit are clamped.
+	MaxIterations int `json:"max_iterations,omitempty"`
+
+	// MaxDuration bounds a direct execution of the skill, in seconds. Zero
+	// uses the server's skill timeout.
+	MaxDuration int `json:"max_duration,omitempty"`
+
+	// Inputs is a JSON schema (type "object") for the params of a direct
+	// execution. Mission Control renders it as a form.
+	Inputs map[string]interface{} `json:"inputs,omitempty"`
 }
 
 // EnvVarSpec defines an environment variable required by the skill.
@@ -43,20 +59,35 @@ type ScriptSpec struct {
 	Runtime     string `json:"runtime"` // python, node, go, shell
 	Description string `json:"description,omitempty"`
 	Entrypoint  bool   `json:"entrypoint,omitempty"` // true if this is the main script
+
+	// Parameters is a JSON schema (type "object") for the script's input,
+	// used as the tool signature when the script is exposed as a tool.
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
 }
 
-// CronSpec defines a scheduled job that the skill auto-registers on install.
+// CronSpec defines a scheduled job the skill declares. Installing a skill
+// validates Expr and Timezone but does not register the job with the cron
+// service, so Timezone has no effect yet.
 type CronSpec struct {
-	Name    string `json:"name"`
-	Expr    string `json:"expr"`    // cron expression (e.g. "0 * * * *")
-	Task    string `json:"task"`    // message/prompt to execute
-	Enabled bool   `json:"enabled"` // whether to enable on install
+	Name     string `json:"name"`
+	Expr     string `json:"expr"`               // cron expression (e.g. "0 * * * *")
+	Task     string `json:"task"`               // message/prompt to execute
+	Enabled  bool   `json:"enabled"`            // whether the job is meant to run by default
+	Timezone string `json:"timezone,omitempty"` // IANA zone for Expr (e.g. "America/New_York"); local time if empty
 }
 
 // WebhookSpec defines a webhook endpoint the skill subscribes to.
 type WebhookSpec struct {
 	Path        string `json:"path"`        // URL path suffix (e.g. "/shopify")
 	Description string `json:"description"` // human-readable description
+
+	// Signature makes deliveries to Path verified: requests without a valid
+	// HMAC signature are refused. Without it the path accepts any POST.
+	Signature *WebhookSignature `json:"signature,omitempty"`
+
+	// Sync makes deliveries wait for the agent and answer with its reply,
+	// as Slack slash commands expect. Any path can opt in with ?sync=1.
+	Sync bool `json:"sync,omitempty"`
 }
 
 // LoadManifest reads and parses a manifest.json from the given skill directory.
@@ -103,6 +134,9 @@ func (m *SkillManifest) Validate() error {
 	} else if !namePattern.MatchString(m.Name) {
 		errs = append(errs, fmt.Sprintf("name %q must match pattern [a-zA-Z0-9]+(-[a-zA-Z0-9]+)*", m.Name))
 	}
+	if tools.IsReservedName(m.Name) {
+		errs = append(errs, fmt.Sprintf("name %q is reserved for a built-in tool", m.Name))
+	}
 	if len(m.Name) > MaxNameLength {
 		errs = append(errs, fmt.Sprintf("name must be at most %d characters", MaxNameLength))
 	}
@@ -119,12 +153,17 @@ func (m *SkillManifest) Validate() error {
 	for i, s := range m.Scripts {
 		if s.Path == "" {
 			errs = append(errs, fmt.Sprintf("scripts[%d].path is required", i))
+		} else if !filepath.IsLocal(s.Path) {
+			errs = append(errs, fmt.Sprintf("scripts[%d].path %q must be relative to the skill directory", i, s.Path))
 		}
 		if s.Runtime == "" {
 			errs = append(errs, fmt.Sprintf("scripts[%d].runtime is required", i))
 		} else if !validRuntimes[s.Runtime] {
 			errs = append(errs, fmt.Sprintf("scripts[%d].runtime %q must be one of: python, node, go, shell", i, s.Runtime))
 		}
+		for _, e := range validateParameters(s.Parameters) {
+			errs = append(errs, fmt.Sprintf("scripts[%d].parameters: %s", i, e))
+		}
 	}
 
 	for i, c := range m.Cron {
@@ -143,6 +182,22 @@ func (m *SkillManifest) Validate() error {
 		if w.Path == "" {
 			errs = append(errs, fmt.Sprintf("webhooks[%d].path is required", i))
 		}
+		if w.Signature != nil {
+			for _, e := range w.Signature.validate() {
+				errs = append(errs, fmt.Sprintf("webhooks[%d].signature.%s", i, e))
+			}
+		}
+	}
+
+	for _, e := range validateParameters(m.Inputs) {
+		errs = append(errs, "inputs: "+e)
+	}
+
+	if m.MaxIterations < 0 {
+		errs = append(errs, "max_iterations must not be negative")
+	}
+	if m.MaxDuration < 0 {
+		errs = append(errs, "max_duration must not be negative")
 	}
 
 	if len(errs) > 0 {
@@ -151,6 +206,34 @@ func (m *SkillManifest) Validate() error {
 	return nil
 }
 
+// MissingInputs returns the required inputs that params does not set.
+func (m *SkillManifest) MissingInputs(params map[string]interface{}) []string {
+	var missing []string
+	for _, name := range requiredNames(m.Inputs) {
+		if v, ok := params[name]; !ok || v == nil || v == "" {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// requiredNames returns the "required" list of a validated schema.
+func requiredNames(schema map[string]interface{}) []string {
+	switch r := schema["required"].(type) {
+	case []string:
+		return r
+	case []interface{}:
+		names := make([]string, 0, len(r))
+		for _, v := range r {
+			if name, ok := v.(string); ok {
+				names = append(names, name)
+			}
+		}
+		return names
+	}
+	return nil
+}
+
 // HasScripts returns true if the manifest defines any executable scripts.
 func (m *SkillManifest) HasScripts() bool {
 	return len(m.Scripts) > 0
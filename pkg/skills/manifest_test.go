@@ -55,6 +55,28 @@ func TestManifestValidation(t *testing.T) {
 			wantError:   true,
 			errContains: "name is required",
 		},
+		{
+			name:        "name reserved for built-in tool",
+			manifest:    SkillManifest{Name: "knowledge", Version: "1.0.0", Description: "test"},
+			wantError:   true,
+			errContains: "reserved for a built-in tool",
+		},
+		{
+			name: "webhook signature without secret",
+			manifest: SkillManifest{Name: "test", Version: "1.0.0", Description: "test", Webhooks: []WebhookSpec{
+				{Path: "/github", Signature: &WebhookSignature{Header: "X-Hub-Signature-256", Algorithm: "md5"}},
+			}},
+			wantError:   true,
+			errContains: "webhooks[0].signature.secret_env is required",
+		},
+		{
+			name: "webhook signature with unknown scheme",
+			manifest: SkillManifest{Name: "test", Version: "1.0.0", Description: "test", Webhooks: []WebhookSpec{
+				{Path: "/stripe", Signature: &WebhookSignature{SecretEnv: "S", Header: "Stripe-Signature", Scheme: "svix"}},
+			}},
+			wantError:   true,
+			errContains: `webhooks[0].signature.unsupported scheme "svix"`,
+		},
 		{
 			name:        "missing version",
 			manifest:    SkillManifest{Name: "test", Description: "test"},
@@ -102,6 +124,23 @@ func TestManifestValidation(t *testing.T) {
 			wantError:   true,
 			errContains: "must match pattern",
 		},
+		{
+			name: "negative max duration",
+			manifest: SkillManifest{
+				Name: "test", Version: "1.0.0", Description: "test", MaxDuration: -1,
+			},
+			wantError:   true,
+			errContains: "max_duration must not be negative",
+		},
+		{
+			name: "inputs not an object schema",
+			manifest: SkillManifest{
+				Name: "test", Version: "1.0.0", Description: "test",
+				Inputs: map[string]interface{}{"type": "string"},
+			},
+			wantError:   true,
+			errContains: `inputs: type must be "object"`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -220,6 +259,18 @@ func TestManifestHelpers(t *testing.T) {
 	assert.Len(t, required, 1)
 	assert.Equal(t, "KEY1", required[0].Name)
 
+	assert.Empty(t, m.MissingInputs(nil), "no inputs declared")
+	m.Inputs = map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"order_id": map[string]interface{}{"type": "string"},
+			"reason":   map[string]interface{}{"type": "string"},
+		},
+		"required": []interface{}{"order_id", "reason"},
+	}
+	assert.Equal(t, []string{"order_id", "reason"}, m.MissingInputs(nil))
+	assert.Equal(t, []string{"reason"}, m.MissingInputs(map[string]interface{}{"order_id": "A-1", "reason": ""}))
+
 	summary := m.CapabilitiesSummary()
 	assert.Contains(t, summary, "1 script(s)")
 	assert.Contains(t, summary, "1 cron job(s)")
@@ -0,0 +1,55 @@
+package skills
+
+import "io"
+
+// Install phases reported through InstallProgress.
+const (
+	PhaseDownload = "download"
+	PhaseExtract  = "extract"
+)
+
+// InstallProgress reports how far an install has got.
+type InstallProgress struct {
+	Phase           string
+	BytesDownloaded int64
+	BytesTotal      int64 // -1 when the server did not send a length
+	FilesExtracted  int
+}
+
+// ProgressFunc receives install progress. It is called synchronously and
+// often, so it should return quickly.
+type ProgressFunc func(InstallProgress)
+
+// SetProgress registers fn to receive progress for subsequent installs.
+// A nil fn disables reporting.
+func (si *SkillInstaller) SetProgress(fn ProgressFunc) {
+	si.progress = fn
+}
+
+// onFile returns a callback for extraction progress, or nil if no one is
+// listening.
+func (si *SkillInstaller) onFile() func(int) {
+	if si.progress == nil {
+		return nil
+	}
+	return func(n int) {
+		si.progress(InstallProgress{Phase: PhaseExtract, FilesExtracted: n})
+	}
+}
+
+// progressReader reports the bytes read through it.
+type progressReader struct {
+	r      io.Reader
+	read   int64
+	total  int64
+	report ProgressFunc
+}
+
+func (pr *progressReader) Read(p []byte) (int, error) {
+	n, err := pr.r.Read(p)
+	if n > 0 {
+		pr.read += int64(n)
+		pr.report(InstallProgress{Phase: PhaseDownload, BytesDownloaded: pr.read, BytesTotal: pr.total})
+	}
+	return n, err
+}
@@ -0,0 +1,203 @@
+package skills
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Sterlites/RDxClaw/pkg/tools"
+)
+
+// DefaultScriptTimeout bounds a single run of a script exposed as a tool.
+const DefaultScriptTimeout = 60 * time.Second
+
+// schemaTypes are the JSON schema types a script parameter may declare.
+var schemaTypes = map[string]bool{
+	"string": true, "number": true, "integer": true, "boolean": true,
+	"array": true, "object": true, "null": true,
+}
+
+var toolNameInvalid = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// runtimeCommands maps a script runtime to the command that runs it.
+var runtimeCommands = map[string][]string{
+	"python": {"python3"},
+	"node":   {"node"},
+	"go":     {"go", "run"},
+	"shell":  {"sh"},
+}
+
+// ScriptTool exposes a manifest script to the agent as a tool. The call
+// arguments are passed to the script as a JSON object on stdin and its
+// output is returned to the model.
+type ScriptTool struct {
+	skillDir string
+	skill    string
+	fallback string // skill description, used when the script has none
+	spec     ScriptSpec
+	timeout  time.Duration
+}
+
+// NewScriptTool builds the tool for one script of an installed skill.
+func NewScriptTool(skillDir string, manifest *SkillManifest, spec ScriptSpec) *ScriptTool {
+	return &ScriptTool{
+		skillDir: skillDir,
+		skill:    manifest.Name,
+		fallback: manifest.Description,
+		spec:     spec,
+		timeout:  DefaultScriptTimeout,
+	}
+}
+
+// ScriptTools returns a tool for every script in the manifest.
+func ScriptTools(skillDir string, manifest *SkillManifest) []*ScriptTool {
+	result := make([]*ScriptTool, 0, len(manifest.Scripts))
+	for _, spec := range manifest.Scripts {
+		result = append(result, NewScriptTool(skillDir, manifest, spec))
+	}
+	return result
+}
+
+// ScriptTools returns a tool for every script of every loaded skill.
+func (sl *SkillsLoader) ScriptTools() []*ScriptTool {
+	var result []*ScriptTool
+	for _, info := range sl.ListSkills() {
+		if info.Manifest == nil {
+			continue
+		}
+		result = append(result, ScriptTools(filepath.Dir(info.Path), info.Manifest)...)
+	}
+	return result
+}
+
+// Name is the skill name joined with the script's base name, e.g.
+// "shopify-refund_logic" for scripts/logic.py.
+func (t *ScriptTool) Name() string {
+	base := filepath.Base(t.spec.Path)
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+	return t.skill + "_" + toolNameInvalid.ReplaceAllString(base, "_")
+}
+
+func (t *ScriptTool) Description() string {
+	if t.spec.Description != "" {
+		return t.spec.Description
+	}
+	return t.fallback
+}
+
+// Parameters returns the script's declared schema, or an empty object
+// schema when it has none.
+func (t *ScriptTool) Parameters() map[string]interface{} {
+	if len(t.spec.Parameters) > 0 {
+		return t.spec.Parameters
+	}
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{},
+	}
+}
+
+func (t *ScriptTool) Execute(ctx context.Context, args map[string]interface{}) *tools.ToolResult {
+	// Manifests are only validated on install, so a hand-edited one could
+	// point outside the skill directory
+	if !filepath.IsLocal(t.spec.Path) {
+		return tools.ErrorResult(fmt.Sprintf("script path %q is outside the skill directory", t.spec.Path))
+	}
+	command, ok := runtimeCommands[t.spec.Runtime]
+	if !ok {
+		return tools.ErrorResult(fmt.Sprintf("unsupported script runtime %q", t.spec.Runtime))
+	}
+	input, err := json.Marshal(args)
+	if err != nil {
+		return tools.ErrorResult(fmt.Sprintf("failed to encode arguments: %v", err))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	cmdArgs := append(append([]string{}, command[1:]...), t.spec.Path)
+	cmd := exec.CommandContext(ctx, command[0], cmdArgs...)
+	cmd.Dir = t.skillDir
+	cmd.Stdin = bytes.NewReader(input)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return tools.ErrorResult(fmt.Sprintf("script timed out after %v", t.timeout))
+		}
+		return tools.ErrorResult(fmt.Sprintf("script failed: %v\n%s", err, out.String())).WithError(err)
+	}
+	return tools.NewToolResult(out.String())
+}
+
+// validateParameters checks a script's parameters schema: it must describe
+// an object, every property must declare a known type, and every required
+// name must be a declared property. A nil schema is valid.
+func validateParameters(schema map[string]interface{}) []string {
+	if schema == nil {
+		return nil
+	}
+
+	var errs []string
+	if typ, _ := schema["type"].(string); typ != "object" {
+		errs = append(errs, `type must be "object"`)
+	}
+
+	properties := map[string]interface{}{}
+	if raw, ok := schema["properties"]; ok {
+		props, ok := raw.(map[string]interface{})
+		if !ok {
+			return append(errs, "properties must be an object")
+		}
+		properties = props
+	}
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prop, ok := properties[name].(map[string]interface{})
+		if !ok {
+			errs = append(errs, fmt.Sprintf("property %q must be an object", name))
+			continue
+		}
+		if typ, _ := prop["type"].(string); !schemaTypes[typ] {
+			errs = append(errs, fmt.Sprintf("property %q has invalid type %v", name, prop["type"]))
+		}
+	}
+
+	if raw, ok := schema["required"]; ok {
+		var required []string
+		switch r := raw.(type) {
+		case []string:
+			required = r
+		case []interface{}:
+			for _, v := range r {
+				name, ok := v.(string)
+				if !ok {
+					return append(errs, "required must be an array of property names")
+				}
+				required = append(required, name)
+			}
+		default:
+			return append(errs, "required must be an array of property names")
+		}
+		for _, name := range required {
+			if _, ok := properties[name]; !ok {
+				errs = append(errs, fmt.Sprintf("required property %q is not declared", name))
+			}
+		}
+	}
+
+	return errs
+}
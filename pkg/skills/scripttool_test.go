@@ -0,0 +1,150 @@
+package skills
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Sterlites/RDxClaw/pkg/tools"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScriptToolDefinition(t *testing.T) {
+	manifestJSON := `{
+		"name": "shopify-refund",
+		"version": "1.0.0",
+		"description": "Shopify refund manager",
+		"scripts": [
+			{
+				"path": "scripts/issue-refund.py",
+				"runtime": "python",
+				"description": "Refund an order",
+				"parameters": {
+					"type": "object",
+					"properties": {
+						"order_id": {"type": "string", "description": "Order to refund"},
+						"amount": {"type": "number"}
+					},
+					"required": ["order_id"]
+				}
+			},
+			{"path": "scripts/sync.sh", "runtime": "shell"}
+		]
+	}`
+	var manifest SkillManifest
+	require.NoError(t, json.Unmarshal([]byte(manifestJSON), &manifest))
+	require.NoError(t, manifest.Validate())
+
+	scriptTools := ScriptTools("/skills/shopify-refund", &manifest)
+	require.Len(t, scriptTools, 2)
+
+	def := tools.ToolToSchema(scriptTools[0])["function"].(map[string]interface{})
+	assert.Equal(t, "shopify-refund_issue-refund", def["name"])
+	assert.Equal(t, "Refund an order", def["description"])
+	params := def["parameters"].(map[string]interface{})
+	assert.Equal(t, "object", params["type"])
+	assert.Contains(t, params["properties"], "order_id")
+	assert.Equal(t, []interface{}{"order_id"}, params["required"])
+
+	// Without its own description and schema the script falls back to the
+	// skill description and an empty object schema.
+	plain := scriptTools[1]
+	assert.Equal(t, "shopify-refund_sync", plain.Name())
+	assert.Equal(t, "Shopify refund manager", plain.Description())
+	assert.Equal(t, "object", plain.Parameters()["type"])
+}
+
+func TestScriptParametersValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		params      map[string]interface{}
+		errContains string
+	}{
+		{
+			name:        "not an object schema",
+			params:      map[string]interface{}{"type": "string"},
+			errContains: `type must be "object"`,
+		},
+		{
+			name: "property without a type",
+			params: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{"id": map[string]interface{}{"description": "x"}},
+			},
+			errContains: `property "id" has invalid type`,
+		},
+		{
+			name: "required property not declared",
+			params: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{"id": map[string]interface{}{"type": "string"}},
+				"required":   []string{"name"},
+			},
+			errContains: `required property "name" is not declared`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := SkillManifest{
+				Name:        "test-skill",
+				Version:     "1.0.0",
+				Description: "test",
+				Scripts:     []ScriptSpec{{Path: "run.sh", Runtime: "shell", Parameters: tt.params}},
+			}
+			err := m.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "scripts[0].parameters: "+tt.errContains)
+		})
+	}
+}
+
+func TestScriptToolExecute(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "echo.sh"), []byte("cat\n"), 0644))
+
+	manifest := &SkillManifest{Name: "echo", Description: "Echo input"}
+	tool := NewScriptTool(dir, manifest, ScriptSpec{Path: "echo.sh", Runtime: "shell"})
+
+	result := tool.Execute(context.Background(), map[string]interface{}{"msg": "hi"})
+	require.False(t, result.IsError, result.ForLLM)
+	assert.JSONEq(t, `{"msg":"hi"}`, result.ForLLM)
+}
+
+func TestScriptToolPathOutsideSkill(t *testing.T) {
+	for _, path := range []string{"../escape.sh", "/bin/sh", "scripts/../../escape.sh"} {
+		manifest := &SkillManifest{
+			Name: "echo", Version: "1.0.0", Description: "Echo input",
+			Scripts: []ScriptSpec{{Path: path, Runtime: "shell"}},
+		}
+		err := manifest.Validate()
+		require.Error(t, err, path)
+		assert.Contains(t, err.Error(), "must be relative to the skill directory")
+
+		result := NewScriptTool(t.TempDir(), manifest, manifest.Scripts[0]).Execute(context.Background(), nil)
+		assert.True(t, result.IsError, path)
+		assert.Contains(t, result.ForLLM, "outside the skill directory")
+	}
+}
+
+func TestLoaderScriptTools(t *testing.T) {
+	workspace := t.TempDir()
+	skillDir := filepath.Join(workspace, "skills", "echo")
+	require.NoError(t, os.MkdirAll(skillDir, 0755))
+	require.NoError(t, SaveManifest(skillDir, &SkillManifest{
+		Name: "echo", Version: "1.0.0", Description: "Echo input",
+		Scripts: []ScriptSpec{{Path: "echo.sh", Runtime: "shell"}},
+	}))
+	require.NoError(t, os.WriteFile(filepath.Join(skillDir, "echo.sh"), []byte("cat\n"), 0644))
+
+	found := NewSkillsLoader(workspace, "", "").ScriptTools()
+	require.Len(t, found, 1)
+	assert.Equal(t, "echo_echo", found[0].Name())
+
+	result := found[0].Execute(context.Background(), map[string]interface{}{"msg": "hi"})
+	require.False(t, result.IsError, result.ForLLM)
+	assert.JSONEq(t, `{"msg":"hi"}`, result.ForLLM)
+}
@@ -0,0 +1,168 @@
+package skills
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SignatureFile holds a skill's signature, beside its manifest.json.
+const SignatureFile = "manifest.sig"
+
+// ManifestSignature is a publisher's Ed25519 signature over a skill. It
+// covers the SHA-256 hash of every file in the skill, manifest.json
+// included, so neither the manifest nor a script can be changed without
+// invalidating it. The manifest is hashed as it is on disk, so fields this
+// version does not know are covered too.
+type ManifestSignature struct {
+	Publisher string            `json:"publisher"`
+	Files     map[string]string `json:"files"` // slash-separated path -> hex SHA-256
+	Value     string            `json:"value"` // base64 signature
+}
+
+// ParsePublisherKeys decodes publisher name -> base64 Ed25519 public key
+// pairs, as found in the skills.trusted_publishers config.
+func ParsePublisherKeys(encoded map[string]string) (map[string]ed25519.PublicKey, error) {
+	keys := make(map[string]ed25519.PublicKey, len(encoded))
+	for publisher, s := range encoded {
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+		if err != nil || len(raw) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid public key for publisher %q: want base64 of %d bytes", publisher, ed25519.PublicKeySize)
+		}
+		keys[publisher] = ed25519.PublicKey(raw)
+	}
+	return keys, nil
+}
+
+// SignManifest hashes the files in skillDir, manifest.json included, signs
+// them as publisher and writes the signature to SignatureFile.
+func SignManifest(skillDir, publisher string, key ed25519.PrivateKey) error {
+	if _, err := os.Stat(filepath.Join(skillDir, "manifest.json")); err != nil {
+		return fmt.Errorf("%s has no manifest.json to sign", skillDir)
+	}
+
+	files, err := hashSkillFiles(skillDir)
+	if err != nil {
+		return err
+	}
+	sig := &ManifestSignature{Publisher: publisher, Files: files}
+	sig.Value = base64.StdEncoding.EncodeToString(ed25519.Sign(key, sig.payload()))
+
+	data, err := json.MarshalIndent(sig, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal signature: %w", err)
+	}
+	return os.WriteFile(filepath.Join(skillDir, SignatureFile), data, 0644)
+}
+
+// VerifyManifest checks that the skill in skillDir carries a valid signature
+// from one of the trusted publishers and returns the publisher's name.
+// It returns ErrUnsignedSkill, ErrUntrustedPublisher or ErrInvalidSignature.
+func VerifyManifest(skillDir string, trusted map[string]ed25519.PublicKey) (string, error) {
+	data, err := os.ReadFile(filepath.Join(skillDir, SignatureFile))
+	if os.IsNotExist(err) {
+		return "", ErrUnsignedSkill
+	}
+	if err != nil {
+		return "", err
+	}
+	var sig ManifestSignature
+	if err := json.Unmarshal(data, &sig); err != nil {
+		return "", fmt.Errorf("%w: malformed %s", ErrInvalidSignature, SignatureFile)
+	}
+
+	key, ok := trusted[sig.Publisher]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUntrustedPublisher, sig.Publisher)
+	}
+
+	value, err := base64.StdEncoding.DecodeString(sig.Value)
+	if err != nil {
+		return "", fmt.Errorf("%w: malformed signature", ErrInvalidSignature)
+	}
+	if !ed25519.Verify(key, sig.payload(), value) {
+		return "", fmt.Errorf("%w: file hashes do not match signature", ErrInvalidSignature)
+	}
+
+	files, err := hashSkillFiles(skillDir)
+	if err != nil {
+		return "", err
+	}
+	for path, hash := range files {
+		want, ok := sig.Files[path]
+		if !ok {
+			return "", fmt.Errorf("%w: unsigned file %s", ErrInvalidSignature, path)
+		}
+		if want != hash {
+			return "", fmt.Errorf("%w: %s was modified", ErrInvalidSignature, path)
+		}
+	}
+	for path := range sig.Files {
+		if _, ok := files[path]; !ok {
+			return "", fmt.Errorf("%w: missing file %s", ErrInvalidSignature, path)
+		}
+	}
+
+	return sig.Publisher, nil
+}
+
+// payload returns the bytes that are signed: the publisher, then one
+// "<hash>  <path>" line per file in path order, as sha256sum prints them.
+func (sig *ManifestSignature) payload() []byte {
+	paths := make([]string, 0, len(sig.Files))
+	for path := range sig.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var b strings.Builder
+	b.WriteString("rdxclaw skill signature v1\n")
+	b.WriteString("publisher " + sig.Publisher + "\n")
+	for _, path := range paths {
+		b.WriteString(sig.Files[path] + "  " + path + "\n")
+	}
+	return []byte(b.String())
+}
+
+// hashSkillFiles returns the SHA-256 of every regular file in skillDir
+// except SignatureFile, keyed by slash-separated relative path.
+func hashSkillFiles(skillDir string) (map[string]string, error) {
+	files := make(map[string]string)
+	err := filepath.WalkDir(skillDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(skillDir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == SignatureFile {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		h := sha256.New()
+		if _, err := io.Copy(h, f); err != nil {
+			return err
+		}
+		files[rel] = hex.EncodeToString(h.Sum(nil))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash skill files: %w", err)
+	}
+	return files, nil
+}
@@ -0,0 +1,163 @@
+package skills
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultWatchDebounce is how long the watcher waits for edits to settle
+// before reporting a change. Editors often write a file several times on save.
+const DefaultWatchDebounce = 300 * time.Millisecond
+
+// Watcher watches skill directories and calls onChange once a burst of file
+// events has settled. fsnotify is not recursive, so every existing skill
+// directory is watched individually and new ones are added as they appear.
+type Watcher struct {
+	fsw      *fsnotify.Watcher
+	debounce time.Duration
+	onChange func()
+	done     chan struct{}
+	wg       sync.WaitGroup
+	once     sync.Once
+}
+
+// NewWatcher starts watching roots and the skill and category directories
+// beneath them.
+// Roots that do not exist are skipped.
+func NewWatcher(roots []string, debounce time.Duration, onChange func()) (*Watcher, error) {
+	if debounce <= 0 {
+		debounce = DefaultWatchDebounce
+	}
+
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watcher{
+		fsw:      fsw,
+		debounce: debounce,
+		onChange: onChange,
+		done:     make(chan struct{}),
+	}
+
+	for _, root := range roots {
+		if root == "" {
+			continue
+		}
+		if err := w.addTree(root); err != nil && !errors.Is(err, os.ErrNotExist) {
+			fsw.Close()
+			return nil, err
+		}
+	}
+
+	w.wg.Add(1)
+	go w.run()
+	return w, nil
+}
+
+// addTree watches dir and the directories beneath it, down to the deepest
+// level a skill may be nested at.
+func (w *Watcher) addTree(dir string) error {
+	return w.addDirs(dir, maxSkillDepth)
+}
+
+func (w *Watcher) addDirs(dir string, depth int) error {
+	if err := w.fsw.Add(dir); err != nil {
+		return err
+	}
+	if depth == 0 {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() && !skipWatchDir(e.Name()) {
+			if err := w.addDirs(filepath.Join(dir, e.Name()), depth-1); err != nil {
+				slog.Warn("skills watcher: cannot watch directory", "dir", e.Name(), "error", err)
+			}
+		}
+	}
+	return nil
+}
+
+// skipWatchDir reports whether a directory is left unwatched: hidden ones,
+// such as .git, and node_modules. They can hold thousands of directories,
+// enough to exhaust the inotify watch limit, and never contain skills.
+func skipWatchDir(name string) bool {
+	return name == "node_modules" || strings.HasPrefix(name, ".")
+}
+
+func (w *Watcher) run() {
+	defer w.wg.Done()
+
+	var timer *time.Timer
+	var fire <-chan time.Time
+
+	for {
+		select {
+		case <-w.done:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+
+		case event, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+			if skipWatchDir(filepath.Base(event.Name)) {
+				continue
+			}
+			if event.Has(fsnotify.Create) {
+				if fi, err := os.Stat(event.Name); err == nil && fi.IsDir() {
+					w.fsw.Add(event.Name)
+				}
+			}
+			if timer == nil {
+				timer = time.NewTimer(w.debounce)
+			} else {
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+				timer.Reset(w.debounce)
+			}
+			fire = timer.C
+
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return
+			}
+			slog.Warn("skills watcher error", "error", err)
+
+		case <-fire:
+			fire = nil
+			if w.onChange != nil {
+				w.onChange()
+			}
+		}
+	}
+}
+
+// Close stops watching. It is safe to call more than once.
+func (w *Watcher) Close() error {
+	var err error
+	w.once.Do(func() {
+		close(w.done)
+		err = w.fsw.Close()
+		w.wg.Wait()
+	})
+	return err
+}
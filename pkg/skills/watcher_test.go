@@ -0,0 +1,96 @@
+package skills
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSkill(t testing.TB, root, name, description string) {
+	t.Helper()
+	dir := filepath.Join(root, name)
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	content := "---\nname: " + name + "\ndescription: " + description + "\n---\n\n# " + name + "\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte(content), 0644))
+}
+
+func TestSkillsLoaderWatchReloadsSummary(t *testing.T) {
+	workspace := t.TempDir()
+	skillsDir := filepath.Join(workspace, "skills")
+	writeSkill(t, skillsDir, "weather", "old description")
+
+	sl := NewSkillsLoader(workspace, "", "")
+	require.NoError(t, sl.Watch(20*time.Millisecond))
+	defer sl.StopWatching()
+
+	assert.Contains(t, sl.BuildSkillsSummary(), "old description")
+
+	writeSkill(t, skillsDir, "weather", "new description")
+	writeSkill(t, skillsDir, "notes", "a brand new skill")
+
+	assert.Eventually(t, func() bool {
+		summary := sl.BuildSkillsSummary()
+		return strings.Contains(summary, "new description") && strings.Contains(summary, "a brand new skill")
+	}, 2*time.Second, 20*time.Millisecond)
+}
+
+func TestWatcherDebouncesEvents(t *testing.T) {
+	root := t.TempDir()
+	changes := make(chan struct{}, 10)
+
+	w, err := NewWatcher([]string{root, filepath.Join(root, "missing")}, 50*time.Millisecond, func() {
+		changes <- struct{}{}
+	})
+	require.NoError(t, err)
+	defer w.Close()
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, os.WriteFile(filepath.Join(root, "file.md"), []byte(strings.Repeat("x", i)), 0644))
+	}
+
+	select {
+	case <-changes:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected a change notification")
+	}
+	select {
+	case <-changes:
+		t.Fatal("expected rapid edits to be coalesced into one notification")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestWatcherSkipsHiddenAndNodeModules(t *testing.T) {
+	root := t.TempDir()
+	skill := filepath.Join(root, "weather")
+	for _, dir := range []string{"node_modules/left-pad", ".git/objects", "scripts"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(skill, dir), 0755))
+	}
+
+	changes := make(chan struct{}, 10)
+	w, err := NewWatcher([]string{root}, 20*time.Millisecond, func() {
+		changes <- struct{}{}
+	})
+	require.NoError(t, err)
+	defer w.Close()
+
+	watched := w.fsw.WatchList()
+	assert.Contains(t, watched, filepath.Join(skill, "scripts"))
+	for _, path := range watched {
+		assert.NotContains(t, path, "node_modules")
+		assert.NotContains(t, path, ".git")
+	}
+
+	// A dependency install inside the skill does not reload skills
+	require.NoError(t, os.MkdirAll(filepath.Join(skill, "node_modules", "lodash"), 0755))
+	select {
+	case <-changes:
+		t.Fatal("changes under node_modules should be ignored")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
@@ -0,0 +1,206 @@
+package skills
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrWebhookUnsigned is returned for a delivery to a verified webhook
+	// path that carries no signature header.
+	ErrWebhookUnsigned = errors.New("webhook signature missing")
+	// ErrWebhookSignature is returned when a delivery's signature does not
+	// match its body.
+	ErrWebhookSignature = errors.New("webhook signature is invalid")
+	// ErrWebhookSecret is returned when a verified webhook's secret is not
+	// set, so no delivery can be verified.
+	ErrWebhookSecret = errors.New("webhook secret is not configured")
+	// ErrWebhookExpired is returned when a timestamped delivery was signed
+	// outside the tolerance window, such as a replayed one.
+	ErrWebhookExpired = errors.New("webhook signature timestamp is outside the tolerance window")
+)
+
+// DefaultWebhookTolerance is how far a timestamped signature may be from
+// now, as Stripe's own libraries allow.
+const DefaultWebhookTolerance = 5 * time.Minute
+
+// WebhookSignature describes how a webhook provider signs its deliveries:
+// an HMAC of the raw request body, keyed with a shared secret, sent in a
+// header. For example GitHub uses header "X-Hub-Signature-256" with prefix
+// "sha256=", and Shopify "X-Shopify-Hmac-Sha256" with base64 encoding.
+//
+// Scheme "stripe" is for providers that sign a timestamp with the body, as
+// Stripe does in "Stripe-Signature": the header reads "t=<unix>,v1=<hex>",
+// possibly with several v1 entries while a secret is rolled, and the HMAC
+// covers "<t>.<body>". Deliveries signed more than Tolerance seconds from
+// now are refused, so a captured one cannot be replayed later.
+type WebhookSignature struct {
+	// SecretEnv names the environment variable holding the shared secret,
+	// so the secret never lives in the manifest.
+	SecretEnv string `json:"secret_env"`
+	Header    string `json:"header"`              // header carrying the signature
+	Scheme    string `json:"scheme,omitempty"`    // hmac (default) or stripe
+	Algorithm string `json:"algorithm,omitempty"` // sha256 (default), sha1 or sha512
+	Encoding  string `json:"encoding,omitempty"`  // hex (default) or base64; stripe is always hex
+	Prefix    string `json:"prefix,omitempty"`    // stripped from the header value, e.g. "sha256="
+	Tolerance int    `json:"tolerance,omitempty"` // stripe only: seconds, default 300
+}
+
+func (ws *WebhookSignature) validate() []string {
+	var errs []string
+	if ws.SecretEnv == "" {
+		errs = append(errs, "secret_env is required")
+	}
+	if ws.Header == "" {
+		errs = append(errs, "header is required")
+	}
+	if ws.hash() == nil {
+		errs = append(errs, fmt.Sprintf("unsupported algorithm %q (supported: sha256, sha1, sha512)", ws.Algorithm))
+	}
+	if ws.Encoding != "" && ws.Encoding != "hex" && ws.Encoding != "base64" {
+		errs = append(errs, fmt.Sprintf("unsupported encoding %q (supported: hex, base64)", ws.Encoding))
+	}
+	switch ws.Scheme {
+	case "", "hmac":
+		if ws.Tolerance != 0 {
+			errs = append(errs, "tolerance only applies to the stripe scheme")
+		}
+	case "stripe":
+		if ws.Encoding == "base64" || ws.Prefix != "" {
+			errs = append(errs, "the stripe scheme takes no encoding or prefix")
+		}
+		if ws.Tolerance < 0 {
+			errs = append(errs, "tolerance must not be negative")
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("unsupported scheme %q (supported: hmac, stripe)", ws.Scheme))
+	}
+	return errs
+}
+
+func (ws *WebhookSignature) tolerance() time.Duration {
+	if ws.Tolerance > 0 {
+		return time.Duration(ws.Tolerance) * time.Second
+	}
+	return DefaultWebhookTolerance
+}
+
+func (ws *WebhookSignature) hash() func() hash.Hash {
+	switch strings.ToLower(ws.Algorithm) {
+	case "", "sha256":
+		return sha256.New
+	case "sha1":
+		return sha1.New
+	case "sha512":
+		return sha512.New
+	}
+	return nil
+}
+
+// Verify checks the signature in header against body.
+func (ws *WebhookSignature) Verify(header http.Header, body []byte) error {
+	secret := os.Getenv(ws.SecretEnv)
+	if secret == "" {
+		return fmt.Errorf("%w: %s is not set", ErrWebhookSecret, ws.SecretEnv)
+	}
+	newHash := ws.hash()
+	if newHash == nil {
+		return fmt.Errorf("unsupported webhook signature algorithm %q", ws.Algorithm)
+	}
+
+	value := strings.TrimSpace(header.Get(ws.Header))
+	if value == "" {
+		return fmt.Errorf("%w: %s header", ErrWebhookUnsigned, ws.Header)
+	}
+	if ws.Scheme == "stripe" {
+		return ws.verifyTimestamped(value, body, newHash, []byte(secret), time.Now())
+	}
+	value = strings.TrimPrefix(value, ws.Prefix)
+
+	var got []byte
+	var err error
+	if ws.Encoding == "base64" {
+		got, err = base64.StdEncoding.DecodeString(value)
+	} else {
+		got, err = hex.DecodeString(value)
+	}
+	if err != nil {
+		return ErrWebhookSignature
+	}
+
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return ErrWebhookSignature
+	}
+	return nil
+}
+
+// verifyTimestamped checks a "t=<unix>,v1=<hex>" header: one of its v1
+// signatures must be the HMAC of "<t>.<body>", and t must be within the
+// tolerance of now.
+func (ws *WebhookSignature) verifyTimestamped(value string, body []byte, newHash func() hash.Hash, secret []byte, now time.Time) error {
+	var timestamp string
+	var signatures [][]byte
+	for _, part := range strings.Split(value, ",") {
+		key, val, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = val
+		case "v1":
+			if sig, err := hex.DecodeString(val); err == nil {
+				signatures = append(signatures, sig)
+			}
+		}
+	}
+	if timestamp == "" || len(signatures) == 0 {
+		return ErrWebhookSignature
+	}
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrWebhookSignature
+	}
+
+	mac := hmac.New(newHash, secret)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	want := mac.Sum(nil)
+	for _, sig := range signatures {
+		if hmac.Equal(sig, want) {
+			if age := now.Sub(time.Unix(unix, 0)); age > ws.tolerance() || age < -ws.tolerance() {
+				return ErrWebhookExpired
+			}
+			return nil
+		}
+	}
+	return ErrWebhookSignature
+}
+
+// Webhook returns the webhook spec a loaded skill declares for path, such
+// as "/shopify".
+func (sl *SkillsLoader) Webhook(path string) (WebhookSpec, bool) {
+	path = "/" + strings.Trim(path, "/")
+	for _, s := range sl.ListSkills() {
+		if s.Manifest == nil {
+			continue
+		}
+		for _, w := range s.Manifest.Webhooks {
+			if "/"+strings.Trim(w.Path, "/") == path {
+				return w, true
+			}
+		}
+	}
+	return WebhookSpec{}, false
+}
@@ -0,0 +1,94 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Limits on long-term memory. Every entry is injected into the system
+// prompt on each turn, so memory must stay small.
+const (
+	MaxMemoryEntries  = 50
+	MaxMemoryKeyLen   = 64
+	MaxMemoryValueLen = 500
+)
+
+var (
+	// ErrMemoryFull is returned when adding a new key would exceed MaxMemoryEntries.
+	ErrMemoryFull = errors.New("memory is full")
+	// ErrInvalidMemory is returned for an empty or oversized key or value.
+	ErrInvalidMemory = errors.New("invalid memory entry")
+)
+
+// SetMemory stores a long-term fact under key, replacing any previous
+// value, and saves the state.
+func (sm *Manager) SetMemory(key, value string) error {
+	key = strings.TrimSpace(key)
+	value = strings.TrimSpace(value)
+	switch {
+	case key == "" || value == "":
+		return fmt.Errorf("%w: key and value are required", ErrInvalidMemory)
+	case len(key) > MaxMemoryKeyLen:
+		return fmt.Errorf("%w: key longer than %d bytes", ErrInvalidMemory, MaxMemoryKeyLen)
+	case len(value) > MaxMemoryValueLen:
+		return fmt.Errorf("%w: value longer than %d bytes", ErrInvalidMemory, MaxMemoryValueLen)
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if _, exists := sm.state.Memory[key]; !exists && len(sm.state.Memory) >= MaxMemoryEntries {
+		return fmt.Errorf("%w: %d entries; delete one first", ErrMemoryFull, MaxMemoryEntries)
+	}
+	if sm.state.Memory == nil {
+		sm.state.Memory = make(map[string]string)
+	}
+	sm.state.Memory[key] = value
+	sm.state.Timestamp = time.Now()
+
+	if err := sm.saveAtomic(); err != nil {
+		return fmt.Errorf("failed to save state atomically: %w", err)
+	}
+	return nil
+}
+
+// GetMemory returns the fact stored under key.
+func (sm *Manager) GetMemory(key string) (string, bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	value, ok := sm.state.Memory[strings.TrimSpace(key)]
+	return value, ok
+}
+
+// DeleteMemory removes the fact stored under key and saves the state. It
+// reports whether the key existed.
+func (sm *Manager) DeleteMemory(key string) (bool, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	key = strings.TrimSpace(key)
+	if _, ok := sm.state.Memory[key]; !ok {
+		return false, nil
+	}
+	delete(sm.state.Memory, key)
+	sm.state.Timestamp = time.Now()
+
+	if err := sm.saveAtomic(); err != nil {
+		return true, fmt.Errorf("failed to save state atomically: %w", err)
+	}
+	return true, nil
+}
+
+// ListMemory returns a copy of all stored facts.
+func (sm *Manager) ListMemory() map[string]string {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	facts := make(map[string]string, len(sm.state.Memory))
+	for k, v := range sm.state.Memory {
+		facts[k] = v
+	}
+	return facts
+}
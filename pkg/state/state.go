@@ -19,6 +19,10 @@ type State struct {
 	// LastChatID is the last chat ID used for communication
 	LastChatID string `json:"last_chat_id,omitempty"`
 
+	// Memory holds long-term facts the agent was asked to remember. Unlike
+	// session history it is shared by every session.
+	Memory map[string]string `json:"memory,omitempty"`
+
 	// Timestamp is the last time this state was updated
 	Timestamp time.Time `json:"timestamp"`
 }
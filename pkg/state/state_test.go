@@ -2,9 +2,11 @@ package state
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -214,3 +216,54 @@ func TestNewManager_EmptyWorkspace(t *testing.T) {
 		t.Error("Expected zero timestamp for new state")
 	}
 }
+
+func TestMemoryPersistence(t *testing.T) {
+	tmpDir := t.TempDir()
+	sm := NewManager(tmpDir)
+
+	if err := sm.SetMemory("user_timezone", "Europe/Berlin"); err != nil {
+		t.Fatalf("SetMemory failed: %v", err)
+	}
+	if err := sm.SetMemory("pet", "cat"); err != nil {
+		t.Fatalf("SetMemory failed: %v", err)
+	}
+	if existed, err := sm.DeleteMemory("pet"); err != nil || !existed {
+		t.Fatalf("DeleteMemory = %v, %v; want true, nil", existed, err)
+	}
+	if existed, _ := sm.DeleteMemory("pet"); existed {
+		t.Error("Expected second delete to report a missing key")
+	}
+
+	// A fresh manager must see the facts written by the first one
+	sm2 := NewManager(tmpDir)
+	if v, ok := sm2.GetMemory("user_timezone"); !ok || v != "Europe/Berlin" {
+		t.Errorf("Expected persisted fact 'Europe/Berlin', got %q (found=%v)", v, ok)
+	}
+	if facts := sm2.ListMemory(); len(facts) != 1 {
+		t.Errorf("Expected 1 remembered fact, got %d", len(facts))
+	}
+}
+
+func TestMemoryBounds(t *testing.T) {
+	sm := NewManager(t.TempDir())
+
+	if err := sm.SetMemory("", "value"); !errors.Is(err, ErrInvalidMemory) {
+		t.Errorf("Expected ErrInvalidMemory for empty key, got %v", err)
+	}
+	if err := sm.SetMemory("key", strings.Repeat("x", MaxMemoryValueLen+1)); !errors.Is(err, ErrInvalidMemory) {
+		t.Errorf("Expected ErrInvalidMemory for oversized value, got %v", err)
+	}
+
+	for i := 0; i < MaxMemoryEntries; i++ {
+		if err := sm.SetMemory(fmt.Sprintf("key-%d", i), "value"); err != nil {
+			t.Fatalf("SetMemory(%d) failed: %v", i, err)
+		}
+	}
+	if err := sm.SetMemory("one-too-many", "value"); !errors.Is(err, ErrMemoryFull) {
+		t.Errorf("Expected ErrMemoryFull, got %v", err)
+	}
+	// Overwriting an existing key is still allowed when full
+	if err := sm.SetMemory("key-0", "updated"); err != nil {
+		t.Errorf("Expected overwrite to succeed when full, got %v", err)
+	}
+}
@@ -0,0 +1,16 @@
+package swarm
+
+import "errors"
+
+var (
+	// ErrAgentNotFound is returned when no agent exists with the given ID.
+	ErrAgentNotFound = errors.New("agent not found")
+	// ErrAgentNotRunning is returned when an operation requires a running agent.
+	ErrAgentNotRunning = errors.New("agent is not running")
+	// ErrScratchFull is returned when a scratchpad has no room for a new key.
+	ErrScratchFull = errors.New("scratchpad is full")
+	// ErrInvalidScratch is returned for an empty or oversized scratchpad entry.
+	ErrInvalidScratch = errors.New("invalid scratchpad entry")
+	// ErrNoTaskScope is returned when the scratchpad is used outside any task.
+	ErrNoTaskScope = errors.New("no swarm task in context")
+)
@@ -2,41 +2,75 @@ package swarm
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/Sterlites/RDxClaw/pkg/bus"
 	"github.com/Sterlites/RDxClaw/pkg/providers"
+	"github.com/Sterlites/RDxClaw/pkg/reqctx"
 	"github.com/Sterlites/RDxClaw/pkg/tools"
 )
 
 type SubagentTask struct {
 	ID            string `json:"id"`
+	ParentID      string `json:"parent_id,omitempty"` // agent that spawned this one, if any
 	Task          string `json:"task"`
 	Label         string `json:"label"`
 	OriginChannel string `json:"origin_channel"`
 	OriginChatID  string `json:"origin_chat_id"`
-	Status        string `json:"status"` // running, completed, failed, cancelled
+	Status        string `json:"status"` // queued, running, stopping, completed, failed, cancelled
 	Result        string `json:"result,omitempty"`
 	TokensUsed    int    `json:"tokens_used,omitempty"`
 	Created       int64  `json:"created"`
 	Finished      int64  `json:"finished,omitempty"`
 	cancel        context.CancelFunc
+	done          chan struct{} // closed when the task's goroutine returns
+	scope         string        // scratchpad scope shared by the task tree
+	// waited is set while a caller, such as delegate_task, waits for the
+	// result itself, so it is not announced on the bus as well.
+	waited bool
 }
 
+// DefaultMaxIterations is a swarm agent's tool iteration cap unless
+// SetMaxIterations or the spawning request overrides it.
+const DefaultMaxIterations = 10
+
+// DefaultKillGracePeriod is how long KillAgent waits for a cancelled agent
+// to stop on its own before marking it cancelled.
+const DefaultKillGracePeriod = 5 * time.Second
+
 // Manager coordinates swarm agents.
 type Manager struct {
 	tasks         map[string]*SubagentTask
 	mu            sync.RWMutex
 	provider      providers.LLMProvider
 	defaultModel  string
+	temperature   float64
+	maxTokens     int
 	bus           *bus.MessageBus
 	workspace     string
 	registry      *tools.ToolRegistry
 	maxIterations int
+	outputLimit   tools.ToolOutputLimit
+	continuations int // see tools.ToolLoopConfig.MaxContinuations
 	nextID        int
+	killGrace     time.Duration
+	scratch       map[string]map[string]string // scope -> key -> value
+	scratchOrder  []string                     // scopes, oldest first
+
+	// Memory backpressure; see SetMemoryThresholds.
+	memLimit       uint64
+	minHeadroom    uint64
+	headroom       func(limit uint64) (uint64, bool) // overridden in tests
+	resumeInterval time.Duration
+	queue          []queuedSpawn
+	draining       bool
 }
 
 // NewManager creates a new swarm manager.
@@ -45,11 +79,103 @@ func NewManager(provider providers.LLMProvider, defaultModel, workspace string,
 		tasks:         make(map[string]*SubagentTask),
 		provider:      provider,
 		defaultModel:  defaultModel,
+		temperature:   0.7,
+		maxTokens:     4096,
 		bus:           bus,
 		workspace:     workspace,
 		registry:      tools.NewToolRegistry(),
-		maxIterations: 10,
+		maxIterations: DefaultMaxIterations,
 		nextID:        1,
+		killGrace:     DefaultKillGracePeriod,
+		scratch:       make(map[string]map[string]string),
+
+		headroom:       memoryHeadroom,
+		resumeInterval: DefaultResumeInterval,
+	}
+}
+
+// SetToolOutputLimit caps the tool results subagents feed back to the model.
+func (sm *Manager) SetToolOutputLimit(limit tools.ToolOutputLimit) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.outputLimit = limit
+}
+
+// SetMaxIterations sets the tool iteration cap of swarm agents spawned
+// without an override.
+func (sm *Manager) SetMaxIterations(n int) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.maxIterations = n
+}
+
+// SetModelOptions sets the sampling temperature and the response token
+// cap of swarm agents.
+func (sm *Manager) SetModelOptions(temperature float64, maxTokens int) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.temperature, sm.maxTokens = temperature, maxTokens
+}
+
+// SetMaxContinuations sets how many times a subagent reply cut off at
+// max_tokens is continued.
+func (sm *Manager) SetMaxContinuations(n int) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.continuations = n
+}
+
+// SetKillGracePeriod sets how long KillAgent waits for an agent to stop.
+func (sm *Manager) SetKillGracePeriod(d time.Duration) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.killGrace = d
+}
+
+// newTaskID returns an agent ID of the form agent-<seq>-<suffix>. The
+// sequence number keeps IDs short and ordered within a run; the random
+// suffix keeps them unique across restarts.
+func newTaskID(seq int) string {
+	var suffix [3]byte
+	if _, err := rand.Read(suffix[:]); err != nil {
+		// Fall back to the clock; still distinct from a previous run.
+		return fmt.Sprintf("agent-%d-%x", seq, time.Now().UnixNano()&0xffffff)
+	}
+	return fmt.Sprintf("agent-%d-%s", seq, hex.EncodeToString(suffix[:]))
+}
+
+// taskSeq extracts the sequence number from an agent ID. It also accepts
+// the older agent-<seq> form.
+func taskSeq(id string) (int, bool) {
+	rest, ok := strings.CutPrefix(id, "agent-")
+	if !ok {
+		return 0, false
+	}
+	rest, _, _ = strings.Cut(rest, "-")
+	seq, err := strconv.Atoi(rest)
+	return seq, err == nil
+}
+
+// Restore loads previously persisted tasks, for example after a restart.
+// Tasks that were still running when saved have lost their goroutine and
+// are marked cancelled. New IDs continue past the highest restored one.
+func (sm *Manager) Restore(tasks []*SubagentTask) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	for _, task := range tasks {
+		if _, exists := sm.tasks[task.ID]; exists {
+			continue
+		}
+		if task.Status == "queued" || task.Status == "running" || task.Status == "stopping" {
+			task.Status = "cancelled"
+			task.Result = "Task interrupted by restart"
+			task.Finished = time.Now().UnixMilli()
+		}
+		sm.tasks[task.ID] = task
+		if seq, ok := taskSeq(task.ID); ok && seq >= sm.nextID {
+			sm.nextID = seq + 1
+		}
 	}
 }
 
@@ -62,35 +188,100 @@ func (sm *Manager) SetToolRegistry(registry *tools.ToolRegistry) {
 
 // Spawn starts a new subagent task asynchronously.
 func (sm *Manager) Spawn(ctx context.Context, task, label, originChannel, originChatID string, callback tools.AsyncCallback) (string, error) {
+	_, msg, err := sm.spawn(ctx, task, label, originChannel, originChatID, callback, false)
+	return msg, err
+}
+
+// spawn is Spawn, returning the task as well. When waited is set the
+// caller receives the result through callback, and it is not announced
+// unless the caller calls stopWaiting.
+func (sm *Manager) spawn(ctx context.Context, task, label, originChannel, originChatID string, callback tools.AsyncCallback, waited bool) (*SubagentTask, string, error) {
+	canStart := sm.hasHeadroom()
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
-	taskID := fmt.Sprintf("agent-%d", sm.nextID)
+	taskID := newTaskID(sm.nextID)
 	sm.nextID++
 
-	// Create a new context with cancel for this specific task
-	taskCtx, cancel := context.WithCancel(context.Background())
+	scope := sm.scopeLocked(ctx)
+	if scope == "" {
+		scope = taskID
+	}
+	var parentID string
+	if id := taskFromContext(ctx); id != "" {
+		if _, ok := sm.tasks[id]; ok {
+			parentID = id
+		}
+	}
+
+	// Create a new context with cancel for this specific task. It outlives the
+	// caller's turn but keeps its request ID for log correlation, and the
+	// iteration cap the request or skill set.
+	detached := reqctx.Detach(ctx)
+	if n := tools.MaxIterationsFromContext(ctx, 0); n > 0 {
+		detached = tools.WithMaxIterations(detached, n)
+	}
+	taskCtx, cancel := context.WithCancel(detached)
 
 	subagentTask := &SubagentTask{
 		ID:            taskID,
+		ParentID:      parentID,
 		Task:          task,
 		Label:         label,
 		OriginChannel: originChannel,
 		OriginChatID:  originChatID,
-		Status:        "running",
+		Status:        "queued",
 		Created:       time.Now().UnixMilli(),
 		cancel:        cancel,
+		done:          make(chan struct{}),
+		scope:         scope,
+		waited:        waited,
 	}
 	sm.tasks[taskID] = subagentTask
 
-	// Start task in background
+	q := queuedSpawn{task: subagentTask, ctx: taskCtx, callback: callback}
+	if len(sm.queue) > 0 || !canStart {
+		// Not enough memory headroom; start it once there is.
+		sm.enqueueLocked(q)
+		if label != "" {
+			return subagentTask, fmt.Sprintf("Queued agent '%s' (ID: %s) until memory frees up, for task: %s", label, taskID, task), nil
+		}
+		return subagentTask, fmt.Sprintf("Queued agent (ID: %s) until memory frees up, for task: %s", taskID, task), nil
+	}
+	subagentTask.Status = "running"
+	sm.startLocked(q)
+
+	if label != "" {
+		return subagentTask, fmt.Sprintf("Spawned agent '%s' (ID: %s) for task: %s", label, taskID, task), nil
+	}
+	return subagentTask, fmt.Sprintf("Spawned agent (ID: %s) for task: %s", taskID, task), nil
+}
+
+// stopWaiting hands a waited task's result back to the bus, for a caller
+// that gives up waiting. It reports false if the task has already
+// finished, in which case its result is on its way to the callback.
+func (sm *Manager) stopWaiting(task *SubagentTask) bool {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	switch task.Status {
+	case "queued", "running", "stopping":
+		task.waited = false
+		return true
+	}
+	return false
+}
+
+// startLocked runs a spawned task in the background. Callers must hold sm.mu.
+func (sm *Manager) startLocked(q queuedSpawn) {
 	go func() {
-		result, err := sm.RunTask(taskCtx, subagentTask)
+		result, err := sm.RunTask(q.ctx, q.task)
+		close(q.task.done)
 
 		// Notify callback if present
-		if callback != nil {
-			toolResult := &tools.ToolResult{
-				ForUser: result.Content,
+		if q.callback != nil {
+			toolResult := &tools.ToolResult{}
+			if result != nil {
+				toolResult.ForUser = result.Content
 			}
 			if err != nil {
 				toolResult.IsError = true
@@ -99,21 +290,18 @@ func (sm *Manager) Spawn(ctx context.Context, task, label, originChannel, origin
 			} else {
 				toolResult.ForLLM = fmt.Sprintf("Agent completed: %s", result.Content)
 			}
-			callback(context.Background(), toolResult)
+			q.callback(context.Background(), toolResult)
 		}
 	}()
-
-	if label != "" {
-		return fmt.Sprintf("Spawned agent '%s' (ID: %s) for task: %s", label, taskID, task), nil
-	}
-	return fmt.Sprintf("Spawned agent (ID: %s) for task: %s", taskID, task), nil
 }
 
 // RunTask executes a task synchronously.
 func (sm *Manager) RunTask(ctx context.Context, task *SubagentTask) (*tools.ToolLoopResult, error) {
 	defer func() {
 		sm.mu.Lock()
-		task.Finished = time.Now().UnixMilli()
+		if task.Finished == 0 {
+			task.Finished = time.Now().UnixMilli()
+		}
 		if task.Status == "running" {
 			task.Status = "completed"
 		}
@@ -140,21 +328,32 @@ When finished, provide a clear summary of your work.`
 	// Run tool loop
 	sm.mu.RLock()
 	registry := sm.registry
-	maxIter := sm.maxIterations
+	maxIter := tools.MaxIterationsFromContext(ctx, sm.maxIterations)
+	outputLimit := sm.outputLimit
+	continuations := sm.continuations
+	temperature, maxTokens := sm.temperature, sm.maxTokens
 	sm.mu.RUnlock()
 
-	loopResult, err := tools.RunToolLoop(ctx, tools.ToolLoopConfig{
+	loopResult, err := tools.RunToolLoop(withTask(ctx, task.ID), tools.ToolLoopConfig{
 		Provider:      sm.provider,
 		Model:         sm.defaultModel,
 		Tools:         registry,
 		MaxIterations: maxIter,
 		LLMOptions: map[string]any{
-			"max_tokens":  4096,
-			"temperature": 0.7,
+			"max_tokens":  maxTokens,
+			"temperature": temperature,
 		},
+		OutputLimit:      outputLimit,
+		MaxContinuations: continuations,
 	}, messages, task.OriginChannel, task.OriginChatID)
 
 	sm.mu.Lock()
+	if task.Status != "running" && task.Status != "stopping" {
+		// KillAgent gave up waiting and already recorded and announced
+		// the cancellation.
+		sm.mu.Unlock()
+		return loopResult, err
+	}
 	if err != nil {
 		task.Status = "failed"
 		task.Result = fmt.Sprintf("Error: %v", err)
@@ -168,21 +367,33 @@ When finished, provide a clear summary of your work.`
 	}
 	sm.mu.Unlock()
 
-	// Announce to bus
-	if sm.bus != nil {
-		announceContent := fmt.Sprintf("Swarm Agent '%s' (%s) finished.\nTask: %s\n\nResult:\n%s",
-			task.Label, task.ID, task.Task, task.Result)
-		sm.bus.PublishInbound(bus.InboundMessage{
-			Channel:  "system",
-			SenderID: fmt.Sprintf("swarm:%s", task.ID),
-			ChatID:   fmt.Sprintf("%s:%s", task.OriginChannel, task.OriginChatID),
-			Content:  announceContent,
-		})
-	}
+	sm.announce(task)
 
 	return loopResult, err
 }
 
+// announce publishes a task's final result to the bus, unless a caller
+// is waiting for it.
+func (sm *Manager) announce(task *SubagentTask) {
+	if sm.bus == nil {
+		return
+	}
+	sm.mu.RLock()
+	if task.waited {
+		sm.mu.RUnlock()
+		return
+	}
+	announceContent := fmt.Sprintf("Swarm Agent '%s' (%s) finished.\nTask: %s\n\nResult:\n%s",
+		task.Label, task.ID, task.Task, task.Result)
+	sm.mu.RUnlock()
+	sm.bus.PublishInbound(bus.InboundMessage{
+		Channel:  "system",
+		SenderID: fmt.Sprintf("swarm:%s", task.ID),
+		ChatID:   fmt.Sprintf("%s:%s", task.OriginChannel, task.OriginChatID),
+		Content:  announceContent,
+	})
+}
+
 func (sm *Manager) GetAgent(id string) (*SubagentTask, bool) {
 	sm.mu.RLock()
 	defer sm.mu.RUnlock()
@@ -204,22 +415,91 @@ func (sm *Manager) ListAgents() []*SubagentTask {
 	return tasks
 }
 
+// AgentNode is a task together with the agents it spawned.
+type AgentNode struct {
+	*SubagentTask
+	Children []*AgentNode `json:"children,omitempty"`
+}
+
+// AgentTree returns the agents arranged by parentage. Top-level agents, and
+// agents whose parent is no longer known, are roots. Like ListAgents, each
+// level is sorted newest first.
+func (sm *Manager) AgentTree() []*AgentNode {
+	tasks := sm.ListAgents()
+
+	nodes := make(map[string]*AgentNode, len(tasks))
+	for _, t := range tasks {
+		nodes[t.ID] = &AgentNode{SubagentTask: t}
+	}
+
+	var roots []*AgentNode
+	for _, t := range tasks {
+		node := nodes[t.ID]
+		if parent, ok := nodes[t.ParentID]; ok && t.ParentID != "" {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+	return roots
+}
+
+// KillAgent stops a running agent in two phases. It cancels the agent's
+// context, then waits up to the kill grace period for the agent to wind
+// down and record its own status, so a tool call in progress can clean up.
+// An agent that has not stopped by then is marked cancelled anyway. Either
+// way the outcome is announced on the bus.
 func (sm *Manager) KillAgent(id string) error {
 	sm.mu.Lock()
-	defer sm.mu.Unlock()
-
 	task, ok := sm.tasks[id]
 	if !ok {
-		return fmt.Errorf("agent not found")
+		sm.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrAgentNotFound, id)
+	}
+	if q, ok := sm.dequeueLocked(id); ok {
+		// Never started, so there is nothing to wind down.
+		task.cancel()
+		task.Status = "cancelled"
+		task.Result = "Task cancelled before it started"
+		task.Finished = time.Now().UnixMilli()
+		sm.mu.Unlock()
+		sm.announce(task)
+		if q.callback != nil {
+			err := fmt.Errorf("agent %s cancelled before it started", id)
+			q.callback(context.Background(), tools.ErrorResult(err.Error()).WithError(err))
+		}
+		return nil
 	}
-
 	if task.Status != "running" {
-		return fmt.Errorf("agent is not running (status: %s)", task.Status)
+		sm.mu.Unlock()
+		return fmt.Errorf("%w (status: %s)", ErrAgentNotRunning, task.Status)
 	}
+	task.Status = "stopping"
+	cancel, done, grace := task.cancel, task.done, sm.killGrace
+	sm.mu.Unlock()
 
-	if task.cancel != nil {
-		task.cancel()
+	if cancel != nil {
+		cancel()
+	}
+	if done != nil {
+		select {
+		case <-done:
+			return nil
+		case <-time.After(grace):
+		}
+	}
+
+	sm.mu.Lock()
+	forced := task.Status == "stopping"
+	if forced {
+		task.Status = "cancelled"
+		task.Result = "Task cancelled (did not stop within the grace period)"
+		task.Finished = time.Now().UnixMilli()
+	}
+	sm.mu.Unlock()
+
+	if forced {
+		sm.announce(task)
 	}
-	task.Status = "cancelled"
 	return nil
 }
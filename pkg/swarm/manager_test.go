@@ -2,28 +2,45 @@ package swarm
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/Sterlites/RDxClaw/pkg/bus"
 	"github.com/Sterlites/RDxClaw/pkg/providers"
+	"github.com/Sterlites/RDxClaw/pkg/reqctx"
+	"github.com/Sterlites/RDxClaw/pkg/tools"
 	"github.com/stretchr/testify/assert"
 )
 
 // MockProvider satisfies providers.LLMProvider for testing
 type MockProvider struct {
 	Response string
+	Delay    time.Duration
 }
 
 func (m *MockProvider) Chat(ctx context.Context, messages []providers.Message, tools []providers.ToolDefinition, model string, options map[string]any) (*providers.LLMResponse, error) {
+	if m.Delay > 0 {
+		select {
+		case <-time.After(m.Delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
 	return &providers.LLMResponse{
 		Content: m.Response,
-		Usage: providers.UsageInfo{
+		Usage: &providers.UsageInfo{
 			TotalTokens: 100,
 		},
 	}, nil
 }
 
+func (m *MockProvider) GetDefaultModel() string {
+	return "test-model"
+}
+
 func (m *MockProvider) EstimateTokens(messages []providers.Message) int {
 	return 100
 }
@@ -67,16 +84,16 @@ func TestManager_Lifecycle(t *testing.T) {
 func TestManager_Kill(t *testing.T) {
 	msgBus := bus.NewMessageBus()
 	// Slow provider to simulate long running task
-	provider := &MockProvider{Response: "Done"}
+	provider := &MockProvider{Response: "Done", Delay: 5 * time.Second}
 	manager := NewManager(provider, "test-model", "/tmp", msgBus)
 
 	// We can't easily wait for it to be mid-execution with a simple mock without channels
 	// but we can test the status transition.
 
-	id, _ := manager.Spawn(context.Background(), "Long task", "kill-me", "ch", "chat", nil)
-	// Extract ID from message: "Spawned agent 'kill-me' (ID: agent-1) for task: Long task"
-	// ID is generated as agent-1, agent-2...
-	agentID := "agent-1"
+	_, _ = manager.Spawn(context.Background(), "Long task", "kill-me", "ch", "chat", nil)
+	agents := manager.ListAgents()
+	assert.Len(t, agents, 1)
+	agentID := agents[0].ID
 
 	err := manager.KillAgent(agentID)
 	assert.NoError(t, err)
@@ -84,3 +101,450 @@ func TestManager_Kill(t *testing.T) {
 	agent, _ := manager.GetAgent(agentID)
 	assert.Equal(t, "cancelled", agent.Status)
 }
+
+func TestManager_KillErrors(t *testing.T) {
+	provider := &MockProvider{Response: "Done"}
+	manager := NewManager(provider, "test-model", "/tmp", nil)
+
+	err := manager.KillAgent("agent-404")
+	assert.ErrorIs(t, err, ErrAgentNotFound)
+
+	manager.mu.Lock()
+	manager.tasks["agent-done"] = &SubagentTask{ID: "agent-done", Status: "completed"}
+	manager.mu.Unlock()
+
+	err = manager.KillAgent("agent-done")
+	assert.ErrorIs(t, err, ErrAgentNotRunning)
+	assert.Contains(t, err.Error(), "completed")
+}
+
+func TestManager_IDsSurviveRestore(t *testing.T) {
+	provider := &MockProvider{Response: "Done"}
+	first := NewManager(provider, "test-model", "/tmp", nil)
+	first.mu.Lock()
+	for i := 1; i <= 3; i++ {
+		id := newTaskID(first.nextID)
+		first.nextID++
+		first.tasks[id] = &SubagentTask{ID: id, Status: "completed"}
+	}
+	first.tasks["agent-7"] = &SubagentTask{ID: "agent-7", Status: "running"} // legacy ID format
+	first.mu.Unlock()
+
+	second := NewManager(provider, "test-model", "/tmp", nil)
+	second.Restore(first.ListAgents())
+
+	legacy, ok := second.GetAgent("agent-7")
+	assert.True(t, ok)
+	assert.Equal(t, "cancelled", legacy.Status, "restored running task has no goroutine")
+
+	_, err := second.Spawn(context.Background(), "New task", "", "ch", "chat", nil)
+	assert.NoError(t, err)
+	assert.Len(t, second.ListAgents(), 5, "new ID must not collide with a restored one")
+
+	seen := map[string]bool{}
+	for _, a := range second.ListAgents() {
+		assert.False(t, seen[a.ID], "duplicate ID %s", a.ID)
+		seen[a.ID] = true
+		if a.Task == "New task" {
+			seq, ok := taskSeq(a.ID)
+			assert.True(t, ok)
+			assert.Equal(t, 8, seq)
+		}
+	}
+}
+
+// toolCallingProvider asks for one call to the named tool, then answers.
+type toolCallingProvider struct {
+	tool  string
+	calls int
+}
+
+func (p *toolCallingProvider) Chat(ctx context.Context, messages []providers.Message, defs []providers.ToolDefinition, model string, options map[string]any) (*providers.LLMResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	p.calls++
+	if p.calls == 1 {
+		return &providers.LLMResponse{ToolCalls: []providers.ToolCall{{ID: "call-1", Name: p.tool, Arguments: map[string]interface{}{}}}}, nil
+	}
+	return &providers.LLMResponse{Content: "Done"}, nil
+}
+
+func (p *toolCallingProvider) GetDefaultModel() string { return "test-model" }
+
+func (p *toolCallingProvider) EstimateTokens(messages []providers.Message) int { return 100 }
+
+// blockingTool runs until its context is cancelled, then spends cleanup
+// cleaning up. With ignoreCancel it keeps running regardless.
+type blockingTool struct {
+	started      chan struct{}
+	cleanedUp    atomic.Bool
+	cleanup      time.Duration
+	ignoreCancel bool
+}
+
+func (t *blockingTool) Name() string        { return "block" }
+func (t *blockingTool) Description() string { return "Blocks until cancelled" }
+func (t *blockingTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{"type": "object"}
+}
+
+func (t *blockingTool) Execute(ctx context.Context, args map[string]interface{}) *tools.ToolResult {
+	close(t.started)
+	if t.ignoreCancel {
+		time.Sleep(time.Second)
+		return tools.NewToolResult("finished anyway")
+	}
+	<-ctx.Done()
+	time.Sleep(t.cleanup)
+	t.cleanedUp.Store(true)
+	return tools.ErrorResult("interrupted")
+}
+
+func spawnBlocked(t *testing.T, manager *Manager, tool *blockingTool) string {
+	t.Helper()
+	registry := tools.NewToolRegistry()
+	registry.Register(tool)
+	manager.SetToolRegistry(registry)
+
+	_, err := manager.Spawn(context.Background(), "Blocking task", "blocker", "ch", "chat", nil)
+	assert.NoError(t, err)
+	select {
+	case <-tool.started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("tool never started")
+	}
+	return manager.ListAgents()[0].ID
+}
+
+func TestManager_KillLetsToolCleanUp(t *testing.T) {
+	msgBus := bus.NewMessageBus()
+	manager := NewManager(&toolCallingProvider{tool: "block"}, "test-model", "/tmp", msgBus)
+	tool := &blockingTool{started: make(chan struct{}), cleanup: 50 * time.Millisecond}
+	agentID := spawnBlocked(t, manager, tool)
+
+	assert.NoError(t, manager.KillAgent(agentID))
+	assert.True(t, tool.cleanedUp.Load(), "kill must wait for the tool to clean up")
+
+	agent, _ := manager.GetAgent(agentID)
+	assert.Equal(t, "cancelled", agent.Status)
+	assert.Equal(t, "Task cancelled", agent.Result)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	msg, ok := msgBus.ConsumeInbound(ctx)
+	assert.True(t, ok, "completion must be announced")
+	assert.Equal(t, "swarm:"+agentID, msg.SenderID)
+}
+
+func TestManager_KillForcesAfterGracePeriod(t *testing.T) {
+	msgBus := bus.NewMessageBus()
+	manager := NewManager(&toolCallingProvider{tool: "block"}, "test-model", "/tmp", msgBus)
+	manager.SetKillGracePeriod(50 * time.Millisecond)
+	tool := &blockingTool{started: make(chan struct{}), ignoreCancel: true}
+	agentID := spawnBlocked(t, manager, tool)
+
+	assert.NoError(t, manager.KillAgent(agentID))
+	agent, _ := manager.GetAgent(agentID)
+	assert.Equal(t, "cancelled", agent.Status)
+	assert.Contains(t, agent.Result, "grace period")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, ok := msgBus.ConsumeInbound(ctx)
+	assert.True(t, ok, "forced cancellation must be announced")
+
+	// When the stubborn tool finally returns, the result must not change
+	// and nothing more is announced.
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	defer cancel2()
+	_, ok = msgBus.ConsumeInbound(ctx2)
+	assert.False(t, ok)
+	agent, _ = manager.GetAgent(agentID)
+	assert.Equal(t, "cancelled", agent.Status)
+}
+
+// scratchProvider drives agents through the scratchpad tool: a task of
+// "write <key>=<value>" posts the value, "read <key>" fetches it. The
+// agent's final answer is the tool's output.
+type scratchProvider struct{}
+
+func (p *scratchProvider) Chat(ctx context.Context, messages []providers.Message, defs []providers.ToolDefinition, model string, options map[string]any) (*providers.LLMResponse, error) {
+	if last := messages[len(messages)-1]; last.Role == "tool" {
+		return &providers.LLMResponse{Content: last.Content}, nil
+	}
+	args := map[string]interface{}{}
+	task := messages[1].Content
+	if kv, ok := strings.CutPrefix(task, "write "); ok {
+		key, value, _ := strings.Cut(kv, "=")
+		args["action"], args["key"], args["value"] = "scratch_set", key, value
+	} else {
+		args["action"], args["key"] = "scratch_get", strings.TrimPrefix(task, "read ")
+	}
+	return &providers.LLMResponse{ToolCalls: []providers.ToolCall{{ID: "call-1", Name: "scratchpad", Arguments: args}}}, nil
+}
+
+func (p *scratchProvider) GetDefaultModel() string { return "test-model" }
+
+func (p *scratchProvider) EstimateTokens(messages []providers.Message) int { return 100 }
+
+func waitForAgents(t *testing.T, manager *Manager) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		manager.mu.RLock()
+		running := 0
+		for _, task := range manager.tasks {
+			if task.Status == "running" {
+				running++
+			}
+		}
+		manager.mu.RUnlock()
+		if running == 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("agents did not finish")
+}
+
+func agentResult(manager *Manager, label string) string {
+	manager.mu.RLock()
+	defer manager.mu.RUnlock()
+	for _, task := range manager.tasks {
+		if task.Label == label {
+			return task.Result
+		}
+	}
+	return ""
+}
+
+func TestManager_ScratchpadSharedWithinTaskTree(t *testing.T) {
+	manager := NewManager(&scratchProvider{}, "test-model", "/tmp", nil)
+	registry := tools.NewToolRegistry()
+	registry.Register(NewScratchpadTool(manager))
+	manager.SetToolRegistry(registry)
+
+	turn := reqctx.WithRequestID(context.Background(), "turn-1")
+	_, err := manager.Spawn(turn, "write partial=42", "mapper", "ch", "chat", nil)
+	assert.NoError(t, err)
+	waitForAgents(t, manager)
+
+	_, err = manager.Spawn(turn, "read partial", "reducer", "ch", "chat", nil)
+	assert.NoError(t, err)
+	otherTurn := reqctx.WithRequestID(context.Background(), "turn-2")
+	_, err = manager.Spawn(otherTurn, "read partial", "outsider", "ch", "chat", nil)
+	assert.NoError(t, err)
+	waitForAgents(t, manager)
+
+	assert.Equal(t, "42", agentResult(manager, "reducer"))
+	assert.Contains(t, agentResult(manager, "outsider"), "Nothing posted", "scratchpads must not leak across task trees")
+
+	// The parent turn sees what its agents posted
+	value, ok := manager.ScratchGet(turn, "partial")
+	assert.True(t, ok)
+	assert.Equal(t, "42", value)
+}
+
+func TestManager_ScratchpadBounds(t *testing.T) {
+	manager := NewManager(&MockProvider{}, "test-model", "/tmp", nil)
+	ctx := reqctx.WithRequestID(context.Background(), "turn")
+
+	assert.ErrorIs(t, manager.ScratchSet(context.Background(), "k", "v"), ErrNoTaskScope)
+	assert.ErrorIs(t, manager.ScratchSet(ctx, "k", strings.Repeat("x", MaxScratchValueLen+1)), ErrInvalidScratch)
+
+	for i := 0; i < MaxScratchEntries; i++ {
+		assert.NoError(t, manager.ScratchSet(ctx, fmt.Sprintf("k%d", i), "v"))
+	}
+	assert.ErrorIs(t, manager.ScratchSet(ctx, "one-more", "v"), ErrScratchFull)
+	assert.NoError(t, manager.ScratchSet(ctx, "k0", "updated"))
+
+	for i := 0; i < MaxScratchScopes; i++ {
+		other := reqctx.WithRequestID(context.Background(), fmt.Sprintf("other-%d", i))
+		assert.NoError(t, manager.ScratchSet(other, "k", "v"))
+	}
+	assert.Empty(t, manager.ScratchList(ctx), "oldest scratchpad is evicted")
+}
+
+// spawningProvider makes the agent whose task is "parent" spawn a child
+// agent through the spawn tool; every other agent just answers.
+type spawningProvider struct{}
+
+func (p *spawningProvider) Chat(ctx context.Context, messages []providers.Message, defs []providers.ToolDefinition, model string, options map[string]any) (*providers.LLMResponse, error) {
+	if messages[len(messages)-1].Role == "tool" || messages[1].Content != "parent" {
+		return &providers.LLMResponse{Content: "done"}, nil
+	}
+	return &providers.LLMResponse{ToolCalls: []providers.ToolCall{{
+		ID:        "call-1",
+		Name:      "spawn_agent",
+		Arguments: map[string]interface{}{"task": "child", "label": "child"},
+	}}}, nil
+}
+
+func (p *spawningProvider) GetDefaultModel() string { return "test-model" }
+
+func (p *spawningProvider) EstimateTokens(messages []providers.Message) int { return 100 }
+
+func TestManager_ChildRecordsParent(t *testing.T) {
+	manager := NewManager(&spawningProvider{}, "test-model", "/tmp", nil)
+	registry := tools.NewToolRegistry()
+	registry.Register(NewSpawnTool(manager))
+	manager.SetToolRegistry(registry)
+
+	_, err := manager.Spawn(context.Background(), "parent", "parent", "ch", "chat", nil)
+	assert.NoError(t, err)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(manager.ListAgents()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	waitForAgents(t, manager)
+
+	tree := manager.AgentTree()
+	if assert.Len(t, tree, 1) {
+		parent := tree[0]
+		assert.Equal(t, "parent", parent.Label)
+		assert.Empty(t, parent.ParentID)
+		if assert.Len(t, parent.Children, 1) {
+			assert.Equal(t, "child", parent.Children[0].Label)
+			assert.Equal(t, parent.ID, parent.Children[0].ParentID)
+		}
+	}
+}
+
+func TestManager_DefersSpawnOnLowHeadroom(t *testing.T) {
+	manager := NewManager(&MockProvider{Response: "Done"}, "test-model", "/tmp", nil)
+	manager.SetMemoryThresholds(1<<30, 64<<20)
+	manager.resumeInterval = 10 * time.Millisecond
+
+	var headroom atomic.Uint64
+	headroom.Store(16 << 20) // well below the 64 MB minimum
+	manager.headroom = func(limit uint64) (uint64, bool) { return headroom.Load(), true }
+
+	msg, err := manager.Spawn(context.Background(), "Heavy task", "heavy", "ch", "chat", nil)
+	assert.NoError(t, err)
+	assert.Contains(t, msg, "Queued agent 'heavy'")
+
+	time.Sleep(50 * time.Millisecond)
+	agentID := manager.ListAgents()[0].ID
+	manager.mu.RLock()
+	status := manager.tasks[agentID].Status
+	manager.mu.RUnlock()
+	assert.Equal(t, "queued", status, "spawn must wait while headroom is low")
+
+	headroom.Store(512 << 20)
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		manager.mu.RLock()
+		status = manager.tasks[agentID].Status
+		manager.mu.RUnlock()
+		if status == "completed" {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, "completed", status, "queued spawn must resume once memory frees")
+}
+
+func TestManager_KillQueuedAgent(t *testing.T) {
+	manager := NewManager(&MockProvider{Response: "Done"}, "test-model", "/tmp", nil)
+	manager.SetMemoryThresholds(1<<30, 64<<20)
+	manager.headroom = func(limit uint64) (uint64, bool) { return 0, true }
+
+	_, err := manager.Spawn(context.Background(), "Heavy task", "heavy", "ch", "chat", nil)
+	assert.NoError(t, err)
+	agentID := manager.ListAgents()[0].ID
+
+	assert.NoError(t, manager.KillAgent(agentID))
+	manager.mu.RLock()
+	defer manager.mu.RUnlock()
+	assert.Equal(t, "cancelled", manager.tasks[agentID].Status)
+	assert.Empty(t, manager.queue)
+}
+
+func TestDelegateTaskIsNotAnnounced(t *testing.T) {
+	msgBus := bus.NewMessageBus()
+	manager := NewManager(&MockProvider{Response: "Delegated result."}, "test-model", "/tmp", msgBus)
+	tool := NewSubagentTool(manager)
+
+	result := tool.Execute(context.Background(), map[string]interface{}{"task": "Sync task"})
+	assert.False(t, result.IsError)
+	assert.Equal(t, "Delegated result.", result.ForUser)
+
+	// A spawned agent is announced; the delegated one before it is not.
+	_, err := manager.Spawn(context.Background(), "Async task", "", "cli", "direct", nil)
+	assert.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	msg, ok := msgBus.ConsumeInbound(ctx)
+	assert.True(t, ok)
+	assert.Contains(t, msg.Content, "Task: Async task")
+}
+
+// loopingProvider asks for a tool call on every turn, so a task runs until
+// its iteration cap.
+type loopingProvider struct {
+	calls atomic.Int32
+}
+
+func (p *loopingProvider) Chat(ctx context.Context, messages []providers.Message, defs []providers.ToolDefinition, model string, options map[string]any) (*providers.LLMResponse, error) {
+	n := p.calls.Add(1)
+	return &providers.LLMResponse{
+		ToolCalls: []providers.ToolCall{{ID: fmt.Sprintf("call-%d", n), Name: "no_such_tool", Arguments: map[string]interface{}{}}},
+	}, nil
+}
+
+func (p *loopingProvider) GetDefaultModel() string { return "test-model" }
+
+func TestManager_SpawnKeepsIterationOverride(t *testing.T) {
+	run := func(ctx context.Context, configured int) int32 {
+		provider := &loopingProvider{}
+		manager := NewManager(provider, "test-model", "/tmp", nil)
+		if configured > 0 {
+			manager.SetMaxIterations(configured)
+		}
+		result := NewSubagentTool(manager).Execute(ctx, map[string]interface{}{"task": "loop"})
+		assert.False(t, result.IsError, result.ForLLM)
+		return provider.calls.Load()
+	}
+
+	assert.Equal(t, int32(DefaultMaxIterations), run(context.Background(), 0))
+	assert.Equal(t, int32(4), run(context.Background(), 4), "the configured cap")
+	assert.Equal(t, int32(2), run(tools.WithMaxIterations(context.Background(), 2), 4), "the request's cap survives the detached task context")
+}
+
+func TestManager_DrainStartsOneAgentPerCheck(t *testing.T) {
+	manager := NewManager(&MockProvider{Response: "Done"}, "test-model", "/tmp", nil)
+	manager.SetMemoryThresholds(1<<30, 64<<20)
+	manager.resumeInterval = 5 * time.Millisecond
+
+	var low atomic.Bool
+	var checks, lockedChecks atomic.Int32
+	low.Store(true)
+	manager.headroom = func(limit uint64) (uint64, bool) {
+		if manager.mu.TryLock() {
+			manager.mu.Unlock()
+		} else {
+			lockedChecks.Add(1)
+		}
+		if low.Load() {
+			return 0, true
+		}
+		checks.Add(1)
+		return 512 << 20, true
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err := manager.Spawn(context.Background(), "Heavy task", "", "ch", "chat", nil)
+		assert.NoError(t, err)
+	}
+	low.Store(false)
+
+	assert.Eventually(t, func() bool {
+		manager.mu.RLock()
+		defer manager.mu.RUnlock()
+		return len(manager.queue) == 0
+	}, 2*time.Second, 5*time.Millisecond)
+	assert.GreaterOrEqual(t, checks.Load(), int32(3), "each queued agent needs its own headroom check")
+	assert.Zero(t, lockedChecks.Load(), "memory must be sampled outside the manager lock")
+}
@@ -0,0 +1,118 @@
+package swarm
+
+import (
+	"context"
+	"math"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/Sterlites/RDxClaw/pkg/tools"
+)
+
+// DefaultResumeInterval is how often queued spawns re-check memory headroom.
+const DefaultResumeInterval = time.Second
+
+// queuedSpawn is a spawn deferred until there is enough memory to run it.
+type queuedSpawn struct {
+	task     *SubagentTask
+	ctx      context.Context
+	callback tools.AsyncCallback
+}
+
+// SetMemoryThresholds enables memory backpressure. A new agent only starts
+// while at least minHeadroom bytes remain below limit; otherwise it is
+// queued until memory frees up. A zero limit uses the process memory limit
+// (GOMEMLIMIT), if one is set. A zero minHeadroom disables backpressure.
+func (sm *Manager) SetMemoryThresholds(limit, minHeadroom uint64) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.memLimit = limit
+	sm.minHeadroom = minHeadroom
+}
+
+// memoryHeadroom estimates how many bytes the process can still allocate
+// before reaching limit, from the Go runtime's own accounting. ok is false
+// when no limit is known.
+func memoryHeadroom(limit uint64) (headroom uint64, ok bool) {
+	if limit == 0 {
+		if l := debug.SetMemoryLimit(-1); l > 0 && l < math.MaxInt64 {
+			limit = uint64(l)
+		}
+	}
+	if limit == 0 {
+		return 0, false
+	}
+
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	used := ms.Sys - ms.HeapReleased
+	if used >= limit {
+		return 0, true
+	}
+	return limit - used, true
+}
+
+// hasHeadroom reports whether there is enough headroom to start another
+// agent now. Reading memory statistics briefly stops the world, so callers
+// must not hold sm.mu.
+func (sm *Manager) hasHeadroom() bool {
+	sm.mu.RLock()
+	limit, minHeadroom, headroomOf := sm.memLimit, sm.minHeadroom, sm.headroom
+	sm.mu.RUnlock()
+	if minHeadroom == 0 {
+		return true
+	}
+	headroom, ok := headroomOf(limit)
+	return !ok || headroom >= minHeadroom
+}
+
+// enqueueLocked defers a spawn and makes sure the queue is being drained.
+// Callers must hold sm.mu.
+func (sm *Manager) enqueueLocked(q queuedSpawn) {
+	sm.queue = append(sm.queue, q)
+	if !sm.draining {
+		sm.draining = true
+		go sm.drainQueue()
+	}
+}
+
+// drainQueue starts queued agents, oldest first, as memory becomes
+// available. It starts at most one per check, since a check cannot see the
+// memory the agents it just started will use. It exits once the queue is
+// empty.
+func (sm *Manager) drainQueue() {
+	for {
+		sm.mu.RLock()
+		interval := sm.resumeInterval
+		sm.mu.RUnlock()
+		time.Sleep(interval)
+
+		canStart := sm.hasHeadroom()
+		sm.mu.Lock()
+		if canStart && len(sm.queue) > 0 {
+			q := sm.queue[0]
+			sm.queue = sm.queue[1:]
+			q.task.Status = "running"
+			sm.startLocked(q)
+		}
+		if len(sm.queue) == 0 {
+			sm.draining = false
+			sm.mu.Unlock()
+			return
+		}
+		sm.mu.Unlock()
+	}
+}
+
+// dequeueLocked removes a queued task, returning it if it was queued.
+// Callers must hold sm.mu.
+func (sm *Manager) dequeueLocked(id string) (queuedSpawn, bool) {
+	for i, q := range sm.queue {
+		if q.task.ID == id {
+			sm.queue = append(sm.queue[:i], sm.queue[i+1:]...)
+			return q, true
+		}
+	}
+	return queuedSpawn{}, false
+}
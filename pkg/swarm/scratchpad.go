@@ -0,0 +1,102 @@
+package swarm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Sterlites/RDxClaw/pkg/reqctx"
+)
+
+// Scratchpad limits. Each task tree gets its own pad; once MaxScratchScopes
+// pads exist, the oldest is dropped to make room.
+const (
+	MaxScratchEntries  = 100
+	MaxScratchKeyLen   = 64
+	MaxScratchValueLen = 4000
+	MaxScratchScopes   = 32
+)
+
+type taskContextKey struct{}
+
+// withTask marks ctx as running on behalf of the given swarm task, so tools
+// and spawns made from it can find their place in the task tree.
+func withTask(ctx context.Context, taskID string) context.Context {
+	return context.WithValue(ctx, taskContextKey{}, taskID)
+}
+
+// taskFromContext returns the swarm task ctx runs on behalf of, or "".
+func taskFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(taskContextKey{}).(string)
+	return id
+}
+
+// scopeLocked returns the scratchpad scope for ctx. Agents inherit the
+// scope of the task that spawned them; a top-level turn is identified by
+// its request ID, so every agent it starts, and their descendants, share
+// one pad. Callers must hold sm.mu.
+func (sm *Manager) scopeLocked(ctx context.Context) string {
+	if id := taskFromContext(ctx); id != "" {
+		if task, ok := sm.tasks[id]; ok {
+			return task.scope
+		}
+	}
+	return reqctx.RequestID(ctx)
+}
+
+// ScratchSet stores value under key in the scratchpad of ctx's task tree.
+func (sm *Manager) ScratchSet(ctx context.Context, key, value string) error {
+	key = strings.TrimSpace(key)
+	switch {
+	case key == "":
+		return fmt.Errorf("%w: key is required", ErrInvalidScratch)
+	case len(key) > MaxScratchKeyLen:
+		return fmt.Errorf("%w: key longer than %d bytes", ErrInvalidScratch, MaxScratchKeyLen)
+	case len(value) > MaxScratchValueLen:
+		return fmt.Errorf("%w: value longer than %d bytes", ErrInvalidScratch, MaxScratchValueLen)
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	scope := sm.scopeLocked(ctx)
+	if scope == "" {
+		return ErrNoTaskScope
+	}
+	pad, ok := sm.scratch[scope]
+	if !ok {
+		if len(sm.scratchOrder) >= MaxScratchScopes {
+			delete(sm.scratch, sm.scratchOrder[0])
+			sm.scratchOrder = sm.scratchOrder[1:]
+		}
+		pad = make(map[string]string)
+		sm.scratch[scope] = pad
+		sm.scratchOrder = append(sm.scratchOrder, scope)
+	}
+	if _, exists := pad[key]; !exists && len(pad) >= MaxScratchEntries {
+		return fmt.Errorf("%w: %d entries", ErrScratchFull, MaxScratchEntries)
+	}
+	pad[key] = value
+	return nil
+}
+
+// ScratchGet returns the value stored under key in the scratchpad of ctx's
+// task tree.
+func (sm *Manager) ScratchGet(ctx context.Context, key string) (string, bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	value, ok := sm.scratch[sm.scopeLocked(ctx)][strings.TrimSpace(key)]
+	return value, ok
+}
+
+// ScratchList returns a copy of the scratchpad of ctx's task tree.
+func (sm *Manager) ScratchList(ctx context.Context) map[string]string {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	pad := sm.scratch[sm.scopeLocked(ctx)]
+	entries := make(map[string]string, len(pad))
+	for k, v := range pad {
+		entries[k] = v
+	}
+	return entries
+}
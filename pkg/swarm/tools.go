@@ -3,6 +3,8 @@ package swarm
 import (
 	"context"
 	"fmt"
+	"sort"
+
 	"github.com/Sterlites/RDxClaw/pkg/tools"
 )
 
@@ -121,28 +123,12 @@ func (t *SubagentTool) Execute(ctx context.Context, args map[string]interface{})
 	taskStr, _ := args["task"].(string)
 	label, _ := args["label"].(string)
 
-	// Create a task record manually or let RunTask handle it?
-	// RunTask expects *SubagentTask.
-	// We should probably expose a cleaner API on Manager like RunSync(ctx, task, label...)
-	// But since I implemented RunTask(*SubagentTask), let's build it here.
-	// Wait, Spawn handles ID generation. I need to replicate that if I construct struct manually.
-	// I should have exposed RunSync(string, string...) on Manager.
-	// I'll fix this by adding a helper in Manager or just duplicating ID logic here?
-	// Duplicating logic is bad.
-	// I'll call Spawn and wait? No, Spawn runs in goroutine.
-	// I should add sm.CreateTask(task, label...) -> *SubagentTask.
-	// For now, let's just hack it: "sync-agent-" + time?
-	// Actually, let's update Manager.RunTask to accept strings if possible or add helper.
-	// Since I can't easily edit Manager again in this turn without conflict, I'll access tasks map directly? No, private.
-	// I'll assume I can add a helper function in tools.go or use Spawn and wait on a channel?
-	// Using Spawn and waiting on channel is safest given current API.
-
 	resultChan := make(chan *tools.ToolResult, 1)
 	callback := func(ctx context.Context, res *tools.ToolResult) {
 		resultChan <- res
 	}
 
-	_, err := t.manager.Spawn(ctx, taskStr, label, t.originChannel, t.originChatID, callback)
+	task, _, err := t.manager.spawn(ctx, taskStr, label, t.originChannel, t.originChatID, callback, true)
 	if err != nil {
 		return tools.ErrorResult(fmt.Sprintf("Failed to delegate task: %v", err))
 	}
@@ -152,7 +138,10 @@ func (t *SubagentTool) Execute(ctx context.Context, args map[string]interface{})
 	case result := <-resultChan:
 		return result
 	case <-ctx.Done():
-		return tools.ErrorResult("Delegated task cancelled or timed out")
+		if !t.manager.stopWaiting(task) {
+			return <-resultChan
+		}
+		return tools.ErrorResult(fmt.Sprintf("Delegated task cancelled or timed out; agent %s keeps running and its result will be announced", task.ID))
 	}
 }
 
@@ -204,9 +193,14 @@ func (t *SwarmTool) Execute(ctx context.Context, args map[string]interface{}) *t
 			return &tools.ToolResult{ForLLM: "No active swarm agents."}
 		}
 		out := "Active Swarm Agents:\n"
-		for _, a := range agents {
-			out += fmt.Sprintf("- [%s] %s (Task: %s) - Status: %s\n", a.ID, a.Label, a.Task, a.Status)
+		var write func(nodes []*AgentNode, indent string)
+		write = func(nodes []*AgentNode, indent string) {
+			for _, a := range nodes {
+				out += fmt.Sprintf("%s- [%s] %s (Task: %s) - Status: %s\n", indent, a.ID, a.Label, a.Task, a.Status)
+				write(a.Children, indent+"  ")
+			}
 		}
+		write(t.manager.AgentTree(), "")
 		return &tools.ToolResult{ForLLM: out, ForUser: out}
 
 	case "status":
@@ -236,3 +230,88 @@ func (t *SwarmTool) Execute(ctx context.Context, args map[string]interface{}) *t
 		return tools.ErrorResult("Unknown action")
 	}
 }
+
+// ScratchpadTool gives the agents of one task tree a shared key-value
+// scratchpad, so they can post partial results and read each other's.
+type ScratchpadTool struct {
+	manager *Manager
+}
+
+func NewScratchpadTool(manager *Manager) *ScratchpadTool {
+	return &ScratchpadTool{
+		manager: manager,
+	}
+}
+
+func (t *ScratchpadTool) Name() string {
+	return "scratchpad"
+}
+
+func (t *ScratchpadTool) Description() string {
+	return "Share intermediate results with the other agents working on the same task: post a value, read one, or list everything posted so far."
+}
+
+func (t *ScratchpadTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"action": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"scratch_set", "scratch_get", "scratch_list"},
+				"description": "Action to perform",
+			},
+			"key": map[string]interface{}{
+				"type":        "string",
+				"description": "Entry name (for scratch_set/scratch_get)",
+			},
+			"value": map[string]interface{}{
+				"type":        "string",
+				"description": "Value to post (for scratch_set)",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *ScratchpadTool) Execute(ctx context.Context, args map[string]interface{}) *tools.ToolResult {
+	action, _ := args["action"].(string)
+	key, _ := args["key"].(string)
+
+	switch action {
+	case "scratch_set":
+		value, _ := args["value"].(string)
+		if err := t.manager.ScratchSet(ctx, key, value); err != nil {
+			return tools.ErrorResult(fmt.Sprintf("Failed to write scratchpad: %v", err))
+		}
+		return tools.SilentResult(fmt.Sprintf("Posted %q to the scratchpad.", key))
+
+	case "scratch_get":
+		if key == "" {
+			return tools.ErrorResult("key required for scratch_get")
+		}
+		value, ok := t.manager.ScratchGet(ctx, key)
+		if !ok {
+			return tools.NewToolResult(fmt.Sprintf("Nothing posted under %q yet.", key))
+		}
+		return tools.NewToolResult(value)
+
+	case "scratch_list":
+		entries := t.manager.ScratchList(ctx)
+		if len(entries) == 0 {
+			return tools.NewToolResult("The scratchpad is empty.")
+		}
+		keys := make([]string, 0, len(entries))
+		for k := range entries {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		out := "Scratchpad:\n"
+		for _, k := range keys {
+			out += fmt.Sprintf("- %s: %s\n", k, entries[k])
+		}
+		return tools.NewToolResult(out)
+
+	default:
+		return tools.ErrorResult("Unknown action")
+	}
+}
@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"strings"
+
+	"github.com/Sterlites/RDxClaw/pkg/providers"
+)
+
+// FinishReasonLength is the finish reason of a response the provider cut
+// off at max_tokens.
+const FinishReasonLength = "length"
+
+// ContinuePrompt asks a model whose reply was cut off to carry on.
+const ContinuePrompt = "Your reply was cut off. Continue exactly where you stopped, without repeating anything."
+
+// Continuation assembles the final reply of a tool loop from responses the
+// provider cut off at max_tokens, asking the model to continue up to Max
+// times. The zero value never continues; it only detects the truncation.
+type Continuation struct {
+	Max int
+
+	count   int
+	pending bool // the last response asked for a continuation
+	cut     bool // a response was cut off with no continuations left
+	content strings.Builder
+}
+
+// Add records a response without tool calls. If the response was cut off
+// and a continuation remains, Add returns messages extended with the
+// partial reply and a request to continue, and true; the loop should call
+// the model again with them.
+func (c *Continuation) Add(messages []providers.Message, response *providers.LLMResponse) ([]providers.Message, bool) {
+	c.content.WriteString(response.Content)
+	c.pending = false
+	if response.FinishReason != FinishReasonLength {
+		return messages, false
+	}
+	if c.count >= c.Max {
+		c.cut = true
+		return messages, false
+	}
+	c.count++
+	c.pending = true
+	return append(messages,
+		providers.Message{Role: "assistant", Content: response.Content},
+		providers.Message{Role: "user", Content: ContinuePrompt},
+	), true
+}
+
+// Content returns the reply stitched from every response added.
+func (c *Continuation) Content() string {
+	return c.content.String()
+}
+
+// Count returns how many continuations were requested.
+func (c *Continuation) Count() int {
+	return c.count
+}
+
+// Truncated reports whether the reply is incomplete: the last response was
+// cut off with no continuations left, or the loop ran out of iterations
+// before a requested continuation.
+func (c *Continuation) Truncated() bool {
+	return c.cut || c.pending
+}
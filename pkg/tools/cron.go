@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/Sterlites/RDxClaw/pkg/bus"
+	"github.com/Sterlites/RDxClaw/pkg/constants"
 	"github.com/Sterlites/RDxClaw/pkg/cron"
 	"github.com/Sterlites/RDxClaw/pkg/utils"
 )
@@ -16,15 +17,30 @@ type JobExecutor interface {
 	ProcessDirectWithChannel(ctx context.Context, content, sessionKey, channel, chatID string) (string, error)
 }
 
-// CronTool provides scheduling capabilities for the agent
+// ChannelSender delivers a message to a chat on a channel and reports
+// whether it was sent, unlike publishing to the outbound bus.
+type ChannelSender interface {
+	SendToChannel(ctx context.Context, channelName, chatID, content string) error
+}
+
+// DefaultMaxAgentCronJobs caps how many jobs the agent may have scheduled
+// at once.
+const DefaultMaxAgentCronJobs = 20
+
+// CronTool provides scheduling capabilities for the agent. Jobs it creates
+// are tagged cron.CreatedByAgent, and it can only remove, enable or
+// disable those jobs.
 type CronTool struct {
 	cronService *cron.CronService
 	executor    JobExecutor
 	msgBus      *bus.MessageBus
+	sender      ChannelSender
 	execTool    *ExecTool
 	channel     string
 	chatID      string
+	maxJobs     int
 	mu          sync.RWMutex
+	addMu       sync.Mutex // makes the job cap check and the add atomic
 }
 
 // NewCronTool creates a new CronTool
@@ -34,9 +50,21 @@ func NewCronTool(cronService *cron.CronService, executor JobExecutor, msgBus *bu
 		executor:    executor,
 		msgBus:      msgBus,
 		execTool:    NewExecTool(workspace, restrict),
+		maxJobs:     DefaultMaxAgentCronJobs,
 	}
 }
 
+// SetMaxAgentJobs sets how many agent-created jobs may exist at once.
+// Values <= 0 restore DefaultMaxAgentCronJobs.
+func (t *CronTool) SetMaxAgentJobs(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if n <= 0 {
+		n = DefaultMaxAgentCronJobs
+	}
+	t.maxJobs = n
+}
+
 // Name returns the tool name
 func (t *CronTool) Name() string {
 	return "cron"
@@ -44,7 +72,7 @@ func (t *CronTool) Name() string {
 
 // Description returns the tool description
 func (t *CronTool) Description() string {
-	return "Schedule reminders, tasks, or system commands. IMPORTANT: When user asks to be reminded or scheduled, you MUST call this tool. Use 'at_seconds' for one-time reminders (e.g., 'remind me in 10 minutes' → at_seconds=600). Use 'every_seconds' ONLY for recurring tasks (e.g., 'every 2 hours' → every_seconds=7200). Use 'cron_expr' for complex recurring schedules. Use 'command' to execute shell commands directly."
+	return "Schedule reminders, tasks, or system commands. IMPORTANT: When user asks to be reminded or scheduled, you MUST call this tool. Use 'at_seconds' for one-time reminders (e.g., 'remind me in 10 minutes' → at_seconds=600). Use 'every_seconds' ONLY for recurring tasks (e.g., 'every 2 hours' → every_seconds=7200). Use 'cron_expr' for complex recurring schedules. Use 'command' to execute shell commands directly. Only jobs you created can be removed, enabled or disabled, and the number of jobs you may have scheduled is limited."
 }
 
 // Parameters returns the tool parameters schema
@@ -90,6 +118,14 @@ func (t *CronTool) Parameters() map[string]interface{} {
 	}
 }
 
+// SetSender sets how job output is delivered. Without a sender, output is
+// published to the outbound bus and every delivery counts as successful.
+func (t *CronTool) SetSender(sender ChannelSender) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.sender = sender
+}
+
 // SetContext sets the current session context for job creation
 func (t *CronTool) SetContext(channel, chatID string) {
 	t.mu.Lock()
@@ -138,7 +174,8 @@ func (t *CronTool) addJob(args map[string]interface{}) *ToolResult {
 
 	var schedule cron.CronSchedule
 
-	// Check for at_seconds (one-time), every_seconds (recurring), or cron_expr
+	// Check for at_seconds (one-time), every_seconds (recurring), or cron_expr.
+	// Bad values are rejected by cron.ValidateSchedule below.
 	atSeconds, hasAt := args["at_seconds"].(float64)
 	everySeconds, hasEvery := args["every_seconds"].(float64)
 	cronExpr, hasCron := args["cron_expr"].(string)
@@ -164,6 +201,9 @@ func (t *CronTool) addJob(args map[string]interface{}) *ToolResult {
 	} else {
 		return ErrorResult("one of at_seconds, every_seconds, or cron_expr is required")
 	}
+	if err := cron.ValidateSchedule(schedule, time.Now().UnixMilli()); err != nil {
+		return ErrorResult(fmt.Sprintf("invalid schedule: %v", err))
+	}
 
 	// Read deliver parameter, default to true
 	deliver := true
@@ -178,6 +218,23 @@ func (t *CronTool) addJob(args map[string]interface{}) *ToolResult {
 		// But for our new logic in ExecuteJob, we can handle it regardless of deliver flag if Payload.Command is set.
 		// However, logically, it's not "delivered" to chat directly as is.
 		deliver = false
+
+		// Check the command against the exec guard (including
+		// RestrictToWorkspace) now rather than when it first runs.
+		if guardError := t.execTool.guardCommand(command, t.execTool.workingDir); guardError != "" {
+			return ErrorResult(guardError)
+		}
+	}
+
+	t.mu.RLock()
+	maxJobs := t.maxJobs
+	t.mu.RUnlock()
+
+	t.addMu.Lock()
+	defer t.addMu.Unlock()
+
+	if n := t.cronService.CountJobsCreatedBy(cron.CreatedByAgent); n >= maxJobs {
+		return ErrorResult(fmt.Sprintf("too many scheduled jobs (%d of %d); remove one before adding another", n, maxJobs))
 	}
 
 	// Truncate message for job name (max 30 chars)
@@ -195,10 +252,10 @@ func (t *CronTool) addJob(args map[string]interface{}) *ToolResult {
 		return ErrorResult(fmt.Sprintf("Error adding job: %v", err))
 	}
 
-	if command != "" {
-		job.Payload.Command = command
-		// Need to save the updated payload
-		t.cronService.UpdateJob(job)
+	job.Payload.Command = command
+	job.CreatedBy = cron.CreatedByAgent
+	if err := t.cronService.UpdateJob(job); err != nil {
+		return ErrorResult(fmt.Sprintf("Error saving job: %v", err))
 	}
 
 	return SilentResult(fmt.Sprintf("Cron job added: %s (id: %s)", job.Name, job.ID))
@@ -223,7 +280,11 @@ func (t *CronTool) listJobs() *ToolResult {
 		} else {
 			scheduleInfo = "unknown"
 		}
-		result += fmt.Sprintf("- %s (id: %s, %s)\n", j.Name, j.ID, scheduleInfo)
+		owner := ""
+		if j.CreatedBy == cron.CreatedByAgent {
+			owner = ", created by you"
+		}
+		result += fmt.Sprintf("- %s (id: %s, %s%s)\n", j.Name, j.ID, scheduleInfo, owner)
 	}
 
 	return SilentResult(result)
@@ -234,6 +295,9 @@ func (t *CronTool) removeJob(args map[string]interface{}) *ToolResult {
 	if !ok || jobID == "" {
 		return ErrorResult("job_id is required for remove")
 	}
+	if result := t.checkAgentJob(jobID); result != nil {
+		return result
+	}
 
 	if t.cronService.RemoveJob(jobID) {
 		return SilentResult(fmt.Sprintf("Cron job removed: %s", jobID))
@@ -246,6 +310,9 @@ func (t *CronTool) enableJob(args map[string]interface{}, enable bool) *ToolResu
 	if !ok || jobID == "" {
 		return ErrorResult("job_id is required for enable/disable")
 	}
+	if result := t.checkAgentJob(jobID); result != nil {
+		return result
+	}
 
 	job := t.cronService.EnableJob(jobID, enable)
 	if job == nil {
@@ -259,18 +326,37 @@ func (t *CronTool) enableJob(args map[string]interface{}, enable bool) *ToolResu
 	return SilentResult(fmt.Sprintf("Cron job '%s' %s", job.Name, status))
 }
 
-// ExecuteJob executes a cron job through the agent
-func (t *CronTool) ExecuteJob(ctx context.Context, job *cron.CronJob) string {
-	// Get channel/chatID from job payload
-	channel := job.Payload.Channel
-	chatID := job.Payload.To
+// checkAgentJob returns an error result unless jobID names a job the agent
+// created itself.
+func (t *CronTool) checkAgentJob(jobID string) *ToolResult {
+	for _, j := range t.cronService.ListJobs(true) {
+		if j.ID != jobID {
+			continue
+		}
+		if j.CreatedBy != cron.CreatedByAgent {
+			return ErrorResult(fmt.Sprintf("Job %s was not created by the agent; the user can change it with 'rdxclaw cron'", jobID))
+		}
+		return nil
+	}
+	return ErrorResult(fmt.Sprintf("Job %s not found", jobID))
+}
 
-	// Default values if not set
-	if channel == "" {
-		channel = "cli"
+// ExecuteJob executes a cron job through the agent. Output is delivered to
+// every target of the job and the outcome per target is recorded in
+// job.State.LastDeliveries.
+func (t *CronTool) ExecuteJob(ctx context.Context, job *cron.CronJob) string {
+	targets := job.Payload.DeliveryTargets()
+	if len(targets) == 0 {
+		targets = []cron.DeliveryTarget{{}}
 	}
-	if chatID == "" {
-		chatID = "direct"
+	for i := range targets {
+		// Default values if not set
+		if targets[i].Channel == "" {
+			targets[i].Channel = "cli"
+		}
+		if targets[i].To == "" {
+			targets[i].To = "direct"
+		}
 	}
 
 	// Execute command if present
@@ -287,41 +373,60 @@ func (t *CronTool) ExecuteJob(ctx context.Context, job *cron.CronJob) string {
 			output = fmt.Sprintf("Scheduled command '%s' executed:\n%s", job.Payload.Command, result.ForLLM)
 		}
 
-		t.msgBus.PublishOutbound(bus.OutboundMessage{
-			Channel: channel,
-			ChatID:  chatID,
-			Content: output,
-		})
+		job.State.LastDeliveries = t.deliver(ctx, targets, output)
 		return "ok"
 	}
 
 	// If deliver=true, send message directly without agent processing
 	if job.Payload.Deliver {
-		t.msgBus.PublishOutbound(bus.OutboundMessage{
-			Channel: channel,
-			ChatID:  chatID,
-			Content: job.Payload.Message,
-		})
+		job.State.LastDeliveries = t.deliver(ctx, targets, job.Payload.Message)
 		return "ok"
 	}
 
 	// For deliver=false, process through agent (for complex tasks)
 	sessionKey := fmt.Sprintf("cron-%s", job.ID)
 
-	// Call agent with job's message
+	// Call agent with job's message, in the context of the first target
 	response, err := t.executor.ProcessDirectWithChannel(
 		ctx,
 		job.Payload.Message,
 		sessionKey,
-		channel,
-		chatID,
+		targets[0].Channel,
+		targets[0].To,
 	)
 
 	if err != nil {
 		return fmt.Sprintf("Error: %v", err)
 	}
 
-	// Response is automatically sent via MessageBus by AgentLoop
-	_ = response // Will be sent by AgentLoop
+	// ProcessDirectWithChannel returns the agent's answer without sending
+	// it, so deliver it to every target, the only one included.
+	if response != "" {
+		job.State.LastDeliveries = t.deliver(ctx, targets, response)
+	}
 	return "ok"
 }
+
+// deliver sends content to every target and reports how each went.
+func (t *CronTool) deliver(ctx context.Context, targets []cron.DeliveryTarget, content string) []cron.DeliveryResult {
+	t.mu.RLock()
+	sender := t.sender
+	t.mu.RUnlock()
+
+	results := make([]cron.DeliveryResult, 0, len(targets))
+	for _, target := range targets {
+		result := cron.DeliveryResult{Channel: target.Channel, To: target.To, Status: "ok"}
+		if sender == nil || constants.IsInternalChannel(target.Channel) {
+			t.msgBus.PublishOutbound(bus.OutboundMessage{
+				Channel: target.Channel,
+				ChatID:  target.To,
+				Content: content,
+			})
+		} else if err := sender.SendToChannel(ctx, target.Channel, target.To, content); err != nil {
+			result.Status = "error"
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results
+}
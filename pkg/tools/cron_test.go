@@ -0,0 +1,207 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/Sterlites/RDxClaw/pkg/bus"
+	"github.com/Sterlites/RDxClaw/pkg/cron"
+)
+
+type fakeSender struct {
+	failOn string
+	sent   []string
+}
+
+func (s *fakeSender) SendToChannel(ctx context.Context, channelName, chatID, content string) error {
+	if channelName == s.failOn {
+		return errors.New("channel unavailable")
+	}
+	s.sent = append(s.sent, channelName+":"+chatID+":"+content)
+	return nil
+}
+
+func TestCronTool_ExecuteJobFanOut(t *testing.T) {
+	workspace := t.TempDir()
+	cs := cron.NewCronService(filepath.Join(workspace, "jobs.json"), nil)
+	tool := NewCronTool(cs, nil, bus.NewMessageBus(), workspace, true)
+	sender := &fakeSender{failOn: "email"}
+	tool.SetSender(sender)
+
+	job := &cron.CronJob{
+		ID: "job1",
+		Payload: cron.CronPayload{
+			Kind:    "agent_turn",
+			Message: "Good morning",
+			Deliver: true,
+			Targets: []cron.DeliveryTarget{
+				{Channel: "slack", To: "C1"},
+				{Channel: "email", To: "ops@example.com"},
+				{Channel: "telegram", To: "42"},
+			},
+		},
+	}
+
+	if got := tool.ExecuteJob(context.Background(), job); got != "ok" {
+		t.Fatalf("ExecuteJob() = %q, want ok", got)
+	}
+
+	want := []string{"slack:C1:Good morning", "telegram:42:Good morning"}
+	if len(sender.sent) != len(want) || sender.sent[0] != want[0] || sender.sent[1] != want[1] {
+		t.Errorf("sent = %v, want %v", sender.sent, want)
+	}
+
+	results := job.State.LastDeliveries
+	if len(results) != 3 {
+		t.Fatalf("LastDeliveries = %+v, want 3 entries", results)
+	}
+	for i, status := range []string{"ok", "error", "ok"} {
+		if results[i].Status != status {
+			t.Errorf("delivery %d (%s) status = %q, want %q", i, results[i].Channel, results[i].Status, status)
+		}
+	}
+	if results[1].Error != "channel unavailable" {
+		t.Errorf("delivery error = %q", results[1].Error)
+	}
+}
+
+type fakeExecutor struct {
+	response string
+}
+
+func (e *fakeExecutor) ProcessDirectWithChannel(ctx context.Context, content, sessionKey, channel, chatID string) (string, error) {
+	return e.response, nil
+}
+
+func TestCronTool_ExecuteJobDeliversAgentReply(t *testing.T) {
+	workspace := t.TempDir()
+	cs := cron.NewCronService(filepath.Join(workspace, "jobs.json"), nil)
+	tool := NewCronTool(cs, &fakeExecutor{response: "Summary ready"}, bus.NewMessageBus(), workspace, true)
+	sender := &fakeSender{}
+	tool.SetSender(sender)
+
+	job := &cron.CronJob{
+		ID: "job1",
+		Payload: cron.CronPayload{
+			Kind:    "agent_turn",
+			Message: "summarize",
+			Deliver: false,
+			Channel: "slack",
+			To:      "C1",
+		},
+	}
+	if got := tool.ExecuteJob(context.Background(), job); got != "ok" {
+		t.Fatalf("ExecuteJob() = %q, want ok", got)
+	}
+	if len(sender.sent) != 1 || sender.sent[0] != "slack:C1:Summary ready" {
+		t.Errorf("sent = %v, want the agent's reply delivered to the single target", sender.sent)
+	}
+	if len(job.State.LastDeliveries) != 1 || job.State.LastDeliveries[0].Status != "ok" {
+		t.Errorf("LastDeliveries = %+v, want one ok delivery", job.State.LastDeliveries)
+	}
+}
+
+func newAgentCronTool(t *testing.T) (*CronTool, *cron.CronService) {
+	t.Helper()
+	workspace := t.TempDir()
+	cs := cron.NewCronService(filepath.Join(workspace, "jobs.json"), nil)
+	tool := NewCronTool(cs, nil, bus.NewMessageBus(), workspace, true)
+	tool.SetContext("telegram", "42")
+	return tool, cs
+}
+
+func TestCronTool_AddTagsAgentJobs(t *testing.T) {
+	tool, cs := newAgentCronTool(t)
+
+	result := tool.Execute(context.Background(), map[string]interface{}{
+		"action":        "add",
+		"message":       "stretch",
+		"every_seconds": float64(3600),
+	})
+	if result.IsError {
+		t.Fatalf("add failed: %s", result.ForLLM)
+	}
+
+	jobs := cs.ListJobs(true)
+	if len(jobs) != 1 || jobs[0].CreatedBy != cron.CreatedByAgent {
+		t.Fatalf("jobs = %+v, want one agent-created job", jobs)
+	}
+
+	// Jobs created by a user cannot be touched through the tool.
+	userJob, err := cs.AddJob("backup", cron.CronSchedule{Kind: "cron", Expr: "0 3 * * *"}, "backup", true, "cli", "direct")
+	if err != nil {
+		t.Fatalf("AddJob failed: %v", err)
+	}
+	result = tool.Execute(context.Background(), map[string]interface{}{"action": "remove", "job_id": userJob.ID})
+	if !result.IsError {
+		t.Error("removing a user-created job should fail")
+	}
+	result = tool.Execute(context.Background(), map[string]interface{}{"action": "remove", "job_id": jobs[0].ID})
+	if result.IsError {
+		t.Errorf("removing an agent-created job failed: %s", result.ForLLM)
+	}
+
+	if n := cs.RemoveJobsCreatedBy(cron.CreatedByAgent); n != 0 {
+		t.Errorf("RemoveJobsCreatedBy removed %d jobs, want 0", n)
+	}
+	if n := len(cs.ListJobs(true)); n != 1 {
+		t.Errorf("%d jobs left, want the user job only", n)
+	}
+}
+
+func TestCronTool_AgentJobCap(t *testing.T) {
+	tool, cs := newAgentCronTool(t)
+	tool.SetMaxAgentJobs(2)
+
+	// User jobs do not count towards the cap.
+	if _, err := cs.AddJob("user", cron.CronSchedule{Kind: "cron", Expr: "0 9 * * *"}, "hi", true, "cli", "direct"); err != nil {
+		t.Fatalf("AddJob failed: %v", err)
+	}
+
+	add := func() *ToolResult {
+		return tool.Execute(context.Background(), map[string]interface{}{
+			"action":    "add",
+			"message":   "ping",
+			"cron_expr": "*/5 * * * *",
+		})
+	}
+	for i := 0; i < 2; i++ {
+		if result := add(); result.IsError {
+			t.Fatalf("add %d failed: %s", i, result.ForLLM)
+		}
+	}
+	if result := add(); !result.IsError {
+		t.Fatal("add beyond the cap should fail")
+	}
+	if n := cs.CountJobsCreatedBy(cron.CreatedByAgent); n != 2 {
+		t.Errorf("%d agent jobs, want 2", n)
+	}
+}
+
+func TestCronTool_AddValidation(t *testing.T) {
+	tool, cs := newAgentCronTool(t)
+
+	tests := map[string]map[string]interface{}{
+		"bad cron expression": {"cron_expr": "every morning"},
+		"negative interval":   {"every_seconds": float64(-5)},
+		"past one-time":       {"at_seconds": float64(-60)},
+		"command outside workspace": {
+			"every_seconds": float64(3600),
+			"command":       "cat /etc/passwd",
+		},
+	}
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			args["action"] = "add"
+			args["message"] = "job"
+			if result := tool.Execute(context.Background(), args); !result.IsError {
+				t.Errorf("add succeeded, want error")
+			}
+		})
+	}
+	if n := len(cs.ListJobs(true)); n != 0 {
+		t.Errorf("%d jobs created, want 0", n)
+	}
+}
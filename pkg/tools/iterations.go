@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+)
+
+// MaxIterationsLimit is the hard upper bound on tool loop iterations. Each
+// iteration is an LLM call, so an unbounded cap from a request or a skill
+// manifest could run up cost or hold a turn open indefinitely.
+const MaxIterationsLimit = 100
+
+type maxIterationsKey struct{}
+
+// WithMaxIterations returns a copy of ctx carrying a tool loop iteration cap
+// that overrides the configured default. Values above MaxIterationsLimit are
+// clamped; zero or less means the default, so ctx is returned unchanged.
+func WithMaxIterations(ctx context.Context, n int) context.Context {
+	n = ClampMaxIterations(n)
+	if n == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, maxIterationsKey{}, n)
+}
+
+// MaxIterationsFromContext returns the iteration cap carried by ctx, or def
+// if none was set.
+func MaxIterationsFromContext(ctx context.Context, def int) int {
+	if ctx != nil {
+		if n, ok := ctx.Value(maxIterationsKey{}).(int); ok {
+			return n
+		}
+	}
+	return def
+}
+
+// ValidateMaxIterations reports whether n is an acceptable iteration cap.
+// Zero means "use the default" and is accepted.
+func ValidateMaxIterations(n int) error {
+	if n < 0 || n > MaxIterationsLimit {
+		return fmt.Errorf("max_iterations must be between 1 and %d", MaxIterationsLimit)
+	}
+	return nil
+}
+
+// ClampMaxIterations bounds n to MaxIterationsLimit. Zero or less returns
+// 0, which callers read as "use the default".
+func ClampMaxIterations(n int) int {
+	if n < 1 {
+		return 0
+	}
+	if n > MaxIterationsLimit {
+		return MaxIterationsLimit
+	}
+	return n
+}
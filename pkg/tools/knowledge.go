@@ -2,26 +2,38 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 
 	"github.com/Sterlites/RDxClaw/pkg/knowledge"
 )
 
 // KnowledgeTool provides access to the corporate memory/knowledge base.
 type KnowledgeTool struct {
-	store *knowledge.Store
+	store     *knowledge.Store
+	workspace string
+	restrict  bool
 }
 
-// NewKnowledgeTool creates a new knowledge tool instance.
-func NewKnowledgeTool(store *knowledge.Store) *KnowledgeTool {
+// NewKnowledgeTool creates a new knowledge tool instance. When restrict is
+// set, only files inside workspace can be ingested.
+func NewKnowledgeTool(store *knowledge.Store, workspace string, restrict bool) *KnowledgeTool {
 	return &KnowledgeTool{
-		store: store,
+		store:     store,
+		workspace: workspace,
+		restrict:  restrict,
 	}
 }
 
+// Store returns the knowledge store the tool reads and writes.
+func (t *KnowledgeTool) Store() *knowledge.Store {
+	return t.store
+}
+
 func (t *KnowledgeTool) Name() string {
 	return "knowledge"
 }
@@ -32,7 +44,8 @@ Use this to find existing information or save new knowledge for future recall.
 Capabilities:
 - search: Find relevant information using keywords (BM25)
 - add: Save text snippets or summaries
-- ingest: Read and index a file (markdown, text, etc.)
+- ingest: Read and index a file (markdown, text, etc.) or every file under a directory
+- delete: Remove a document, e.g. one whose source file is gone or stale
 - list: List available knowledge collections`
 }
 
@@ -42,8 +55,8 @@ func (t *KnowledgeTool) Parameters() map[string]interface{} {
 		"properties": map[string]interface{}{
 			"action": map[string]interface{}{
 				"type":        "string",
-				"enum":        []string{"search", "add", "ingest", "list"},
-				"description": "The action to perform: 'search', 'add', 'ingest', or 'list'",
+				"enum":        []string{"search", "add", "ingest", "delete", "list"},
+				"description": "The action to perform: 'search', 'add', 'ingest', 'delete', or 'list'",
 			},
 			"collection": map[string]interface{}{
 				"type":        "string",
@@ -63,12 +76,32 @@ func (t *KnowledgeTool) Parameters() map[string]interface{} {
 			},
 			"path": map[string]interface{}{
 				"type":        "string",
-				"description": "Absolute path to file to ingest (for action='ingest')",
+				"description": "Path to a file, or a directory whose files are ingested recursively (for action='ingest'); for action='delete', the ingested file whose document to remove",
+			},
+			"doc_id": map[string]interface{}{
+				"type":        "string",
+				"description": "Document ID for the ingested file; re-ingesting with the same ID replaces the previous version (for action='ingest' of a file, default: derived from the path). For action='delete', the document to remove",
+			},
+			"upsert": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Also replace documents previously ingested from the same file under another ID (for action='ingest')",
 			},
 			"limit": map[string]interface{}{
 				"type":        "integer",
 				"description": "Max number of results to return (default: 5)",
 			},
+			"offset": map[string]interface{}{
+				"type":        "integer",
+				"description": "Number of results to skip, to page through more matches (for action='search', default: 0)",
+			},
+			"group_by_document": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Return the best chunks per document instead of per chunk, so one document cannot fill every result; limit then counts documents (for action='search')",
+			},
+			"chunks_per_document": map[string]interface{}{
+				"type":        "integer",
+				"description": "Chunks to keep per document when grouping (default: 1)",
+			},
 		},
 		"required": []string{"action"},
 	}
@@ -88,6 +121,8 @@ func (t *KnowledgeTool) Execute(ctx context.Context, args map[string]interface{}
 		return t.handleAdd(args, collection)
 	case "ingest":
 		return t.handleIngest(args, collection)
+	case "delete":
+		return t.handleDelete(args, collection)
 	case "list":
 		return t.handleList()
 	default:
@@ -103,14 +138,34 @@ func (t *KnowledgeTool) handleSearch(args map[string]interface{}, collection str
 
 	limit := 5
 	if l, ok := args["limit"].(float64); ok {
-		limit = int(l)
+		limit = max(int(l), 1)
 	}
 
-	results, err := t.store.Search(collection, query, limit)
+	opts := knowledge.SearchOptions{Limit: limit}
+	if n, ok := args["offset"].(float64); ok && n > 0 {
+		opts.Offset = int(n)
+	}
+	if grouped, _ := args["group_by_document"].(bool); grouped {
+		opts.PerDoc = 1
+		if n, ok := args["chunks_per_document"].(float64); ok && n >= 1 {
+			opts.PerDoc = int(n)
+		}
+	}
+	page, err := t.store.SearchPaged(collection, query, opts)
+	results := page.Results
+	if errors.Is(err, knowledge.ErrEmptyQuery) {
+		return ErrorResult(fmt.Sprintf("cannot search for '%s': %v; use words or numbers", query, err))
+	}
 	if err != nil {
 		return ErrorResult(fmt.Sprintf("search failed: %v", err))
 	}
 
+	if len(results) == 0 && page.Total > 0 {
+		return &ToolResult{
+			ForLLM:  fmt.Sprintf("No results past offset %d for '%s' in collection '%s'; it has %d matches.", opts.Offset, query, collection, page.Total),
+			ForUser: fmt.Sprintf("🔍 Searched '%s' in '%s': No more matches.", query, collection),
+		}
+	}
 	if len(results) == 0 {
 		return &ToolResult{
 			ForLLM:  fmt.Sprintf("No results found for '%s' in collection '%s'.", query, collection),
@@ -118,15 +173,30 @@ func (t *KnowledgeTool) handleSearch(args map[string]interface{}, collection str
 		}
 	}
 
-	// Format results for LLM
-	var llmOutput string
+	// Format results for LLM. Citations let the agent answer "according
+	// to <file>:<range>".
+	llmOutput := "Cite sources by their Citation when answering from these results.\n"
+	unit, shown := "results", len(results)
+	if opts.PerDoc > 0 {
+		// Pages of grouped results count documents, not chunks
+		unit, shown = "documents", min(opts.Limit, page.Total-opts.Offset)
+	}
+	llmOutput += fmt.Sprintf("Showing %s %d-%d of %d.", unit, opts.Offset+1, opts.Offset+shown, page.Total)
+	if next := opts.Offset + shown; next < page.Total {
+		llmOutput += fmt.Sprintf(" Search again with offset=%d for more.", next)
+	}
+	llmOutput += "\n\n"
 	for i, res := range results {
-		llmOutput += fmt.Sprintf("Result %d (Score: %.2f)\nSource: %s\nContent:\n%s\n\n---\n\n",
-			i+1, res.Score, res.Source, res.Chunk.Content)
+		score := fmt.Sprintf("Score: %.2f", res.Score)
+		if res.DocumentScore > 0 {
+			score += fmt.Sprintf(", Document: %s, Document Score: %.2f", res.DocumentID, res.DocumentScore)
+		}
+		llmOutput += fmt.Sprintf("Result %d (%s)\nSource: %s\nCitation: %s\nContent:\n%s\n\n---\n\n",
+			i+1, score, res.Source, res.Chunk.Citation(), res.Chunk.Content)
 	}
 
 	// Simplified summary for user
-	userOutput := fmt.Sprintf("🔍 Found %d results for '%s' in '%s':\n", len(results), query, collection)
+	userOutput := fmt.Sprintf("🔍 Found %d results for '%s' in '%s':\n", page.Total, query, collection)
 	for i, res := range results {
 		if i >= 3 {
 			break // Show max 3 to user
@@ -183,20 +253,34 @@ func (t *KnowledgeTool) handleIngest(args map[string]interface{}, collection str
 	if path == "" {
 		return ErrorResult("path is required for ingest action")
 	}
+	path, err := validatePath(path, t.workspace, t.restrict)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return t.handleIngestDir(args, collection, path)
+	}
 
-	content, err := os.ReadFile(path)
+	// Stream the file into the index so large files are never held whole
+	f, err := os.Open(path)
 	if err != nil {
 		return ErrorResult(fmt.Sprintf("failed to read file: %v", err))
 	}
+	defer f.Close()
 
 	filename := filepath.Base(path)
 	ext := filepath.Ext(filename)
 
+	docID, _ := args["doc_id"].(string)
+	if docID == "" {
+		docID = knowledge.FileDocumentID(path)
+	}
+
 	doc := knowledge.Document{
-		Title:   filename,
-		Content: string(content),
-		Source:  path,
-		Type:    ext,
+		ID:     docID,
+		Title:  filename,
+		Source: knowledge.FileSource(path),
+		Type:   ext,
 		Metadata: map[string]interface{}{
 			"title":    filename,
 			"filename": filename,
@@ -204,7 +288,11 @@ func (t *KnowledgeTool) handleIngest(args map[string]interface{}, collection str
 		},
 	}
 
-	if err := t.store.AddDocument(collection, doc); err != nil {
+	add := t.store.AddDocumentReader
+	if upsert, _ := args["upsert"].(bool); upsert {
+		add = t.store.UpsertDocumentReader
+	}
+	if err := add(collection, doc, f); err != nil {
 		return ErrorResult(fmt.Sprintf("failed to ingest document: %v", err))
 	}
 
@@ -214,6 +302,67 @@ func (t *KnowledgeTool) handleIngest(args map[string]interface{}, collection str
 	}
 }
 
+// handleIngestDir ingests every file under a directory, one document per
+// file, and reports the files that failed.
+func (t *KnowledgeTool) handleIngestDir(args map[string]interface{}, collection, dir string) *ToolResult {
+	if docID, _ := args["doc_id"].(string); docID != "" {
+		return ErrorResult("doc_id applies to a single file; documents ingested from a directory are identified by their paths")
+	}
+
+	ingest := t.store.IngestDir
+	if upsert, _ := args["upsert"].(bool); upsert {
+		ingest = t.store.UpsertDir
+	}
+	results, err := ingest(collection, dir)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to ingest directory: %v", err))
+	}
+
+	var failed []string
+	chunks := 0
+	for _, res := range results {
+		if res.Error != "" {
+			failed = append(failed, fmt.Sprintf("- %s: %s", res.Path, res.Error))
+			continue
+		}
+		chunks += res.Chunks
+	}
+	ingested := len(results) - len(failed)
+
+	llmOutput := fmt.Sprintf("Ingested %d of %d files from '%s' into collection '%s' (%d chunks).",
+		ingested, len(results), dir, collection, chunks)
+	if len(failed) > 0 {
+		llmOutput += "\nFailed files:\n" + strings.Join(failed, "\n")
+	}
+	return &ToolResult{
+		ForLLM:  llmOutput,
+		ForUser: fmt.Sprintf("📥 Ingested %d of %d files from '%s' into knowledge base '%s'.", ingested, len(results), dir, collection),
+	}
+}
+
+// handleDelete removes a document, named by doc_id or by the path it was
+// ingested from.
+func (t *KnowledgeTool) handleDelete(args map[string]interface{}, collection string) *ToolResult {
+	docID, _ := args["doc_id"].(string)
+	if docID == "" {
+		if path, _ := args["path"].(string); path != "" {
+			docID = knowledge.FileDocumentID(path)
+		}
+	}
+	if docID == "" {
+		return ErrorResult("doc_id or path is required for delete action")
+	}
+
+	if err := t.store.DeleteDocument(collection, docID); err != nil {
+		return ErrorResult(fmt.Sprintf("failed to delete document: %v", err))
+	}
+
+	return &ToolResult{
+		ForLLM:  fmt.Sprintf("Deleted document '%s' from collection '%s'.", docID, collection),
+		ForUser: fmt.Sprintf("🗑️ Deleted '%s' from knowledge base '%s'.", docID, collection),
+	}
+}
+
 func (t *KnowledgeTool) handleList() *ToolResult {
 	collections, err := t.store.ListCollections()
 	if err != nil {
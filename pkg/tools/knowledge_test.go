@@ -0,0 +1,170 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Sterlites/RDxClaw/pkg/knowledge"
+)
+
+func TestKnowledgeTool_ReingestReplacesDocument(t *testing.T) {
+	store, err := knowledge.NewStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tool := NewKnowledgeTool(store, "", false)
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "runbook.md")
+	for _, content := range []string{"Restart the legacy daemon", "Restart the modern service"} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		result := tool.Execute(ctx, map[string]interface{}{"action": "ingest", "path": path})
+		if result.IsError {
+			t.Fatalf("ingest failed: %s", result.ForLLM)
+		}
+	}
+
+	idx, err := store.GetIndex("general")
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs := map[string]bool{}
+	for _, chunk := range idx.Docs {
+		docs[chunk.DocumentID] = true
+	}
+	if len(docs) != 1 || !docs[knowledge.FileDocumentID(path)] {
+		t.Fatalf("Expected one document with the path-derived ID, got %v", docs)
+	}
+
+	if results, _ := store.Search("general", "legacy", 5); len(results) != 0 {
+		t.Errorf("Expected the previous version to be gone, got %d results", len(results))
+	}
+	if results, _ := store.Search("general", "modern", 5); len(results) != 1 {
+		t.Errorf("Expected the new version to be searchable, got %d results", len(results))
+	}
+
+	// An explicit ID keeps a second copy apart from the path-derived one
+	result := tool.Execute(ctx, map[string]interface{}{"action": "ingest", "path": path, "doc_id": "runbook-v2"})
+	if result.IsError {
+		t.Fatalf("ingest failed: %s", result.ForLLM)
+	}
+	if results, _ := store.Search("general", "modern", 5); len(results) != 2 {
+		t.Errorf("Expected two documents after ingesting with an explicit ID, got %d", len(results))
+	}
+
+	// Upserting replaces every copy ingested from the file
+	result = tool.Execute(ctx, map[string]interface{}{"action": "ingest", "path": path, "upsert": true})
+	if result.IsError {
+		t.Fatalf("ingest failed: %s", result.ForLLM)
+	}
+	if results, _ := store.Search("general", "modern", 5); len(results) != 1 {
+		t.Errorf("Expected one document after an upsert, got %d", len(results))
+	}
+}
+
+func TestKnowledgeTool_SearchWithoutTerms(t *testing.T) {
+	store, err := knowledge.NewStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tool := NewKnowledgeTool(store, "", false)
+
+	for _, query := range []string{"   ", "?!"} {
+		result := tool.Execute(context.Background(), map[string]interface{}{"action": "search", "query": query})
+		if !result.IsError {
+			t.Fatalf("Expected an error for query %q", query)
+		}
+		if !strings.Contains(result.ForLLM, knowledge.ErrEmptyQuery.Error()) {
+			t.Errorf("Expected the error to explain the query has no terms, got %q", result.ForLLM)
+		}
+	}
+}
+
+func TestKnowledgeTool_SearchLimitAtLeastOne(t *testing.T) {
+	store, err := knowledge.NewStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"a", "b"} {
+		if err := store.AddDocument("general", knowledge.Document{ID: id, Content: "shared term"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tool := NewKnowledgeTool(store, "", false)
+
+	for _, limit := range []float64{0, -2} {
+		result := tool.Execute(context.Background(), map[string]interface{}{"action": "search", "query": "shared", "limit": limit})
+		if result.IsError {
+			t.Fatalf("limit %v: search failed: %s", limit, result.ForLLM)
+		}
+		if !strings.Contains(result.ForLLM, "Showing results 1-1 of 2.") {
+			t.Errorf("limit %v: expected one result, got %q", limit, result.ForLLM)
+		}
+	}
+}
+
+func TestKnowledgeTool_Delete(t *testing.T) {
+	store, err := knowledge.NewStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tool := NewKnowledgeTool(store, "", false)
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "runbook.md")
+	if err := os.WriteFile(path, []byte("Restart the legacy daemon"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if result := tool.Execute(ctx, map[string]interface{}{"action": "ingest", "path": path}); result.IsError {
+		t.Fatalf("ingest failed: %s", result.ForLLM)
+	}
+
+	result := tool.Execute(ctx, map[string]interface{}{"action": "delete", "path": path})
+	if result.IsError {
+		t.Fatalf("delete failed: %s", result.ForLLM)
+	}
+	if results, _ := store.Search("general", "legacy", 5); len(results) != 0 {
+		t.Errorf("Expected the deleted document to be gone, got %d results", len(results))
+	}
+
+	result = tool.Execute(ctx, map[string]interface{}{"action": "delete", "doc_id": "missing"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "document not found") {
+		t.Errorf("Expected a not found error, got %q", result.ForLLM)
+	}
+}
+
+func TestKnowledgeTool_IngestConfinedToWorkspace(t *testing.T) {
+	store, err := knowledge.NewStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	workspace := t.TempDir()
+	tool := NewKnowledgeTool(store, workspace, true)
+	ctx := context.Background()
+
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "secret.md"), []byte("outside the workspace"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{outside, filepath.Join(outside, "secret.md"), "/", "../"} {
+		result := tool.Execute(ctx, map[string]interface{}{"action": "ingest", "path": path})
+		if !result.IsError || !strings.Contains(result.ForLLM, "access denied") {
+			t.Errorf("Expected ingesting %q to be denied, got %q", path, result.ForLLM)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Join(workspace, "docs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(workspace, "docs", "notes.md"), []byte("inside the workspace"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if result := tool.Execute(ctx, map[string]interface{}{"action": "ingest", "path": "docs"}); result.IsError {
+		t.Errorf("Expected a workspace directory to be ingested, got %q", result.ForLLM)
+	}
+}
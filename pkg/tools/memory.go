@@ -0,0 +1,131 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FactStore is the long-term key-value memory the memory tool operates on.
+// state.Manager implements it.
+type FactStore interface {
+	SetMemory(key, value string) error
+	GetMemory(key string) (string, bool)
+	DeleteMemory(key string) (bool, error)
+	ListMemory() map[string]string
+}
+
+// MemoryTool lets the agent remember short facts across sessions and restarts.
+type MemoryTool struct {
+	store FactStore
+}
+
+// NewMemoryTool creates a memory tool backed by store.
+func NewMemoryTool(store FactStore) *MemoryTool {
+	return &MemoryTool{store: store}
+}
+
+func (t *MemoryTool) Name() string {
+	return "memory"
+}
+
+func (t *MemoryTool) Description() string {
+	return `Remember short facts (user preferences, names, decisions) across sessions and restarts.
+Remembered facts are shown to you at the start of every turn.
+Capabilities:
+- set: Store a value under a key, replacing any previous value
+- get: Read the value stored under a key
+- list: List all remembered facts
+- delete: Forget a key`
+}
+
+func (t *MemoryTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"action": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"set", "get", "list", "delete"},
+				"description": "The action to perform: 'set', 'get', 'list', or 'delete'",
+			},
+			"key": map[string]interface{}{
+				"type":        "string",
+				"description": "Short identifier for the fact, e.g. 'user_timezone' (for set, get, delete)",
+			},
+			"value": map[string]interface{}{
+				"type":        "string",
+				"description": "The fact to remember (for action='set')",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *MemoryTool) Execute(ctx context.Context, args map[string]interface{}) *ToolResult {
+	action, _ := args["action"].(string)
+	key, _ := args["key"].(string)
+
+	switch action {
+	case "set":
+		value, _ := args["value"].(string)
+		if key == "" || value == "" {
+			return ErrorResult("key and value are required for set action")
+		}
+		if err := t.store.SetMemory(key, value); err != nil {
+			return ErrorResult(fmt.Sprintf("failed to remember %q: %v", key, err))
+		}
+		return SilentResult(fmt.Sprintf("Remembered %q.", key))
+	case "get":
+		if key == "" {
+			return ErrorResult("key is required for get action")
+		}
+		value, ok := t.store.GetMemory(key)
+		if !ok {
+			return NewToolResult(fmt.Sprintf("Nothing is remembered under %q.", key))
+		}
+		return NewToolResult(value)
+	case "list":
+		return NewToolResult(FormatFacts(t.store.ListMemory(), 0))
+	case "delete":
+		if key == "" {
+			return ErrorResult("key is required for delete action")
+		}
+		existed, err := t.store.DeleteMemory(key)
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("failed to forget %q: %v", key, err))
+		}
+		if !existed {
+			return NewToolResult(fmt.Sprintf("Nothing is remembered under %q.", key))
+		}
+		return SilentResult(fmt.Sprintf("Forgot %q.", key))
+	default:
+		return ErrorResult(fmt.Sprintf("unknown action: %s", action))
+	}
+}
+
+// FormatFacts renders facts as a sorted "- key: value" list. If maxChars is
+// positive, entries that would push the output past it are left out and
+// replaced by a note saying how many were omitted.
+func FormatFacts(facts map[string]string, maxChars int) string {
+	if len(facts) == 0 {
+		return "No facts remembered."
+	}
+
+	keys := make([]string, 0, len(facts))
+	for k := range facts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for i, k := range keys {
+		line := fmt.Sprintf("- %s: %s\n", k, facts[k])
+		if maxChars > 0 && sb.Len()+len(line) > maxChars {
+			fmt.Fprintf(&sb, "- (%d more not shown; use the memory tool to list them)\n", len(keys)-i)
+			break
+		}
+		sb.WriteString(line)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+type mapFactStore map[string]string
+
+func (m mapFactStore) SetMemory(key, value string) error { m[key] = value; return nil }
+
+func (m mapFactStore) GetMemory(key string) (string, bool) { v, ok := m[key]; return v, ok }
+
+func (m mapFactStore) DeleteMemory(key string) (bool, error) {
+	_, ok := m[key]
+	delete(m, key)
+	return ok, nil
+}
+
+func (m mapFactStore) ListMemory() map[string]string { return m }
+
+func TestMemoryTool_Actions(t *testing.T) {
+	store := mapFactStore{}
+	tool := NewMemoryTool(store)
+	ctx := context.Background()
+
+	result := tool.Execute(ctx, map[string]interface{}{"action": "set", "key": "language", "value": "Go"})
+	if result.IsError {
+		t.Fatalf("set failed: %s", result.ForLLM)
+	}
+
+	result = tool.Execute(ctx, map[string]interface{}{"action": "get", "key": "language"})
+	if result.ForLLM != "Go" {
+		t.Errorf("Expected 'Go', got %q", result.ForLLM)
+	}
+
+	result = tool.Execute(ctx, map[string]interface{}{"action": "list"})
+	if !strings.Contains(result.ForLLM, "- language: Go") {
+		t.Errorf("Expected list to contain the fact, got %q", result.ForLLM)
+	}
+
+	result = tool.Execute(ctx, map[string]interface{}{"action": "delete", "key": "language"})
+	if result.IsError || len(store) != 0 {
+		t.Errorf("Expected delete to remove the fact, got %q", result.ForLLM)
+	}
+
+	result = tool.Execute(ctx, map[string]interface{}{"action": "set", "key": "language"})
+	if !result.IsError {
+		t.Error("Expected set without a value to fail")
+	}
+}
+
+func TestFormatFacts_Bounded(t *testing.T) {
+	facts := map[string]string{
+		"a": strings.Repeat("x", 20),
+		"b": strings.Repeat("y", 20),
+		"c": strings.Repeat("z", 20),
+	}
+
+	out := FormatFacts(facts, 30)
+	if !strings.HasPrefix(out, "- a: ") {
+		t.Errorf("Expected facts sorted by key, got %q", out)
+	}
+	if strings.Contains(out, "- b: ") || !strings.Contains(out, "2 more not shown") {
+		t.Errorf("Expected output truncated after the first fact, got %q", out)
+	}
+}
@@ -0,0 +1,89 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultStashTTL is how long a stashed tool output is kept.
+const DefaultStashTTL = 24 * time.Hour
+
+// ToolOutputLimit caps the tool results fed back to the model (ForLLM) so
+// one oversized result cannot blow the context window. What the user sees
+// (ForUser) is not affected.
+type ToolOutputLimit struct {
+	// MaxChars is the most characters passed on; 0 disables the cap.
+	MaxChars int
+	// StashDir, when set, receives the full output of every truncated
+	// result so the agent can read specific parts of it.
+	StashDir string
+	// StashTTL is how long stashed outputs are kept (default
+	// DefaultStashTTL). Older ones are deleted whenever another is stashed.
+	StashTTL time.Duration
+}
+
+// Apply returns content, or its first MaxChars characters followed by a
+// marker saying how much was cut and, when stashed, where the rest is.
+func (l ToolOutputLimit) Apply(toolName, toolCallID, content string) string {
+	if l.MaxChars <= 0 || utf8.RuneCountInString(content) <= l.MaxChars {
+		return content
+	}
+
+	// Byte offset of the first rune past the cap
+	n, cut := 0, len(content)
+	for i := range content {
+		if n == l.MaxChars {
+			cut = i
+			break
+		}
+		n++
+	}
+	total := utf8.RuneCountInString(content)
+
+	marker := fmt.Sprintf("\n\n[Output truncated: showing the first %d of %d characters.", l.MaxChars, total)
+	if path, err := l.stash(toolName, toolCallID, content); err == nil {
+		marker += fmt.Sprintf(" The full output is saved in %s; use exec with grep, sed or tail to read the parts you need.", path)
+	}
+	return content[:cut] + marker + "]"
+}
+
+func (l ToolOutputLimit) stash(toolName, toolCallID, content string) (string, error) {
+	if l.StashDir == "" {
+		return "", fmt.Errorf("no stash directory")
+	}
+	if err := os.MkdirAll(l.StashDir, 0755); err != nil {
+		return "", err
+	}
+	id := toolCallID
+	if id == "" {
+		id = fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	name := traceFileInvalid.ReplaceAllString(toolName+"-"+id, "_") + ".txt"
+	path := filepath.Join(l.StashDir, name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		return "", err
+	}
+	l.pruneStash()
+	return path, nil
+}
+
+// pruneStash deletes stashed outputs older than the TTL.
+func (l ToolOutputLimit) pruneStash() {
+	ttl := l.StashTTL
+	if ttl <= 0 {
+		ttl = DefaultStashTTL
+	}
+	files, err := filepath.Glob(filepath.Join(l.StashDir, "*.txt"))
+	if err != nil {
+		return
+	}
+	cutoff := time.Now().Add(-ttl)
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil && info.ModTime().Before(cutoff) {
+			os.Remove(file)
+		}
+	}
+}
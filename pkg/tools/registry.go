@@ -42,7 +42,7 @@ func (r *ToolRegistry) Execute(ctx context.Context, name string, args map[string
 // If the tool implements AsyncTool and a non-nil callback is provided,
 // the callback will be set on the tool before execution.
 func (r *ToolRegistry) ExecuteWithContext(ctx context.Context, name string, args map[string]interface{}, channel, chatID string, asyncCallback AsyncCallback) *ToolResult {
-	logger.InfoCF("tool", "Tool execution started",
+	logger.InfoCtx(ctx, "tool", "Tool execution started",
 		map[string]interface{}{
 			"tool": name,
 			"args": args,
@@ -50,7 +50,7 @@ func (r *ToolRegistry) ExecuteWithContext(ctx context.Context, name string, args
 
 	tool, ok := r.Get(name)
 	if !ok {
-		logger.ErrorCF("tool", "Tool not found",
+		logger.ErrorCtx(ctx, "tool", "Tool not found",
 			map[string]interface{}{
 				"tool": name,
 			})
@@ -65,7 +65,7 @@ func (r *ToolRegistry) ExecuteWithContext(ctx context.Context, name string, args
 	// If tool implements AsyncTool and callback is provided, set callback
 	if asyncTool, ok := tool.(AsyncTool); ok && asyncCallback != nil {
 		asyncTool.SetCallback(asyncCallback)
-		logger.DebugCF("tool", "Async callback injected",
+		logger.DebugCtx(ctx, "tool", "Async callback injected",
 			map[string]interface{}{
 				"tool": name,
 			})
@@ -77,20 +77,20 @@ func (r *ToolRegistry) ExecuteWithContext(ctx context.Context, name string, args
 
 	// Log based on result type
 	if result.IsError {
-		logger.ErrorCF("tool", "Tool execution failed",
+		logger.ErrorCtx(ctx, "tool", "Tool execution failed",
 			map[string]interface{}{
 				"tool":     name,
 				"duration": duration.Milliseconds(),
 				"error":    result.ForLLM,
 			})
 	} else if result.Async {
-		logger.InfoCF("tool", "Tool started (async)",
+		logger.InfoCtx(ctx, "tool", "Tool started (async)",
 			map[string]interface{}{
 				"tool":     name,
 				"duration": duration.Milliseconds(),
 			})
 	} else {
-		logger.InfoCF("tool", "Tool execution completed",
+		logger.InfoCtx(ctx, "tool", "Tool execution completed",
 			map[string]interface{}{
 				"tool":          name,
 				"duration_ms":   duration.Milliseconds(),
@@ -0,0 +1,48 @@
+package tools
+
+import (
+	"sort"
+	"strings"
+)
+
+// builtinToolNames are the names of the tools the agent registers itself.
+// Skills may not use them, so a skill can never shadow core functionality.
+// Keep this in sync with createToolRegistry in pkg/agent and the swarm
+// tools; TestBuiltinToolsAreReserved there fails if a tool is missing.
+var builtinToolNames = map[string]bool{
+	"read_file":     true,
+	"write_file":    true,
+	"list_dir":      true,
+	"edit_file":     true,
+	"append_file":   true,
+	"exec":          true,
+	"web_search":    true,
+	"web_fetch":     true,
+	"i2c":           true,
+	"spi":           true,
+	"message":       true,
+	"knowledge":     true,
+	"memory":        true,
+	"cron":          true,
+	"spawn_agent":   true,
+	"delegate_task": true,
+	"swarm":         true,
+	"scratchpad":    true,
+	"self_trace":    true,
+}
+
+// IsReservedName reports whether name belongs to a built-in tool.
+// The comparison ignores case.
+func IsReservedName(name string) bool {
+	return builtinToolNames[strings.ToLower(name)]
+}
+
+// ReservedNames returns the built-in tool names, sorted.
+func ReservedNames() []string {
+	names := make([]string, 0, len(builtinToolNames))
+	for name := range builtinToolNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
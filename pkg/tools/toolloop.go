@@ -23,24 +23,29 @@ type ToolLoopConfig struct {
 	Tools         *ToolRegistry
 	MaxIterations int
 	LLMOptions    map[string]any
+	OutputLimit   ToolOutputLimit // Cap on tool results fed back to the LLM
+	// MaxContinuations is how many times a reply cut off at max_tokens is
+	// continued; 0 only flags it as truncated.
+	MaxContinuations int
 }
 
 // ToolLoopResult contains the result of running the tool loop.
 type ToolLoopResult struct {
 	Content    string
 	Iterations int
+	Truncated  bool // the reply was cut off at max_tokens
 }
 
 // RunToolLoop executes the LLM + tool call iteration loop.
 // This is the core agent logic that can be reused by both main agent and subagents.
 func RunToolLoop(ctx context.Context, config ToolLoopConfig, messages []providers.Message, channel, chatID string) (*ToolLoopResult, error) {
 	iteration := 0
-	var finalContent string
+	reply := Continuation{Max: config.MaxContinuations}
 
 	for iteration < config.MaxIterations {
 		iteration++
 
-		logger.DebugCF("toolloop", "LLM iteration",
+		logger.DebugCtx(ctx, "toolloop", "LLM iteration",
 			map[string]any{
 				"iteration": iteration,
 				"max":       config.MaxIterations,
@@ -64,21 +69,30 @@ func RunToolLoop(ctx context.Context, config ToolLoopConfig, messages []provider
 		// 3. Call LLM
 		response, err := config.Provider.Chat(ctx, messages, providerToolDefs, config.Model, llmOpts)
 		if err != nil {
-			logger.ErrorCF("toolloop", "LLM call failed",
+			logger.ErrorCtx(ctx, "toolloop", "LLM call failed",
 				map[string]any{
 					"iteration": iteration,
 					"error":     err.Error(),
 				})
 			return nil, fmt.Errorf("LLM call failed: %w", err)
 		}
+		providers.RecordUsage(ctx, config.Model, response.Usage)
 
 		// 4. If no tool calls, we're done
 		if len(response.ToolCalls) == 0 {
-			finalContent = response.Content
-			logger.InfoCF("toolloop", "LLM response without tool calls (direct answer)",
+			var more bool
+			if messages, more = reply.Add(messages, response); more {
+				logger.InfoCtx(ctx, "toolloop", "LLM reply cut off at max_tokens, continuing",
+					map[string]any{
+						"iteration":    iteration,
+						"continuation": reply.Count(),
+					})
+				continue
+			}
+			logger.InfoCtx(ctx, "toolloop", "LLM response without tool calls (direct answer)",
 				map[string]any{
 					"iteration":     iteration,
-					"content_chars": len(finalContent),
+					"content_chars": len(reply.Content()),
 				})
 			break
 		}
@@ -88,7 +102,7 @@ func RunToolLoop(ctx context.Context, config ToolLoopConfig, messages []provider
 		for _, tc := range response.ToolCalls {
 			toolNames = append(toolNames, tc.Name)
 		}
-		logger.InfoCF("toolloop", "LLM requested tool calls",
+		logger.InfoCtx(ctx, "toolloop", "LLM requested tool calls",
 			map[string]any{
 				"tools":     toolNames,
 				"count":     len(response.ToolCalls),
@@ -117,7 +131,7 @@ func RunToolLoop(ctx context.Context, config ToolLoopConfig, messages []provider
 		for _, tc := range response.ToolCalls {
 			argsJSON, _ := json.Marshal(tc.Arguments)
 			argsPreview := utils.Truncate(string(argsJSON), 200)
-			logger.InfoCF("toolloop", fmt.Sprintf("Tool call: %s(%s)", tc.Name, argsPreview),
+			logger.InfoCtx(ctx, "toolloop", fmt.Sprintf("Tool call: %s(%s)", tc.Name, argsPreview),
 				map[string]any{
 					"tool":      tc.Name,
 					"iteration": iteration,
@@ -136,6 +150,7 @@ func RunToolLoop(ctx context.Context, config ToolLoopConfig, messages []provider
 			if contentForLLM == "" && toolResult.Err != nil {
 				contentForLLM = toolResult.Err.Error()
 			}
+			contentForLLM = config.OutputLimit.Apply(tc.Name, tc.ID, contentForLLM)
 
 			// Add tool result message
 			toolResultMsg := providers.Message{
@@ -147,8 +162,16 @@ func RunToolLoop(ctx context.Context, config ToolLoopConfig, messages []provider
 		}
 	}
 
+	if reply.Truncated() {
+		logger.WarnCtx(ctx, "toolloop", "LLM reply truncated at max_tokens",
+			map[string]any{
+				"iteration":     iteration,
+				"continuations": reply.Count(),
+			})
+	}
 	return &ToolLoopResult{
-		Content:    finalContent,
+		Content:    reply.Content(),
 		Iterations: iteration,
+		Truncated:  reply.Truncated(),
 	}, nil
 }
@@ -0,0 +1,165 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sterlites/RDxClaw/pkg/providers"
+)
+
+// bigOutputTool returns a result far larger than the output cap.
+type bigOutputTool struct{}
+
+func (bigOutputTool) Name() string        { return "dump" }
+func (bigOutputTool) Description() string { return "Dump a lot of text" }
+func (bigOutputTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{"type": "object"}
+}
+func (bigOutputTool) Execute(ctx context.Context, args map[string]interface{}) *ToolResult {
+	return &ToolResult{ForLLM: strings.Repeat("é", 5000) + "TAIL", ForUser: "dumped"}
+}
+
+// dumpThenAnswerProvider calls the dump tool, then records what it was
+// sent back.
+type dumpThenAnswerProvider struct {
+	toolResult string
+}
+
+func (p *dumpThenAnswerProvider) Chat(ctx context.Context, messages []providers.Message, tools []providers.ToolDefinition, model string, options map[string]interface{}) (*providers.LLMResponse, error) {
+	last := messages[len(messages)-1]
+	if last.Role == "tool" {
+		p.toolResult = last.Content
+		return &providers.LLMResponse{Content: "done"}, nil
+	}
+	return &providers.LLMResponse{ToolCalls: []providers.ToolCall{{ID: "call_1", Name: "dump", Arguments: map[string]interface{}{}}}}, nil
+}
+
+func (p *dumpThenAnswerProvider) GetDefaultModel() string { return "mock" }
+
+func TestRunToolLoop_TruncatesOversizedToolResult(t *testing.T) {
+	registry := NewToolRegistry()
+	registry.Register(bigOutputTool{})
+	provider := &dumpThenAnswerProvider{}
+	stash := t.TempDir()
+
+	result, err := RunToolLoop(context.Background(), ToolLoopConfig{
+		Provider:      provider,
+		Model:         "mock",
+		Tools:         registry,
+		MaxIterations: 3,
+		OutputLimit:   ToolOutputLimit{MaxChars: 100, StashDir: stash},
+	}, []providers.Message{{Role: "user", Content: "dump it"}}, "cli", "direct")
+	if err != nil {
+		t.Fatalf("RunToolLoop failed: %v", err)
+	}
+	if result.Content != "done" {
+		t.Fatalf("Content = %q, want done", result.Content)
+	}
+
+	got := provider.toolResult
+	if !strings.HasPrefix(got, strings.Repeat("é", 100)+"\n\n[Output truncated: showing the first 100 of 5004 characters.") {
+		t.Errorf("tool result was not truncated as expected: %.200q", got)
+	}
+	if strings.Contains(got, "TAIL") {
+		t.Error("truncated result still contains the end of the output")
+	}
+
+	files, _ := os.ReadDir(stash)
+	if len(files) != 1 {
+		t.Fatalf("stash holds %d files, want 1", len(files))
+	}
+	if !strings.Contains(got, files[0].Name()) {
+		t.Errorf("marker does not point at the stashed file %s", files[0].Name())
+	}
+}
+
+func TestToolOutputLimit_PrunesOldStashes(t *testing.T) {
+	stash := t.TempDir()
+	old := filepath.Join(stash, "dump-old.txt")
+	if err := os.WriteFile(old, []byte("old output"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-2 * time.Hour)
+	os.Chtimes(old, past, past)
+
+	limit := ToolOutputLimit{MaxChars: 10, StashDir: stash, StashTTL: time.Hour}
+	limit.Apply("dump", "new", strings.Repeat("x", 100))
+
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Error("expected the expired stash to be deleted")
+	}
+	if _, err := os.Stat(filepath.Join(stash, "dump-new.txt")); err != nil {
+		t.Errorf("expected the new stash to be kept: %v", err)
+	}
+}
+
+func TestToolOutputLimit_Disabled(t *testing.T) {
+	content := strings.Repeat("x", 1000)
+	if got := (ToolOutputLimit{}).Apply("dump", "1", content); got != content {
+		t.Error("a zero limit should leave the output alone")
+	}
+}
+
+// cutOffProvider answers in parts, reporting a length finish for all but
+// the last, and counts the calls it gets.
+type cutOffProvider struct {
+	parts []string
+	calls int
+}
+
+func (p *cutOffProvider) Chat(ctx context.Context, messages []providers.Message, tools []providers.ToolDefinition, model string, options map[string]interface{}) (*providers.LLMResponse, error) {
+	part := p.parts[p.calls]
+	p.calls++
+	if p.calls < len(p.parts) {
+		return &providers.LLMResponse{Content: part, FinishReason: FinishReasonLength}, nil
+	}
+	return &providers.LLMResponse{Content: part, FinishReason: "stop"}, nil
+}
+
+func (p *cutOffProvider) GetDefaultModel() string { return "mock" }
+
+func TestRunToolLoop_LengthFinish(t *testing.T) {
+	run := func(provider *cutOffProvider, continuations int) *ToolLoopResult {
+		t.Helper()
+		result, err := RunToolLoop(context.Background(), ToolLoopConfig{
+			Provider:         provider,
+			Model:            "mock",
+			MaxIterations:    5,
+			MaxContinuations: continuations,
+		}, []providers.Message{{Role: "user", Content: "tell me a story"}}, "cli", "direct")
+		if err != nil {
+			t.Fatalf("RunToolLoop failed: %v", err)
+		}
+		return result
+	}
+
+	t.Run("detected without continuations", func(t *testing.T) {
+		provider := &cutOffProvider{parts: []string{"Once upon", " a time."}}
+		result := run(provider, 0)
+		if !result.Truncated || result.Content != "Once upon" || provider.calls != 1 {
+			t.Errorf("got content %q, truncated %v after %d calls; want the cut-off part flagged after 1 call",
+				result.Content, result.Truncated, provider.calls)
+		}
+	})
+
+	t.Run("continued and stitched", func(t *testing.T) {
+		provider := &cutOffProvider{parts: []string{"Once upon", " a time", " the end."}}
+		result := run(provider, 2)
+		if result.Truncated || result.Content != "Once upon a time the end." {
+			t.Errorf("got content %q, truncated %v; want the stitched reply", result.Content, result.Truncated)
+		}
+	})
+
+	t.Run("out of continuations", func(t *testing.T) {
+		provider := &cutOffProvider{parts: []string{"Once upon", " a time", " the end."}}
+		result := run(provider, 1)
+		if !result.Truncated || result.Content != "Once upon a time" || provider.calls != 2 {
+			t.Errorf("got content %q, truncated %v after %d calls; want two parts flagged as truncated",
+				result.Content, result.Truncated, provider.calls)
+		}
+	})
+}
@@ -0,0 +1,338 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sterlites/RDxClaw/pkg/reqctx"
+	"github.com/Sterlites/RDxClaw/pkg/utils"
+)
+
+// traceFieldChars bounds the arguments and result kept per trace entry.
+const traceFieldChars = 500
+
+const (
+	// DefaultTraceMaxAge is how long a session's trace is kept after its
+	// last tool call.
+	DefaultTraceMaxAge = 7 * 24 * time.Hour
+	// DefaultTraceMaxBytes caps one session's trace file. When it grows
+	// past this, the oldest half of its entries is dropped.
+	DefaultTraceMaxBytes = 1 << 20
+)
+
+// tracePruneInterval is how often Record looks for expired trace files.
+const tracePruneInterval = time.Hour
+
+const redactedValue = "[REDACTED]"
+
+var traceFileInvalid = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// secretArgName matches argument names whose values are never written to
+// a trace, such as "api_key", "password" or "Authorization".
+var secretArgName = regexp.MustCompile(`(?i)(password|passwd|secret|token$|api[_-]?key|authorization|credential|private[_-]?key|cookie|^auth$|^pass$)`)
+
+// secretValue matches credentials embedded in free text, such as a bearer
+// header in a curl command or a provider API key.
+var secretValue = regexp.MustCompile(`(?i)(bearer\s+[a-z0-9._~+/=-]+|\bsk-[a-z0-9_-]{16,}|\bgh[pousr]_[a-z0-9]{20,}|\bxox[abp]-[a-z0-9-]{10,})`)
+
+// TraceEntry is one tool call recorded in a session's trace.
+type TraceEntry struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Tool       string    `json:"tool"`
+	Args       string    `json:"args"`   // JSON, redacted and truncated
+	Result     string    `json:"result"` // redacted and truncated
+	IsError    bool      `json:"is_error,omitempty"`
+	DurationMS int64     `json:"duration_ms"`
+}
+
+// TraceRecorder appends the agent's tool calls to one JSONL file per
+// session under dir. Files are named by a hash of the session key, so
+// distinct sessions never share a trace. Traces idle for longer than the
+// max age are deleted, and each is capped at max bytes.
+type TraceRecorder struct {
+	dir       string
+	maxAge    time.Duration
+	maxBytes  int64
+	lastPrune time.Time
+	mu        sync.Mutex
+}
+
+func NewTraceRecorder(dir string) *TraceRecorder {
+	return &TraceRecorder{dir: dir, maxAge: DefaultTraceMaxAge, maxBytes: DefaultTraceMaxBytes}
+}
+
+// SetRetention overrides how long idle traces are kept and how large one
+// may grow. Zero disables the respective limit.
+func (r *TraceRecorder) SetRetention(maxAge time.Duration, maxBytes int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.maxAge = maxAge
+	r.maxBytes = maxBytes
+}
+
+func (r *TraceRecorder) path(sessionKey string) string {
+	sum := sha256.Sum256([]byte(sessionKey))
+	return filepath.Join(r.dir, hex.EncodeToString(sum[:16])+".jsonl")
+}
+
+// Record appends a tool call to the session's trace. Secrets are redacted
+// from the arguments and result, and both are truncated to keep the trace
+// small.
+func (r *TraceRecorder) Record(sessionKey, tool string, args map[string]interface{}, result *ToolResult, duration time.Duration) error {
+	if sessionKey == "" {
+		return nil
+	}
+	argsJSON, _ := json.Marshal(redactArgs(args))
+	entry := TraceEntry{
+		Timestamp:  time.Now(),
+		Tool:       tool,
+		Args:       utils.Truncate(redactSecrets(string(argsJSON)), traceFieldChars),
+		DurationMS: duration.Milliseconds(),
+	}
+	if result != nil {
+		entry.Result = utils.Truncate(redactSecrets(result.ForLLM), traceFieldChars)
+		entry.IsError = result.IsError
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := os.MkdirAll(r.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create trace directory: %w", err)
+	}
+	path := r.path(sessionKey)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open trace: %w", err)
+	}
+	_, err = f.Write(append(line, '\n'))
+	var size int64
+	if info, statErr := f.Stat(); statErr == nil {
+		size = info.Size()
+	}
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	if r.maxBytes > 0 && size > r.maxBytes {
+		if err := trimTrace(path, r.maxBytes/2); err != nil {
+			return err
+		}
+	}
+	if r.maxAge > 0 && time.Since(r.lastPrune) >= tracePruneInterval {
+		r.lastPrune = time.Now()
+		r.pruneExpired()
+	}
+	return nil
+}
+
+// pruneExpired deletes traces not written to within the max age.
+func (r *TraceRecorder) pruneExpired() {
+	files, err := filepath.Glob(filepath.Join(r.dir, "*.jsonl"))
+	if err != nil {
+		return
+	}
+	cutoff := time.Now().Add(-r.maxAge)
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil && info.ModTime().Before(cutoff) {
+			os.Remove(file)
+		}
+	}
+}
+
+// trimTrace rewrites the trace at path with only its newest entries that
+// fit in keep bytes.
+func trimTrace(path string, keep int64) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read trace: %w", err)
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	start := len(lines)
+	var size int64
+	for start > 0 && size+int64(len(lines[start-1])) <= keep {
+		start--
+		size += int64(len(lines[start]))
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strings.Join(lines[start:], "")), 0600); err != nil {
+		return fmt.Errorf("failed to trim trace: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to trim trace: %w", err)
+	}
+	return nil
+}
+
+// redactArgs returns a copy of args with the values of secret-looking
+// names, at any depth, replaced.
+func redactArgs(args map[string]interface{}) map[string]interface{} {
+	if args == nil {
+		return nil
+	}
+	out := make(map[string]interface{}, len(args))
+	for name, value := range args {
+		if secretArgName.MatchString(name) {
+			out[name] = redactedValue
+			continue
+		}
+		out[name] = redactValue(value)
+	}
+	return out
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return redactArgs(v)
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = redactValue(item)
+		}
+		return out
+	default:
+		return value
+	}
+}
+
+// redactSecrets replaces credentials embedded in text.
+func redactSecrets(text string) string {
+	return secretValue.ReplaceAllString(text, redactedValue)
+}
+
+// Recent returns up to limit of the session's latest entries that pass
+// keep, oldest first.
+func (r *TraceRecorder) Recent(sessionKey string, limit int, keep func(TraceEntry) bool) ([]TraceEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, err := os.Open(r.path(sessionKey))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open trace: %w", err)
+	}
+	defer f.Close()
+
+	var entries []TraceEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry TraceEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if keep != nil && !keep(entry) {
+			continue
+		}
+		entries = append(entries, entry)
+		if limit > 0 && len(entries) > limit {
+			entries = entries[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read trace: %w", err)
+	}
+	return entries, nil
+}
+
+// SelfTraceTool lets the agent look back at the tool calls it made in the
+// current session, e.g. to say what it just did or to recover after an
+// error. It never reads other sessions' traces.
+type SelfTraceTool struct {
+	recorder *TraceRecorder
+}
+
+func NewSelfTraceTool(recorder *TraceRecorder) *SelfTraceTool {
+	return &SelfTraceTool{recorder: recorder}
+}
+
+func (t *SelfTraceTool) Name() string {
+	return "self_trace"
+}
+
+func (t *SelfTraceTool) Description() string {
+	return "List the tool calls you made earlier in this conversation, with their (shortened) arguments and results. Use it to recall what you just did or what went wrong."
+}
+
+func (t *SelfTraceTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"limit": map[string]interface{}{
+				"type":        "integer",
+				"description": "How many recent calls to return (default 10, max 50)",
+			},
+			"query": map[string]interface{}{
+				"type":        "string",
+				"description": "Only return calls whose tool name, arguments or result contain this text",
+			},
+			"errors_only": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Only return calls that failed",
+			},
+		},
+	}
+}
+
+func (t *SelfTraceTool) Execute(ctx context.Context, args map[string]interface{}) *ToolResult {
+	sessionKey := reqctx.SessionKey(ctx)
+	if sessionKey == "" {
+		return ErrorResult("no active session to trace")
+	}
+
+	limit := 10
+	if l, ok := args["limit"].(float64); ok && l > 0 {
+		limit = int(l)
+	}
+	if limit > 50 {
+		limit = 50
+	}
+	query, _ := args["query"].(string)
+	query = strings.ToLower(query)
+	errorsOnly, _ := args["errors_only"].(bool)
+
+	entries, err := t.recorder.Recent(sessionKey, limit, func(e TraceEntry) bool {
+		if e.Tool == t.Name() || (errorsOnly && !e.IsError) {
+			return false
+		}
+		return query == "" || strings.Contains(strings.ToLower(e.Tool+" "+e.Args+" "+e.Result), query)
+	})
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	if len(entries) == 0 {
+		return SilentResult("No matching tool calls in this session.")
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Last %d tool call(s), oldest first:\n", len(entries))
+	for _, e := range entries {
+		status := "ok"
+		if e.IsError {
+			status = "error"
+		}
+		fmt.Fprintf(&sb, "- %s %s(%s) [%s, %dms]\n  -> %s\n",
+			e.Timestamp.Format("15:04:05"), e.Tool, e.Args, status, e.DurationMS, strings.ReplaceAll(e.Result, "\n", " "))
+	}
+	return SilentResult(sb.String())
+}
@@ -0,0 +1,83 @@
+package tools
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTraceRecorder_SessionsDoNotCollide(t *testing.T) {
+	r := NewTraceRecorder(t.TempDir())
+	// Both keys sanitize to the same name, but belong to different sessions
+	r.Record("telegram:42", "exec", map[string]interface{}{"command": "ls"}, NewToolResult("a"), 0)
+	r.Record("telegram_42", "exec", map[string]interface{}{"command": "pwd"}, NewToolResult("b"), 0)
+
+	entries, err := r.Recent("telegram:42", 10, nil)
+	if err != nil {
+		t.Fatalf("Recent failed: %v", err)
+	}
+	if len(entries) != 1 || !strings.Contains(entries[0].Args, "ls") {
+		t.Errorf("Expected only the telegram:42 call, got %+v", entries)
+	}
+}
+
+func TestTraceRecorder_RedactsSecrets(t *testing.T) {
+	r := NewTraceRecorder(t.TempDir())
+	args := map[string]interface{}{
+		"command": "curl -H 'Authorization: Bearer abc.def.ghi' https://example.com",
+		"headers": map[string]interface{}{"X-Api-Key": "hunter2"},
+		"env":     []interface{}{map[string]interface{}{"password": "swordfish"}},
+		"author":  "Ada",
+	}
+	r.Record("cli:default", "web_fetch", args, NewToolResult("key sk-abcdefghijklmnopqrstuvwx used"), 0)
+
+	entries, err := r.Recent("cli:default", 10, nil)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("Recent = %v, %v", entries, err)
+	}
+	for _, secret := range []string{"abc.def.ghi", "hunter2", "swordfish", "sk-abcdefghijklmnopqrstuvwx"} {
+		if strings.Contains(entries[0].Args+entries[0].Result, secret) {
+			t.Errorf("Trace leaked %q: %+v", secret, entries[0])
+		}
+	}
+	if !strings.Contains(entries[0].Args, "Ada") {
+		t.Errorf("Expected non-secret arguments to be kept, got %s", entries[0].Args)
+	}
+}
+
+func TestTraceRecorder_Retention(t *testing.T) {
+	r := NewTraceRecorder(t.TempDir())
+	r.SetRetention(time.Hour, 2000)
+
+	for i := 0; i < 50; i++ {
+		r.Record("cli:big", "exec", map[string]interface{}{"command": strings.Repeat("x", 50)}, NewToolResult("ok"), 0)
+	}
+	info, err := os.Stat(r.path("cli:big"))
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if info.Size() > 2000 {
+		t.Errorf("Trace grew to %d bytes, want at most 2000", info.Size())
+	}
+	entries, _ := r.Recent("cli:big", 0, nil)
+	if len(entries) == 0 || len(entries) == 50 {
+		t.Errorf("Expected the newest entries to be kept, got %d", len(entries))
+	}
+
+	// A trace idle for longer than the max age is deleted on a later write
+	old := r.path("cli:old")
+	if err := os.WriteFile(old, []byte("{}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-2 * time.Hour)
+	os.Chtimes(old, past, past)
+	r.lastPrune = time.Time{}
+	r.Record("cli:new", "exec", nil, NewToolResult("ok"), 0)
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Error("Expected the expired trace to be deleted")
+	}
+	if _, err := os.Stat(r.path("cli:big")); err != nil {
+		t.Error("Expected the recent trace to be kept")
+	}
+}
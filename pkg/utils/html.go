@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	htmlScriptRe = regexp.MustCompile(`<script[\s\S]*?</script>`)
+	htmlStyleRe  = regexp.MustCompile(`<style[\s\S]*?</style>`)
+	htmlTagRe    = regexp.MustCompile(`<[^>]+>`)
+	whitespaceRe = regexp.MustCompile(`\s+`)
+)
+
+// HTMLToText strips scripts, styles and tags from an HTML document and
+// collapses the remaining whitespace.
+func HTMLToText(htmlContent string) string {
+	result := htmlScriptRe.ReplaceAllLiteralString(htmlContent, "")
+	result = htmlStyleRe.ReplaceAllLiteralString(result, "")
+	result = htmlTagRe.ReplaceAllLiteralString(result, "")
+
+	result = strings.TrimSpace(result)
+	result = whitespaceRe.ReplaceAllLiteralString(result, " ")
+
+	lines := strings.Split(result, "\n")
+	var cleanLines []string
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			cleanLines = append(cleanLines, line)
+		}
+	}
+
+	return strings.Join(cleanLines, "\n")
+}
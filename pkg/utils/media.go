@@ -32,6 +32,19 @@ func IsAudioFile(filename, contentType string) bool {
 	return false
 }
 
+// IsImageFile checks if a file is an image based on its filename extension and content type.
+func IsImageFile(filename, contentType string) bool {
+	imageExtensions := []string{".jpg", ".jpeg", ".png", ".gif", ".webp"}
+
+	for _, ext := range imageExtensions {
+		if strings.HasSuffix(strings.ToLower(filename), ext) {
+			return true
+		}
+	}
+
+	return strings.HasPrefix(strings.ToLower(contentType), "image/")
+}
+
 // SanitizeFilename removes potentially dangerous characters from a filename
 // and returns a safe version for local filesystem storage.
 func SanitizeFilename(filename string) string {